@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/Je33/imperial_fleet/internal/fleetctl"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	return fleetctl.New(os.Stdout, nil).Run(ctx, os.Args[1:])
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
-	"github.com/Je33/imperial_fleet/internal/transport/rest"
+	"os"
+
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/transport/cli"
 )
 
 func main() {
@@ -12,9 +16,6 @@ func main() {
 }
 
 func run() error {
-	err := rest.RunRest()
-	if err != nil {
-		return err
-	}
-	return nil
-}
\ No newline at end of file
+	ctx := context.Background()
+	return cli.NewCLI(os.Stdout, mysql.Connect).Run(ctx, os.Args[1:])
+}
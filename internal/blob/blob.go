@@ -0,0 +1,122 @@
+// Package blob stores uploaded files
+package blob
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	blobErrorPrefix = "[blob]"
+
+	// test interface
+	_ service.BlobStore = (*FileStore)(nil)
+)
+
+// store which keeps blobs as files in directory, key is slash separated relative path
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir}
+}
+
+// write blob atomically, existing blob is replaced
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create blob dir", blobErrorPrefix)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return errors.Wrapf(err, "%s: create blob file", blobErrorPrefix)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "%s: write blob file", blobErrorPrefix)
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.Wrapf(err, "%s: write blob file", blobErrorPrefix)
+	}
+
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return errors.Wrapf(err, "%s: save blob file", blobErrorPrefix)
+	}
+
+	return nil
+}
+
+// read blob, content type is detected from content
+func (s *FileStore) Get(ctx context.Context, key string) (*domain.Blob, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get %s", blobErrorPrefix, key)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: stat blob file", blobErrorPrefix)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: read blob file", blobErrorPrefix)
+	}
+
+	return &domain.Blob{
+		Key:         key,
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		ModTime:     info.ModTime().Unix(),
+	}, nil
+}
+
+// delete all blobs with key prefix, prefix is a directory
+func (s *FileStore) DeleteAll(ctx context.Context, prefix string) error {
+
+	path, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(path)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete blobs", blobErrorPrefix)
+	}
+
+	return nil
+}
+
+// file path of key, keys can't point outside of store directory
+func (s *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s: invalid key %q", blobErrorPrefix, key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
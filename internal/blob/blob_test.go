@@ -0,0 +1,38 @@
+package blob
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+
+	ctx := context.Background()
+	store := NewFileStore(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "spaceships/1/2/original", []byte("first")))
+	require.NoError(t, store.Put(ctx, "spaceships/1/2/original", []byte("second")))
+	require.NoError(t, store.Put(ctx, "spaceships/1/2/thumb", []byte("thumb")))
+	require.NoError(t, store.Put(ctx, "spaceships/1/3/original", []byte("other")))
+
+	blob, err := store.Get(ctx, "spaceships/1/2/original")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), blob.Data)
+	assert.Equal(t, "text/plain; charset=utf-8", blob.ContentType)
+
+	require.NoError(t, store.DeleteAll(ctx, "spaceships/1/2"))
+	_, err = store.Get(ctx, "spaceships/1/2/thumb")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = store.Get(ctx, "spaceships/1/3/original")
+	assert.NoError(t, err)
+
+	// keys can't escape store directory
+	for _, key := range []string{"", ".", "../secret", "/etc/passwd", "spaceships/../../secret"} {
+		assert.Error(t, store.Put(ctx, key, []byte("x")), key)
+	}
+}
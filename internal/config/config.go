@@ -2,29 +2,119 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"os"
 	"regexp"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/ratelimit"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	LogLevel  string `envconfig:"LOG_LEVEL"`
-	MysqlDSN  string `envconfig:"MYSQL_DSN"`
-	HTTPAddr  string `envconfig:"HTTP_ADDR"`
-	JWTSecret string `envconfig:"JWT_SECRET"`
+	LogLevel string `envconfig:"LOG_LEVEL"`
+	MysqlDSN string `envconfig:"MYSQL_DSN"`
+	HTTPAddr string `envconfig:"HTTP_ADDR"`
+
+	// issuer and audience claims of issued tokens
+	JWTIssuer   string `envconfig:"JWT_ISSUER" default:"imperial-fleet"`
+	JWTAudience string `envconfig:"JWT_AUDIENCE" default:"imperial-fleet-api"`
+	// signing keys are kept in db or as pem files in JWTKeyDir
+	JWTKeyStore string `envconfig:"JWT_KEY_STORE" default:"db"`
+	JWTKeyDir   string `envconfig:"JWT_KEY_DIR" default:"keys"`
+	// algorithm of new keys, RS256 or EdDSA
+	JWTKeyAlgorithm string `envconfig:"JWT_KEY_ALGORITHM" default:"EdDSA"`
+	// new key is generated every rotation, published propagation before it signs
+	// and kept for retention after it stops signing, retention must cover refresh token lifetime
+	JWTKeyRotation    time.Duration `envconfig:"JWT_KEY_ROTATION" default:"720h"`
+	JWTKeyPropagation time.Duration `envconfig:"JWT_KEY_PROPAGATION" default:"10m"`
+	JWTKeyRetention   time.Duration `envconfig:"JWT_KEY_RETENTION" default:"720h"`
+
+	// rate limits per route group in form limit/period, empty disables limit
+	RateLimitAuth ratelimit.Rate `envconfig:"RATE_LIMIT_AUTH" default:"10/1m"`
+	RateLimitAPI  ratelimit.Rate `envconfig:"RATE_LIMIT_API" default:"300/1m"`
+	// comma separated cidrs of proxies whose X-Forwarded-For is trusted,
+	// empty uses address of connection as client ip
+	TrustedProxies IPRanges `envconfig:"TRUSTED_PROXIES"`
+
+	// failed logins after which account is locked, lock doubles up to max
+	LoginLockoutThreshold int           `envconfig:"LOGIN_LOCKOUT_THRESHOLD" default:"5"`
+	LoginLockoutBase      time.Duration `envconfig:"LOGIN_LOCKOUT_BASE" default:"1m"`
+	LoginLockoutMax       time.Duration `envconfig:"LOGIN_LOCKOUT_MAX" default:"1h"`
+
+	// base url of frontend used in email links
+	AppURL string `envconfig:"APP_URL" default:"http://localhost:8080"`
+
+	// mailer is smtp or log, log mailer writes emails to MailerLogFile or stderr
+	Mailer        string `envconfig:"MAILER" default:"log"`
+	MailerLogFile string `envconfig:"MAILER_LOG_FILE"`
+	MailFrom      string `envconfig:"MAIL_FROM" default:"fleet@localhost"`
+	SMTPAddr      string `envconfig:"SMTP_ADDR"`
+	SMTPUsername  string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword  string `envconfig:"SMTP_PASSWORD"`
+
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"1h"`
+	EmailVerifyTTL   time.Duration `envconfig:"EMAIL_VERIFY_TTL" default:"48h"`
+
+	// issuer shown in authenticator apps
+	MFAIssuer string `envconfig:"MFA_ISSUER" default:"Imperial Fleet"`
+	// comma separated roles which must use second factor, e.g. officer,admin
+	MFARequiredRoles []string `envconfig:"MFA_REQUIRED_ROLES"`
+	// lifetime of token which is exchanged for auth tokens with mfa code
+	MFAChallengeTTL time.Duration `envconfig:"MFA_CHALLENGE_TTL" default:"5m"`
+
+	// directory of uploaded files
+	BlobDir string `envconfig:"BLOB_DIR" default:"data/blobs"`
+	// max size of uploaded image in bytes
+	ImageMaxBytes int64 `envconfig:"IMAGE_MAX_BYTES" default:"10485760"`
+	// max width or height of uploaded image in pixels
+	ImageMaxSide int `envconfig:"IMAGE_MAX_SIDE" default:"8000"`
+
+	// interval of fleet metrics snapshots, snapshot of the day is refreshed until the day ends
+	ReportSnapshotInterval time.Duration `envconfig:"REPORT_SNAPSHOT_INTERVAL" default:"1h"`
+
+	// yaml file of readiness scoring rules, reloaded when changed, empty for default rules
+	ReadinessRules string `envconfig:"READINESS_RULES"`
+
+	// yaml or json file of star systems and hyperlanes for route planner, empty for default galaxy
+	HyperspaceGalaxy string `envconfig:"HYPERSPACE_GALAXY"`
+
+	// read-through cache of spaceships by id, size is count of cached spaceships
+	SpaceshipCache     bool          `envconfig:"SPACESHIP_CACHE" default:"true"`
+	SpaceshipCacheSize int           `envconfig:"SPACESHIP_CACHE_SIZE" default:"10000"`
+	SpaceshipCacheTTL  time.Duration `envconfig:"SPACESHIP_CACHE_TTL" default:"1m"`
+
+	// responses of requests with Idempotency-Key are kept in db or memory store and replayed during ttl
+	IdempotencyStore string        `envconfig:"IDEMPOTENCY_STORE" default:"db"`
+	IdempotencyTTL   time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	// time retry waits for request with the same key in flight
+	IdempotencyWait time.Duration `envconfig:"IDEMPOTENCY_WAIT" default:"10s"`
+
+	// limits of graphql queries, complexity is count of fields multiplied by page sizes
+	GraphQLMaxDepth      int `envconfig:"GRAPHQL_MAX_DEPTH" default:"10"`
+	GraphQLMaxComplexity int `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"1000"`
 }
 
 var (
 	config Config
 	once   sync.Once
+
+	// password part of dsn user:password@tcp(host:port)/db
+	dsnPasswordRegexp = regexp.MustCompile(`^([^:@/]*):([^@]*)@`)
 )
 
-const projectDirName = "ImperialFleet"
+const (
+	projectDirName = "ImperialFleet"
+	redactedValue  = "***"
+)
 
 // Get reads config from environment. Once.
 func Get() *Config {
@@ -33,19 +123,59 @@ func Get() *Config {
 		currentWorkDirectory, _ := os.Getwd()
 		rootPath := projectName.Find([]byte(currentWorkDirectory))
 
-		err := godotenv.Load(string(rootPath) + `/.env`) // load .env file
-		if err != nil {
+		// load .env file, environment variables are enough if it is absent
+		err := godotenv.Load(string(rootPath) + `/.env`)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			log.Fatal(err)
 		}
 		err = envconfig.Process("", &config)
 		if err != nil {
 			log.Fatal(err)
 		}
-		configBytes, err := json.MarshalIndent(config, "", "  ")
+		configBytes, err := json.MarshalIndent(config.Redacted(), "", "  ")
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println("Configuration:", string(configBytes))
+		log.Println("Configuration:", string(configBytes))
 	})
 	return &config
 }
+
+// Redacted returns copy of config with secrets hidden, safe for output
+func (c Config) Redacted() Config {
+	if c.SMTPPassword != "" {
+		c.SMTPPassword = redactedValue
+	}
+	c.MysqlDSN = dsnPasswordRegexp.ReplaceAllString(c.MysqlDSN, "${1}:"+redactedValue+"@")
+	return c
+}
+
+// ip ranges in cidr notation, e.g. 10.0.0.0/8,192.168.0.1/32
+type IPRanges []*net.IPNet
+
+// Decode implements envconfig decoder
+func (r *IPRanges) Decode(value string) error {
+	ranges := IPRanges{}
+	for _, cidr := range strings.Split(value, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("ip range %q: %w", cidr, err)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	*r = ranges
+	return nil
+}
+
+// MarshalText keeps ranges readable in printed config
+func (r IPRanges) MarshalText() ([]byte, error) {
+	cidrs := make([]string, 0, len(r))
+	for _, ipNet := range r {
+		cidrs = append(cidrs, ipNet.String())
+	}
+	return []byte(strings.Join(cidrs, ",")), nil
+}
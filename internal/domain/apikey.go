@@ -0,0 +1,77 @@
+package domain
+
+// scopes which can be granted to api key
+const (
+	ScopeSpaceshipsRead  = "spaceships:read"
+	ScopeSpaceshipsWrite = "spaceships:write"
+	ScopeCrewRead        = "crew:read"
+	ScopeCrewWrite       = "crew:write"
+	ScopeWorkOrdersRead  = "workorders:read"
+	ScopeWorkOrdersWrite = "workorders:write"
+	ScopeMissionsRead    = "missions:read"
+	ScopeMissionsWrite   = "missions:write"
+	ScopeReportsRead     = "reports:read"
+)
+
+// all known scopes
+func Scopes() []string {
+	return []string{
+		ScopeSpaceshipsRead,
+		ScopeSpaceshipsWrite,
+		ScopeCrewRead,
+		ScopeCrewWrite,
+		ScopeWorkOrdersRead,
+		ScopeWorkOrdersWrite,
+		ScopeMissionsRead,
+		ScopeMissionsWrite,
+		ScopeReportsRead,
+	}
+}
+
+// check if scope is known
+func ValidScope(scope string) bool {
+	for _, s := range Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// api key for machine access, only hash of key is stored,
+// key belongs to user or to service account and acts as its owner
+type APIKey struct {
+	ID uint
+	// zero for key of service account
+	UserID uint
+	// zero for key of user
+	ServiceAccountID uint
+	// filled on authentication of key of service account
+	ServiceAccount *ServiceAccount
+	Name           string
+	// first characters of key which help to recognize it
+	Prefix string
+	Hash   string
+	Scopes []string
+	// zero if key does not expire
+	ExpiresAt  int64
+	LastUsedAt int64
+	CreatedAt  int64
+}
+
+// check if key has scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// model of api key creation request
+type APIKeyCreateReq struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt int64
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "strings"
+
+// max count of operations in spaceship batch
+const SpaceshipBatchLimit = 100
+
+// custom type for operation of spaceship batch
+type SpaceshipOperationType uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	SpaceshipOperationUndefined SpaceshipOperationType = iota
+	SpaceshipOperationCreate
+	SpaceshipOperationUpdate
+	SpaceshipOperationDelete
+	SpaceshipOperationStatus
+)
+
+// convert operation type to string value
+func (t SpaceshipOperationType) String() string {
+	return [...]string{
+		"undefined",
+		"create",
+		"update",
+		"delete",
+		"status",
+	}[t]
+}
+
+func SpaceshipOperationTypeFromString(s string) SpaceshipOperationType {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "create":
+		return SpaceshipOperationCreate
+	case "update":
+		return SpaceshipOperationUpdate
+	case "delete":
+		return SpaceshipOperationDelete
+	case "status":
+		return SpaceshipOperationStatus
+	default:
+		return SpaceshipOperationUndefined
+	}
+}
+
+// operation of spaceship batch, spaceship is set for create and update,
+// status is set for status change
+type SpaceshipOperation struct {
+	Type      SpaceshipOperationType
+	ID        uint
+	Spaceship *Spaceship
+	Status    SpaceshipStatus
+}
+
+// result of operation, spaceship is nil on error
+type SpaceshipOperationResult struct {
+	Spaceship *Spaceship
+	Err       error
+}
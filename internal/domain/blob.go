@@ -0,0 +1,9 @@
+package domain
+
+// stored file with detected content type
+type Blob struct {
+	Key         string
+	Data        []byte
+	ContentType string
+	ModTime     int64
+}
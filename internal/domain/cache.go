@@ -0,0 +1,18 @@
+package domain
+
+// metrics of cache since start
+type CacheStats struct {
+	Enabled bool
+	Hits    int64
+	Misses  int64
+	// misses collapsed into load of another request
+	Shared int64
+}
+
+// share of reads served from cache in percents
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) * 100 / float64(s.Hits+s.Misses)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "strings"
+
+// custom type for rank of crew member
+type CrewRank uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	CrewRankUndefined CrewRank = iota
+	CrewRankCrewman
+	CrewRankPettyOfficer
+	CrewRankEnsign
+	CrewRankLieutenant
+	CrewRankCommander
+	CrewRankCaptain
+	CrewRankAdmiral
+)
+
+// convert rank to string value
+func (r CrewRank) String() string {
+	return [...]string{
+		"Undefined",
+		"Crewman",
+		"Petty Officer",
+		"Ensign",
+		"Lieutenant",
+		"Commander",
+		"Captain",
+		"Admiral",
+	}[r]
+}
+
+func CrewRankFromString(s string) CrewRank {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "crewman":
+		return CrewRankCrewman
+	case "petty officer":
+		return CrewRankPettyOfficer
+	case "ensign":
+		return CrewRankEnsign
+	case "lieutenant":
+		return CrewRankLieutenant
+	case "commander":
+		return CrewRankCommander
+	case "captain":
+		return CrewRankCaptain
+	case "admiral":
+		return CrewRankAdmiral
+	default:
+		return CrewRankUndefined
+	}
+}
+
+// person who serves on spaceships of organization
+type CrewMember struct {
+	ID uint
+	// organization of crew member, set by repository
+	TenantID      uint
+	Name          string
+	Rank          CrewRank
+	Specialty     string
+	ServiceNumber string
+	// spaceship of active assignment, zero if not assigned
+	SpaceshipID  uint
+	EnlistedAt   int64
+	DischargedAt int64
+}
+
+// service of crew member on spaceship
+type CrewAssignment struct {
+	ID           uint
+	CrewMemberID uint
+	SpaceshipID  uint
+	StartedAt    int64
+	// zero while assignment is active
+	EndedAt int64
+}
+
+// filter of crew list, empty fields are not applied
+type CrewFilter struct {
+	SpaceshipID uint
+	// discharged crew members are listed too
+	Discharged bool
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// fixed-point decimal with 4 fractional digits, e.g. money or rates,
+// sums are exact and products are rounded half away from zero
+type Decimal int64
+
+const (
+	// units of decimal in one
+	DecimalScale  = 10000
+	decimalDigits = 4
+	// integer digits which fit into int64 with fractional digits
+	decimalMaxIntDigits = 14
+	// the largest decimal of 14 integer digits, products are clamped to it
+	decimalMax = Decimal(1e18 - 1)
+)
+
+// decimal of whole number
+func NewDecimal(n int64) Decimal {
+	return Decimal(n * DecimalScale)
+}
+
+// parse decimal like "-1500.25", more than 4 fractional digits are not allowed
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(whole) > decimalMaxIntDigits || len(frac) > decimalDigits {
+		return 0, errors.Wrapf(ErrConversion, "decimal %q", s)
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, errors.Wrapf(ErrConversion, "decimal %q", s)
+		}
+	}
+
+	frac += strings.Repeat("0", decimalDigits-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(ErrConversion, "decimal %q", s)
+	}
+	if negative {
+		n = -n
+	}
+	return Decimal(n), nil
+}
+
+// decimal of float rounded to 4 fractional digits, e.g. for values of clients which send numbers,
+// NaN, infinities and values of more than 14 integer digits are not allowed
+func DecimalFromFloat(f float64) (Decimal, error) {
+	n := math.Round(f * DecimalScale)
+	if math.IsNaN(n) || math.Abs(n) >= float64(decimalMax) {
+		return 0, errors.Wrapf(ErrConversion, "decimal %v", f)
+	}
+	return Decimal(n), nil
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d) / DecimalScale
+}
+
+// decimal with at least 2 fractional digits, e.g. "1500.00" or "0.1234"
+func (d Decimal) String() string {
+	sign := ""
+	n := int64(d)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	frac := strconv.FormatInt(n%DecimalScale+DecimalScale, 10)[1:]
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) < 2 {
+		frac += strings.Repeat("0", 2-len(frac))
+	}
+	return sign + strconv.FormatInt(n/DecimalScale, 10) + "." + frac
+}
+
+// product of decimals, e.g. amount by rate
+func (d Decimal) Mul(x Decimal) Decimal {
+	return d.MulRatio(int64(x), DecimalScale)
+}
+
+// product of decimal and ratio of integers, e.g. rate of elapsed days of year,
+// big integers are used so that products never overflow, products out of range
+// of 14 integer digits are clamped to it
+func (d Decimal) MulRatio(num int64, den int64) Decimal {
+	if den == 0 {
+		return 0
+	}
+	p := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(num))
+	negative := (p.Sign() < 0) != (den < 0)
+	p.Abs(p)
+	divisor := new(big.Int).Abs(big.NewInt(den))
+
+	// round half away from zero
+	q, r := new(big.Int).QuoRem(p, divisor, new(big.Int))
+	if r.Lsh(r, 1).Cmp(divisor) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() || q.Int64() > int64(decimalMax) {
+		q.SetInt64(int64(decimalMax))
+	}
+	if negative && q.Sign() != 0 {
+		q.Neg(q)
+	}
+	return Decimal(q.Int64())
+}
+
+// decimal is written as json number with exact digits
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// json number or string is parsed without float conversion
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	// exponent of json number, e.g. 1e3, is parsed as float
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.Wrapf(ErrConversion, "decimal %q", s)
+		}
+		v, err := DecimalFromFloat(f)
+		if err != nil {
+			return err
+		}
+		*d = v
+		return nil
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// currency of values if none is given, galactic credit standard
+const DefaultCurrency = "GCR"
+
+// currency code of 3 latin capital letters
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // all application domain level errors stored here
 // TODO: make all errors for domain level
@@ -13,4 +18,103 @@ var (
 	ErrConfig            = errors.New("config error")
 	ErrPasswordWrong     = errors.New("password wrong")
 	ErrRePasswordWrong   = errors.New("password and repeat password not equal")
+	ErrRoleWrong         = errors.New("role is unknown")
+	ErrAuthFailed        = errors.New("email or password wrong")
+	ErrLoginLocked       = errors.New("too many failed logins")
+	ErrTokenInvalid      = errors.New("token is invalid or expired")
+	ErrEmailNotVerified  = errors.New("email is not verified")
+	ErrMFACodeInvalid    = errors.New("mfa code is invalid")
+	ErrMFAEnabled        = errors.New("mfa is already enabled")
+	ErrMFANotEnabled     = errors.New("mfa is not enabled")
+	ErrMFARequired       = errors.New("mfa is required for role")
+	ErrForbidden         = errors.New("forbidden")
+	ErrAPIKeyInvalid     = errors.New("api key is invalid or expired")
+	ErrScopeWrong        = errors.New("scope is unknown")
+	ErrScopeMissing      = errors.New("scope is not granted")
+	ErrExpiryWrong       = errors.New("expiry must be in future")
+	ErrTenantRequired    = errors.New("organization is not selected")
+	ErrNotMember         = errors.New("not a member of organization")
+	ErrOrgExists         = errors.New("organization exists")
+	ErrOrgAdminRequired  = errors.New("organization must have an admin")
+	ErrSpaceshipExists   = errors.New("spaceship with this name exists")
+	ErrImageRequired     = errors.New("image file is required")
+	ErrImageType         = errors.New("image must be jpeg, png or webp")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrImageSize         = errors.New("image size is unknown")
+	ErrSearchQuery       = errors.New("search query is invalid")
+	ErrRankWrong         = errors.New("rank is unknown")
+	ErrServiceNumber     = errors.New("service number is required")
+	ErrServiceNumberUsed = errors.New("crew member with this service number exists")
+	ErrCrewDischarged    = errors.New("crew member is discharged")
+	ErrCrewAssigned      = errors.New("crew member is already assigned to spaceship")
+	ErrCrewComplete      = errors.New("spaceship crew is complete")
+	ErrCrewBelowRoster   = errors.New("crew is less than assigned crew members")
+	ErrUnderCrewed       = errors.New("crew is below minimum of class for operational spaceship")
+	ErrSeverityWrong     = errors.New("severity is unknown")
+	ErrDescriptionEmpty  = errors.New("description is required")
+	ErrPartUnknown       = errors.New("part is not in armament catalog")
+	ErrWorkOrderStatus   = errors.New("work order status is unknown")
+	ErrWorkOrderMove     = errors.New("work order can't move to status")
+	ErrWorkOrderClosed   = errors.New("work order is done")
+	ErrWorkOrdersOpen    = errors.New("spaceship has open work orders")
+	ErrCostWrong         = errors.New("estimated cost can't be negative")
+	ErrShipClassUnknown  = errors.New("spaceship class is unknown")
+	ErrShipClassExists   = errors.New("spaceship class with this name or alias exists")
+	ErrShipClassInUse    = errors.New("spaceship class is used by spaceships")
+	ErrValueWrong        = errors.New("value can't be negative")
+	ErrCrewRange         = errors.New("crew is out of range of class")
+	ErrArmamentLimit     = errors.New("armament exceeds limits of class")
+	ErrReportGroup       = errors.New("report can't be grouped by field")
+	ErrReportWindow      = errors.New("report time window is invalid")
+	ErrPatchInvalid      = errors.New("patch is invalid")
+	ErrPatchTest         = errors.New("patch test failed")
+	ErrIdempotencyKey    = errors.New("idempotency key is invalid")
+	ErrIdempotencyReused = errors.New("idempotency key is used by another request")
+	ErrIdempotencyBusy   = errors.New("request with idempotency key is in progress")
+	ErrBatchSize         = errors.New("batch size is out of limits")
+	ErrBatchOperation    = errors.New("batch operation is unknown")
+	ErrBatchAborted      = errors.New("operation is rolled back with batch")
+	ErrGraphQLQuery      = errors.New("graphql query is required")
+	ErrQueryLimit        = errors.New("query exceeds limits")
+	ErrNotAcceptable     = errors.New("none of accepted media types is available")
+	ErrCurrencyWrong     = errors.New("currency code is invalid")
+	ErrDepreciation      = errors.New("depreciation schedule is invalid")
+	ErrAppraisalDate     = errors.New("appraisal date can't be in future")
+	ErrPositionWrong     = errors.New("position is invalid")
+	ErrProximityQuery    = errors.New("proximity query is invalid")
+	ErrGalaxyInvalid     = errors.New("galaxy of star systems and hyperlanes is invalid")
+	ErrSystemUnknown     = errors.New("star system is unknown")
+	ErrLaneUnknown       = errors.New("hyperlane is unknown")
+	ErrRouteMode         = errors.New("route mode is unknown")
+	ErrRouteOrigin       = errors.New("spaceship is not in a known star system")
+	ErrNoRoute           = errors.New("destination can't be reached by open hyperlanes")
+	ErrHyperdriveWrong   = errors.New("hyperdrive rating can't be negative")
+	ErrClosureWrong      = errors.New("hyperlane closure is invalid")
+
+	// missions of spaceships
+	ErrObjectiveWrong     = errors.New("objective of mission is empty or too long")
+	ErrMissionWindow      = errors.New("window of mission is invalid")
+	ErrMissionSpaceships  = errors.New("mission needs assigned spaceships")
+	ErrFirepowerWrong     = errors.New("firepower can't be negative")
+	ErrMissionStatus      = errors.New("status of mission is unknown")
+	ErrMissionMove        = errors.New("mission can't be moved to status")
+	ErrMissionClosed      = errors.New("mission can be changed only while it is planned")
+	ErrMissionConflict    = errors.New("spaceship is booked by mission in overlapping window")
+	ErrSpaceshipDamaged   = errors.New("damaged spaceship can't be sent on mission")
+	ErrMissionCapability  = errors.New("spaceship doesn't meet requirements of mission")
+	ErrSpaceshipOnMission = errors.New("spaceship is on active mission")
 )
+
+// error of operation which can be retried later
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Err, e.After.Round(time.Second))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,21 @@
+package domain
+
+// response of request with idempotency key, which is replayed on retry
+type IdempotencyRecord struct {
+	// user or api key which sent request
+	Owner string
+	Key   string
+	// hash of method, path, organization and body of request
+	Fingerprint string
+	// zero while request is in flight
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   int64
+	ExpiresAt   int64
+}
+
+// response of request is stored
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
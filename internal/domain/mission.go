@@ -0,0 +1,94 @@
+package domain
+
+import "strings"
+
+// custom type for status of mission
+type MissionStatus uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	MissionStatusUndefined MissionStatus = iota
+	MissionStatusPlanned
+	MissionStatusActive
+	MissionStatusCompleted
+	MissionStatusAborted
+)
+
+// convert status to string value
+func (s MissionStatus) String() string {
+	return [...]string{
+		"Undefined",
+		"Planned",
+		"Active",
+		"Completed",
+		"Aborted",
+	}[s]
+}
+
+func MissionStatusFromString(s string) MissionStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "planned":
+		return MissionStatusPlanned
+	case "active":
+		return MissionStatusActive
+	case "completed":
+		return MissionStatusCompleted
+	case "aborted":
+		return MissionStatusAborted
+	default:
+		return MissionStatusUndefined
+	}
+}
+
+// mission moves forward only, it may be aborted until it is completed
+func (s MissionStatus) CanMoveTo(next MissionStatus) bool {
+	switch s {
+	case MissionStatusPlanned:
+		return next == MissionStatusActive || next == MissionStatusAborted
+	case MissionStatusActive:
+		return next == MissionStatusCompleted || next == MissionStatusAborted
+	default:
+		return false
+	}
+}
+
+// planned and active missions book their spaceships
+func (s MissionStatus) Open() bool {
+	return s == MissionStatusPlanned || s == MissionStatusActive
+}
+
+// capabilities required from every assigned spaceship, besides it must be operational
+type MissionRequirements struct {
+	// firepower of armament rated by damage of class catalog
+	MinFirepower float64
+	MinCrew      uint
+}
+
+// mission of spaceships of organization within time window
+type Mission struct {
+	ID uint
+	// organization of mission, set by repository
+	TenantID  uint
+	Objective string
+	Status    MissionStatus
+	// window from starts at until ends at, spaceships can't be booked by overlapping windows,
+	// end is exclusive, so back to back missions don't overlap
+	StartsAt     int64
+	EndsAt       int64
+	SpaceshipIDs []uint
+	Requirements MissionRequirements
+	CreatedAt    int64
+	StartedAt    int64
+	ClosedAt     int64
+}
+
+// filter of missions, empty fields are not applied
+type MissionFilter struct {
+	SpaceshipID uint
+	Status      MissionStatus
+	// missions which are planned or active, status is ignored
+	Open bool
+	// missions which windows overlap window from until to
+	From int64
+	To   int64
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "strings"
+
+// organization which owns spaceships of existing deployments
+const DefaultOrganizationName = "Imperial Navy"
+
+// custom type for role of member in organization
+type OrgRole uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	OrgRoleUndefined OrgRole = iota
+	// can read records of organization
+	OrgRoleViewer
+	// can also change records of organization
+	OrgRoleMember
+	// can also manage members of organization
+	OrgRoleAdmin
+)
+
+// convert role to string value
+func (r OrgRole) String() string {
+	return [...]string{
+		"Undefined",
+		"Viewer",
+		"Member",
+		"Admin",
+	}[r]
+}
+
+func OrgRoleFromString(s string) OrgRole {
+	switch strings.ToLower(s) {
+	case "viewer":
+		return OrgRoleViewer
+	case "member":
+		return OrgRoleMember
+	case "admin":
+		return OrgRoleAdmin
+	default:
+		return OrgRoleUndefined
+	}
+}
+
+// check if role grants permissions of required role
+func (r OrgRole) Allows(required OrgRole) bool {
+	return r >= required
+}
+
+// tenant which owns spaceships and all related records
+type Organization struct {
+	ID        uint
+	Name      string
+	CreatedAt int64
+}
+
+// membership of user in organization
+type Membership struct {
+	OrgID  uint
+	UserID uint
+	Role   OrgRole
+	// filled on read for listings
+	OrgName   string
+	UserEmail string
+	CreatedAt int64
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "math"
+
+// point of galactic map, coordinates are in parsecs from galactic core
+type Coordinates struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+func (c Coordinates) Distance(to Coordinates) float64 {
+	return math.Sqrt((c.X-to.X)*(c.X-to.X) + (c.Y-to.Y)*(c.Y-to.Y) + (c.Z-to.Z)*(c.Z-to.Z))
+}
+
+// coordinates of galactic map are at most this far from galactic core by every axis
+const GalaxyExtent = 1e6
+
+// all coordinates are finite numbers within galaxy extent
+func (c Coordinates) Valid() bool {
+	for _, v := range []float64{c.X, c.Y, c.Z} {
+		if math.IsNaN(v) || math.Abs(v) > GalaxyExtent {
+			return false
+		}
+	}
+	return true
+}
+
+// reported position of spaceship, log of spaceship is ordered by date
+type Position struct {
+	ID          uint
+	SpaceshipID uint
+	Sector      string
+	// star system, empty in deep space
+	System string
+	// nil if only sector is known
+	Coordinates *Coordinates
+	ReportedAt  int64
+}
+
+// spaceships within radius of point, the nearest first
+type ProximityQuery struct {
+	Point  Coordinates
+	Radius float64
+	Limit  int
+}
+
+type ProximityHit struct {
+	Spaceship *Spaceship
+	Distance  float64
+}
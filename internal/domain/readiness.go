@@ -0,0 +1,53 @@
+package domain
+
+// data readiness of spaceship is scored from
+type ReadinessInput struct {
+	Spaceship *Spaceship
+	// nil if class of spaceship is out of catalog
+	Class *ShipClass
+	// number of crew members assigned to spaceship
+	Roster int
+	// open work orders of spaceship
+	Orders []*WorkOrder
+	Now    int64
+}
+
+// part of readiness score
+type ReadinessFactor struct {
+	Name string
+	// relative weight of factor in score
+	Weight float64
+	// score of factor from 0 to 1
+	Score float64
+	// points of factor in total score
+	Points      float64
+	Explanation string
+}
+
+// combat readiness of spaceship from 0 to 100 with breakdown by factors
+type Readiness struct {
+	SpaceshipID uint
+	Score       float64
+	// level of score, e.g. ready
+	Level   string
+	Factors []ReadinessFactor
+}
+
+// readiness of spaceships of one class, class is empty for spaceships out of catalog
+type ClassReadiness struct {
+	Class      string
+	Spaceships int
+	Score      float64
+}
+
+// readiness aggregated over fleet
+type FleetReadiness struct {
+	Spaceships int
+	// average score of spaceships
+	Score float64
+	// count of spaceships by level
+	Levels  map[string]int
+	Classes []ClassReadiness
+	// the least ready spaceship
+	Weakest *Readiness
+}
@@ -0,0 +1,93 @@
+package domain
+
+// custom type for action of spaceship audit
+type SpaceshipAuditAction uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	SpaceshipAuditActionUndefined SpaceshipAuditAction = iota
+	SpaceshipAuditActionCreated
+	SpaceshipAuditActionUpdated
+	SpaceshipAuditActionDeleted
+)
+
+// fields reports are grouped by
+const (
+	ReportGroupStatus   = "status"
+	ReportGroupClass    = "class"
+	ReportGroupArmament = "armament"
+)
+
+// filter of report, spaceships changed within time window are counted,
+// zero bounds are not applied
+type ReportQuery struct {
+	Group []string
+	From  int64
+	To    int64
+}
+
+// check if report is grouped by field
+func (q ReportQuery) GroupedBy(field string) bool {
+	for _, g := range q.Group {
+		if g == field {
+			return true
+		}
+	}
+	return false
+}
+
+// count and value of spaceships, fields out of grouping are empty,
+// values of different currencies are in different rows
+type FleetReportRow struct {
+	Status   SpaceshipStatus
+	Class    string
+	Currency string
+	Count    int64
+	Value    Decimal
+	Crew     int64
+}
+
+// armament inventory, class is empty if report is not grouped by class
+type ArmamentReportRow struct {
+	Title      string
+	Class      string
+	Qty        int64
+	Spaceships int64
+}
+
+// operational spaceships of all spaceships
+type ReadinessReportRow struct {
+	Class       string
+	Total       int64
+	Operational int64
+}
+
+// readiness in percents
+func (r *ReadinessReportRow) Percent() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Operational) * 100 / float64(r.Total)
+}
+
+// daily metrics of organization fleet, kept for trends
+type FleetSnapshot struct {
+	TenantID uint
+	// start of day in utc
+	Day         int64
+	Spaceships  int64
+	Operational int64
+	Damaged     int64
+	// value of spaceships in default currency
+	Value   Decimal
+	Crew    int64
+	TakenAt int64
+}
+
+// readiness in percents
+func (s *FleetSnapshot) Readiness() float64 {
+	if s.Spaceships == 0 {
+		return 0
+	}
+	return float64(s.Operational) * 100 / float64(s.Spaceships)
+}
@@ -0,0 +1,113 @@
+package domain
+
+import "strings"
+
+// custom type for optimization of route
+type RouteMode uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	RouteModeUndefined RouteMode = iota
+	// the least distance
+	RouteModeShortest
+	// the least travel time with hyperdrive of spaceship
+	RouteModeFastest
+)
+
+func (m RouteMode) String() string {
+	return [...]string{
+		"undefined",
+		"shortest",
+		"fastest",
+	}[m]
+}
+
+func RouteModeFromString(s string) RouteMode {
+	switch strings.ToLower(s) {
+	case "shortest":
+		return RouteModeShortest
+	case "fastest":
+		return RouteModeFastest
+	default:
+		return RouteModeUndefined
+	}
+}
+
+// rating of hyperdrive for classes without one, lower rating is faster
+const DefaultHyperdriveRating = 2
+
+// star system of galactic map
+type StarSystem struct {
+	Name        string
+	Sector      string
+	Coordinates Coordinates
+}
+
+// hyperlane between two systems, travelled both ways
+type Hyperlane struct {
+	From string
+	To   string
+	// parsecs
+	Length float64
+	// parsecs per hour with hyperdrive of rating 1
+	Speed float64
+	// lane is closed by active closure
+	Closed bool
+}
+
+// graph of star systems and hyperlanes
+type Galaxy struct {
+	Systems []StarSystem
+	Lanes   []Hyperlane
+	// hours of navigation computations before every jump
+	JumpHours float64
+	// date of uploaded galaxy, zero for galaxy of data file
+	UploadedAt int64
+	// incremented by every upload, zero for galaxy of data file
+	Version int64
+}
+
+// closure of hyperlane, e.g. blockade, lane is open again after until unless it is zero
+type HyperlaneClosure struct {
+	ID        uint
+	From      string
+	To        string
+	Reason    string
+	Until     int64
+	CreatedAt int64
+}
+
+// closure is active at time
+func (c *HyperlaneClosure) Active(at int64) bool {
+	return c.Until == 0 || c.Until > at
+}
+
+type RouteQuery struct {
+	From string
+	To   string
+	Mode RouteMode
+	// hyperdrive rating of spaceship
+	Rating float64
+	// lanes of active closures are not travelled
+	Closures []*HyperlaneClosure
+}
+
+// jump along one hyperlane
+type RouteJump struct {
+	From     string
+	To       string
+	Distance float64
+	Hours    float64
+}
+
+type Route struct {
+	SpaceshipID uint
+	From        string
+	To          string
+	Mode        RouteMode
+	Rating      float64
+	Distance    float64
+	Hours       float64
+	// jumps from origin, empty if spaceship is in destination system
+	Jumps []RouteJump
+}
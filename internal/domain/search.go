@@ -0,0 +1,9 @@
+package domain
+
+// spaceship found by search with relevance
+type SearchHit struct {
+	Spaceship *Spaceship
+	Score     float64
+	// matched values by field, matched words are wrapped in <em>
+	Highlights map[string][]string
+}
@@ -0,0 +1,38 @@
+package domain
+
+// non-login identity of automation, it is a member of one organization
+// and acts with api keys issued to it, so it does not depend on users who created it
+type ServiceAccount struct {
+	ID    uint
+	OrgID uint
+	Name  string
+	Role  OrgRole
+	// filled on read
+	OrgName string
+	// admin who created account, kept for audit only
+	CreatedBy uint
+	CreatedAt int64
+	// zero if account is active, keys of revoked account are not accepted
+	RevokedAt int64
+}
+
+// membership of account in its organization
+func (a *ServiceAccount) Membership() *Membership {
+	return &Membership{
+		OrgID:     a.OrgID,
+		Role:      a.Role,
+		OrgName:   a.OrgName,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// service account can read or change records of organization, but can't manage it
+func ServiceAccountRole(role OrgRole) bool {
+	return role == OrgRoleViewer || role == OrgRoleMember
+}
+
+// model of service account creation request
+type ServiceAccountCreateReq struct {
+	Name string
+	Role OrgRole
+}
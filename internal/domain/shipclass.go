@@ -0,0 +1,155 @@
+package domain
+
+import "strings"
+
+// armament of class with default qty of loadout and max qty allowed on spaceship
+type ShipClassArmament struct {
+	Title string
+	Qty   uint
+	Max   uint
+	// damage rating of one unit, used for firepower
+	Damage float64
+}
+
+// spaceship class of catalog shared by all organizations
+type ShipClass struct {
+	ID   uint
+	Name string
+	// alternative names matched on normalization, e.g. abbreviations
+	Aliases []string
+	// default loadout, armament of spaceship is limited to these titles if it is not empty
+	Armament []ShipClassArmament
+	// crew below min is allowed for spaceship which is not operational
+	MinCrew uint
+	// zero for unlimited crew
+	MaxCrew   uint
+	BaseValue float64
+	// schedule of book value of spaceships
+	Depreciation Depreciation
+	// rating of hyperdrive, lower is faster, zero for default rating
+	Hyperdrive float64
+}
+
+// normalized key of class name or alias
+func ShipClassKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// names of class matched on normalization
+func (c *ShipClass) Keys() []string {
+	keys := []string{ShipClassKey(c.Name)}
+	for _, a := range c.Aliases {
+		keys = append(keys, ShipClassKey(a))
+	}
+	return keys
+}
+
+// armament of class by title, titles are case insensitive
+func (c *ShipClass) FindArmament(title string) (ShipClassArmament, bool) {
+	for _, a := range c.Armament {
+		if strings.EqualFold(a.Title, strings.TrimSpace(title)) {
+			return a, true
+		}
+	}
+	return ShipClassArmament{}, false
+}
+
+// firepower of armament rated by damage of class, armament out of class has no rating
+func (c *ShipClass) Firepower(armament []SpaceshipArmament) float64 {
+	firepower := 0.0
+	for _, a := range armament {
+		if rated, ok := c.FindArmament(a.Title); ok {
+			firepower += float64(a.Qty) * rated.Damage
+		}
+	}
+	return firepower
+}
+
+// default loadout of class for new spaceship
+func (c *ShipClass) DefaultArmament() []SpaceshipArmament {
+	armament := make([]SpaceshipArmament, 0, len(c.Armament))
+	for _, a := range c.Armament {
+		if a.Qty > 0 {
+			armament = append(armament, SpaceshipArmament{Title: a.Title, Qty: a.Qty})
+		}
+	}
+	return armament
+}
+
+// catalog which is seeded on first migration, shares of depreciation are decimals, e.g. 500 is 0.05
+func DefaultShipClasses() []*ShipClass {
+	return []*ShipClass{
+		{
+			Name:    "Star Destroyer",
+			Aliases: []string{"ISD", "Imperial Star Destroyer", "Imperial-class Star Destroyer"},
+			Armament: []ShipClassArmament{
+				{Title: "Turbo Laser", Qty: 60, Max: 60, Damage: 10},
+				{Title: "Ion Cannons", Qty: 60, Max: 60, Damage: 6},
+				{Title: "Tractor Beam", Qty: 10, Max: 10, Damage: 1},
+			},
+			MinCrew:   9000,
+			MaxCrew:   50000,
+			BaseValue: 1500,
+			Depreciation: Depreciation{
+				Method:  DepreciationStraightLine,
+				Rate:    500,
+				Salvage: 2000,
+				Damage:  3000,
+			},
+			Hyperdrive: 2,
+		},
+		{
+			Name:    "Super Star Destroyer",
+			Aliases: []string{"SSD", "Executor-class Star Dreadnought", "Star Dreadnought"},
+			Armament: []ShipClassArmament{
+				{Title: "Turbo Laser", Qty: 250, Max: 2000, Damage: 10},
+				{Title: "Ion Cannons", Qty: 250, Max: 2000, Damage: 6},
+				{Title: "Tractor Beam", Qty: 40, Max: 40, Damage: 1},
+			},
+			MinCrew:   50000,
+			MaxCrew:   300000,
+			BaseValue: 10000,
+			Depreciation: Depreciation{
+				Method:  DepreciationStraightLine,
+				Rate:    400,
+				Salvage: 2500,
+				Damage:  2500,
+			},
+			Hyperdrive: 2,
+		},
+		{
+			Name:    "Corvette",
+			Aliases: []string{"CR90", "CR90 Corvette", "Corellian Corvette"},
+			Armament: []ShipClassArmament{
+				{Title: "Turbo Laser", Qty: 6, Max: 6, Damage: 10},
+			},
+			MinCrew:   30,
+			MaxCrew:   165,
+			BaseValue: 350,
+			Depreciation: Depreciation{
+				Method:  DepreciationDecliningBalance,
+				Rate:    1200,
+				Salvage: 1000,
+				Damage:  4000,
+			},
+			Hyperdrive: 2,
+		},
+		{
+			Name:    "Lambda Shuttle",
+			Aliases: []string{"Lambda-class T-4a shuttle", "Lambda"},
+			Armament: []ShipClassArmament{
+				{Title: "Laser Cannon", Qty: 5, Max: 5, Damage: 3},
+			},
+			MinCrew:   1,
+			MaxCrew:   6,
+			BaseValue: 140,
+			Depreciation: Depreciation{
+				Method:  DepreciationDecliningBalance,
+				Rate:    1500,
+				Salvage: 1000,
+				Damage:  3500,
+			},
+			Hyperdrive: 1,
+		},
+	}
+}
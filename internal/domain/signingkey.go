@@ -0,0 +1,12 @@
+package domain
+
+// private key which signs auth tokens, kept by key store
+type SigningKey struct {
+	// key id used as kid header of tokens
+	ID string
+	// jwt algorithm, RS256 or EdDSA
+	Algorithm string
+	// pkcs8 pem encoded private key
+	PrivateKey []byte
+	CreatedAt  int64
+}
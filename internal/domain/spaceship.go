@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // custom type for spaceship status enum
 type SpaceshipStatus uint
 
@@ -21,7 +23,7 @@ func (s SpaceshipStatus) String() string {
 
 // TODO: redo enum
 func SpaceshipStatusFromString(s string) SpaceshipStatus {
-	switch s {
+	switch strings.ToLower(s) {
 	case "operational":
 		return SpaceshipStatusOperational
 	case "damaged":
@@ -40,14 +42,93 @@ type SpaceshipArmament struct {
 
 // main spaceship model
 type Spaceship struct {
-	ID        uint
-	Name      string
-	Class     string
-	Armament  []SpaceshipArmament
-	Crew      uint
-	Image     string
-	Value     float64
-	Status    SpaceshipStatus
+	ID uint
+	// organization which owns spaceship, set by repository
+	TenantID uint
+	Name     string
+	Class    string
+	// class of catalog, zero if class is not in catalog
+	ClassID  uint
+	Armament []SpaceshipArmament
+	Crew     uint
+	Image    string
+	Value    Decimal
+	Currency string
+	// date of the latest valuation, value is depreciated since it
+	ValuedAt int64
+	Status   SpaceshipStatus
+	// the latest reported position, nil if position was never reported
+	Position  *Position
 	CreatedAt int64
 	UpdatedAt int64
 }
+
+// partial update of spaceship, nil fields are absent and kept,
+// present fields are set even to zero values
+type SpaceshipUpdate struct {
+	Name    *string
+	Class   *string
+	ClassID *uint
+	// present armament replaces the whole armament
+	Armament *[]SpaceshipArmament
+	Crew     *uint
+	Image    *string
+	Value    *Decimal
+	Currency *string
+	Status   *SpaceshipStatus
+}
+
+// change of spaceship computed from its current state, e.g. json patch
+type SpaceshipPatch interface {
+	Update(*Spaceship) (*SpaceshipUpdate, error)
+}
+
+// update is patch which doesn't depend on current state
+func (u *SpaceshipUpdate) Update(*Spaceship) (*SpaceshipUpdate, error) {
+	return u, nil
+}
+
+// set present fields of spaceship, class changed without class id is resolved by name again
+func (u *SpaceshipUpdate) Apply(s *Spaceship) {
+	if u.Name != nil {
+		s.Name = *u.Name
+	}
+	if u.Class != nil && *u.Class != s.Class {
+		s.Class = *u.Class
+		s.ClassID = 0
+	}
+	if u.ClassID != nil {
+		s.ClassID = *u.ClassID
+	}
+	if u.Armament != nil {
+		s.Armament = append([]SpaceshipArmament{}, *u.Armament...)
+	}
+	if u.Crew != nil {
+		s.Crew = *u.Crew
+	}
+	if u.Image != nil {
+		s.Image = *u.Image
+	}
+	if u.Value != nil {
+		s.Value = *u.Value
+	}
+	if u.Currency != nil {
+		s.Currency = *u.Currency
+	}
+	if u.Status != nil {
+		s.Status = *u.Status
+	}
+}
+
+// filter of spaceships list, empty fields are not applied
+type SpaceshipFilter struct {
+	Name   string
+	Class  string
+	Status SpaceshipStatus
+	// sector and system of the latest position
+	Sector string
+	System string
+	// page of list ordered by id, spaceships after id and at most limit of them, all if limit is zero
+	AfterID uint
+	Limit   int
+}
@@ -1,12 +1,55 @@
 package domain
 
+import "strings"
+
+// custom type for user role enum
+type UserRole uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	UserRoleUndefined UserRole = iota
+	UserRoleUser
+	UserRoleOfficer
+	UserRoleAdmin
+)
+
+// convert role to string value
+func (r UserRole) String() string {
+	return [...]string{
+		"Undefined",
+		"User",
+		"Officer",
+		"Admin",
+	}[r]
+}
+
+func UserRoleFromString(s string) UserRole {
+	switch strings.ToLower(s) {
+	case "user":
+		return UserRoleUser
+	case "officer":
+		return UserRoleOfficer
+	case "admin":
+		return UserRoleAdmin
+	default:
+		return UserRoleUndefined
+	}
+}
+
 // simple model for user authorization
 type User struct {
-	ID        uint
-	Email     string
-	Password  string
-	CreatedAt int64
-	UpdatedAt int64
+	ID              uint
+	Email           string
+	Password        string
+	Role            UserRole
+	EmailVerifiedAt int64
+	// base32 totp secret, enrolment is pending until TOTPEnabledAt is set
+	TOTPSecret    string
+	TOTPEnabledAt int64
+	// time step of last accepted code, codes are not accepted twice
+	TOTPCounter int64
+	CreatedAt   int64
+	UpdatedAt   int64
 }
 
 // model of authorisation request
@@ -21,3 +64,69 @@ type UserRegisterReq struct {
 	Password   string
 	RePassword string
 }
+
+// model of user creation request made by operator
+type UserCreateReq struct {
+	Email    string
+	Password string
+	Role     UserRole
+}
+
+// custom type for user token purpose enum
+type UserTokenPurpose uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	UserTokenPurposeUndefined UserTokenPurpose = iota
+	UserTokenPurposePasswordReset
+	UserTokenPurposeEmailVerify
+	UserTokenPurposeRecoveryCode
+)
+
+// single-use token sent to user email or recovery code, only hash of token is stored,
+// recovery codes do not expire
+type UserToken struct {
+	ID        uint
+	UserID    uint
+	Purpose   UserTokenPurpose
+	Hash      string
+	ExpiresAt int64
+	UsedAt    int64
+	CreatedAt int64
+}
+
+// model of password reset request
+type PasswordResetReq struct {
+	Token      string
+	Password   string
+	RePassword string
+}
+
+// second factor step required after password auth
+type MFAChallenge uint
+
+const (
+	// user has no second factor and policy does not require it
+	MFAChallengeNone MFAChallenge = iota
+	// user must provide totp or recovery code
+	MFAChallengeVerify
+	// policy requires second factor which user must enrol first
+	MFAChallengeEnroll
+)
+
+// convert challenge to string value
+func (c MFAChallenge) String() string {
+	return [...]string{
+		"none",
+		"verify",
+		"enroll",
+	}[c]
+}
+
+// totp enrolment data for authenticator app
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+	// png image with qr code of URI
+	QRCode []byte
+}
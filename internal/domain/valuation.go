@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// custom type for source of spaceship valuation
+type ValuationSource uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	ValuationSourceUndefined ValuationSource = iota
+	// value of created spaceship, given or base value of class
+	ValuationSourceInitial
+	// value changed by update of spaceship
+	ValuationSourceManual
+	// appraisal recorded with its date
+	ValuationSourceAppraisal
+)
+
+func (s ValuationSource) String() string {
+	return [...]string{
+		"Undefined",
+		"Initial",
+		"Manual",
+		"Appraisal",
+	}[s]
+}
+
+func ValuationSourceFromString(s string) ValuationSource {
+	switch strings.ToLower(s) {
+	case "initial":
+		return ValuationSourceInitial
+	case "manual":
+		return ValuationSourceManual
+	case "appraisal":
+		return ValuationSourceAppraisal
+	default:
+		return ValuationSourceUndefined
+	}
+}
+
+// appraisal of spaceship value, history of spaceship is ordered by date
+type Valuation struct {
+	ID          uint
+	SpaceshipID uint
+	Amount      Decimal
+	Currency    string
+	Source      ValuationSource
+	Note        string
+	AppraisedAt int64
+}
+
+// custom type for method of depreciation
+type DepreciationMethod uint
+
+const (
+	// value is not depreciated
+	DepreciationNone DepreciationMethod = iota
+	// the same share of appraised value is lost every year
+	DepreciationStraightLine
+	// share of remaining value is lost every year
+	DepreciationDecliningBalance
+	// method which is not known, schedule with it is invalid
+	DepreciationUnknown
+)
+
+func (m DepreciationMethod) String() string {
+	if m > DepreciationUnknown {
+		m = DepreciationUnknown
+	}
+	return [...]string{
+		"none",
+		"straight_line",
+		"declining_balance",
+		"unknown",
+	}[m]
+}
+
+// empty method is none
+func DepreciationMethodFromString(s string) DepreciationMethod {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return DepreciationNone
+	case "straight_line":
+		return DepreciationStraightLine
+	case "declining_balance":
+		return DepreciationDecliningBalance
+	default:
+		return DepreciationUnknown
+	}
+}
+
+// days of year depreciation rates are given for
+const depreciationYearDays = 365
+
+// depreciation schedule of class, shares are decimals from 0 to 1
+type Depreciation struct {
+	Method DepreciationMethod
+	// share of value lost per year
+	Rate Decimal
+	// share of appraised value which is never depreciated
+	Salvage Decimal
+	// share of book value lost while spaceship is damaged
+	Damage Decimal
+}
+
+// depreciation of appraised value after days, value never drops below salvage share
+func (d Depreciation) Apply(value Decimal, days int64) Decimal {
+
+	if days <= 0 || value <= 0 {
+		return 0
+	}
+
+	remaining := value
+	switch d.Method {
+	case DepreciationStraightLine:
+		remaining -= value.Mul(d.Rate).MulRatio(days, depreciationYearDays)
+	case DepreciationDecliningBalance:
+		// full years are compounded, the rest of year is prorated
+		for year := int64(0); year < days/depreciationYearDays && remaining > 0; year++ {
+			remaining -= remaining.Mul(d.Rate)
+		}
+		remaining -= remaining.Mul(d.Rate).MulRatio(days%depreciationYearDays, depreciationYearDays)
+	default:
+		return 0
+	}
+
+	salvage := value.Mul(d.Salvage)
+	if remaining < salvage {
+		remaining = salvage
+	}
+	return value - remaining
+}
+
+// current value of spaceship, book value is depreciated from the latest appraisal
+type SpaceshipValuation struct {
+	SpaceshipID uint
+	Currency    string
+	Appraised   Decimal
+	AppraisedAt int64
+	// schedule of class, absent for spaceship out of catalog
+	Schedule *Depreciation
+	// depreciation since appraisal
+	Depreciation Decimal
+	// loss of damaged spaceship
+	DamageAdjustment Decimal
+	BookValue        Decimal
+	// appraisals from the oldest
+	History []*Valuation
+	At      int64
+}
+
+// book value of spaceship at time, class is nil for spaceship out of catalog
+func NewSpaceshipValuation(spaceship *Spaceship, class *ShipClass, at int64) *SpaceshipValuation {
+
+	v := &SpaceshipValuation{
+		SpaceshipID: spaceship.ID,
+		Currency:    spaceship.Currency,
+		Appraised:   spaceship.Value,
+		AppraisedAt: spaceship.ValuedAt,
+		At:          at,
+	}
+	if v.Currency == "" {
+		v.Currency = DefaultCurrency
+	}
+
+	if class != nil {
+		schedule := class.Depreciation
+		v.Schedule = &schedule
+		if spaceship.ValuedAt != 0 {
+			v.Depreciation = schedule.Apply(spaceship.Value, (at-spaceship.ValuedAt)/(24*60*60))
+		}
+		if spaceship.Status == SpaceshipStatusDamaged {
+			v.DamageAdjustment = (spaceship.Value - v.Depreciation).Mul(schedule.Damage)
+		}
+	}
+	v.BookValue = v.Appraised - v.Depreciation - v.DamageAdjustment
+
+	return v
+}
+
+// totals of spaceships valued in one currency
+type ValuationTotal struct {
+	Currency         string
+	Spaceships       int
+	Appraised        Decimal
+	Depreciation     Decimal
+	DamageAdjustment Decimal
+	BookValue        Decimal
+}
+
+// valuation of fleet, values of different currencies are never summed
+type FleetValuation struct {
+	// totals ordered by currency
+	Totals []ValuationTotal
+	At     int64
+}
+
+// sum valuations of spaceships by currency
+func NewFleetValuation(valuations []*SpaceshipValuation, at int64) *FleetValuation {
+
+	totals := map[string]*ValuationTotal{}
+	for _, v := range valuations {
+		t, ok := totals[v.Currency]
+		if !ok {
+			t = &ValuationTotal{Currency: v.Currency}
+			totals[v.Currency] = t
+		}
+		t.Spaceships++
+		t.Appraised += v.Appraised
+		t.Depreciation += v.Depreciation
+		t.DamageAdjustment += v.DamageAdjustment
+		t.BookValue += v.BookValue
+	}
+
+	fleet := &FleetValuation{Totals: make([]ValuationTotal, 0, len(totals)), At: at}
+	for _, t := range totals {
+		fleet.Totals = append(fleet.Totals, *t)
+	}
+	sort.Slice(fleet.Totals, func(i, j int) bool { return fleet.Totals[i].Currency < fleet.Totals[j].Currency })
+
+	return fleet
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// custom type for status of work order
+type WorkOrderStatus uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	WorkOrderStatusUndefined WorkOrderStatus = iota
+	WorkOrderStatusTriage
+	WorkOrderStatusInProgress
+	WorkOrderStatusDone
+)
+
+// convert status to string value
+func (s WorkOrderStatus) String() string {
+	return [...]string{
+		"Undefined",
+		"Triage",
+		"In Progress",
+		"Done",
+	}[s]
+}
+
+func WorkOrderStatusFromString(s string) WorkOrderStatus {
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "_", " ") {
+	case "triage":
+		return WorkOrderStatusTriage
+	case "in progress":
+		return WorkOrderStatusInProgress
+	case "done":
+		return WorkOrderStatusDone
+	default:
+		return WorkOrderStatusUndefined
+	}
+}
+
+// work order moves forward only, triage may be closed right away, e.g. on false alarm
+func (s WorkOrderStatus) CanMoveTo(next WorkOrderStatus) bool {
+	switch s {
+	case WorkOrderStatusTriage:
+		return next == WorkOrderStatusInProgress || next == WorkOrderStatusDone
+	case WorkOrderStatusInProgress:
+		return next == WorkOrderStatusDone
+	default:
+		return false
+	}
+}
+
+// custom type for severity of damage
+type WorkOrderSeverity uint
+
+const (
+	// since iota starts with 0, the first value reserved for undefined
+	WorkOrderSeverityUndefined WorkOrderSeverity = iota
+	WorkOrderSeverityLow
+	WorkOrderSeverityMedium
+	WorkOrderSeverityHigh
+	WorkOrderSeverityCritical
+)
+
+// convert severity to string value
+func (s WorkOrderSeverity) String() string {
+	return [...]string{
+		"Undefined",
+		"Low",
+		"Medium",
+		"High",
+		"Critical",
+	}[s]
+}
+
+func WorkOrderSeverityFromString(s string) WorkOrderSeverity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return WorkOrderSeverityLow
+	case "medium":
+		return WorkOrderSeverityMedium
+	case "high":
+		return WorkOrderSeverityHigh
+	case "critical":
+		return WorkOrderSeverityCritical
+	default:
+		return WorkOrderSeverityUndefined
+	}
+}
+
+// time to close work order of severity
+func (s WorkOrderSeverity) SLA() time.Duration {
+	switch s {
+	case WorkOrderSeverityCritical:
+		return 24 * time.Hour
+	case WorkOrderSeverityHigh:
+		return 3 * 24 * time.Hour
+	case WorkOrderSeverityMedium:
+		return 7 * 24 * time.Hour
+	default:
+		return 30 * 24 * time.Hour
+	}
+}
+
+// work order opened when spaceship is marked damaged without report
+const (
+	DefaultDamageSeverity    = WorkOrderSeverityMedium
+	DefaultDamageDescription = "Spaceship is marked damaged"
+)
+
+// part of armament catalog used for repair
+type WorkOrderPart struct {
+	Title string
+	Qty   uint
+}
+
+// repair ticket of damaged spaceship
+type WorkOrder struct {
+	ID uint
+	// organization of work order, set by repository
+	TenantID      uint
+	SpaceshipID   uint
+	Severity      WorkOrderSeverity
+	Status        WorkOrderStatus
+	Description   string
+	Yard          string
+	EstimatedCost float64
+	Parts         []WorkOrderPart
+	OpenedAt      int64
+	StartedAt     int64
+	ClosedAt      int64
+}
+
+// time when work order must be closed
+func (o *WorkOrder) DueAt() int64 {
+	return o.OpenedAt + int64(o.Severity.SLA()/time.Second)
+}
+
+// time work order is overdue at moment, zero if it is closed in time or not due yet
+func (o *WorkOrder) Overdue(now int64) time.Duration {
+	end := now
+	if o.ClosedAt != 0 {
+		end = o.ClosedAt
+	}
+	if end <= o.DueAt() {
+		return 0
+	}
+	return time.Duration(end-o.DueAt()) * time.Second
+}
+
+// filter of work orders, empty fields are not applied
+type WorkOrderFilter struct {
+	SpaceshipID uint
+	Status      WorkOrderStatus
+	// orders which are not done, status is ignored
+	Open bool
+}
+
+// work order which is not closed in time
+type WorkOrderBreach struct {
+	WorkOrder *WorkOrder
+	Overdue   time.Duration
+}
@@ -0,0 +1,71 @@
+package fleetctl
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// fleetctl config stored in user config dir
+type Config struct {
+	Server       string `json:"server"`
+	AuthToken    string `json:"auth_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// config path from FLEETCTL_CONFIG or user config dir
+func defaultConfigPath() string {
+	if path := os.Getenv("FLEETCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "fleetctl", "config.json")
+}
+
+// load config, absent file gives default config
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Server: defaultServer}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: read config", fleetctlErrorPrefix)
+	}
+
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: decode config", fleetctlErrorPrefix)
+	}
+
+	return cfg, nil
+}
+
+// save config readable by owner only since it contains tokens
+func saveConfig(path string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "%s: encode config", fleetctlErrorPrefix)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create config dir", fleetctlErrorPrefix)
+	}
+
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "%s: write config", fleetctlErrorPrefix)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package fleetctl is command line client of fleet api for operators
+package fleetctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/transport/rest/client"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	fleetctlErrorPrefix = "[fleetctl]"
+
+	// returned when command line can't be parsed, usage is already printed
+	ErrUsage = errors.New("usage error")
+
+	// returned when command requires login
+	ErrNotLoggedIn = errors.New("not logged in, run fleetctl login")
+)
+
+const usage = `Usage: fleetctl [-config file] [-server url] [-org id] <command> [arguments]
+
+Commands:
+  login -email [-password] [-otp]              authenticate and store token in config file,
+                                               password may be set with FLEETCTL_PASSWORD,
+                                               otp is totp or recovery code of account with mfa
+  ships list [-o format] [-name] [-class] [-status]
+                                               list spaceships, name is matched by substring
+  ships get [-o format] <id>                   show spaceship
+  ships create -f file                         create spaceship from yaml or json file
+  ships update -f file <id>                    replace spaceship with yaml or json file
+  ships delete <id>                            delete spaceship
+  ships apply -f file                          create or update spaceships by name from yaml file
+  orgs list [-o format]                        list organizations of user
+  search query [-o format] [-limit n] <query>  search spaceships, e.g. class:"Star Destroyer"
+                                               armament:"Ion Cannons" status:operational crew>5000
+  search reindex                               rebuild search index from database, admins only
+
+Spaceships of organization set with -org or FLEETCTL_ORG are managed,
+the first organization of user is used by default.
+
+Output formats: table (default), json, yaml
+`
+
+// fleetctl application
+type App struct {
+	out        io.Writer
+	httpClient *http.Client
+
+	configPath string
+	config     *Config
+	orgID      uint
+}
+
+// application builder
+func New(out io.Writer, httpClient *http.Client) *App {
+	return &App{
+		out:        out,
+		httpClient: httpClient,
+	}
+}
+
+// run command from arguments without program name
+func (a *App) Run(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("fleetctl")
+	configPath := fs.String("config", defaultConfigPath(), "config file with server and tokens")
+	server := fs.String("server", "", "api server url, overrides config")
+	org := fs.Uint("org", envUint("FLEETCTL_ORG"), "organization id")
+	fs.Usage = func() {
+		fmt.Fprint(a.out, usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	args = fs.Args()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+	a.configPath = *configPath
+	a.config = cfg
+	a.orgID = *org
+
+	if len(args) == 0 {
+		return a.usageError("command is required")
+	}
+
+	switch args[0] {
+	case "login":
+		return a.login(ctx, args[1:])
+	case "ships":
+		return a.ships(ctx, args[1:])
+	case "orgs":
+		return a.orgs(ctx, args[1:])
+	case "search":
+		return a.search(ctx, args[1:])
+	case "help":
+		fmt.Fprint(a.out, usage)
+		return nil
+	default:
+		return a.usageError("unknown command %q", args[0])
+	}
+}
+
+// print error with usage and return usage error
+func (a *App) usageError(format string, args ...interface{}) error {
+	fmt.Fprintf(a.out, format+"\n\n", args...)
+	fmt.Fprint(a.out, usage)
+	return ErrUsage
+}
+
+// create flag set which prints errors to app output
+func (a *App) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	return fs
+}
+
+// parse flags, flag errors are reported as usage errors
+func (a *App) parse(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return ErrUsage
+	}
+	return nil
+}
+
+// api client with stored tokens, refreshed tokens are saved to config
+func (a *App) client() *client.Client {
+	opts := []client.Option{
+		client.WithTokens(a.config.AuthToken, a.config.RefreshToken),
+		client.WithTokenHandler(func(tokens model.UserAuthRes) {
+			a.config.AuthToken = tokens.AuthToken
+			a.config.RefreshToken = tokens.RefreshToken
+			if err := saveConfig(a.configPath, a.config); err != nil {
+				fmt.Fprintln(a.out, err)
+			}
+		}),
+	}
+	if a.httpClient != nil {
+		opts = append(opts, client.WithHTTPClient(a.httpClient))
+	}
+	if a.orgID != 0 {
+		opts = append(opts, client.WithOrg(a.orgID))
+	}
+	return client.New(a.config.Server, opts...)
+}
+
+// api client for commands which require login
+func (a *App) authClient() (*client.Client, error) {
+	if a.config.AuthToken == "" {
+		return nil, ErrNotLoggedIn
+	}
+	return a.client(), nil
+}
+
+func (a *App) login(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("login")
+	email := fs.String("email", "", "user email")
+	password := fs.String("password", os.Getenv("FLEETCTL_PASSWORD"), "user password")
+	otp := fs.String("otp", "", "totp or recovery code")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *email == "" || *password == "" {
+		return a.usageError("email and password are required")
+	}
+
+	c := a.client()
+	res, err := c.Login(ctx, *email, *password)
+	if err != nil {
+		return err
+	}
+
+	switch res.MFA {
+	case "":
+	case "verify":
+		if *otp == "" {
+			return errors.New("second factor is required, pass code with -otp")
+		}
+		_, err = c.VerifyMFA(ctx, res.MFAToken, *otp)
+		if err != nil {
+			return err
+		}
+	case "enroll":
+		return errors.New("second factor is required for account, enrol authenticator with api first")
+	default:
+		return fmt.Errorf("unsupported mfa step %q", res.MFA)
+	}
+
+	fmt.Fprintf(a.out, "logged in to %s as %s\n", a.config.Server, *email)
+	return nil
+}
+
+// unsigned number from environment, zero if not set
+func envUint(name string) uint {
+	n, err := strconv.ParseUint(os.Getenv(name), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(n)
+}
@@ -0,0 +1,118 @@
+package fleetctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/resttest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const shipsYAML = `name: Devastator
+class: Star Destroyer
+armament:
+  - title: Turbo Laser
+    qty: 60
+  - title: Ion Cannons
+    qty: 60
+crew: 35000
+value: 1999.99
+status: operational
+---
+name: Avenger
+class: Star Destroyer
+crew: 37085
+status: damaged
+`
+
+func TestApp(t *testing.T) {
+
+	ctx := context.Background()
+
+	// api server backed by sqlite database with registered officer
+	server := resttest.NewServer(t, config.Get())
+	server.CreateUser(t, "piett@empire.gov", "123123", domain.UserRoleOfficer)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	shipsPath := filepath.Join(dir, "ships.yaml")
+	require.NoError(t, os.WriteFile(shipsPath, []byte(shipsYAML), 0o600))
+
+	out := new(bytes.Buffer)
+	run := func(args ...string) error {
+		out.Reset()
+		return New(out, server.Client()).Run(ctx, append([]string{"-config", configPath}, args...))
+	}
+
+	assert.ErrorIs(t, run("ships", "list"), ErrNotLoggedIn)
+	assert.ErrorIs(t, run("ships", "unknown"), ErrUsage)
+
+	// login stores server and tokens
+	require.NoError(t, run("-server", server.URL, "login", "-email", "piett@empire.gov", "-password", "123123"))
+	cfg, err := loadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, cfg.Server)
+	assert.NotEmpty(t, cfg.AuthToken)
+	assert.NotEmpty(t, cfg.RefreshToken)
+
+	// operational spaceship of catalog class is manned by crew members assigned to it
+	org, err := server.Org.GetByName(ctx, "piett@empire.gov")
+	require.NoError(t, err)
+	devastator := &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 35000, Status: domain.SpaceshipStatusDamaged}
+	require.NoError(t, server.Ship.CreateSpaceship(tenant.WithID(ctx, org.ID), devastator))
+	server.ManSpaceship(t, "piett@empire.gov", devastator.ID)
+
+	require.NoError(t, run("ships", "apply", "-f", shipsPath))
+	assert.Equal(t, "spaceship Devastator configured\nspaceship Avenger created\n", out.String())
+
+	require.NoError(t, run("ships", "apply", "-f", shipsPath))
+	assert.Equal(t, "spaceship Devastator configured\nspaceship Avenger configured\n", out.String())
+
+	require.NoError(t, run("ships", "list", "-status", "damaged"))
+	assert.Contains(t, out.String(), "Avenger")
+	assert.NotContains(t, out.String(), "Devastator")
+
+	require.NoError(t, run("ships", "list", "-o", "json"))
+	spaceships := []model.SpaceshipShort{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &spaceships))
+	require.Len(t, spaceships, 2)
+
+	require.NoError(t, run("ships", "get", "-o", "yaml", "1"))
+	spaceship := model.SpaceshipFull{}
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &spaceship))
+	assert.Equal(t, "Devastator", spaceship.Name)
+	assert.Len(t, spaceship.Armament, 2)
+
+	require.NoError(t, run("ships", "get", "1"))
+	assert.Contains(t, out.String(), "Turbo Laser x60")
+
+	require.NoError(t, run("ships", "delete", "2"))
+	require.NoError(t, run("ships", "list", "-o", "json"))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &spaceships))
+	assert.Len(t, spaceships, 1)
+
+	assert.ErrorIs(t, run("ships", "get", "abc"), ErrUsage)
+
+	require.NoError(t, run("search", "query", "armament:ion", "destroyer"))
+	assert.Contains(t, out.String(), "*Ion* Cannons")
+	assert.ErrorIs(t, run("search", "query"), ErrUsage)
+	assert.Error(t, run("search", "reindex"))
+
+	require.NoError(t, run("orgs", "list"))
+	assert.Contains(t, out.String(), "piett@empire.gov  admin")
+
+	// spaceships of organization which user is not member of are not reachable
+	server.CreateUser(t, "mothma@rebellion.org", "123123", domain.UserRoleOfficer)
+	assert.Error(t, run("-org", "2", "ships", "list"))
+}
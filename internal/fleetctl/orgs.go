@@ -0,0 +1,46 @@
+package fleetctl
+
+import (
+	"context"
+	"text/tabwriter"
+)
+
+func (a *App) orgs(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return a.usageError("orgs command is required")
+	}
+
+	switch args[0] {
+	case "list":
+		return a.orgsList(ctx, args[1:])
+	default:
+		return a.usageError("unknown orgs command %q", args[0])
+	}
+}
+
+func (a *App) orgsList(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("orgs list")
+	format := fs.String("o", formatTable, "output format: table, json or yaml")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	orgs, err := c.ListOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return render(a.out, *format, orgs, func(tw *tabwriter.Writer) {
+		row(tw, "ID", "NAME", "ROLE")
+		for _, o := range orgs {
+			row(tw, o.ID, o.Name, o.Role)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package fleetctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// write value in requested format, table is written by callback
+func render(w io.Writer, format string, v interface{}, table func(*tabwriter.Writer)) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		table(tw)
+		return tw.Flush()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		err := enc.Encode(v)
+		if err != nil {
+			return errors.Wrapf(err, "%s: encode yaml", fleetctlErrorPrefix)
+		}
+		return enc.Close()
+	default:
+		return errors.Wrapf(ErrUsage, "%s: unknown output format %q", fleetctlErrorPrefix, format)
+	}
+}
+
+// print table row with tab separated values
+func row(tw *tabwriter.Writer, values ...interface{}) {
+	for i, v := range values {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, v)
+	}
+	fmt.Fprintln(tw)
+}
@@ -0,0 +1,84 @@
+package fleetctl
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"text/tabwriter"
+)
+
+func (a *App) search(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return a.usageError("search command is required")
+	}
+
+	switch args[0] {
+	case "query":
+		return a.searchQuery(ctx, args[1:])
+	case "reindex":
+		return a.searchReindex(ctx)
+	default:
+		return a.usageError("unknown search command %q", args[0])
+	}
+}
+
+func (a *App) searchQuery(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("search query")
+	format := fs.String("o", formatTable, "output format: table, json or yaml")
+	limit := fs.Int("limit", 0, "max count of results")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return a.usageError("search query is required")
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	res, err := c.Search(ctx, strings.Join(fs.Args(), " "), *limit)
+	if err != nil {
+		return err
+	}
+
+	return render(a.out, *format, res, func(tw *tabwriter.Writer) {
+		row(tw, "ID", "NAME", "CLASS", "STATUS", "SCORE", "MATCHES")
+		for _, hit := range res.Data {
+			matches := []string{}
+			for _, field := range []string{"name", "class", "armament"} {
+				for _, value := range hit.Highlights[field] {
+					matches = append(matches, plainHighlight(value))
+				}
+			}
+			row(tw, hit.ID, hit.Name, hit.Class, hit.Status, fmt.Sprintf("%.2f", hit.Score), strings.Join(matches, ", "))
+		}
+	})
+}
+
+// rebuild index of server, e.g. after spaceships were imported with server cli
+func (a *App) searchReindex(ctx context.Context) error {
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	res, err := c.Reindex(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "%d spaceships indexed\n", res.Spaceships)
+	return nil
+}
+
+// highlight for terminal, matched words are marked with asterisks
+func plainHighlight(value string) string {
+	value = strings.NewReplacer("<em>", "*", "</em>", "*").Replace(value)
+	return html.UnescapeString(value)
+}
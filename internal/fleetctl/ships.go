@@ -0,0 +1,305 @@
+package fleetctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Je33/imperial_fleet/internal/transport/rest/client"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+func (a *App) ships(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return a.usageError("ships command is required")
+	}
+
+	switch args[0] {
+	case "list":
+		return a.shipsList(ctx, args[1:])
+	case "get":
+		return a.shipsGet(ctx, args[1:])
+	case "create":
+		return a.shipsCreate(ctx, args[1:])
+	case "update":
+		return a.shipsUpdate(ctx, args[1:])
+	case "delete":
+		return a.shipsDelete(ctx, args[1:])
+	case "apply":
+		return a.shipsApply(ctx, args[1:])
+	default:
+		return a.usageError("unknown ships command %q", args[0])
+	}
+}
+
+func (a *App) shipsList(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("ships list")
+	format := fs.String("o", formatTable, "output format: table, json or yaml")
+	name := fs.String("name", "", "filter by name substring")
+	class := fs.String("class", "", "filter by class")
+	status := fs.String("status", "", "filter by status: operational or damaged")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	spaceships, err := c.ListSpaceships(ctx, client.SpaceshipFilter{
+		Name:   *name,
+		Class:  *class,
+		Status: *status,
+	})
+	if err != nil {
+		return err
+	}
+
+	return render(a.out, *format, spaceships, func(tw *tabwriter.Writer) {
+		row(tw, "ID", "NAME", "STATUS")
+		for _, s := range spaceships {
+			row(tw, s.ID, s.Name, s.Status)
+		}
+	})
+}
+
+func (a *App) shipsGet(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("ships get")
+	format := fs.String("o", formatTable, "output format: table, json or yaml")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	id, err := a.idArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	spaceship, err := c.GetSpaceship(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return render(a.out, *format, spaceship, func(tw *tabwriter.Writer) {
+		armament := make([]string, 0, len(spaceship.Armament))
+		for _, arm := range spaceship.Armament {
+			armament = append(armament, fmt.Sprintf("%s x%d", arm.Title, arm.Qty))
+		}
+		row(tw, "ID", "NAME", "CLASS", "CREW", "VALUE", "STATUS", "ARMAMENT")
+		row(tw, spaceship.ID, spaceship.Name, spaceship.Class, spaceship.Crew, spaceship.Value, spaceship.Status, strings.Join(armament, ", "))
+	})
+}
+
+func (a *App) shipsCreate(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("ships create")
+	file := fs.String("f", "", "yaml or json file with spaceship, - for stdin")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	spaceships, err := a.readSpaceships(*file)
+	if err != nil {
+		return err
+	}
+	if len(spaceships) != 1 {
+		return a.usageError("file must contain exactly one spaceship")
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	err = c.CreateSpaceship(ctx, &spaceships[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "spaceship %s created\n", spaceships[0].Name)
+	return nil
+}
+
+func (a *App) shipsUpdate(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("ships update")
+	file := fs.String("f", "", "yaml or json file with spaceship, - for stdin")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	id, err := a.idArg(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	spaceships, err := a.readSpaceships(*file)
+	if err != nil {
+		return err
+	}
+	if len(spaceships) != 1 {
+		return a.usageError("file must contain exactly one spaceship")
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	err = c.UpdateSpaceship(ctx, id, &spaceships[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "spaceship %d updated\n", id)
+	return nil
+}
+
+func (a *App) shipsDelete(ctx context.Context, args []string) error {
+
+	id, err := a.idArg(args)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	err = c.DeleteSpaceship(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "spaceship %d deleted\n", id)
+	return nil
+}
+
+// declarative upsert, spaceships are matched by name
+func (a *App) shipsApply(ctx context.Context, args []string) error {
+
+	fs := a.flagSet("ships apply")
+	file := fs.String("f", "", "yaml or json file with one or more spaceships, - for stdin")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	spaceships, err := a.readSpaceships(*file)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.authClient()
+	if err != nil {
+		return err
+	}
+
+	for i := range spaceships {
+		spaceship := &spaceships[i]
+
+		existing, err := c.ListSpaceships(ctx, client.SpaceshipFilter{Name: spaceship.Name})
+		if err != nil {
+			return err
+		}
+
+		var id uint
+		for _, s := range existing {
+			if strings.EqualFold(s.Name, spaceship.Name) {
+				id = s.ID
+				break
+			}
+		}
+
+		if id == 0 {
+			err = c.CreateSpaceship(ctx, spaceship)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "spaceship %s created\n", spaceship.Name)
+			continue
+		}
+
+		err = c.UpdateSpaceship(ctx, id, spaceship)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "spaceship %s configured\n", spaceship.Name)
+	}
+
+	return nil
+}
+
+// parse single spaceship id argument
+func (a *App) idArg(args []string) (uint, error) {
+	if len(args) != 1 {
+		return 0, a.usageError("spaceship id is required")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, a.usageError("spaceship id %q is not a number", args[0])
+	}
+	return uint(id), nil
+}
+
+// read spaceships from json file or yaml file with one or more documents
+func (a *App) readSpaceships(path string) ([]model.SpaceshipFull, error) {
+
+	if path == "" {
+		return nil, a.usageError("file is required")
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: open file", fleetctlErrorPrefix)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	spaceships := []model.SpaceshipFull{}
+
+	// json uses api format where qty is a string
+	if filepath.Ext(path) == ".json" {
+		spaceship := model.SpaceshipFull{}
+		err := json.NewDecoder(r).Decode(&spaceship)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: decode json", fleetctlErrorPrefix)
+		}
+		return append(spaceships, spaceship), nil
+	}
+
+	dec := yaml.NewDecoder(r)
+	for {
+		spaceship := model.SpaceshipFull{}
+		err := dec.Decode(&spaceship)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: decode yaml", fleetctlErrorPrefix)
+		}
+		spaceships = append(spaceships, spaceship)
+	}
+
+	return spaceships, nil
+}
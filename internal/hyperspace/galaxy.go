@@ -0,0 +1,148 @@
+package hyperspace
+
+import (
+	_ "embed"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// errors prefix
+	hyperspaceErrorPrefix = "[hyperspace]"
+
+	//go:embed galaxy.yaml
+	defaultGalaxy []byte
+)
+
+// galaxy as it is written in yaml or json file
+type galaxyFile struct {
+	JumpHours float64 `yaml:"jump_hours"`
+	// speed of lanes without own speed
+	Speed   float64      `yaml:"speed"`
+	Systems []systemFile `yaml:"systems"`
+	Lanes   []laneFile   `yaml:"lanes"`
+}
+
+type systemFile struct {
+	Name   string  `yaml:"name"`
+	Sector string  `yaml:"sector"`
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Z      float64 `yaml:"z"`
+}
+
+type laneFile struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	// distance between systems if zero
+	Length float64 `yaml:"length"`
+	// speed of galaxy if zero
+	Speed float64 `yaml:"speed"`
+}
+
+// galaxy shipped with app
+func DefaultGalaxy() *domain.Galaxy {
+	galaxy, err := Parse(defaultGalaxy)
+	if err != nil {
+		panic(err)
+	}
+	return galaxy
+}
+
+// read and parse galaxy file
+func ReadFile(path string) (*domain.Galaxy, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: read galaxy", hyperspaceErrorPrefix)
+	}
+
+	galaxy, err := Parse(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", path)
+	}
+
+	return galaxy, nil
+}
+
+// parse and validate yaml or json galaxy
+func Parse(data []byte) (*domain.Galaxy, error) {
+
+	file := &galaxyFile{}
+	err := yaml.Unmarshal(data, file)
+	if err != nil {
+		return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: parse galaxy: %s", hyperspaceErrorPrefix, err)
+	}
+
+	if !finite(file.JumpHours) || file.JumpHours < 0 {
+		return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: jump hours can't be negative", hyperspaceErrorPrefix)
+	}
+	if file.Speed == 0 {
+		file.Speed = 1
+	}
+
+	galaxy := &domain.Galaxy{JumpHours: file.JumpHours}
+
+	systems := map[string]domain.StarSystem{}
+	for _, s := range file.Systems {
+		system := domain.StarSystem{
+			Name:        strings.TrimSpace(s.Name),
+			Sector:      strings.TrimSpace(s.Sector),
+			Coordinates: domain.Coordinates{X: s.X, Y: s.Y, Z: s.Z},
+		}
+		galaxy.Systems = append(galaxy.Systems, system)
+		systems[key(system.Name)] = system
+	}
+
+	for _, l := range file.Lanes {
+		lane := domain.Hyperlane{From: l.From, To: l.To, Length: l.Length, Speed: l.Speed}
+		from, ok := systems[key(l.From)]
+		if !ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane from unknown system %q", hyperspaceErrorPrefix, l.From)
+		}
+		to, ok := systems[key(l.To)]
+		if !ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane to unknown system %q", hyperspaceErrorPrefix, l.To)
+		}
+		// names of lanes are the same as names of their systems
+		lane.From, lane.To = from.Name, to.Name
+		if lane.Length == 0 {
+			lane.Length = from.Coordinates.Distance(to.Coordinates)
+		}
+		if lane.Speed == 0 {
+			lane.Speed = file.Speed
+		}
+		galaxy.Lanes = append(galaxy.Lanes, lane)
+	}
+
+	// graph validates the rest
+	_, err = New(galaxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return galaxy, nil
+}
+
+// key of system name, names are matched in any case
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// key of lane, lanes are travelled both ways
+func laneKey(from, to string) string {
+	a, b := key(from), key(to)
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,278 @@
+package hyperspace
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// immutable graph of galaxy, systems are matched by name in any case
+type Graph struct {
+	galaxy *domain.Galaxy
+	// index of system by key of name
+	systems map[string]int
+	// index of lane by key of its systems
+	lanes map[string]int
+	// indexes of lanes by index of system, ordered by name of neighbour
+	adjacent [][]int
+}
+
+// validate galaxy and build its graph
+func New(galaxy *domain.Galaxy) (*Graph, error) {
+
+	if !finite(galaxy.JumpHours) || galaxy.JumpHours < 0 {
+		return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: jump hours can't be negative", hyperspaceErrorPrefix)
+	}
+
+	g := &Graph{
+		galaxy:   galaxy,
+		systems:  make(map[string]int, len(galaxy.Systems)),
+		lanes:    make(map[string]int, len(galaxy.Lanes)),
+		adjacent: make([][]int, len(galaxy.Systems)),
+	}
+
+	for i, s := range galaxy.Systems {
+		if key(s.Name) == "" {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: system without name", hyperspaceErrorPrefix)
+		}
+		if _, ok := g.systems[key(s.Name)]; ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: system %q is duplicated", hyperspaceErrorPrefix, s.Name)
+		}
+		if !s.Coordinates.Valid() {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: system %q is out of galaxy", hyperspaceErrorPrefix, s.Name)
+		}
+		g.systems[key(s.Name)] = i
+	}
+
+	for i, l := range galaxy.Lanes {
+		from, ok := g.systems[key(l.From)]
+		if !ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane from unknown system %q", hyperspaceErrorPrefix, l.From)
+		}
+		to, ok := g.systems[key(l.To)]
+		if !ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane to unknown system %q", hyperspaceErrorPrefix, l.To)
+		}
+		if from == to {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane of %q leads to itself", hyperspaceErrorPrefix, l.From)
+		}
+		if _, ok := g.lanes[laneKey(l.From, l.To)]; ok {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: lane %q - %q is duplicated", hyperspaceErrorPrefix, l.From, l.To)
+		}
+		if !finite(l.Length) || l.Length <= 0 {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: length of lane %q - %q must be positive", hyperspaceErrorPrefix, l.From, l.To)
+		}
+		if !finite(l.Speed) || l.Speed <= 0 {
+			return nil, errors.Wrapf(domain.ErrGalaxyInvalid, "%s: speed of lane %q - %q must be positive", hyperspaceErrorPrefix, l.From, l.To)
+		}
+		g.lanes[laneKey(l.From, l.To)] = i
+		g.adjacent[from] = append(g.adjacent[from], i)
+		g.adjacent[to] = append(g.adjacent[to], i)
+	}
+
+	// neighbours are visited in the same order whatever order of file is
+	for i := range g.adjacent {
+		lanes := g.adjacent[i]
+		sort.Slice(lanes, func(a, b int) bool {
+			return key(g.neighbour(i, lanes[a]).Name) < key(g.neighbour(i, lanes[b]).Name)
+		})
+	}
+
+	return g, nil
+}
+
+func (g *Graph) Galaxy() *domain.Galaxy {
+	return g.galaxy
+}
+
+// system by name in any case
+func (g *Graph) System(name string) (*domain.StarSystem, bool) {
+	i, ok := g.systems[key(name)]
+	if !ok {
+		return nil, false
+	}
+	return &g.galaxy.Systems[i], true
+}
+
+// lane between systems in any direction
+func (g *Graph) Lane(from, to string) (*domain.Hyperlane, bool) {
+	i, ok := g.lanes[laneKey(from, to)]
+	if !ok {
+		return nil, false
+	}
+	return &g.galaxy.Lanes[i], true
+}
+
+// system on the other end of lane
+func (g *Graph) neighbour(system, lane int) *domain.StarSystem {
+	l := g.galaxy.Lanes[lane]
+	if g.systems[key(l.From)] == system {
+		return &g.galaxy.Systems[g.systems[key(l.To)]]
+	}
+	return &g.galaxy.Systems[g.systems[key(l.From)]]
+}
+
+// hours of jump along lane, including navigation computations
+func (g *Graph) hours(lane *domain.Hyperlane, rating float64) float64 {
+	return lane.Length*rating/lane.Speed + g.galaxy.JumpHours
+}
+
+// route of the least distance or travel time by open lanes,
+// routes of the same cost are resolved to the one with fewer jumps and then by names of systems
+func (g *Graph) Route(query domain.RouteQuery) (*domain.Route, error) {
+
+	from, ok := g.systems[key(query.From)]
+	if !ok {
+		return nil, errors.Wrapf(domain.ErrSystemUnknown, "%s: origin %q", hyperspaceErrorPrefix, query.From)
+	}
+	to, ok := g.systems[key(query.To)]
+	if !ok {
+		return nil, errors.Wrapf(domain.ErrSystemUnknown, "%s: destination %q", hyperspaceErrorPrefix, query.To)
+	}
+	if query.Mode != domain.RouteModeShortest && query.Mode != domain.RouteModeFastest {
+		return nil, errors.Wrapf(domain.ErrRouteMode, "%s: mode %s", hyperspaceErrorPrefix, query.Mode)
+	}
+	if !finite(query.Rating) || query.Rating <= 0 {
+		return nil, errors.Wrapf(domain.ErrHyperdriveWrong, "%s: rating must be positive", hyperspaceErrorPrefix)
+	}
+
+	closed := map[int]bool{}
+	for _, c := range query.Closures {
+		if i, ok := g.lanes[laneKey(c.From, c.To)]; ok {
+			closed[i] = true
+		}
+	}
+
+	cost := func(lane int) float64 {
+		l := &g.galaxy.Lanes[lane]
+		if query.Mode == domain.RouteModeShortest {
+			return l.Length
+		}
+		return g.hours(l, query.Rating)
+	}
+
+	costs := make([]float64, len(g.galaxy.Systems))
+	jumps := make([]int, len(g.galaxy.Systems))
+	// lane by which system is reached
+	via := make([]int, len(g.galaxy.Systems))
+	done := make([]bool, len(g.galaxy.Systems))
+	for i := range costs {
+		costs[i] = math.Inf(1)
+		via[i] = -1
+	}
+	costs[from] = 0
+
+	queue := &routeQueue{graph: g}
+	heap.Push(queue, routeStep{system: from})
+	for queue.Len() > 0 {
+		step := heap.Pop(queue).(routeStep)
+		if done[step.system] {
+			continue
+		}
+		done[step.system] = true
+		if step.system == to {
+			break
+		}
+		for _, lane := range g.adjacent[step.system] {
+			if closed[lane] {
+				continue
+			}
+			next := g.systems[key(g.neighbour(step.system, lane).Name)]
+			if done[next] {
+				continue
+			}
+			c := step.cost + cost(lane)
+			if c < costs[next] || (c == costs[next] && step.jumps+1 < jumps[next]) {
+				costs[next] = c
+				jumps[next] = step.jumps + 1
+				via[next] = lane
+				heap.Push(queue, routeStep{system: next, cost: c, jumps: step.jumps + 1})
+			}
+		}
+	}
+
+	if !done[to] {
+		return nil, errors.Wrapf(domain.ErrNoRoute, "%s: %q - %q", hyperspaceErrorPrefix, query.From, query.To)
+	}
+
+	route := &domain.Route{
+		From:   g.galaxy.Systems[from].Name,
+		To:     g.galaxy.Systems[to].Name,
+		Mode:   query.Mode,
+		Rating: query.Rating,
+		Jumps:  []domain.RouteJump{},
+	}
+
+	// jumps are collected from destination back to origin
+	for system := to; system != from; {
+		lane := &g.galaxy.Lanes[via[system]]
+		prev := g.systems[key(g.neighbour(system, via[system]).Name)]
+		hours := g.hours(lane, query.Rating)
+		route.Distance += lane.Length
+		route.Hours += hours
+		route.Jumps = append(route.Jumps, domain.RouteJump{
+			From:     g.galaxy.Systems[prev].Name,
+			To:       g.galaxy.Systems[system].Name,
+			Distance: round(lane.Length),
+			Hours:    round(hours),
+		})
+		system = prev
+	}
+	for i, j := 0, len(route.Jumps)-1; i < j; i, j = i+1, j-1 {
+		route.Jumps[i], route.Jumps[j] = route.Jumps[j], route.Jumps[i]
+	}
+	route.Distance = round(route.Distance)
+	route.Hours = round(route.Hours)
+
+	return route, nil
+}
+
+// round to hundredths
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// system reached with cost and count of jumps
+type routeStep struct {
+	system int
+	cost   float64
+	jumps  int
+}
+
+// priority queue of steps by cost, count of jumps and name of system
+type routeQueue struct {
+	graph *Graph
+	steps []routeStep
+}
+
+func (q *routeQueue) Len() int {
+	return len(q.steps)
+}
+
+func (q *routeQueue) Less(i, j int) bool {
+	a, b := q.steps[i], q.steps[j]
+	if a.cost != b.cost {
+		return a.cost < b.cost
+	}
+	if a.jumps != b.jumps {
+		return a.jumps < b.jumps
+	}
+	return key(q.graph.galaxy.Systems[a.system].Name) < key(q.graph.galaxy.Systems[b.system].Name)
+}
+
+func (q *routeQueue) Swap(i, j int) {
+	q.steps[i], q.steps[j] = q.steps[j], q.steps[i]
+}
+
+func (q *routeQueue) Push(x any) {
+	q.steps = append(q.steps, x.(routeStep))
+}
+
+func (q *routeQueue) Pop() any {
+	last := q.steps[len(q.steps)-1]
+	q.steps = q.steps[:len(q.steps)-1]
+	return last
+}
@@ -0,0 +1,237 @@
+package hyperspace
+
+import (
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slow straight lanes A - B - C and fast diagonal lanes A - D - C
+const diamond = `
+jump_hours: 1
+speed: 1
+systems:
+  - {name: A, sector: Core, x: 0, y: 0}
+  - {name: B, sector: Core, x: 10, y: 0}
+  - {name: C, sector: Rim, x: 20, y: 0}
+  - {name: D, sector: Rim, x: 10, y: 10}
+  - {name: E, sector: Rim, x: 100, y: 100}
+lanes:
+  - {from: A, to: B}
+  - {from: b, to: c}
+  - {from: A, to: D, speed: 10}
+  - {from: D, to: C, speed: 10}
+`
+
+func TestGraph_Route(t *testing.T) {
+
+	galaxy, err := Parse([]byte(diamond))
+	require.NoError(t, err)
+	graph, err := New(galaxy)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		query    domain.RouteQuery
+		systems  []string
+		distance float64
+		hours    float64
+		err      error
+	}{
+		{
+			name:     "shortest by straight lanes",
+			query:    domain.RouteQuery{From: "A", To: "c", Mode: domain.RouteModeShortest, Rating: 1},
+			systems:  []string{"A", "B", "C"},
+			distance: 20,
+			hours:    22,
+		},
+		{
+			name:     "fastest by diagonal lanes",
+			query:    domain.RouteQuery{From: "A", To: "C", Mode: domain.RouteModeFastest, Rating: 1},
+			systems:  []string{"A", "D", "C"},
+			distance: 28.28,
+			hours:    4.83,
+		},
+		{
+			name:     "fastest with slow hyperdrive",
+			query:    domain.RouteQuery{From: "A", To: "C", Mode: domain.RouteModeFastest, Rating: 2},
+			systems:  []string{"A", "D", "C"},
+			distance: 28.28,
+			hours:    7.66,
+		},
+		{
+			name: "fastest around closed lane",
+			query: domain.RouteQuery{From: "A", To: "C", Mode: domain.RouteModeFastest, Rating: 1,
+				Closures: []*domain.HyperlaneClosure{{From: "d", To: "a"}}},
+			systems:  []string{"A", "B", "C"},
+			distance: 20,
+			hours:    22,
+		},
+		{
+			name:    "origin is destination",
+			query:   domain.RouteQuery{From: "B", To: "B", Mode: domain.RouteModeShortest, Rating: 1},
+			systems: []string{"B"},
+		},
+		{
+			name: "failed all lanes of origin are closed",
+			query: domain.RouteQuery{From: "A", To: "C", Mode: domain.RouteModeShortest, Rating: 1,
+				Closures: []*domain.HyperlaneClosure{{From: "A", To: "B"}, {From: "A", To: "D"}}},
+			err: domain.ErrNoRoute,
+		},
+		{
+			name:  "failed system without lanes",
+			query: domain.RouteQuery{From: "A", To: "E", Mode: domain.RouteModeShortest, Rating: 1},
+			err:   domain.ErrNoRoute,
+		},
+		{
+			name:  "failed unknown destination",
+			query: domain.RouteQuery{From: "A", To: "Z", Mode: domain.RouteModeShortest, Rating: 1},
+			err:   domain.ErrSystemUnknown,
+		},
+		{
+			name:  "failed undefined mode",
+			query: domain.RouteQuery{From: "A", To: "C", Rating: 1},
+			err:   domain.ErrRouteMode,
+		},
+		{
+			name:  "failed without hyperdrive rating",
+			query: domain.RouteQuery{From: "A", To: "C", Mode: domain.RouteModeFastest},
+			err:   domain.ErrHyperdriveWrong,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		route, err := graph.Route(test.query)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+
+		systems := []string{route.From}
+		for _, j := range route.Jumps {
+			systems = append(systems, j.To)
+		}
+		assert.Equal(t, test.systems, systems)
+		assert.Equal(t, test.distance, route.Distance)
+		assert.Equal(t, test.hours, route.Hours)
+	}
+}
+
+func TestGraph_RouteTie(t *testing.T) {
+
+	// routes by B and by C are of the same length, the one by B is chosen whatever order of lanes is
+	galaxies := []string{`
+systems: [{name: A}, {name: B}, {name: C}, {name: D}]
+lanes:
+  - {from: A, to: B, length: 1}
+  - {from: B, to: D, length: 1}
+  - {from: A, to: C, length: 1}
+  - {from: C, to: D, length: 1}
+  - {from: A, to: D, length: 3}
+`, `
+systems: [{name: D}, {name: C}, {name: B}, {name: A}]
+lanes:
+  - {from: D, to: A, length: 3}
+  - {from: D, to: C, length: 1}
+  - {from: C, to: A, length: 1}
+  - {from: D, to: B, length: 1}
+  - {from: B, to: A, length: 1}
+`}
+
+	for _, data := range galaxies {
+		galaxy, err := Parse([]byte(data))
+		require.NoError(t, err)
+		graph, err := New(galaxy)
+		require.NoError(t, err)
+
+		route, err := graph.Route(domain.RouteQuery{From: "A", To: "D", Mode: domain.RouteModeShortest, Rating: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RouteJump{
+			{From: "A", To: "B", Distance: 1, Hours: 1},
+			{From: "B", To: "D", Distance: 1, Hours: 1},
+		}, route.Jumps)
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	testCases := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{
+			name: "success json",
+			data: `{"systems": [{"name": "A"}, {"name": "B", "x": 3, "y": 4}], "lanes": [{"from": "A", "to": "B"}]}`,
+		},
+		{
+			name: "failed not yaml",
+			data: `systems: {`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed duplicated system",
+			data: `systems: [{name: A}, {name: a}]`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed lane to unknown system",
+			data: `{systems: [{name: A}], lanes: [{from: A, to: B, length: 1}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed lane to itself",
+			data: `{systems: [{name: A}], lanes: [{from: A, to: a, length: 1}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed duplicated lane",
+			data: `{systems: [{name: A}, {name: B}], lanes: [{from: A, to: B, length: 1}, {from: B, to: A, length: 2}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed lane between systems in the same place",
+			data: `{systems: [{name: A}, {name: B}], lanes: [{from: A, to: B}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed negative speed",
+			data: `{systems: [{name: A}, {name: B}], lanes: [{from: A, to: B, length: 1, speed: -1}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+		{
+			name: "failed negative jump hours",
+			data: `{jump_hours: -1, systems: [{name: A}]}`,
+			err:  domain.ErrGalaxyInvalid,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		_, err := Parse([]byte(test.data))
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestDefaultGalaxy(t *testing.T) {
+
+	planner, err := NewPlanner(DefaultGalaxy())
+	require.NoError(t, err)
+
+	// every system of shipped galaxy is reachable
+	for _, s := range planner.Galaxy().Systems {
+		_, err := planner.Plan(domain.RouteQuery{From: "Coruscant", To: s.Name, Mode: domain.RouteModeFastest, Rating: domain.DefaultHyperdriveRating})
+		assert.NoError(t, err, s.Name)
+	}
+}
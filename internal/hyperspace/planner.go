@@ -0,0 +1,62 @@
+package hyperspace
+
+import (
+	"sync"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// route planner on the latest loaded galaxy, safe for concurrent use
+type Planner struct {
+	mu    sync.RWMutex
+	graph *Graph
+}
+
+func NewPlanner(galaxy *domain.Galaxy) (*Planner, error) {
+	graph, err := New(galaxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Planner{graph: graph}, nil
+}
+
+// parse and validate yaml or json galaxy without loading it
+func (p *Planner) Parse(data []byte) (*domain.Galaxy, error) {
+	return Parse(data)
+}
+
+// replace galaxy, routes being planned are finished on the previous one
+func (p *Planner) Load(galaxy *domain.Galaxy) error {
+	graph, err := New(galaxy)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.graph = graph
+
+	return nil
+}
+
+func (p *Planner) Galaxy() *domain.Galaxy {
+	return p.current().Galaxy()
+}
+
+func (p *Planner) System(name string) (*domain.StarSystem, bool) {
+	return p.current().System(name)
+}
+
+func (p *Planner) Lane(from, to string) (*domain.Hyperlane, bool) {
+	return p.current().Lane(from, to)
+}
+
+func (p *Planner) Plan(query domain.RouteQuery) (*domain.Route, error) {
+	return p.current().Route(query)
+}
+
+func (p *Planner) current() *Graph {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.graph
+}
@@ -0,0 +1,201 @@
+// Package idempotency keeps responses of requests with idempotency keys,
+// so retried request gets the first response instead of running again
+package idempotency
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	idempotencyErrorPrefix = "[idempotency]"
+)
+
+// persistent storage of idempotency records
+type Store interface {
+	// create record unless there is unexpired record of owner and key,
+	// which is returned then
+	Reserve(context.Context, *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	// store response of reserved record
+	Complete(context.Context, *domain.IdempotencyRecord) error
+	// delete record which is in flight, so key can be used again
+	Release(ctx context.Context, owner string, key string) error
+	DeleteExpired(ctx context.Context, now int64) (int64, error)
+}
+
+type Config struct {
+	// time response is replayed
+	TTL time.Duration
+	// time retry waits for request in flight before it gives up
+	Wait time.Duration
+	// time request in flight holds key, so key is freed when instance dies
+	Lock time.Duration
+	// interval of store checks while retry waits for request of another instance
+	Poll time.Duration
+}
+
+// keeper of idempotency keys, requests in flight of this instance
+// wake up their retries when they are done
+type Keeper struct {
+	store  Store
+	config Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+func New(store Store, config Config) *Keeper {
+	if config.Lock < config.Wait {
+		config.Lock = config.Wait
+	}
+	if config.Lock == 0 {
+		config.Lock = time.Minute
+	}
+	if config.Poll == 0 {
+		config.Poll = 100 * time.Millisecond
+	}
+	return &Keeper{
+		store:    store,
+		config:   config,
+		now:      time.Now,
+		inflight: map[string]chan struct{}{},
+	}
+}
+
+// reserve key for request, nil record means request must run and be completed or released,
+// otherwise completed record of the same request is returned for replay
+func (k *Keeper) Begin(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+
+	deadline := k.now().Add(k.config.Wait)
+	for {
+		now := k.now()
+		record.StatusCode = 0
+		record.CreatedAt = now.Unix()
+		record.ExpiresAt = now.Add(k.config.Lock).Unix()
+
+		// done channel is created before reservation, so retry waits for it
+		done := k.track(record)
+		existing, err := k.store.Reserve(ctx, record)
+		if err != nil || existing != nil {
+			k.untrack(record, done)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, nil
+		}
+
+		if existing.Fingerprint != record.Fingerprint {
+			return nil, errors.Wrapf(domain.ErrIdempotencyReused, "%s: key %q", idempotencyErrorPrefix, record.Key)
+		}
+		if existing.Completed() {
+			return existing, nil
+		}
+
+		if !now.Before(deadline) {
+			return nil, &domain.RetryAfterError{
+				Err:   errors.Wrapf(domain.ErrIdempotencyBusy, "%s: key %q", idempotencyErrorPrefix, record.Key),
+				After: time.Second,
+			}
+		}
+		err = k.wait(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// store response of request, it is replayed until ttl ends
+func (k *Keeper) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	defer k.done(record)
+	record.ExpiresAt = k.now().Add(k.config.TTL).Unix()
+	return k.store.Complete(ctx, record)
+}
+
+// free key of failed request, so retry runs it again
+func (k *Keeper) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	defer k.done(record)
+	return k.store.Release(ctx, record.Owner, record.Key)
+}
+
+// delete expired records periodically until context is done
+func (k *Keeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := k.store.DeleteExpired(ctx, k.now().Unix())
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// wait until request in flight of this instance is done or next store check
+func (k *Keeper) wait(ctx context.Context, record *domain.IdempotencyRecord) error {
+	k.mu.Lock()
+	done := k.inflight[inflightKey(record)]
+	k.mu.Unlock()
+
+	timer := time.NewTimer(k.config.Poll)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "%s: wait for key %q", idempotencyErrorPrefix, record.Key)
+	case <-done:
+	case <-timer.C:
+	}
+	return nil
+}
+
+// channel which is closed when request is done, nil if another request of this instance holds key
+func (k *Keeper) track(record *domain.IdempotencyRecord) chan struct{} {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := inflightKey(record)
+	if _, ok := k.inflight[key]; ok {
+		return nil
+	}
+	done := make(chan struct{})
+	k.inflight[key] = done
+	return done
+}
+
+// forget channel of request which didn't get key
+func (k *Keeper) untrack(record *domain.IdempotencyRecord, done chan struct{}) {
+	if done == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.inflight, inflightKey(record))
+}
+
+// wake up retries waiting for request
+func (k *Keeper) done(record *domain.IdempotencyRecord) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key := inflightKey(record)
+	if done, ok := k.inflight[key]; ok {
+		close(done)
+		delete(k.inflight, key)
+	}
+}
+
+func inflightKey(record *domain.IdempotencyRecord) string {
+	return record.Owner + "\x00" + record.Key
+}
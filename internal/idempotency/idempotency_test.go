@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record(fingerprint string) *domain.IdempotencyRecord {
+	return &domain.IdempotencyRecord{Owner: "user:tarkin@empire.gov", Key: "key-1", Fingerprint: fingerprint}
+}
+
+func TestKeeper_Replay(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	k := New(NewMemoryStore(), Config{TTL: time.Hour, Wait: 0})
+	k.now = func() time.Time { return now }
+
+	// the first request runs
+	first := record("a")
+	replay, err := k.Begin(ctx, first)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+
+	first.StatusCode = http.StatusCreated
+	first.ContentType = "application/json"
+	first.Body = []byte(`{"success":true}`)
+	require.NoError(t, k.Complete(ctx, first))
+
+	// retry gets the first response
+	replay, err = k.Begin(ctx, record("a"))
+	require.NoError(t, err)
+	require.NotNil(t, replay)
+	assert.Equal(t, http.StatusCreated, replay.StatusCode)
+	assert.Equal(t, []byte(`{"success":true}`), replay.Body)
+
+	// key can't be used by another request
+	_, err = k.Begin(ctx, record("b"))
+	assert.ErrorIs(t, err, domain.ErrIdempotencyReused)
+
+	// expired key is used again
+	now = now.Add(time.Hour)
+	replay, err = k.Begin(ctx, record("b"))
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+}
+
+func TestKeeper_Release(t *testing.T) {
+
+	ctx := context.Background()
+	k := New(NewMemoryStore(), Config{TTL: time.Hour})
+
+	first := record("a")
+	replay, err := k.Begin(ctx, first)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+
+	// request in flight can't be run again
+	_, err = k.Begin(ctx, record("a"))
+	assert.ErrorIs(t, err, domain.ErrIdempotencyBusy)
+
+	// failed request is run again
+	require.NoError(t, k.Release(ctx, first))
+	replay, err = k.Begin(ctx, record("a"))
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+}
+
+func TestKeeper_WaitInFlight(t *testing.T) {
+
+	ctx := context.Background()
+	k := New(NewMemoryStore(), Config{TTL: time.Hour, Wait: 5 * time.Second, Poll: time.Hour})
+
+	first := record("a")
+	replay, err := k.Begin(ctx, first)
+	require.NoError(t, err)
+	require.Nil(t, replay)
+
+	// duplicates wait for the first request and are woken up without polling
+	wg := sync.WaitGroup{}
+	replays := make([]*domain.IdempotencyRecord, 3)
+	for i := range replays {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			replays[i], _ = k.Begin(ctx, record("a"))
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	first.StatusCode = http.StatusOK
+	require.NoError(t, k.Complete(ctx, first))
+	wg.Wait()
+
+	for _, replay := range replays {
+		require.NotNil(t, replay)
+		assert.Equal(t, http.StatusOK, replay.StatusCode)
+	}
+}
+
+func TestMemoryStore_DeleteExpired(t *testing.T) {
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for i, key := range []string{"a", "b", "c"} {
+		_, err := store.Reserve(ctx, &domain.IdempotencyRecord{Owner: "user", Key: key, CreatedAt: 0, ExpiresAt: int64(10 * (i + 1))})
+		require.NoError(t, err)
+	}
+
+	deleted, err := store.DeleteExpired(ctx, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	existing, err := store.Reserve(ctx, &domain.IdempotencyRecord{Owner: "user", Key: "c", CreatedAt: 20})
+	require.NoError(t, err)
+	assert.NotNil(t, existing)
+}
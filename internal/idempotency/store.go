@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// test interface
+	_ Store = (*MemoryStore)(nil)
+)
+
+// store which keeps records in memory, records are lost on restart and
+// are not shared by instances
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]domain.IdempotencyRecord{}}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := inflightKey(record)
+	if existing, ok := s.records[key]; ok && existing.ExpiresAt > record.CreatedAt {
+		return &existing, nil
+	}
+	s.records[key] = *record
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := inflightKey(record)
+	if _, ok := s.records[key]; !ok {
+		return errors.Wrapf(domain.ErrNotFound, "%s: complete key %q", idempotencyErrorPrefix, record.Key)
+	}
+	s.records[key] = *record
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, owner string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := inflightKey(&domain.IdempotencyRecord{Owner: owner, Key: key})
+	if record, ok := s.records[k]; ok && !record.Completed() {
+		delete(s.records, k)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(ctx context.Context, now int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := int64(0)
+	for key, record := range s.records {
+		if record.ExpiresAt <= now {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,104 @@
+// Package imaging decodes uploaded images and renders thumbnails
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+
+	// decoders registered for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// errors prefix
+	imagingErrorPrefix = "[imaging]"
+
+	// returned for content which is not supported image
+	ErrFormat = errors.New("image format is not supported")
+)
+
+// supported formats by sniffed content type
+var formats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// quality of jpeg thumbnails
+const jpegQuality = 85
+
+// sniff content type of data, error if it is not supported image
+func ContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := formats[contentType]; !ok {
+		return "", errors.Wrapf(ErrFormat, "%s: %s", imagingErrorPrefix, contentType)
+	}
+	return contentType, nil
+}
+
+// width and height of image without decoding pixels
+func Size(data []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, errors.Wrapf(ErrFormat, "%s: decode config: %s", imagingErrorPrefix, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// decode supported image
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(ErrFormat, "%s: decode: %s", imagingErrorPrefix, err)
+	}
+	return img, nil
+}
+
+// scale image down to fit square with side, smaller images are not upscaled
+func Fit(img image.Image, side int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= side && height <= side {
+		return img
+	}
+
+	if width >= height {
+		height = height * side / width
+		width = side
+	} else {
+		width = width * side / height
+		height = side
+	}
+	// very narrow images keep at least one pixel
+	if width == 0 {
+		width = 1
+	}
+	if height == 0 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// encode thumbnail, png keeps transparency of png sources, jpeg is used for others
+func Encode(img image.Image, sourceContentType string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var err error
+	if sourceContentType == "image/png" {
+		err = png.Encode(buf, img)
+	} else {
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: encode", imagingErrorPrefix)
+	}
+	return buf.Bytes(), nil
+}
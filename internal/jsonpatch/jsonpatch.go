@@ -0,0 +1,255 @@
+// Package jsonpatch applies RFC 6902 JSON Patch to documents decoded by encoding/json
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// operation can't be applied to document, e.g. path doesn't exist
+	ErrInvalid = errors.New("json patch is invalid")
+	// test operation failed
+	ErrTest = errors.New("json patch test failed")
+)
+
+// operation of patch, value is used by add, replace and test, from by move and copy
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	From  string      `json:"from,omitempty"`
+}
+
+// operations applied in order, patch is applied as a whole or not at all
+type Patch []Operation
+
+// decode patch from json array of operations
+func Decode(data []byte) (Patch, error) {
+	patch := Patch{}
+	err := json.Unmarshal(data, &patch)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalid, "%s", err)
+	}
+	return patch, nil
+}
+
+// apply patch to document of maps, slices and values, document is not changed
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+
+	doc = clone(doc)
+
+	for i, op := range p {
+		var err error
+		switch op.Op {
+		case "add":
+			doc, err = add(doc, op.Path, clone(op.Value))
+		case "remove":
+			doc, _, err = remove(doc, op.Path)
+		case "replace":
+			doc, _, err = remove(doc, op.Path)
+			if err == nil {
+				doc, err = add(doc, op.Path, clone(op.Value))
+			}
+		case "move":
+			var value interface{}
+			if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+				err = errors.Wrapf(ErrInvalid, "%s is moved into itself", op.From)
+				break
+			}
+			doc, value, err = remove(doc, op.From)
+			if err == nil {
+				doc, err = add(doc, op.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			value, err = get(doc, op.From)
+			if err == nil {
+				doc, err = add(doc, op.Path, clone(value))
+			}
+		case "test":
+			var value interface{}
+			value, err = get(doc, op.Path)
+			if err == nil && !equal(value, op.Value) {
+				err = errors.Wrapf(ErrTest, "%s", op.Path)
+			}
+		default:
+			err = errors.Wrapf(ErrInvalid, "unknown op %q", op.Op)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "operation %d", i)
+		}
+	}
+
+	return doc, nil
+}
+
+// reference tokens of json pointer, e.g. /armament/0/qty
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.Wrapf(ErrInvalid, "path %q doesn't start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path string) (interface{}, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[t]
+			if !ok {
+				return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(t, len(node)-1)
+			if err != nil {
+				return nil, errors.Wrapf(err, "path %q", path)
+			}
+			doc = node[i]
+		default:
+			return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+		}
+	}
+	return doc, nil
+}
+
+// add value at path, parent of path must exist
+func add(doc interface{}, path string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return update(doc, tokens, path, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[last] = value
+			return node, nil
+		case []interface{}:
+			i := len(node)
+			if last != "-" {
+				i, err = index(last, len(node))
+				if err != nil {
+					return nil, errors.Wrapf(err, "path %q", path)
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, errors.Wrapf(ErrInvalid, "parent of %q is not a container", path)
+		}
+	})
+}
+
+// remove value at path, removed value is returned
+func remove(doc interface{}, path string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	var removed interface{}
+	doc, err = update(doc, tokens, path, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[last]
+			if !ok {
+				return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+			}
+			removed = value
+			delete(node, last)
+			return node, nil
+		case []interface{}:
+			i, err := index(last, len(node)-1)
+			if err != nil {
+				return nil, errors.Wrapf(err, "path %q", path)
+			}
+			removed = node[i]
+			return append(node[:i], node[i+1:]...), nil
+		default:
+			return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+		}
+	})
+	return doc, removed, err
+}
+
+// change parent of the last token, slices are set back since they may be reallocated
+func update(doc interface{}, tokens []string, path string, change func(interface{}, string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return change(doc, tokens[0])
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[tokens[0]]
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+		}
+		child, err := update(child, tokens[1:], path, change)
+		if err != nil {
+			return nil, err
+		}
+		node[tokens[0]] = child
+		return node, nil
+	case []interface{}:
+		i, err := index(tokens[0], len(node)-1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "path %q", path)
+		}
+		child, err := update(node[i], tokens[1:], path, change)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	default:
+		return nil, errors.Wrapf(ErrInvalid, "path %q doesn't exist", path)
+	}
+}
+
+// array index up to max, leading zeros are not allowed
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.Wrapf(ErrInvalid, "index %q is out of range", token)
+	}
+	return i, nil
+}
+
+// deep copy through json, so numbers of document and patch are compared as float64
+func clone(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var res interface{}
+	if json.Unmarshal(data, &res) != nil {
+		return v
+	}
+	return res
+}
+
+func equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(clone(a), clone(b))
+}
@@ -0,0 +1,141 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatch_Apply(t *testing.T) {
+
+	testCases := []struct {
+		name  string
+		doc   string
+		patch string
+		res   string
+		err   error
+	}{
+		{
+			name:  "add object member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			res:   `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:  "add array element",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			res:   `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "append array element",
+			doc:   `{"armament":[{"title":"Turbo Laser","qty":60}]}`,
+			patch: `[{"op":"add","path":"/armament/-","value":{"title":"Ion Cannons","qty":10}}]`,
+			res:   `{"armament":[{"title":"Turbo Laser","qty":60},{"title":"Ion Cannons","qty":10}]}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			res:   `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "replace nested value",
+			doc:   `{"armament":[{"title":"Turbo Laser","qty":60}]}`,
+			patch: `[{"op":"replace","path":"/armament/0/qty","value":0}]`,
+			res:   `{"armament":[{"title":"Turbo Laser","qty":0}]}`,
+		},
+		{
+			name:  "move value",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			res:   `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "move array element",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			res:   `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:  "copy value",
+			doc:   `{"a":{"b":1}}`,
+			patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			res:   `{"a":{"b":1},"c":{"b":2}}`,
+		},
+		{
+			name:  "escaped pointer",
+			doc:   `{"a/b":1,"m~n":2}`,
+			patch: `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`,
+			res:   `{"m~n":3}`,
+		},
+		{
+			name:  "test success",
+			doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			res:   `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:  "test failure",
+			doc:   `{"baz":"qux"}`,
+			patch: `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:   ErrTest,
+		},
+		{
+			name:  "add to nonexistent target",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:   ErrInvalid,
+		},
+		{
+			name:  "remove nonexistent member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			err:   ErrInvalid,
+		},
+		{
+			name:  "index out of range",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/2","value":"qux"}]`,
+			err:   ErrInvalid,
+		},
+		{
+			name:  "unknown operation",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"merge","path":"/foo","value":"qux"}]`,
+			err:   ErrInvalid,
+		},
+		{
+			name:  "move into itself",
+			doc:   `{"foo":{"bar":1}}`,
+			patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			err:   ErrInvalid,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		var doc interface{}
+		require.NoError(t, json.Unmarshal([]byte(test.doc), &doc))
+		patch, err := Decode([]byte(test.patch))
+		require.NoError(t, err)
+
+		res, err := patch.Apply(doc)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		data, err := json.Marshal(res)
+		require.NoError(t, err)
+		assert.JSONEq(t, test.res, string(data))
+
+		// original document is not changed
+		original, err := json.Marshal(doc)
+		require.NoError(t, err)
+		assert.JSONEq(t, test.doc, string(original))
+	}
+}
@@ -0,0 +1,132 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// supported signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// signing key with parsed private key
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// public part of key used for verification
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// jwt signing method of key
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// generate key with random id
+func GenerateKey(algorithm string, now time.Time) (*Key, error) {
+
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: generate rsa key", keysetErrorPrefix)
+		}
+		private = rsaKey
+	case AlgorithmEdDSA:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: generate ed25519 key", keysetErrorPrefix)
+		}
+		private = edKey
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: unsupported algorithm %q", keysetErrorPrefix, algorithm)
+	}
+
+	id := make([]byte, 8)
+	_, err := rand.Read(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: generate key id", keysetErrorPrefix)
+	}
+
+	return &Key{
+		ID:        hex.EncodeToString(id),
+		Algorithm: algorithm,
+		Private:   private,
+		CreatedAt: now,
+	}, nil
+}
+
+// encode key for store
+func (k *Key) toDomain() (*domain.SigningKey, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: marshal key %s", keysetErrorPrefix, k.ID)
+	}
+	return &domain.SigningKey{
+		ID:         k.ID,
+		Algorithm:  k.Algorithm,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		CreatedAt:  k.CreatedAt.Unix(),
+	}, nil
+}
+
+// decode key from store
+func keyFromDomain(signingKey *domain.SigningKey) (*Key, error) {
+	block, _ := pem.Decode(signingKey.PrivateKey)
+	if block == nil {
+		return nil, errors.Errorf("%s: key %s is not pem encoded", keysetErrorPrefix, signingKey.ID)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: parse key %s", keysetErrorPrefix, signingKey.ID)
+	}
+
+	var private crypto.Signer
+	switch signingKey.Algorithm {
+	case AlgorithmRS256:
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.Errorf("%s: key %s is not rsa key", keysetErrorPrefix, signingKey.ID)
+		}
+		private = rsaKey
+	case AlgorithmEdDSA:
+		edKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.Errorf("%s: key %s is not ed25519 key", keysetErrorPrefix, signingKey.ID)
+		}
+		private = edKey
+	default:
+		return nil, errors.Errorf("%s: key %s has unsupported algorithm %q", keysetErrorPrefix, signingKey.ID, signingKey.Algorithm)
+	}
+
+	return &Key{
+		ID:        signingKey.ID,
+		Algorithm: signingKey.Algorithm,
+		Private:   private,
+		CreatedAt: time.Unix(signingKey.CreatedAt, 0),
+	}, nil
+}
@@ -0,0 +1,211 @@
+// Package keyset keeps asymmetric keys which sign auth tokens,
+// rotates them on schedule and publishes public keys as JWKS
+package keyset
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	keysetErrorPrefix = "[keyset]"
+)
+
+// persistent storage of signing keys
+type Store interface {
+	GetAll(context.Context) ([]*domain.SigningKey, error)
+	Create(context.Context, *domain.SigningKey) error
+	Delete(context.Context, string) error
+}
+
+type Config struct {
+	// algorithm of new keys, RS256 or EdDSA
+	Algorithm string
+	// age of newest key after which new key is generated
+	RotationInterval time.Duration
+	// time new key is published before it signs tokens, so all instances
+	// and token consumers load it first
+	Propagation time.Duration
+	// time key is kept for verification after it stops signing, must cover
+	// lifetime of longest token
+	Retention time.Duration
+}
+
+// set of signing keys, newest propagated key signs tokens and all keys verify
+type KeySet struct {
+	store  Store
+	config Config
+	now    func() time.Time
+
+	mu sync.RWMutex
+	// sorted from newest to oldest
+	keys []*Key
+}
+
+// load keys from store, the first key is generated when store is empty
+func New(ctx context.Context, store Store, config Config) (*KeySet, error) {
+	ks := &KeySet{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+
+	err := ks.Maintain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// key which signs new tokens
+func (ks *KeySet) Signing() *Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.signing(ks.now())
+}
+
+// newest key older than propagation, fresh key signs only if it is the only one
+func (ks *KeySet) signing(now time.Time) *Key {
+	for _, key := range ks.keys {
+		if now.Sub(key.CreatedAt) >= ks.config.Propagation {
+			return key
+		}
+	}
+	if len(ks.keys) == 0 {
+		return nil
+	}
+	return ks.keys[len(ks.keys)-1]
+}
+
+// key by id for token verification
+func (ks *KeySet) Key(id string) (*Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for _, key := range ks.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// all keys from newest to oldest
+func (ks *KeySet) Keys() []*Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return append([]*Key(nil), ks.keys...)
+}
+
+// reload keys from store
+func (ks *KeySet) Load(ctx context.Context) error {
+
+	signingKeys, err := ks.store.GetAll(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "%s: load keys", keysetErrorPrefix)
+	}
+
+	keys := make([]*Key, 0, len(signingKeys))
+	for _, signingKey := range signingKeys {
+		key, err := keyFromDomain(signingKey)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// generate new key, it signs tokens after propagation
+func (ks *KeySet) Rotate(ctx context.Context) (*Key, error) {
+
+	key, err := GenerateKey(ks.config.Algorithm, ks.now())
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := key.toDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ks.store.Create(ctx, signingKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: save key", keysetErrorPrefix)
+	}
+
+	return key, ks.Load(ctx)
+}
+
+// reload keys, rotate when newest key is older than rotation interval
+// and delete keys which do not sign for longer than retention
+func (ks *KeySet) Maintain(ctx context.Context) error {
+
+	err := ks.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := ks.now()
+	keys := ks.Keys()
+
+	if len(keys) == 0 || now.Sub(keys[0].CreatedAt) >= ks.config.RotationInterval {
+		_, err = ks.Rotate(ctx)
+		if err != nil {
+			return err
+		}
+		keys = ks.Keys()
+	}
+
+	// key stops signing when next key is propagated
+	deleted := false
+	for i := 1; i < len(keys); i++ {
+		retiredAt := keys[i-1].CreatedAt.Add(ks.config.Propagation)
+		if now.Sub(retiredAt) < ks.config.Retention {
+			continue
+		}
+		err = ks.store.Delete(ctx, keys[i].ID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete key %s", keysetErrorPrefix, keys[i].ID)
+		}
+		deleted = true
+	}
+
+	if deleted {
+		return ks.Load(ctx)
+	}
+	return nil
+}
+
+// maintain keys periodically until context is done
+func (ks *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := ks.Maintain(ctx)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
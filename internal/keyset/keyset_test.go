@@ -0,0 +1,116 @@
+package keyset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Maintain(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	store := NewMemoryStore()
+	ks := &KeySet{
+		store: store,
+		config: Config{
+			Algorithm:        AlgorithmEdDSA,
+			RotationInterval: 24 * time.Hour,
+			Propagation:      10 * time.Minute,
+			Retention:        48 * time.Hour,
+		},
+		now: func() time.Time { return now },
+	}
+
+	// the first key signs at once
+	require.NoError(t, ks.Maintain(ctx))
+	require.Len(t, ks.Keys(), 1)
+	first := ks.Signing()
+	require.NotNil(t, first)
+
+	// nothing changes before rotation
+	now = now.Add(23 * time.Hour)
+	require.NoError(t, ks.Maintain(ctx))
+	assert.Len(t, ks.Keys(), 1)
+
+	// new key is published but old key signs during propagation
+	now = now.Add(time.Hour)
+	require.NoError(t, ks.Maintain(ctx))
+	require.Len(t, ks.Keys(), 2)
+	second := ks.Keys()[0]
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first.ID, ks.Signing().ID)
+
+	now = now.Add(10 * time.Minute)
+	assert.Equal(t, second.ID, ks.Signing().ID)
+	_, ok := ks.Key(first.ID)
+	assert.True(t, ok)
+
+	// old key is deleted after retention, even after later rotation
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, ks.Maintain(ctx))
+	assert.Len(t, ks.Keys(), 3)
+
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, ks.Maintain(ctx))
+	_, ok = ks.Key(first.ID)
+	assert.False(t, ok)
+	_, ok = ks.Key(second.ID)
+	assert.True(t, ok)
+
+	// other instance loads the same keys from store
+	other := &KeySet{store: store, config: ks.config, now: ks.now}
+	require.NoError(t, other.Load(ctx))
+	assert.Equal(t, ks.Signing().ID, other.Signing().ID)
+}
+
+func TestFileStore(t *testing.T) {
+
+	ctx := context.Background()
+	store := NewFileStore(t.TempDir())
+
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		key, err := GenerateKey(algorithm, time.Unix(1700000000, 0))
+		require.NoError(t, err)
+		signingKey, err := key.toDomain()
+		require.NoError(t, err)
+		require.NoError(t, store.Create(ctx, signingKey))
+	}
+
+	ks := &KeySet{store: store, now: time.Now}
+	require.NoError(t, ks.Load(ctx))
+	require.Len(t, ks.Keys(), 2)
+
+	jwks := ks.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	for _, jwk := range jwks.Keys {
+		key, ok := ks.Key(jwk.KeyID)
+		require.True(t, ok)
+		assert.Equal(t, key.Algorithm, jwk.Algorithm)
+		assert.Equal(t, int64(1700000000), key.CreatedAt.Unix())
+
+		switch jwk.Algorithm {
+		case AlgorithmRS256:
+			assert.Equal(t, "RSA", jwk.KeyType)
+			assert.Equal(t, "AQAB", jwk.E)
+			assert.NotEmpty(t, jwk.N)
+		case AlgorithmEdDSA:
+			assert.Equal(t, "OKP", jwk.KeyType)
+			assert.Equal(t, "Ed25519", jwk.Curve)
+			assert.Len(t, jwk.X, 43)
+		}
+	}
+
+	require.NoError(t, store.Delete(ctx, jwks.Keys[0].KeyID))
+	require.NoError(t, ks.Load(ctx))
+	assert.Len(t, ks.Keys(), 1)
+}
+
+func TestGenerateKey_Unsupported(t *testing.T) {
+	_, err := GenerateKey("HS256", time.Now())
+	assert.Error(t, err)
+}
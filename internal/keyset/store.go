@@ -0,0 +1,157 @@
+package keyset
+
+import (
+	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// test interface
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
+
+// store which keeps keys in memory, keys are lost on restart
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]domain.SigningKey
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: map[string]domain.SigningKey{}}
+}
+
+func (s *MemoryStore) GetAll(ctx context.Context) ([]*domain.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]*domain.SigningKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		key := key
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) Create(ctx context.Context, key *domain.SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = *key
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, id)
+	return nil
+}
+
+// pem headers with key metadata
+const (
+	pemHeaderAlgorithm = "Algorithm"
+	pemHeaderCreatedAt = "Created-At"
+)
+
+// store which keeps every key in own pem file in directory
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir}
+}
+
+func (s *FileStore) GetAll(ctx context.Context) ([]*domain.SigningKey, error) {
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list key files", keysetErrorPrefix)
+	}
+
+	keys := make([]*domain.SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: read key file", keysetErrorPrefix)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.Errorf("%s: key file %s is not pem encoded", keysetErrorPrefix, path)
+		}
+		createdAt, err := strconv.ParseInt(block.Headers[pemHeaderCreatedAt], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: key file %s creation time", keysetErrorPrefix, path)
+		}
+
+		// headers are not part of stored key
+		algorithm := block.Headers[pemHeaderAlgorithm]
+		block.Headers = nil
+
+		keys = append(keys, &domain.SigningKey{
+			ID:         strings.TrimSuffix(filepath.Base(path), ".pem"),
+			Algorithm:  algorithm,
+			PrivateKey: pem.EncodeToMemory(block),
+			CreatedAt:  createdAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// write key file atomically, readable by owner only
+func (s *FileStore) Create(ctx context.Context, key *domain.SigningKey) error {
+
+	block, _ := pem.Decode(key.PrivateKey)
+	if block == nil {
+		return errors.Errorf("%s: key %s is not pem encoded", keysetErrorPrefix, key.ID)
+	}
+	block.Headers = map[string]string{
+		pemHeaderAlgorithm: key.Algorithm,
+		pemHeaderCreatedAt: strconv.FormatInt(key.CreatedAt, 10),
+	}
+
+	err := os.MkdirAll(s.dir, 0o700)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create key dir", keysetErrorPrefix)
+	}
+
+	f, err := os.CreateTemp(s.dir, ".key-*")
+	if err != nil {
+		return errors.Wrapf(err, "%s: create key file", keysetErrorPrefix)
+	}
+	defer os.Remove(f.Name())
+
+	err = pem.Encode(f, block)
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "%s: write key file", keysetErrorPrefix)
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.Wrapf(err, "%s: write key file", keysetErrorPrefix)
+	}
+
+	err = os.Rename(f.Name(), filepath.Join(s.dir, key.ID+".pem"))
+	if err != nil {
+		return errors.Wrapf(err, "%s: save key file", keysetErrorPrefix)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(id)+".pem"))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "%s: delete key file", keysetErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package mailer delivers emails to users
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	mailerErrorPrefix = "[mailer]"
+)
+
+// plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// mailer delivers messages
+type Mailer interface {
+	Send(context.Context, Message) error
+}
+
+// build rfc 5322 message with headers
+func build(from string, msg Message) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// mailer which writes messages to writer instead of delivery, for development
+type LogMailer struct {
+	from string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// log mailer builder
+func NewLogMailer(w io.Writer, from string) *LogMailer {
+	return &LogMailer{w: w, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "%s\r\n", build(m.from, msg))
+	if err != nil {
+		return errors.Wrapf(err, "%s: write message", mailerErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// received smtp envelope
+type envelope struct {
+	from string
+	to   []string
+	data string
+}
+
+// minimal in-process smtp server without extensions
+func fakeSMTPServer(t *testing.T) (string, <-chan envelope) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan envelope, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+
+	return ln.Addr().String(), received
+}
+
+func serveSMTP(conn net.Conn, received chan<- envelope) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	env := envelope{}
+	reply("220 fake smtp")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			env.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			env.to = append(env.to, strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>"))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			data := new(strings.Builder)
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			env.data = data.String()
+			received <- env
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+
+	addr, received := fakeSMTPServer(t)
+	m := NewSMTPMailer(addr, "", "", "fleet@empire.gov")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := m.Send(ctx, Message{
+		To:      "vader@empire.gov",
+		Subject: "Password reset",
+		Body:    "token: abc\nlink: https://fleet/reset",
+	})
+	require.NoError(t, err)
+
+	env := <-received
+	assert.Equal(t, "fleet@empire.gov", env.from)
+	assert.Equal(t, []string{"vader@empire.gov"}, env.to)
+	assert.Contains(t, env.data, "To: vader@empire.gov\r\n")
+	assert.Contains(t, env.data, "Subject: Password reset\r\n")
+	assert.Contains(t, env.data, "\r\n\r\ntoken: abc\r\nlink: https://fleet/reset\r\n")
+}
+
+func TestSMTPMailer_SendUnavailable(t *testing.T) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	m := NewSMTPMailer(addr, "", "", "fleet@empire.gov")
+	assert.Error(t, m.Send(context.Background(), Message{To: "vader@empire.gov"}))
+}
+
+func TestLogMailer_Send(t *testing.T) {
+
+	buf := new(bytes.Buffer)
+	m := NewLogMailer(buf, "fleet@empire.gov")
+
+	require.NoError(t, m.Send(context.Background(), Message{To: "vader@empire.gov", Subject: "Verify email", Body: "token: abc"}))
+	assert.Contains(t, buf.String(), "From: fleet@empire.gov\r\n")
+	assert.Contains(t, buf.String(), "token: abc")
+}
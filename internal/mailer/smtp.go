@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+
+	"github.com/pkg/errors"
+)
+
+// mailer which delivers messages to smtp server
+type SMTPMailer struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+// smtp mailer builder, auth is used if username is set
+func NewSMTPMailer(addr string, username string, password string, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     addr,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp address", mailerErrorPrefix)
+	}
+
+	// dial with context, so request deadline is respected
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return errors.Wrapf(err, "%s: dial smtp", mailerErrorPrefix)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrapf(err, "%s: smtp client", mailerErrorPrefix)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return errors.Wrapf(err, "%s: starttls", mailerErrorPrefix)
+		}
+	}
+
+	if m.username != "" {
+		err = c.Auth(smtp.PlainAuth("", m.username, m.password, host))
+		if err != nil {
+			return errors.Wrapf(err, "%s: smtp auth", mailerErrorPrefix)
+		}
+	}
+
+	err = c.Mail(m.from)
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp mail from", mailerErrorPrefix)
+	}
+	err = c.Rcpt(msg.To)
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp rcpt to", mailerErrorPrefix)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp data", mailerErrorPrefix)
+	}
+	_, err = w.Write(build(m.from, msg))
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp write", mailerErrorPrefix)
+	}
+	err = w.Close()
+	if err != nil {
+		return errors.Wrapf(err, "%s: smtp data close", mailerErrorPrefix)
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// how many failures are counted between sweeps of forgotten keys
+const lockoutSweepEvery = 1024
+
+type lockoutEntry struct {
+	failures    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// progressive lockout of keys after failures, safe for concurrent use.
+// After threshold failures key is locked for base duration, every next
+// failure doubles lock duration up to max.
+type Lockout struct {
+	threshold int
+	base      time.Duration
+	max       time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*lockoutEntry
+	fails   int
+}
+
+// lockout builder
+func NewLockout(threshold int, base time.Duration, max time.Duration) *Lockout {
+	return &Lockout{
+		threshold: threshold,
+		base:      base,
+		max:       max,
+		now:       time.Now,
+		entries:   make(map[string]*lockoutEntry),
+	}
+}
+
+// remaining lock duration of key, zero if key is not locked
+func (l *Lockout) Check(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return 0
+	}
+
+	now := l.now()
+	if now.Before(e.lockedUntil) {
+		return e.lockedUntil.Sub(now)
+	}
+
+	return 0
+}
+
+// register failure of key
+func (l *Lockout) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.fails++
+	if l.fails%lockoutSweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	e, ok := l.entries[key]
+	if !ok || l.expired(e, now) {
+		e = &lockoutEntry{}
+		l.entries[key] = e
+	}
+	e.failures++
+	e.lastFailure = now
+
+	if e.failures < l.threshold {
+		return
+	}
+
+	lock := l.max
+	if shift := e.failures - l.threshold; shift < 32 && l.base<<shift < l.max {
+		lock = l.base << shift
+	}
+	e.lockedUntil = now.Add(lock)
+}
+
+// forget failures of key after success
+func (l *Lockout) Success(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// failures are forgotten when key is not locked and max lock duration passed since last failure
+func (l *Lockout) expired(e *lockoutEntry, now time.Time) bool {
+	return !now.Before(e.lockedUntil) && now.Sub(e.lastFailure) >= l.max
+}
+
+func (l *Lockout) sweep(now time.Time) {
+	for key, e := range l.entries {
+		if l.expired(e, now) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package ratelimit provides in-memory token bucket limiter and login lockout
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	ratelimitErrorPrefix = "[ratelimit]"
+)
+
+// rate of requests, Limit requests are allowed per Period, zero rate means no limit
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRate parses rate in form "10/1m", empty string gives zero rate
+func ParseRate(s string) (Rate, error) {
+	if s == "" {
+		return Rate{}, nil
+	}
+
+	limit, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rate{}, errors.Errorf("%s: rate %q must be in form limit/period", ratelimitErrorPrefix, s)
+	}
+
+	l, err := strconv.Atoi(limit)
+	if err != nil || l < 0 {
+		return Rate{}, errors.Errorf("%s: rate %q has wrong limit", ratelimitErrorPrefix, s)
+	}
+
+	p, err := time.ParseDuration(period)
+	if err != nil || p <= 0 {
+		return Rate{}, errors.Errorf("%s: rate %q has wrong period", ratelimitErrorPrefix, s)
+	}
+
+	return Rate{Limit: l, Period: p}, nil
+}
+
+// Decode implements envconfig decoder
+func (r *Rate) Decode(value string) error {
+	rate, err := ParseRate(value)
+	if err != nil {
+		return err
+	}
+	*r = rate
+	return nil
+}
+
+func (r Rate) String() string {
+	if r.Limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// MarshalText keeps rate readable in printed config
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// result of limiter check
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// time until bucket is full again
+	Reset time.Duration
+	// time until next request is allowed, zero if allowed
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// token bucket limiter keyed by string, safe for concurrent use
+type Limiter struct {
+	rate Rate
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// limiter builder
+func NewLimiter(rate Rate) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// take one token from bucket of key
+func (l *Limiter) Allow(key string) Result {
+	if l.rate.Limit == 0 {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	limit := float64(l.rate.Limit)
+	perToken := l.rate.Period.Seconds() / limit
+
+	// refill bucket by elapsed time
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(limit, b.tokens+now.Sub(b.last).Seconds()/perToken)
+	b.last = now
+
+	res := Result{Limit: l.rate.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) * perToken)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((limit - b.tokens) * perToken)
+
+	return res
+}
+
+// forget buckets which are full again, once a period
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.rate.Period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.rate.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// manually advanced clock
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestParseRate(t *testing.T) {
+
+	testCases := []struct {
+		input string
+		rate  Rate
+		err   bool
+	}{
+		{input: "", rate: Rate{}},
+		{input: "10/1m", rate: Rate{Limit: 10, Period: time.Minute}},
+		{input: "300/1h", rate: Rate{Limit: 300, Period: time.Hour}},
+		{input: "10", err: true},
+		{input: "x/1m", err: true},
+		{input: "10/x", err: true},
+		{input: "10/0s", err: true},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %q", test.input)
+
+		rate, err := ParseRate(test.input)
+		if test.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.rate, rate)
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	limiter := NewLimiter(Rate{Limit: 3, Period: 3 * time.Second})
+	limiter.now = clock.now
+
+	// burst is allowed up to limit
+	for i := 2; i >= 0; i-- {
+		res := limiter.Allow("a")
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res := limiter.Allow("a")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	// other keys have own buckets
+	assert.True(t, limiter.Allow("b").Allowed)
+
+	// one token is refilled per second
+	clock.advance(time.Second)
+	assert.True(t, limiter.Allow("a").Allowed)
+	assert.False(t, limiter.Allow("a").Allowed)
+
+	// idle buckets are swept
+	clock.advance(time.Minute)
+	assert.True(t, limiter.Allow("c").Allowed)
+	assert.Len(t, limiter.buckets, 1)
+
+	// zero rate is unlimited
+	unlimited := NewLimiter(Rate{})
+	for i := 0; i < 100; i++ {
+		assert.True(t, unlimited.Allow("a").Allowed)
+	}
+}
+
+func TestLockout(t *testing.T) {
+
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lockout := NewLockout(3, time.Minute, 10*time.Minute)
+	lockout.now = clock.now
+
+	testCases := []struct {
+		name    string
+		advance time.Duration
+		fail    bool
+		success bool
+		locked  time.Duration
+	}{
+		{name: "first failure", fail: true, locked: 0},
+		{name: "second failure", fail: true, locked: 0},
+		{name: "threshold reached", fail: true, locked: time.Minute},
+		{name: "lock passes", advance: time.Minute, locked: 0},
+		{name: "lock doubles", fail: true, locked: 2 * time.Minute},
+		{name: "lock doubles again", advance: 2 * time.Minute, fail: true, locked: 4 * time.Minute},
+		{name: "lock is limited by max", advance: 4 * time.Minute, fail: true, locked: 8 * time.Minute},
+		{name: "lock is max", advance: 8 * time.Minute, fail: true, locked: 10 * time.Minute},
+		{name: "success resets", advance: 10 * time.Minute, success: true, locked: 0},
+		{name: "failures start again", fail: true, locked: 0},
+		{name: "failures are forgotten", advance: 10 * time.Minute, fail: true, locked: 0},
+		{name: "failures are counted", fail: true, locked: 0},
+		{name: "threshold reached again", fail: true, locked: time.Minute},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		clock.advance(test.advance)
+		if test.fail {
+			lockout.Fail("vader@empire.gov")
+		}
+		if test.success {
+			lockout.Success("vader@empire.gov")
+		}
+
+		assert.Equal(t, test.locked, lockout.Check("vader@empire.gov"))
+		assert.Equal(t, time.Duration(0), lockout.Check("tarkin@empire.gov"))
+	}
+}
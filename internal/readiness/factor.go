@@ -0,0 +1,113 @@
+package readiness
+
+import (
+	"fmt"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// names of built in factors, weights of rules are keyed by them
+const (
+	FactorStatus      = "status"
+	FactorCrew        = "crew"
+	FactorFirepower   = "firepower"
+	FactorMaintenance = "maintenance"
+)
+
+// part of readiness, custom factors are plugged into engine and weighted by rules
+type Factor interface {
+	Name() string
+	// score from 0 to 1 with explanation
+	Score(*Rules, domain.ReadinessInput) (float64, string)
+}
+
+// factors scored by default
+func DefaultFactors() []Factor {
+	return []Factor{StatusFactor{}, CrewFactor{}, FirepowerFactor{}, MaintenanceFactor{}}
+}
+
+// score of spaceship status
+type StatusFactor struct{}
+
+func (StatusFactor) Name() string {
+	return FactorStatus
+}
+
+func (StatusFactor) Score(rules *Rules, in domain.ReadinessInput) (float64, string) {
+	status := in.Spaceship.Status.String()
+	return rules.Status[key(status)], fmt.Sprintf("status is %s", status)
+}
+
+// assigned crew members against min crew of class
+type CrewFactor struct{}
+
+func (CrewFactor) Name() string {
+	return FactorCrew
+}
+
+func (CrewFactor) Score(rules *Rules, in domain.ReadinessInput) (float64, string) {
+	if in.Class == nil || in.Class.MinCrew == 0 {
+		return rules.Crew.Unrated, fmt.Sprintf("%d crew members assigned, class has no crew requirement", in.Roster)
+	}
+	score := float64(in.Roster) / float64(in.Class.MinCrew)
+	if score > 1 {
+		score = 1
+	}
+	return score, fmt.Sprintf("%d crew members assigned of %d required", in.Roster, in.Class.MinCrew)
+}
+
+// firepower of armament against default loadout of class, rated by damage of catalog
+type FirepowerFactor struct{}
+
+func (FirepowerFactor) Name() string {
+	return FactorFirepower
+}
+
+func (FirepowerFactor) Score(rules *Rules, in domain.ReadinessInput) (float64, string) {
+	if in.Class == nil {
+		return rules.Firepower.Unrated, "class is out of catalog, firepower is not rated"
+	}
+
+	full := 0.0
+	for _, a := range in.Class.Armament {
+		full += float64(a.Qty) * a.Damage
+	}
+	if full == 0 {
+		return rules.Firepower.Unrated, "class has no rated loadout"
+	}
+
+	firepower := in.Class.Firepower(in.Spaceship.Armament)
+
+	score := firepower / full
+	if score > 1 {
+		score = 1
+	}
+	return score, fmt.Sprintf("firepower %g of %g of class loadout", firepower, full)
+}
+
+// open work orders lower score by severity
+type MaintenanceFactor struct{}
+
+func (MaintenanceFactor) Name() string {
+	return FactorMaintenance
+}
+
+func (MaintenanceFactor) Score(rules *Rules, in domain.ReadinessInput) (float64, string) {
+	if len(in.Orders) == 0 {
+		return 1, "no open work orders"
+	}
+
+	score := 1.0
+	overdue := 0
+	for _, o := range in.Orders {
+		score -= rules.Maintenance.Severity[key(o.Severity.String())]
+		if o.Overdue(in.Now) > 0 {
+			score -= rules.Maintenance.Overdue
+			overdue++
+		}
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score, fmt.Sprintf("%d open work orders, %d overdue", len(in.Orders), overdue)
+}
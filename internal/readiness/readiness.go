@@ -0,0 +1,111 @@
+// Package readiness scores combat readiness of spaceships by configurable rules
+package readiness
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+)
+
+var (
+	// test interface
+	_ service.ReadinessEngine = (*Engine)(nil)
+)
+
+// weighted sum of factors, rules are taken from source on every score
+type Engine struct {
+	source  Source
+	factors []Factor
+}
+
+// engine of factors, default factors are used if none is given
+func New(source Source, factors ...Factor) *Engine {
+	if len(factors) == 0 {
+		factors = DefaultFactors()
+	}
+	return &Engine{source, factors}
+}
+
+// readiness of spaceship from 0 to 100
+func (e *Engine) Score(in domain.ReadinessInput) *domain.Readiness {
+	return e.score(e.source.Rules(), in)
+}
+
+// readiness of every spaceship and their average by class
+func (e *Engine) Fleet(ins []domain.ReadinessInput) *domain.FleetReadiness {
+
+	rules := e.source.Rules()
+	fleet := &domain.FleetReadiness{Levels: map[string]int{}, Classes: []domain.ClassReadiness{}}
+	classes := map[string]*domain.ClassReadiness{}
+	total := 0.0
+
+	for _, in := range ins {
+		r := e.score(rules, in)
+
+		fleet.Spaceships++
+		fleet.Levels[r.Level]++
+		total += r.Score
+		if fleet.Weakest == nil || r.Score < fleet.Weakest.Score {
+			fleet.Weakest = r
+		}
+
+		class, ok := classes[in.Spaceship.Class]
+		if !ok {
+			class = &domain.ClassReadiness{Class: in.Spaceship.Class}
+			classes[in.Spaceship.Class] = class
+		}
+		class.Spaceships++
+		// sum of scores until average is taken
+		class.Score += r.Score
+	}
+
+	if fleet.Spaceships > 0 {
+		fleet.Score = round(total / float64(fleet.Spaceships))
+	}
+	for _, c := range classes {
+		c.Score = round(c.Score / float64(c.Spaceships))
+		fleet.Classes = append(fleet.Classes, *c)
+	}
+	sort.Slice(fleet.Classes, func(i, j int) bool {
+		return fleet.Classes[i].Class < fleet.Classes[j].Class
+	})
+
+	return fleet
+}
+
+func (e *Engine) score(rules *Rules, in domain.ReadinessInput) *domain.Readiness {
+
+	weights := 0.0
+	for _, f := range e.factors {
+		weights += rules.Weights[f.Name()]
+	}
+
+	r := &domain.Readiness{SpaceshipID: in.Spaceship.ID, Factors: []domain.ReadinessFactor{}}
+	for _, f := range e.factors {
+		weight := rules.Weights[f.Name()]
+		if weight == 0 {
+			continue
+		}
+		score, explanation := f.Score(rules, in)
+		points := weight / weights * score * 100
+		r.Score += points
+		r.Factors = append(r.Factors, domain.ReadinessFactor{
+			Name:        f.Name(),
+			Weight:      weight,
+			Score:       round(score),
+			Points:      round(points),
+			Explanation: explanation,
+		})
+	}
+	r.Score = round(r.Score)
+	r.Level = rules.Level(r.Score)
+
+	return r
+}
+
+// scores are rounded to hundredths
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,260 @@
+package readiness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func starDestroyer() *domain.ShipClass {
+	return &domain.ShipClass{
+		Name:    "Star Destroyer",
+		MinCrew: 9000,
+		Armament: []domain.ShipClassArmament{
+			{Title: "Turbo Laser", Qty: 60, Max: 60, Damage: 10},
+			{Title: "Ion Cannons", Qty: 60, Max: 60, Damage: 5},
+		},
+	}
+}
+
+func TestEngine_Score(t *testing.T) {
+
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC).Unix()
+
+	testCases := []struct {
+		name    string
+		in      domain.ReadinessInput
+		score   float64
+		level   string
+		factors map[string]float64
+	}{
+		{
+			name: "fully manned and armed operational spaceship",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational, Crew: 9500, Armament: []domain.SpaceshipArmament{
+					{Title: "turbo laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}}},
+				Class:  starDestroyer(),
+				Roster: 9500,
+			},
+			score:   100,
+			level:   "ready",
+			factors: map[string]float64{"status": 1, "crew": 1, "firepower": 1, "maintenance": 1},
+		},
+		{
+			name: "half armed and under crewed spaceship",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational, Crew: 4500, Armament: []domain.SpaceshipArmament{
+					{Title: "Turbo Laser", Qty: 30}, {Title: "Ion Cannons", Qty: 30}}},
+				Class:  starDestroyer(),
+				Roster: 4500,
+			},
+			// 30 + 10 + 15 + 20
+			score:   75,
+			level:   "limited",
+			factors: map[string]float64{"status": 1, "crew": 0.5, "firepower": 0.5, "maintenance": 1},
+		},
+		{
+			name: "armament out of class is not rated",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational, Crew: 9000, Armament: []domain.SpaceshipArmament{
+					{Title: "Proton Torpedo", Qty: 100}}},
+				Class:  starDestroyer(),
+				Roster: 9000,
+			},
+			score:   70,
+			level:   "limited",
+			factors: map[string]float64{"status": 1, "crew": 1, "firepower": 0, "maintenance": 1},
+		},
+		{
+			name: "damaged spaceship with overdue critical work order",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusDamaged, Crew: 9000, Armament: []domain.SpaceshipArmament{
+					{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}}},
+				Class:  starDestroyer(),
+				Roster: 9000,
+				Orders: []*domain.WorkOrder{
+					{Severity: domain.WorkOrderSeverityCritical, OpenedAt: now - int64(48*time.Hour/time.Second)},
+					{Severity: domain.WorkOrderSeverityLow, OpenedAt: now},
+				},
+				Now: now,
+			},
+			// 6 + 20 + 30 + 5
+			score:   61,
+			level:   "limited",
+			factors: map[string]float64{"status": 0.2, "crew": 1, "firepower": 1, "maintenance": 0.25},
+		},
+		{
+			name: "penalties of work orders do not go below zero",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusDamaged},
+				Class:     starDestroyer(),
+				Orders: []*domain.WorkOrder{
+					{Severity: domain.WorkOrderSeverityCritical, OpenedAt: now},
+					{Severity: domain.WorkOrderSeverityCritical, OpenedAt: now},
+				},
+				Now: now,
+			},
+			score:   6,
+			level:   "not_ready",
+			factors: map[string]float64{"status": 0.2, "crew": 0, "firepower": 0, "maintenance": 0},
+		},
+		{
+			name: "crew without assigned crew members is not counted",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational, Crew: 9000, Armament: []domain.SpaceshipArmament{
+					{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}}},
+				Class:  starDestroyer(),
+				Roster: 900,
+			},
+			// 30 + 2 + 30 + 20
+			score:   82,
+			level:   "ready",
+			factors: map[string]float64{"status": 1, "crew": 0.1, "firepower": 1, "maintenance": 1},
+		},
+		{
+			name: "class out of catalog is scored by unrated rules",
+			in: domain.ReadinessInput{
+				Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational, Crew: 2, Class: "Light Freighter"},
+			},
+			// 30 + 20 + 15 + 20
+			score:   85,
+			level:   "ready",
+			factors: map[string]float64{"status": 1, "crew": 1, "firepower": 0.5, "maintenance": 1},
+		},
+	}
+
+	engine := New(DefaultRules())
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		r := engine.Score(test.in)
+		assert.Equal(t, test.score, r.Score)
+		assert.Equal(t, test.level, r.Level)
+		factors := map[string]float64{}
+		points := 0.0
+		for _, f := range r.Factors {
+			factors[f.Name] = f.Score
+			points += f.Points
+			assert.NotEmpty(t, f.Explanation)
+		}
+		assert.Equal(t, test.factors, factors)
+		assert.InDelta(t, r.Score, points, 0.05)
+	}
+}
+
+// factor plugged into engine, scored only if rules weight it
+type shieldsFactor struct{}
+
+func (shieldsFactor) Name() string {
+	return "shields"
+}
+
+func (shieldsFactor) Score(*Rules, domain.ReadinessInput) (float64, string) {
+	return 0, "shields are down"
+}
+
+func TestEngine_Factors(t *testing.T) {
+
+	rules, err := Parse([]byte(`
+weights:
+  status: 1
+  shields: 1
+status:
+  operational: 1
+levels:
+  - name: ready
+    min: 60
+  - name: limited
+    min: 0
+`))
+	require.NoError(t, err)
+
+	engine := New(rules, StatusFactor{}, CrewFactor{}, shieldsFactor{})
+	r := engine.Score(domain.ReadinessInput{Spaceship: &domain.Spaceship{Status: domain.SpaceshipStatusOperational}})
+	assert.Equal(t, float64(50), r.Score)
+	assert.Equal(t, "limited", r.Level)
+	require.Len(t, r.Factors, 2)
+	assert.Equal(t, "shields", r.Factors[1].Name)
+}
+
+func TestEngine_Fleet(t *testing.T) {
+
+	engine := New(DefaultRules())
+	fleet := engine.Fleet([]domain.ReadinessInput{
+		{Spaceship: &domain.Spaceship{ID: 1, Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational, Crew: 9000,
+			Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}}}, Class: starDestroyer(), Roster: 9000},
+		{Spaceship: &domain.Spaceship{ID: 2, Class: "Star Destroyer", Status: domain.SpaceshipStatusDamaged}, Class: starDestroyer()},
+		{Spaceship: &domain.Spaceship{ID: 3, Class: "Light Freighter", Status: domain.SpaceshipStatusOperational}},
+	})
+
+	assert.Equal(t, 3, fleet.Spaceships)
+	// (100 + 26 + 85) / 3
+	assert.Equal(t, 70.33, fleet.Score)
+	assert.Equal(t, map[string]int{"ready": 2, "not_ready": 1}, fleet.Levels)
+	assert.Equal(t, []domain.ClassReadiness{
+		{Class: "Light Freighter", Spaceships: 1, Score: 85},
+		{Class: "Star Destroyer", Spaceships: 2, Score: 63},
+	}, fleet.Classes)
+	assert.Equal(t, uint(2), fleet.Weakest.SpaceshipID)
+
+	empty := engine.Fleet(nil)
+	assert.Zero(t, empty.Score)
+	assert.Nil(t, empty.Weakest)
+}
+
+func TestParse(t *testing.T) {
+
+	testCases := []struct {
+		name string
+		yaml string
+		err  bool
+	}{
+		{name: "default rules", yaml: string(defaultRules)},
+		{name: "invalid yaml", yaml: "weights: [", err: true},
+		{name: "zero weights", yaml: "weights:\n  status: 0\n", err: true},
+		{name: "negative weight", yaml: "weights:\n  status: 1\n  crew: -1\n", err: true},
+		{name: "status score above one", yaml: "weights:\n  status: 1\nstatus:\n  operational: 2\n", err: true},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		_, err := Parse([]byte(test.yaml))
+		if test.err {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestFile_Reload(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("weights:\n  status: 1\nstatus:\n  Operational: 1\n"), 0o644))
+
+	file, err := NewFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), file.Rules().Status["operational"])
+
+	// modified file is reloaded without restart
+	modified := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(path, []byte("weights:\n  status: 1\nstatus:\n  operational: 0.5\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	assert.Equal(t, 0.5, file.Rules().Status["operational"])
+
+	// invalid file keeps previous rules
+	modified = modified.Add(time.Minute)
+	require.NoError(t, os.WriteFile(path, []byte("weights: ["), 0o644))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	assert.Equal(t, 0.5, file.Rules().Status["operational"])
+
+	_, err = NewFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
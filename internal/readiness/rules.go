@@ -0,0 +1,194 @@
+package readiness
+
+import (
+	_ "embed"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// errors prefix
+	readinessErrorPrefix = "[readiness]"
+
+	//go:embed rules.yaml
+	defaultRules []byte
+)
+
+// configurable rules of scoring
+type Rules struct {
+	// weights by factor name
+	Weights     map[string]float64 `yaml:"weights"`
+	Status      map[string]float64 `yaml:"status"`
+	Crew        CrewRules          `yaml:"crew"`
+	Firepower   FirepowerRules     `yaml:"firepower"`
+	Maintenance MaintenanceRules   `yaml:"maintenance"`
+	Levels      []Level            `yaml:"levels"`
+}
+
+type CrewRules struct {
+	Unrated float64 `yaml:"unrated"`
+}
+
+type FirepowerRules struct {
+	Unrated float64 `yaml:"unrated"`
+}
+
+type MaintenanceRules struct {
+	Severity map[string]float64 `yaml:"severity"`
+	Overdue  float64            `yaml:"overdue"`
+}
+
+// named level of score
+type Level struct {
+	Name string  `yaml:"name"`
+	Min  float64 `yaml:"min"`
+}
+
+// source of rules which may change while app is running
+type Source interface {
+	Rules() *Rules
+}
+
+// rules are static source of themselves
+func (r *Rules) Rules() *Rules {
+	return r
+}
+
+// level of score, levels are sorted by min descending
+func (r *Rules) Level(score float64) string {
+	for _, l := range r.Levels {
+		if score >= l.Min {
+			return l.Name
+		}
+	}
+	return ""
+}
+
+// rules shipped with app
+func DefaultRules() *Rules {
+	rules, err := Parse(defaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+// parse and validate yaml rules
+func Parse(data []byte) (*Rules, error) {
+
+	rules := &Rules{}
+	err := yaml.Unmarshal(data, rules)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: parse rules", readinessErrorPrefix)
+	}
+
+	total := 0.0
+	for name, w := range rules.Weights {
+		if w < 0 {
+			return nil, errors.Errorf("%s: weight of %s is negative", readinessErrorPrefix, name)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.Errorf("%s: all weights are zero", readinessErrorPrefix)
+	}
+
+	scores := map[string]float64{"crew unrated": rules.Crew.Unrated, "firepower unrated": rules.Firepower.Unrated}
+	for name, s := range rules.Status {
+		scores["status "+name] = s
+	}
+	for name, s := range scores {
+		if s < 0 || s > 1 {
+			return nil, errors.Errorf("%s: score of %s is out of 0..1", readinessErrorPrefix, name)
+		}
+	}
+
+	// names are matched in lower case
+	rules.Status = lowerKeys(rules.Status)
+	rules.Maintenance.Severity = lowerKeys(rules.Maintenance.Severity)
+
+	sort.SliceStable(rules.Levels, func(i, j int) bool {
+		return rules.Levels[i].Min > rules.Levels[j].Min
+	})
+
+	return rules, nil
+}
+
+func lowerKeys(m map[string]float64) map[string]float64 {
+	res := make(map[string]float64, len(m))
+	for k, v := range m {
+		res[key(k)] = v
+	}
+	return res
+}
+
+// key of status or severity name in rules, e.g. "In Progress" is in_progress
+func key(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+}
+
+// rules of yaml file, file is reloaded when it is modified,
+// invalid file is logged and previous rules are kept
+type File struct {
+	path string
+
+	mu      sync.Mutex
+	rules   *Rules
+	modTime time.Time
+}
+
+func NewFile(path string) (*File, error) {
+	f := &File{path: path}
+	err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Rules() *Rules {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err == nil && !info.ModTime().Equal(f.modTime) {
+		err = f.load()
+	}
+	if err != nil {
+		log.Println(err)
+	}
+
+	return f.rules
+}
+
+func (f *File) load() error {
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "%s: stat rules", readinessErrorPrefix)
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "%s: read rules", readinessErrorPrefix)
+	}
+
+	rules, err := Parse(data)
+	if err != nil {
+		// file is not read again until it is modified
+		f.modTime = info.ModTime()
+		return errors.Wrapf(err, "%s", f.path)
+	}
+
+	f.rules = rules
+	f.modTime = info.ModTime()
+
+	return nil
+}
@@ -0,0 +1,18 @@
+// Package cache is read-through cache of repositories
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// storage of cached values, e.g. in-process lru or redis
+type Cache interface {
+	// value of key and whether it is found
+	Get(context.Context, string) ([]byte, bool, error)
+	// set value of key for ttl, zero ttl for no expiration
+	Set(context.Context, string, []byte, time.Duration) error
+	Delete(context.Context, ...string) error
+	// delete all values
+	Purge(context.Context) error
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	require.NoError(t, lru.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, lru.Set(ctx, "b", []byte("2"), 0))
+
+	// read of a makes b the least recently used
+	v, ok, err := lru.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(v))
+
+	require.NoError(t, lru.Set(ctx, "c", []byte("3"), 0))
+	assert.Equal(t, 2, lru.Len())
+	_, ok, _ = lru.Get(ctx, "b")
+	assert.False(t, ok)
+
+	// entry expires after ttl, entry without ttl doesn't
+	now = now.Add(time.Minute)
+	_, ok, _ = lru.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok, _ = lru.Get(ctx, "c")
+	assert.True(t, ok)
+
+	require.NoError(t, lru.Delete(ctx, "c", "missing"))
+	_, ok, _ = lru.Get(ctx, "c")
+	assert.False(t, ok)
+
+	require.NoError(t, lru.Set(ctx, "d", []byte("4"), 0))
+	require.NoError(t, lru.Purge(ctx))
+	assert.Zero(t, lru.Len())
+}
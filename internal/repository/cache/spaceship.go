@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// errors prefix
+	cacheErrorPrefix = "[repository.cache]"
+
+	// test interface
+	_ service.SpaceshipRepository = (*SpaceshipRepo)(nil)
+	_ service.SpaceshipCache      = (*SpaceshipRepo)(nil)
+)
+
+// spaceship repository which caches spaceships by id, lists are not cached,
+// cache errors fall back to repository
+type SpaceshipRepo struct {
+	service.SpaceshipRepository
+
+	cache Cache
+	ttl   time.Duration
+	group singleflight.Group
+	// incremented on every invalidation, loads started before it are not cached
+	// and are not shared with requests after it
+	generation atomic.Uint64
+	// guards check of generation with set and increment of generation with delete
+	mu sync.Mutex
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	loads  atomic.Int64
+}
+
+func NewSpaceshipRepo(repository service.SpaceshipRepository, cache Cache, ttl time.Duration) *SpaceshipRepo {
+	return &SpaceshipRepo{SpaceshipRepository: repository, cache: cache, ttl: ttl}
+}
+
+// spaceships written inside Atomic, they are invalidated again after commit or rollback
+type atomicWrites struct {
+	mu  sync.Mutex
+	ids []uint
+}
+
+type atomicContextKey struct{}
+
+func spaceshipKey(tenantID uint, id uint) string {
+	return fmt.Sprintf("spaceship:%d:%d", tenantID, id)
+}
+
+func (repo *SpaceshipRepo) GetById(ctx context.Context, id uint) (*domain.Spaceship, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: scope", cacheErrorPrefix)
+	}
+	key := spaceshipKey(tenantID, id)
+
+	// uncommitted writes of transaction are not cached
+	if _, ok := ctx.Value(atomicContextKey{}).(*atomicWrites); ok {
+		return repo.SpaceshipRepository.GetById(ctx, id)
+	}
+
+	data, ok, err := repo.cache.Get(ctx, key)
+	if err != nil {
+		log.Println(errors.Wrapf(err, "%s: get %s", cacheErrorPrefix, key))
+	}
+	if ok {
+		spaceship := &domain.Spaceship{}
+		err = json.Unmarshal(data, spaceship)
+		if err == nil {
+			repo.hits.Add(1)
+			return spaceship, nil
+		}
+		log.Println(errors.Wrapf(err, "%s: decode %s", cacheErrorPrefix, key))
+	}
+	repo.misses.Add(1)
+
+	// concurrent misses of key wait for one load
+	generation := repo.generation.Load()
+	v, err, _ := repo.group.Do(fmt.Sprintf("%s@%d", key, generation), func() (interface{}, error) {
+		repo.loads.Add(1)
+
+		spaceship, err := repo.SpaceshipRepository.GetById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(spaceship)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: encode %s", cacheErrorPrefix, key)
+		}
+
+		// spaceship could be changed while it was loaded
+		repo.mu.Lock()
+		if repo.generation.Load() == generation {
+			err = repo.cache.Set(ctx, key, data, repo.ttl)
+			if err != nil {
+				log.Println(errors.Wrapf(err, "%s: set %s", cacheErrorPrefix, key))
+			}
+		}
+		repo.mu.Unlock()
+
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// every caller gets own copy
+	spaceship := &domain.Spaceship{}
+	err = json.Unmarshal(v.([]byte), spaceship)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: decode %s", cacheErrorPrefix, key)
+	}
+
+	return spaceship, nil
+}
+
+func (repo *SpaceshipRepo) Update(ctx context.Context, spaceship *domain.Spaceship) error {
+	err := repo.SpaceshipRepository.Update(ctx, spaceship)
+	return repo.invalidate(ctx, spaceship.ID, err)
+}
+
+func (repo *SpaceshipRepo) Delete(ctx context.Context, spaceship *domain.Spaceship) error {
+	err := repo.SpaceshipRepository.Delete(ctx, spaceship)
+	return repo.invalidate(ctx, spaceship.ID, err)
+}
+
+func (repo *SpaceshipRepo) UpdateImage(ctx context.Context, id uint, image string) error {
+	err := repo.SpaceshipRepository.UpdateImage(ctx, id, image)
+	return repo.invalidate(ctx, id, err)
+}
+
+func (repo *SpaceshipRepo) Appraise(ctx context.Context, valuation *domain.Valuation) error {
+	err := repo.SpaceshipRepository.Appraise(ctx, valuation)
+	return repo.invalidate(ctx, valuation.SpaceshipID, err)
+}
+
+func (repo *SpaceshipRepo) SetStatus(ctx context.Context, id uint, status domain.SpaceshipStatus) error {
+	err := repo.SpaceshipRepository.SetStatus(ctx, id, status)
+	return repo.invalidate(ctx, id, err)
+}
+
+func (repo *SpaceshipRepo) ReportPosition(ctx context.Context, position *domain.Position) error {
+	err := repo.SpaceshipRepository.ReportPosition(ctx, position)
+	return repo.invalidate(ctx, position.SpaceshipID, err)
+}
+
+// spaceships are invalidated when writes become visible to other requests,
+// nested Atomic joins writes of outer one
+func (repo *SpaceshipRepo) Atomic(ctx context.Context, fn func(context.Context) error) error {
+	if _, ok := ctx.Value(atomicContextKey{}).(*atomicWrites); ok {
+		return repo.SpaceshipRepository.Atomic(ctx, fn)
+	}
+	writes := &atomicWrites{}
+	err := repo.SpaceshipRepository.Atomic(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, atomicContextKey{}, writes))
+	})
+	for _, id := range writes.ids {
+		err = repo.invalidate(ctx, id, err)
+	}
+	return err
+}
+
+// invalidate spaceship after write, write may be partially done on error
+func (repo *SpaceshipRepo) invalidate(ctx context.Context, id uint, err error) error {
+	if writes, ok := ctx.Value(atomicContextKey{}).(*atomicWrites); ok {
+		writes.mu.Lock()
+		writes.ids = append(writes.ids, id)
+		writes.mu.Unlock()
+	}
+	invalidateErr := repo.Invalidate(ctx, id)
+	if err != nil {
+		return err
+	}
+	return invalidateErr
+}
+
+// invalidate spaceship of tenant from context, e.g. after its status is changed by work order
+func (repo *SpaceshipRepo) Invalidate(ctx context.Context, id uint) error {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "%s: scope", cacheErrorPrefix)
+	}
+	key := spaceshipKey(tenantID, id)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.generation.Add(1)
+	err = repo.cache.Delete(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete %s", cacheErrorPrefix, key)
+	}
+
+	return nil
+}
+
+// invalidate spaceships of all tenants, e.g. after classes are normalized
+func (repo *SpaceshipRepo) Purge(ctx context.Context) error {
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.generation.Add(1)
+	err := repo.cache.Purge(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "%s: purge", cacheErrorPrefix)
+	}
+
+	return nil
+}
+
+func (repo *SpaceshipRepo) Stats() domain.CacheStats {
+	return domain.CacheStats{
+		Enabled: true,
+		Hits:    repo.hits.Load(),
+		Misses:  repo.misses.Load(),
+		Shared:  repo.misses.Load() - repo.loads.Load(),
+	}
+}
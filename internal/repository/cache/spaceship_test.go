@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpaceshipRepo_GetById(t *testing.T) {
+
+	ctx := tenant.WithID(context.Background(), 1)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	repo := NewSpaceshipRepo(spaceshipRepo, NewLRU(10), time.Minute)
+
+	spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+	spaceshipRepo.On("GetById", ctx, uint(2)).Return(nil, domain.ErrNotFound).Twice()
+
+	// second read is served from cache
+	for i := 0; i < 2; i++ {
+		spaceship, err := repo.GetById(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Executor", spaceship.Name)
+	}
+
+	// changes of returned spaceship are not cached
+	spaceship, _ := repo.GetById(ctx, 1)
+	spaceship.Name = "Devastator"
+	spaceship, _ = repo.GetById(ctx, 1)
+	assert.Equal(t, "Executor", spaceship.Name)
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetById(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	}
+
+	// spaceships are cached per tenant
+	other := tenant.WithID(context.Background(), 2)
+	spaceshipRepo.On("GetById", other, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Tantive IV"}, nil).Once()
+	spaceship, err := repo.GetById(other, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Tantive IV", spaceship.Name)
+
+	stats := repo.Stats()
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Equal(t, int64(4), stats.Misses)
+}
+
+func TestSpaceshipRepo_Invalidation(t *testing.T) {
+
+	ctx := tenant.WithID(context.Background(), 1)
+
+	testCases := []struct {
+		name  string
+		write func(*SpaceshipRepo, *mocks.SpaceshipRepository) error
+	}{
+		{
+			name: "update",
+			write: func(repo *SpaceshipRepo, spaceshipRepo *mocks.SpaceshipRepository) error {
+				spaceshipRepo.On("Update", ctx, mock.Anything).Return(nil)
+				return repo.Update(ctx, &domain.Spaceship{ID: 1, Name: "Devastator"})
+			},
+		},
+		{
+			name: "update image",
+			write: func(repo *SpaceshipRepo, spaceshipRepo *mocks.SpaceshipRepository) error {
+				spaceshipRepo.On("UpdateImage", ctx, uint(1), "executor.png").Return(nil)
+				return repo.UpdateImage(ctx, 1, "executor.png")
+			},
+		},
+		{
+			name: "delete",
+			write: func(repo *SpaceshipRepo, spaceshipRepo *mocks.SpaceshipRepository) error {
+				spaceshipRepo.On("Delete", ctx, mock.Anything).Return(nil)
+				return repo.Delete(ctx, &domain.Spaceship{ID: 1})
+			},
+		},
+		{
+			name: "failed update",
+			write: func(repo *SpaceshipRepo, spaceshipRepo *mocks.SpaceshipRepository) error {
+				spaceshipRepo.On("Update", ctx, mock.Anything).Return(domain.ErrSpaceshipExists)
+				err := repo.Update(ctx, &domain.Spaceship{ID: 1})
+				assert.ErrorIs(t, err, domain.ErrSpaceshipExists)
+				return nil
+			},
+		},
+		{
+			name: "invalidation by work order",
+			write: func(repo *SpaceshipRepo, _ *mocks.SpaceshipRepository) error {
+				return repo.Invalidate(ctx, 1)
+			},
+		},
+		{
+			name: "purge by normalization",
+			write: func(repo *SpaceshipRepo, _ *mocks.SpaceshipRepository) error {
+				return repo.Purge(ctx)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		repo := NewSpaceshipRepo(spaceshipRepo, NewLRU(10), time.Hour)
+
+		spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+		_, err := repo.GetById(ctx, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, test.write(repo, spaceshipRepo))
+
+		// spaceship is loaded again after write
+		spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator"}, nil).Once()
+		spaceship, err := repo.GetById(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Devastator", spaceship.Name)
+	}
+}
+
+func TestSpaceshipRepo_Atomic(t *testing.T) {
+
+	ctx := tenant.WithID(context.Background(), 1)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	repo := NewSpaceshipRepo(spaceshipRepo, NewLRU(10), time.Hour)
+
+	spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+	_, err := repo.GetById(ctx, 1)
+	require.NoError(t, err)
+
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		// commit fails after writes
+		return domain.ErrConfig
+	})
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(atomicContextKey{}) != nil })
+	spaceshipRepo.On("Update", inTx, mock.Anything).Return(nil)
+	spaceshipRepo.On("GetById", inTx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator"}, nil).Twice()
+
+	err = repo.Atomic(ctx, func(ctx context.Context) error {
+		err := repo.Update(ctx, &domain.Spaceship{ID: 1, Name: "Devastator"})
+		if err != nil {
+			return err
+		}
+		// uncommitted spaceship is read from transaction and is not cached
+		for i := 0; i < 2; i++ {
+			spaceship, err := repo.GetById(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, "Devastator", spaceship.Name)
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, domain.ErrConfig)
+
+	// rolled back spaceship is loaded again
+	spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+	spaceship, err := repo.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Executor", spaceship.Name)
+}
+
+func TestSpaceshipRepo_StaleLoad(t *testing.T) {
+
+	ctx := tenant.WithID(context.Background(), 1)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	repo := NewSpaceshipRepo(spaceshipRepo, NewLRU(10), time.Hour)
+
+	// load of old spaceship is blocked until update is done
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	spaceshipRepo.On("GetById", ctx, uint(1)).Run(func(mock.Arguments) {
+		close(loading)
+		<-release
+	}).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+	spaceshipRepo.On("Update", ctx, mock.Anything).Return(nil).Once()
+
+	old := make(chan *domain.Spaceship)
+	go func() {
+		spaceship, _ := repo.GetById(ctx, 1)
+		old <- spaceship
+	}()
+	<-loading
+	require.NoError(t, repo.Update(ctx, &domain.Spaceship{ID: 1, Name: "Devastator"}))
+
+	// read after update doesn't join load which started before it
+	spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator"}, nil).Once()
+	spaceship, err := repo.GetById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Devastator", spaceship.Name)
+
+	close(release)
+	assert.Equal(t, "Executor", (<-old).Name)
+
+	// old spaceship is not cached by load which finished after update
+	for i := 0; i < 3; i++ {
+		spaceship, err = repo.GetById(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Devastator", spaceship.Name)
+	}
+}
+
+func TestSpaceshipRepo_Singleflight(t *testing.T) {
+
+	ctx := tenant.WithID(context.Background(), 1)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	repo := NewSpaceshipRepo(spaceshipRepo, NewLRU(10), time.Hour)
+
+	const readers = 10
+	started := sync.WaitGroup{}
+	started.Add(readers)
+	release := make(chan struct{})
+	spaceshipRepo.On("GetById", ctx, uint(1)).Run(func(mock.Arguments) {
+		<-release
+	}).Return(&domain.Spaceship{ID: 1, Name: "Executor"}, nil).Once()
+
+	done := sync.WaitGroup{}
+	for i := 0; i < readers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			spaceship, err := repo.GetById(ctx, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Executor", spaceship.Name)
+		}()
+	}
+	started.Wait()
+	// let readers reach the load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	stats := repo.Stats()
+	assert.Equal(t, int64(readers), stats.Hits+stats.Misses)
+	assert.Equal(t, stats.Misses-1, stats.Shared)
+}
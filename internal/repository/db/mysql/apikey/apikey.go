@@ -0,0 +1,167 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	apiKeyErrorPrefix = "[repository.db.mysql.apikey]"
+
+	// test interface
+	_ service.APIKeyRepository = (*APIKeyMysqlRepo)(nil)
+)
+
+type APIKeyMysqlRepo struct {
+	db *mysql.DB
+}
+
+// api_keys table
+type APIKey struct {
+	ID               uint   `gorm:"primaryKey"`
+	UserID           uint   `gorm:"index"`
+	ServiceAccountID uint   `gorm:"index"`
+	Name             string `gorm:"size:256"`
+	Prefix           string `gorm:"size:16"`
+	Hash             string `gorm:"size:64;uniqueIndex"`
+	// comma separated scopes
+	Scopes     string `gorm:"size:1024"`
+	ExpiresAt  int64
+	LastUsedAt int64
+	CreatedAt  int64
+}
+
+func NewAPIKeyRepo(db *mysql.DB) *APIKeyMysqlRepo {
+	return &APIKeyMysqlRepo{db}
+}
+
+// get keys of user, keys of service accounts are not included
+func (repo *APIKeyMysqlRepo) GetByUser(ctx context.Context, userID uint) ([]*domain.APIKey, error) {
+	keysDb := []APIKey{}
+	res := repo.db.WithContext(ctx).Where("user_id = ? AND service_account_id = 0", userID).Order("id").Find(&keysDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get by user", apiKeyErrorPrefix)
+	}
+	keys := make([]*domain.APIKey, 0, res.RowsAffected)
+	for i := range keysDb {
+		keys = append(keys, apiKeyToDomain(&keysDb[i]))
+	}
+	return keys, nil
+}
+
+// get keys of service account
+func (repo *APIKeyMysqlRepo) GetByServiceAccount(ctx context.Context, accountID uint) ([]*domain.APIKey, error) {
+	keysDb := []APIKey{}
+	res := repo.db.WithContext(ctx).Where("service_account_id = ?", accountID).Order("id").Find(&keysDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get by service account", apiKeyErrorPrefix)
+	}
+	keys := make([]*domain.APIKey, 0, res.RowsAffected)
+	for i := range keysDb {
+		keys = append(keys, apiKeyToDomain(&keysDb[i]))
+	}
+	return keys, nil
+}
+
+// get key by id
+func (repo *APIKeyMysqlRepo) GetById(ctx context.Context, id uint) (*domain.APIKey, error) {
+	keyDb := APIKey{ID: id}
+	err := repo.db.WithContext(ctx).First(&keyDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by id", apiKeyErrorPrefix)
+	}
+	return apiKeyToDomain(&keyDb), nil
+}
+
+// get key by hash
+func (repo *APIKeyMysqlRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	keyDb := APIKey{}
+	err := repo.db.WithContext(ctx).Where("hash = ?", hash).First(&keyDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by hash", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", apiKeyErrorPrefix)
+	}
+	return apiKeyToDomain(&keyDb), nil
+}
+
+// create key
+func (repo *APIKeyMysqlRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	keyDb := APIKey{
+		UserID:           key.UserID,
+		ServiceAccountID: key.ServiceAccountID,
+		Name:             key.Name,
+		Prefix:           key.Prefix,
+		Hash:             key.Hash,
+		Scopes:           strings.Join(key.Scopes, ","),
+		ExpiresAt:        key.ExpiresAt,
+		CreatedAt:        key.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&keyDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	key.ID = keyDb.ID
+	return nil
+}
+
+// delete key
+func (repo *APIKeyMysqlRepo) Delete(ctx context.Context, id uint) error {
+	res := repo.db.WithContext(ctx).Delete(&APIKey{ID: id})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: delete", apiKeyErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: delete", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// delete all keys of service account
+func (repo *APIKeyMysqlRepo) DeleteByServiceAccount(ctx context.Context, accountID uint) error {
+	err := repo.db.WithContext(ctx).Where("service_account_id = ?", accountID).Delete(&APIKey{}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by service account", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// save last use time
+func (repo *APIKeyMysqlRepo) Touch(ctx context.Context, id uint, usedAt int64) error {
+	err := repo.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+func apiKeyToDomain(keyDb *APIKey) *domain.APIKey {
+	scopes := []string{}
+	if keyDb.Scopes != "" {
+		scopes = strings.Split(keyDb.Scopes, ",")
+	}
+	return &domain.APIKey{
+		ID:               keyDb.ID,
+		UserID:           keyDb.UserID,
+		ServiceAccountID: keyDb.ServiceAccountID,
+		Name:             keyDb.Name,
+		Prefix:           keyDb.Prefix,
+		Hash:             keyDb.Hash,
+		Scopes:           scopes,
+		ExpiresAt:        keyDb.ExpiresAt,
+		LastUsedAt:       keyDb.LastUsedAt,
+		CreatedAt:        keyDb.CreatedAt,
+	}
+}
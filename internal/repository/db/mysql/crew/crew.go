@@ -0,0 +1,418 @@
+package crew
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	crewErrorPrefix = "[repository.db.mysql.crew]"
+
+	// test interface
+	_ service.CrewRepository = (*CrewMysqlRepo)(nil)
+)
+
+type CrewMysqlRepo struct {
+	db *mysql.DB
+}
+
+// models for orm, all tables are scoped by tenant
+
+// crew_members table, spaceship of active assignment is kept to count roster
+type CrewMember struct {
+	ID            uint   `gorm:"primaryKey"`
+	TenantID      uint   `gorm:"uniqueIndex:idx_crew_members_tenant_service_number"`
+	ServiceNumber string `gorm:"size:64;uniqueIndex:idx_crew_members_tenant_service_number"`
+	Name          string `gorm:"size:256"`
+	Rank          uint
+	Specialty     string `gorm:"size:256"`
+	SpaceshipID   uint   `gorm:"index"`
+	EnlistedAt    int64
+	DischargedAt  int64
+}
+
+// crew_assignments table, history of service on spaceships
+type CrewAssignment struct {
+	ID           uint `gorm:"primaryKey"`
+	TenantID     uint `gorm:"index"`
+	CrewMemberID uint `gorm:"index"`
+	SpaceshipID  uint `gorm:"index"`
+	StartedAt    int64
+	EndedAt      int64
+}
+
+func NewCrewRepo(db *mysql.DB) *CrewMysqlRepo {
+	return &CrewMysqlRepo{db}
+}
+
+// query scoped by tenant from context
+func (repo *CrewMysqlRepo) scope(ctx context.Context) (*gorm.DB, uint, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: scope", crewErrorPrefix)
+	}
+	return repo.db.Conn(ctx).Where("tenant_id = ?", tenantID), tenantID, nil
+}
+
+// get crew members matching filter
+func (repo *CrewMysqlRepo) GetAll(ctx context.Context, filter domain.CrewFilter) ([]*domain.CrewMember, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.SpaceshipID != 0 {
+		query = query.Where("spaceship_id = ?", filter.SpaceshipID)
+	}
+	if !filter.Discharged {
+		query = query.Where("discharged_at = 0")
+	}
+
+	membersDb := []CrewMember{}
+	err = query.Order("id").Find(&membersDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", crewErrorPrefix)
+	}
+
+	members := make([]*domain.CrewMember, 0, len(membersDb))
+	for i := range membersDb {
+		members = append(members, memberToDomain(&membersDb[i]))
+	}
+
+	return members, nil
+}
+
+func (repo *CrewMysqlRepo) GetById(ctx context.Context, id uint) (*domain.CrewMember, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	memberDb := CrewMember{}
+	err = query.Where("id = ?", id).First(&memberDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", crewErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", crewErrorPrefix)
+	}
+
+	return memberToDomain(&memberDb), nil
+}
+
+// assignments of crew member, the oldest first
+func (repo *CrewMysqlRepo) GetAssignments(ctx context.Context, crewMemberID uint) ([]*domain.CrewAssignment, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assignmentsDb := []CrewAssignment{}
+	err = query.Where("crew_member_id = ?", crewMemberID).Order("started_at, id").Find(&assignmentsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get assignments", crewErrorPrefix)
+	}
+
+	assignments := make([]*domain.CrewAssignment, 0, len(assignmentsDb))
+	for _, a := range assignmentsDb {
+		assignments = append(assignments, &domain.CrewAssignment{
+			ID:           a.ID,
+			CrewMemberID: a.CrewMemberID,
+			SpaceshipID:  a.SpaceshipID,
+			StartedAt:    a.StartedAt,
+			EndedAt:      a.EndedAt,
+		})
+	}
+
+	return assignments, nil
+}
+
+// enlist crew member, member is assigned to spaceship if it is set
+func (repo *CrewMysqlRepo) Create(ctx context.Context, member *domain.CrewMember) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		var count int64
+		err := tx.Model(&CrewMember{}).Where("tenant_id = ? AND service_number = ?", tenantID, member.ServiceNumber).Count(&count).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: create check service number", crewErrorPrefix)
+		}
+		if count > 0 {
+			return domain.ErrServiceNumberUsed
+		}
+
+		memberDb := CrewMember{
+			TenantID:      tenantID,
+			ServiceNumber: member.ServiceNumber,
+			Name:          member.Name,
+			Rank:          uint(member.Rank),
+			Specialty:     member.Specialty,
+			EnlistedAt:    member.EnlistedAt,
+		}
+		err = tx.Create(&memberDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: create", crewErrorPrefix)
+		}
+
+		if member.SpaceshipID != 0 {
+			err = assign(tx, tenantID, &memberDb, member.SpaceshipID, member.EnlistedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		member.ID = memberDb.ID
+		member.TenantID = tenantID
+
+		return nil
+	})
+}
+
+// transfer crew member to spaceship, active assignment is ended
+func (repo *CrewMysqlRepo) Transfer(ctx context.Context, id uint, spaceshipID uint, at int64) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		memberDb, err := activeMember(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if memberDb.SpaceshipID == spaceshipID {
+			return domain.ErrCrewAssigned
+		}
+
+		err = endAssignment(tx, tenantID, memberDb, at)
+		if err != nil {
+			return err
+		}
+
+		return assign(tx, tenantID, memberDb, spaceshipID, at)
+	})
+}
+
+// discharge crew member from service, active assignment is ended
+func (repo *CrewMysqlRepo) Discharge(ctx context.Context, id uint, at int64) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		memberDb, err := activeMember(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		err = endAssignment(tx, tenantID, memberDb, at)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(memberDb).Update("discharged_at", at).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: discharge", crewErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// crew member of tenant who is not discharged
+func activeMember(tx *gorm.DB, tenantID uint, id uint) (*CrewMember, error) {
+
+	memberDb := CrewMember{}
+	err := tx.Where("id = ? AND tenant_id = ?", id, tenantID).First(&memberDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get crew member", crewErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get crew member", crewErrorPrefix)
+	}
+	if memberDb.DischargedAt != 0 {
+		return nil, domain.ErrCrewDischarged
+	}
+
+	return &memberDb, nil
+}
+
+// assign crew member to spaceship of tenant, roster can't exceed crew of spaceship
+func assign(tx *gorm.DB, tenantID uint, memberDb *CrewMember, spaceshipID uint, at int64) error {
+
+	// spaceship row is locked, so concurrent assignments are counted one by one
+	spaceship := struct {
+		ID   uint
+		Crew uint
+	}{}
+	err := tx.Table("spaceships").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", spaceshipID, tenantID).Take(&spaceship).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Wrapf(domain.ErrNotFound, "%s: assign get spaceship", crewErrorPrefix)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: assign get spaceship", crewErrorPrefix)
+	}
+
+	roster, err := countRoster(tx, tenantID, spaceshipID)
+	if err != nil {
+		return err
+	}
+	if uint(roster) >= spaceship.Crew {
+		return domain.ErrCrewComplete
+	}
+
+	err = tx.Create(&CrewAssignment{
+		TenantID:     tenantID,
+		CrewMemberID: memberDb.ID,
+		SpaceshipID:  spaceshipID,
+		StartedAt:    at,
+	}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: assign", crewErrorPrefix)
+	}
+
+	err = tx.Model(memberDb).Update("spaceship_id", spaceshipID).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: assign", crewErrorPrefix)
+	}
+
+	return nil
+}
+
+// end active assignment of crew member if any
+func endAssignment(tx *gorm.DB, tenantID uint, memberDb *CrewMember, at int64) error {
+
+	if memberDb.SpaceshipID == 0 {
+		return nil
+	}
+
+	err := tx.Model(&CrewAssignment{}).
+		Where("tenant_id = ? AND crew_member_id = ? AND ended_at = 0", tenantID, memberDb.ID).
+		Update("ended_at", at).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: end assignment", crewErrorPrefix)
+	}
+
+	err = tx.Model(memberDb).Update("spaceship_id", 0).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: end assignment", crewErrorPrefix)
+	}
+
+	return nil
+}
+
+// number of crew members assigned to spaceship
+func (repo *CrewMysqlRepo) CountRoster(ctx context.Context, spaceshipID uint) (int, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var roster int64
+	err = query.Model(&CrewMember{}).Where("spaceship_id = ?", spaceshipID).Count(&roster).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count roster", crewErrorPrefix)
+	}
+
+	return int(roster), nil
+}
+
+// number of crew members assigned to every manned spaceship, keyed by spaceship id
+func (repo *CrewMysqlRepo) CountRosters(ctx context.Context) (map[uint]int, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsDb := []struct {
+		SpaceshipID uint
+		Roster      int
+	}{}
+	err = query.Model(&CrewMember{}).Select("spaceship_id, COUNT(*) AS roster").
+		Where("spaceship_id <> 0").Group("spaceship_id").Scan(&rowsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: count rosters", crewErrorPrefix)
+	}
+
+	rosters := make(map[uint]int, len(rowsDb))
+	for _, r := range rowsDb {
+		rosters[r.SpaceshipID] = r.Roster
+	}
+
+	return rosters, nil
+}
+
+// end assignments of crew members of spaceship, crew stays in service without assignment
+func (repo *CrewMysqlRepo) Unassign(ctx context.Context, spaceshipID uint, at int64) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Model(&CrewAssignment{}).Where("tenant_id = ? AND spaceship_id = ? AND ended_at = 0", tenantID, spaceshipID).
+			Update("ended_at", at).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: unassign end assignments", crewErrorPrefix)
+		}
+
+		err = tx.Model(&CrewMember{}).Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipID).
+			Update("spaceship_id", 0).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: unassign", crewErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// count crew members assigned to spaceship in transaction of caller
+func countRoster(tx *gorm.DB, tenantID uint, spaceshipID uint) (int64, error) {
+	var roster int64
+	err := tx.Model(&CrewMember{}).Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipID).Count(&roster).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count roster", crewErrorPrefix)
+	}
+	return roster, nil
+}
+
+func memberToDomain(memberDb *CrewMember) *domain.CrewMember {
+	return &domain.CrewMember{
+		ID:            memberDb.ID,
+		TenantID:      memberDb.TenantID,
+		Name:          memberDb.Name,
+		Rank:          domain.CrewRank(memberDb.Rank),
+		Specialty:     memberDb.Specialty,
+		ServiceNumber: memberDb.ServiceNumber,
+		SpaceshipID:   memberDb.SpaceshipID,
+		EnlistedAt:    memberDb.EnlistedAt,
+		DischargedAt:  memberDb.DischargedAt,
+	}
+}
@@ -0,0 +1,244 @@
+package hyperspace
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	hyperspaceErrorPrefix = "[repository.db.mysql.hyperspace]"
+
+	// test interface
+	_ service.HyperspaceRepository = (*HyperspaceMysqlRepo)(nil)
+)
+
+type HyperspaceMysqlRepo struct {
+	db *mysql.DB
+}
+
+// models for orm, galaxy is shared by all tenants, only the latest uploaded one is kept
+// hyperspace_galaxies, hyperspace_systems, hyperspace_lanes, hyperlane_closures
+
+// hyperspace_galaxies table
+type HyperspaceGalaxy struct {
+	ID         uint `gorm:"primaryKey"`
+	JumpHours  float64
+	UploadedAt int64
+	Version    int64
+}
+
+// hyperspace_systems table
+type HyperspaceSystem struct {
+	ID     uint   `gorm:"primaryKey"`
+	Name   string `gorm:"size:128"`
+	Sector string `gorm:"size:128"`
+	X      float64
+	Y      float64
+	Z      float64
+}
+
+// hyperspace_lanes table
+type HyperspaceLane struct {
+	ID uint `gorm:"primaryKey"`
+	// from and to are reserved words of sql
+	From   string `gorm:"column:from_system;size:128"`
+	To     string `gorm:"column:to_system;size:128"`
+	Length float64
+	Speed  float64
+}
+
+// hyperlane_closures table, closures are kept when galaxy is uploaded again
+type HyperlaneClosure struct {
+	ID        uint   `gorm:"primaryKey"`
+	From      string `gorm:"column:from_system;size:128"`
+	To        string `gorm:"column:to_system;size:128"`
+	Reason    string `gorm:"size:1024"`
+	Until     int64  `gorm:"column:closed_until;index"`
+	CreatedAt int64
+}
+
+func NewHyperspaceRepo(db *mysql.DB) *HyperspaceMysqlRepo {
+	return &HyperspaceMysqlRepo{db}
+}
+
+// get uploaded galaxy, not found if galaxy of data file was never replaced
+func (repo *HyperspaceMysqlRepo) GetGalaxy(ctx context.Context) (*domain.Galaxy, error) {
+
+	galaxyDb := HyperspaceGalaxy{}
+	err := repo.db.WithContext(ctx).Order("id desc").First(&galaxyDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get galaxy", hyperspaceErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get galaxy", hyperspaceErrorPrefix)
+	}
+
+	systemsDb := []HyperspaceSystem{}
+	err = repo.db.WithContext(ctx).Order("id").Find(&systemsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get systems", hyperspaceErrorPrefix)
+	}
+
+	lanesDb := []HyperspaceLane{}
+	err = repo.db.WithContext(ctx).Order("id").Find(&lanesDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get lanes", hyperspaceErrorPrefix)
+	}
+
+	galaxy := &domain.Galaxy{
+		Systems:    make([]domain.StarSystem, 0, len(systemsDb)),
+		Lanes:      make([]domain.Hyperlane, 0, len(lanesDb)),
+		JumpHours:  galaxyDb.JumpHours,
+		UploadedAt: galaxyDb.UploadedAt,
+		Version:    galaxyDb.Version,
+	}
+	for _, s := range systemsDb {
+		galaxy.Systems = append(galaxy.Systems, domain.StarSystem{
+			Name:        s.Name,
+			Sector:      s.Sector,
+			Coordinates: domain.Coordinates{X: s.X, Y: s.Y, Z: s.Z},
+		})
+	}
+	for _, l := range lanesDb {
+		galaxy.Lanes = append(galaxy.Lanes, domain.Hyperlane{From: l.From, To: l.To, Length: l.Length, Speed: l.Speed})
+	}
+
+	return galaxy, nil
+}
+
+// version of galaxy, not found if galaxy of data file was never replaced
+func (repo *HyperspaceMysqlRepo) GetVersion(ctx context.Context) (int64, error) {
+
+	galaxyDb := HyperspaceGalaxy{}
+	err := repo.db.WithContext(ctx).Select("version").Order("id desc").First(&galaxyDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, errors.Wrapf(domain.ErrNotFound, "%s: get version", hyperspaceErrorPrefix)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: get version", hyperspaceErrorPrefix)
+	}
+
+	return galaxyDb.Version, nil
+}
+
+// replace uploaded galaxy, version of galaxy is set to the next one
+func (repo *HyperspaceMysqlRepo) SaveGalaxy(ctx context.Context, galaxy *domain.Galaxy) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// concurrent uploads wait for the lock, so every upload gets its own version
+		var version int64
+		err := tx.Model(&HyperspaceGalaxy{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: save galaxy version", hyperspaceErrorPrefix)
+		}
+
+		for _, model := range []interface{}{&HyperspaceGalaxy{}, &HyperspaceSystem{}, &HyperspaceLane{}} {
+			err = tx.Where("1 = 1").Delete(model).Error
+			if err != nil {
+				return errors.Wrapf(err, "%s: save galaxy delete", hyperspaceErrorPrefix)
+			}
+		}
+
+		err = tx.Create(&HyperspaceGalaxy{JumpHours: galaxy.JumpHours, UploadedAt: galaxy.UploadedAt, Version: version + 1}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: save galaxy", hyperspaceErrorPrefix)
+		}
+		galaxy.Version = version + 1
+
+		if len(galaxy.Systems) > 0 {
+			systemsDb := make([]HyperspaceSystem, 0, len(galaxy.Systems))
+			for _, s := range galaxy.Systems {
+				systemsDb = append(systemsDb, HyperspaceSystem{
+					Name:   s.Name,
+					Sector: s.Sector,
+					X:      s.Coordinates.X,
+					Y:      s.Coordinates.Y,
+					Z:      s.Coordinates.Z,
+				})
+			}
+			err = tx.CreateInBatches(systemsDb, 100).Error
+			if err != nil {
+				return errors.Wrapf(err, "%s: save systems", hyperspaceErrorPrefix)
+			}
+		}
+
+		if len(galaxy.Lanes) > 0 {
+			lanesDb := make([]HyperspaceLane, 0, len(galaxy.Lanes))
+			for _, l := range galaxy.Lanes {
+				lanesDb = append(lanesDb, HyperspaceLane{From: l.From, To: l.To, Length: l.Length, Speed: l.Speed})
+			}
+			err = tx.CreateInBatches(lanesDb, 100).Error
+			if err != nil {
+				return errors.Wrapf(err, "%s: save lanes", hyperspaceErrorPrefix)
+			}
+		}
+
+		return nil
+	})
+}
+
+// get closures active at time, the oldest first
+func (repo *HyperspaceMysqlRepo) GetClosures(ctx context.Context, at int64) ([]*domain.HyperlaneClosure, error) {
+
+	closuresDb := []HyperlaneClosure{}
+	err := repo.db.WithContext(ctx).Where("closed_until = 0 OR closed_until > ?", at).Order("id").Find(&closuresDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get closures", hyperspaceErrorPrefix)
+	}
+
+	closures := make([]*domain.HyperlaneClosure, 0, len(closuresDb))
+	for _, c := range closuresDb {
+		closures = append(closures, &domain.HyperlaneClosure{
+			ID:        c.ID,
+			From:      c.From,
+			To:        c.To,
+			Reason:    c.Reason,
+			Until:     c.Until,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+
+	return closures, nil
+}
+
+func (repo *HyperspaceMysqlRepo) CreateClosure(ctx context.Context, closure *domain.HyperlaneClosure) error {
+
+	closureDb := HyperlaneClosure{
+		From:      closure.From,
+		To:        closure.To,
+		Reason:    closure.Reason,
+		Until:     closure.Until,
+		CreatedAt: closure.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&closureDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create closure", hyperspaceErrorPrefix)
+	}
+
+	closure.ID = closureDb.ID
+
+	return nil
+}
+
+func (repo *HyperspaceMysqlRepo) DeleteClosure(ctx context.Context, id uint) error {
+
+	res := repo.db.WithContext(ctx).Where("id = ?", id).Delete(&HyperlaneClosure{})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: delete closure", hyperspaceErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: delete closure", hyperspaceErrorPrefix)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package hyperspace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/hyperspace"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperspaceMysqlRepo(t *testing.T) {
+
+	repo := hyperspace.NewHyperspaceRepo(mysqltest.Open(t))
+	ctx := context.Background()
+
+	// nothing is uploaded by default
+	_, err := repo.GetGalaxy(ctx)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.GetVersion(ctx)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	first := &domain.Galaxy{
+		Systems:    []domain.StarSystem{{Name: "Hoth"}, {Name: "Endor"}},
+		Lanes:      []domain.Hyperlane{{From: "Hoth", To: "Endor", Length: 10, Speed: 1}},
+		UploadedAt: 100,
+	}
+	require.NoError(t, repo.SaveGalaxy(ctx, first))
+	assert.Equal(t, int64(1), first.Version)
+	second := &domain.Galaxy{
+		Systems: []domain.StarSystem{
+			{Name: "Coruscant", Sector: "Core Worlds"},
+			{Name: "Jakku", Sector: "Western Reaches", Coordinates: domain.Coordinates{X: 3, Y: 4}},
+		},
+		Lanes:      []domain.Hyperlane{{From: "Coruscant", To: "Jakku", Length: 5, Speed: 2}},
+		JumpHours:  1,
+		UploadedAt: 200,
+	}
+	require.NoError(t, repo.SaveGalaxy(ctx, second))
+	assert.Equal(t, int64(2), second.Version)
+
+	// the latest upload replaces previous one
+	galaxy, err := repo.GetGalaxy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second, galaxy)
+	version, err := repo.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+
+	closures := []*domain.HyperlaneClosure{
+		{From: "Coruscant", To: "Jakku", Reason: "blockade", CreatedAt: 100},
+		{From: "Coruscant", To: "Jakku", Until: 150, CreatedAt: 100},
+		{From: "Coruscant", To: "Jakku", Until: 300, CreatedAt: 100},
+	}
+	for _, c := range closures {
+		require.NoError(t, repo.CreateClosure(ctx, c))
+		assert.NotZero(t, c.ID)
+	}
+
+	active, err := repo.GetClosures(ctx, 200)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.HyperlaneClosure{closures[0], closures[2]}, active)
+
+	require.NoError(t, repo.DeleteClosure(ctx, closures[0].ID))
+	assert.ErrorIs(t, repo.DeleteClosure(ctx, closures[0].ID), domain.ErrNotFound)
+	active, err = repo.GetClosures(ctx, 200)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.HyperlaneClosure{closures[2]}, active)
+}
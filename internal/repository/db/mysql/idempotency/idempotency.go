@@ -0,0 +1,140 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/idempotency"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errors prefix
+	idempotencyErrorPrefix = "[repository.db.mysql.idempotency]"
+
+	// test interface
+	_ idempotency.Store = (*IdempotencyMysqlRepo)(nil)
+)
+
+type IdempotencyMysqlRepo struct {
+	db *mysql.DB
+}
+
+// idempotency_records table
+type IdempotencyRecord struct {
+	Owner       string `gorm:"primaryKey;size:128"`
+	Key         string `gorm:"primaryKey;column:idempotency_key;size:255"`
+	Fingerprint string `gorm:"size:64"`
+	StatusCode  int
+	ContentType string `gorm:"size:255"`
+	Body        []byte
+	CreatedAt   int64
+	ExpiresAt   int64 `gorm:"index"`
+}
+
+func NewIdempotencyRepo(db *mysql.DB) *IdempotencyMysqlRepo {
+	return &IdempotencyMysqlRepo{db}
+}
+
+// create record, unexpired record of owner and key is returned instead
+func (repo *IdempotencyMysqlRepo) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+
+	var existing *domain.IdempotencyRecord
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// expired record is replaced
+		err := tx.Where("owner = ? AND idempotency_key = ? AND expires_at <= ?", record.Owner, record.Key, record.CreatedAt).
+			Delete(&IdempotencyRecord{}).Error
+		if err != nil {
+			return err
+		}
+
+		recordDb := fromDomain(record)
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&recordDb)
+		if res.Error != nil || res.RowsAffected == 1 {
+			return res.Error
+		}
+
+		existingDb := IdempotencyRecord{}
+		err = tx.Where("owner = ? AND idempotency_key = ?", record.Owner, record.Key).Take(&existingDb).Error
+		if err != nil {
+			return err
+		}
+		existing = existingDb.toDomain()
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: reserve", idempotencyErrorPrefix)
+	}
+
+	return existing, nil
+}
+
+// store response of record
+func (repo *IdempotencyMysqlRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	res := repo.db.WithContext(ctx).Model(&IdempotencyRecord{}).
+		Where("owner = ? AND idempotency_key = ?", record.Owner, record.Key).
+		Updates(map[string]interface{}{
+			"status_code":  record.StatusCode,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+			"expires_at":   record.ExpiresAt,
+		})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: complete", idempotencyErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: complete", idempotencyErrorPrefix)
+	}
+	return nil
+}
+
+// delete record in flight
+func (repo *IdempotencyMysqlRepo) Release(ctx context.Context, owner string, key string) error {
+	err := repo.db.WithContext(ctx).
+		Where("owner = ? AND idempotency_key = ? AND status_code = 0", owner, key).
+		Delete(&IdempotencyRecord{}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: release", idempotencyErrorPrefix)
+	}
+	return nil
+}
+
+// delete records expired by now
+func (repo *IdempotencyMysqlRepo) DeleteExpired(ctx context.Context, now int64) (int64, error) {
+	res := repo.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&IdempotencyRecord{})
+	if res.Error != nil {
+		return 0, errors.Wrapf(res.Error, "%s: delete expired", idempotencyErrorPrefix)
+	}
+	return res.RowsAffected, nil
+}
+
+func fromDomain(record *domain.IdempotencyRecord) IdempotencyRecord {
+	return IdempotencyRecord{
+		Owner:       record.Owner,
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		StatusCode:  record.StatusCode,
+		ContentType: record.ContentType,
+		Body:        record.Body,
+		CreatedAt:   record.CreatedAt,
+		ExpiresAt:   record.ExpiresAt,
+	}
+}
+
+func (r *IdempotencyRecord) toDomain() *domain.IdempotencyRecord {
+	return &domain.IdempotencyRecord{
+		Owner:       r.Owner,
+		Key:         r.Key,
+		Fingerprint: r.Fingerprint,
+		StatusCode:  r.StatusCode,
+		ContentType: r.ContentType,
+		Body:        r.Body,
+		CreatedAt:   r.CreatedAt,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
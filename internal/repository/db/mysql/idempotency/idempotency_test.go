@@ -0,0 +1,60 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/idempotency"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMysqlRepo(t *testing.T) {
+
+	db := mysqltest.Open(t)
+	repo := idempotency.NewIdempotencyRepo(db)
+	ctx := context.Background()
+
+	record := &domain.IdempotencyRecord{Owner: "user:tarkin@empire.gov", Key: "key-1", Fingerprint: "a", CreatedAt: 100, ExpiresAt: 160}
+	existing, err := repo.Reserve(ctx, record)
+	require.NoError(t, err)
+	require.Nil(t, existing)
+
+	// key in flight is returned to duplicate
+	existing, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Owner: record.Owner, Key: record.Key, Fingerprint: "a", CreatedAt: 110})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.Completed())
+
+	// the same key of another owner is independent
+	existing, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Owner: "apikey:1", Key: record.Key, CreatedAt: 110, ExpiresAt: 170})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	record.StatusCode = http.StatusCreated
+	record.ContentType = "application/json"
+	record.Body = []byte(`{"success":true}`)
+	record.ExpiresAt = 1000
+	require.NoError(t, repo.Complete(ctx, record))
+
+	// completed record is not released
+	require.NoError(t, repo.Release(ctx, record.Owner, record.Key))
+	existing, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Owner: record.Owner, Key: record.Key, CreatedAt: 200})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, http.StatusCreated, existing.StatusCode)
+	assert.Equal(t, record.Body, existing.Body)
+
+	deleted, err := repo.DeleteExpired(ctx, 500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// expired record is replaced
+	existing, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Owner: record.Owner, Key: record.Key, Fingerprint: "b", CreatedAt: 1000, ExpiresAt: 1060})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
@@ -0,0 +1,393 @@
+package migrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/apikey"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/crew"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/hyperspace"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/idempotency"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mission"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/organization"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/report"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/serviceaccount"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/shipclass"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/signingkey"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/workorder"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errors prefix
+	migrateErrorPrefix = "[repository.db.mysql.migrate]"
+)
+
+// all orm models which tables are managed by automigration
+func models() []interface{} {
+	return []interface{}{
+		&user.User{},
+		&user.UserToken{},
+		&signingkey.SigningKey{},
+		&apikey.APIKey{},
+		&organization.Organization{},
+		&organization.Membership{},
+		&serviceaccount.ServiceAccount{},
+		&spaceship.Spaceship{},
+		&spaceship.SpaceshipArmament{},
+		&spaceship.SpaceshipArmamentQty{},
+		&spaceship.SpaceshipValuation{},
+		&spaceship.SpaceshipPosition{},
+		&crew.CrewMember{},
+		&crew.CrewAssignment{},
+		&workorder.WorkOrder{},
+		&workorder.WorkOrderPart{},
+		&shipclass.ShipClass{},
+		&shipclass.ShipClassKey{},
+		&shipclass.ShipClassArmament{},
+		&spaceship.SpaceshipAudit{},
+		&report.FleetSnapshot{},
+		&idempotency.IdempotencyRecord{},
+		&hyperspace.HyperspaceGalaxy{},
+		&hyperspace.HyperspaceSystem{},
+		&hyperspace.HyperspaceLane{},
+		&hyperspace.HyperlaneClosure{},
+		&mission.Mission{},
+		&mission.MissionSpaceship{},
+	}
+}
+
+// data migration which runs once when table is created
+type tableMigration struct {
+	model interface{}
+	run   func(tx *gorm.DB) error
+}
+
+func tableMigrations() []tableMigration {
+	return []tableMigration{
+		{
+			// catalog starts with default classes, existing spaceships are linked to them
+			model: &shipclass.ShipClass{},
+			run:   seedShipClasses,
+		},
+		{
+			// history starts with existing spaceships, so they fall into report windows
+			model: &spaceship.SpaceshipAudit{},
+			run:   seedSpaceshipAudits,
+		},
+		{
+			// valuation history starts with current values of spaceships
+			model: &spaceship.SpaceshipValuation{},
+			run:   seedSpaceshipValuations,
+		},
+	}
+}
+
+// data migration which runs once when column is added to existing table
+type columnMigration struct {
+	model  interface{}
+	column string
+	run    func(tx *gorm.DB) error
+}
+
+func columnMigrations() []columnMigration {
+	return []columnMigration{
+		{
+			// users registered before verification was introduced are considered verified
+			model:  &user.User{},
+			column: "EmailVerifiedAt",
+			run: func(tx *gorm.DB) error {
+				return tx.Model(&user.User{}).Where("email_verified_at = 0").
+					Update("email_verified_at", gorm.Expr("created_at")).Error
+			},
+		},
+		{
+			// records created before tenants were introduced belong to default organization
+			model:  &spaceship.Spaceship{},
+			column: "TenantID",
+			run:    assignDefaultOrganization,
+		},
+		{
+			// armament of catalog seeded before damage ratings gets default ratings
+			model:  &shipclass.ShipClassArmament{},
+			column: "Damage",
+			run:    seedArmamentDamage,
+		},
+		{
+			// float values are converted to fixed-point decimals of default currency, valued on migration
+			model:  &spaceship.Spaceship{},
+			column: "Value",
+			run: func(tx *gorm.DB) error {
+				return tx.Model(&spaceship.Spaceship{}).Where("1 = 1").Updates(map[string]interface{}{
+					"value_amount": gorm.Expr("ROUND(COALESCE(value, 0) * ?)", domain.DecimalScale),
+					"currency":     domain.DefaultCurrency,
+					"valued_at":    time.Now().Unix(),
+				}).Error
+			},
+		},
+		{
+			model:  &report.FleetSnapshot{},
+			column: "Value",
+			run: func(tx *gorm.DB) error {
+				return tx.Model(&report.FleetSnapshot{}).Where("1 = 1").
+					Update("value_amount", gorm.Expr("ROUND(COALESCE(value, 0) * ?)", domain.DecimalScale)).Error
+			},
+		},
+		{
+			// classes of catalog seeded before depreciation get default schedules
+			model:  &shipclass.ShipClass{},
+			column: "DepreciationMethod",
+			run:    seedDepreciation,
+		},
+		{
+			// classes of catalog seeded before hyperdrive ratings get default ratings
+			model:  &shipclass.ShipClass{},
+			column: "HyperdriveRating",
+			run: func(tx *gorm.DB) error {
+				for _, class := range domain.DefaultShipClasses() {
+					err := tx.Model(&shipclass.ShipClass{}).Where("name = ?", class.Name).
+						Update("hyperdrive_rating", class.Hyperdrive).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// galaxy uploaded before versions is the first version
+			model:  &hyperspace.HyperspaceGalaxy{},
+			column: "Version",
+			run: func(tx *gorm.DB) error {
+				return tx.Model(&hyperspace.HyperspaceGalaxy{}).Where("version = 0").Update("version", 1).Error
+			},
+		},
+	}
+}
+
+// idempotent data fix which runs on every migration, before column migrations
+type repair struct {
+	name string
+	run  func(tx *gorm.DB) error
+}
+
+func repairs() []repair {
+	return []repair{
+		{
+			// users created before roles, or by versions which added role column without default, are plain users
+			name: "user roles",
+			run: func(tx *gorm.DB) error {
+				return tx.Model(&user.User{}).Where("role = ?", uint(domain.UserRoleUndefined)).
+					Update("role", uint(domain.UserRoleUser)).Error
+			},
+		},
+	}
+}
+
+// index which is replaced by another one and must be dropped after automigration
+type obsoleteIndex struct {
+	model interface{}
+	name  string
+}
+
+func obsoleteIndexes() []obsoleteIndex {
+	return []obsoleteIndex{
+		// names are unique per tenant
+		{model: &spaceship.Spaceship{}, name: "idx_spaceships_name"},
+		{model: &spaceship.SpaceshipArmament{}, name: "idx_spaceship_armaments_title"},
+	}
+}
+
+// create default classes of catalog and normalize classes of spaceships
+func seedShipClasses(tx *gorm.DB) error {
+
+	for _, class := range domain.DefaultShipClasses() {
+		err := shipclass.Create(tx, class)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := shipclass.Normalize(tx)
+	return err
+}
+
+// move all spaceships with armament to default organization, existing users become its members
+func assignDefaultOrganization(tx *gorm.DB) error {
+
+	org := organization.Organization{}
+	err := tx.Where(organization.Organization{Name: domain.DefaultOrganizationName}).
+		Attrs(organization.Organization{CreatedAt: time.Now().Unix()}).
+		FirstOrCreate(&org).Error
+	if err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&spaceship.Spaceship{}, &spaceship.SpaceshipArmament{}, &spaceship.SpaceshipArmamentQty{}} {
+		err = tx.Model(model).Where("tenant_id IS NULL OR tenant_id = 0").Update("tenant_id", org.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	users := []user.User{}
+	err = tx.Find(&users).Error
+	if err != nil || len(users) == 0 {
+		return err
+	}
+
+	// users keep write access they had before tenants, only admins manage organization
+	roles := map[domain.UserRole]domain.OrgRole{
+		domain.UserRoleUndefined: domain.OrgRoleMember,
+		domain.UserRoleUser:      domain.OrgRoleMember,
+		domain.UserRoleOfficer:   domain.OrgRoleMember,
+		domain.UserRoleAdmin:     domain.OrgRoleAdmin,
+	}
+	memberships := make([]organization.Membership, 0, len(users))
+	for _, u := range users {
+		role, ok := roles[domain.UserRole(u.Role)]
+		if !ok {
+			return errors.Errorf("user %d has unknown role %d", u.ID, u.Role)
+		}
+		memberships = append(memberships, organization.Membership{
+			OrgID:     org.ID,
+			UserID:    u.ID,
+			Role:      uint(role),
+			CreatedAt: u.CreatedAt,
+		})
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&memberships).Error
+}
+
+func seedArmamentDamage(tx *gorm.DB) error {
+	for _, class := range domain.DefaultShipClasses() {
+		for _, a := range class.Armament {
+			err := tx.Model(&shipclass.ShipClassArmament{}).Where("title = ? AND damage = 0", a.Title).
+				Update("damage", a.Damage).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func seedDepreciation(tx *gorm.DB) error {
+	for _, class := range domain.DefaultShipClasses() {
+		err := tx.Model(&shipclass.ShipClass{}).Where("name = ?", class.Name).Updates(map[string]interface{}{
+			"depreciation_method": uint(class.Depreciation.Method),
+			"depreciation_rate":   int64(class.Depreciation.Rate),
+			"salvage_rate":        int64(class.Depreciation.Salvage),
+			"damage_rate":         int64(class.Depreciation.Damage),
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedSpaceshipValuations(tx *gorm.DB) error {
+	spaceships := []spaceship.Spaceship{}
+	err := tx.Select("id", "tenant_id", "value_amount", "currency", "valued_at").Find(&spaceships).Error
+	if err != nil || len(spaceships) == 0 {
+		return err
+	}
+	valuations := make([]spaceship.SpaceshipValuation, 0, len(spaceships))
+	for _, s := range spaceships {
+		valuations = append(valuations, spaceship.SpaceshipValuation{
+			TenantID:    s.TenantID,
+			SpaceshipID: s.ID,
+			Amount:      s.Value,
+			Currency:    s.Currency,
+			Source:      uint(domain.ValuationSourceInitial),
+			AppraisedAt: s.ValuedAt,
+		})
+	}
+	return tx.CreateInBatches(&valuations, 100).Error
+}
+
+func seedSpaceshipAudits(tx *gorm.DB) error {
+	spaceships := []spaceship.Spaceship{}
+	err := tx.Select("id", "tenant_id", "status").Find(&spaceships).Error
+	if err != nil || len(spaceships) == 0 {
+		return err
+	}
+	audits := make([]spaceship.SpaceshipAudit, 0, len(spaceships))
+	now := time.Now().Unix()
+	for _, s := range spaceships {
+		audits = append(audits, spaceship.SpaceshipAudit{
+			TenantID:    s.TenantID,
+			SpaceshipID: s.ID,
+			Action:      uint(domain.SpaceshipAuditActionCreated),
+			Status:      s.Status,
+			At:          now,
+		})
+	}
+	return tx.CreateInBatches(&audits, 100).Error
+}
+
+// Run automigrates database schema
+func Run(ctx context.Context, db *mysql.DB) error {
+	tx := db.WithContext(ctx)
+
+	// find data migrations for columns which are about to be added to existing tables
+	pending := []columnMigration{}
+	for _, m := range columnMigrations() {
+		if tx.Migrator().HasTable(m.model) && !tx.Migrator().HasColumn(m.model, m.column) {
+			pending = append(pending, m)
+		}
+	}
+
+	// find data migrations for tables which are about to be created
+	created := []tableMigration{}
+	for _, m := range tableMigrations() {
+		if !tx.Migrator().HasTable(m.model) {
+			created = append(created, m)
+		}
+	}
+
+	err := tx.AutoMigrate(models()...)
+	if err != nil {
+		return errors.Wrapf(err, "%s: automigrate", migrateErrorPrefix)
+	}
+
+	for _, index := range obsoleteIndexes() {
+		if tx.Migrator().HasIndex(index.model, index.name) {
+			err = tx.Migrator().DropIndex(index.model, index.name)
+			if err != nil {
+				return errors.Wrapf(err, "%s: drop index %s", migrateErrorPrefix, index.name)
+			}
+		}
+	}
+
+	for _, r := range repairs() {
+		err = r.run(tx)
+		if err != nil {
+			return errors.Wrapf(err, "%s: repair %s", migrateErrorPrefix, r.name)
+		}
+	}
+
+	for _, m := range pending {
+		err = m.run(tx)
+		if err != nil {
+			return errors.Wrapf(err, "%s: migrate column %s", migrateErrorPrefix, m.column)
+		}
+	}
+
+	for _, m := range created {
+		err = m.run(tx)
+		if err != nil {
+			return errors.Wrapf(err, "%s: migrate table of %T", migrateErrorPrefix, m.model)
+		}
+	}
+
+	return nil
+}
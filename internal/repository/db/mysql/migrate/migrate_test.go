@@ -0,0 +1,95 @@
+package migrate_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/migrate"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/organization"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// models of the first release
+type legacyUser struct {
+	ID        uint   `gorm:"primaryKey"`
+	Email     string `gorm:"index:,unique;size:256"`
+	Password  string `gorm:"size:256"`
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+func (legacyUser) TableName() string { return "users" }
+
+type legacyArmament struct {
+	ID    uint   `gorm:"primaryKey"`
+	Title string `gorm:"size:256;uniqueIndex"`
+}
+
+func (legacyArmament) TableName() string { return "spaceship_armaments" }
+
+type legacyArmamentQty struct {
+	SpaceshipID         uint `gorm:"index:,unique,composite:myname"`
+	SpaceshipArmamentID uint `gorm:"index:,unique,composite:myname"`
+	Qty                 uint
+}
+
+func (legacyArmamentQty) TableName() string { return "spaceship_armament_qties" }
+
+type legacySpaceship struct {
+	ID     uint   `gorm:"primaryKey"`
+	Name   string `gorm:"size:256;uniqueIndex"`
+	Class  string `gorm:"size:256"`
+	Crew   uint
+	Image  string `gorm:"size:256"`
+	Value  float64
+	Status uint
+}
+
+func (legacySpaceship) TableName() string { return "spaceships" }
+
+// database with schema and data of the first release
+func openLegacy(t *testing.T) *mysql.DB {
+	t.Helper()
+
+	gormDb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "fleet.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, gormDb.AutoMigrate(&legacyUser{}, &legacyArmament{}, &legacyArmamentQty{}, &legacySpaceship{}))
+	require.NoError(t, gormDb.Create(&legacyUser{Email: "tarkin@empire.gov", Password: "x", CreatedAt: 100}).Error)
+	require.NoError(t, gormDb.Create(&legacySpaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 35000, Value: 1999.99, Status: 1}).Error)
+
+	return &mysql.DB{DB: gormDb}
+}
+
+func TestRun_Legacy(t *testing.T) {
+
+	db := openLegacy(t)
+	require.NoError(t, migrate.Run(context.Background(), db))
+
+	// users of the first release are plain users
+	u := user.User{}
+	require.NoError(t, db.First(&u, "email = ?", "tarkin@empire.gov").Error)
+	assert.Equal(t, uint(domain.UserRoleUser), u.Role)
+
+	// users keep write access to spaceships of default organization
+	membership := organization.Membership{}
+	require.NoError(t, db.First(&membership, "user_id = ?", u.ID).Error)
+	assert.Equal(t, uint(domain.OrgRoleMember), membership.Role)
+	org := organization.Organization{}
+	require.NoError(t, db.First(&org, membership.OrgID).Error)
+	assert.Equal(t, domain.DefaultOrganizationName, org.Name)
+
+	// migration is repeatable
+	require.NoError(t, migrate.Run(context.Background(), db))
+}
@@ -0,0 +1,404 @@
+package mission
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	missionErrorPrefix = "[repository.db.mysql.mission]"
+
+	// test interface
+	_ service.MissionRepository = (*MissionMysqlRepo)(nil)
+)
+
+type MissionMysqlRepo struct {
+	db *mysql.DB
+}
+
+// models for orm, all tables are scoped by tenant
+// missions -> mission_spaceships -> spaceships
+
+// missions table
+type Mission struct {
+	ID           uint   `gorm:"primaryKey"`
+	TenantID     uint   `gorm:"index"`
+	Objective    string `gorm:"size:1024"`
+	Status       uint   `gorm:"index"`
+	StartsAt     int64  `gorm:"index"`
+	EndsAt       int64  `gorm:"index"`
+	MinFirepower float64
+	MinCrew      uint
+	CreatedAt    int64
+	StartedAt    int64
+	ClosedAt     int64
+}
+
+// mission_spaceships table
+type MissionSpaceship struct {
+	MissionID   uint `gorm:"primaryKey;autoIncrement:false"`
+	SpaceshipID uint `gorm:"primaryKey;autoIncrement:false;index"`
+	TenantID    uint `gorm:"index"`
+}
+
+func NewMissionRepo(db *mysql.DB) *MissionMysqlRepo {
+	return &MissionMysqlRepo{db}
+}
+
+// query scoped by tenant from context
+func (repo *MissionMysqlRepo) scope(ctx context.Context) (*gorm.DB, uint, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: scope", missionErrorPrefix)
+	}
+	return repo.db.Conn(ctx).Where("tenant_id = ?", tenantID), tenantID, nil
+}
+
+// get missions matching filter, the earliest window first
+func (repo *MissionMysqlRepo) GetAll(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.SpaceshipID != 0 {
+		query = query.Where("id IN (?)", repo.db.Conn(ctx).Model(&MissionSpaceship{}).Select("mission_id").
+			Where("tenant_id = ? AND spaceship_id = ?", tenantID, filter.SpaceshipID))
+	}
+	if filter.Open {
+		query = query.Where("status IN ?", openStatuses())
+	} else if filter.Status != domain.MissionStatusUndefined {
+		query = query.Where("status = ?", uint(filter.Status))
+	}
+	if filter.From != 0 {
+		query = query.Where("ends_at > ?", filter.From)
+	}
+	if filter.To != 0 {
+		query = query.Where("starts_at < ?", filter.To)
+	}
+
+	missionsDb := []Mission{}
+	err = query.Order("starts_at, id").Find(&missionsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", missionErrorPrefix)
+	}
+
+	return repo.withSpaceships(ctx, tenantID, missionsDb)
+}
+
+func (repo *MissionMysqlRepo) GetById(ctx context.Context, id uint) (*domain.Mission, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missionDb := Mission{}
+	err = query.Where("id = ?", id).First(&missionDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", missionErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", missionErrorPrefix)
+	}
+
+	missions, err := repo.withSpaceships(ctx, tenantID, []Mission{missionDb})
+	if err != nil {
+		return nil, err
+	}
+
+	return missions[0], nil
+}
+
+// get mission and lock its row until transaction of Atomic ends
+func (repo *MissionMysqlRepo) GetForUpdate(ctx context.Context, id uint) (*domain.Mission, error) {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missionDb, err := getForUpdate(repo.db.Conn(ctx), tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	missions, err := repo.withSpaceships(ctx, tenantID, []Mission{*missionDb})
+	if err != nil {
+		return nil, err
+	}
+
+	return missions[0], nil
+}
+
+// plan mission, spaceships are locked by caller, so concurrent bookings of them are serialized
+func (repo *MissionMysqlRepo) Create(ctx context.Context, mission *domain.Mission) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := book(tx, tenantID, mission)
+		if err != nil {
+			return err
+		}
+
+		missionDb := missionToDb(mission)
+		missionDb.TenantID = tenantID
+		err = tx.Create(&missionDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: create", missionErrorPrefix)
+		}
+
+		mission.ID = missionDb.ID
+		mission.TenantID = tenantID
+
+		return saveSpaceships(tx, tenantID, mission)
+	})
+}
+
+// reschedule or reassign planned mission
+func (repo *MissionMysqlRepo) Update(ctx context.Context, mission *domain.Mission) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		missionDb, err := getForUpdate(tx, tenantID, mission.ID)
+		if err != nil {
+			return err
+		}
+		if domain.MissionStatus(missionDb.Status) != domain.MissionStatusPlanned {
+			return domain.ErrMissionClosed
+		}
+
+		err = book(tx, tenantID, mission)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(missionDb).Select("objective", "starts_at", "ends_at", "min_firepower", "min_crew").
+			Updates(missionToDb(mission)).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", missionErrorPrefix)
+		}
+
+		err = tx.Where("mission_id = ? AND tenant_id = ?", missionDb.ID, tenantID).Delete(&MissionSpaceship{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update delete spaceships", missionErrorPrefix)
+		}
+
+		return saveSpaceships(tx, tenantID, mission)
+	})
+}
+
+// move mission to status, spaceships of activated mission are checked by caller
+func (repo *MissionMysqlRepo) SetStatus(ctx context.Context, id uint, status domain.MissionStatus, at int64) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		missionDb, err := getForUpdate(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if !domain.MissionStatus(missionDb.Status).CanMoveTo(status) {
+			return errors.Wrapf(domain.ErrMissionMove, "%s: %s to %s", missionErrorPrefix,
+				domain.MissionStatus(missionDb.Status), status)
+		}
+
+		update := map[string]interface{}{"status": uint(status)}
+		switch status {
+		case domain.MissionStatusActive:
+			update["started_at"] = at
+		case domain.MissionStatusCompleted, domain.MissionStatusAborted:
+			update["closed_at"] = at
+		}
+
+		err = tx.Model(missionDb).Updates(update).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: set status", missionErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// count active missions of spaceship
+func (repo *MissionMysqlRepo) CountActive(ctx context.Context, spaceshipID uint) (int, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = query.Model(&Mission{}).
+		Where("status = ? AND id IN (?)", uint(domain.MissionStatusActive),
+			repo.db.Conn(ctx).Model(&MissionSpaceship{}).Select("mission_id").Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipID)).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count active", missionErrorPrefix)
+	}
+
+	return int(count), nil
+}
+
+// unassign deleted spaceship from its missions
+func (repo *MissionMysqlRepo) DeleteBySpaceship(ctx context.Context, spaceshipID uint) error {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = query.Where("spaceship_id = ?", spaceshipID).Delete(&MissionSpaceship{}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete spaceship", missionErrorPrefix)
+	}
+
+	return nil
+}
+
+func openStatuses() []uint {
+	return []uint{uint(domain.MissionStatusPlanned), uint(domain.MissionStatusActive)}
+}
+
+// book spaceships for window of mission, they must be free of other open missions
+func book(tx *gorm.DB, tenantID uint, mission *domain.Mission) error {
+
+	booked := struct {
+		SpaceshipID uint
+		MissionID   uint
+	}{}
+	err := tx.Raw(`
+		SELECT ms.spaceship_id, m.id AS mission_id FROM mission_spaceships ms
+		INNER JOIN missions m ON m.id = ms.mission_id AND m.tenant_id = ?
+		WHERE ms.tenant_id = ? AND ms.spaceship_id IN ? AND m.id <> ? AND m.status IN ?
+		AND m.starts_at < ? AND m.ends_at > ?
+		ORDER BY m.starts_at, m.id LIMIT 1
+	`, tenantID, tenantID, mission.SpaceshipIDs, mission.ID, openStatuses(), mission.EndsAt, mission.StartsAt).Scan(&booked).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: get bookings", missionErrorPrefix)
+	}
+	if booked.MissionID != 0 {
+		return errors.Wrapf(domain.ErrMissionConflict, "%s: spaceship %d is booked by mission %d", missionErrorPrefix,
+			booked.SpaceshipID, booked.MissionID)
+	}
+
+	return nil
+}
+
+func getForUpdate(tx *gorm.DB, tenantID uint, id uint) (*Mission, error) {
+	missionDb := Mission{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND tenant_id = ?", id, tenantID).First(&missionDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get mission", missionErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get mission", missionErrorPrefix)
+	}
+	return &missionDb, nil
+}
+
+func saveSpaceships(tx *gorm.DB, tenantID uint, mission *domain.Mission) error {
+
+	if len(mission.SpaceshipIDs) == 0 {
+		return nil
+	}
+
+	rows := make([]MissionSpaceship, 0, len(mission.SpaceshipIDs))
+	for _, id := range mission.SpaceshipIDs {
+		rows = append(rows, MissionSpaceship{MissionID: mission.ID, SpaceshipID: id, TenantID: tenantID})
+	}
+	err := tx.Create(&rows).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: save spaceships", missionErrorPrefix)
+	}
+
+	return nil
+}
+
+func missionToDb(mission *domain.Mission) Mission {
+	return Mission{
+		Objective:    mission.Objective,
+		Status:       uint(mission.Status),
+		StartsAt:     mission.StartsAt,
+		EndsAt:       mission.EndsAt,
+		MinFirepower: mission.Requirements.MinFirepower,
+		MinCrew:      mission.Requirements.MinCrew,
+		CreatedAt:    mission.CreatedAt,
+		StartedAt:    mission.StartedAt,
+		ClosedAt:     mission.ClosedAt,
+	}
+}
+
+// convert missions to domain level with spaceships loaded by one query
+func (repo *MissionMysqlRepo) withSpaceships(ctx context.Context, tenantID uint, missionsDb []Mission) ([]*domain.Mission, error) {
+
+	missions := make([]*domain.Mission, 0, len(missionsDb))
+	if len(missionsDb) == 0 {
+		return missions, nil
+	}
+
+	ids := make([]uint, 0, len(missionsDb))
+	for _, m := range missionsDb {
+		ids = append(ids, m.ID)
+	}
+
+	rows := []MissionSpaceship{}
+	err := repo.db.Conn(ctx).Where("tenant_id = ? AND mission_id IN ?", tenantID, ids).
+		Order("mission_id, spaceship_id").Find(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get spaceships", missionErrorPrefix)
+	}
+	spaceshipsByMission := map[uint][]uint{}
+	for _, r := range rows {
+		spaceshipsByMission[r.MissionID] = append(spaceshipsByMission[r.MissionID], r.SpaceshipID)
+	}
+
+	for _, m := range missionsDb {
+		spaceshipIDs := spaceshipsByMission[m.ID]
+		if spaceshipIDs == nil {
+			spaceshipIDs = []uint{}
+		}
+		missions = append(missions, &domain.Mission{
+			ID:           m.ID,
+			TenantID:     m.TenantID,
+			Objective:    m.Objective,
+			Status:       domain.MissionStatus(m.Status),
+			StartsAt:     m.StartsAt,
+			EndsAt:       m.EndsAt,
+			SpaceshipIDs: spaceshipIDs,
+			Requirements: domain.MissionRequirements{
+				MinFirepower: m.MinFirepower,
+				MinCrew:      m.MinCrew,
+			},
+			CreatedAt: m.CreatedAt,
+			StartedAt: m.StartedAt,
+			ClosedAt:  m.ClosedAt,
+		})
+	}
+
+	return missions, nil
+}
@@ -0,0 +1,94 @@
+package mission_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mission"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissionMysqlRepo_Booking(t *testing.T) {
+
+	db := mysqltest.Open(t)
+	repo := mission.NewMissionRepo(db)
+	spaceships := spaceship.NewSpaceshipRepo(db)
+	ctx := tenant.WithID(context.Background(), 1)
+
+	executor := &domain.Spaceship{Name: "Executor", Status: domain.SpaceshipStatusOperational}
+	devastator := &domain.Spaceship{Name: "Devastator", Status: domain.SpaceshipStatusOperational}
+	for _, s := range []*domain.Spaceship{executor, devastator} {
+		require.NoError(t, spaceships.Create(ctx, s))
+	}
+
+	hoth := &domain.Mission{Objective: "Hoth", Status: domain.MissionStatusPlanned, StartsAt: 100, EndsAt: 200,
+		SpaceshipIDs: []uint{executor.ID, devastator.ID}, CreatedAt: 50}
+	require.NoError(t, repo.Create(ctx, hoth))
+	assert.NotZero(t, hoth.ID)
+	assert.Equal(t, uint(1), hoth.TenantID)
+
+	missionDb, err := repo.GetById(ctx, hoth.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hoth, missionDb)
+
+	// spaceship is booked by one mission at a time, end of window is exclusive
+	overlap := &domain.Mission{Objective: "Endor", Status: domain.MissionStatusPlanned, StartsAt: 150, EndsAt: 300,
+		SpaceshipIDs: []uint{devastator.ID}}
+	assert.ErrorIs(t, repo.Create(ctx, overlap), domain.ErrMissionConflict)
+	endor := &domain.Mission{Objective: "Endor", Status: domain.MissionStatusPlanned, StartsAt: 200, EndsAt: 300,
+		SpaceshipIDs: []uint{devastator.ID}}
+	require.NoError(t, repo.Create(ctx, endor))
+
+	// mission doesn't conflict with itself when it is rescheduled
+	endor.StartsAt, endor.EndsAt = 250, 350
+	require.NoError(t, repo.Update(ctx, endor))
+	endor.SpaceshipIDs = []uint{executor.ID, devastator.ID}
+	endor.StartsAt = 150
+	assert.ErrorIs(t, repo.Update(ctx, endor), domain.ErrMissionConflict)
+
+	missions, err := repo.GetAll(ctx, domain.MissionFilter{SpaceshipID: executor.ID, Open: true})
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, hoth.ID, missions[0].ID)
+	missions, err = repo.GetAll(ctx, domain.MissionFilter{From: 200, To: 260})
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, endor.ID, missions[0].ID)
+
+	// spaceships of active mission are counted, so they are kept from deletion
+	assert.ErrorIs(t, repo.SetStatus(ctx, hoth.ID, domain.MissionStatusCompleted, 120), domain.ErrMissionMove)
+	active, err := repo.CountActive(ctx, executor.ID)
+	require.NoError(t, err)
+	assert.Zero(t, active)
+	require.NoError(t, repo.SetStatus(ctx, hoth.ID, domain.MissionStatusActive, 120))
+	assert.ErrorIs(t, repo.Update(ctx, hoth), domain.ErrMissionClosed)
+	active, err = repo.CountActive(ctx, executor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, active)
+	active, err = repo.CountActive(tenant.WithID(context.Background(), 2), executor.ID)
+	require.NoError(t, err)
+	assert.Zero(t, active)
+
+	require.NoError(t, repo.SetStatus(ctx, hoth.ID, domain.MissionStatusCompleted, 180))
+	missionDb, err = repo.GetById(ctx, hoth.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MissionStatusCompleted, missionDb.Status)
+	assert.Equal(t, int64(120), missionDb.StartedAt)
+	assert.Equal(t, int64(180), missionDb.ClosedAt)
+
+	// completed mission releases window of its spaceships
+	require.NoError(t, repo.Create(ctx, &domain.Mission{Objective: "Bespin", Status: domain.MissionStatusPlanned, StartsAt: 100,
+		EndsAt: 200, SpaceshipIDs: []uint{executor.ID}}))
+
+	// planned mission loses deleted spaceship
+	require.NoError(t, repo.DeleteBySpaceship(ctx, devastator.ID))
+	missionDb, err = repo.GetById(ctx, endor.ID)
+	require.NoError(t, err)
+	assert.Empty(t, missionDb.SpaceshipIDs)
+}
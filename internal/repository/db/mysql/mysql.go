@@ -53,3 +53,22 @@ func (db *DB) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+// key of transaction in context
+type txContextKey struct{}
+
+// connection of context, repositories called inside Atomic join its transaction
+func (db *DB) Conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// run fn in transaction which is committed when fn returns nil, transactions of repositories
+// called with context of fn become savepoints of it
+func (db *DB) Atomic(ctx context.Context, fn func(context.Context) error) error {
+	return db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
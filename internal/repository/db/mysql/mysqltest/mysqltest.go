@@ -0,0 +1,35 @@
+// Package mysqltest provides sqlite backed database with migrated schema for tests
+package mysqltest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/migrate"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open creates sqlite database in test temp dir and migrates schema
+func Open(t testing.TB) *mysql.DB {
+	t.Helper()
+
+	gormDb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "fleet.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &mysql.DB{DB: gormDb}
+
+	err = migrate.Run(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
@@ -0,0 +1,203 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	organizationErrorPrefix = "[repository.db.mysql.organization]"
+
+	// test interface
+	_ service.OrganizationRepository = (*OrganizationMysqlRepo)(nil)
+)
+
+type OrganizationMysqlRepo struct {
+	db *mysql.DB
+}
+
+// organizations table
+type Organization struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:256;uniqueIndex"`
+	CreatedAt int64
+}
+
+// memberships table
+type Membership struct {
+	OrgID     uint `gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint `gorm:"primaryKey;autoIncrement:false;index"`
+	Role      uint
+	CreatedAt int64
+}
+
+// membership joined with organization name and user email
+type membershipRow struct {
+	Membership
+	OrgName   string
+	UserEmail string
+}
+
+func NewOrganizationRepo(db *mysql.DB) *OrganizationMysqlRepo {
+	return &OrganizationMysqlRepo{db}
+}
+
+// get all organizations
+func (repo *OrganizationMysqlRepo) GetAll(ctx context.Context) ([]*domain.Organization, error) {
+	orgsDb := []Organization{}
+	res := repo.db.WithContext(ctx).Order("id").Find(&orgsDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get all", organizationErrorPrefix)
+	}
+	orgs := make([]*domain.Organization, 0, res.RowsAffected)
+	for i := range orgsDb {
+		orgs = append(orgs, organizationToDomain(&orgsDb[i]))
+	}
+	return orgs, nil
+}
+
+// get organization by id
+func (repo *OrganizationMysqlRepo) GetById(ctx context.Context, id uint) (*domain.Organization, error) {
+	orgDb := Organization{}
+	err := repo.db.WithContext(ctx).Where("id = ?", id).First(&orgDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", organizationErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by id", organizationErrorPrefix)
+	}
+	return organizationToDomain(&orgDb), nil
+}
+
+// get organization by name
+func (repo *OrganizationMysqlRepo) GetByName(ctx context.Context, name string) (*domain.Organization, error) {
+	orgDb := Organization{}
+	err := repo.db.WithContext(ctx).Where("name = ?", name).First(&orgDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by name", organizationErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by name", organizationErrorPrefix)
+	}
+	return organizationToDomain(&orgDb), nil
+}
+
+// create organization
+func (repo *OrganizationMysqlRepo) Create(ctx context.Context, org *domain.Organization) error {
+	orgDb := Organization{
+		Name:      org.Name,
+		CreatedAt: org.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&orgDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", organizationErrorPrefix)
+	}
+	org.ID = orgDb.ID
+	return nil
+}
+
+// get membership of user in organization
+func (repo *OrganizationMysqlRepo) GetMembership(ctx context.Context, orgID uint, userID uint) (*domain.Membership, error) {
+	rows := []membershipRow{}
+	err := repo.memberships(ctx).Where("m.org_id = ? AND m.user_id = ?", orgID, userID).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get membership", organizationErrorPrefix)
+	}
+	if len(rows) == 0 {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get membership", organizationErrorPrefix)
+	}
+	return membershipToDomain(&rows[0]), nil
+}
+
+// get memberships of user, the oldest first
+func (repo *OrganizationMysqlRepo) GetMemberships(ctx context.Context, userID uint) ([]*domain.Membership, error) {
+	rows := []membershipRow{}
+	err := repo.memberships(ctx).Where("m.user_id = ?", userID).Order("m.created_at, m.org_id").Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get memberships", organizationErrorPrefix)
+	}
+	return membershipsToDomain(rows), nil
+}
+
+// get members of organization
+func (repo *OrganizationMysqlRepo) GetMembers(ctx context.Context, orgID uint) ([]*domain.Membership, error) {
+	rows := []membershipRow{}
+	err := repo.memberships(ctx).Where("m.org_id = ?", orgID).Order("m.created_at, m.user_id").Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get members", organizationErrorPrefix)
+	}
+	return membershipsToDomain(rows), nil
+}
+
+// create membership or update role of existing one
+func (repo *OrganizationMysqlRepo) SaveMembership(ctx context.Context, membership *domain.Membership) error {
+	membershipDb := Membership{
+		OrgID:     membership.OrgID,
+		UserID:    membership.UserID,
+		Role:      uint(membership.Role),
+		CreatedAt: membership.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&membershipDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: save membership", organizationErrorPrefix)
+	}
+	return nil
+}
+
+// delete membership
+func (repo *OrganizationMysqlRepo) DeleteMembership(ctx context.Context, orgID uint, userID uint) error {
+	res := repo.db.WithContext(ctx).Where("org_id = ? AND user_id = ?", orgID, userID).Delete(&Membership{})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: delete membership", organizationErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: delete membership", organizationErrorPrefix)
+	}
+	return nil
+}
+
+// memberships query joined with organizations and users
+func (repo *OrganizationMysqlRepo) memberships(ctx context.Context) *gorm.DB {
+	return repo.db.WithContext(ctx).Table("memberships m").
+		Select("m.*, o.name AS org_name, u.email AS user_email").
+		Joins("INNER JOIN organizations o ON o.id = m.org_id").
+		Joins("INNER JOIN users u ON u.id = m.user_id")
+}
+
+func organizationToDomain(orgDb *Organization) *domain.Organization {
+	return &domain.Organization{
+		ID:        orgDb.ID,
+		Name:      orgDb.Name,
+		CreatedAt: orgDb.CreatedAt,
+	}
+}
+
+func membershipToDomain(row *membershipRow) *domain.Membership {
+	return &domain.Membership{
+		OrgID:     row.OrgID,
+		UserID:    row.UserID,
+		Role:      domain.OrgRole(row.Role),
+		OrgName:   row.OrgName,
+		UserEmail: row.UserEmail,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func membershipsToDomain(rows []membershipRow) []*domain.Membership {
+	memberships := make([]*domain.Membership, 0, len(rows))
+	for i := range rows {
+		memberships = append(memberships, membershipToDomain(&rows[i]))
+	}
+	return memberships
+}
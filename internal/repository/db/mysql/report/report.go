@@ -0,0 +1,280 @@
+package report
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	reportErrorPrefix = "[repository.db.mysql.report]"
+
+	// test interface
+	_ service.ReportRepository = (*ReportMysqlRepo)(nil)
+)
+
+// aggregations over spaceships of tenant, all queries are scoped by tenant
+type ReportMysqlRepo struct {
+	db *mysql.DB
+}
+
+// fleet_snapshots table, one row per tenant and day
+type FleetSnapshot struct {
+	ID          uint  `gorm:"primaryKey"`
+	TenantID    uint  `gorm:"uniqueIndex:idx_fleet_snapshots_tenant_day"`
+	Day         int64 `gorm:"uniqueIndex:idx_fleet_snapshots_tenant_day"`
+	Spaceships  int64
+	Operational int64
+	Damaged     int64
+	// fixed-point value in units of domain decimal
+	Value   int64 `gorm:"column:value_amount"`
+	Crew    int64
+	TakenAt int64
+}
+
+// columns of group fields, selected from whitelist only
+var groupColumns = map[string]string{
+	domain.ReportGroupStatus:   "s.status",
+	domain.ReportGroupClass:    "s.class",
+	domain.ReportGroupArmament: "sa.title",
+}
+
+func NewReportRepo(db *mysql.DB) *ReportMysqlRepo {
+	return &ReportMysqlRepo{db}
+}
+
+// spaceships of tenant from context filtered by time window of report
+func (repo *ReportMysqlRepo) spaceships(ctx context.Context, q domain.ReportQuery) (*gorm.DB, uint, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: scope", reportErrorPrefix)
+	}
+	db := repo.db.WithContext(ctx)
+	query := db.Table("spaceships s").Where("s.tenant_id = ?", tenantID)
+	if q.From != 0 || q.To != 0 {
+		query = query.Where("s.id IN (?)", changed(db, tenantID, q.From, q.To))
+	}
+	return query, tenantID, nil
+}
+
+// subquery of spaceships of tenant changed within time window by audit history, zero bounds are not applied
+func changed(db *gorm.DB, tenantID uint, from int64, to int64) *gorm.DB {
+	query := db.Table("spaceship_audits").Select("spaceship_id").Where("tenant_id = ?", tenantID)
+	if from != 0 {
+		query = query.Where("at >= ?", from)
+	}
+	if to != 0 {
+		query = query.Where("at < ?", to)
+	}
+	return query
+}
+
+// group by columns of fields, aliases of columns are names of fields
+func groupBy(query *gorm.DB, group []string) (*gorm.DB, []string, error) {
+	selects := []string{}
+	for _, g := range group {
+		column, ok := groupColumns[g]
+		if !ok {
+			return nil, nil, errors.Wrapf(domain.ErrReportGroup, "%s: %q", reportErrorPrefix, g)
+		}
+		query = query.Group(column).Order(column)
+		selects = append(selects, column+" AS "+g)
+	}
+	return query, selects, nil
+}
+
+// count, value and crew of spaceships grouped by status and class, values are summed
+// as integers of fixed-point decimals and are always grouped by currency
+func (repo *ReportMysqlRepo) Fleet(ctx context.Context, q domain.ReportQuery) ([]*domain.FleetReportRow, error) {
+
+	query, _, err := repo.spaceships(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	query, selects, err := groupBy(query, q.Group)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Group("s.currency").Order("s.currency")
+
+	rows := []struct {
+		Status   uint
+		Class    string
+		Currency string
+		Count    int64
+		Value    int64
+		Crew     int64
+	}{}
+	selects = append(selects, "s.currency AS currency", "COUNT(*) AS count", "COALESCE(SUM(s.value_amount), 0) AS value", "COALESCE(SUM(s.crew), 0) AS crew")
+	err = query.Select(selects).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: fleet", reportErrorPrefix)
+	}
+
+	res := make([]*domain.FleetReportRow, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &domain.FleetReportRow{
+			Status:   domain.SpaceshipStatus(r.Status),
+			Class:    r.Class,
+			Currency: r.Currency,
+			Count:    r.Count,
+			Value:    domain.Decimal(r.Value),
+			Crew:     r.Crew,
+		})
+	}
+
+	return res, nil
+}
+
+// armament inventory totals grouped by title and optionally class
+func (repo *ReportMysqlRepo) Armament(ctx context.Context, q domain.ReportQuery) ([]*domain.ArmamentReportRow, error) {
+
+	query, tenantID, err := repo.spaceships(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	query = query.
+		Joins("INNER JOIN spaceship_armament_qties q ON q.spaceship_id = s.id AND q.tenant_id = ?", tenantID).
+		Joins("INNER JOIN spaceship_armaments sa ON sa.id = q.spaceship_armament_id AND sa.tenant_id = ?", tenantID)
+	query, selects, err := groupBy(query, q.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		Armament   string
+		Class      string
+		Qty        int64
+		Spaceships int64
+	}{}
+	selects = append(selects, "COALESCE(SUM(q.qty), 0) AS qty", "COUNT(DISTINCT s.id) AS spaceships")
+	err = query.Select(selects).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: armament", reportErrorPrefix)
+	}
+
+	res := make([]*domain.ArmamentReportRow, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &domain.ArmamentReportRow{
+			Title:      r.Armament,
+			Class:      r.Class,
+			Qty:        r.Qty,
+			Spaceships: r.Spaceships,
+		})
+	}
+
+	return res, nil
+}
+
+// operational spaceships of all spaceships grouped by class
+func (repo *ReportMysqlRepo) Readiness(ctx context.Context, q domain.ReportQuery) ([]*domain.ReadinessReportRow, error) {
+
+	query, _, err := repo.spaceships(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	query, selects, err := groupBy(query, q.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		Class       string
+		Total       int64
+		Operational int64
+	}{}
+	selects = append(selects, "COUNT(*) AS total", "COALESCE(SUM(CASE WHEN s.status = ? THEN 1 ELSE 0 END), 0) AS operational")
+	err = query.Select(strings.Join(selects, ", "), uint(domain.SpaceshipStatusOperational)).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: readiness", reportErrorPrefix)
+	}
+
+	res := make([]*domain.ReadinessReportRow, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &domain.ReadinessReportRow{
+			Class:       r.Class,
+			Total:       r.Total,
+			Operational: r.Operational,
+		})
+	}
+
+	return res, nil
+}
+
+// save snapshot of tenant from context, snapshot of the same day is replaced
+func (repo *ReportMysqlRepo) SaveSnapshot(ctx context.Context, snapshot *domain.FleetSnapshot) error {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "%s: scope", reportErrorPrefix)
+	}
+
+	err = repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"spaceships", "operational", "damaged", "value_amount", "crew", "taken_at"}),
+	}).Create(&FleetSnapshot{
+		TenantID:    tenantID,
+		Day:         snapshot.Day,
+		Spaceships:  snapshot.Spaceships,
+		Operational: snapshot.Operational,
+		Damaged:     snapshot.Damaged,
+		Value:       int64(snapshot.Value),
+		Crew:        snapshot.Crew,
+		TakenAt:     snapshot.TakenAt,
+	}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: save snapshot", reportErrorPrefix)
+	}
+
+	snapshot.TenantID = tenantID
+
+	return nil
+}
+
+// snapshots of tenant from context within days, zero bounds are not applied
+func (repo *ReportMysqlRepo) GetSnapshots(ctx context.Context, from int64, to int64) ([]*domain.FleetSnapshot, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: scope", reportErrorPrefix)
+	}
+
+	query := repo.db.WithContext(ctx).Where("tenant_id = ?", tenantID)
+	if from != 0 {
+		query = query.Where("day >= ?", from)
+	}
+	if to != 0 {
+		query = query.Where("day < ?", to)
+	}
+
+	snapshotsDb := []FleetSnapshot{}
+	err = query.Order("day").Find(&snapshotsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get snapshots", reportErrorPrefix)
+	}
+
+	snapshots := make([]*domain.FleetSnapshot, 0, len(snapshotsDb))
+	for _, s := range snapshotsDb {
+		snapshots = append(snapshots, &domain.FleetSnapshot{
+			TenantID:    s.TenantID,
+			Day:         s.Day,
+			Spaceships:  s.Spaceships,
+			Operational: s.Operational,
+			Damaged:     s.Damaged,
+			Value:       domain.Decimal(s.Value),
+			Crew:        s.Crew,
+			TakenAt:     s.TakenAt,
+		})
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,121 @@
+package serviceaccount
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	serviceAccountErrorPrefix = "[repository.db.mysql.serviceaccount]"
+
+	// test interface
+	_ service.ServiceAccountRepository = (*ServiceAccountMysqlRepo)(nil)
+)
+
+type ServiceAccountMysqlRepo struct {
+	db *mysql.DB
+}
+
+// service_accounts table
+type ServiceAccount struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrgID     uint   `gorm:"index"`
+	Name      string `gorm:"size:256"`
+	Role      uint
+	CreatedBy uint
+	CreatedAt int64
+	RevokedAt int64
+}
+
+// service account row joined with its organization
+type serviceAccountRow struct {
+	ServiceAccount
+	OrgName string
+}
+
+func NewServiceAccountRepo(db *mysql.DB) *ServiceAccountMysqlRepo {
+	return &ServiceAccountMysqlRepo{db}
+}
+
+// get service accounts of organization
+func (repo *ServiceAccountMysqlRepo) GetByOrg(ctx context.Context, orgID uint) ([]*domain.ServiceAccount, error) {
+	accountsDb := []serviceAccountRow{}
+	res := repo.accounts(ctx).Where("a.org_id = ?", orgID).Order("a.id").Find(&accountsDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get by org", serviceAccountErrorPrefix)
+	}
+	accounts := make([]*domain.ServiceAccount, 0, res.RowsAffected)
+	for i := range accountsDb {
+		accounts = append(accounts, serviceAccountToDomain(&accountsDb[i]))
+	}
+	return accounts, nil
+}
+
+// get service account by id
+func (repo *ServiceAccountMysqlRepo) GetById(ctx context.Context, id uint) (*domain.ServiceAccount, error) {
+	accountDb := serviceAccountRow{}
+	err := repo.accounts(ctx).Where("a.id = ?", id).Take(&accountDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", serviceAccountErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by id", serviceAccountErrorPrefix)
+	}
+	return serviceAccountToDomain(&accountDb), nil
+}
+
+// create service account
+func (repo *ServiceAccountMysqlRepo) Create(ctx context.Context, account *domain.ServiceAccount) error {
+	accountDb := ServiceAccount{
+		OrgID:     account.OrgID,
+		Name:      account.Name,
+		Role:      uint(account.Role),
+		CreatedBy: account.CreatedBy,
+		CreatedAt: account.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&accountDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", serviceAccountErrorPrefix)
+	}
+	account.ID = accountDb.ID
+	return nil
+}
+
+// mark service account revoked, revoked account is kept for audit
+func (repo *ServiceAccountMysqlRepo) Revoke(ctx context.Context, id uint, revokedAt int64) error {
+	res := repo.db.WithContext(ctx).Model(&ServiceAccount{}).Where("id = ? AND revoked_at = 0", id).Update("revoked_at", revokedAt)
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: revoke", serviceAccountErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: revoke", serviceAccountErrorPrefix)
+	}
+	return nil
+}
+
+// service accounts query joined with organizations
+func (repo *ServiceAccountMysqlRepo) accounts(ctx context.Context) *gorm.DB {
+	return repo.db.WithContext(ctx).Table("service_accounts a").
+		Select("a.*, o.name AS org_name").
+		Joins("INNER JOIN organizations o ON o.id = a.org_id")
+}
+
+func serviceAccountToDomain(accountDb *serviceAccountRow) *domain.ServiceAccount {
+	return &domain.ServiceAccount{
+		ID:        accountDb.ID,
+		OrgID:     accountDb.OrgID,
+		Name:      accountDb.Name,
+		Role:      domain.OrgRole(accountDb.Role),
+		OrgName:   accountDb.OrgName,
+		CreatedBy: accountDb.CreatedBy,
+		CreatedAt: accountDb.CreatedAt,
+		RevokedAt: accountDb.RevokedAt,
+	}
+}
@@ -0,0 +1,378 @@
+package shipclass
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	shipClassErrorPrefix = "[repository.db.mysql.shipclass]"
+
+	// test interface
+	_ service.ShipClassRepository = (*ShipClassMysqlRepo)(nil)
+)
+
+type ShipClassMysqlRepo struct {
+	db *mysql.DB
+}
+
+// models for orm, catalog is shared by all tenants
+// ship_classes -> ship_class_keys, ship_class_armaments
+
+// ship_classes table
+type ShipClass struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:256"`
+	MinCrew   uint
+	MaxCrew   uint
+	BaseValue float64
+	// depreciation schedule, shares in units of domain decimal
+	DepreciationMethod uint
+	DepreciationRate   int64
+	SalvageRate        int64
+	DamageRate         int64
+	HyperdriveRating   float64
+}
+
+// ship_class_keys table, normalized name and aliases of class are unique in catalog
+type ShipClassKey struct {
+	NameKey     string `gorm:"primaryKey;size:256"`
+	ShipClassID uint   `gorm:"index"`
+	// alias as entered, empty for key of name
+	Alias string `gorm:"size:256"`
+}
+
+// ship_class_armaments table
+type ShipClassArmament struct {
+	ShipClassID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Title       string `gorm:"primaryKey;size:256"`
+	Qty         uint
+	Max         uint
+	Damage      float64
+}
+
+func NewShipClassRepo(db *mysql.DB) *ShipClassMysqlRepo {
+	return &ShipClassMysqlRepo{db}
+}
+
+// get all classes of catalog ordered by name
+func (repo *ShipClassMysqlRepo) GetAll(ctx context.Context) ([]*domain.ShipClass, error) {
+
+	classesDb := []ShipClass{}
+	err := repo.db.WithContext(ctx).Order("name, id").Find(&classesDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", shipClassErrorPrefix)
+	}
+
+	return load(repo.db.WithContext(ctx), classesDb)
+}
+
+func (repo *ShipClassMysqlRepo) GetById(ctx context.Context, id uint) (*domain.ShipClass, error) {
+
+	classDb := ShipClass{}
+	err := repo.db.WithContext(ctx).Where("id = ?", id).First(&classDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", shipClassErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", shipClassErrorPrefix)
+	}
+
+	classes, err := load(repo.db.WithContext(ctx), []ShipClass{classDb})
+	if err != nil {
+		return nil, err
+	}
+
+	return classes[0], nil
+}
+
+// get class by name or alias
+func (repo *ShipClassMysqlRepo) GetByName(ctx context.Context, name string) (*domain.ShipClass, error) {
+
+	keyDb := ShipClassKey{}
+	err := repo.db.WithContext(ctx).Where("name_key = ?", domain.ShipClassKey(name)).First(&keyDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by name", shipClassErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by name", shipClassErrorPrefix)
+	}
+
+	return repo.GetById(ctx, keyDb.ShipClassID)
+}
+
+func (repo *ShipClassMysqlRepo) Create(ctx context.Context, class *domain.ShipClass) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return Create(tx, class)
+	})
+}
+
+// update class, its name and aliases are renamed on spaceships by normalization
+func (repo *ShipClassMysqlRepo) Update(ctx context.Context, class *domain.ShipClass) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		classDb := ShipClass{}
+		err := tx.Where("id = ?", class.ID).First(&classDb).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: update", shipClassErrorPrefix)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", shipClassErrorPrefix)
+		}
+
+		err = tx.Model(&classDb).Select("name", "min_crew", "max_crew", "base_value",
+			"depreciation_method", "depreciation_rate", "salvage_rate", "damage_rate", "hyperdrive_rating").Updates(classToDb(class)).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", shipClassErrorPrefix)
+		}
+
+		err = deleteRelated(tx, classDb.ID)
+		if err != nil {
+			return err
+		}
+
+		return saveRelated(tx, class)
+	})
+}
+
+// delete class which is not used by spaceships of any tenant
+func (repo *ShipClassMysqlRepo) Delete(ctx context.Context, id uint) error {
+
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		var count int64
+		err := tx.Table("spaceships").Where("class_id = ?", id).Count(&count).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete count spaceships", shipClassErrorPrefix)
+		}
+		if count > 0 {
+			return domain.ErrShipClassInUse
+		}
+
+		res := tx.Where("id = ?", id).Delete(&ShipClass{})
+		if res.Error != nil {
+			return errors.Wrapf(res.Error, "%s: delete", shipClassErrorPrefix)
+		}
+		if res.RowsAffected == 0 {
+			return errors.Wrapf(domain.ErrNotFound, "%s: delete", shipClassErrorPrefix)
+		}
+
+		return deleteRelated(tx, id)
+	})
+}
+
+// link spaceships of all tenants to classes by name or alias
+func (repo *ShipClassMysqlRepo) Normalize(ctx context.Context) (int64, error) {
+	var count int64
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = Normalize(tx)
+		return err
+	})
+	return count, err
+}
+
+// create class in transaction of caller, e.g. seed on migration
+func Create(tx *gorm.DB, class *domain.ShipClass) error {
+
+	classDb := classToDb(class)
+	err := tx.Create(&classDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", shipClassErrorPrefix)
+	}
+
+	class.ID = classDb.ID
+
+	return saveRelated(tx, class)
+}
+
+func classToDb(class *domain.ShipClass) ShipClass {
+	return ShipClass{
+		Name:               class.Name,
+		MinCrew:            class.MinCrew,
+		MaxCrew:            class.MaxCrew,
+		BaseValue:          class.BaseValue,
+		DepreciationMethod: uint(class.Depreciation.Method),
+		DepreciationRate:   int64(class.Depreciation.Rate),
+		SalvageRate:        int64(class.Depreciation.Salvage),
+		DamageRate:         int64(class.Depreciation.Damage),
+		HyperdriveRating:   class.Hyperdrive,
+	}
+}
+
+// set class id and canonical class name of spaceships which class matches name or alias,
+// count of changed spaceships is returned
+func Normalize(tx *gorm.DB) (int64, error) {
+
+	keys := []ShipClassKey{}
+	err := tx.Find(&keys).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: normalize get keys", shipClassErrorPrefix)
+	}
+	classesDb := []ShipClass{}
+	err = tx.Find(&classesDb).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: normalize get classes", shipClassErrorPrefix)
+	}
+	names := make(map[uint]string, len(classesDb))
+	for _, c := range classesDb {
+		names[c.ID] = c.Name
+	}
+
+	// spaceships are compared by normalized class in go, since collation of database may differ
+	spaceships := []struct {
+		ID      uint
+		Class   string
+		ClassID uint
+	}{}
+	err = tx.Table("spaceships").Select("id", "class", "class_id").Find(&spaceships).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: normalize get spaceships", shipClassErrorPrefix)
+	}
+
+	var count int64
+	classByKey := make(map[string]uint, len(keys))
+	for _, k := range keys {
+		classByKey[k.NameKey] = k.ShipClassID
+	}
+
+	for _, s := range spaceships {
+		classID, ok := classByKey[domain.ShipClassKey(s.Class)]
+		if !ok {
+			classID = s.ClassID
+		}
+		name, ok := names[classID]
+		if !ok || (classID == s.ClassID && name == s.Class) {
+			continue
+		}
+		err = tx.Table("spaceships").Where("id = ?", s.ID).
+			Updates(map[string]interface{}{"class_id": classID, "class": name}).Error
+		if err != nil {
+			return 0, errors.Wrapf(err, "%s: normalize spaceship", shipClassErrorPrefix)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// save keys and armament of class, name or alias of another class is refused
+func saveRelated(tx *gorm.DB, class *domain.ShipClass) error {
+
+	keysDb := []ShipClassKey{{NameKey: domain.ShipClassKey(class.Name), ShipClassID: class.ID}}
+	seen := map[string]bool{keysDb[0].NameKey: true}
+	for _, a := range class.Aliases {
+		key := domain.ShipClassKey(a)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keysDb = append(keysDb, ShipClassKey{NameKey: key, ShipClassID: class.ID, Alias: a})
+	}
+	for _, k := range keysDb {
+		var count int64
+		err := tx.Model(&ShipClassKey{}).Where("name_key = ?", k.NameKey).Count(&count).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: check key", shipClassErrorPrefix)
+		}
+		if count > 0 {
+			return errors.Wrapf(domain.ErrShipClassExists, "%s: %q", shipClassErrorPrefix, k.NameKey)
+		}
+		err = tx.Create(&k).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: save key", shipClassErrorPrefix)
+		}
+	}
+
+	if len(class.Armament) == 0 {
+		return nil
+	}
+	armamentDb := make([]ShipClassArmament, 0, len(class.Armament))
+	for _, a := range class.Armament {
+		armamentDb = append(armamentDb, ShipClassArmament{ShipClassID: class.ID, Title: a.Title, Qty: a.Qty, Max: a.Max, Damage: a.Damage})
+	}
+	err := tx.Create(&armamentDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: save armament", shipClassErrorPrefix)
+	}
+
+	return nil
+}
+
+func deleteRelated(tx *gorm.DB, id uint) error {
+
+	err := tx.Where("ship_class_id = ?", id).Delete(&ShipClassKey{}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete keys", shipClassErrorPrefix)
+	}
+
+	err = tx.Where("ship_class_id = ?", id).Delete(&ShipClassArmament{}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete armament", shipClassErrorPrefix)
+	}
+
+	return nil
+}
+
+// convert classes to domain level with keys and armament
+func load(tx *gorm.DB, classesDb []ShipClass) ([]*domain.ShipClass, error) {
+
+	classes := make([]*domain.ShipClass, 0, len(classesDb))
+	if len(classesDb) == 0 {
+		return classes, nil
+	}
+
+	ids := make([]uint, 0, len(classesDb))
+	for _, c := range classesDb {
+		ids = append(ids, c.ID)
+	}
+
+	keysDb := []ShipClassKey{}
+	err := tx.Where("ship_class_id IN ? AND alias <> ''", ids).Order("alias").Find(&keysDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get keys", shipClassErrorPrefix)
+	}
+	armamentDb := []ShipClassArmament{}
+	err = tx.Where("ship_class_id IN ?", ids).Order("title").Find(&armamentDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get armament", shipClassErrorPrefix)
+	}
+
+	aliases := map[uint][]string{}
+	for _, k := range keysDb {
+		aliases[k.ShipClassID] = append(aliases[k.ShipClassID], k.Alias)
+	}
+	armament := map[uint][]domain.ShipClassArmament{}
+	for _, a := range armamentDb {
+		armament[a.ShipClassID] = append(armament[a.ShipClassID], domain.ShipClassArmament{Title: a.Title, Qty: a.Qty, Max: a.Max, Damage: a.Damage})
+	}
+
+	for _, c := range classesDb {
+		classes = append(classes, &domain.ShipClass{
+			ID:        c.ID,
+			Name:      c.Name,
+			Aliases:   aliases[c.ID],
+			Armament:  armament[c.ID],
+			MinCrew:   c.MinCrew,
+			MaxCrew:   c.MaxCrew,
+			BaseValue: c.BaseValue,
+			Depreciation: domain.Depreciation{
+				Method:  domain.DepreciationMethod(c.DepreciationMethod),
+				Rate:    domain.Decimal(c.DepreciationRate),
+				Salvage: domain.Decimal(c.SalvageRate),
+				Damage:  domain.Decimal(c.DamageRate),
+			},
+			Hyperdrive: c.HyperdriveRating,
+		})
+	}
+
+	return classes, nil
+}
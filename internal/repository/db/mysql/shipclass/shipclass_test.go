@@ -0,0 +1,93 @@
+package shipclass_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/shipclass"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShipClassMysqlRepo_Normalize(t *testing.T) {
+
+	db := mysqltest.Open(t)
+	repo := shipclass.NewShipClassRepo(db)
+	spaceships := spaceship.NewSpaceshipRepo(db)
+	ctx := context.Background()
+	empire := tenant.WithID(ctx, 1)
+	rebels := tenant.WithID(ctx, 2)
+
+	// catalog is seeded by migration
+	starDestroyer, err := repo.GetByName(ctx, "imperial star destroyer")
+	require.NoError(t, err)
+	assert.Equal(t, "Star Destroyer", starDestroyer.Name)
+	assert.Equal(t, uint(9000), starDestroyer.MinCrew)
+	assert.NotEmpty(t, starDestroyer.Armament)
+
+	// class strings entered by hand before catalog
+	classes := map[string]string{
+		"Devastator": "star destroyer",
+		"Avenger":    "ISD",
+		"Chimaera":   "Imperial-class  Star Destroyer",
+		"Tantive IV": "Corvette",
+		"Falcon":     "Light Freighter",
+	}
+	ids := map[string]uint{}
+	for name, class := range classes {
+		ctx := empire
+		if name == "Tantive IV" || name == "Falcon" {
+			ctx = rebels
+		}
+		s := &domain.Spaceship{Name: name, Class: class, Status: domain.SpaceshipStatusDamaged}
+		require.NoError(t, spaceships.Create(ctx, s))
+		ids[name] = s.ID
+	}
+
+	count, err := repo.Normalize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	for _, name := range []string{"Devastator", "Avenger", "Chimaera"} {
+		s, err := spaceships.GetById(empire, ids[name])
+		require.NoError(t, err)
+		assert.Equal(t, "Star Destroyer", s.Class)
+		assert.Equal(t, starDestroyer.ID, s.ClassID)
+	}
+	falcon, err := spaceships.GetById(rebels, ids["Falcon"])
+	require.NoError(t, err)
+	assert.Equal(t, "Light Freighter", falcon.Class)
+	assert.Zero(t, falcon.ClassID)
+
+	// normalized spaceships are not changed again
+	count, err = repo.Normalize(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// new alias links spaceships of class out of catalog
+	freighter := &domain.ShipClass{Name: "YT-1300", Aliases: []string{"Light Freighter"}, MaxCrew: 8}
+	require.NoError(t, repo.Create(ctx, freighter))
+	assert.ErrorIs(t, repo.Create(ctx, &domain.ShipClass{Name: "light freighter"}), domain.ErrShipClassExists)
+	count, err = repo.Normalize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	falcon, err = spaceships.GetById(rebels, ids["Falcon"])
+	require.NoError(t, err)
+	assert.Equal(t, "YT-1300", falcon.Class)
+
+	// renamed class is renamed on spaceships, used class can't be deleted
+	freighter.Name = "YT-1300 Light Freighter"
+	require.NoError(t, repo.Update(ctx, freighter))
+	count, err = repo.Normalize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	falcon, err = spaceships.GetById(rebels, ids["Falcon"])
+	require.NoError(t, err)
+	assert.Equal(t, "YT-1300 Light Freighter", falcon.Class)
+	assert.ErrorIs(t, repo.Delete(ctx, freighter.ID), domain.ErrShipClassInUse)
+}
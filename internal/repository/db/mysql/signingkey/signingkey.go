@@ -0,0 +1,78 @@
+package signingkey
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	signingKeyErrorPrefix = "[repository.db.mysql.signingkey]"
+
+	// test interface
+	_ keyset.Store = (*SigningKeyMysqlRepo)(nil)
+)
+
+type SigningKeyMysqlRepo struct {
+	db *mysql.DB
+}
+
+// signing_keys table
+type SigningKey struct {
+	ID         string `gorm:"primaryKey;size:32"`
+	Algorithm  string `gorm:"size:16"`
+	PrivateKey []byte
+	CreatedAt  int64
+}
+
+func NewSigningKeyRepo(db *mysql.DB) *SigningKeyMysqlRepo {
+	return &SigningKeyMysqlRepo{db}
+}
+
+// get all keys
+func (repo *SigningKeyMysqlRepo) GetAll(ctx context.Context) ([]*domain.SigningKey, error) {
+	keysDb := []SigningKey{}
+	res := repo.db.WithContext(ctx).Order("created_at DESC").Find(&keysDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get all", signingKeyErrorPrefix)
+	}
+	keys := make([]*domain.SigningKey, 0, res.RowsAffected)
+	for _, keyDb := range keysDb {
+		keys = append(keys, &domain.SigningKey{
+			ID:         keyDb.ID,
+			Algorithm:  keyDb.Algorithm,
+			PrivateKey: keyDb.PrivateKey,
+			CreatedAt:  keyDb.CreatedAt,
+		})
+	}
+	return keys, nil
+}
+
+// create key
+func (repo *SigningKeyMysqlRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	keyDb := SigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: key.PrivateKey,
+		CreatedAt:  key.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&keyDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", signingKeyErrorPrefix)
+	}
+	return nil
+}
+
+// delete key by id, missing key is not an error
+func (repo *SigningKeyMysqlRepo) Delete(ctx context.Context, id string) error {
+	err := repo.db.WithContext(ctx).Delete(&SigningKey{ID: id}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete", signingKeyErrorPrefix)
+	}
+	return nil
+}
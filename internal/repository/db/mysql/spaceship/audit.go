@@ -0,0 +1,36 @@
+package spaceship
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
+)
+
+// spaceship_audits table, history of spaceship changes
+type SpaceshipAudit struct {
+	ID          uint `gorm:"primaryKey"`
+	TenantID    uint `gorm:"index:idx_spaceship_audits_tenant_at"`
+	SpaceshipID uint `gorm:"index"`
+	Action      uint
+	// status of spaceship after change
+	Status uint
+	At     int64 `gorm:"index:idx_spaceship_audits_tenant_at"`
+}
+
+// record change of spaceship in transaction of change
+func recordAudit(tx *gorm.DB, tenantID uint, spaceshipID uint, action domain.SpaceshipAuditAction, status domain.SpaceshipStatus) error {
+	err := tx.Create(&SpaceshipAudit{
+		TenantID:    tenantID,
+		SpaceshipID: spaceshipID,
+		Action:      uint(action),
+		Status:      uint(status),
+		At:          time.Now().Unix(),
+	}).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: record audit", spaceshipErrorPrefix)
+	}
+	return nil
+}
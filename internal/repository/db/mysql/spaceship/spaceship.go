@@ -2,12 +2,15 @@ package spaceship
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
 	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
 	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -28,11 +31,13 @@ type SpaceshipMysqlRepo struct {
 // models for orm
 // many 2 many relation:
 // spaceship_armaments -> spaceship_armament_qties <- spaceships
+// all tables are scoped by tenant, every query must filter by tenant_id
 
 // spaceship_armaments table
 type SpaceshipArmament struct {
-	ID    uint   `gorm:"primaryKey"`
-	Title string `gorm:"size:256;uniqueIndex"`
+	ID       uint   `gorm:"primaryKey"`
+	TenantID uint   `gorm:"uniqueIndex:idx_spaceship_armaments_tenant_title"`
+	Title    string `gorm:"size:256;uniqueIndex:idx_spaceship_armaments_tenant_title"`
 
 	// supress field for orm
 	Qty uint `gorm:"-"`
@@ -40,6 +45,7 @@ type SpaceshipArmament struct {
 
 // spaceship_armament_qties table
 type SpaceshipArmamentQty struct {
+	TenantID            uint `gorm:"index"`
 	SpaceshipID         uint `gorm:"index:,unique,composite:myname"`
 	SpaceshipArmamentID uint `gorm:"index:,unique,composite:myname"`
 	Qty                 uint
@@ -48,13 +54,72 @@ type SpaceshipArmamentQty struct {
 // spaceships table
 type Spaceship struct {
 	ID       uint                `gorm:"primaryKey"`
-	Name     string              `gorm:"size:256;uniqueIndex"`
+	TenantID uint                `gorm:"uniqueIndex:idx_spaceships_tenant_name"`
+	Name     string              `gorm:"size:256;uniqueIndex:idx_spaceships_tenant_name"`
 	Class    string              `gorm:"size:256"`
+	ClassID  uint                `gorm:"index"`
 	Armament []SpaceshipArmament `gorm:"many2many:spaceship_armament_qties;"`
 	Crew     uint
 	Image    string `gorm:"size:256"`
-	Value    float64
+	// fixed-point value in units of domain decimal
+	Value    int64  `gorm:"column:value_amount"`
+	Currency string `gorm:"size:3"`
+	ValuedAt int64
 	Status   uint
+	// the latest position, coordinates are null if unknown
+	Sector       string   `gorm:"size:128;index"`
+	System       string   `gorm:"size:128"`
+	X            *float64 `gorm:"column:pos_x"`
+	Y            *float64 `gorm:"column:pos_y"`
+	Z            *float64 `gorm:"column:pos_z"`
+	PositionedAt int64
+}
+
+// spaceship_valuations table, history of spaceship values
+type SpaceshipValuation struct {
+	ID          uint `gorm:"primaryKey"`
+	TenantID    uint `gorm:"index:idx_spaceship_valuations_tenant_spaceship"`
+	SpaceshipID uint `gorm:"index:idx_spaceship_valuations_tenant_spaceship"`
+	Amount      int64
+	Currency    string `gorm:"size:3"`
+	Source      uint
+	Note        string `gorm:"size:256"`
+	AppraisedAt int64
+}
+
+// spaceship_positions table, log of reported positions
+type SpaceshipPosition struct {
+	ID          uint     `gorm:"primaryKey"`
+	TenantID    uint     `gorm:"index:idx_spaceship_positions_tenant_spaceship"`
+	SpaceshipID uint     `gorm:"index:idx_spaceship_positions_tenant_spaceship"`
+	Sector      string   `gorm:"size:128"`
+	System      string   `gorm:"size:128"`
+	X           *float64 `gorm:"column:pos_x"`
+	Y           *float64 `gorm:"column:pos_y"`
+	Z           *float64 `gorm:"column:pos_z"`
+	ReportedAt  int64
+}
+
+// the latest position of spaceship, nil if it was never reported
+func (ss *Spaceship) position() *domain.Position {
+	if ss.PositionedAt == 0 {
+		return nil
+	}
+	return &domain.Position{
+		SpaceshipID: ss.ID,
+		Sector:      ss.Sector,
+		System:      ss.System,
+		Coordinates: coordinates(ss.X, ss.Y, ss.Z),
+		ReportedAt:  ss.PositionedAt,
+	}
+}
+
+// coordinates of nullable columns, all of them are set together
+func coordinates(x, y, z *float64) *domain.Coordinates {
+	if x == nil || y == nil || z == nil {
+		return nil
+	}
+	return &domain.Coordinates{X: *x, Y: *y, Z: *z}
 }
 
 // spaceship repo builder
@@ -62,14 +127,35 @@ func NewSpaceshipRepo(db *mysql.DB) *SpaceshipMysqlRepo {
 	return &SpaceshipMysqlRepo{db}
 }
 
-// get all spaceships from db with short info
-func (repo *SpaceshipMysqlRepo) GetAll(ctx context.Context) ([]*domain.Spaceship, error) {
+// query scoped by tenant from context
+func (repo *SpaceshipMysqlRepo) scope(ctx context.Context) (*gorm.DB, uint, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: scope", spaceshipErrorPrefix)
+	}
+	return repo.db.Conn(ctx).Where("tenant_id = ?", tenantID), tenantID, nil
+}
+
+// get all spaceships from db without armament
+func (repo *SpaceshipMysqlRepo) GetAll(ctx context.Context, filter domain.SpaceshipFilter) ([]*domain.Spaceship, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	spaceships := []Spaceship{}
 
+	query = applyFilter(query, filter)
+	if filter.AfterID != 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
 	// get all records from db
-	// TODO: make filters
-	res := repo.db.Select("id", "name", "status").Find(&spaceships)
+	res := query.Order("id").Find(&spaceships)
 	if res.Error != nil {
 		return nil, errors.Wrapf(res.Error, "%s: get all", spaceshipErrorPrefix)
 	}
@@ -78,9 +164,145 @@ func (repo *SpaceshipMysqlRepo) GetAll(ctx context.Context) ([]*domain.Spaceship
 	domainSpaceships := make([]*domain.Spaceship, 0, res.RowsAffected)
 	for _, ss := range spaceships {
 		domainSpaceships = append(domainSpaceships, &domain.Spaceship{
-			ID:     ss.ID,
-			Name:   ss.Name,
-			Status: domain.SpaceshipStatus(ss.Status),
+			ID:       ss.ID,
+			TenantID: tenantID,
+			Name:     ss.Name,
+			Class:    ss.Class,
+			ClassID:  ss.ClassID,
+			Crew:     ss.Crew,
+			Image:    ss.Image,
+			Value:    domain.Decimal(ss.Value),
+			Currency: ss.Currency,
+			ValuedAt: ss.ValuedAt,
+			Status:   domain.SpaceshipStatus(ss.Status),
+			Position: ss.position(),
+		})
+	}
+
+	return domainSpaceships, nil
+}
+
+// number of spaceships matching filter, page of filter is ignored
+func (repo *SpaceshipMysqlRepo) Count(ctx context.Context, filter domain.SpaceshipFilter) (int, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = applyFilter(query, filter).Model(&Spaceship{}).Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count", spaceshipErrorPrefix)
+	}
+
+	return int(count), nil
+}
+
+// apply filters of list, name is matched by substring
+func applyFilter(query *gorm.DB, filter domain.SpaceshipFilter) *gorm.DB {
+	if filter.Name != "" {
+		query = query.Where("lower(name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
+	}
+	if filter.Class != "" {
+		query = query.Where("lower(class) = ?", strings.ToLower(filter.Class))
+	}
+	if filter.Status != domain.SpaceshipStatusUndefined {
+		query = query.Where("status = ?", uint(filter.Status))
+	}
+	if filter.Sector != "" {
+		query = query.Where("lower(sector) = ?", strings.ToLower(filter.Sector))
+	}
+	if filter.System != "" {
+		query = query.Where("lower(system) = ?", strings.ToLower(filter.System))
+	}
+	return query
+}
+
+// get armaments of spaceships by one query, spaceships without armament are absent
+func (repo *SpaceshipMysqlRepo) GetArmament(ctx context.Context, ids []uint) (map[uint][]domain.SpaceshipArmament, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get armament", spaceshipErrorPrefix)
+	}
+
+	armamentsBySpaceship := map[uint][]domain.SpaceshipArmament{}
+	if len(ids) == 0 {
+		return armamentsBySpaceship, nil
+	}
+
+	armaments := []struct {
+		SpaceshipID uint
+		domain.SpaceshipArmament
+	}{}
+	err = repo.db.Conn(ctx).Raw(`
+		SELECT saq.spaceship_id, sa.id, sa.title, saq.qty FROM spaceship_armaments sa
+		INNER JOIN spaceship_armament_qties saq ON sa.id = saq.spaceship_armament_id
+			AND saq.spaceship_id IN ? AND saq.tenant_id = ?
+		WHERE sa.tenant_id = ?
+		ORDER BY saq.spaceship_id, sa.id
+	`, ids, tenantID, tenantID).Scan(&armaments).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get armament", spaceshipErrorPrefix)
+	}
+	for _, a := range armaments {
+		armamentsBySpaceship[a.SpaceshipID] = append(armamentsBySpaceship[a.SpaceshipID], a.SpaceshipArmament)
+	}
+
+	return armamentsBySpaceship, nil
+}
+
+// get all spaceships from db with detailed info, e.g. for search index
+func (repo *SpaceshipMysqlRepo) GetAllFull(ctx context.Context) ([]*domain.Spaceship, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	spaceships := []Spaceship{}
+	err = query.Order("id").Find(&spaceships).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all full", spaceshipErrorPrefix)
+	}
+
+	// armaments of all spaceships are loaded by one query
+	armaments := []struct {
+		SpaceshipID uint
+		domain.SpaceshipArmament
+	}{}
+	err = repo.db.Conn(ctx).Raw(`
+		SELECT saq.spaceship_id, sa.id, sa.title, saq.qty FROM spaceship_armaments sa
+		INNER JOIN spaceship_armament_qties saq ON sa.id = saq.spaceship_armament_id
+			AND saq.tenant_id = ?
+		WHERE sa.tenant_id = ?
+		ORDER BY saq.spaceship_id, sa.id
+	`, tenantID, tenantID).Scan(&armaments).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all full armament", spaceshipErrorPrefix)
+	}
+	armamentsBySpaceship := map[uint][]domain.SpaceshipArmament{}
+	for _, a := range armaments {
+		armamentsBySpaceship[a.SpaceshipID] = append(armamentsBySpaceship[a.SpaceshipID], a.SpaceshipArmament)
+	}
+
+	domainSpaceships := make([]*domain.Spaceship, 0, len(spaceships))
+	for _, ss := range spaceships {
+		domainSpaceships = append(domainSpaceships, &domain.Spaceship{
+			ID:       ss.ID,
+			TenantID: ss.TenantID,
+			Name:     ss.Name,
+			Class:    ss.Class,
+			ClassID:  ss.ClassID,
+			Crew:     ss.Crew,
+			Image:    ss.Image,
+			Armament: armamentsBySpaceship[ss.ID],
+			Value:    domain.Decimal(ss.Value),
+			Currency: ss.Currency,
+			ValuedAt: ss.ValuedAt,
+			Status:   domain.SpaceshipStatus(ss.Status),
+			Position: ss.position(),
 		})
 	}
 
@@ -89,10 +311,27 @@ func (repo *SpaceshipMysqlRepo) GetAll(ctx context.Context) ([]*domain.Spaceship
 
 // get one spaceship from db with detailed info
 func (repo *SpaceshipMysqlRepo) GetById(ctx context.Context, id uint) (*domain.Spaceship, error) {
+	return repo.get(ctx, id, false)
+}
+
+// get spaceship and lock its row until transaction of Atomic ends
+func (repo *SpaceshipMysqlRepo) GetForUpdate(ctx context.Context, id uint) (*domain.Spaceship, error) {
+	return repo.get(ctx, id, true)
+}
+
+func (repo *SpaceshipMysqlRepo) get(ctx context.Context, id uint, lock bool) (*domain.Spaceship, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
 
-	// create mini model for orm query
-	spaceshipDb := Spaceship{ID: id}
-	err := repo.db.First(&spaceshipDb).Error
+	// spaceship of other tenant is not found
+	spaceshipDb := Spaceship{}
+	err = query.Where("id = ?", id).First(&spaceshipDb).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// if not found return domain not found error
@@ -104,155 +343,431 @@ func (repo *SpaceshipMysqlRepo) GetById(ctx context.Context, id uint) (*domain.S
 
 	// convert db spaceship armaments to domain level
 	domainSpaceshipArmaments := []domain.SpaceshipArmament{}
-	repo.db.Raw(`
+	err = repo.db.Conn(ctx).Raw(`
 		SELECT sa.id, sa.title, saq.qty FROM spaceship_armaments sa
-		INNER JOIN spaceship_armament_qties saq ON sa.id = saq.spaceship_armament_id AND saq.spaceship_id = ?
-	`, spaceshipDb.ID).Scan(&domainSpaceshipArmaments)
+		INNER JOIN spaceship_armament_qties saq ON sa.id = saq.spaceship_armament_id
+			AND saq.spaceship_id = ? AND saq.tenant_id = ?
+		WHERE sa.tenant_id = ?
+		ORDER BY sa.id
+	`, spaceshipDb.ID, tenantID, tenantID).Scan(&domainSpaceshipArmaments).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id armament", spaceshipErrorPrefix)
+	}
 
 	return &domain.Spaceship{
 		ID:       spaceshipDb.ID,
+		TenantID: spaceshipDb.TenantID,
 		Name:     spaceshipDb.Name,
 		Class:    spaceshipDb.Class,
+		ClassID:  spaceshipDb.ClassID,
 		Crew:     spaceshipDb.Crew,
 		Image:    spaceshipDb.Image,
 		Armament: domainSpaceshipArmaments,
-		Value:    spaceshipDb.Value,
+		Value:    domain.Decimal(spaceshipDb.Value),
+		Currency: spaceshipDb.Currency,
+		ValuedAt: spaceshipDb.ValuedAt,
 		Status:   domain.SpaceshipStatus(spaceshipDb.Status),
+		Position: spaceshipDb.position(),
 	}, nil
 }
 
+// run all writes of fn in one transaction
+func (repo *SpaceshipMysqlRepo) Atomic(ctx context.Context, fn func(context.Context) error) error {
+	err := repo.db.Atomic(ctx, fn)
+	if err != nil {
+		return errors.Wrapf(err, "%s: atomic", spaceshipErrorPrefix)
+	}
+	return nil
+}
+
 // create spaceship
 func (repo *SpaceshipMysqlRepo) Create(ctx context.Context, spaceship *domain.Spaceship) error {
 
-	// TODO: make transaction wrap
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
 
-	// make map with armament quantities
-	spaceshipArmamentMap := make(map[string]uint)
-	spiceshipArmamentDb := make([]SpaceshipArmament, 0, len(spaceship.Armament))
-	for _, a := range spaceship.Armament {
-		spaceshipArmamentMap[a.Title] = a.Qty
-		spiceshipArmamentDb = append(spiceshipArmamentDb, SpaceshipArmament{
-			Title: a.Title,
-		})
+	// value of new spaceship is valued now
+	if spaceship.ValuedAt == 0 {
+		spaceship.ValuedAt = time.Now().Unix()
 	}
 
-	// ensure that all new armaments exist in db
-	err := repo.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&spiceshipArmamentDb).Error
+	// create spaceship db model
+	spaceshipDb := Spaceship{
+		TenantID: tenantID,
+		Name:     spaceship.Name,
+		Class:    spaceship.Class,
+		ClassID:  spaceship.ClassID,
+		Crew:     spaceship.Crew,
+		Status:   uint(spaceship.Status),
+		Image:    spaceship.Image,
+		Value:    int64(spaceship.Value),
+		Currency: spaceship.Currency,
+		ValuedAt: spaceship.ValuedAt,
+	}
+
+	err = repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := checkName(tx, tenantID, 0, spaceship.Name)
+		if err != nil {
+			return err
+		}
+
+		// save spaceship model to db
+		err = tx.Create(&spaceshipDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		}
+
+		err = saveArmament(tx, tenantID, spaceshipDb.ID, spaceship.Armament)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(tx, tenantID, spaceshipDb.ID, domain.SpaceshipAuditActionCreated, spaceship.Status)
+	})
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return err
 	}
 
-	// find all requested armaments
-	err = repo.db.Find(&spiceshipArmamentDb).Error
+	spaceship.ID = spaceshipDb.ID
+	spaceship.TenantID = tenantID
+
+	return nil
+}
+
+// update spaceship, all fields are replaced and valuation time is given by caller
+func (repo *SpaceshipMysqlRepo) Update(ctx context.Context, spaceship *domain.Spaceship) error {
+
+	_, tenantID, err := repo.scope(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return err
 	}
 
-	// create spaceship db model
-	spaceshipDb := Spaceship{
-		Name:   spaceship.Name,
-		Class:  spaceship.Class,
-		Crew:   spaceship.Crew,
-		Status: uint(spaceship.Status),
-		Image:  spaceship.Image,
-		Value:  spaceship.Value,
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// check if spaceship exists in db for tenant
+		spaceshipQuery := Spaceship{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND tenant_id = ?", spaceship.ID, tenantID).
+			First(&spaceshipQuery).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: update", spaceshipErrorPrefix)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", spaceshipErrorPrefix)
+		}
+
+		err = checkName(tx, tenantID, spaceshipQuery.ID, spaceship.Name)
+		if err != nil {
+			return err
+		}
+
+		// create spaceship db model
+		spaceshipDb := Spaceship{
+			Name:     spaceship.Name,
+			Class:    spaceship.Class,
+			Crew:     spaceship.Crew,
+			Status:   uint(spaceship.Status),
+			Image:    spaceship.Image,
+			Value:    int64(spaceship.Value),
+			Currency: spaceship.Currency,
+			ValuedAt: spaceship.ValuedAt,
+		}
+
+		// save spaceship model to db, all fields are replaced including zero values,
+		// class id is reset for class out of catalog
+		err = tx.Model(&spaceshipQuery).Select("name", "class", "crew", "status", "image", "value_amount", "currency", "valued_at").Updates(spaceshipDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", spaceshipErrorPrefix)
+		}
+		err = tx.Model(&spaceshipQuery).Update("class_id", spaceship.ClassID).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update class", spaceshipErrorPrefix)
+		}
+
+		// position is reported separately and kept
+		spaceship.TenantID = tenantID
+		spaceship.Position = spaceshipQuery.position()
+
+		err = recordAudit(tx, tenantID, spaceshipQuery.ID, domain.SpaceshipAuditActionUpdated, spaceship.Status)
+		if err != nil {
+			return err
+		}
+
+		// armament is replaced, titles absent in update are removed from spaceship
+		err = tx.Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipQuery.ID).Delete(&SpaceshipArmamentQty{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update delete armament", spaceshipErrorPrefix)
+		}
+
+		return saveArmament(tx, tenantID, spaceshipQuery.ID, spaceship.Armament)
+	})
+}
+
+// get valuations of spaceship from the oldest
+func (repo *SpaceshipMysqlRepo) GetValuations(ctx context.Context, id uint) ([]*domain.Valuation, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get valuations", spaceshipErrorPrefix)
 	}
 
-	// save spaceship model to db
-	err = repo.db.Create(&spaceshipDb).Error
+	valuationsDb := []SpaceshipValuation{}
+	err = repo.db.Conn(ctx).Where("tenant_id = ? AND spaceship_id = ?", tenantID, id).
+		Order("appraised_at, id").Find(&valuationsDb).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return nil, errors.Wrapf(err, "%s: get valuations", spaceshipErrorPrefix)
 	}
 
-	// build all quantites and armaments
-	spaceshipArmamentQtyDb := make([]SpaceshipArmamentQty, 0, len(spaceship.Armament))
-	for _, a := range spiceshipArmamentDb {
-		spaceshipArmamentQtyDb = append(spaceshipArmamentQtyDb, SpaceshipArmamentQty{
-			SpaceshipID:         spaceshipDb.ID,
-			SpaceshipArmamentID: a.ID,
-			Qty:                 spaceshipArmamentMap[a.Title],
+	valuations := make([]*domain.Valuation, 0, len(valuationsDb))
+	for _, v := range valuationsDb {
+		valuations = append(valuations, &domain.Valuation{
+			ID:          v.ID,
+			SpaceshipID: v.SpaceshipID,
+			Amount:      domain.Decimal(v.Amount),
+			Currency:    v.Currency,
+			Source:      domain.ValuationSource(v.Source),
+			Note:        v.Note,
+			AppraisedAt: v.AppraisedAt,
 		})
 	}
 
-	// save armaments with quantities
-	err = repo.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "spaceship_id"}, {Name: "spaceship_armament_id"}},
-		UpdateAll: true,
-	}).Create(&spaceshipArmamentQtyDb).Error
+	return valuations, nil
+}
+
+// record appraisal of spaceship, appraisal which is not older than current value becomes value of spaceship
+func (repo *SpaceshipMysqlRepo) Appraise(ctx context.Context, valuation *domain.Valuation) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		spaceshipQuery := Spaceship{}
+		err := tx.Where("id = ? AND tenant_id = ?", valuation.SpaceshipID, tenantID).First(&spaceshipQuery).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: appraise", spaceshipErrorPrefix)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: appraise", spaceshipErrorPrefix)
+		}
+
+		err = recordValuation(tx, tenantID, valuation)
+		if err != nil {
+			return err
+		}
+
+		if valuation.AppraisedAt < spaceshipQuery.ValuedAt {
+			return nil
+		}
+
+		err = tx.Model(&spaceshipQuery).Select("value_amount", "currency", "valued_at").Updates(Spaceship{
+			Value:    int64(valuation.Amount),
+			Currency: valuation.Currency,
+			ValuedAt: valuation.AppraisedAt,
+		}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: appraise update", spaceshipErrorPrefix)
+		}
+
+		return recordAudit(tx, tenantID, spaceshipQuery.ID, domain.SpaceshipAuditActionUpdated, domain.SpaceshipStatus(spaceshipQuery.Status))
+	})
+}
+
+// save valuation in history of spaceship, value of spaceship is not changed
+func (repo *SpaceshipMysqlRepo) AddValuation(ctx context.Context, valuation *domain.Valuation) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return recordValuation(repo.db.Conn(ctx), tenantID, valuation)
+}
+
+// save valuation in history of spaceship
+func recordValuation(tx *gorm.DB, tenantID uint, valuation *domain.Valuation) error {
+
+	valuationDb := SpaceshipValuation{
+		TenantID:    tenantID,
+		SpaceshipID: valuation.SpaceshipID,
+		Amount:      int64(valuation.Amount),
+		Currency:    valuation.Currency,
+		Source:      uint(valuation.Source),
+		Note:        valuation.Note,
+		AppraisedAt: valuation.AppraisedAt,
+	}
+	err := tx.Create(&valuationDb).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return errors.Wrapf(err, "%s: record valuation", spaceshipErrorPrefix)
 	}
 
+	valuation.ID = valuationDb.ID
+
 	return nil
 }
 
-func (repo *SpaceshipMysqlRepo) Update(ctx context.Context, spaceship *domain.Spaceship) error {
+// get the latest positions of spaceship from the newest, all positions if limit is zero
+func (repo *SpaceshipMysqlRepo) GetPositions(ctx context.Context, id uint, limit int) ([]*domain.Position, error) {
 
-	// TODO: make transaction wrap
-	// TODO: DRY
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get positions", spaceshipErrorPrefix)
+	}
 
-	// check if spaceship exists in db
-	spaceshipQuery := Spaceship{ID: spaceship.ID}
-	err := repo.db.First(&spaceshipQuery).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return errors.Wrapf(domain.ErrNotFound, "%s: update", spaceshipErrorPrefix)
+	query := repo.db.Conn(ctx).Where("tenant_id = ? AND spaceship_id = ?", tenantID, id).Order("reported_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	positionsDb := []SpaceshipPosition{}
+	err = query.Find(&positionsDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get positions", spaceshipErrorPrefix)
 	}
 
-	// make map with armament quantities
-	spaceshipArmamentMap := make(map[string]uint)
-	spiceshipArmamentDb := make([]SpaceshipArmament, 0, len(spaceship.Armament))
-	for _, a := range spaceship.Armament {
-		spaceshipArmamentMap[a.Title] = a.Qty
-		spiceshipArmamentDb = append(spiceshipArmamentDb, SpaceshipArmament{
-			Title: a.Title,
+	positions := make([]*domain.Position, 0, len(positionsDb))
+	for _, p := range positionsDb {
+		positions = append(positions, &domain.Position{
+			ID:          p.ID,
+			SpaceshipID: p.SpaceshipID,
+			Sector:      p.Sector,
+			System:      p.System,
+			Coordinates: coordinates(p.X, p.Y, p.Z),
+			ReportedAt:  p.ReportedAt,
 		})
 	}
 
-	// ensure that all new armaments exist in db
-	err = repo.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&spiceshipArmamentDb).Error
+	return positions, nil
+}
+
+// record reported position in log, position which is not older than the latest one becomes position of spaceship
+func (repo *SpaceshipMysqlRepo) ReportPosition(ctx context.Context, position *domain.Position) error {
+
+	_, tenantID, err := repo.scope(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return err
 	}
 
-	// find all requested armaments
-	err = repo.db.Find(&spiceshipArmamentDb).Error
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		spaceshipQuery := Spaceship{}
+		err := tx.Where("id = ? AND tenant_id = ?", position.SpaceshipID, tenantID).First(&spaceshipQuery).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: report position", spaceshipErrorPrefix)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: report position", spaceshipErrorPrefix)
+		}
+
+		positionDb := SpaceshipPosition{
+			TenantID:    tenantID,
+			SpaceshipID: position.SpaceshipID,
+			Sector:      position.Sector,
+			System:      position.System,
+			ReportedAt:  position.ReportedAt,
+		}
+		if c := position.Coordinates; c != nil {
+			positionDb.X, positionDb.Y, positionDb.Z = &c.X, &c.Y, &c.Z
+		}
+		err = tx.Create(&positionDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: report position", spaceshipErrorPrefix)
+		}
+		position.ID = positionDb.ID
+
+		if position.ReportedAt < spaceshipQuery.PositionedAt {
+			return nil
+		}
+
+		// coordinates are reset to null for position without them
+		err = tx.Model(&spaceshipQuery).Select("sector", "system", "pos_x", "pos_y", "pos_z", "positioned_at").Updates(Spaceship{
+			Sector:       positionDb.Sector,
+			System:       positionDb.System,
+			X:            positionDb.X,
+			Y:            positionDb.Y,
+			Z:            positionDb.Z,
+			PositionedAt: positionDb.ReportedAt,
+		}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: report position update", spaceshipErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// name of spaceship is unique in tenant, id is excluded from check on update
+func checkName(tx *gorm.DB, tenantID uint, id uint, name string) error {
+
+	var count int64
+	err := tx.Model(&Spaceship{}).Where("tenant_id = ? AND name = ? AND id <> ?", tenantID, name, id).Count(&count).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return errors.Wrapf(err, "%s: check name", spaceshipErrorPrefix)
+	}
+	if count > 0 {
+		return errors.Wrapf(domain.ErrSpaceshipExists, "%s: check name", spaceshipErrorPrefix)
 	}
 
-	// create spaceship db model
-	spaceshipDb := Spaceship{
-		Name:   spaceship.Name,
-		Class:  spaceship.Class,
-		Crew:   spaceship.Crew,
-		Status: uint(spaceship.Status),
-		Image:  spaceship.Image,
-		Value:  spaceship.Value,
+	return nil
+}
+
+// ensure that all armaments exist in db and save their quantities for spaceship
+func saveArmament(tx *gorm.DB, tenantID uint, spaceshipID uint, armament []domain.SpaceshipArmament) error {
+
+	if len(armament) == 0 {
+		return nil
+	}
+
+	// make map with armament quantities
+	spaceshipArmamentMap := make(map[string]uint)
+	spaceshipArmamentTitles := make([]string, 0, len(armament))
+	spaceshipArmamentDb := make([]SpaceshipArmament, 0, len(armament))
+	for _, a := range armament {
+		spaceshipArmamentMap[a.Title] = a.Qty
+		spaceshipArmamentTitles = append(spaceshipArmamentTitles, a.Title)
+		spaceshipArmamentDb = append(spaceshipArmamentDb, SpaceshipArmament{
+			TenantID: tenantID,
+			Title:    a.Title,
+		})
+	}
+
+	// ensure that all new armaments exist in db
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "title"}},
+		UpdateAll: true,
+	}).Create(&spaceshipArmamentDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: save armament", spaceshipErrorPrefix)
 	}
 
-	// save spaceship model to db
-	err = repo.db.Model(&spaceshipQuery).Updates(spaceshipDb).Error
+	// find all requested armaments of tenant
+	spaceshipArmamentDb = spaceshipArmamentDb[:0]
+	err = tx.Where("tenant_id = ? AND title IN ?", tenantID, spaceshipArmamentTitles).Find(&spaceshipArmamentDb).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return errors.Wrapf(err, "%s: save armament", spaceshipErrorPrefix)
 	}
 
 	// build all quantites and armaments
-	spaceshipArmamentQtyDb := make([]SpaceshipArmamentQty, 0, len(spaceship.Armament))
-	for _, a := range spiceshipArmamentDb {
+	spaceshipArmamentQtyDb := make([]SpaceshipArmamentQty, 0, len(spaceshipArmamentDb))
+	for _, a := range spaceshipArmamentDb {
 		spaceshipArmamentQtyDb = append(spaceshipArmamentQtyDb, SpaceshipArmamentQty{
-			SpaceshipID:         spaceshipQuery.ID,
+			TenantID:            tenantID,
+			SpaceshipID:         spaceshipID,
 			SpaceshipArmamentID: a.ID,
 			Qty:                 spaceshipArmamentMap[a.Title],
 		})
 	}
 
 	// save armaments with quantities
-	err = repo.db.Clauses(clause.OnConflict{
+	err = tx.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "spaceship_id"}, {Name: "spaceship_armament_id"}},
 		UpdateAll: true,
 	}).Create(&spaceshipArmamentQtyDb).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: create", spaceshipErrorPrefix)
+		return errors.Wrapf(err, "%s: save armament", spaceshipErrorPrefix)
 	}
 
 	return nil
@@ -263,24 +778,83 @@ func (repo *SpaceshipMysqlRepo) Delete(ctx context.Context, spaceship *domain.Sp
 
 	// TODO: soft delete
 
-	// check if spaceship exists in db
-	spaceshipQuery := Spaceship{ID: spaceship.ID}
-	err := repo.db.First(&spaceshipQuery).Error
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	// check if spaceship exists in db for tenant
+	spaceshipQuery := Spaceship{}
+	err = query.Where("id = ?", spaceship.ID).First(&spaceshipQuery).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.Wrapf(domain.ErrNotFound, "%s: delete get by id", spaceshipErrorPrefix)
 	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete get by id", spaceshipErrorPrefix)
+	}
+
+	spaceship.TenantID = tenantID
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// delete spaceship
+		err = tx.Where("tenant_id = ?", tenantID).Delete(&spaceshipQuery).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete spaceship", spaceshipErrorPrefix)
+		}
+
+		// delete armaments with quantities
+		err = tx.Where("spaceship_id = ? AND tenant_id = ?", spaceshipQuery.ID, tenantID).Delete(&SpaceshipArmamentQty{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete spaceship armament qty", spaceshipErrorPrefix)
+		}
+
+		err = tx.Where("spaceship_id = ? AND tenant_id = ?", spaceshipQuery.ID, tenantID).Delete(&SpaceshipValuation{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete spaceship valuations", spaceshipErrorPrefix)
+		}
+
+		err = tx.Where("spaceship_id = ? AND tenant_id = ?", spaceshipQuery.ID, tenantID).Delete(&SpaceshipPosition{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete spaceship positions", spaceshipErrorPrefix)
+		}
+
+		return recordAudit(tx, tenantID, spaceshipQuery.ID, domain.SpaceshipAuditActionDeleted, domain.SpaceshipStatus(spaceshipQuery.Status))
+	})
+}
+
+// set image url of spaceship
+func (repo *SpaceshipMysqlRepo) UpdateImage(ctx context.Context, id uint, image string) error {
 
-	// delete spaceship
-	err = repo.db.Delete(&spaceshipQuery).Error
+	query, _, err := repo.scope(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "%s: delete spaceship", spaceshipErrorPrefix)
+		return err
 	}
 
-	// delete armaments with quantities
-	err = repo.db.Where("spaceship_id = ?", spaceshipQuery.ID).Delete(&SpaceshipArmamentQty{}).Error
+	// mysql reports no affected rows for unchanged url, so existence is not checked here
+	err = query.Model(&Spaceship{}).Where("id = ?", id).Update("image", image).Error
 	if err != nil {
-		return errors.Wrapf(err, "%s: delete spaceship armament qty", spaceshipErrorPrefix)
+		return errors.Wrapf(err, "%s: update image", spaceshipErrorPrefix)
 	}
 
 	return nil
 }
+
+// set status of spaceship, change is audited
+func (repo *SpaceshipMysqlRepo) SetStatus(ctx context.Context, id uint, status domain.SpaceshipStatus) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Model(&Spaceship{}).Where("id = ? AND tenant_id = ?", id, tenantID).Update("status", uint(status)).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: set status", spaceshipErrorPrefix)
+		}
+
+		return recordAudit(tx, tenantID, id, domain.SpaceshipAuditActionUpdated, status)
+	})
+}
@@ -0,0 +1,283 @@
+package spaceship_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpaceshipMysqlRepo_TenantIsolation(t *testing.T) {
+
+	repo := spaceship.NewSpaceshipRepo(mysqltest.Open(t))
+	empire := tenant.WithID(context.Background(), 1)
+	rebels := tenant.WithID(context.Background(), 2)
+
+	devastator := &domain.Spaceship{
+		Name:     "Devastator",
+		Class:    "Star Destroyer",
+		Status:   domain.SpaceshipStatusOperational,
+		Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}},
+	}
+	require.NoError(t, repo.Create(empire, devastator))
+	assert.Equal(t, uint(1), devastator.TenantID)
+
+	// records are not reachable without tenant
+	_, err := repo.GetAll(context.Background(), domain.SpaceshipFilter{})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+	_, err = repo.GetById(context.Background(), devastator.ID)
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+	assert.ErrorIs(t, repo.Create(context.Background(), &domain.Spaceship{Name: "Executor"}), domain.ErrTenantRequired)
+
+	// names are unique per tenant
+	assert.ErrorIs(t, repo.Create(empire, &domain.Spaceship{Name: "Devastator"}), domain.ErrSpaceshipExists)
+	homeOne := &domain.Spaceship{
+		Name:     "Devastator",
+		Class:    "Captured Star Destroyer",
+		Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 2}},
+	}
+	require.NoError(t, repo.Create(rebels, homeOne))
+
+	// other tenant can't read records
+	_, err = repo.GetById(rebels, devastator.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	spaceships, err := repo.GetAll(rebels, domain.SpaceshipFilter{})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	assert.Equal(t, homeOne.ID, spaceships[0].ID)
+
+	// armament quantities are kept per tenant
+	spaceshipDb, err := repo.GetById(empire, devastator.ID)
+	require.NoError(t, err)
+	require.Len(t, spaceshipDb.Armament, 1)
+	assert.Equal(t, uint(60), spaceshipDb.Armament[0].Qty)
+	spaceshipDb, err = repo.GetById(rebels, homeOne.ID)
+	require.NoError(t, err)
+	require.Len(t, spaceshipDb.Armament, 1)
+	assert.Equal(t, uint(2), spaceshipDb.Armament[0].Qty)
+
+	// other tenant can't write records
+	assert.ErrorIs(t, repo.Update(rebels, &domain.Spaceship{ID: devastator.ID, Name: "Liberty"}), domain.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(rebels, &domain.Spaceship{ID: devastator.ID}), domain.ErrNotFound)
+
+	spaceshipDb, err = repo.GetById(empire, devastator.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Devastator", spaceshipDb.Name)
+	assert.Len(t, spaceshipDb.Armament, 1)
+
+	// rename into existing name of the same tenant
+	require.NoError(t, repo.Create(empire, &domain.Spaceship{Name: "Avenger"}))
+	devastator.Name = "Avenger"
+	assert.ErrorIs(t, repo.Update(empire, devastator), domain.ErrSpaceshipExists)
+
+	require.NoError(t, repo.Delete(empire, &domain.Spaceship{ID: devastator.ID}))
+	spaceshipDb, err = repo.GetById(rebels, homeOne.ID)
+	require.NoError(t, err)
+	assert.Len(t, spaceshipDb.Armament, 1)
+}
+
+func TestSpaceshipMysqlRepo_Atomic(t *testing.T) {
+
+	repo := spaceship.NewSpaceshipRepo(mysqltest.Open(t))
+	empire := tenant.WithID(context.Background(), 1)
+
+	devastator := &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational}
+	require.NoError(t, repo.Create(empire, devastator))
+
+	// failed write rolls back writes before it
+	err := repo.Atomic(empire, func(ctx context.Context) error {
+		err := repo.Create(ctx, &domain.Spaceship{Name: "Executor", Class: "Star Dreadnought"})
+		if err != nil {
+			return err
+		}
+		devastator.Crew = 35000
+		err = repo.Update(ctx, devastator)
+		if err != nil {
+			return err
+		}
+		return repo.Create(ctx, &domain.Spaceship{Name: "Devastator"})
+	})
+	assert.ErrorIs(t, err, domain.ErrSpaceshipExists)
+
+	spaceships, err := repo.GetAll(empire, domain.SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+	spaceshipDb, err := repo.GetById(empire, devastator.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), spaceshipDb.Crew)
+
+	// writes are committed together and are visible inside transaction
+	err = repo.Atomic(empire, func(ctx context.Context) error {
+		err := repo.Create(ctx, &domain.Spaceship{Name: "Executor", Class: "Star Dreadnought"})
+		if err != nil {
+			return err
+		}
+		spaceships, err := repo.GetAll(ctx, domain.SpaceshipFilter{})
+		if err != nil {
+			return err
+		}
+		assert.Len(t, spaceships, 2)
+		return repo.Delete(ctx, devastator)
+	})
+	require.NoError(t, err)
+
+	spaceships, err = repo.GetAll(empire, domain.SpaceshipFilter{})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	assert.Equal(t, "Executor", spaceships[0].Name)
+
+	// spaceship is read with lock for read-modify-write, only by its tenant
+	err = repo.Atomic(empire, func(ctx context.Context) error {
+		spaceshipDb, err := repo.GetForUpdate(ctx, spaceships[0].ID)
+		if err != nil {
+			return err
+		}
+		spaceshipDb.Crew = 1000
+		return repo.Update(ctx, spaceshipDb)
+	})
+	require.NoError(t, err)
+	spaceshipDb, err = repo.GetById(empire, spaceships[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1000), spaceshipDb.Crew)
+	_, err = repo.GetForUpdate(tenant.WithID(context.Background(), 2), spaceships[0].ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestSpaceshipMysqlRepo_Page(t *testing.T) {
+
+	repo := spaceship.NewSpaceshipRepo(mysqltest.Open(t))
+	empire := tenant.WithID(context.Background(), 1)
+
+	for _, name := range []string{"Devastator", "Executor", "Avenger", "Chimaera"} {
+		require.NoError(t, repo.Create(empire, &domain.Spaceship{Name: name, Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational}))
+	}
+	require.NoError(t, repo.Create(empire, &domain.Spaceship{Name: "Tydirium", Class: "Lambda Shuttle"}))
+
+	filter := domain.SpaceshipFilter{Class: "star destroyer", Limit: 2}
+	page, err := repo.GetAll(empire, filter)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "Executor", page[1].Name)
+
+	filter.AfterID = page[1].ID
+	page, err = repo.GetAll(empire, filter)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "Avenger", page[0].Name)
+	assert.Equal(t, "Chimaera", page[1].Name)
+
+	// count ignores page
+	count, err := repo.Count(empire, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	count, err = repo.Count(tenant.WithID(context.Background(), 2), filter)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestSpaceshipMysqlRepo_GetArmament(t *testing.T) {
+
+	repo := spaceship.NewSpaceshipRepo(mysqltest.Open(t))
+	empire := tenant.WithID(context.Background(), 1)
+	rebels := tenant.WithID(context.Background(), 2)
+
+	devastator := &domain.Spaceship{
+		Name:     "Devastator",
+		Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannon", Qty: 10}},
+	}
+	avenger := &domain.Spaceship{Name: "Avenger"}
+	homeOne := &domain.Spaceship{Name: "Home One", Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 29}}}
+	require.NoError(t, repo.Create(empire, devastator))
+	require.NoError(t, repo.Create(empire, avenger))
+	require.NoError(t, repo.Create(rebels, homeOne))
+
+	// armaments of several spaceships are loaded at once, other tenant is not reachable
+	armaments, err := repo.GetArmament(empire, []uint{devastator.ID, avenger.ID, homeOne.ID})
+	require.NoError(t, err)
+	assert.Len(t, armaments, 1)
+	require.Len(t, armaments[devastator.ID], 2)
+	assert.Equal(t, "Turbo Laser", armaments[devastator.ID][0].Title)
+	assert.Equal(t, uint(10), armaments[devastator.ID][1].Qty)
+
+	armaments, err = repo.GetArmament(empire, nil)
+	require.NoError(t, err)
+	assert.Empty(t, armaments)
+
+	_, err = repo.GetArmament(context.Background(), []uint{devastator.ID})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+}
+
+func TestSpaceshipMysqlRepo_ReportPosition(t *testing.T) {
+
+	repo := spaceship.NewSpaceshipRepo(mysqltest.Open(t))
+	empire := tenant.WithID(context.Background(), 1)
+	rebels := tenant.WithID(context.Background(), 2)
+
+	executor := &domain.Spaceship{Name: "Executor", Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational}
+	require.NoError(t, repo.Create(empire, executor))
+
+	hoth := &domain.Position{SpaceshipID: executor.ID, Sector: "Outer Rim", System: "Hoth",
+		Coordinates: &domain.Coordinates{X: 1, Y: 2, Z: 3}, ReportedAt: 200}
+	require.NoError(t, repo.ReportPosition(empire, hoth))
+	assert.NotZero(t, hoth.ID)
+
+	// older position is logged only, position without coordinates resets them
+	require.NoError(t, repo.ReportPosition(empire, &domain.Position{SpaceshipID: executor.ID, Sector: "Outer Rim", System: "Endor", ReportedAt: 100}))
+	spaceshipDb, err := repo.GetById(empire, executor.ID)
+	require.NoError(t, err)
+	require.NotNil(t, spaceshipDb.Position)
+	assert.Equal(t, "Hoth", spaceshipDb.Position.System)
+	assert.Equal(t, &domain.Coordinates{X: 1, Y: 2, Z: 3}, spaceshipDb.Position.Coordinates)
+
+	require.NoError(t, repo.ReportPosition(empire, &domain.Position{SpaceshipID: executor.ID, Sector: "Core Worlds", ReportedAt: 300}))
+	spaceships, err := repo.GetAll(empire, domain.SpaceshipFilter{Sector: "core worlds"})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	assert.Nil(t, spaceships[0].Position.Coordinates)
+
+	// update keeps position
+	executor.Crew = 9000
+	require.NoError(t, repo.Update(empire, executor))
+	assert.Equal(t, "Core Worlds", executor.Position.Sector)
+
+	positions, err := repo.GetPositions(empire, executor.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, positions, 3)
+	assert.Equal(t, []int64{300, 200, 100}, []int64{positions[0].ReportedAt, positions[1].ReportedAt, positions[2].ReportedAt})
+
+	// positions of other tenant are not reachable
+	assert.ErrorIs(t, repo.ReportPosition(rebels, &domain.Position{SpaceshipID: executor.ID, Sector: "Outer Rim"}), domain.ErrNotFound)
+	positions, err = repo.GetPositions(rebels, executor.ID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+}
+
+func TestSpaceshipMysqlRepo_SetStatus(t *testing.T) {
+
+	db := mysqltest.Open(t)
+	repo := spaceship.NewSpaceshipRepo(db)
+	empire := tenant.WithID(context.Background(), 1)
+	rebels := tenant.WithID(context.Background(), 2)
+
+	tydirium := &domain.Spaceship{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Status: domain.SpaceshipStatusOperational}
+	require.NoError(t, repo.Create(empire, tydirium))
+
+	// status of spaceship is changed alone
+	require.NoError(t, repo.SetStatus(empire, tydirium.ID, domain.SpaceshipStatusDamaged))
+	spaceshipDb, err := repo.GetById(empire, tydirium.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SpaceshipStatusDamaged, spaceshipDb.Status)
+	assert.Equal(t, uint(6), spaceshipDb.Crew)
+
+	// spaceship of other tenant is not changed
+	require.NoError(t, repo.SetStatus(rebels, tydirium.ID, domain.SpaceshipStatusOperational))
+	spaceshipDb, err = repo.GetById(empire, tydirium.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SpaceshipStatusDamaged, spaceshipDb.Status)
+}
@@ -0,0 +1,100 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"gorm.io/gorm"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	userTokenErrorPrefix = "[repository.db.mysql.user.token]"
+
+	// test interface
+	_ service.UserTokenRepository = (*UserTokenMysqlRepo)(nil)
+)
+
+type UserTokenMysqlRepo struct {
+	db *mysql.DB
+}
+
+// user_tokens table
+type UserToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	Purpose   uint   `gorm:"uniqueIndex:idx_user_tokens_purpose_hash"`
+	Hash      string `gorm:"size:64;uniqueIndex:idx_user_tokens_purpose_hash"`
+	ExpiresAt int64
+	UsedAt    int64
+	CreatedAt int64
+}
+
+func NewUserTokenRepo(db *mysql.DB) *UserTokenMysqlRepo {
+	return &UserTokenMysqlRepo{db}
+}
+
+// create token
+func (repo *UserTokenMysqlRepo) Create(ctx context.Context, token *domain.UserToken) error {
+	tokenDb := UserToken{
+		UserID:    token.UserID,
+		Purpose:   uint(token.Purpose),
+		Hash:      token.Hash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}
+	err := repo.db.WithContext(ctx).Create(&tokenDb).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", userTokenErrorPrefix)
+	}
+	token.ID = tokenDb.ID
+	return nil
+}
+
+// get token by purpose and hash
+func (repo *UserTokenMysqlRepo) GetByHash(ctx context.Context, purpose domain.UserTokenPurpose, hash string) (*domain.UserToken, error) {
+	tokenDb := UserToken{}
+	err := repo.db.WithContext(ctx).Where("purpose = ? AND hash = ?", uint(purpose), hash).First(&tokenDb).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by hash", userTokenErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", userTokenErrorPrefix)
+	}
+	return &domain.UserToken{
+		ID:        tokenDb.ID,
+		UserID:    tokenDb.UserID,
+		Purpose:   domain.UserTokenPurpose(tokenDb.Purpose),
+		Hash:      tokenDb.Hash,
+		ExpiresAt: tokenDb.ExpiresAt,
+		UsedAt:    tokenDb.UsedAt,
+		CreatedAt: tokenDb.CreatedAt,
+	}, nil
+}
+
+// mark token used, only unused token can be marked so it is used once
+func (repo *UserTokenMysqlRepo) MarkUsed(ctx context.Context, id uint, usedAt int64) error {
+	res := repo.db.WithContext(ctx).Model(&UserToken{}).Where("id = ? AND used_at = 0", id).Update("used_at", usedAt)
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: mark used", userTokenErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: mark used", userTokenErrorPrefix)
+	}
+	return nil
+}
+
+// mark all unused tokens of user with purpose as used
+func (repo *UserTokenMysqlRepo) RevokeAll(ctx context.Context, userID uint, purpose domain.UserTokenPurpose, usedAt int64) error {
+	err := repo.db.WithContext(ctx).Model(&UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at = 0", userID, uint(purpose)).
+		Update("used_at", usedAt).Error
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke all", userTokenErrorPrefix)
+	}
+	return nil
+}
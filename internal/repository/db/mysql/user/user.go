@@ -25,17 +25,36 @@ type UserMysqlRepo struct {
 }
 
 type User struct {
-	ID        uint   `gorm:"primaryKey"`
-	Email     string `gorm:"index:,unique;size:256"` // TODO: add case insensitive index
-	Password  string `gorm:"size:256"`
-	CreatedAt int64
-	UpdatedAt int64
+	ID              uint   `gorm:"primaryKey"`
+	Email           string `gorm:"index:,unique;size:256"` // TODO: add case insensitive index
+	Password        string `gorm:"size:256"`
+	Role            uint   `gorm:"default:1"` // users of rows created before roles are plain users
+	EmailVerifiedAt int64  // zero if email is not verified
+	TOTPSecret      string `gorm:"column:totp_secret;size:64"`
+	TOTPEnabledAt   int64  `gorm:"column:totp_enabled_at"`
+	TOTPCounter     int64  `gorm:"column:totp_counter"`
+	CreatedAt       int64
+	UpdatedAt       int64
 }
 
 func NewUserRepo(db *mysql.DB) *UserMysqlRepo {
 	return &UserMysqlRepo{db}
 }
 
+// get all users
+func (repo *UserMysqlRepo) GetAll(ctx context.Context) ([]*domain.User, error) {
+	usersDb := []User{}
+	res := repo.db.Order("id").Find(&usersDb)
+	if res.Error != nil {
+		return nil, errors.Wrapf(res.Error, "%s: get all", userErrorPrefix)
+	}
+	users := make([]*domain.User, 0, res.RowsAffected)
+	for i := range usersDb {
+		users = append(users, userToDomain(&usersDb[i]))
+	}
+	return users, nil
+}
+
 // get user by id
 func (repo *UserMysqlRepo) GetById(ctx context.Context, id uint) (*domain.User, error) {
 	userDb := User{ID: id}
@@ -46,12 +65,7 @@ func (repo *UserMysqlRepo) GetById(ctx context.Context, id uint) (*domain.User,
 		}
 		return nil, errors.Wrapf(err, "%s: get by id", userErrorPrefix)
 	}
-	return &domain.User{
-		ID:        userDb.ID,
-		Email:     userDb.Email,
-		Password:  userDb.Password,
-		CreatedAt: userDb.CreatedAt,
-	}, nil
+	return userToDomain(&userDb), nil
 }
 
 // get user by email
@@ -64,61 +78,80 @@ func (repo *UserMysqlRepo) GetByEmail(ctx context.Context, email string) (*domai
 		}
 		return nil, errors.Wrapf(err, "%s: get by email", userErrorPrefix)
 	}
-	return &domain.User{
-		ID:        userDb.ID,
-		Email:     userDb.Email,
-		Password:  userDb.Password,
-		CreatedAt: userDb.CreatedAt,
-	}, nil
+	return userToDomain(&userDb), nil
 }
 
 // create user
 func (repo *UserMysqlRepo) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
 	userDb := User{
-		Email:     user.Email,
-		Password:  user.Password,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Email:           user.Email,
+		Password:        user.Password,
+		Role:            uint(user.Role),
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 	err := repo.db.Create(&userDb).Error
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s: create", userErrorPrefix)
 	}
-	userDomain := &domain.User{
-		ID:        userDb.ID,
-		Email:     userDb.Email,
-		Password:  userDb.Password,
-		CreatedAt: userDb.CreatedAt,
-		UpdatedAt: userDb.UpdatedAt,
-	}
-	return userDomain, nil
+	return userToDomain(&userDb), nil
 }
 
-// update user
+// update user, zero fields are kept as is
 func (repo *UserMysqlRepo) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
 	userQuery := User{ID: user.ID}
 	userDb := User{
-		ID:        user.ID,
-		Email:     user.Email,
-		Password:  user.Password,
-		UpdatedAt: user.UpdatedAt,
+		Email:           user.Email,
+		Password:        user.Password,
+		Role:            uint(user.Role),
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 	err := repo.db.First(&userQuery).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", userErrorPrefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", userErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by id", userErrorPrefix)
 	}
-	err = repo.db.Save(&userDb).Error
+	err = repo.db.Model(&userQuery).Updates(userDb).Error
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s: update", userErrorPrefix)
 	}
-	userDomain := &domain.User{
-		ID:        userDb.ID,
-		Email:     userDb.Email,
-		Password:  userDb.Password,
-		CreatedAt: userDb.CreatedAt,
-		UpdatedAt: userDb.UpdatedAt,
+	return userToDomain(&userQuery), nil
+}
+
+// update totp columns of user, zero values are written too as they disable mfa
+func (repo *UserMysqlRepo) UpdateTOTP(ctx context.Context, user *domain.User) error {
+	res := repo.db.WithContext(ctx).Model(&User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_secret":     user.TOTPSecret,
+		"totp_enabled_at": user.TOTPEnabledAt,
+		"totp_counter":    user.TOTPCounter,
+		"updated_at":      user.UpdatedAt,
+	})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "%s: update totp", userErrorPrefix)
+	}
+	if res.RowsAffected == 0 {
+		return errors.Wrapf(domain.ErrNotFound, "%s: update totp", userErrorPrefix)
+	}
+	return nil
+}
+
+func userToDomain(userDb *User) *domain.User {
+	return &domain.User{
+		ID:              userDb.ID,
+		Email:           userDb.Email,
+		Password:        userDb.Password,
+		Role:            domain.UserRole(userDb.Role),
+		EmailVerifiedAt: userDb.EmailVerifiedAt,
+		TOTPSecret:      userDb.TOTPSecret,
+		TOTPEnabledAt:   userDb.TOTPEnabledAt,
+		TOTPCounter:     userDb.TOTPCounter,
+		CreatedAt:       userDb.CreatedAt,
+		UpdatedAt:       userDb.UpdatedAt,
 	}
-	return userDomain, nil
 }
 
 // TODO: delete of soft delete user
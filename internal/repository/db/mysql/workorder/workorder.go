@@ -0,0 +1,392 @@
+package workorder
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	workOrderErrorPrefix = "[repository.db.mysql.workorder]"
+
+	// test interface
+	_ service.WorkOrderRepository = (*WorkOrderMysqlRepo)(nil)
+)
+
+type WorkOrderMysqlRepo struct {
+	db *mysql.DB
+}
+
+// models for orm, all tables are scoped by tenant
+// parts refer to armament catalog of tenant:
+// work_orders -> work_order_parts -> spaceship_armaments
+
+// work_orders table
+type WorkOrder struct {
+	ID            uint `gorm:"primaryKey"`
+	TenantID      uint `gorm:"index"`
+	SpaceshipID   uint `gorm:"index"`
+	Severity      uint
+	Status        uint   `gorm:"index"`
+	Description   string `gorm:"size:1024"`
+	Yard          string `gorm:"size:256"`
+	EstimatedCost float64
+	OpenedAt      int64
+	StartedAt     int64
+	ClosedAt      int64
+}
+
+// work_order_parts table
+type WorkOrderPart struct {
+	WorkOrderID         uint `gorm:"primaryKey;autoIncrement:false"`
+	SpaceshipArmamentID uint `gorm:"primaryKey;autoIncrement:false"`
+	TenantID            uint `gorm:"index"`
+	Qty                 uint
+}
+
+// part joined with armament title
+type partRow struct {
+	WorkOrderID uint
+	domain.WorkOrderPart
+}
+
+func NewWorkOrderRepo(db *mysql.DB) *WorkOrderMysqlRepo {
+	return &WorkOrderMysqlRepo{db}
+}
+
+// query scoped by tenant from context
+func (repo *WorkOrderMysqlRepo) scope(ctx context.Context) (*gorm.DB, uint, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: scope", workOrderErrorPrefix)
+	}
+	return repo.db.Conn(ctx).Where("tenant_id = ?", tenantID), tenantID, nil
+}
+
+// get work orders matching filter, the oldest first
+func (repo *WorkOrderMysqlRepo) GetAll(ctx context.Context, filter domain.WorkOrderFilter) ([]*domain.WorkOrder, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.SpaceshipID != 0 {
+		query = query.Where("spaceship_id = ?", filter.SpaceshipID)
+	}
+	if filter.Open {
+		query = query.Where("status <> ?", uint(domain.WorkOrderStatusDone))
+	} else if filter.Status != domain.WorkOrderStatusUndefined {
+		query = query.Where("status = ?", uint(filter.Status))
+	}
+
+	ordersDb := []WorkOrder{}
+	err = query.Order("opened_at, id").Find(&ordersDb).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", workOrderErrorPrefix)
+	}
+
+	return repo.withParts(ctx, tenantID, ordersDb)
+}
+
+func (repo *WorkOrderMysqlRepo) GetById(ctx context.Context, id uint) (*domain.WorkOrder, error) {
+
+	query, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orderDb := WorkOrder{}
+	err = query.Where("id = ?", id).First(&orderDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get by id", workOrderErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", workOrderErrorPrefix)
+	}
+
+	orders, err := repo.withParts(ctx, tenantID, []WorkOrder{orderDb})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders[0], nil
+}
+
+// get work order and lock its row until transaction of Atomic ends, parts are not loaded,
+// so rows locked after it are read as committed
+func (repo *WorkOrderMysqlRepo) GetForUpdate(ctx context.Context, id uint) (*domain.WorkOrder, error) {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orderDb, err := getForUpdate(repo.db.Conn(ctx), tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderToDomain(orderDb, nil), nil
+}
+
+// open work order in triage, spaceship is marked damaged by caller in the same transaction
+func (repo *WorkOrderMysqlRepo) Create(ctx context.Context, order *domain.WorkOrder) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		orderDb := WorkOrder{
+			TenantID:      tenantID,
+			SpaceshipID:   order.SpaceshipID,
+			Severity:      uint(order.Severity),
+			Status:        uint(domain.WorkOrderStatusTriage),
+			Description:   order.Description,
+			Yard:          order.Yard,
+			EstimatedCost: order.EstimatedCost,
+			OpenedAt:      order.OpenedAt,
+		}
+		err := tx.Create(&orderDb).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: create", workOrderErrorPrefix)
+		}
+
+		err = saveParts(tx, tenantID, orderDb.ID, order.Parts)
+		if err != nil {
+			return err
+		}
+
+		order.ID = orderDb.ID
+		order.TenantID = tenantID
+		order.Status = domain.WorkOrderStatusTriage
+
+		return nil
+	})
+}
+
+// update details of work order which is not done, parts are replaced
+func (repo *WorkOrderMysqlRepo) Update(ctx context.Context, order *domain.WorkOrder) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		orderDb, err := getForUpdate(tx, tenantID, order.ID)
+		if err != nil {
+			return err
+		}
+		if domain.WorkOrderStatus(orderDb.Status) == domain.WorkOrderStatusDone {
+			return domain.ErrWorkOrderClosed
+		}
+
+		err = tx.Model(orderDb).Select("severity", "description", "yard", "estimated_cost").Updates(WorkOrder{
+			Severity:      uint(order.Severity),
+			Description:   order.Description,
+			Yard:          order.Yard,
+			EstimatedCost: order.EstimatedCost,
+		}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update", workOrderErrorPrefix)
+		}
+
+		err = tx.Where("work_order_id = ? AND tenant_id = ?", orderDb.ID, tenantID).Delete(&WorkOrderPart{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: update delete parts", workOrderErrorPrefix)
+		}
+
+		return saveParts(tx, tenantID, orderDb.ID, order.Parts)
+	})
+}
+
+// move work order to status, spaceship is made operational by caller when its last order is done
+func (repo *WorkOrderMysqlRepo) SetStatus(ctx context.Context, id uint, status domain.WorkOrderStatus, at int64) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		orderDb, err := getForUpdate(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if !domain.WorkOrderStatus(orderDb.Status).CanMoveTo(status) {
+			return errors.Wrapf(domain.ErrWorkOrderMove, "%s: %s to %s", workOrderErrorPrefix,
+				domain.WorkOrderStatus(orderDb.Status), status)
+		}
+
+		update := map[string]interface{}{"status": uint(status)}
+		switch status {
+		case domain.WorkOrderStatusInProgress:
+			update["started_at"] = at
+		case domain.WorkOrderStatusDone:
+			update["closed_at"] = at
+		}
+		err = tx.Model(orderDb).Updates(update).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: set status", workOrderErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// count work orders of spaceship which are not done
+func (repo *WorkOrderMysqlRepo) CountOpen(ctx context.Context, spaceshipID uint) (int, error) {
+
+	query, _, err := repo.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = query.Model(&WorkOrder{}).Where("spaceship_id = ? AND status <> ?", spaceshipID, uint(domain.WorkOrderStatusDone)).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count open", workOrderErrorPrefix)
+	}
+
+	return int(count), nil
+}
+
+// delete work orders with parts of deleted spaceship
+func (repo *WorkOrderMysqlRepo) DeleteBySpaceship(ctx context.Context, spaceshipID uint) error {
+
+	_, tenantID, err := repo.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Where("tenant_id = ? AND work_order_id IN (?)", tenantID,
+			tx.Model(&WorkOrder{}).Select("id").Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipID)).
+			Delete(&WorkOrderPart{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete parts", workOrderErrorPrefix)
+		}
+
+		err = tx.Where("tenant_id = ? AND spaceship_id = ?", tenantID, spaceshipID).Delete(&WorkOrder{}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete", workOrderErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+func getForUpdate(tx *gorm.DB, tenantID uint, id uint) (*WorkOrder, error) {
+	orderDb := WorkOrder{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND tenant_id = ?", id, tenantID).First(&orderDb).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: get work order", workOrderErrorPrefix)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get work order", workOrderErrorPrefix)
+	}
+	return &orderDb, nil
+}
+
+// parts are resolved by title in armament catalog of tenant
+func saveParts(tx *gorm.DB, tenantID uint, orderID uint, parts []domain.WorkOrderPart) error {
+
+	for _, part := range parts {
+		if part.Qty == 0 {
+			continue
+		}
+
+		armament := struct{ ID uint }{}
+		err := tx.Table("spaceship_armaments").Where("tenant_id = ? AND title = ?", tenantID, part.Title).Take(&armament).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(domain.ErrPartUnknown, "%s: part %q", workOrderErrorPrefix, part.Title)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: get part", workOrderErrorPrefix)
+		}
+
+		// the same part listed twice is summed up
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "work_order_id"}, {Name: "spaceship_armament_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"qty": gorm.Expr("qty + ?", part.Qty)}),
+		}).Create(&WorkOrderPart{
+			WorkOrderID:         orderID,
+			SpaceshipArmamentID: armament.ID,
+			TenantID:            tenantID,
+			Qty:                 part.Qty,
+		}).Error
+		if err != nil {
+			return errors.Wrapf(err, "%s: save part", workOrderErrorPrefix)
+		}
+	}
+
+	return nil
+}
+
+// convert orders to domain level with parts loaded by one query
+func (repo *WorkOrderMysqlRepo) withParts(ctx context.Context, tenantID uint, ordersDb []WorkOrder) ([]*domain.WorkOrder, error) {
+
+	orders := make([]*domain.WorkOrder, 0, len(ordersDb))
+	if len(ordersDb) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]uint, 0, len(ordersDb))
+	for _, o := range ordersDb {
+		ids = append(ids, o.ID)
+	}
+
+	parts := []partRow{}
+	err := repo.db.Conn(ctx).Raw(`
+		SELECT wop.work_order_id, sa.title, wop.qty FROM work_order_parts wop
+		INNER JOIN spaceship_armaments sa ON sa.id = wop.spaceship_armament_id AND sa.tenant_id = ?
+		WHERE wop.tenant_id = ? AND wop.work_order_id IN ?
+		ORDER BY wop.work_order_id, sa.title
+	`, tenantID, tenantID, ids).Scan(&parts).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get parts", workOrderErrorPrefix)
+	}
+	partsByOrder := map[uint][]domain.WorkOrderPart{}
+	for _, p := range parts {
+		partsByOrder[p.WorkOrderID] = append(partsByOrder[p.WorkOrderID], p.WorkOrderPart)
+	}
+
+	for i := range ordersDb {
+		orders = append(orders, orderToDomain(&ordersDb[i], partsByOrder[ordersDb[i].ID]))
+	}
+
+	return orders, nil
+}
+
+func orderToDomain(o *WorkOrder, parts []domain.WorkOrderPart) *domain.WorkOrder {
+	return &domain.WorkOrder{
+		ID:            o.ID,
+		TenantID:      o.TenantID,
+		SpaceshipID:   o.SpaceshipID,
+		Severity:      domain.WorkOrderSeverity(o.Severity),
+		Status:        domain.WorkOrderStatus(o.Status),
+		Description:   o.Description,
+		Yard:          o.Yard,
+		EstimatedCost: o.EstimatedCost,
+		Parts:         parts,
+		OpenedAt:      o.OpenedAt,
+		StartedAt:     o.StartedAt,
+		ClosedAt:      o.ClosedAt,
+	}
+}
@@ -0,0 +1,458 @@
+// Package search is in-process full text and spatial index of spaceships
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+)
+
+var (
+	// test interface
+	_ service.SpaceshipIndex = (*Index)(nil)
+)
+
+// weight of match in field, name match is the most relevant
+var boosts = map[string]float64{
+	FieldName:     3,
+	FieldClass:    1.5,
+	FieldArmament: 1,
+}
+
+// text fields in order of highlights
+var textFields = []string{FieldName, FieldClass, FieldArmament}
+
+// prefix match weights less than whole word
+const prefixWeight = 0.5
+
+// inverted index of spaceships, kept separately for every tenant
+type Index struct {
+	mu      sync.RWMutex
+	tenants map[uint]*tenantIndex
+}
+
+type tenantIndex struct {
+	docs map[uint]*document
+	// spaceship ids by field and token
+	postings map[string]map[string]map[uint]struct{}
+	// spaceships with known coordinates
+	points *grid
+}
+
+type document struct {
+	spaceship *domain.Spaceship
+	// text values by field, armament has value per title
+	values map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{tenants: map[uint]*tenantIndex{}}
+}
+
+// add or replace spaceship
+func (idx *Index) Put(spaceship *domain.Spaceship) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	ti := idx.tenant(spaceship.TenantID)
+	ti.remove(spaceship.ID)
+	ti.add(spaceship)
+}
+
+func (idx *Index) Delete(tenantID uint, id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if ti, ok := idx.tenants[tenantID]; ok {
+		ti.remove(id)
+	}
+}
+
+// replace all spaceships of tenant, e.g. on rebuild from database
+func (idx *Index) Replace(tenantID uint, spaceships []*domain.Spaceship) {
+	ti := newTenantIndex()
+	for _, spaceship := range spaceships {
+		ti.add(spaceship)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.tenants[tenantID] = ti
+}
+
+// ranked spaceships of tenant matched by query, total is count of all matches
+func (idx *Index) Search(tenantID uint, q string, limit int) ([]*domain.SearchHit, int, error) {
+
+	query, err := Parse(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ti, ok := idx.tenants[tenantID]
+	if !ok {
+		return []*domain.SearchHit{}, 0, nil
+	}
+
+	hits := []*domain.SearchHit{}
+	for id := range ti.candidates(query) {
+		doc := ti.docs[id]
+		score, ok := ti.score(doc, query)
+		if !ok {
+			continue
+		}
+		spaceship := *doc.spaceship
+		hits = append(hits, &domain.SearchHit{
+			Spaceship:  &spaceship,
+			Score:      score,
+			Highlights: highlights(doc, query),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Spaceship.Name != hits[j].Spaceship.Name {
+			return hits[i].Spaceship.Name < hits[j].Spaceship.Name
+		}
+		return hits[i].Spaceship.ID < hits[j].Spaceship.ID
+	})
+
+	total := len(hits)
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, total, nil
+}
+
+// spaceships of tenant within radius of point from the nearest, total is count of all of them
+func (idx *Index) Near(tenantID uint, query domain.ProximityQuery) ([]*domain.ProximityHit, int) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ti, ok := idx.tenants[tenantID]
+	if !ok {
+		return []*domain.ProximityHit{}, 0
+	}
+
+	hits := []*domain.ProximityHit{}
+	for _, h := range ti.points.near(query.Point, query.Radius) {
+		spaceship := *ti.docs[h.id].spaceship
+		hits = append(hits, &domain.ProximityHit{Spaceship: &spaceship, Distance: h.distance})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Distance != hits[j].Distance {
+			return hits[i].Distance < hits[j].Distance
+		}
+		return hits[i].Spaceship.ID < hits[j].Spaceship.ID
+	})
+
+	total := len(hits)
+	if query.Limit > 0 && len(hits) > query.Limit {
+		hits = hits[:query.Limit]
+	}
+
+	return hits, total
+}
+
+func (idx *Index) tenant(tenantID uint) *tenantIndex {
+	ti, ok := idx.tenants[tenantID]
+	if !ok {
+		ti = newTenantIndex()
+		idx.tenants[tenantID] = ti
+	}
+	return ti
+}
+
+func newTenantIndex() *tenantIndex {
+	return &tenantIndex{
+		docs:     map[uint]*document{},
+		postings: map[string]map[string]map[uint]struct{}{},
+		points:   newGrid(),
+	}
+}
+
+func (ti *tenantIndex) add(spaceship *domain.Spaceship) {
+
+	// spaceship is copied, caller may change it later
+	copied := *spaceship
+	copied.Armament = append([]domain.SpaceshipArmament(nil), spaceship.Armament...)
+
+	doc := &document{
+		spaceship: &copied,
+		values: map[string][]string{
+			FieldName:  {copied.Name},
+			FieldClass: {copied.Class},
+		},
+	}
+	for _, armament := range copied.Armament {
+		doc.values[FieldArmament] = append(doc.values[FieldArmament], armament.Title)
+	}
+	ti.docs[copied.ID] = doc
+
+	if copied.Position != nil && copied.Position.Coordinates != nil {
+		ti.points.put(copied.ID, *copied.Position.Coordinates)
+	}
+
+	for field, values := range doc.values {
+		tokens, ok := ti.postings[field]
+		if !ok {
+			tokens = map[string]map[uint]struct{}{}
+			ti.postings[field] = tokens
+		}
+		for _, value := range values {
+			for _, token := range Tokenize(value) {
+				if tokens[token] == nil {
+					tokens[token] = map[uint]struct{}{}
+				}
+				tokens[token][copied.ID] = struct{}{}
+			}
+		}
+	}
+}
+
+func (ti *tenantIndex) remove(id uint) {
+
+	doc, ok := ti.docs[id]
+	if !ok {
+		return
+	}
+	delete(ti.docs, id)
+	ti.points.remove(id)
+
+	for field, values := range doc.values {
+		for _, value := range values {
+			for _, token := range Tokenize(value) {
+				ids := ti.postings[field][token]
+				delete(ids, id)
+				if len(ids) == 0 {
+					delete(ti.postings[field], token)
+				}
+			}
+		}
+	}
+}
+
+// spaceships which have all words of text terms, every spaceship if query has filters only
+func (ti *tenantIndex) candidates(query *Query) map[uint]struct{} {
+
+	var result map[uint]struct{}
+	for _, term := range query.Terms {
+		fields := termFields(term)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, token := range term.Tokens {
+			ids := map[uint]struct{}{}
+			for _, field := range fields {
+				for id := range ti.prefixed(field, token) {
+					if result == nil {
+						ids[id] = struct{}{}
+					} else if _, ok := result[id]; ok {
+						ids[id] = struct{}{}
+					}
+				}
+			}
+			result = ids
+		}
+	}
+
+	if result == nil {
+		result = make(map[uint]struct{}, len(ti.docs))
+		for id := range ti.docs {
+			result[id] = struct{}{}
+		}
+	}
+	return result
+}
+
+// spaceships with word starting with token in field
+func (ti *tenantIndex) prefixed(field string, token string) map[uint]struct{} {
+	ids := map[uint]struct{}{}
+	for word, wordIDs := range ti.postings[field] {
+		if strings.HasPrefix(word, token) {
+			for id := range wordIDs {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	return ids
+}
+
+// relevance of spaceship, false if any term is not matched
+func (ti *tenantIndex) score(doc *document, query *Query) (float64, bool) {
+
+	total := 0.0
+	for _, term := range query.Terms {
+		switch term.Field {
+		case FieldStatus:
+			if doc.spaceship.Status != term.Status {
+				return 0, false
+			}
+		case FieldCrew:
+			if !compare(float64(doc.spaceship.Crew), term.Op, term.Number) {
+				return 0, false
+			}
+		case FieldValue:
+			if !compare(doc.spaceship.Value.Float64(), term.Op, term.Number) {
+				return 0, false
+			}
+		default:
+			// the best field of term is counted
+			best, matched := 0.0, false
+			for _, field := range termFields(term) {
+				for _, value := range doc.values[field] {
+					weight, ok := phrase(Tokenize(value), term.Tokens)
+					if !ok {
+						continue
+					}
+					matched = true
+					score := boosts[field] * weight * ti.idf(field, term.Tokens)
+					if score > best {
+						best = score
+					}
+				}
+			}
+			if !matched {
+				return 0, false
+			}
+			total += best
+		}
+	}
+
+	return total, true
+}
+
+// rare words are more relevant
+func (ti *tenantIndex) idf(field string, tokens []string) float64 {
+	idf := 0.0
+	for _, token := range tokens {
+		df := len(ti.prefixed(field, token))
+		idf += math.Log(1 + float64(len(ti.docs))/float64(1+df))
+	}
+	return idf
+}
+
+// fields matched by text term
+func termFields(term Term) []string {
+	switch term.Field {
+	case "":
+		return []string{FieldName, FieldClass}
+	case FieldName, FieldClass, FieldArmament:
+		return []string{term.Field}
+	default:
+		return nil
+	}
+}
+
+// match consecutive words starting with tokens, whole words weight more
+func phrase(words []string, tokens []string) (float64, bool) {
+	best, matched := 0.0, false
+	for i := 0; i+len(tokens) <= len(words); i++ {
+		weight := 0.0
+		ok := true
+		for j, token := range tokens {
+			switch {
+			case words[i+j] == token:
+				weight += 1
+			case strings.HasPrefix(words[i+j], token):
+				weight += prefixWeight
+			default:
+				ok = false
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok && weight > best {
+			best, matched = weight, true
+		}
+	}
+	return best / float64(len(tokens)), matched
+}
+
+func compare(value float64, op Op, number float64) bool {
+	switch op {
+	case OpLt:
+		return value < number
+	case OpLe:
+		return value <= number
+	case OpGt:
+		return value > number
+	case OpGe:
+		return value >= number
+	default:
+		return value == number
+	}
+}
+
+// matched words of text fields wrapped in <em>, values are html escaped
+func highlights(doc *document, query *Query) map[string][]string {
+
+	result := map[string][]string{}
+	for _, field := range textFields {
+		tokens := []string{}
+		for _, term := range query.textTerms(field) {
+			tokens = append(tokens, term.Tokens...)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		for _, value := range doc.values[field] {
+			if highlighted, ok := highlight(value, tokens); ok {
+				result[field] = append(result[field], highlighted)
+			}
+		}
+	}
+	return result
+}
+
+func highlight(value string, tokens []string) (string, bool) {
+
+	var b strings.Builder
+	found := false
+	last := 0
+	for start := 0; start < len(value); {
+		r, size := utf8.DecodeRuneInString(value[start:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			start += size
+			continue
+		}
+		end := start
+		for end < len(value) {
+			r, size := utf8.DecodeRuneInString(value[end:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			end += size
+		}
+
+		word := strings.ToLower(value[start:end])
+		for _, token := range tokens {
+			if strings.HasPrefix(word, token) {
+				b.WriteString(html.EscapeString(value[last:start]))
+				b.WriteString("<em>" + html.EscapeString(value[start:end]) + "</em>")
+				last = end
+				found = true
+				break
+			}
+		}
+		start = end
+	}
+	b.WriteString(html.EscapeString(value[last:]))
+
+	return b.String(), found
+}
@@ -0,0 +1,182 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// searchable fields of spaceship
+const (
+	FieldName     = "name"
+	FieldClass    = "class"
+	FieldArmament = "armament"
+	FieldStatus   = "status"
+	FieldCrew     = "crew"
+	FieldValue    = "value"
+)
+
+// comparison of numeric field
+type Op int
+
+const (
+	OpEq Op = iota
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+)
+
+// operators are matched longest first
+var ops = []struct {
+	text string
+	op   Op
+}{
+	{">=", OpGe},
+	{"<=", OpLe},
+	{">", OpGt},
+	{"<", OpLt},
+	{"=", OpEq},
+	{":", OpEq},
+}
+
+// term of query, all terms must match
+type Term struct {
+	// empty field is free text matched over name and class
+	Field string
+	// lowercase tokens of text term, matched as phrase
+	Tokens []string
+	Status domain.SpaceshipStatus
+	Op     Op
+	Number float64
+}
+
+// parsed search query
+type Query struct {
+	Terms []Term
+}
+
+// parse query like `destroyer class:"Star Destroyer" armament:ion status:operational crew>5000 value<1e9`
+func Parse(q string) (*Query, error) {
+
+	words, err := split(q)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Query{}
+	for _, word := range words {
+		term, err := parseTerm(word)
+		if err != nil {
+			return nil, err
+		}
+		// words without letters and digits are skipped
+		if term.Field != FieldCrew && term.Field != FieldValue && term.Field != FieldStatus && len(term.Tokens) == 0 {
+			continue
+		}
+		query.Terms = append(query.Terms, term)
+	}
+
+	if len(query.Terms) == 0 {
+		return nil, errors.Wrap(domain.ErrSearchQuery, "query is empty")
+	}
+
+	return query, nil
+}
+
+// text terms matched against field, free text is matched against name and class
+func (q *Query) textTerms(field string) []Term {
+	terms := []Term{}
+	for _, term := range q.Terms {
+		if term.Field == field || (term.Field == "" && (field == FieldName || field == FieldClass)) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func parseTerm(word string) (Term, error) {
+
+	field, op, value, ok := cutField(word)
+	if !ok {
+		return Term{Tokens: Tokenize(unquote(word))}, nil
+	}
+
+	switch field {
+	case FieldName, FieldClass, FieldArmament:
+		if op != OpEq {
+			return Term{}, errors.Wrapf(domain.ErrSearchQuery, "field %s can't be compared", field)
+		}
+		return Term{Field: field, Tokens: Tokenize(value)}, nil
+	case FieldStatus:
+		status := domain.SpaceshipStatusFromString(value)
+		if op != OpEq || status == domain.SpaceshipStatusUndefined {
+			return Term{}, errors.Wrapf(domain.ErrSearchQuery, "status %q is unknown", value)
+		}
+		return Term{Field: field, Status: status}, nil
+	case FieldCrew, FieldValue:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Term{}, errors.Wrapf(domain.ErrSearchQuery, "%s must be a number", field)
+		}
+		return Term{Field: field, Op: op, Number: number}, nil
+	default:
+		return Term{}, errors.Wrapf(domain.ErrSearchQuery, "field %q is unknown", field)
+	}
+}
+
+// split `field<op>value`, words starting with quote are free text
+func cutField(word string) (string, Op, string, bool) {
+	end := strings.IndexAny(word, `:<>="`)
+	if end <= 0 || word[end] == '"' {
+		return "", 0, "", false
+	}
+	for _, o := range ops {
+		if strings.HasPrefix(word[end:], o.text) {
+			return strings.ToLower(word[:end]), o.op, unquote(word[end+len(o.text):]), true
+		}
+	}
+	return "", 0, "", false
+}
+
+// split query by spaces outside of quotes
+func split(q string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.Wrap(domain.ErrSearchQuery, "quote is not closed")
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
+
+// lowercase words of text, everything except letters and digits separates words
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,201 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+
+	testCases := []struct {
+		name  string
+		query string
+		terms []Term
+		err   error
+	}{
+		{
+			name:  "free text and phrase",
+			query: `devastator "Star Destroyer"`,
+			terms: []Term{{Tokens: []string{"devastator"}}, {Tokens: []string{"star", "destroyer"}}},
+		},
+		{
+			name:  "field terms",
+			query: `class:"Star Destroyer" armament:"Ion Cannons" Status:damaged`,
+			terms: []Term{
+				{Field: FieldClass, Tokens: []string{"star", "destroyer"}},
+				{Field: FieldArmament, Tokens: []string{"ion", "cannons"}},
+				{Field: FieldStatus, Status: domain.SpaceshipStatusDamaged},
+			},
+		},
+		{
+			name:  "numeric terms",
+			query: `crew>5000 value<1e9 crew<=40000 value:10`,
+			terms: []Term{
+				{Field: FieldCrew, Op: OpGt, Number: 5000},
+				{Field: FieldValue, Op: OpLt, Number: 1e9},
+				{Field: FieldCrew, Op: OpLe, Number: 40000},
+				{Field: FieldValue, Op: OpEq, Number: 10},
+			},
+		},
+		{name: "empty query", query: "  ", err: domain.ErrSearchQuery},
+		{name: "unknown field", query: "pilot:vader", err: domain.ErrSearchQuery},
+		{name: "unknown status", query: "status:destroyed", err: domain.ErrSearchQuery},
+		{name: "not a number", query: "crew>many", err: domain.ErrSearchQuery},
+		{name: "compared text", query: "class>star", err: domain.ErrSearchQuery},
+		{name: "not closed quote", query: `class:"Star`, err: domain.ErrSearchQuery},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		query, err := Parse(test.query)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, test.terms, query.Terms)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+
+	index := NewIndex()
+	index.Replace(1, []*domain.Spaceship{
+		{ID: 1, TenantID: 1, Name: "Devastator", Class: "Star Destroyer", Crew: 35000, Value: domain.NewDecimal(1e9), Status: domain.SpaceshipStatusOperational,
+			Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}}},
+		{ID: 2, TenantID: 1, Name: "Avenger", Class: "Star Destroyer", Crew: 37000, Value: domain.NewDecimal(2e9), Status: domain.SpaceshipStatusDamaged,
+			Armament: []domain.SpaceshipArmament{{Title: "Ion Cannon Battery", Qty: 20}, {Title: "Turbo Laser", Qty: 10}}},
+		{ID: 3, TenantID: 1, Name: "Star Runner", Class: "Corvette", Crew: 46, Value: domain.NewDecimal(3e6), Status: domain.SpaceshipStatusOperational,
+			Armament: []domain.SpaceshipArmament{{Title: "Ion Laser", Qty: 2}}},
+	})
+	index.Put(&domain.Spaceship{ID: 4, TenantID: 2, Name: "Liberty", Class: "Star Cruiser"})
+
+	testCases := []struct {
+		name  string
+		query string
+		ids   []uint
+	}{
+		// equal scores are ordered by name
+		{name: "free text over name and class", query: "star", ids: []uint{3, 2, 1}},
+		{name: "prefix of word", query: "devas", ids: []uint{1}},
+		{name: "phrase", query: `"star destroyer"`, ids: []uint{2, 1}},
+		{name: "whole words rank higher", query: `armament:"ion cannon"`, ids: []uint{2, 1}},
+		{name: "armament words in different titles", query: `armament:"laser cannons"`, ids: []uint{}},
+		{name: "class and armament", query: `class:"Star Destroyer" armament:"Ion Cannons"`, ids: []uint{1}},
+		{name: "status", query: "status:damaged", ids: []uint{2}},
+		{name: "numeric", query: "crew>5000 value<2e9", ids: []uint{1}},
+		{name: "other tenant", query: "liberty", ids: []uint{}},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		hits, total, err := index.Search(1, test.query, 10)
+		require.NoError(t, err)
+		assert.Equal(t, len(test.ids), total)
+		ids := []uint{}
+		for _, hit := range hits {
+			ids = append(ids, hit.Spaceship.ID)
+		}
+		assert.Equal(t, test.ids, ids)
+	}
+
+	hits, _, err := index.Search(1, "devastator armament:ion", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, map[string][]string{
+		FieldName:     {"<em>Devastator</em>"},
+		FieldArmament: {"<em>Ion</em> Cannons"},
+	}, hits[0].Highlights)
+
+	// index is kept up to date
+	index.Put(&domain.Spaceship{ID: 1, TenantID: 1, Name: "Executor", Class: "Super Star Destroyer"})
+	index.Delete(1, 2)
+	hits, total, err := index.Search(1, "destroyer", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "Executor", hits[0].Spaceship.Name)
+	_, total, err = index.Search(1, "devastator", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestIndex_Near(t *testing.T) {
+
+	at := func(x, y, z float64) *domain.Position {
+		return &domain.Position{Sector: "Outer Rim", Coordinates: &domain.Coordinates{X: x, Y: y, Z: z}}
+	}
+
+	idx := NewIndex()
+	idx.Replace(1, []*domain.Spaceship{
+		{ID: 1, TenantID: 1, Name: "Executor", Position: at(0, 0, 0)},
+		{ID: 2, TenantID: 1, Name: "Avenger", Position: at(30, 40, 0)},
+		// neighbour cell of grid
+		{ID: 3, TenantID: 1, Name: "Devastator", Position: at(-60, 0, 80)},
+		{ID: 4, TenantID: 1, Name: "Tydirium", Position: at(5000, 0, 0)},
+		// sector without coordinates
+		{ID: 5, TenantID: 1, Name: "Home One", Position: &domain.Position{Sector: "Core Worlds"}},
+		{ID: 6, TenantID: 1, Name: "Accuser"},
+	})
+	idx.Put(&domain.Spaceship{ID: 7, TenantID: 2, Name: "Chimaera", Position: at(1, 1, 1)})
+
+	testCases := []struct {
+		name  string
+		query domain.ProximityQuery
+		ids   []uint
+		total int
+	}{
+		{
+			name:  "nearest first within radius",
+			query: domain.ProximityQuery{Point: domain.Coordinates{}, Radius: 100},
+			ids:   []uint{1, 2, 3},
+			total: 3,
+		},
+		{
+			name:  "limited",
+			query: domain.ProximityQuery{Point: domain.Coordinates{X: 30, Y: 40}, Radius: 100, Limit: 1},
+			ids:   []uint{2},
+			total: 2,
+		},
+		{
+			name:  "radius beyond occupied cells",
+			query: domain.ProximityQuery{Point: domain.Coordinates{}, Radius: 1e6},
+			ids:   []uint{1, 2, 3, 4},
+			total: 4,
+		},
+		{
+			name:  "nothing within radius",
+			query: domain.ProximityQuery{Point: domain.Coordinates{X: 1000}, Radius: 10},
+			ids:   []uint{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		hits, total := idx.Near(1, test.query)
+		ids := []uint{}
+		for _, hit := range hits {
+			ids = append(ids, hit.Spaceship.ID)
+		}
+		assert.Equal(t, test.ids, ids)
+		assert.Equal(t, test.total, total)
+	}
+
+	// moved spaceship is found at new coordinates only
+	idx.Put(&domain.Spaceship{ID: 4, TenantID: 1, Name: "Tydirium", Position: at(10, 0, 0)})
+	hits, _ := idx.Near(1, domain.ProximityQuery{Point: domain.Coordinates{}, Radius: 20})
+	require.Len(t, hits, 2)
+	assert.Equal(t, uint(4), hits[1].Spaceship.ID)
+	assert.Equal(t, float64(10), hits[1].Distance)
+
+	idx.Delete(1, 4)
+	hits, total := idx.Near(1, domain.ProximityQuery{Point: domain.Coordinates{}, Radius: 1e6})
+	assert.Equal(t, 3, total)
+	assert.Len(t, hits, 3)
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"math"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// side of grid cell in parsecs, proximity query visits cells overlapped by its radius
+const cellSize = 100.0
+
+type cell [3]int64
+
+// uniform grid of spaceship coordinates, only cells with spaceships are kept
+type grid struct {
+	cells  map[cell]map[uint]domain.Coordinates
+	points map[uint]cell
+}
+
+// spaceship within radius of point
+type gridHit struct {
+	id       uint
+	distance float64
+}
+
+func newGrid() *grid {
+	return &grid{cells: map[cell]map[uint]domain.Coordinates{}, points: map[uint]cell{}}
+}
+
+func cellOf(c domain.Coordinates) cell {
+	return cell{
+		int64(math.Floor(c.X / cellSize)),
+		int64(math.Floor(c.Y / cellSize)),
+		int64(math.Floor(c.Z / cellSize)),
+	}
+}
+
+func (g *grid) put(id uint, c domain.Coordinates) {
+	g.remove(id)
+	key := cellOf(c)
+	if g.cells[key] == nil {
+		g.cells[key] = map[uint]domain.Coordinates{}
+	}
+	g.cells[key][id] = c
+	g.points[id] = key
+}
+
+func (g *grid) remove(id uint) {
+	key, ok := g.points[id]
+	if !ok {
+		return
+	}
+	delete(g.points, id)
+	delete(g.cells[key], id)
+	if len(g.cells[key]) == 0 {
+		delete(g.cells, key)
+	}
+}
+
+// spaceships within radius of point in any order
+func (g *grid) near(point domain.Coordinates, radius float64) []gridHit {
+
+	from := cellOf(domain.Coordinates{X: point.X - radius, Y: point.Y - radius, Z: point.Z - radius})
+	to := cellOf(domain.Coordinates{X: point.X + radius, Y: point.Y + radius, Z: point.Z + radius})
+
+	hits := []gridHit{}
+	match := func(points map[uint]domain.Coordinates) {
+		for id, c := range points {
+			if d := point.Distance(c); d <= radius {
+				hits = append(hits, gridHit{id, d})
+			}
+		}
+	}
+
+	// large radius overlaps more cells than there are occupied, so occupied cells are scanned
+	overlapped := 1.0
+	for axis := range from {
+		overlapped *= float64(to[axis]-from[axis]) + 1
+	}
+	if overlapped > float64(len(g.cells)) {
+		for key, points := range g.cells {
+			if key[0] >= from[0] && key[0] <= to[0] && key[1] >= from[1] && key[1] <= to[1] && key[2] >= from[2] && key[2] <= to[2] {
+				match(points)
+			}
+		}
+		return hits
+	}
+
+	for x := from[0]; x <= to[0]; x++ {
+		for y := from[1]; y <= to[1]; y++ {
+			for z := from[2]; z <= to[2]; z++ {
+				match(g.cells[cell{x, y, z}])
+			}
+		}
+	}
+	return hits
+}
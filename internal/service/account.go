@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/mailer"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	accountErrorPrefix = "[service.account]"
+)
+
+//go:generate mockery --dir . --name UserTokenRepository --output ./mocks
+type UserTokenRepository interface {
+	Create(context.Context, *domain.UserToken) error
+	GetByHash(context.Context, domain.UserTokenPurpose, string) (*domain.UserToken, error)
+	MarkUsed(context.Context, uint, int64) error
+	RevokeAll(context.Context, uint, domain.UserTokenPurpose, int64) error
+}
+
+//go:generate mockery --dir . --name Mailer --output ./mocks
+type Mailer interface {
+	Send(context.Context, mailer.Message) error
+}
+
+// account service settings
+type AccountConfig struct {
+	// base url of frontend used in email links
+	AppURL           string
+	PasswordResetTTL time.Duration
+	EmailVerifyTTL   time.Duration
+}
+
+// account service handles password reset and email verification
+type AccountService struct {
+	users  UserRepository
+	tokens UserTokenRepository
+	mailer Mailer
+	config AccountConfig
+}
+
+// account service builder
+func NewAccountService(users UserRepository, tokens UserTokenRepository, mailer Mailer, config AccountConfig) *AccountService {
+	return &AccountService{users, tokens, mailer, config}
+}
+
+// send password reset token, unknown email is silently ignored
+func (s *AccountService) ForgotPassword(ctx context.Context, email string) error {
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: forgot password get user", accountErrorPrefix)
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.UserTokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/password/reset", token)
+	return s.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Imperial Fleet password reset",
+		Body: fmt.Sprintf("Someone requested password reset for your account.\n\n"+
+			"Reset password: %s\nReset token: %s\n\n"+
+			"The token expires in %s. Ignore this email if you did not request reset.\n",
+			link, token, s.config.PasswordResetTTL),
+	})
+}
+
+// set new password by reset token
+func (s *AccountService) ResetPassword(ctx context.Context, req *domain.PasswordResetReq) error {
+
+	if req.Token == "" || req.Password == "" {
+		return domain.ErrRegRequiredFields
+	}
+
+	if req.Password != req.RePassword {
+		return domain.ErrRePasswordWrong
+	}
+
+	token, err := s.consumeToken(ctx, domain.UserTokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.GetById(ctx, token.UserID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: reset password get user", accountErrorPrefix)
+	}
+
+	user.Password, err = hashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	// reset link proves email ownership
+	now := time.Now().Unix()
+	if user.EmailVerifiedAt == 0 {
+		user.EmailVerifiedAt = now
+	}
+	user.UpdatedAt = now
+
+	_, err = s.users.Update(ctx, user)
+	if err != nil {
+		return errors.Wrapf(err, "%s: reset password update user", accountErrorPrefix)
+	}
+
+	// other reset tokens are not valid after password change
+	err = s.tokens.RevokeAll(ctx, user.ID, domain.UserTokenPurposePasswordReset, now)
+	if err != nil {
+		return errors.Wrapf(err, "%s: reset password revoke tokens", accountErrorPrefix)
+	}
+
+	return nil
+}
+
+// send email verification token, unknown and verified emails are silently ignored
+func (s *AccountService) SendVerification(ctx context.Context, email string) error {
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: send verification get user", accountErrorPrefix)
+	}
+
+	if user.EmailVerifiedAt != 0 {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.UserTokenPurposeEmailVerify, s.config.EmailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/email/verify", token)
+	return s.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Imperial Fleet email verification",
+		Body: fmt.Sprintf("Welcome to Imperial Fleet.\n\n"+
+			"Verify email: %s\nVerification token: %s\n\n"+
+			"The token expires in %s.\n",
+			link, token, s.config.EmailVerifyTTL),
+	})
+}
+
+// verify email by token
+func (s *AccountService) VerifyEmail(ctx context.Context, rawToken string) error {
+
+	if rawToken == "" {
+		return domain.ErrTokenInvalid
+	}
+
+	token, err := s.consumeToken(ctx, domain.UserTokenPurposeEmailVerify, rawToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.GetById(ctx, token.UserID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: verify email get user", accountErrorPrefix)
+	}
+
+	now := time.Now().Unix()
+	user.EmailVerifiedAt = now
+	user.UpdatedAt = now
+
+	_, err = s.users.Update(ctx, user)
+	if err != nil {
+		return errors.Wrapf(err, "%s: verify email update user", accountErrorPrefix)
+	}
+
+	return nil
+}
+
+// check that user exists and email is verified
+func (s *AccountService) CheckVerified(ctx context.Context, userID uint) error {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == 0 {
+		return domain.ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+// user has at least global role, e.g. admin of catalog shared by organizations
+func (s *AccountService) CheckRole(ctx context.Context, userID uint, role domain.UserRole) error {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role < role {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// create random token, only its hash is stored
+func (s *AccountService) issueToken(ctx context.Context, userID uint, purpose domain.UserTokenPurpose, ttl time.Duration) (string, error) {
+
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: generate token", accountErrorPrefix)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	err = s.tokens.Create(ctx, &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		Hash:      hashToken(token),
+		ExpiresAt: now.Add(ttl).Unix(),
+		CreatedAt: now.Unix(),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: save token", accountErrorPrefix)
+	}
+
+	return token, nil
+}
+
+// find valid token and mark it used
+func (s *AccountService) consumeToken(ctx context.Context, purpose domain.UserTokenPurpose, rawToken string) (*domain.UserToken, error) {
+
+	token, err := s.tokens.GetByHash(ctx, purpose, hashToken(rawToken))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get token", accountErrorPrefix)
+	}
+
+	now := time.Now().Unix()
+	if token.UsedAt != 0 || token.ExpiresAt <= now {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	// concurrent use of the same token is resolved by repo
+	err = s.tokens.MarkUsed(ctx, token.ID, now)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: mark token used", accountErrorPrefix)
+	}
+
+	return token, nil
+}
+
+func (s *AccountService) send(ctx context.Context, msg mailer.Message) error {
+	err := s.mailer.Send(ctx, msg)
+	if err != nil {
+		return errors.Wrapf(err, "%s: send email", accountErrorPrefix)
+	}
+	return nil
+}
+
+// frontend link with token
+func (s *AccountService) link(path string, token string) string {
+	return s.config.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
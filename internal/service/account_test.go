@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/mailer"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccountService_ForgotPassword(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository, *mocks.UserTokenRepository, *mocks.Mailer)
+		input        string
+		err          error
+	}{
+		{
+			name:  "success forgot password",
+			input: "test@test.com",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, mail *mocks.Mailer) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(&domain.User{ID: 1, Email: "test@test.com"}, nil)
+				tokenRepo.On("Create", ctx, mock.MatchedBy(func(token *domain.UserToken) bool {
+					return token.UserID == 1 && token.Purpose == domain.UserTokenPurposePasswordReset && len(token.Hash) == 64
+				})).Return(nil)
+				mail.On("Send", ctx, mock.MatchedBy(func(msg mailer.Message) bool {
+					return msg.To == "test@test.com"
+				})).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "unknown email is ignored",
+			input: "test@test.com",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, mail *mocks.Mailer) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(nil, domain.ErrNotFound)
+			},
+			err: nil,
+		},
+		{
+			name:  "failed forgot password send error",
+			input: "test@test.com",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, mail *mocks.Mailer) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(&domain.User{ID: 1, Email: "test@test.com"}, nil)
+				tokenRepo.On("Create", ctx, mock.Anything).Return(nil)
+				mail.On("Send", ctx, mock.Anything).Return(errors.New("error"))
+			},
+			err: errors.New("error"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		tokenRepo := mocks.NewUserTokenRepository(t)
+		mail := mocks.NewMailer(t)
+		accountService := NewAccountService(userRepo, tokenRepo, mail, AccountConfig{PasswordResetTTL: time.Hour})
+
+		test.expectations(ctx, userRepo, tokenRepo, mail)
+
+		err := accountService.ForgotPassword(ctx, test.input)
+
+		if test.err != nil {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		userRepo.AssertExpectations(t)
+		tokenRepo.AssertExpectations(t)
+		mail.AssertExpectations(t)
+	}
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+
+	hash := hashToken("token")
+	future := time.Now().Add(time.Hour).Unix()
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository, *mocks.UserTokenRepository)
+		input        *domain.PasswordResetReq
+		err          error
+	}{
+		{
+			name:  "success reset password",
+			input: &domain.PasswordResetReq{Token: "token", Password: "456456", RePassword: "456456"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository) {
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposePasswordReset, hash).Return(&domain.UserToken{ID: 2, UserID: 1, ExpiresAt: future}, nil)
+				tokenRepo.On("MarkUsed", ctx, uint(2), mock.Anything).Return(nil)
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+				userRepo.On("Update", ctx, mock.MatchedBy(func(user *domain.User) bool {
+					return user.Password != "" && user.EmailVerifiedAt != 0
+				})).Return(&domain.User{}, nil)
+				tokenRepo.On("RevokeAll", ctx, uint(1), domain.UserTokenPurposePasswordReset, mock.Anything).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:         "failed reset password repassword",
+			input:        &domain.PasswordResetReq{Token: "token", Password: "456456", RePassword: "123"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository) {},
+			err:          domain.ErrRePasswordWrong,
+		},
+		{
+			name:  "failed reset password unknown token",
+			input: &domain.PasswordResetReq{Token: "token", Password: "456456", RePassword: "456456"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository) {
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposePasswordReset, hash).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrTokenInvalid,
+		},
+		{
+			name:  "failed reset password expired token",
+			input: &domain.PasswordResetReq{Token: "token", Password: "456456", RePassword: "456456"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository) {
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposePasswordReset, hash).Return(&domain.UserToken{ID: 2, UserID: 1, ExpiresAt: 1}, nil)
+			},
+			err: domain.ErrTokenInvalid,
+		},
+		{
+			name:  "failed reset password used token",
+			input: &domain.PasswordResetReq{Token: "token", Password: "456456", RePassword: "456456"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository) {
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposePasswordReset, hash).Return(&domain.UserToken{ID: 2, UserID: 1, ExpiresAt: future}, nil)
+				tokenRepo.On("MarkUsed", ctx, uint(2), mock.Anything).Return(domain.ErrNotFound)
+			},
+			err: domain.ErrTokenInvalid,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		tokenRepo := mocks.NewUserTokenRepository(t)
+		accountService := NewAccountService(userRepo, tokenRepo, mocks.NewMailer(t), AccountConfig{})
+
+		test.expectations(ctx, userRepo, tokenRepo)
+
+		err := accountService.ResetPassword(ctx, test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		userRepo.AssertExpectations(t)
+		tokenRepo.AssertExpectations(t)
+	}
+}
+
+func TestAccountService_CheckRole(t *testing.T) {
+
+	testCases := []struct {
+		name  string
+		user  *domain.User
+		role  domain.UserRole
+		dbErr error
+		err   error
+	}{
+		{
+			name: "success admin",
+			user: &domain.User{ID: 1, Role: domain.UserRoleAdmin},
+			role: domain.UserRoleAdmin,
+		},
+		{
+			name: "failed officer",
+			user: &domain.User{ID: 1, Role: domain.UserRoleOfficer},
+			role: domain.UserRoleAdmin,
+			err:  domain.ErrForbidden,
+		},
+		{
+			name:  "failed user not found",
+			role:  domain.UserRoleAdmin,
+			dbErr: domain.ErrNotFound,
+			err:   domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		accountService := NewAccountService(userRepo, nil, nil, AccountConfig{})
+
+		userRepo.On("GetById", ctx, uint(1)).Return(test.user, test.dbErr)
+
+		err := accountService.CheckRole(ctx, uint(1), test.role)
+
+		assert.ErrorIs(t, err, test.err)
+
+		userRepo.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	apiKeyErrorPrefix = "[service.apikey]"
+)
+
+const (
+	// prefix of all keys, makes leaked keys easy to find by secret scanners
+	apiKeyPrefix = "ifk_"
+	// characters of key shown in key list
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+	// last use is saved not more often than once per interval
+	apiKeyTouchInterval = time.Minute
+)
+
+//go:generate mockery --dir . --name APIKeyRepository --output ./mocks
+type APIKeyRepository interface {
+	GetByUser(context.Context, uint) ([]*domain.APIKey, error)
+	GetByServiceAccount(context.Context, uint) ([]*domain.APIKey, error)
+	GetById(context.Context, uint) (*domain.APIKey, error)
+	GetByHash(context.Context, string) (*domain.APIKey, error)
+	Create(context.Context, *domain.APIKey) error
+	Delete(context.Context, uint) error
+	DeleteByServiceAccount(context.Context, uint) error
+	Touch(context.Context, uint, int64) error
+}
+
+// api key service
+type APIKeyService struct {
+	keys     APIKeyRepository
+	users    UserRepository
+	accounts ServiceAccountRepository
+}
+
+func NewAPIKeyService(keys APIKeyRepository, users UserRepository, accounts ServiceAccountRepository) *APIKeyService {
+	return &APIKeyService{keys, users, accounts}
+}
+
+// create key for user, returns key which is not stored and is shown once
+func (s *APIKeyService) Create(ctx context.Context, userID uint, req *domain.APIKeyCreateReq) (*domain.APIKey, string, error) {
+
+	key, rawKey, err := newAPIKey(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: create get user", apiKeyErrorPrefix)
+	}
+
+	key.UserID = user.ID
+	err = s.keys.Create(ctx, key)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+
+	return key, rawKey, nil
+}
+
+// keys of user
+func (s *APIKeyService) List(ctx context.Context, userID uint) ([]*domain.APIKey, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list get user", apiKeyErrorPrefix)
+	}
+
+	keys, err := s.keys.GetByUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list", apiKeyErrorPrefix)
+	}
+
+	return keys, nil
+}
+
+// delete key of user, admins can delete any key
+func (s *APIKeyService) Revoke(ctx context.Context, userID uint, id uint) error {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke get user", apiKeyErrorPrefix)
+	}
+
+	key, err := s.keys.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// key of other user looks like missing key
+	if key.UserID != user.ID && user.Role != domain.UserRoleAdmin {
+		return errors.Wrapf(domain.ErrNotFound, "%s: revoke", apiKeyErrorPrefix)
+	}
+
+	err = s.keys.Delete(ctx, key.ID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke", apiKeyErrorPrefix)
+	}
+
+	return nil
+}
+
+// find valid key by raw key and track its use
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, error) {
+
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, domain.ErrAPIKeyInvalid
+	}
+
+	key, err := s.keys.GetByHash(ctx, hashToken(rawKey))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: authenticate", apiKeyErrorPrefix)
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != 0 && key.ExpiresAt <= now.Unix() {
+		return nil, domain.ErrAPIKeyInvalid
+	}
+
+	// key of service account acts as account, so it stops working once account is revoked
+	if key.ServiceAccountID != 0 {
+		account, err := s.accounts.GetById(ctx, key.ServiceAccountID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrAPIKeyInvalid
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: authenticate get service account", apiKeyErrorPrefix)
+		}
+		if account.RevokedAt != 0 {
+			return nil, domain.ErrAPIKeyInvalid
+		}
+		key.ServiceAccount = account
+	}
+
+	if now.Sub(time.Unix(key.LastUsedAt, 0)) >= apiKeyTouchInterval {
+		key.LastUsedAt = now.Unix()
+		err = s.keys.Touch(ctx, key.ID, key.LastUsedAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: touch", apiKeyErrorPrefix)
+		}
+	}
+
+	return key, nil
+}
+
+// validate creation request and generate key without owner, raw key is returned to be shown once
+func newAPIKey(req *domain.APIKeyCreateReq) (*domain.APIKey, string, error) {
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, "", domain.ErrNameRequired
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, "", domain.ErrScopeWrong
+	}
+	for _, scope := range req.Scopes {
+		if !domain.ValidScope(scope) {
+			return nil, "", errors.Wrapf(domain.ErrScopeWrong, "%s: scope %q", apiKeyErrorPrefix, scope)
+		}
+	}
+
+	now := time.Now().Unix()
+	if req.ExpiresAt != 0 && req.ExpiresAt <= now {
+		return nil, "", domain.ErrExpiryWrong
+	}
+
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: generate key", apiKeyErrorPrefix)
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	return &domain.APIKey{
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyShownLength],
+		Hash:      hashToken(rawKey),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}, rawKey, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyService_Create(t *testing.T) {
+
+	officer := &domain.User{ID: 1, Email: "test@test.com", Role: domain.UserRoleOfficer}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.APIKeyRepository, *mocks.UserRepository)
+		input        *domain.APIKeyCreateReq
+		err          error
+	}{
+		{
+			name:  "success create",
+			input: &domain.APIKeyCreateReq{Name: "ci", Scopes: []string{domain.ScopeSpaceshipsRead}},
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(officer, nil)
+				keyRepo.On("Create", ctx, mock.MatchedBy(func(key *domain.APIKey) bool {
+					return key.UserID == 1 && strings.HasPrefix(key.Prefix, apiKeyPrefix) && len(key.Hash) == 64
+				})).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:         "failed create without name",
+			input:        &domain.APIKeyCreateReq{Scopes: []string{domain.ScopeSpaceshipsRead}},
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrNameRequired,
+		},
+		{
+			name:         "failed create unknown scope",
+			input:        &domain.APIKeyCreateReq{Name: "ci", Scopes: []string{"spaceships:fly"}},
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrScopeWrong,
+		},
+		{
+			name:         "failed create expired",
+			input:        &domain.APIKeyCreateReq{Name: "ci", Scopes: []string{domain.ScopeSpaceshipsRead}, ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrExpiryWrong,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		keyRepo := mocks.NewAPIKeyRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		apiKeyService := NewAPIKeyService(keyRepo, userRepo, mocks.NewServiceAccountRepository(t))
+
+		test.expectations(ctx, keyRepo, userRepo)
+
+		key, rawKey, err := apiKeyService.Create(ctx, uint(1), test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, rawKey[:apiKeyShownLength], key.Prefix)
+			assert.Equal(t, hashToken(rawKey), key.Hash)
+		}
+
+		keyRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+
+	rawKey := apiKeyPrefix + "secret"
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.APIKeyRepository, *mocks.ServiceAccountRepository)
+		input        string
+		account      bool
+		err          error
+	}{
+		{
+			name:  "success authenticate touches key",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1}, nil)
+				keyRepo.On("Touch", ctx, uint(1), mock.Anything).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "success authenticate recently used",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1, LastUsedAt: now}, nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "failed authenticate without prefix",
+			input: "secret",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+			},
+			err: domain.ErrAPIKeyInvalid,
+		},
+		{
+			name:  "failed authenticate unknown key",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrAPIKeyInvalid,
+		},
+		{
+			name:  "failed authenticate expired key",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1, ExpiresAt: now - 1}, nil)
+			},
+			err: domain.ErrAPIKeyInvalid,
+		},
+		{
+			name:  "success authenticate key of service account",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1, ServiceAccountID: 3, LastUsedAt: now}, nil)
+				accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 2, Role: domain.OrgRoleMember}, nil)
+			},
+			account: true,
+			err:     nil,
+		},
+		{
+			name:  "failed authenticate key of revoked service account",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1, ServiceAccountID: 3}, nil)
+				accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 2, RevokedAt: now}, nil)
+			},
+			err: domain.ErrAPIKeyInvalid,
+		},
+		{
+			name:  "failed authenticate key of missing service account",
+			input: rawKey,
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, accountRepo *mocks.ServiceAccountRepository) {
+				keyRepo.On("GetByHash", ctx, hashToken(rawKey)).Return(&domain.APIKey{ID: 1, ServiceAccountID: 3}, nil)
+				accountRepo.On("GetById", ctx, uint(3)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrAPIKeyInvalid,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		keyRepo := mocks.NewAPIKeyRepository(t)
+		accountRepo := mocks.NewServiceAccountRepository(t)
+		apiKeyService := NewAPIKeyService(keyRepo, mocks.NewUserRepository(t), accountRepo)
+
+		test.expectations(ctx, keyRepo, accountRepo)
+
+		key, err := apiKeyService.Authenticate(ctx, test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.account, key.ServiceAccount != nil)
+		}
+
+		keyRepo.AssertExpectations(t)
+		accountRepo.AssertExpectations(t)
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.APIKeyRepository, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name: "success revoke own key",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, Role: domain.UserRoleOfficer}, nil)
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, UserID: 1}, nil)
+				keyRepo.On("Delete", ctx, uint(5)).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "success revoke by admin",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, Role: domain.UserRoleAdmin}, nil)
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, UserID: 2}, nil)
+				keyRepo.On("Delete", ctx, uint(5)).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed revoke key of other user",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, Role: domain.UserRoleOfficer}, nil)
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, UserID: 2}, nil)
+			},
+			err: domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		keyRepo := mocks.NewAPIKeyRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		apiKeyService := NewAPIKeyService(keyRepo, userRepo, mocks.NewServiceAccountRepository(t))
+
+		test.expectations(ctx, keyRepo, userRepo)
+
+		err := apiKeyService.Revoke(ctx, uint(1), 5)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		keyRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+var (
+	// prefix for wrap errors
+	cacheErrorPrefix = "[service.cache]"
+)
+
+// cache of spaceship reads, spaceships changed by other repositories are invalidated by services
+//
+//go:generate mockery --dir . --name SpaceshipCache --output ./mocks
+type SpaceshipCache interface {
+	// invalidate spaceship of tenant from context
+	Invalidate(context.Context, uint) error
+	// invalidate spaceships of all tenants
+	Purge(context.Context) error
+	Stats() domain.CacheStats
+}
+
+// cache metrics and purge for admins, cache is nil if it is disabled
+type CacheService struct {
+	cache SpaceshipCache
+}
+
+func NewCacheService(cache SpaceshipCache) *CacheService {
+	return &CacheService{cache}
+}
+
+func (s *CacheService) Stats(ctx context.Context) (domain.CacheStats, error) {
+
+	if s.cache == nil {
+		return domain.CacheStats{}, nil
+	}
+
+	return s.cache.Stats(), nil
+}
+
+// purge cache, e.g. after spaceships were imported with cli
+func (s *CacheService) Purge(ctx context.Context) error {
+
+	if s.cache == nil {
+		return nil
+	}
+
+	return s.cache.Purge(ctx)
+}
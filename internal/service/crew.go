@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	crewErrorPrefix = "[service.crew]"
+)
+
+//go:generate mockery --dir . --name CrewRepository --output ./mocks
+type CrewRepository interface {
+	GetAll(context.Context, domain.CrewFilter) ([]*domain.CrewMember, error)
+	GetById(context.Context, uint) (*domain.CrewMember, error)
+	GetAssignments(context.Context, uint) ([]*domain.CrewAssignment, error)
+	Create(context.Context, *domain.CrewMember) error
+	Transfer(context.Context, uint, uint, int64) error
+	Discharge(context.Context, uint, int64) error
+	CountRoster(context.Context, uint) (int, error)
+	CountRosters(context.Context) (map[uint]int, error)
+	// end assignments to spaceship, e.g. when it is deleted
+	Unassign(context.Context, uint, int64) error
+}
+
+// crew roster service, crew members are assigned to spaceships of tenant from context
+type CrewService struct {
+	repository CrewRepository
+}
+
+func NewCrewService(repository CrewRepository) *CrewService {
+	return &CrewService{repository}
+}
+
+func (s *CrewService) GetAll(ctx context.Context, filter domain.CrewFilter) ([]*domain.CrewMember, error) {
+
+	members, err := s.repository.GetAll(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", crewErrorPrefix)
+	}
+
+	return members, nil
+}
+
+// crew member with history of assignments
+func (s *CrewService) GetById(ctx context.Context, id uint) (*domain.CrewMember, []*domain.CrewAssignment, error) {
+
+	member, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignments, err := s.repository.GetAssignments(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return member, assignments, nil
+}
+
+// enlist crew member, spaceship is optional and must have free place in crew
+func (s *CrewService) Enlist(ctx context.Context, member *domain.CrewMember) error {
+
+	member.Name = strings.TrimSpace(member.Name)
+	if member.Name == "" {
+		return domain.ErrNameRequired
+	}
+
+	member.ServiceNumber = strings.TrimSpace(member.ServiceNumber)
+	if member.ServiceNumber == "" {
+		return domain.ErrServiceNumber
+	}
+
+	if member.Rank == domain.CrewRankUndefined {
+		return domain.ErrRankWrong
+	}
+
+	member.EnlistedAt = time.Now().Unix()
+	member.DischargedAt = 0
+
+	return s.repository.Create(ctx, member)
+}
+
+// transfer crew member to another spaceship
+func (s *CrewService) Transfer(ctx context.Context, id uint, spaceshipID uint) (*domain.CrewMember, error) {
+
+	err := s.repository.Transfer(ctx, id, spaceshipID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetById(ctx, id)
+}
+
+// discharge crew member, spaceship assignment is ended
+func (s *CrewService) Discharge(ctx context.Context, id uint) (*domain.CrewMember, error) {
+
+	err := s.repository.Discharge(ctx, id, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetById(ctx, id)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCrewService_Enlist(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		member       *domain.CrewMember
+		expectations func(context.Context, *mocks.CrewRepository)
+		err          error
+	}{
+		{
+			name:   "success enlist crew member",
+			member: &domain.CrewMember{Name: " Firmus Piett ", Rank: domain.CrewRankCaptain, ServiceNumber: "IN-0001", SpaceshipID: 1},
+			expectations: func(ctx context.Context, crewRepo *mocks.CrewRepository) {
+				crewRepo.On("Create", ctx, mock.MatchedBy(func(m *domain.CrewMember) bool {
+					return m.Name == "Firmus Piett" && m.EnlistedAt > 0
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "failed enlist without name",
+			member:       &domain.CrewMember{Rank: domain.CrewRankCaptain, ServiceNumber: "IN-0001"},
+			expectations: func(context.Context, *mocks.CrewRepository) {},
+			err:          domain.ErrNameRequired,
+		},
+		{
+			name:         "failed enlist without service number",
+			member:       &domain.CrewMember{Name: "Firmus Piett", Rank: domain.CrewRankCaptain},
+			expectations: func(context.Context, *mocks.CrewRepository) {},
+			err:          domain.ErrServiceNumber,
+		},
+		{
+			name:         "failed enlist with unknown rank",
+			member:       &domain.CrewMember{Name: "Firmus Piett", ServiceNumber: "IN-0001"},
+			expectations: func(context.Context, *mocks.CrewRepository) {},
+			err:          domain.ErrRankWrong,
+		},
+		{
+			name:   "failed enlist to complete crew",
+			member: &domain.CrewMember{Name: "Firmus Piett", Rank: domain.CrewRankCaptain, ServiceNumber: "IN-0001", SpaceshipID: 1},
+			expectations: func(ctx context.Context, crewRepo *mocks.CrewRepository) {
+				crewRepo.On("Create", ctx, mock.Anything).Return(domain.ErrCrewComplete)
+			},
+			err: domain.ErrCrewComplete,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		crewRepo := mocks.NewCrewRepository(t)
+		crewService := NewCrewService(crewRepo)
+
+		test.expectations(ctx, crewRepo)
+
+		err := crewService.Enlist(ctx, test.member)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/totp"
+	"github.com/pkg/errors"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	// prefix for wrap errors
+	mfaErrorPrefix = "[service.mfa]"
+
+	recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+const (
+	// accepted clock drift in totp steps
+	mfaSkew = 1
+	// recovery codes issued on enrolment
+	recoveryCodeCount = 10
+	// qr code png size in pixels
+	qrCodeSize = 256
+)
+
+type MFAConfig struct {
+	// issuer shown in authenticator app
+	Issuer string
+	// roles which must use second factor
+	RequiredRoles []domain.UserRole
+}
+
+// totp second factor service
+type MFAService struct {
+	users   UserRepository
+	tokens  UserTokenRepository
+	lockout LoginLockout
+	config  MFAConfig
+}
+
+// mfa service builder, lockout is optional
+func NewMFAService(users UserRepository, tokens UserTokenRepository, lockout LoginLockout, config MFAConfig) *MFAService {
+	return &MFAService{users, tokens, lockout, config}
+}
+
+// second factor step required for user after password auth
+func (s *MFAService) Challenge(ctx context.Context, email string) (domain.MFAChallenge, error) {
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return domain.MFAChallengeNone, errors.Wrapf(err, "%s: challenge get user", mfaErrorPrefix)
+	}
+
+	switch {
+	case user.TOTPEnabledAt != 0:
+		return domain.MFAChallengeVerify, nil
+	case s.required(user):
+		return domain.MFAChallengeEnroll, nil
+	default:
+		return domain.MFAChallengeNone, nil
+	}
+}
+
+// start totp enrolment, pending enrolment is replaced by new secret
+func (s *MFAService) Enroll(ctx context.Context, userID uint) (*domain.MFAEnrollment, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: enroll get user", mfaErrorPrefix)
+	}
+
+	if user.TOTPEnabledAt != 0 {
+		return nil, domain.ErrMFAEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(s.config.Issuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: enroll qr code", mfaErrorPrefix)
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPCounter = 0
+	user.UpdatedAt = time.Now().Unix()
+	err = s.users.UpdateTOTP(ctx, user)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: enroll update user", mfaErrorPrefix)
+	}
+
+	return &domain.MFAEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: png,
+	}, nil
+}
+
+// finish enrolment with first code, returns recovery codes which are shown once
+func (s *MFAService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: confirm get user", mfaErrorPrefix)
+	}
+
+	if user.TOTPEnabledAt != 0 {
+		return nil, domain.ErrMFAEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, domain.ErrMFANotEnabled
+	}
+
+	counter, ok := totp.Validate(user.TOTPSecret, code, time.Now(), mfaSkew)
+	if !ok {
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	now := time.Now().Unix()
+	user.TOTPEnabledAt = now
+	user.TOTPCounter = counter
+	user.UpdatedAt = now
+	err = s.users.UpdateTOTP(ctx, user)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: confirm update user", mfaErrorPrefix)
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// check totp or recovery code of user with enabled mfa
+func (s *MFAService) Verify(ctx context.Context, userID uint, code string) error {
+
+	// lockout is shared with password auth, so keys are prefixed
+	lockoutKey := "mfa:" + strconv.FormatUint(uint64(userID), 10)
+	if s.lockout != nil {
+		if after := s.lockout.Check(lockoutKey); after > 0 {
+			return &domain.RetryAfterError{Err: domain.ErrLoginLocked, After: after}
+		}
+	}
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: verify get user", mfaErrorPrefix)
+	}
+
+	if user.TOTPEnabledAt == 0 {
+		return domain.ErrMFANotEnabled
+	}
+
+	err = s.checkCode(ctx, user, code)
+	if errors.Is(err, domain.ErrMFACodeInvalid) && s.lockout != nil {
+		s.lockout.Fail(lockoutKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.lockout != nil {
+		s.lockout.Success(lockoutKey)
+	}
+
+	return nil
+}
+
+// turn off mfa, not allowed for roles which require it
+func (s *MFAService) Disable(ctx context.Context, userID uint, code string) error {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: disable get user", mfaErrorPrefix)
+	}
+
+	if user.TOTPEnabledAt == 0 {
+		return domain.ErrMFANotEnabled
+	}
+	if s.required(user) {
+		return domain.ErrMFARequired
+	}
+
+	err = s.checkCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = 0
+	user.TOTPCounter = 0
+	user.UpdatedAt = now
+	err = s.users.UpdateTOTP(ctx, user)
+	if err != nil {
+		return errors.Wrapf(err, "%s: disable update user", mfaErrorPrefix)
+	}
+
+	err = s.tokens.RevokeAll(ctx, user.ID, domain.UserTokenPurposeRecoveryCode, now)
+	if err != nil {
+		return errors.Wrapf(err, "%s: disable revoke recovery codes", mfaErrorPrefix)
+	}
+
+	return nil
+}
+
+// replace recovery codes of user with enabled mfa
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: recovery codes get user", mfaErrorPrefix)
+	}
+
+	if user.TOTPEnabledAt == 0 {
+		return nil, domain.ErrMFANotEnabled
+	}
+
+	err = s.checkCode(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// role of user requires second factor by policy
+func (s *MFAService) required(user *domain.User) bool {
+	for _, role := range s.config.RequiredRoles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// check totp code or consume recovery code
+func (s *MFAService) checkCode(ctx context.Context, user *domain.User, code string) error {
+
+	code = normalizeRecoveryCode(code)
+
+	if len(code) == totp.Digits {
+		counter, ok := totp.Validate(user.TOTPSecret, code, time.Now(), mfaSkew)
+		// code of already used time step is replay
+		if !ok || counter <= user.TOTPCounter {
+			return domain.ErrMFACodeInvalid
+		}
+
+		user.TOTPCounter = counter
+		user.UpdatedAt = time.Now().Unix()
+		err := s.users.UpdateTOTP(ctx, user)
+		if err != nil {
+			return errors.Wrapf(err, "%s: save totp counter", mfaErrorPrefix)
+		}
+		return nil
+	}
+
+	token, err := s.tokens.GetByHash(ctx, domain.UserTokenPurposeRecoveryCode, hashToken(code))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrMFACodeInvalid
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: get recovery code", mfaErrorPrefix)
+	}
+
+	if token.UserID != user.ID || token.UsedAt != 0 {
+		return domain.ErrMFACodeInvalid
+	}
+
+	err = s.tokens.MarkUsed(ctx, token.ID, time.Now().Unix())
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrMFACodeInvalid
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: mark recovery code used", mfaErrorPrefix)
+	}
+
+	return nil
+}
+
+// revoke old and create new recovery codes, only hashes are stored
+func (s *MFAService) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+
+	now := time.Now().Unix()
+	err := s.tokens.RevokeAll(ctx, userID, domain.UserTokenPurposeRecoveryCode, now)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: revoke recovery codes", mfaErrorPrefix)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: generate recovery code", mfaErrorPrefix)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+
+		err = s.tokens.Create(ctx, &domain.UserToken{
+			UserID:    userID,
+			Purpose:   domain.UserTokenPurposeRecoveryCode,
+			Hash:      hashToken(code),
+			CreatedAt: now,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: save recovery code", mfaErrorPrefix)
+		}
+
+		// dash makes code easier to read
+		codes = append(codes, code[:4]+"-"+code[4:])
+	}
+
+	return codes, nil
+}
+
+// recovery codes are accepted in any case and with or without dash
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+	"github.com/Je33/imperial_fleet/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAService_Challenge(t *testing.T) {
+
+	testCases := []struct {
+		name      string
+		user      *domain.User
+		challenge domain.MFAChallenge
+	}{
+		{
+			name:      "user without mfa",
+			user:      &domain.User{Role: domain.UserRoleUser},
+			challenge: domain.MFAChallengeNone,
+		},
+		{
+			name:      "user with mfa",
+			user:      &domain.User{Role: domain.UserRoleUser, TOTPEnabledAt: 1},
+			challenge: domain.MFAChallengeVerify,
+		},
+		{
+			name:      "admin without mfa must enroll",
+			user:      &domain.User{Role: domain.UserRoleAdmin},
+			challenge: domain.MFAChallengeEnroll,
+		},
+		{
+			name:      "admin with pending enrolment must enroll",
+			user:      &domain.User{Role: domain.UserRoleAdmin, TOTPSecret: "ABC"},
+			challenge: domain.MFAChallengeEnroll,
+		},
+		{
+			name:      "admin with mfa",
+			user:      &domain.User{Role: domain.UserRoleAdmin, TOTPEnabledAt: 1},
+			challenge: domain.MFAChallengeVerify,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		mfaService := NewMFAService(userRepo, mocks.NewUserTokenRepository(t), nil, MFAConfig{
+			RequiredRoles: []domain.UserRole{domain.UserRoleAdmin},
+		})
+
+		userRepo.On("GetByEmail", ctx, "test@test.com").Return(test.user, nil)
+
+		challenge, err := mfaService.Challenge(ctx, "test@test.com")
+		assert.NoError(t, err)
+		assert.Equal(t, test.challenge, challenge)
+	}
+}
+
+func TestMFAService_Verify(t *testing.T) {
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	counter := totp.Counter(time.Now())
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository, *mocks.UserTokenRepository, *mocks.LoginLockout)
+		code         string
+		err          error
+	}{
+		{
+			name: "success totp code",
+			code: code,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Duration(0))
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: 1}, nil)
+				userRepo.On("UpdateTOTP", ctx, mock.MatchedBy(func(user *domain.User) bool {
+					return user.TOTPCounter >= counter
+				})).Return(nil)
+				lockout.On("Success", "mfa:1").Return()
+			},
+			err: nil,
+		},
+		{
+			name: "failed replayed totp code",
+			code: code,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Duration(0))
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: 1, TOTPCounter: counter + 1}, nil)
+				lockout.On("Fail", "mfa:1").Return()
+			},
+			err: domain.ErrMFACodeInvalid,
+		},
+		{
+			name: "success recovery code",
+			code: "ABCD-EFGH",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Duration(0))
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: 1}, nil)
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposeRecoveryCode, hashToken("abcdefgh")).Return(&domain.UserToken{ID: 5, UserID: 1}, nil)
+				tokenRepo.On("MarkUsed", ctx, uint(5), mock.Anything).Return(nil)
+				lockout.On("Success", "mfa:1").Return()
+			},
+			err: nil,
+		},
+		{
+			name: "failed recovery code of other user",
+			code: "abcd-efgh",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Duration(0))
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, TOTPSecret: secret, TOTPEnabledAt: 1}, nil)
+				tokenRepo.On("GetByHash", ctx, domain.UserTokenPurposeRecoveryCode, hashToken("abcdefgh")).Return(&domain.UserToken{ID: 5, UserID: 2}, nil)
+				lockout.On("Fail", "mfa:1").Return()
+			},
+			err: domain.ErrMFACodeInvalid,
+		},
+		{
+			name: "failed mfa is not enabled",
+			code: code,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Duration(0))
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, TOTPSecret: secret}, nil)
+			},
+			err: domain.ErrMFANotEnabled,
+		},
+		{
+			name: "failed locked",
+			code: code,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, tokenRepo *mocks.UserTokenRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", "mfa:1").Return(time.Minute)
+			},
+			err: domain.ErrLoginLocked,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		tokenRepo := mocks.NewUserTokenRepository(t)
+		lockout := mocks.NewLoginLockout(t)
+		mfaService := NewMFAService(userRepo, tokenRepo, lockout, MFAConfig{})
+
+		test.expectations(ctx, userRepo, tokenRepo, lockout)
+
+		err := mfaService.Verify(ctx, uint(1), test.code)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		userRepo.AssertExpectations(t)
+		tokenRepo.AssertExpectations(t)
+		lockout.AssertExpectations(t)
+	}
+}
+
+func TestMFAService_Disable(t *testing.T) {
+
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	mfaService := NewMFAService(userRepo, mocks.NewUserTokenRepository(t), nil, MFAConfig{
+		RequiredRoles: []domain.UserRole{domain.UserRoleOfficer, domain.UserRoleAdmin},
+	})
+
+	userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1, Role: domain.UserRoleOfficer, TOTPSecret: "ABC", TOTPEnabledAt: 1}, nil)
+
+	err := mfaService.Disable(ctx, uint(1), "123456")
+	assert.ErrorIs(t, err, domain.ErrMFARequired)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	missionErrorPrefix = "[service.mission]"
+)
+
+const (
+	// length of objective of mission
+	missionObjectiveMaxLen = 1024
+	// count of spaceships assigned to mission at most
+	MissionMaxSpaceships = 100
+)
+
+//go:generate mockery --dir . --name MissionRepository --output ./mocks
+type MissionRepository interface {
+	GetAll(context.Context, domain.MissionFilter) ([]*domain.Mission, error)
+	GetById(context.Context, uint) (*domain.Mission, error)
+	// mission locked until transaction of Atomic ends
+	GetForUpdate(context.Context, uint) (*domain.Mission, error)
+	Create(context.Context, *domain.Mission) error
+	Update(context.Context, *domain.Mission) error
+	SetStatus(context.Context, uint, domain.MissionStatus, int64) error
+	// number of active missions of spaceship
+	CountActive(context.Context, uint) (int, error)
+	// unassign spaceship from its missions, e.g. when it is deleted
+	DeleteBySpaceship(context.Context, uint) error
+}
+
+// missions of spaceships of tenant from context, spaceship is booked by one open mission at a time,
+// capabilities of spaceships are checked while they are locked for booking in one transaction
+type MissionService struct {
+	repository MissionRepository
+	spaceships SpaceshipRepository
+	classes    ShipClassRepository
+	crew       CrewRepository
+}
+
+// mission service builder, class catalog is optional and rates firepower of spaceships if set
+func NewMissionService(repository MissionRepository, spaceships SpaceshipRepository, classes ShipClassRepository, crew CrewRepository) *MissionService {
+	return &MissionService{repository, spaceships, classes, crew}
+}
+
+func (s *MissionService) GetAll(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+
+	missions, err := s.repository.GetAll(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", missionErrorPrefix)
+	}
+
+	return missions, nil
+}
+
+func (s *MissionService) GetById(ctx context.Context, id uint) (*domain.Mission, error) {
+	return s.repository.GetById(ctx, id)
+}
+
+// plan mission, assigned spaceships must meet its requirements and be free in its window
+func (s *MissionService) Plan(ctx context.Context, mission *domain.Mission) error {
+
+	now := time.Now().Unix()
+
+	err := s.validate(mission, now)
+	if err != nil {
+		return err
+	}
+
+	mission.Status = domain.MissionStatusPlanned
+	mission.CreatedAt = now
+	mission.StartedAt = 0
+	mission.ClosedAt = 0
+
+	return s.spaceships.Atomic(ctx, func(ctx context.Context) error {
+
+		err := s.lockSpaceships(ctx, mission.SpaceshipIDs, mission.Requirements)
+		if err != nil {
+			return err
+		}
+
+		return s.repository.Create(ctx, mission)
+	})
+}
+
+// reschedule or reassign mission while it is planned
+func (s *MissionService) Update(ctx context.Context, mission *domain.Mission) (*domain.Mission, error) {
+
+	err := s.validate(mission, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.spaceships.Atomic(ctx, func(ctx context.Context) error {
+
+		current, err := s.repository.GetForUpdate(ctx, mission.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.MissionStatusPlanned {
+			return domain.ErrMissionClosed
+		}
+
+		err = s.lockSpaceships(ctx, mission.SpaceshipIDs, mission.Requirements)
+		if err != nil {
+			return err
+		}
+
+		return s.repository.Update(ctx, mission)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetById(ctx, mission.ID)
+}
+
+// move mission to next status, spaceships are released when it is completed or aborted
+func (s *MissionService) Move(ctx context.Context, id uint, status domain.MissionStatus) (*domain.Mission, error) {
+
+	if status == domain.MissionStatusUndefined {
+		return nil, domain.ErrMissionStatus
+	}
+
+	err := s.spaceships.Atomic(ctx, func(ctx context.Context) error {
+
+		mission, err := s.repository.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// spaceships of activated mission must be operational and still meet its requirements
+		if status == domain.MissionStatusActive && mission.Status.CanMoveTo(status) {
+			err = s.lockSpaceships(ctx, mission.SpaceshipIDs, mission.Requirements)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.repository.SetStatus(ctx, id, status, time.Now().Unix())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetById(ctx, id)
+}
+
+// lock spaceships in order of ids, all of them must exist, be operational and meet requirements,
+// so roster and armament don't change until mission is saved
+func (s *MissionService) lockSpaceships(ctx context.Context, ids []uint, req domain.MissionRequirements) error {
+
+	ids = append([]uint{}, ids...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	classes := map[uint]*domain.ShipClass{}
+	for _, id := range ids {
+		spaceship, err := s.spaceships.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if spaceship.Status != domain.SpaceshipStatusOperational {
+			return errors.Wrapf(domain.ErrSpaceshipDamaged, "%s: %s is %s", missionErrorPrefix, spaceship.Name, spaceship.Status)
+		}
+
+		// crew members assigned to spaceship count, declared crew doesn't
+		if req.MinCrew > 0 {
+			roster, err := s.crew.CountRoster(ctx, spaceship.ID)
+			if err != nil {
+				return errors.Wrapf(err, "%s: count roster", missionErrorPrefix)
+			}
+			if uint(roster) < req.MinCrew {
+				return errors.Wrapf(domain.ErrMissionCapability, "%s: %s has crew %d of %d required", missionErrorPrefix,
+					spaceship.Name, roster, req.MinCrew)
+			}
+		}
+
+		// firepower of armament is rated by class, spaceship out of catalog has none
+		if req.MinFirepower > 0 {
+			class, err := s.class(ctx, classes, spaceship.ClassID)
+			if err != nil {
+				return err
+			}
+			firepower := 0.0
+			if class != nil {
+				firepower = class.Firepower(spaceship.Armament)
+			}
+			if firepower < req.MinFirepower {
+				return errors.Wrapf(domain.ErrMissionCapability, "%s: %s has firepower %g of %g required", missionErrorPrefix,
+					spaceship.Name, firepower, req.MinFirepower)
+			}
+		}
+	}
+
+	return nil
+}
+
+// class of catalog by id, loaded classes are kept in map, nil for class out of catalog
+func (s *MissionService) class(ctx context.Context, classes map[uint]*domain.ShipClass, id uint) (*domain.ShipClass, error) {
+
+	if s.classes == nil || id == 0 {
+		return nil, nil
+	}
+	if class, ok := classes[id]; ok {
+		return class, nil
+	}
+
+	class, err := s.classes.GetById(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		class, err = nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get class", missionErrorPrefix)
+	}
+	classes[id] = class
+
+	return class, nil
+}
+
+func (s *MissionService) validate(mission *domain.Mission, now int64) error {
+
+	mission.Objective = strings.TrimSpace(mission.Objective)
+	if mission.Objective == "" {
+		return domain.ErrObjectiveWrong
+	}
+	if utf8.RuneCountInString(mission.Objective) > missionObjectiveMaxLen {
+		return errors.Wrapf(domain.ErrObjectiveWrong, "%s: objective is too long", missionErrorPrefix)
+	}
+
+	if mission.StartsAt == 0 || mission.EndsAt <= mission.StartsAt {
+		return errors.Wrapf(domain.ErrMissionWindow, "%s: window must end after it starts", missionErrorPrefix)
+	}
+	if mission.EndsAt <= now {
+		return errors.Wrapf(domain.ErrMissionWindow, "%s: window has already ended", missionErrorPrefix)
+	}
+
+	req := mission.Requirements
+	if math.IsNaN(req.MinFirepower) || math.IsInf(req.MinFirepower, 0) || req.MinFirepower < 0 {
+		return domain.ErrFirepowerWrong
+	}
+
+	// the same spaceship listed twice is assigned once
+	ids := make([]uint, 0, len(mission.SpaceshipIDs))
+	seen := map[uint]bool{}
+	for _, id := range mission.SpaceshipIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	mission.SpaceshipIDs = ids
+	if len(ids) == 0 {
+		return domain.ErrMissionSpaceships
+	}
+	if len(ids) > MissionMaxSpaceships {
+		return errors.Wrapf(domain.ErrMissionSpaceships, "%s: at most %d spaceships", missionErrorPrefix, MissionMaxSpaceships)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissionService_Plan(t *testing.T) {
+
+	now := time.Now().Unix()
+
+	class := &domain.ShipClass{ID: 2, Name: "Star Destroyer", Armament: []domain.ShipClassArmament{{Title: "Turbolaser", Max: 60, Damage: 10}}}
+	operational := &domain.Spaceship{ID: 1, Name: "Devastator", ClassID: 2, Status: domain.SpaceshipStatusOperational,
+		Armament: []domain.SpaceshipArmament{{Title: "Turbolaser", Qty: 60}}}
+
+	testCases := []struct {
+		name      string
+		mission   *domain.Mission
+		spaceship *domain.Spaceship
+		roster    int
+		err       error
+	}{
+		{
+			name: "success with requirements",
+			mission: &domain.Mission{Objective: " Hoth ", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1, 1},
+				Requirements: domain.MissionRequirements{MinFirepower: 600, MinCrew: 100}},
+			spaceship: operational,
+			roster:    100,
+		},
+		{
+			name:      "failed damaged spaceship",
+			mission:   &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1}},
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusDamaged},
+			err:       domain.ErrSpaceshipDamaged,
+		},
+		{
+			name: "failed crew members assigned below min crew",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1},
+				Requirements: domain.MissionRequirements{MinCrew: 100}},
+			spaceship: operational,
+			roster:    99,
+			err:       domain.ErrMissionCapability,
+		},
+		{
+			name: "failed firepower rated by class",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1},
+				Requirements: domain.MissionRequirements{MinFirepower: 601}},
+			spaceship: operational,
+			err:       domain.ErrMissionCapability,
+		},
+		{
+			name: "failed firepower of spaceship out of catalog",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1},
+				Requirements: domain.MissionRequirements{MinFirepower: 1}},
+			spaceship: &domain.Spaceship{ID: 1, Name: "Slave I", Status: domain.SpaceshipStatusOperational,
+				Armament: []domain.SpaceshipArmament{{Title: "Turbolaser", Qty: 60}}},
+			err: domain.ErrMissionCapability,
+		},
+		{
+			name:    "failed empty objective",
+			mission: &domain.Mission{Objective: " ", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1}},
+			err:     domain.ErrObjectiveWrong,
+		},
+		{
+			name:    "failed too long objective",
+			mission: &domain.Mission{Objective: strings.Repeat("a", 1025), StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1}},
+			err:     domain.ErrObjectiveWrong,
+		},
+		{
+			name:    "failed window ends before it starts",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now + 3600, EndsAt: now, SpaceshipIDs: []uint{1}},
+			err:     domain.ErrMissionWindow,
+		},
+		{
+			name:    "failed window in the past",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now - 7200, EndsAt: now - 3600, SpaceshipIDs: []uint{1}},
+			err:     domain.ErrMissionWindow,
+		},
+		{
+			name: "failed negative firepower",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1},
+				Requirements: domain.MissionRequirements{MinFirepower: -1}},
+			err: domain.ErrFirepowerWrong,
+		},
+		{
+			name:    "failed without spaceships",
+			mission: &domain.Mission{Objective: "Hoth", StartsAt: now, EndsAt: now + 3600},
+			err:     domain.ErrMissionSpaceships,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		missionRepo := mocks.NewMissionRepository(t)
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		classRepo := mocks.NewShipClassRepository(t)
+		crewRepo := mocks.NewCrewRepository(t)
+		missionService := NewMissionService(missionRepo, spaceshipRepo, classRepo, crewRepo)
+
+		if test.spaceship != nil {
+			spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+			spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(test.spaceship, nil)
+			crewRepo.On("CountRoster", ctx, uint(1)).Return(test.roster, nil).Maybe()
+			classRepo.On("GetById", ctx, uint(2)).Return(class, nil).Maybe()
+		}
+		if test.err == nil {
+			missionRepo.On("Create", ctx, test.mission).Return(nil)
+		}
+
+		err := missionService.Plan(ctx, test.mission)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "Hoth", test.mission.Objective)
+		assert.Equal(t, []uint{1}, test.mission.SpaceshipIDs)
+		assert.Equal(t, domain.MissionStatusPlanned, test.mission.Status)
+		assert.NotZero(t, test.mission.CreatedAt)
+	}
+}
+
+func TestMissionService_Move(t *testing.T) {
+
+	ctx := context.Background()
+
+	missionRepo := mocks.NewMissionRepository(t)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	crewRepo := mocks.NewCrewRepository(t)
+	missionService := NewMissionService(missionRepo, spaceshipRepo, nil, crewRepo)
+
+	_, err := missionService.Move(ctx, 1, domain.MissionStatusFromString("launched"))
+	assert.ErrorIs(t, err, domain.ErrMissionStatus)
+
+	// requirements are checked again on activation
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+	missionRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Mission{ID: 1, Status: domain.MissionStatusPlanned,
+		SpaceshipIDs: []uint{1}, Requirements: domain.MissionRequirements{MinCrew: 2}}, nil)
+	spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Status: domain.SpaceshipStatusOperational}, nil)
+	crewRepo.On("CountRoster", ctx, uint(1)).Return(1, nil).Once()
+	_, err = missionService.Move(ctx, 1, domain.MissionStatusActive)
+	assert.ErrorIs(t, err, domain.ErrMissionCapability)
+
+	crewRepo.On("CountRoster", ctx, uint(1)).Return(2, nil).Once()
+	missionRepo.On("SetStatus", ctx, uint(1), domain.MissionStatusActive, mock.AnythingOfType("int64")).Return(nil)
+	missionRepo.On("GetById", ctx, uint(1)).Return(&domain.Mission{ID: 1, Status: domain.MissionStatusActive}, nil)
+	mission, err := missionService.Move(ctx, 1, domain.MissionStatusActive)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MissionStatusActive, mission.Status)
+}
+
+func TestMissionService_Update(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	missionRepo := mocks.NewMissionRepository(t)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	missionService := NewMissionService(missionRepo, spaceshipRepo, nil, nil)
+
+	// mission can't be changed once it is active
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+	missionRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Mission{ID: 1, Status: domain.MissionStatusActive}, nil)
+	_, err := missionService.Update(ctx, &domain.Mission{ID: 1, Objective: "Hoth", StartsAt: now, EndsAt: now + 3600, SpaceshipIDs: []uint{1}})
+	assert.ErrorIs(t, err, domain.ErrMissionClosed)
+}
@@ -0,0 +1,213 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CrewRepository is an autogenerated mock type for the CrewRepository type
+type CrewRepository struct {
+	mock.Mock
+}
+
+// CountRoster provides a mock function with given fields: _a0, _a1
+func (_m *CrewRepository) CountRoster(_a0 context.Context, _a1 uint) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountRosters provides a mock function with given fields: _a0
+func (_m *CrewRepository) CountRosters(_a0 context.Context) (map[uint]int, error) {
+	ret := _m.Called(_a0)
+
+	var r0 map[uint]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[uint]int, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[uint]int); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *CrewRepository) Create(_a0 context.Context, _a1 *domain.CrewMember) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CrewMember) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Discharge provides a mock function with given fields: _a0, _a1, _a2
+func (_m *CrewRepository) Discharge(_a0 context.Context, _a1 uint, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *CrewRepository) GetAll(_a0 context.Context, _a1 domain.CrewFilter) ([]*domain.CrewMember, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.CrewMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CrewFilter) ([]*domain.CrewMember, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CrewFilter) []*domain.CrewMember); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CrewFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAssignments provides a mock function with given fields: _a0, _a1
+func (_m *CrewRepository) GetAssignments(_a0 context.Context, _a1 uint) ([]*domain.CrewAssignment, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.CrewAssignment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.CrewAssignment, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.CrewAssignment); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.CrewAssignment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *CrewRepository) GetById(_a0 context.Context, _a1 uint) (*domain.CrewMember, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.CrewMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.CrewMember, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.CrewMember); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transfer provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *CrewRepository) Transfer(_a0 context.Context, _a1 uint, _a2 uint, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unassign provides a mock function with given fields: _a0, _a1, _a2
+func (_m *CrewRepository) Unassign(_a0 context.Context, _a1 uint, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCrewRepository creates a new instance of CrewRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCrewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CrewRepository {
+	mock := &CrewRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
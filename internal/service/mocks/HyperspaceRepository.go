@@ -0,0 +1,147 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HyperspaceRepository is an autogenerated mock type for the HyperspaceRepository type
+type HyperspaceRepository struct {
+	mock.Mock
+}
+
+// CreateClosure provides a mock function with given fields: _a0, _a1
+func (_m *HyperspaceRepository) CreateClosure(_a0 context.Context, _a1 *domain.HyperlaneClosure) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.HyperlaneClosure) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteClosure provides a mock function with given fields: _a0, _a1
+func (_m *HyperspaceRepository) DeleteClosure(_a0 context.Context, _a1 uint) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetClosures provides a mock function with given fields: _a0, _a1
+func (_m *HyperspaceRepository) GetClosures(_a0 context.Context, _a1 int64) ([]*domain.HyperlaneClosure, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.HyperlaneClosure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*domain.HyperlaneClosure, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*domain.HyperlaneClosure); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.HyperlaneClosure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGalaxy provides a mock function with given fields: _a0
+func (_m *HyperspaceRepository) GetGalaxy(_a0 context.Context) (*domain.Galaxy, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.Galaxy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.Galaxy, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.Galaxy); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Galaxy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetVersion provides a mock function with given fields: _a0
+func (_m *HyperspaceRepository) GetVersion(_a0 context.Context) (int64, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveGalaxy provides a mock function with given fields: _a0, _a1
+func (_m *HyperspaceRepository) SaveGalaxy(_a0 context.Context, _a1 *domain.Galaxy) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Galaxy) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewHyperspaceRepository creates a new instance of HyperspaceRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHyperspaceRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HyperspaceRepository {
+	mock := &HyperspaceRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
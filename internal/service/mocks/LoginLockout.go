@@ -0,0 +1,52 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LoginLockout is an autogenerated mock type for the LoginLockout type
+type LoginLockout struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields: _a0
+func (_m *LoginLockout) Check(_a0 string) time.Duration {
+	ret := _m.Called(_a0)
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func(string) time.Duration); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// Fail provides a mock function with given fields: _a0
+func (_m *LoginLockout) Fail(_a0 string) {
+	_m.Called(_a0)
+}
+
+// Success provides a mock function with given fields: _a0
+func (_m *LoginLockout) Success(_a0 string) {
+	_m.Called(_a0)
+}
+
+// NewLoginLockout creates a new instance of LoginLockout. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginLockout(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginLockout {
+	mock := &LoginLockout{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,187 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MissionRepository is an autogenerated mock type for the MissionRepository type
+type MissionRepository struct {
+	mock.Mock
+}
+
+// CountActive provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) CountActive(_a0 context.Context, _a1 uint) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) Create(_a0 context.Context, _a1 *domain.Mission) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Mission) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteBySpaceship provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) DeleteBySpaceship(_a0 context.Context, _a1 uint) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) GetAll(_a0 context.Context, _a1 domain.MissionFilter) ([]*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MissionFilter) ([]*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MissionFilter) []*domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MissionFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) GetById(_a0 context.Context, _a1 uint) (*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetForUpdate provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) GetForUpdate(_a0 context.Context, _a1 uint) (*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetStatus provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *MissionRepository) SetStatus(_a0 context.Context, _a1 uint, _a2 domain.MissionStatus, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.MissionStatus, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *MissionRepository) Update(_a0 context.Context, _a1 *domain.Mission) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Mission) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMissionRepository creates a new instance of MissionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMissionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MissionRepository {
+	mock := &MissionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
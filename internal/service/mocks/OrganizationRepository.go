@@ -0,0 +1,227 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrganizationRepository is an autogenerated mock type for the OrganizationRepository type
+type OrganizationRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) Create(_a0 context.Context, _a1 *domain.Organization) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Organization) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMembership provides a mock function with given fields: _a0, _a1, _a2
+func (_m *OrganizationRepository) DeleteMembership(_a0 context.Context, _a1 uint, _a2 uint) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: _a0
+func (_m *OrganizationRepository) GetAll(_a0 context.Context) ([]*domain.Organization, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*domain.Organization, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.Organization); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) GetById(_a0 context.Context, _a1 uint) (*domain.Organization, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Organization, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Organization); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByName provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) GetByName(_a0 context.Context, _a1 string) (*domain.Organization, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Organization, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Organization); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMembers provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) GetMembers(_a0 context.Context, _a1 uint) ([]*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMembership provides a mock function with given fields: _a0, _a1, _a2
+func (_m *OrganizationRepository) GetMembership(_a0 context.Context, _a1 uint, _a2 uint) (*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (*domain.Membership, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *domain.Membership); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMemberships provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) GetMemberships(_a0 context.Context, _a1 uint) ([]*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveMembership provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationRepository) SaveMembership(_a0 context.Context, _a1 *domain.Membership) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Membership) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOrganizationRepository creates a new instance of OrganizationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrganizationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrganizationRepository {
+	mock := &OrganizationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReadinessEngine is an autogenerated mock type for the ReadinessEngine type
+type ReadinessEngine struct {
+	mock.Mock
+}
+
+// Fleet provides a mock function with given fields: _a0
+func (_m *ReadinessEngine) Fleet(_a0 []domain.ReadinessInput) *domain.FleetReadiness {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.FleetReadiness
+	if rf, ok := ret.Get(0).(func([]domain.ReadinessInput) *domain.FleetReadiness); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.FleetReadiness)
+		}
+	}
+
+	return r0
+}
+
+// Score provides a mock function with given fields: _a0
+func (_m *ReadinessEngine) Score(_a0 domain.ReadinessInput) *domain.Readiness {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.Readiness
+	if rf, ok := ret.Get(0).(func(domain.ReadinessInput) *domain.Readiness); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Readiness)
+		}
+	}
+
+	return r0
+}
+
+// NewReadinessEngine creates a new instance of ReadinessEngine. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReadinessEngine(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReadinessEngine {
+	mock := &ReadinessEngine{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,147 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReportRepository is an autogenerated mock type for the ReportRepository type
+type ReportRepository struct {
+	mock.Mock
+}
+
+// Armament provides a mock function with given fields: _a0, _a1
+func (_m *ReportRepository) Armament(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.ArmamentReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ArmamentReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.ArmamentReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.ArmamentReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ArmamentReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fleet provides a mock function with given fields: _a0, _a1
+func (_m *ReportRepository) Fleet(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.FleetReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.FleetReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.FleetReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.FleetReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.FleetReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSnapshots provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ReportRepository) GetSnapshots(_a0 context.Context, _a1 int64, _a2 int64) ([]*domain.FleetSnapshot, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.FleetSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]*domain.FleetSnapshot, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*domain.FleetSnapshot); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.FleetSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Readiness provides a mock function with given fields: _a0, _a1
+func (_m *ReportRepository) Readiness(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.ReadinessReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ReadinessReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.ReadinessReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.ReadinessReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ReadinessReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSnapshot provides a mock function with given fields: _a0, _a1
+func (_m *ReportRepository) SaveSnapshot(_a0 context.Context, _a1 *domain.FleetSnapshot) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.FleetSnapshot) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewReportRepository creates a new instance of ReportRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReportRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReportRepository {
+	mock := &ReportRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
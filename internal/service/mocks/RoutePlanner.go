@@ -0,0 +1,161 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RoutePlanner is an autogenerated mock type for the RoutePlanner type
+type RoutePlanner struct {
+	mock.Mock
+}
+
+// Galaxy provides a mock function with given fields:
+func (_m *RoutePlanner) Galaxy() *domain.Galaxy {
+	ret := _m.Called()
+
+	var r0 *domain.Galaxy
+	if rf, ok := ret.Get(0).(func() *domain.Galaxy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Galaxy)
+		}
+	}
+
+	return r0
+}
+
+// Lane provides a mock function with given fields: _a0, _a1
+func (_m *RoutePlanner) Lane(_a0 string, _a1 string) (*domain.Hyperlane, bool) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Hyperlane
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string, string) (*domain.Hyperlane, bool)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *domain.Hyperlane); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Hyperlane)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// Load provides a mock function with given fields: _a0
+func (_m *RoutePlanner) Load(_a0 *domain.Galaxy) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.Galaxy) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Parse provides a mock function with given fields: _a0
+func (_m *RoutePlanner) Parse(_a0 []byte) (*domain.Galaxy, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.Galaxy
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (*domain.Galaxy, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) *domain.Galaxy); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Galaxy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Plan provides a mock function with given fields: _a0
+func (_m *RoutePlanner) Plan(_a0 domain.RouteQuery) (*domain.Route, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.Route
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.RouteQuery) (*domain.Route, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(domain.RouteQuery) *domain.Route); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Route)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.RouteQuery) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// System provides a mock function with given fields: _a0
+func (_m *RoutePlanner) System(_a0 string) (*domain.StarSystem, bool) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.StarSystem
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (*domain.StarSystem, bool)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.StarSystem); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.StarSystem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// NewRoutePlanner creates a new instance of RoutePlanner. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRoutePlanner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RoutePlanner {
+	mock := &RoutePlanner{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,109 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceAccountRepository is an autogenerated mock type for the ServiceAccountRepository type
+type ServiceAccountRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *ServiceAccountRepository) Create(_a0 context.Context, _a1 *domain.ServiceAccount) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ServiceAccount) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *ServiceAccountRepository) GetById(_a0 context.Context, _a1 uint) (*domain.ServiceAccount, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.ServiceAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.ServiceAccount, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.ServiceAccount); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ServiceAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByOrg provides a mock function with given fields: _a0, _a1
+func (_m *ServiceAccountRepository) GetByOrg(_a0 context.Context, _a1 uint) ([]*domain.ServiceAccount, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ServiceAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.ServiceAccount, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.ServiceAccount); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ServiceAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ServiceAccountRepository) Revoke(_a0 context.Context, _a1 uint, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewServiceAccountRepository creates a new instance of ServiceAccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceAccountRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceAccountRepository {
+	mock := &ServiceAccountRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,71 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SpaceshipCache is an autogenerated mock type for the SpaceshipCache type
+type SpaceshipCache struct {
+	mock.Mock
+}
+
+// Invalidate provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipCache) Invalidate(_a0 context.Context, _a1 uint) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Purge provides a mock function with given fields: _a0
+func (_m *SpaceshipCache) Purge(_a0 context.Context) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stats provides a mock function with given fields:
+func (_m *SpaceshipCache) Stats() domain.CacheStats {
+	ret := _m.Called()
+
+	var r0 domain.CacheStats
+	if rf, ok := ret.Get(0).(func() domain.CacheStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(domain.CacheStats)
+	}
+
+	return r0
+}
+
+// NewSpaceshipCache creates a new instance of SpaceshipCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSpaceshipCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SpaceshipCache {
+	mock := &SpaceshipCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,101 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SpaceshipIndex is an autogenerated mock type for the SpaceshipIndex type
+type SpaceshipIndex struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipIndex) Delete(_a0 uint, _a1 uint) {
+	_m.Called(_a0, _a1)
+}
+
+// Near provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipIndex) Near(_a0 uint, _a1 domain.ProximityQuery) ([]*domain.ProximityHit, int) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ProximityHit
+	var r1 int
+	if rf, ok := ret.Get(0).(func(uint, domain.ProximityQuery) ([]*domain.ProximityHit, int)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(uint, domain.ProximityQuery) []*domain.ProximityHit); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProximityHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, domain.ProximityQuery) int); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	return r0, r1
+}
+
+// Put provides a mock function with given fields: _a0
+func (_m *SpaceshipIndex) Put(_a0 *domain.Spaceship) {
+	_m.Called(_a0)
+}
+
+// Replace provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipIndex) Replace(_a0 uint, _a1 []*domain.Spaceship) {
+	_m.Called(_a0, _a1)
+}
+
+// Search provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipIndex) Search(_a0 uint, _a1 string, _a2 int) ([]*domain.SearchHit, int, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.SearchHit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint, string, int) ([]*domain.SearchHit, int, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, int) []*domain.SearchHit); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.SearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, int) int); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(uint, string, int) error); ok {
+		r2 = rf(_a0, _a1, _a2)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewSpaceshipIndex creates a new instance of SpaceshipIndex. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSpaceshipIndex(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SpaceshipIndex {
+	mock := &SpaceshipIndex{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
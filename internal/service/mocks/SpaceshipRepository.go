@@ -14,6 +14,72 @@ type SpaceshipRepository struct {
 	mock.Mock
 }
 
+// AddValuation provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) AddValuation(_a0 context.Context, _a1 *domain.Valuation) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Valuation) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Appraise provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) Appraise(_a0 context.Context, _a1 *domain.Valuation) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Valuation) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Atomic provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) Atomic(_a0 context.Context, _a1 func(context.Context) error) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) Count(_a0 context.Context, _a1 domain.SpaceshipFilter) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SpaceshipFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: _a0, _a1
 func (_m *SpaceshipRepository) Create(_a0 context.Context, _a1 *domain.Spaceship) error {
 	ret := _m.Called(_a0, _a1)
@@ -42,8 +108,34 @@ func (_m *SpaceshipRepository) Delete(_a0 context.Context, _a1 *domain.Spaceship
 	return r0
 }
 
-// GetAll provides a mock function with given fields: _a0
-func (_m *SpaceshipRepository) GetAll(_a0 context.Context) ([]*domain.Spaceship, error) {
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) GetAll(_a0 context.Context, _a1 domain.SpaceshipFilter) ([]*domain.Spaceship, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Spaceship
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) ([]*domain.Spaceship, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) []*domain.Spaceship); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Spaceship)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SpaceshipFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllFull provides a mock function with given fields: _a0
+func (_m *SpaceshipRepository) GetAllFull(_a0 context.Context) ([]*domain.Spaceship, error) {
 	ret := _m.Called(_a0)
 
 	var r0 []*domain.Spaceship
@@ -68,6 +160,32 @@ func (_m *SpaceshipRepository) GetAll(_a0 context.Context) ([]*domain.Spaceship,
 	return r0, r1
 }
 
+// GetArmament provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) GetArmament(_a0 context.Context, _a1 []uint) (map[uint][]domain.SpaceshipArmament, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 map[uint][]domain.SpaceshipArmament
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) (map[uint][]domain.SpaceshipArmament, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) map[uint][]domain.SpaceshipArmament); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint][]domain.SpaceshipArmament)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetById provides a mock function with given fields: _a0, _a1
 func (_m *SpaceshipRepository) GetById(_a0 context.Context, _a1 uint) (*domain.Spaceship, error) {
 	ret := _m.Called(_a0, _a1)
@@ -94,6 +212,112 @@ func (_m *SpaceshipRepository) GetById(_a0 context.Context, _a1 uint) (*domain.S
 	return r0, r1
 }
 
+// GetForUpdate provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) GetForUpdate(_a0 context.Context, _a1 uint) (*domain.Spaceship, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Spaceship
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Spaceship, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Spaceship); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Spaceship)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPositions provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipRepository) GetPositions(_a0 context.Context, _a1 uint, _a2 int) ([]*domain.Position, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) ([]*domain.Position, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) []*domain.Position); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Position)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetValuations provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) GetValuations(_a0 context.Context, _a1 uint) ([]*domain.Valuation, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Valuation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.Valuation, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.Valuation); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Valuation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportPosition provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipRepository) ReportPosition(_a0 context.Context, _a1 *domain.Position) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Position) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetStatus provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipRepository) SetStatus(_a0 context.Context, _a1 uint, _a2 domain.SpaceshipStatus) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.SpaceshipStatus) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: _a0, _a1
 func (_m *SpaceshipRepository) Update(_a0 context.Context, _a1 *domain.Spaceship) error {
 	ret := _m.Called(_a0, _a1)
@@ -108,6 +332,20 @@ func (_m *SpaceshipRepository) Update(_a0 context.Context, _a1 *domain.Spaceship
 	return r0
 }
 
+// UpdateImage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipRepository) UpdateImage(_a0 context.Context, _a1 uint, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewSpaceshipRepository creates a new instance of SpaceshipRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewSpaceshipRepository(t interface {
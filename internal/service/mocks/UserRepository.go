@@ -40,6 +40,32 @@ func (_m *UserRepository) Create(_a0 context.Context, _a1 *domain.User) (*domain
 	return r0, r1
 }
 
+// GetAll provides a mock function with given fields: _a0
+func (_m *UserRepository) GetAll(_a0 context.Context) ([]*domain.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*domain.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByEmail provides a mock function with given fields: _a0, _a1
 func (_m *UserRepository) GetByEmail(_a0 context.Context, _a1 string) (*domain.User, error) {
 	ret := _m.Called(_a0, _a1)
@@ -66,6 +92,72 @@ func (_m *UserRepository) GetByEmail(_a0 context.Context, _a1 string) (*domain.U
 	return r0, r1
 }
 
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) GetById(_a0 context.Context, _a1 uint) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Update(_a0 context.Context, _a1 *domain.User) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.User) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateTOTP provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) UpdateTOTP(_a0 context.Context, _a1 *domain.User) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
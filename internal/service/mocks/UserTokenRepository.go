@@ -0,0 +1,97 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserTokenRepository is an autogenerated mock type for the UserTokenRepository type
+type UserTokenRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *UserTokenRepository) Create(_a0 context.Context, _a1 *domain.UserToken) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserToken) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByHash provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserTokenRepository) GetByHash(_a0 context.Context, _a1 domain.UserTokenPurpose, _a2 string) (*domain.UserToken, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.UserToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserTokenPurpose, string) (*domain.UserToken, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserTokenPurpose, string) *domain.UserToken); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserTokenPurpose, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkUsed provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserTokenRepository) MarkUsed(_a0 context.Context, _a1 uint, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeAll provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *UserTokenRepository) RevokeAll(_a0 context.Context, _a1 uint, _a2 domain.UserTokenPurpose, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.UserTokenPurpose, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserTokenRepository creates a new instance of UserTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserTokenRepository {
+	mock := &UserTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,187 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkOrderRepository is an autogenerated mock type for the WorkOrderRepository type
+type WorkOrderRepository struct {
+	mock.Mock
+}
+
+// CountOpen provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) CountOpen(_a0 context.Context, _a1 uint) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) Create(_a0 context.Context, _a1 *domain.WorkOrder) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteBySpaceship provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) DeleteBySpaceship(_a0 context.Context, _a1 uint) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) GetAll(_a0 context.Context, _a1 domain.WorkOrderFilter) ([]*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WorkOrderFilter) ([]*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WorkOrderFilter) []*domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.WorkOrderFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) GetById(_a0 context.Context, _a1 uint) (*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetForUpdate provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) GetForUpdate(_a0 context.Context, _a1 uint) (*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetStatus provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *WorkOrderRepository) SetStatus(_a0 context.Context, _a1 uint, _a2 domain.WorkOrderStatus, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.WorkOrderStatus, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderRepository) Update(_a0 context.Context, _a1 *domain.WorkOrder) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWorkOrderRepository creates a new instance of WorkOrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWorkOrderRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WorkOrderRepository {
+	mock := &WorkOrderRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	organizationErrorPrefix = "[service.organization]"
+)
+
+//go:generate mockery --dir . --name OrganizationRepository --output ./mocks
+type OrganizationRepository interface {
+	GetAll(context.Context) ([]*domain.Organization, error)
+	GetById(context.Context, uint) (*domain.Organization, error)
+	GetByName(context.Context, string) (*domain.Organization, error)
+	Create(context.Context, *domain.Organization) error
+	GetMembership(context.Context, uint, uint) (*domain.Membership, error)
+	GetMemberships(context.Context, uint) ([]*domain.Membership, error)
+	GetMembers(context.Context, uint) ([]*domain.Membership, error)
+	SaveMembership(context.Context, *domain.Membership) error
+	DeleteMembership(context.Context, uint, uint) error
+}
+
+// organization service, manages tenants and their members
+type OrganizationService struct {
+	orgs  OrganizationRepository
+	users UserRepository
+}
+
+func NewOrganizationService(orgs OrganizationRepository, users UserRepository) *OrganizationService {
+	return &OrganizationService{orgs, users}
+}
+
+// create organization, user becomes its admin
+func (s *OrganizationService) Create(ctx context.Context, userID uint, name string) (*domain.Organization, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: create get user", organizationErrorPrefix)
+	}
+
+	org, err := s.CreateOrganization(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.orgs.SaveMembership(ctx, &domain.Membership{
+		OrgID:     org.ID,
+		UserID:    user.ID,
+		Role:      domain.OrgRoleAdmin,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: create membership", organizationErrorPrefix)
+	}
+
+	return org, nil
+}
+
+// create organization without members, used by operator
+func (s *OrganizationService) CreateOrganization(ctx context.Context, name string) (*domain.Organization, error) {
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrNameRequired
+	}
+
+	_, err := s.orgs.GetByName(ctx, name)
+	if err == nil {
+		return nil, domain.ErrOrgExists
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, errors.Wrapf(err, "%s: create get by name", organizationErrorPrefix)
+	}
+
+	org := &domain.Organization{
+		Name:      name,
+		CreatedAt: time.Now().Unix(),
+	}
+	err = s.orgs.Create(ctx, org)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: create", organizationErrorPrefix)
+	}
+
+	return org, nil
+}
+
+// get all organizations
+func (s *OrganizationService) GetAll(ctx context.Context) ([]*domain.Organization, error) {
+
+	orgs, err := s.orgs.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", organizationErrorPrefix)
+	}
+
+	return orgs, nil
+}
+
+// get organization by name
+func (s *OrganizationService) GetByName(ctx context.Context, name string) (*domain.Organization, error) {
+	return s.orgs.GetByName(ctx, name)
+}
+
+// memberships of user
+func (s *OrganizationService) List(ctx context.Context, userID uint) ([]*domain.Membership, error) {
+
+	user, err := s.users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list get user", organizationErrorPrefix)
+	}
+
+	memberships, err := s.orgs.GetMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list", organizationErrorPrefix)
+	}
+
+	return memberships, nil
+}
+
+// find membership of user which selects tenant of request,
+// the oldest membership is used when organization is not requested
+func (s *OrganizationService) Resolve(ctx context.Context, userID uint, orgID uint) (*domain.Membership, error) {
+
+	if orgID != 0 {
+		membership, err := s.orgs.GetMembership(ctx, orgID, userID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotMember
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: resolve", organizationErrorPrefix)
+		}
+		return membership, nil
+	}
+
+	memberships, err := s.orgs.GetMemberships(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: resolve", organizationErrorPrefix)
+	}
+	if len(memberships) == 0 {
+		return nil, domain.ErrNotMember
+	}
+
+	return memberships[0], nil
+}
+
+// members of organization, visible to its members only
+func (s *OrganizationService) Members(ctx context.Context, userID uint, orgID uint) ([]*domain.Membership, error) {
+
+	_, err := s.membership(ctx, userID, orgID, domain.OrgRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgs.GetMembers(ctx, orgID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: members", organizationErrorPrefix)
+	}
+
+	return members, nil
+}
+
+// add member or change role of member, allowed to organization admins
+func (s *OrganizationService) AddMember(ctx context.Context, userID uint, orgID uint, memberEmail string, role domain.OrgRole) (*domain.Membership, error) {
+
+	_, err := s.membership(ctx, userID, orgID, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.SetMember(ctx, orgID, memberEmail, role)
+}
+
+// add member or change role of member without permission check, used by operator
+func (s *OrganizationService) SetMember(ctx context.Context, orgID uint, email string, role domain.OrgRole) (*domain.Membership, error) {
+
+	if role == domain.OrgRoleUndefined {
+		return nil, domain.ErrRoleWrong
+	}
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: set member get user", organizationErrorPrefix)
+	}
+
+	membership, err := s.orgs.GetMembership(ctx, orgID, user.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		_, err = s.orgs.GetById(ctx, orgID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: set member get organization", organizationErrorPrefix)
+		}
+		membership = &domain.Membership{OrgID: orgID, UserID: user.ID, CreatedAt: time.Now().Unix()}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "%s: set member", organizationErrorPrefix)
+	} else if membership.Role == domain.OrgRoleAdmin && role != domain.OrgRoleAdmin {
+		err = s.checkOtherAdmin(ctx, orgID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	membership.Role = role
+	membership.UserEmail = user.Email
+	err = s.orgs.SaveMembership(ctx, membership)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: set member", organizationErrorPrefix)
+	}
+
+	return membership, nil
+}
+
+// remove member from organization, allowed to organization admins and to member itself
+func (s *OrganizationService) RemoveMember(ctx context.Context, callerID uint, orgID uint, userID uint) error {
+
+	caller, err := s.membership(ctx, callerID, orgID, domain.OrgRoleViewer)
+	if err != nil {
+		return err
+	}
+	if caller.UserID != userID && !caller.Role.Allows(domain.OrgRoleAdmin) {
+		return domain.ErrForbidden
+	}
+
+	membership, err := s.orgs.GetMembership(ctx, orgID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: remove member", organizationErrorPrefix)
+	}
+	if membership.Role == domain.OrgRoleAdmin {
+		err = s.checkOtherAdmin(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.orgs.DeleteMembership(ctx, orgID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: remove member", organizationErrorPrefix)
+	}
+
+	return nil
+}
+
+// membership of user with role, organization of other tenants looks like missing one
+func (s *OrganizationService) membership(ctx context.Context, userID uint, orgID uint, role domain.OrgRole) (*domain.Membership, error) {
+	return checkMembership(ctx, s.orgs, s.users, userID, orgID, role)
+}
+
+// membership of user with role in organization, shared by services which are managed by organization admins
+func checkMembership(ctx context.Context, orgs OrganizationRepository, users UserRepository, userID uint, orgID uint, role domain.OrgRole) (*domain.Membership, error) {
+
+	user, err := users.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: membership get user", organizationErrorPrefix)
+	}
+
+	membership, err := orgs.GetMembership(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: membership", organizationErrorPrefix)
+	}
+
+	if !membership.Role.Allows(role) {
+		return nil, domain.ErrForbidden
+	}
+
+	return membership, nil
+}
+
+// organization can't be left without admin
+func (s *OrganizationService) checkOtherAdmin(ctx context.Context, orgID uint, userID uint) error {
+
+	members, err := s.orgs.GetMembers(ctx, orgID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: check admin", organizationErrorPrefix)
+	}
+
+	for _, m := range members {
+		if m.UserID != userID && m.Role == domain.OrgRoleAdmin {
+			return nil
+		}
+	}
+
+	return domain.ErrOrgAdminRequired
+}
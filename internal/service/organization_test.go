@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrganizationService_Resolve(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.OrganizationRepository)
+		orgID        uint
+		resOrgID     uint
+		err          error
+	}{
+		{
+			name:  "success resolve requested organization",
+			orgID: 2,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(&domain.Membership{OrgID: 2, UserID: 1, Role: domain.OrgRoleViewer}, nil)
+			},
+			resOrgID: 2,
+		},
+		{
+			name:  "success resolve the oldest membership",
+			orgID: 0,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetMemberships", ctx, uint(1)).Return([]*domain.Membership{{OrgID: 3, UserID: 1}, {OrgID: 2, UserID: 1}}, nil)
+			},
+			resOrgID: 3,
+		},
+		{
+			name:  "failed resolve organization of other tenant",
+			orgID: 2,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrNotMember,
+		},
+		{
+			name:  "failed resolve without memberships",
+			orgID: 0,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetMemberships", ctx, uint(1)).Return([]*domain.Membership{}, nil)
+			},
+			err: domain.ErrNotMember,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		orgRepo := mocks.NewOrganizationRepository(t)
+		orgService := NewOrganizationService(orgRepo, mocks.NewUserRepository(t))
+
+		test.expectations(ctx, orgRepo)
+
+		membership, err := orgService.Resolve(ctx, 1, test.orgID)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.resOrgID, membership.OrgID)
+		}
+
+		orgRepo.AssertExpectations(t)
+	}
+}
+
+func TestOrganizationService_AddMember(t *testing.T) {
+
+	admin := &domain.User{ID: 1, Email: "admin@test.com"}
+	member := &domain.User{ID: 2, Email: "member@test.com"}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.OrganizationRepository, *mocks.UserRepository)
+		role         domain.OrgRole
+		err          error
+	}{
+		{
+			name: "success add member",
+			role: domain.OrgRoleMember,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(admin, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(1)).Return(&domain.Membership{OrgID: 5, UserID: 1, Role: domain.OrgRoleAdmin}, nil)
+				userRepo.On("GetByEmail", ctx, "member@test.com").Return(member, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(2)).Return(nil, domain.ErrNotFound)
+				orgRepo.On("GetById", ctx, uint(5)).Return(&domain.Organization{ID: 5}, nil)
+				orgRepo.On("SaveMembership", ctx, mock.MatchedBy(func(m *domain.Membership) bool {
+					return m.OrgID == 5 && m.UserID == 2 && m.Role == domain.OrgRoleMember
+				})).Return(nil)
+			},
+		},
+		{
+			name: "failed add member by member",
+			role: domain.OrgRoleMember,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(admin, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(1)).Return(&domain.Membership{OrgID: 5, UserID: 1, Role: domain.OrgRoleMember}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name: "failed add member to organization of other tenant",
+			role: domain.OrgRoleMember,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(admin, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(1)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name: "failed demote the last admin",
+			role: domain.OrgRoleViewer,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(admin, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(1)).Return(&domain.Membership{OrgID: 5, UserID: 1, Role: domain.OrgRoleAdmin}, nil)
+				userRepo.On("GetByEmail", ctx, "member@test.com").Return(member, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(2)).Return(&domain.Membership{OrgID: 5, UserID: 2, Role: domain.OrgRoleAdmin}, nil)
+				orgRepo.On("GetMembers", ctx, uint(5)).Return([]*domain.Membership{{OrgID: 5, UserID: 2, Role: domain.OrgRoleAdmin}}, nil)
+			},
+			err: domain.ErrOrgAdminRequired,
+		},
+		{
+			name: "failed add member with unknown role",
+			role: domain.OrgRoleUndefined,
+			expectations: func(ctx context.Context, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(admin, nil)
+				orgRepo.On("GetMembership", ctx, uint(5), uint(1)).Return(&domain.Membership{OrgID: 5, UserID: 1, Role: domain.OrgRoleAdmin}, nil)
+			},
+			err: domain.ErrRoleWrong,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		orgRepo := mocks.NewOrganizationRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		orgService := NewOrganizationService(orgRepo, userRepo)
+
+		test.expectations(ctx, orgRepo, userRepo)
+
+		_, err := orgService.AddMember(ctx, uint(1), 5, "member@test.com", test.role)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		orgRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	}
+}
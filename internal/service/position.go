@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	positionErrorPrefix = "[service.position]"
+)
+
+const (
+	// count of logged positions by default and at most
+	PositionLogDefaultLimit = 50
+	PositionLogMaxLimit     = 500
+
+	// length of sector and system names
+	positionNameMaxLen = 128
+)
+
+// positions of spaceships of tenant from context, proximity is searched by in-process spatial index
+type PositionService struct {
+	spaceships SpaceshipRepository
+	index      SpaceshipIndex
+}
+
+func NewPositionService(spaceships SpaceshipRepository, index SpaceshipIndex) *PositionService {
+	return &PositionService{spaceships, index}
+}
+
+// record reported position of spaceship, date defaults to now and can't be in the future
+func (s *PositionService) Report(ctx context.Context, id uint, position *domain.Position) error {
+
+	now := time.Now().Unix()
+
+	position.SpaceshipID = id
+	position.Sector = strings.TrimSpace(position.Sector)
+	position.System = strings.TrimSpace(position.System)
+	if position.ReportedAt == 0 {
+		position.ReportedAt = now
+	}
+
+	if position.Sector == "" {
+		return errors.Wrapf(domain.ErrPositionWrong, "%s: sector is required", positionErrorPrefix)
+	}
+	if utf8.RuneCountInString(position.Sector) > positionNameMaxLen || utf8.RuneCountInString(position.System) > positionNameMaxLen {
+		return errors.Wrapf(domain.ErrPositionWrong, "%s: name of sector or system is too long", positionErrorPrefix)
+	}
+	if position.Coordinates != nil && !position.Coordinates.Valid() {
+		return errors.Wrapf(domain.ErrPositionWrong, "%s: coordinates are out of galaxy", positionErrorPrefix)
+	}
+	if position.ReportedAt > now {
+		return errors.Wrapf(domain.ErrPositionWrong, "%s: position can't be reported in the future", positionErrorPrefix)
+	}
+
+	err := s.spaceships.ReportPosition(ctx, position)
+	if err != nil {
+		return errors.Wrapf(err, "%s: report", positionErrorPrefix)
+	}
+
+	spaceship, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "%s: get spaceship", positionErrorPrefix)
+	}
+	s.index.Put(spaceship)
+
+	return nil
+}
+
+// log of spaceship positions from the newest
+func (s *PositionService) Positions(ctx context.Context, id uint, limit int) ([]*domain.Position, error) {
+
+	_, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = PositionLogDefaultLimit
+	}
+	if limit > PositionLogMaxLimit {
+		limit = PositionLogMaxLimit
+	}
+
+	positions, err := s.spaceships.GetPositions(ctx, id, limit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get positions", positionErrorPrefix)
+	}
+
+	return positions, nil
+}
+
+// spaceships with known coordinates within radius of point, returns total count of them
+func (s *PositionService) Near(ctx context.Context, query domain.ProximityQuery) ([]*domain.ProximityHit, int, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: near", positionErrorPrefix)
+	}
+
+	if !query.Point.Valid() {
+		return nil, 0, errors.Wrapf(domain.ErrProximityQuery, "%s: point is out of galaxy", positionErrorPrefix)
+	}
+	if math.IsNaN(query.Radius) || query.Radius <= 0 || query.Radius > 4*domain.GalaxyExtent {
+		return nil, 0, errors.Wrapf(domain.ErrProximityQuery, "%s: radius is out of range", positionErrorPrefix)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = SearchDefaultLimit
+	}
+	if query.Limit > SearchMaxLimit {
+		query.Limit = SearchMaxLimit
+	}
+
+	hits, total := s.index.Near(tenantID, query)
+	return hits, total, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionService_Report(t *testing.T) {
+
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name     string
+		position *domain.Position
+		err      error
+	}{
+		{
+			name:     "success sector and system",
+			position: &domain.Position{Sector: " Outer Rim ", System: "Hoth"},
+		},
+		{
+			name:     "success past position with coordinates",
+			position: &domain.Position{Sector: "Outer Rim", Coordinates: &domain.Coordinates{X: 1, Y: -2, Z: 3}, ReportedAt: now - 60},
+		},
+		{
+			name:     "failed position without sector",
+			position: &domain.Position{System: "Hoth"},
+			err:      domain.ErrPositionWrong,
+		},
+		{
+			name:     "failed position out of galaxy",
+			position: &domain.Position{Sector: "Outer Rim", Coordinates: &domain.Coordinates{X: math.Inf(1)}},
+			err:      domain.ErrPositionWrong,
+		},
+		{
+			name:     "failed position in the future",
+			position: &domain.Position{Sector: "Outer Rim", ReportedAt: now + 3600},
+			err:      domain.ErrPositionWrong,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		index := mocks.NewSpaceshipIndex(t)
+		positionService := NewPositionService(spaceshipRepo, index)
+
+		if test.err == nil {
+			spaceship := &domain.Spaceship{ID: 1, Position: test.position}
+			spaceshipRepo.On("ReportPosition", ctx, test.position).Return(nil)
+			spaceshipRepo.On("GetById", ctx, uint(1)).Return(spaceship, nil)
+			index.On("Put", spaceship).Return()
+		}
+
+		err := positionService.Report(ctx, 1, test.position)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), test.position.SpaceshipID)
+		assert.Equal(t, "Outer Rim", test.position.Sector)
+		assert.NotZero(t, test.position.ReportedAt)
+	}
+}
+
+func TestPositionService_Near(t *testing.T) {
+
+	testCases := []struct {
+		name  string
+		query domain.ProximityQuery
+		limit int
+		err   error
+	}{
+		{
+			name:  "success with default limit",
+			query: domain.ProximityQuery{Radius: 10},
+			limit: SearchDefaultLimit,
+		},
+		{
+			name:  "success with limit cut to max",
+			query: domain.ProximityQuery{Point: domain.Coordinates{X: 100}, Radius: 10, Limit: 1000},
+			limit: SearchMaxLimit,
+		},
+		{
+			name:  "failed without radius",
+			query: domain.ProximityQuery{},
+			err:   domain.ErrProximityQuery,
+		},
+		{
+			name:  "failed point out of galaxy",
+			query: domain.ProximityQuery{Point: domain.Coordinates{Y: math.NaN()}, Radius: 10},
+			err:   domain.ErrProximityQuery,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := tenant.WithID(context.Background(), 1)
+
+		index := mocks.NewSpaceshipIndex(t)
+		positionService := NewPositionService(mocks.NewSpaceshipRepository(t), index)
+
+		if test.err == nil {
+			index.On("Near", uint(1), mock.MatchedBy(func(q domain.ProximityQuery) bool {
+				return q.Limit == test.limit
+			})).Return([]*domain.ProximityHit{}, 0)
+		}
+
+		_, _, err := positionService.Near(ctx, test.query)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
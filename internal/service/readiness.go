@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	readinessErrorPrefix = "[service.readiness]"
+)
+
+// scoring of readiness by configurable rules
+//
+//go:generate mockery --dir . --name ReadinessEngine --output ./mocks
+type ReadinessEngine interface {
+	Score(domain.ReadinessInput) *domain.Readiness
+	Fleet([]domain.ReadinessInput) *domain.FleetReadiness
+}
+
+// combat readiness of spaceships of tenant from context
+type ReadinessService struct {
+	spaceships SpaceshipRepository
+	classes    ShipClassRepository
+	orders     WorkOrderRepository
+	crew       CrewRepository
+	engine     ReadinessEngine
+}
+
+func NewReadinessService(spaceships SpaceshipRepository, classes ShipClassRepository, orders WorkOrderRepository, crew CrewRepository, engine ReadinessEngine) *ReadinessService {
+	return &ReadinessService{spaceships, classes, orders, crew, engine}
+}
+
+func (s *ReadinessService) Spaceship(ctx context.Context, id uint) (*domain.Readiness, error) {
+
+	spaceship, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	in := domain.ReadinessInput{Spaceship: spaceship, Now: time.Now().Unix()}
+	if spaceship.ClassID != 0 {
+		in.Class, err = s.classes.GetById(ctx, spaceship.ClassID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get class", readinessErrorPrefix)
+		}
+	}
+
+	in.Orders, err = s.orders.GetAll(ctx, domain.WorkOrderFilter{SpaceshipID: id, Open: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get work orders", readinessErrorPrefix)
+	}
+
+	in.Roster, err = s.crew.CountRoster(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: count roster", readinessErrorPrefix)
+	}
+
+	return s.engine.Score(in), nil
+}
+
+func (s *ReadinessService) Fleet(ctx context.Context) (*domain.FleetReadiness, error) {
+
+	spaceships, err := s.spaceships.GetAllFull(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get spaceships", readinessErrorPrefix)
+	}
+
+	classes, err := s.classes.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get classes", readinessErrorPrefix)
+	}
+	classByID := make(map[uint]*domain.ShipClass, len(classes))
+	for _, c := range classes {
+		classByID[c.ID] = c
+	}
+
+	orders, err := s.orders.GetAll(ctx, domain.WorkOrderFilter{Open: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get work orders", readinessErrorPrefix)
+	}
+	ordersBySpaceship := map[uint][]*domain.WorkOrder{}
+	for _, o := range orders {
+		ordersBySpaceship[o.SpaceshipID] = append(ordersBySpaceship[o.SpaceshipID], o)
+	}
+
+	rosters, err := s.crew.CountRosters(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: count rosters", readinessErrorPrefix)
+	}
+
+	now := time.Now().Unix()
+	ins := make([]domain.ReadinessInput, 0, len(spaceships))
+	for _, ss := range spaceships {
+		ins = append(ins, domain.ReadinessInput{
+			Spaceship: ss,
+			Class:     classByID[ss.ClassID],
+			Orders:    ordersBySpaceship[ss.ID],
+			Roster:    rosters[ss.ID],
+			Now:       now,
+		})
+	}
+
+	return s.engine.Fleet(ins), nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessService_Spaceship(t *testing.T) {
+
+	testCases := []struct {
+		name      string
+		spaceship *domain.Spaceship
+		roster    int
+		err       error
+	}{
+		{
+			name:      "success spaceship of class scored by roster",
+			spaceship: &domain.Spaceship{ID: 1, ClassID: 2, Crew: 9000},
+			roster:    900,
+		},
+		{
+			name:      "success spaceship out of catalog",
+			spaceship: &domain.Spaceship{ID: 1, Crew: 2},
+			roster:    2,
+		},
+		{
+			name:      "failed count roster",
+			spaceship: &domain.Spaceship{ID: 1},
+			err:       domain.ErrConfig,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		classRepo := mocks.NewShipClassRepository(t)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		crewRepo := mocks.NewCrewRepository(t)
+		engine := mocks.NewReadinessEngine(t)
+		readinessService := NewReadinessService(spaceshipRepo, classRepo, orderRepo, crewRepo, engine)
+
+		class := &domain.ShipClass{ID: 2, MinCrew: 9000}
+		spaceshipRepo.On("GetById", ctx, uint(1)).Return(test.spaceship, nil)
+		if test.spaceship.ClassID != 0 {
+			classRepo.On("GetById", ctx, test.spaceship.ClassID).Return(class, nil)
+		}
+		orderRepo.On("GetAll", ctx, domain.WorkOrderFilter{SpaceshipID: 1, Open: true}).Return(nil, nil)
+		crewRepo.On("CountRoster", ctx, uint(1)).Return(test.roster, test.err)
+		if test.err == nil {
+			engine.On("Score", mock.MatchedBy(func(in domain.ReadinessInput) bool {
+				return in.Spaceship == test.spaceship && in.Roster == test.roster &&
+					(test.spaceship.ClassID == 0) == (in.Class == nil)
+			})).Return(&domain.Readiness{SpaceshipID: 1})
+		}
+
+		r, err := readinessService.Spaceship(ctx, 1)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), r.SpaceshipID)
+	}
+}
+
+func TestReadinessService_Fleet(t *testing.T) {
+
+	ctx := context.Background()
+
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	classRepo := mocks.NewShipClassRepository(t)
+	orderRepo := mocks.NewWorkOrderRepository(t)
+	crewRepo := mocks.NewCrewRepository(t)
+	engine := mocks.NewReadinessEngine(t)
+	readinessService := NewReadinessService(spaceshipRepo, classRepo, orderRepo, crewRepo, engine)
+
+	spaceshipRepo.On("GetAllFull", ctx).Return([]*domain.Spaceship{{ID: 1, ClassID: 2}, {ID: 2, ClassID: 2}}, nil)
+	classRepo.On("GetAll", ctx).Return([]*domain.ShipClass{{ID: 2, MinCrew: 10}}, nil)
+	orderRepo.On("GetAll", ctx, domain.WorkOrderFilter{Open: true}).Return(nil, nil)
+	crewRepo.On("CountRosters", ctx).Return(map[uint]int{1: 10}, nil)
+	engine.On("Fleet", mock.MatchedBy(func(ins []domain.ReadinessInput) bool {
+		return len(ins) == 2 && ins[0].Roster == 10 && ins[1].Roster == 0
+	})).Return(&domain.FleetReadiness{Spaceships: 2})
+
+	fleet, err := readinessService.Fleet(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fleet.Spaceships)
+}
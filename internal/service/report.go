@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	reportErrorPrefix = "[service.report]"
+)
+
+//go:generate mockery --dir . --name ReportRepository --output ./mocks
+type ReportRepository interface {
+	Fleet(context.Context, domain.ReportQuery) ([]*domain.FleetReportRow, error)
+	Armament(context.Context, domain.ReportQuery) ([]*domain.ArmamentReportRow, error)
+	Readiness(context.Context, domain.ReportQuery) ([]*domain.ReadinessReportRow, error)
+	SaveSnapshot(context.Context, *domain.FleetSnapshot) error
+	GetSnapshots(context.Context, int64, int64) ([]*domain.FleetSnapshot, error)
+}
+
+// fields each report can be grouped by
+var (
+	fleetReportGroups     = []string{domain.ReportGroupStatus, domain.ReportGroupClass}
+	armamentReportGroups  = []string{domain.ReportGroupArmament, domain.ReportGroupClass}
+	readinessReportGroups = []string{domain.ReportGroupClass}
+)
+
+// reports over fleet of tenant from context, aggregations run in database
+type ReportService struct {
+	repository ReportRepository
+	orgs       OrganizationRepository
+}
+
+func NewReportService(repository ReportRepository, orgs OrganizationRepository) *ReportService {
+	return &ReportService{repository, orgs}
+}
+
+// count, value and crew of spaceships
+func (s *ReportService) Fleet(ctx context.Context, q domain.ReportQuery) ([]*domain.FleetReportRow, error) {
+
+	err := validateReportQuery(q, fleetReportGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repository.Fleet(ctx, q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: fleet", reportErrorPrefix)
+	}
+
+	return rows, nil
+}
+
+// armament inventory, always grouped by armament
+func (s *ReportService) Armament(ctx context.Context, q domain.ReportQuery) ([]*domain.ArmamentReportRow, error) {
+
+	if !q.GroupedBy(domain.ReportGroupArmament) {
+		q.Group = append([]string{domain.ReportGroupArmament}, q.Group...)
+	}
+
+	err := validateReportQuery(q, armamentReportGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repository.Armament(ctx, q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: armament", reportErrorPrefix)
+	}
+
+	return rows, nil
+}
+
+// operational spaceships of all spaceships
+func (s *ReportService) Readiness(ctx context.Context, q domain.ReportQuery) ([]*domain.ReadinessReportRow, error) {
+
+	err := validateReportQuery(q, readinessReportGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repository.Readiness(ctx, q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: readiness", reportErrorPrefix)
+	}
+
+	return rows, nil
+}
+
+// daily snapshots within time window
+func (s *ReportService) Trends(ctx context.Context, q domain.ReportQuery) ([]*domain.FleetSnapshot, error) {
+
+	err := validateReportQuery(q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.repository.GetSnapshots(ctx, q.From, q.To)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: trends", reportErrorPrefix)
+	}
+
+	return snapshots, nil
+}
+
+// snapshot fleet metrics of all organizations for current day, returns count of snapshots
+func (s *ReportService) Snapshot(ctx context.Context, now time.Time) (int, error) {
+
+	orgs, err := s.orgs.GetAll(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: snapshot get organizations", reportErrorPrefix)
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour).Unix()
+	for _, org := range orgs {
+		ctx := tenant.WithID(ctx, org.ID)
+
+		rows, err := s.repository.Fleet(ctx, domain.ReportQuery{Group: []string{domain.ReportGroupStatus}})
+		if err != nil {
+			return 0, errors.Wrapf(err, "%s: snapshot of organization %d", reportErrorPrefix, org.ID)
+		}
+
+		snapshot := &domain.FleetSnapshot{Day: day, TakenAt: now.Unix()}
+		for _, r := range rows {
+			snapshot.Spaceships += r.Count
+			if r.Currency == domain.DefaultCurrency {
+				snapshot.Value += r.Value
+			}
+			snapshot.Crew += r.Crew
+			switch r.Status {
+			case domain.SpaceshipStatusOperational:
+				snapshot.Operational += r.Count
+			case domain.SpaceshipStatusDamaged:
+				snapshot.Damaged += r.Count
+			}
+		}
+
+		err = s.repository.SaveSnapshot(ctx, snapshot)
+		if err != nil {
+			return 0, errors.Wrapf(err, "%s: snapshot of organization %d", reportErrorPrefix, org.ID)
+		}
+	}
+
+	return len(orgs), nil
+}
+
+// snapshot metrics on start and periodically until context is done,
+// snapshot of the day is refreshed until the day ends
+func (s *ReportService) Run(ctx context.Context, interval time.Duration) {
+	_, err := s.Snapshot(ctx, time.Now())
+	if err != nil {
+		log.Println(err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.Snapshot(ctx, time.Now())
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+func validateReportQuery(q domain.ReportQuery, groups []string) error {
+
+	if q.From != 0 && q.To != 0 && q.From >= q.To {
+		return errors.Wrapf(domain.ErrReportWindow, "%s: from is not before to", reportErrorPrefix)
+	}
+
+	seen := map[string]bool{}
+	for _, g := range q.Group {
+		allowed := false
+		for _, a := range groups {
+			allowed = allowed || a == g
+		}
+		if !allowed || seen[g] {
+			return errors.Wrapf(domain.ErrReportGroup, "%s: %q", reportErrorPrefix, g)
+		}
+		seen[g] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService_Fleet(t *testing.T) {
+
+	testCases := []struct {
+		name  string
+		query domain.ReportQuery
+		err   error
+	}{
+		{
+			name:  "success report grouped by status and class",
+			query: domain.ReportQuery{Group: []string{"status", "class"}},
+		},
+		{
+			name:  "success report within window",
+			query: domain.ReportQuery{From: 100, To: 200},
+		},
+		{
+			name:  "failed report grouped by armament",
+			query: domain.ReportQuery{Group: []string{"armament"}},
+			err:   domain.ErrReportGroup,
+		},
+		{
+			name:  "failed report grouped twice by status",
+			query: domain.ReportQuery{Group: []string{"status", "status"}},
+			err:   domain.ErrReportGroup,
+		},
+		{
+			name:  "failed report with reversed window",
+			query: domain.ReportQuery{From: 200, To: 100},
+			err:   domain.ErrReportWindow,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		reportRepo := mocks.NewReportRepository(t)
+		reportService := NewReportService(reportRepo, nil)
+
+		if test.err == nil {
+			reportRepo.On("Fleet", ctx, test.query).Return([]*domain.FleetReportRow{}, nil)
+		}
+
+		_, err := reportService.Fleet(ctx, test.query)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestReportService_Snapshot(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 4, 15, 30, 0, 0, time.UTC)
+
+	reportRepo := mocks.NewReportRepository(t)
+	orgRepo := mocks.NewOrganizationRepository(t)
+	reportService := NewReportService(reportRepo, orgRepo)
+
+	orgRepo.On("GetAll", ctx).Return([]*domain.Organization{{ID: 1}, {ID: 2}}, nil)
+	byStatus := domain.ReportQuery{Group: []string{domain.ReportGroupStatus}}
+	reportRepo.On("Fleet", tenant.WithID(ctx, 1), byStatus).Return([]*domain.FleetReportRow{
+		{Status: domain.SpaceshipStatusOperational, Count: 3, Value: domain.NewDecimal(300), Currency: domain.DefaultCurrency, Crew: 30},
+		{Status: domain.SpaceshipStatusDamaged, Count: 1, Value: domain.NewDecimal(50), Currency: domain.DefaultCurrency, Crew: 5},
+	}, nil)
+	reportRepo.On("Fleet", tenant.WithID(ctx, 2), byStatus).Return([]*domain.FleetReportRow{}, nil)
+
+	snapshots := map[uint]*domain.FleetSnapshot{}
+	reportRepo.On("SaveSnapshot", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tenantID, err := tenant.ID(args.Get(0).(context.Context))
+		require.NoError(t, err)
+		snapshots[tenantID] = args.Get(1).(*domain.FleetSnapshot)
+	}).Return(nil)
+
+	count, err := reportService.Snapshot(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	day := time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC).Unix()
+	assert.Equal(t, &domain.FleetSnapshot{Day: day, Spaceships: 4, Operational: 3, Damaged: 1, Value: domain.NewDecimal(350), Crew: 35, TakenAt: now.Unix()}, snapshots[1])
+	assert.Equal(t, float64(75), snapshots[1].Readiness())
+	assert.Equal(t, &domain.FleetSnapshot{Day: day, TakenAt: now.Unix()}, snapshots[2])
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	routeErrorPrefix = "[service.route]"
+)
+
+// length of reason of lane closure
+const closureReasonMaxLen = 1024
+
+//go:generate mockery --dir . --name HyperspaceRepository --output ./mocks
+type HyperspaceRepository interface {
+	GetGalaxy(context.Context) (*domain.Galaxy, error)
+	GetVersion(context.Context) (int64, error)
+	SaveGalaxy(context.Context, *domain.Galaxy) error
+	GetClosures(context.Context, int64) ([]*domain.HyperlaneClosure, error)
+	CreateClosure(context.Context, *domain.HyperlaneClosure) error
+	DeleteClosure(context.Context, uint) error
+}
+
+// planner of routes on graph of star systems and hyperlanes
+//
+//go:generate mockery --dir . --name RoutePlanner --output ./mocks
+type RoutePlanner interface {
+	Parse([]byte) (*domain.Galaxy, error)
+	Load(*domain.Galaxy) error
+	Galaxy() *domain.Galaxy
+	System(string) (*domain.StarSystem, bool)
+	Lane(string, string) (*domain.Hyperlane, bool)
+	Plan(domain.RouteQuery) (*domain.Route, error)
+}
+
+// routes of spaceships of tenant from context, galaxy and closures of lanes are shared
+// by all organizations and changed by admins, galaxy uploaded by other instance is
+// reloaded into planner when its version differs from one of planner
+type RouteService struct {
+	spaceships SpaceshipRepository
+	classes    ShipClassRepository
+	hyperspace HyperspaceRepository
+	planner    RoutePlanner
+}
+
+func NewRouteService(spaceships SpaceshipRepository, classes ShipClassRepository, hyperspace HyperspaceRepository, planner RoutePlanner) *RouteService {
+	return &RouteService{spaceships, classes, hyperspace, planner}
+}
+
+// load uploaded galaxy into planner, planner keeps galaxy of data file if none was uploaded
+func (s *RouteService) Restore(ctx context.Context) error {
+
+	galaxy, err := s.hyperspace.GetGalaxy(ctx)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: restore", routeErrorPrefix)
+	}
+
+	return s.planner.Load(galaxy)
+}
+
+// reload galaxy when other instance uploaded new one
+func (s *RouteService) sync(ctx context.Context) error {
+
+	version, err := s.hyperspace.GetVersion(ctx)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: sync", routeErrorPrefix)
+	}
+
+	if version == s.planner.Galaxy().Version {
+		return nil
+	}
+
+	return s.Restore(ctx)
+}
+
+// route of spaceship from its star system to destination
+func (s *RouteService) Plan(ctx context.Context, id uint, destination string, mode domain.RouteMode) (*domain.Route, error) {
+
+	spaceship, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if spaceship.Position == nil || spaceship.Position.System == "" {
+		return nil, errors.Wrapf(domain.ErrRouteOrigin, "%s: position of spaceship has no system", routeErrorPrefix)
+	}
+	origin, ok := s.planner.System(spaceship.Position.System)
+	if !ok {
+		return nil, errors.Wrapf(domain.ErrRouteOrigin, "%s: system %q is out of galaxy", routeErrorPrefix, spaceship.Position.System)
+	}
+
+	rating := float64(domain.DefaultHyperdriveRating)
+	if spaceship.ClassID != 0 {
+		class, err := s.classes.GetById(ctx, spaceship.ClassID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get class", routeErrorPrefix)
+		}
+		if class.Hyperdrive > 0 {
+			rating = class.Hyperdrive
+		}
+	}
+
+	closures, err := s.hyperspace.GetClosures(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get closures", routeErrorPrefix)
+	}
+
+	route, err := s.planner.Plan(domain.RouteQuery{
+		From:     origin.Name,
+		To:       strings.TrimSpace(destination),
+		Mode:     mode,
+		Rating:   rating,
+		Closures: closures,
+	})
+	if err != nil {
+		return nil, err
+	}
+	route.SpaceshipID = id
+
+	return route, nil
+}
+
+// galaxy of planner with lanes closed now and active closures
+func (s *RouteService) Galaxy(ctx context.Context) (*domain.Galaxy, []*domain.HyperlaneClosure, error) {
+
+	err := s.sync(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closures, err := s.hyperspace.GetClosures(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s: get closures", routeErrorPrefix)
+	}
+
+	// galaxy of planner is shared, so lanes are copied before they are flagged
+	current := s.planner.Galaxy()
+	galaxy := *current
+	galaxy.Lanes = make([]domain.Hyperlane, len(current.Lanes))
+	copy(galaxy.Lanes, current.Lanes)
+	for i, l := range galaxy.Lanes {
+		for _, c := range closures {
+			if sameLane(l, c) {
+				galaxy.Lanes[i].Closed = true
+			}
+		}
+	}
+
+	return &galaxy, closures, nil
+}
+
+// replace galaxy by yaml or json one, closures of lanes out of new galaxy have no effect
+func (s *RouteService) Upload(ctx context.Context, data []byte) (*domain.Galaxy, error) {
+
+	galaxy, err := s.planner.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	galaxy.UploadedAt = time.Now().Unix()
+
+	err = s.hyperspace.SaveGalaxy(ctx, galaxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: save galaxy", routeErrorPrefix)
+	}
+
+	err = s.planner.Load(galaxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return galaxy, nil
+}
+
+// close lane until date or until it is opened when date is zero
+func (s *RouteService) CloseLane(ctx context.Context, closure *domain.HyperlaneClosure) error {
+
+	err := s.sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	lane, ok := s.planner.Lane(closure.From, closure.To)
+	if !ok {
+		return errors.Wrapf(domain.ErrLaneUnknown, "%s: %q - %q", routeErrorPrefix, closure.From, closure.To)
+	}
+	closure.From, closure.To = lane.From, lane.To
+	closure.Reason = strings.TrimSpace(closure.Reason)
+	closure.CreatedAt = now
+
+	if utf8.RuneCountInString(closure.Reason) > closureReasonMaxLen {
+		return errors.Wrapf(domain.ErrClosureWrong, "%s: reason is too long", routeErrorPrefix)
+	}
+	if closure.Until != 0 && closure.Until <= now {
+		return errors.Wrapf(domain.ErrClosureWrong, "%s: closure can't end in the past", routeErrorPrefix)
+	}
+
+	err = s.hyperspace.CreateClosure(ctx, closure)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create closure", routeErrorPrefix)
+	}
+
+	return nil
+}
+
+// open lane by removing its closure
+func (s *RouteService) OpenLane(ctx context.Context, id uint) error {
+
+	return s.hyperspace.DeleteClosure(ctx, id)
+}
+
+// closure is of lane in any direction
+func sameLane(lane domain.Hyperlane, closure *domain.HyperlaneClosure) bool {
+	from, to := strings.ToLower(closure.From), strings.ToLower(closure.To)
+	return (strings.ToLower(lane.From) == from && strings.ToLower(lane.To) == to) ||
+		(strings.ToLower(lane.From) == to && strings.ToLower(lane.To) == from)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/hyperspace"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// line of systems A - B - C with lanes of 100 parsecs, hour of jump at rating 1
+const routeGalaxy = `
+speed: 100
+systems: [{name: A}, {name: B, x: 100}, {name: C, x: 200}]
+lanes: [{from: A, to: B}, {from: B, to: C}]
+`
+
+func routePlanner(t *testing.T) *hyperspace.Planner {
+	galaxy, err := hyperspace.Parse([]byte(routeGalaxy))
+	require.NoError(t, err)
+	planner, err := hyperspace.NewPlanner(galaxy)
+	require.NoError(t, err)
+	return planner
+}
+
+func TestRouteService_Plan(t *testing.T) {
+
+	testCases := []struct {
+		name      string
+		spaceship *domain.Spaceship
+		class     *domain.ShipClass
+		closures  []*domain.HyperlaneClosure
+		hours     float64
+		err       error
+	}{
+		{
+			name:      "success with hyperdrive of class",
+			spaceship: &domain.Spaceship{ID: 1, ClassID: 3, Position: &domain.Position{System: "a"}},
+			class:     &domain.ShipClass{ID: 3, Hyperdrive: 1.5},
+			hours:     3,
+		},
+		{
+			name:      "success with default hyperdrive of class without rating",
+			spaceship: &domain.Spaceship{ID: 1, ClassID: 3, Position: &domain.Position{System: "A"}},
+			class:     &domain.ShipClass{ID: 3},
+			hours:     4,
+		},
+		{
+			name:      "success with default hyperdrive out of catalog",
+			spaceship: &domain.Spaceship{ID: 1, Position: &domain.Position{System: "A"}},
+			hours:     4,
+		},
+		{
+			name:      "failed closed lane",
+			spaceship: &domain.Spaceship{ID: 1, Position: &domain.Position{System: "A"}},
+			closures:  []*domain.HyperlaneClosure{{From: "C", To: "B"}},
+			err:       domain.ErrNoRoute,
+		},
+		{
+			name:      "failed spaceship without position",
+			spaceship: &domain.Spaceship{ID: 1},
+			err:       domain.ErrRouteOrigin,
+		},
+		{
+			name:      "failed spaceship out of galaxy",
+			spaceship: &domain.Spaceship{ID: 1, Position: &domain.Position{Sector: "Outer Rim", System: "Hoth"}},
+			err:       domain.ErrRouteOrigin,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		shipClassRepo := mocks.NewShipClassRepository(t)
+		hyperspaceRepo := mocks.NewHyperspaceRepository(t)
+		routeService := NewRouteService(spaceshipRepo, shipClassRepo, hyperspaceRepo, routePlanner(t))
+
+		spaceshipRepo.On("GetById", ctx, uint(1)).Return(test.spaceship, nil)
+		hyperspaceRepo.On("GetVersion", ctx).Return(int64(0), domain.ErrNotFound)
+		if test.class != nil {
+			shipClassRepo.On("GetById", ctx, test.class.ID).Return(test.class, nil)
+		}
+		if test.err != domain.ErrRouteOrigin {
+			hyperspaceRepo.On("GetClosures", ctx, mock.AnythingOfType("int64")).Return(test.closures, nil)
+		}
+
+		route, err := routeService.Plan(ctx, 1, "C", domain.RouteModeFastest)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), route.SpaceshipID)
+		assert.Equal(t, "A", route.From)
+		assert.Equal(t, test.hours, route.Hours)
+	}
+}
+
+func TestRouteService_CloseLane(t *testing.T) {
+
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name    string
+		closure *domain.HyperlaneClosure
+		err     error
+	}{
+		{
+			name:    "success lane in other direction",
+			closure: &domain.HyperlaneClosure{From: "b", To: "a", Reason: " blockade ", Until: now + 3600},
+		},
+		{
+			name:    "failed unknown lane",
+			closure: &domain.HyperlaneClosure{From: "A", To: "C"},
+			err:     domain.ErrLaneUnknown,
+		},
+		{
+			name:    "failed closure ended in the past",
+			closure: &domain.HyperlaneClosure{From: "A", To: "B", Until: now - 60},
+			err:     domain.ErrClosureWrong,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		hyperspaceRepo := mocks.NewHyperspaceRepository(t)
+		routeService := NewRouteService(mocks.NewSpaceshipRepository(t), mocks.NewShipClassRepository(t), hyperspaceRepo, routePlanner(t))
+
+		hyperspaceRepo.On("GetVersion", ctx).Return(int64(0), domain.ErrNotFound)
+		if test.err == nil {
+			hyperspaceRepo.On("CreateClosure", ctx, test.closure).Return(nil)
+		}
+
+		err := routeService.CloseLane(ctx, test.closure)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "A", test.closure.From)
+		assert.Equal(t, "B", test.closure.To)
+		assert.Equal(t, "blockade", test.closure.Reason)
+		assert.NotZero(t, test.closure.CreatedAt)
+	}
+}
+
+func TestRouteService_Sync(t *testing.T) {
+
+	ctx := context.Background()
+
+	hyperspaceRepo := mocks.NewHyperspaceRepository(t)
+	routeService := NewRouteService(mocks.NewSpaceshipRepository(t), mocks.NewShipClassRepository(t), hyperspaceRepo, routePlanner(t))
+
+	// galaxy uploaded by other instance is loaded once
+	uploaded, err := hyperspace.Parse([]byte(routeGalaxy))
+	require.NoError(t, err)
+	uploaded.Systems = append(uploaded.Systems, domain.StarSystem{Name: "D"})
+	uploaded.Version = 2
+	hyperspaceRepo.On("GetVersion", ctx).Return(int64(2), nil)
+	hyperspaceRepo.On("GetGalaxy", ctx).Return(uploaded, nil).Once()
+	hyperspaceRepo.On("GetClosures", ctx, mock.AnythingOfType("int64")).Return(nil, nil)
+
+	for i := 0; i < 2; i++ {
+		galaxy, _, err := routeService.Galaxy(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), galaxy.Version)
+		assert.Len(t, galaxy.Systems, 4)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	searchErrorPrefix = "[service.search]"
+)
+
+const (
+	// count of search results by default and at most
+	SearchDefaultLimit = 20
+	SearchMaxLimit     = 100
+)
+
+// full text and spatial index of spaceships, kept up to date by spaceship service
+//
+//go:generate mockery --dir . --name SpaceshipIndex --output ./mocks
+type SpaceshipIndex interface {
+	Put(*domain.Spaceship)
+	Delete(uint, uint)
+	Replace(uint, []*domain.Spaceship)
+	Search(uint, string, int) ([]*domain.SearchHit, int, error)
+	Near(uint, domain.ProximityQuery) ([]*domain.ProximityHit, int)
+}
+
+// search service, index is built from database on start and on demand of admin
+type SearchService struct {
+	index      SpaceshipIndex
+	spaceships SpaceshipRepository
+	orgs       OrganizationRepository
+}
+
+func NewSearchService(index SpaceshipIndex, spaceships SpaceshipRepository, orgs OrganizationRepository) *SearchService {
+	return &SearchService{index, spaceships, orgs}
+}
+
+// ranked spaceships of tenant from context, returns total count of matches
+func (s *SearchService) Search(ctx context.Context, query string, limit int) ([]*domain.SearchHit, int, error) {
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "%s: search", searchErrorPrefix)
+	}
+
+	if limit <= 0 {
+		limit = SearchDefaultLimit
+	}
+	if limit > SearchMaxLimit {
+		limit = SearchMaxLimit
+	}
+
+	return s.index.Search(tenantID, query, limit)
+}
+
+// load spaceships of all organizations into index, e.g. on start or after spaceships
+// were imported with cli, returns count of spaceships
+func (s *SearchService) Rebuild(ctx context.Context) (int, error) {
+
+	orgs, err := s.orgs.GetAll(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: rebuild get organizations", searchErrorPrefix)
+	}
+
+	count := 0
+	for _, org := range orgs {
+		spaceships, err := s.spaceships.GetAllFull(tenant.WithID(ctx, org.ID))
+		if err != nil {
+			return count, errors.Wrapf(err, "%s: rebuild organization %d", searchErrorPrefix, org.ID)
+		}
+		s.index.Replace(org.ID, spaceships)
+		count += len(spaceships)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchService_Rebuild(t *testing.T) {
+
+	spaceships := []*domain.Spaceship{{ID: 1, TenantID: 2, Name: "Devastator"}}
+
+	testCases := []struct {
+		name         string
+		expectations func(*mocks.SpaceshipIndex, *mocks.SpaceshipRepository, *mocks.OrganizationRepository)
+		count        int
+		err          error
+	}{
+		{
+			name: "success rebuild",
+			expectations: func(index *mocks.SpaceshipIndex, spaceshipRepo *mocks.SpaceshipRepository, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetAll", mock.Anything).Return([]*domain.Organization{{ID: 2}, {ID: 3}}, nil)
+				spaceshipRepo.On("GetAllFull", mock.MatchedBy(func(ctx context.Context) bool {
+					id, _ := tenant.ID(ctx)
+					return id == 2
+				})).Return(spaceships, nil)
+				spaceshipRepo.On("GetAllFull", mock.MatchedBy(func(ctx context.Context) bool {
+					id, _ := tenant.ID(ctx)
+					return id == 3
+				})).Return([]*domain.Spaceship{}, nil)
+				index.On("Replace", uint(2), spaceships).Return()
+				index.On("Replace", uint(3), []*domain.Spaceship{}).Return()
+			},
+			count: 1,
+		},
+		{
+			name: "failed rebuild get organizations",
+			expectations: func(index *mocks.SpaceshipIndex, spaceshipRepo *mocks.SpaceshipRepository, orgRepo *mocks.OrganizationRepository) {
+				orgRepo.On("GetAll", mock.Anything).Return(nil, domain.ErrConfig)
+			},
+			err: domain.ErrConfig,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		index := mocks.NewSpaceshipIndex(t)
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		orgRepo := mocks.NewOrganizationRepository(t)
+		searchService := NewSearchService(index, spaceshipRepo, orgRepo)
+
+		test.expectations(index, spaceshipRepo, orgRepo)
+
+		count, err := searchService.Rebuild(ctx)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.count, count)
+		}
+	}
+}
+
+func TestSearchService_Search(t *testing.T) {
+
+	index := mocks.NewSpaceshipIndex(t)
+	searchService := NewSearchService(index, nil, nil)
+
+	_, _, err := searchService.Search(context.Background(), "devastator", 0)
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+
+	// limit is bounded
+	ctx := tenant.WithID(context.Background(), 2)
+	index.On("Search", uint(2), "devastator", SearchMaxLimit).Return([]*domain.SearchHit{}, 0, nil)
+	_, _, err = searchService.Search(ctx, "devastator", 1000)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	serviceAccountErrorPrefix = "[service.serviceaccount]"
+)
+
+//go:generate mockery --dir . --name ServiceAccountRepository --output ./mocks
+type ServiceAccountRepository interface {
+	GetByOrg(context.Context, uint) ([]*domain.ServiceAccount, error)
+	GetById(context.Context, uint) (*domain.ServiceAccount, error)
+	Create(context.Context, *domain.ServiceAccount) error
+	Revoke(context.Context, uint, int64) error
+}
+
+// service account service, accounts and their keys are managed by organization admins
+type ServiceAccountService struct {
+	accounts ServiceAccountRepository
+	keys     APIKeyRepository
+	orgs     OrganizationRepository
+	users    UserRepository
+}
+
+func NewServiceAccountService(accounts ServiceAccountRepository, keys APIKeyRepository, orgs OrganizationRepository, users UserRepository) *ServiceAccountService {
+	return &ServiceAccountService{accounts, keys, orgs, users}
+}
+
+// service accounts of organization, revoked ones included
+func (s *ServiceAccountService) List(ctx context.Context, userID uint, orgID uint) ([]*domain.ServiceAccount, error) {
+
+	_, err := checkMembership(ctx, s.orgs, s.users, userID, orgID, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.accounts.GetByOrg(ctx, orgID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list", serviceAccountErrorPrefix)
+	}
+
+	return accounts, nil
+}
+
+// create service account which is a member of organization with role of request
+func (s *ServiceAccountService) Create(ctx context.Context, userID uint, orgID uint, req *domain.ServiceAccountCreateReq) (*domain.ServiceAccount, error) {
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, domain.ErrNameRequired
+	}
+	if !domain.ServiceAccountRole(req.Role) {
+		return nil, errors.Wrapf(domain.ErrRoleWrong, "%s: service account can't be %s", serviceAccountErrorPrefix, req.Role)
+	}
+
+	admin, err := checkMembership(ctx, s.orgs, s.users, userID, orgID, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &domain.ServiceAccount{
+		OrgID:     orgID,
+		Name:      req.Name,
+		Role:      req.Role,
+		CreatedBy: admin.UserID,
+		CreatedAt: time.Now().Unix(),
+	}
+	err = s.accounts.Create(ctx, account)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: create", serviceAccountErrorPrefix)
+	}
+
+	return account, nil
+}
+
+// revoke service account, its keys are deleted and are not accepted anymore
+func (s *ServiceAccountService) Revoke(ctx context.Context, userID uint, orgID uint, id uint) error {
+
+	account, err := s.account(ctx, userID, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	// keys of revoked account are rejected on authentication, so they are deleted after revocation
+	err = s.accounts.Revoke(ctx, account.ID, time.Now().Unix())
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke", serviceAccountErrorPrefix)
+	}
+
+	err = s.keys.DeleteByServiceAccount(ctx, account.ID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke delete keys", serviceAccountErrorPrefix)
+	}
+
+	return nil
+}
+
+// create key of service account, returns key which is not stored and is shown once
+func (s *ServiceAccountService) CreateKey(ctx context.Context, userID uint, orgID uint, id uint, req *domain.APIKeyCreateReq) (*domain.APIKey, string, error) {
+
+	account, err := s.account(ctx, userID, orgID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, rawKey, err := newAPIKey(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key.ServiceAccountID = account.ID
+	err = s.keys.Create(ctx, key)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: create key", serviceAccountErrorPrefix)
+	}
+
+	return key, rawKey, nil
+}
+
+// keys of service account
+func (s *ServiceAccountService) Keys(ctx context.Context, userID uint, orgID uint, id uint) ([]*domain.APIKey, error) {
+
+	account, err := s.account(ctx, userID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.keys.GetByServiceAccount(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: keys", serviceAccountErrorPrefix)
+	}
+
+	return keys, nil
+}
+
+// delete key of service account, account is kept
+func (s *ServiceAccountService) RevokeKey(ctx context.Context, userID uint, orgID uint, id uint, keyID uint) error {
+
+	account, err := s.account(ctx, userID, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	// key of other owner looks like missing key
+	key, err := s.keys.GetById(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.ServiceAccountID != account.ID {
+		return errors.Wrapf(domain.ErrNotFound, "%s: revoke key", serviceAccountErrorPrefix)
+	}
+
+	err = s.keys.Delete(ctx, key.ID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke key", serviceAccountErrorPrefix)
+	}
+
+	return nil
+}
+
+// active service account of organization managed by admin,
+// account of other organization looks like missing one
+func (s *ServiceAccountService) account(ctx context.Context, userID uint, orgID uint, id uint) (*domain.ServiceAccount, error) {
+
+	_, err := checkMembership(ctx, s.orgs, s.users, userID, orgID, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.accounts.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.OrgID != orgID || account.RevokedAt != 0 {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: service account %d", serviceAccountErrorPrefix, id)
+	}
+
+	return account, nil
+}
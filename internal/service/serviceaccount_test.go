@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestServiceAccountService_Create(t *testing.T) {
+
+	admin := &domain.Membership{OrgID: 2, UserID: 1, Role: domain.OrgRoleAdmin}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.ServiceAccountRepository, *mocks.OrganizationRepository, *mocks.UserRepository)
+		input        *domain.ServiceAccountCreateReq
+		err          error
+	}{
+		{
+			name:  "success create by admin",
+			input: &domain.ServiceAccountCreateReq{Name: "ci", Role: domain.OrgRoleMember},
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+				orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(admin, nil)
+				accountRepo.On("Create", ctx, mock.MatchedBy(func(account *domain.ServiceAccount) bool {
+					return account.OrgID == 2 && account.CreatedBy == 1 && account.Role == domain.OrgRoleMember
+				})).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:  "failed create without name",
+			input: &domain.ServiceAccountCreateReq{Name: " ", Role: domain.OrgRoleMember},
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+			},
+			err: domain.ErrNameRequired,
+		},
+		{
+			name:  "failed create admin account",
+			input: &domain.ServiceAccountCreateReq{Name: "ci", Role: domain.OrgRoleAdmin},
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+			},
+			err: domain.ErrRoleWrong,
+		},
+		{
+			name:  "failed create by member",
+			input: &domain.ServiceAccountCreateReq{Name: "ci", Role: domain.OrgRoleViewer},
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, orgRepo *mocks.OrganizationRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+				orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(&domain.Membership{OrgID: 2, UserID: 1, Role: domain.OrgRoleMember}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		accountRepo := mocks.NewServiceAccountRepository(t)
+		orgRepo := mocks.NewOrganizationRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		accountService := NewServiceAccountService(accountRepo, mocks.NewAPIKeyRepository(t), orgRepo, userRepo)
+
+		test.expectations(ctx, accountRepo, orgRepo, userRepo)
+
+		_, err := accountService.Create(ctx, 1, 2, test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		accountRepo.AssertExpectations(t)
+		orgRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	}
+}
+
+func TestServiceAccountService_Revoke(t *testing.T) {
+
+	admin := &domain.Membership{OrgID: 2, UserID: 1, Role: domain.OrgRoleAdmin}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.ServiceAccountRepository, *mocks.APIKeyRepository)
+		err          error
+	}{
+		{
+			name: "success revoke deletes keys",
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, keyRepo *mocks.APIKeyRepository) {
+				accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 2}, nil)
+				accountRepo.On("Revoke", ctx, uint(3), mock.Anything).Return(nil)
+				keyRepo.On("DeleteByServiceAccount", ctx, uint(3)).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed revoke account of other organization",
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, keyRepo *mocks.APIKeyRepository) {
+				accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 4}, nil)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name: "failed revoke revoked account",
+			expectations: func(ctx context.Context, accountRepo *mocks.ServiceAccountRepository, keyRepo *mocks.APIKeyRepository) {
+				accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 2, RevokedAt: 1}, nil)
+			},
+			err: domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		accountRepo := mocks.NewServiceAccountRepository(t)
+		keyRepo := mocks.NewAPIKeyRepository(t)
+		orgRepo := mocks.NewOrganizationRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		accountService := NewServiceAccountService(accountRepo, keyRepo, orgRepo, userRepo)
+
+		userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+		orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(admin, nil)
+		test.expectations(ctx, accountRepo, keyRepo)
+
+		err := accountService.Revoke(ctx, 1, 2, 3)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		accountRepo.AssertExpectations(t)
+		keyRepo.AssertExpectations(t)
+	}
+}
+
+func TestServiceAccountService_RevokeKey(t *testing.T) {
+
+	admin := &domain.Membership{OrgID: 2, UserID: 1, Role: domain.OrgRoleAdmin}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.APIKeyRepository)
+		err          error
+	}{
+		{
+			name: "success revoke key of account",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository) {
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, ServiceAccountID: 3}, nil)
+				keyRepo.On("Delete", ctx, uint(5)).Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed revoke key of other account",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository) {
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, ServiceAccountID: 4}, nil)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name: "failed revoke key of user",
+			expectations: func(ctx context.Context, keyRepo *mocks.APIKeyRepository) {
+				keyRepo.On("GetById", ctx, uint(5)).Return(&domain.APIKey{ID: 5, UserID: 1}, nil)
+			},
+			err: domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		accountRepo := mocks.NewServiceAccountRepository(t)
+		keyRepo := mocks.NewAPIKeyRepository(t)
+		orgRepo := mocks.NewOrganizationRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		accountService := NewServiceAccountService(accountRepo, keyRepo, orgRepo, userRepo)
+
+		userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+		orgRepo.On("GetMembership", ctx, uint(2), uint(1)).Return(admin, nil)
+		accountRepo.On("GetById", ctx, uint(3)).Return(&domain.ServiceAccount{ID: 3, OrgID: 2}, nil)
+		test.expectations(ctx, keyRepo)
+
+		err := accountService.RevokeKey(ctx, 1, 2, 3, 5)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		keyRepo.AssertExpectations(t)
+	}
+}
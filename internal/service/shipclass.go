@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	shipClassErrorPrefix = "[service.shipclass]"
+)
+
+//go:generate mockery --dir . --name ShipClassRepository --output ./mocks
+type ShipClassRepository interface {
+	GetAll(context.Context) ([]*domain.ShipClass, error)
+	GetById(context.Context, uint) (*domain.ShipClass, error)
+	GetByName(context.Context, string) (*domain.ShipClass, error)
+	Create(context.Context, *domain.ShipClass) error
+	Update(context.Context, *domain.ShipClass) error
+	Delete(context.Context, uint) error
+	Normalize(context.Context) (int64, error)
+}
+
+// catalog of spaceship classes shared by all organizations, changed by admins only
+type ShipClassService struct {
+	repository ShipClassRepository
+	search     *SearchService
+	cache      SpaceshipCache
+}
+
+// ship class service builder, search and cache are optional, search is rebuilt
+// and cache is purged after normalization
+func NewShipClassService(repository ShipClassRepository, search *SearchService, cache SpaceshipCache) *ShipClassService {
+	return &ShipClassService{repository, search, cache}
+}
+
+func (s *ShipClassService) GetAll(ctx context.Context) ([]*domain.ShipClass, error) {
+
+	classes, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", shipClassErrorPrefix)
+	}
+
+	return classes, nil
+}
+
+func (s *ShipClassService) GetById(ctx context.Context, id uint) (*domain.ShipClass, error) {
+	return s.repository.GetById(ctx, id)
+}
+
+func (s *ShipClassService) Create(ctx context.Context, class *domain.ShipClass) error {
+
+	err := validateShipClass(class)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Create(ctx, class)
+}
+
+// update class, spaceships are normalized to new name and aliases
+func (s *ShipClassService) Update(ctx context.Context, class *domain.ShipClass) error {
+
+	err := validateShipClass(class)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.Update(ctx, class)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.normalize(ctx)
+	return err
+}
+
+func (s *ShipClassService) Delete(ctx context.Context, id uint) error {
+
+	return s.repository.Delete(ctx, id)
+}
+
+// link spaceships of all organizations to classes by name or alias, e.g. after alias is added,
+// returns count of changed spaceships
+func (s *ShipClassService) Normalize(ctx context.Context) (int64, error) {
+
+	return s.normalize(ctx)
+}
+
+func (s *ShipClassService) normalize(ctx context.Context) (int64, error) {
+
+	count, err := s.repository.Normalize(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: normalize", shipClassErrorPrefix)
+	}
+
+	// class names are indexed for search and cached with spaceships
+	if count > 0 && s.cache != nil {
+		err = s.cache.Purge(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if count > 0 && s.search != nil {
+		_, err = s.search.Rebuild(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func validateShipClass(class *domain.ShipClass) error {
+
+	class.Name = strings.TrimSpace(class.Name)
+	if class.Name == "" {
+		return domain.ErrNameRequired
+	}
+
+	aliases := make([]string, 0, len(class.Aliases))
+	for _, a := range class.Aliases {
+		if a = strings.TrimSpace(a); a != "" {
+			aliases = append(aliases, a)
+		}
+	}
+	class.Aliases = aliases
+
+	if class.MaxCrew != 0 && class.MinCrew > class.MaxCrew {
+		return errors.Wrapf(domain.ErrCrewRange, "%s: min crew %d is above max crew %d", shipClassErrorPrefix, class.MinCrew, class.MaxCrew)
+	}
+
+	if _, err := domain.DecimalFromFloat(class.BaseValue); err != nil || class.BaseValue < 0 {
+		return errors.Wrapf(domain.ErrValueWrong, "%s: base value", shipClassErrorPrefix)
+	}
+
+	if class.Hyperdrive < 0 || math.IsNaN(class.Hyperdrive) {
+		return errors.Wrapf(domain.ErrHyperdriveWrong, "%s: hyperdrive rating", shipClassErrorPrefix)
+	}
+
+	// shares of depreciation are from 0 to 1
+	d := class.Depreciation
+	if d.Method >= domain.DepreciationUnknown {
+		return errors.Wrapf(domain.ErrDepreciation, "%s: method is unknown", shipClassErrorPrefix)
+	}
+	for _, share := range []domain.Decimal{d.Rate, d.Salvage, d.Damage} {
+		if share < 0 || share > domain.NewDecimal(1) {
+			return errors.Wrapf(domain.ErrDepreciation, "%s: share %s is out of range", shipClassErrorPrefix, share)
+		}
+	}
+
+	for i, a := range class.Armament {
+		class.Armament[i].Title = strings.TrimSpace(a.Title)
+		if class.Armament[i].Title == "" || a.Qty > a.Max {
+			return errors.Wrapf(domain.ErrArmamentLimit, "%s: armament %q", shipClassErrorPrefix, a.Title)
+		}
+		if a.Damage < 0 {
+			return errors.Wrapf(domain.ErrValueWrong, "%s: damage of armament %q", shipClassErrorPrefix, a.Title)
+		}
+	}
+
+	return nil
+}
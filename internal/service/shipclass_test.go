@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShipClassService_Create(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		class        *domain.ShipClass
+		expectations func(context.Context, *mocks.ShipClassRepository)
+		err          error
+	}{
+		{
+			name:  "success create",
+			class: &domain.ShipClass{Name: " Corvette ", Aliases: []string{"CR90", " "}, MinCrew: 30, MaxCrew: 165},
+			expectations: func(ctx context.Context, classRepo *mocks.ShipClassRepository) {
+				classRepo.On("Create", ctx, mock.MatchedBy(func(c *domain.ShipClass) bool {
+					return c.Name == "Corvette" && len(c.Aliases) == 1
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "failed create without name",
+			class:        &domain.ShipClass{Name: " "},
+			expectations: func(context.Context, *mocks.ShipClassRepository) {},
+			err:          domain.ErrNameRequired,
+		},
+		{
+			name:         "failed create with min crew above max",
+			class:        &domain.ShipClass{Name: "Corvette", MinCrew: 200, MaxCrew: 165},
+			expectations: func(context.Context, *mocks.ShipClassRepository) {},
+			err:          domain.ErrCrewRange,
+		},
+		{
+			name:         "failed create with default loadout above max",
+			class:        &domain.ShipClass{Name: "Corvette", Armament: []domain.ShipClassArmament{{Title: "Turbo Laser", Qty: 8, Max: 6}}},
+			expectations: func(context.Context, *mocks.ShipClassRepository) {},
+			err:          domain.ErrArmamentLimit,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		classRepo := mocks.NewShipClassRepository(t)
+		classService := NewShipClassService(classRepo, nil, nil)
+
+		test.expectations(ctx, classRepo)
+
+		err := classService.Create(ctx, test.class)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
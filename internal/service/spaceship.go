@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/imaging"
 	"github.com/pkg/errors"
 )
 
@@ -14,28 +18,90 @@ var (
 
 //go:generate mockery --dir . --name SpaceshipRepository --output ./mocks
 type SpaceshipRepository interface {
-	GetAll(context.Context) ([]*domain.Spaceship, error)
+	GetAll(context.Context, domain.SpaceshipFilter) ([]*domain.Spaceship, error)
+	// number of spaceships matching filter, page of filter is ignored
+	Count(context.Context, domain.SpaceshipFilter) (int, error)
 	GetById(context.Context, uint) (*domain.Spaceship, error)
+	// spaceship locked until transaction of Atomic ends, it is not read from cache
+	GetForUpdate(context.Context, uint) (*domain.Spaceship, error)
 	Create(context.Context, *domain.Spaceship) error
 	Update(context.Context, *domain.Spaceship) error
 	Delete(context.Context, *domain.Spaceship) error
+	GetAllFull(context.Context) ([]*domain.Spaceship, error)
+	// armaments of spaceships by id, loaded at once
+	GetArmament(context.Context, []uint) (map[uint][]domain.SpaceshipArmament, error)
+	UpdateImage(context.Context, uint, string) error
+	// run writes of function in one transaction, all of them are rolled back on error
+	Atomic(context.Context, func(context.Context) error) error
+	// valuations of spaceship from the oldest
+	GetValuations(context.Context, uint) ([]*domain.Valuation, error)
+	// record appraisal, value of spaceship is updated unless a later one is recorded
+	Appraise(context.Context, *domain.Valuation) error
+	// save valuation in history, value of spaceship is not changed
+	AddValuation(context.Context, *domain.Valuation) error
+	// set status of spaceship, change is audited
+	SetStatus(context.Context, uint, domain.SpaceshipStatus) error
+	// the latest positions of spaceship from the newest, all of them if limit is zero
+	GetPositions(context.Context, uint, int) ([]*domain.Position, error)
+	// record position in log, position of spaceship is updated unless a later one is recorded
+	ReportPosition(context.Context, *domain.Position) error
 }
 
-// spaceship service
+// storage of uploaded files
+//
+//go:generate mockery --dir . --name BlobStore --output ./mocks
+type BlobStore interface {
+	Put(context.Context, string, []byte) error
+	Get(context.Context, string) (*domain.Blob, error)
+	DeleteAll(context.Context, string) error
+}
+
+// name of uploaded image size
+const ImageSizeOriginal = "original"
+
+// thumbnails rendered on upload, max side in pixels by size name
+var imageThumbnails = map[string]int{
+	"thumb":  160,
+	"small":  480,
+	"medium": 1024,
+}
+
+// limits of uploaded images
+type ImageConfig struct {
+	MaxBytes int64
+	// max width or height, protects from decompression bombs
+	MaxSide int
+}
+
+// spaceship service, crew, work orders and missions of spaceship are changed with it in one transaction
 type SpaceshipService struct {
 	repository SpaceshipRepository
+	classes    ShipClassRepository
+	crew       CrewRepository
+	orders     WorkOrderRepository
+	missions   MissionRepository
+	blobs      BlobStore
+	index      SpaceshipIndex
+	images     ImageConfig
 }
 
-// spaceship service builder
-func NewSpaceshipService(repository SpaceshipRepository) *SpaceshipService {
-	return &SpaceshipService{repository}
+// spaceship service builder, class catalog is optional and validates spaceships if set,
+// blob store is required for images only, search index is optional and is kept up to date if set
+func NewSpaceshipService(repository SpaceshipRepository, classes ShipClassRepository, crew CrewRepository, orders WorkOrderRepository,
+	missions MissionRepository, blobs BlobStore, index SpaceshipIndex, images ImageConfig) *SpaceshipService {
+	return &SpaceshipService{repository, classes, crew, orders, missions, blobs, index, images}
 }
 
-// get list of all spaceships
-func (s *SpaceshipService) GetAll(ctx context.Context) ([]*domain.Spaceship, error) {
+// get list of all spaceships matching filter
+func (s *SpaceshipService) GetAll(ctx context.Context, filter domain.SpaceshipFilter) ([]*domain.Spaceship, error) {
+
+	filter, err := s.canonicalFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
 
 	// get all spaceships
-	spaceships, err := s.repository.GetAll(ctx)
+	spaceships, err := s.repository.GetAll(ctx, filter)
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s: get all spaceships error", spaceshipErrorPrefix)
@@ -44,6 +110,36 @@ func (s *SpaceshipService) GetAll(ctx context.Context) ([]*domain.Spaceship, err
 	return spaceships, nil
 }
 
+// number of spaceships matching filter, page of filter is ignored
+func (s *SpaceshipService) Count(ctx context.Context, filter domain.SpaceshipFilter) (int, error) {
+
+	filter, err := s.canonicalFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.repository.Count(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: count spaceships", spaceshipErrorPrefix)
+	}
+
+	return count, nil
+}
+
+// class is filtered by canonical name, so aliases match too
+func (s *SpaceshipService) canonicalFilter(ctx context.Context, filter domain.SpaceshipFilter) (domain.SpaceshipFilter, error) {
+	if s.classes != nil && filter.Class != "" {
+		class, err := s.classes.GetByName(ctx, filter.Class)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return filter, errors.Wrapf(err, "%s: get class", spaceshipErrorPrefix)
+		}
+		if class != nil {
+			filter.Class = class.Name
+		}
+	}
+	return filter, nil
+}
+
 func (s *SpaceshipService) GetById(ctx context.Context, id uint) (*domain.Spaceship, error) {
 
 	spaceship, err := s.repository.GetById(ctx, id)
@@ -54,49 +150,608 @@ func (s *SpaceshipService) GetById(ctx context.Context, id uint) (*domain.Spaces
 	return spaceship, nil
 }
 
+// get armaments of spaceships by id at once, e.g. for lists which show armament
+func (s *SpaceshipService) GetArmament(ctx context.Context, ids []uint) (map[uint][]domain.SpaceshipArmament, error) {
+
+	armaments, err := s.repository.GetArmament(ctx, ids)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get armament", spaceshipErrorPrefix)
+	}
+
+	return armaments, nil
+}
+
 // create spaceship record
 func (s *SpaceshipService) CreateSpaceship(ctx context.Context, spaceship *domain.Spaceship) error {
 
+	err := s.create(ctx, spaceship)
+	if err != nil {
+		return err
+	}
+
+	if s.index != nil {
+		s.index.Put(spaceship)
+	}
+
+	return nil
+}
+
+// validate and create spaceship record, search index is not changed
+func (s *SpaceshipService) create(ctx context.Context, spaceship *domain.Spaceship) error {
+
 	// name of spaceship is required
 	if spaceship.Name == "" {
 		return domain.ErrNameRequired
 	}
 
-	// create spaceship record in repo db
-	err := s.repository.Create(ctx, spaceship)
+	// defaults of class are used for empty fields when class is set by id
+	byID := spaceship.ClassID != 0
+	class, err := s.resolveClass(ctx, spaceship)
 	if err != nil {
 		return err
 	}
+	if class != nil && byID {
+		if len(spaceship.Armament) == 0 {
+			spaceship.Armament = class.DefaultArmament()
+		}
+		if spaceship.Crew == 0 {
+			spaceship.Crew = class.MinCrew
+		}
+		if spaceship.Value == 0 {
+			spaceship.Value, err = domain.DecimalFromFloat(class.BaseValue)
+			if err != nil {
+				return errors.Wrapf(domain.ErrValueWrong, "%s: base value of class", spaceshipErrorPrefix)
+			}
+		}
+	}
 
-	return nil
-}
+	err = checkValue(spaceship)
+	if err != nil {
+		return err
+	}
+
+	err = checkClass(spaceship, class)
+	if err != nil {
+		return err
+	}
+
+	// new spaceship has no crew members assigned yet
+	if spaceship.Status == domain.SpaceshipStatusOperational {
+		err = checkManned(class, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.repository.Atomic(ctx, func(ctx context.Context) error {
+
+		err := s.repository.Create(ctx, spaceship)
+		if err != nil {
+			return err
+		}
+
+		err = s.repository.AddValuation(ctx, &domain.Valuation{
+			SpaceshipID: spaceship.ID,
+			Amount:      spaceship.Value,
+			Currency:    spaceship.Currency,
+			Source:      domain.ValuationSourceInitial,
+			AppraisedAt: spaceship.ValuedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if spaceship.Status == domain.SpaceshipStatusDamaged {
+			return s.openDamageOrder(ctx, spaceship.ID)
+		}
 
+		return nil
+	})
+}
 
 // update spaceship record
 func (s *SpaceshipService) UpdateSpaceship(ctx context.Context, spaceship *domain.Spaceship) error {
 
+	err := s.update(ctx, spaceship)
+	if err != nil {
+		return err
+	}
+
+	if s.index != nil {
+		s.index.Put(spaceship)
+	}
+
+	return nil
+}
+
+// validate and update spaceship record, search index is not changed
+func (s *SpaceshipService) update(ctx context.Context, spaceship *domain.Spaceship) error {
+
 	// name of spaceship is required
 	if spaceship.Name == "" {
 		return domain.ErrNameRequired
 	}
 
-	// update spaceship record in repo db
-	err := s.repository.Update(ctx, spaceship)
+	class, err := s.resolveClass(ctx, spaceship)
+	if err != nil {
+		return err
+	}
+
+	err = checkValue(spaceship)
 	if err != nil {
 		return err
 	}
 
+	err = checkClass(spaceship, class)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Atomic(ctx, func(ctx context.Context) error {
+
+		// spaceship is locked, so roster is counted before concurrent assignments
+		current, err := s.repository.GetForUpdate(ctx, spaceship.ID)
+		if err != nil {
+			return err
+		}
+
+		// crew members assigned to spaceship are part of its crew
+		roster, err := s.crew.CountRoster(ctx, current.ID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: count roster", spaceshipErrorPrefix)
+		}
+		if uint(roster) > spaceship.Crew {
+			return domain.ErrCrewBelowRoster
+		}
+		if spaceship.Status == domain.SpaceshipStatusOperational {
+			err = checkManned(class, roster)
+			if err != nil {
+				return err
+			}
+		}
+
+		// damaged spaceship is repaired by work orders, closing of the last one makes it operational
+		wasDamaged := current.Status == domain.SpaceshipStatusDamaged
+		if wasDamaged && spaceship.Status == domain.SpaceshipStatusOperational {
+			open, err := s.orders.CountOpen(ctx, current.ID)
+			if err != nil {
+				return errors.Wrapf(err, "%s: count work orders", spaceshipErrorPrefix)
+			}
+			if open > 0 {
+				return domain.ErrWorkOrdersOpen
+			}
+		}
+		if !wasDamaged && spaceship.Status == domain.SpaceshipStatusDamaged {
+			err = s.openDamageOrder(ctx, current.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// changed value is valued now and kept in history
+		spaceship.ValuedAt = current.ValuedAt
+		if spaceship.Value != current.Value || spaceship.Currency != current.Currency {
+			spaceship.ValuedAt = time.Now().Unix()
+			err = s.repository.AddValuation(ctx, &domain.Valuation{
+				SpaceshipID: current.ID,
+				Amount:      spaceship.Value,
+				Currency:    spaceship.Currency,
+				Source:      domain.ValuationSourceManual,
+				AppraisedAt: spaceship.ValuedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.repository.Update(ctx, spaceship)
+	})
+}
+
+// open default work order for spaceship marked damaged
+func (s *SpaceshipService) openDamageOrder(ctx context.Context, spaceshipID uint) error {
+	return s.orders.Create(ctx, &domain.WorkOrder{
+		SpaceshipID: spaceshipID,
+		Severity:    domain.DefaultDamageSeverity,
+		Description: domain.DefaultDamageDescription,
+		OpenedAt:    time.Now().Unix(),
+	})
+}
+
+// apply patch to current spaceship, fields absent in patch are kept, spaceship is locked
+// from read to write, so concurrent patches of other fields are not lost
+func (s *SpaceshipService) PatchSpaceship(ctx context.Context, id uint, patch domain.SpaceshipPatch) (*domain.Spaceship, error) {
+
+	var spaceship *domain.Spaceship
+	err := s.repository.Atomic(ctx, func(ctx context.Context) error {
+
+		current, err := s.repository.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		update, err := patch.Update(current)
+		if err != nil {
+			return err
+		}
+		update.Apply(current)
+
+		err = s.update(ctx, current)
+		if err != nil {
+			return err
+		}
+
+		spaceship = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.index != nil {
+		s.index.Put(spaceship)
+	}
+
+	return spaceship, nil
+}
+
+// run operations of batch with the same validation as single operations, in atomic mode
+// operations are committed in one transaction and the first failed operation rolls back others,
+// otherwise every operation is independent
+func (s *SpaceshipService) Batch(ctx context.Context, operations []domain.SpaceshipOperation, atomic bool) ([]domain.SpaceshipOperationResult, error) {
+
+	if len(operations) == 0 || len(operations) > domain.SpaceshipBatchLimit {
+		return nil, errors.Wrapf(domain.ErrBatchSize, "%s: %d operations, limit is %d", spaceshipErrorPrefix, len(operations), domain.SpaceshipBatchLimit)
+	}
+
+	results := make([]domain.SpaceshipOperationResult, len(operations))
+
+	if !atomic {
+		for i, op := range operations {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err == nil {
+				results[i].Err = s.applied(ctx, op, results[i].Spaceship)
+			}
+		}
+		return results, nil
+	}
+
+	err := s.repository.Atomic(ctx, func(ctx context.Context) error {
+		for i, op := range operations {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err != nil {
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// operations around failed one are rolled back or not run,
+		// all of them fail with error of commit if no operation failed
+		failed := false
+		for _, r := range results {
+			failed = failed || r.Err != nil
+		}
+		for i := range results {
+			if results[i].Err != nil {
+				continue
+			}
+			results[i].Spaceship = nil
+			results[i].Err = err
+			if failed {
+				results[i].Err = domain.ErrBatchAborted
+			}
+		}
+		return results, nil
+	}
+
+	for i, op := range operations {
+		results[i].Err = s.applied(ctx, op, results[i].Spaceship)
+	}
+
+	return results, nil
+}
+
+// run operation of batch, search index is not changed
+func (s *SpaceshipService) apply(ctx context.Context, op domain.SpaceshipOperation) domain.SpaceshipOperationResult {
+
+	var spaceship *domain.Spaceship
+	var err error
+
+	switch op.Type {
+	case domain.SpaceshipOperationCreate, domain.SpaceshipOperationUpdate:
+		if op.Spaceship == nil {
+			return domain.SpaceshipOperationResult{Err: errors.Wrapf(domain.ErrBatchOperation, "%s: %s without spaceship", spaceshipErrorPrefix, op.Type)}
+		}
+		spaceship = op.Spaceship
+		if op.Type == domain.SpaceshipOperationCreate {
+			spaceship.ID = 0
+			err = s.create(ctx, spaceship)
+		} else {
+			spaceship.ID = op.ID
+			err = s.update(ctx, spaceship)
+		}
+	case domain.SpaceshipOperationStatus:
+		if op.Status == domain.SpaceshipStatusUndefined {
+			return domain.SpaceshipOperationResult{Err: errors.Wrapf(domain.ErrBatchOperation, "%s: status is unknown", spaceshipErrorPrefix)}
+		}
+		spaceship, err = s.repository.GetById(ctx, op.ID)
+		if err == nil {
+			spaceship.Status = op.Status
+			err = s.update(ctx, spaceship)
+		}
+	case domain.SpaceshipOperationDelete:
+		spaceship = &domain.Spaceship{ID: op.ID}
+		err = s.delete(ctx, spaceship)
+	default:
+		err = errors.Wrapf(domain.ErrBatchOperation, "%s: %s", spaceshipErrorPrefix, op.Type)
+	}
+
+	if err != nil {
+		return domain.SpaceshipOperationResult{Err: err}
+	}
+	return domain.SpaceshipOperationResult{Spaceship: spaceship}
+}
+
+// update search index and blob store after operation is committed
+func (s *SpaceshipService) applied(ctx context.Context, op domain.SpaceshipOperation, spaceship *domain.Spaceship) error {
+
+	if op.Type == domain.SpaceshipOperationDelete {
+		return s.deleted(ctx, spaceship)
+	}
+
+	if s.index != nil {
+		s.index.Put(spaceship)
+	}
+
 	return nil
 }
 
 // delete spaceship record
 func (s *SpaceshipService) DeleteSpaceship(ctx context.Context, spaceship *domain.Spaceship) error {
 
-	// delete spaceship record and all related records in repo db
-	err := s.repository.Delete(ctx, spaceship)
+	err := s.delete(ctx, spaceship)
 	if err != nil {
 		return err
 	}
 
+	return s.deleted(ctx, spaceship)
+}
+
+// delete spaceship record with all related records, search index and blob store are not changed
+func (s *SpaceshipService) delete(ctx context.Context, spaceship *domain.Spaceship) error {
+
+	return s.repository.Atomic(ctx, func(ctx context.Context) error {
+
+		// spaceship is locked, so it can't be deleted while its mission is being activated
+		_, err := s.repository.GetForUpdate(ctx, spaceship.ID)
+		if err != nil {
+			return err
+		}
+		active, err := s.missions.CountActive(ctx, spaceship.ID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: count active missions", spaceshipErrorPrefix)
+		}
+		if active > 0 {
+			return domain.ErrSpaceshipOnMission
+		}
+
+		err = s.repository.Delete(ctx, spaceship)
+		if err != nil {
+			return err
+		}
+
+		// crew stays in service without assignment
+		err = s.crew.Unassign(ctx, spaceship.ID, time.Now().Unix())
+		if err != nil {
+			return errors.Wrapf(err, "%s: unassign crew", spaceshipErrorPrefix)
+		}
+
+		err = s.missions.DeleteBySpaceship(ctx, spaceship.ID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: unassign missions", spaceshipErrorPrefix)
+		}
+
+		err = s.orders.DeleteBySpaceship(ctx, spaceship.ID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete work orders", spaceshipErrorPrefix)
+		}
+
+		return nil
+	})
+}
+
+// remove deleted spaceship from search index and blob store
+func (s *SpaceshipService) deleted(ctx context.Context, spaceship *domain.Spaceship) error {
+
+	if s.index != nil {
+		s.index.Delete(spaceship.TenantID, spaceship.ID)
+	}
+
+	// uploaded image with thumbnails
+	if s.blobs != nil {
+		err := s.blobs.DeleteAll(ctx, imagePrefix(spaceship))
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete image", spaceshipErrorPrefix)
+		}
+	}
+
+	return nil
+}
+
+// save jpeg, png or webp image with thumbnails, url is saved as spaceship image
+func (s *SpaceshipService) UploadImage(ctx context.Context, id uint, data []byte, url string) error {
+
+	if s.blobs == nil {
+		return errors.Wrapf(domain.ErrConfig, "%s: blob store is not configured", spaceshipErrorPrefix)
+	}
+
+	// spaceship of other tenant is not found
+	spaceship, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if s.images.MaxBytes > 0 && int64(len(data)) > s.images.MaxBytes {
+		return domain.ErrImageTooLarge
+	}
+
+	contentType, err := imaging.ContentType(data)
+	if err != nil {
+		return errors.Wrapf(domain.ErrImageType, "%s: %s", spaceshipErrorPrefix, err)
+	}
+
+	// size is checked before pixels are decoded
+	width, height, err := imaging.Size(data)
+	if err != nil {
+		return errors.Wrapf(domain.ErrImageType, "%s: %s", spaceshipErrorPrefix, err)
+	}
+	if s.images.MaxSide > 0 && (width > s.images.MaxSide || height > s.images.MaxSide) {
+		return domain.ErrImageTooLarge
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return errors.Wrapf(domain.ErrImageType, "%s: %s", spaceshipErrorPrefix, err)
+	}
+
+	// thumbnails are saved before original, so that original is never served without them
+	for size, side := range imageThumbnails {
+		thumbnail, err := imaging.Encode(imaging.Fit(img, side), contentType)
+		if err != nil {
+			return err
+		}
+		err = s.blobs.Put(ctx, imageKey(spaceship, size), thumbnail)
+		if err != nil {
+			return errors.Wrapf(err, "%s: save image %s", spaceshipErrorPrefix, size)
+		}
+	}
+
+	err = s.blobs.Put(ctx, imageKey(spaceship, ImageSizeOriginal), data)
+	if err != nil {
+		return errors.Wrapf(err, "%s: save image", spaceshipErrorPrefix)
+	}
+
+	return s.repository.UpdateImage(ctx, spaceship.ID, url)
+}
+
+// uploaded image of spaceship in size, original or thumbnail
+func (s *SpaceshipService) GetImage(ctx context.Context, id uint, size string) (*domain.Blob, error) {
+
+	if s.blobs == nil {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: blob store is not configured", spaceshipErrorPrefix)
+	}
+
+	if _, ok := imageThumbnails[size]; !ok && size != ImageSizeOriginal {
+		return nil, domain.ErrImageSize
+	}
+
+	spaceship, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.blobs.Get(ctx, imageKey(spaceship, size))
+}
+
+// find class of spaceship by class id or name, spaceship gets canonical class name,
+// nil is returned for class out of catalog
+func (s *SpaceshipService) resolveClass(ctx context.Context, spaceship *domain.Spaceship) (*domain.ShipClass, error) {
+
+	if s.classes == nil {
+		return nil, nil
+	}
+
+	var class *domain.ShipClass
+	var err error
+	if spaceship.ClassID != 0 {
+		class, err = s.classes.GetById(ctx, spaceship.ClassID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, errors.Wrapf(domain.ErrShipClassUnknown, "%s: class id %d", spaceshipErrorPrefix, spaceship.ClassID)
+		}
+	} else {
+		class, err = s.classes.GetByName(ctx, spaceship.Class)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get class", spaceshipErrorPrefix)
+	}
+
+	spaceship.ClassID = class.ID
+	spaceship.Class = class.Name
+
+	return class, nil
+}
+
+// value of spaceship can't be negative, empty currency is default one
+func checkValue(spaceship *domain.Spaceship) error {
+
+	if spaceship.Value < 0 {
+		return errors.Wrapf(domain.ErrValueWrong, "%s: value %s", spaceshipErrorPrefix, spaceship.Value)
+	}
+
+	spaceship.Currency = strings.ToUpper(strings.TrimSpace(spaceship.Currency))
+	if spaceship.Currency == "" {
+		spaceship.Currency = domain.DefaultCurrency
+	}
+	if !domain.ValidCurrency(spaceship.Currency) {
+		return errors.Wrapf(domain.ErrCurrencyWrong, "%s: %q", spaceshipErrorPrefix, spaceship.Currency)
+	}
+
 	return nil
 }
+
+// crew and armament of spaceship must be within limits of class,
+// minimum crew of operational spaceship is checked against its roster by checkManned
+func checkClass(spaceship *domain.Spaceship, class *domain.ShipClass) error {
+
+	if class == nil {
+		return nil
+	}
+
+	if class.MaxCrew != 0 && spaceship.Crew > class.MaxCrew {
+		return errors.Wrapf(domain.ErrCrewRange, "%s: %s allows crew up to %d", spaceshipErrorPrefix, class.Name, class.MaxCrew)
+	}
+
+	if len(class.Armament) == 0 {
+		return nil
+	}
+	for i, a := range spaceship.Armament {
+		limit, ok := class.FindArmament(a.Title)
+		if !ok {
+			return errors.Wrapf(domain.ErrArmamentLimit, "%s: %s can't carry %s", spaceshipErrorPrefix, class.Name, a.Title)
+		}
+		if a.Qty > limit.Max {
+			return errors.Wrapf(domain.ErrArmamentLimit, "%s: %s carries up to %d of %s", spaceshipErrorPrefix, class.Name, limit.Max, limit.Title)
+		}
+		spaceship.Armament[i].Title = limit.Title
+	}
+
+	return nil
+}
+
+// under-crewed spaceship of catalog class can't be operational, crew members assigned to it are counted
+func checkManned(class *domain.ShipClass, roster int) error {
+
+	if class == nil || class.MinCrew == 0 {
+		return nil
+	}
+
+	if uint(roster) < class.MinCrew {
+		return errors.Wrapf(domain.ErrUnderCrewed, "%s: %s needs crew of %d, %d assigned", spaceshipErrorPrefix,
+			class.Name, class.MinCrew, roster)
+	}
+
+	return nil
+}
+
+// blobs of spaceship are kept under tenant, so ids of tenants never collide
+func imagePrefix(spaceship *domain.Spaceship) string {
+	return fmt.Sprintf("spaceships/%d/%d", spaceship.TenantID, spaceship.ID)
+}
+
+func imageKey(spaceship *domain.Spaceship, size string) string {
+	return imagePrefix(spaceship) + "/" + size
+}
@@ -1,13 +1,18 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"image"
+	"image/png"
 	"testing"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
 	"github.com/Je33/imperial_fleet/internal/service/mocks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSpaceshipService_GetAll(t *testing.T) {
@@ -28,14 +33,14 @@ func TestSpaceshipService_GetAll(t *testing.T) {
 		{
 			name: "success get all",
 			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("GetAll", ctx).Return(spaceships, nil)
+				spaceshipRepo.On("GetAll", ctx, domain.SpaceshipFilter{}).Return(spaceships, nil)
 			},
 			err: nil,
 		},
 		{
 			name: "failed get all",
 			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("GetAll", ctx).Return(nil, domain.ErrNotFound)
+				spaceshipRepo.On("GetAll", ctx, domain.SpaceshipFilter{}).Return(nil, domain.ErrNotFound)
 			},
 			err: domain.ErrNotFound,
 		},
@@ -47,11 +52,11 @@ func TestSpaceshipService_GetAll(t *testing.T) {
 		ctx := context.Background()
 
 		spaceshipRepo := mocks.NewSpaceshipRepository(t)
-		spaceshipService := NewSpaceshipService(spaceshipRepo)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, nil, nil, nil, nil, nil, ImageConfig{})
 
 		test.expectations(ctx, spaceshipRepo)
 
-		_, err := spaceshipService.GetAll(ctx)
+		_, err := spaceshipService.GetAll(ctx, domain.SpaceshipFilter{})
 
 		if err != nil {
 			if test.err != nil {
@@ -102,7 +107,7 @@ func TestSpaceshipService_GetById(t *testing.T) {
 		ctx := context.Background()
 
 		spaceshipRepo := mocks.NewSpaceshipRepository(t)
-		spaceshipService := NewSpaceshipService(spaceshipRepo)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, nil, nil, nil, nil, nil, ImageConfig{})
 
 		test.expectations(ctx, spaceshipRepo)
 
@@ -121,32 +126,58 @@ func TestSpaceshipService_GetById(t *testing.T) {
 	}
 }
 
-func TestSpaceshipService_CreateSpaceship(t *testing.T) {
+// changes of spaceship are run in one transaction
+func atomically(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
 
-	spaceship := &domain.Spaceship{
-		ID:     1,
-		Name:   "Devastator",
-		Status: domain.SpaceshipStatusOperational,
-	}
+// spaceship is deleted with its crew assignments, missions and work orders
+func deleting(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository,
+	orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository, spaceship *domain.Spaceship) {
+	spaceshipRepo.On("GetForUpdate", ctx, spaceship.ID).Return(&domain.Spaceship{ID: spaceship.ID}, nil)
+	missionRepo.On("CountActive", ctx, spaceship.ID).Return(0, nil)
+	spaceshipRepo.On("Delete", ctx, spaceship).Return(nil)
+	crewRepo.On("Unassign", ctx, spaceship.ID, mock.AnythingOfType("int64")).Return(nil)
+	missionRepo.On("DeleteBySpaceship", ctx, spaceship.ID).Return(nil)
+	orderRepo.On("DeleteBySpaceship", ctx, spaceship.ID).Return(nil)
+}
+
+func TestSpaceshipService_CreateSpaceship(t *testing.T) {
 
 	testCases := []struct {
 		name         string
-		expectations func(context.Context, *mocks.SpaceshipRepository)
+		spaceship    *domain.Spaceship
+		expectations func(context.Context, *domain.Spaceship, *mocks.SpaceshipRepository, *mocks.WorkOrderRepository)
 		err          error
 	}{
 		{
-			name: "success create spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
+			name:      "success create spaceship",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, orderRepo *mocks.WorkOrderRepository) {
 				spaceshipRepo.On("Create", ctx, spaceship).Return(nil)
+				spaceshipRepo.On("AddValuation", ctx, mock.MatchedBy(func(v *domain.Valuation) bool {
+					return v.SpaceshipID == 1 && v.Source == domain.ValuationSourceInitial
+				})).Return(nil)
 			},
-			err: nil,
 		},
 		{
-			name: "failed create spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("Create", ctx, spaceship).Return(domain.ErrNotFound)
+			name:      "success create damaged spaceship with work order",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, orderRepo *mocks.WorkOrderRepository) {
+				spaceshipRepo.On("Create", ctx, spaceship).Return(nil)
+				spaceshipRepo.On("AddValuation", ctx, mock.Anything).Return(nil)
+				orderRepo.On("Create", ctx, mock.MatchedBy(func(o *domain.WorkOrder) bool {
+					return o.SpaceshipID == 1 && o.Severity == domain.DefaultDamageSeverity
+				})).Return(nil)
 			},
-			err: domain.ErrNotFound,
+		},
+		{
+			name:      "failed create spaceship",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, orderRepo *mocks.WorkOrderRepository) {
+				spaceshipRepo.On("Create", ctx, spaceship).Return(domain.ErrSpaceshipExists)
+			},
+			err: domain.ErrSpaceshipExists,
 		},
 	}
 
@@ -156,49 +187,168 @@ func TestSpaceshipService_CreateSpaceship(t *testing.T) {
 		ctx := context.Background()
 
 		spaceshipRepo := mocks.NewSpaceshipRepository(t)
-		spaceshipService := NewSpaceshipService(spaceshipRepo)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, nil, orderRepo, nil, nil, nil, ImageConfig{})
 
-		test.expectations(ctx, spaceshipRepo)
-
-		err := spaceshipService.CreateSpaceship(ctx, spaceship)
+		spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+		test.expectations(ctx, test.spaceship, spaceshipRepo, orderRepo)
 
-		if err != nil {
-			if test.err != nil {
-				assert.Error(t, test.err, err)
-			} else {
-				assert.NoError(t, err)
-			}
+		err := spaceshipService.CreateSpaceship(ctx, test.spaceship)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
 		}
 
 		spaceshipRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
 
 	}
 }
 
 func TestSpaceshipService_UpdateSpaceship(t *testing.T) {
 
-	spaceship := &domain.Spaceship{
-		ID:     1,
-		Name:   "Devastator",
-		Status: domain.SpaceshipStatusOperational,
+	testCases := []struct {
+		name         string
+		spaceship    *domain.Spaceship
+		current      *domain.Spaceship
+		expectations func(context.Context, *domain.Spaceship, *mocks.SpaceshipRepository, *mocks.CrewRepository, *mocks.WorkOrderRepository)
+		err          error
+	}{
+		{
+			name:      "success update spaceship",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Crew: 10, Status: domain.SpaceshipStatusOperational},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Crew: 10, Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(10, nil)
+				spaceshipRepo.On("Update", ctx, spaceship).Return(nil)
+			},
+		},
+		{
+			name:      "success update value with valuation",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Value: domain.NewDecimal(1500), Status: domain.SpaceshipStatusOperational},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Value: domain.NewDecimal(1000), Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				spaceshipRepo.On("AddValuation", ctx, mock.MatchedBy(func(v *domain.Valuation) bool {
+					return v.Amount == domain.NewDecimal(1500) && v.Source == domain.ValuationSourceManual
+				})).Return(nil)
+				spaceshipRepo.On("Update", ctx, spaceship).Return(nil)
+			},
+		},
+		{
+			name:      "success update damaged spaceship with work order",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusDamaged},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				orderRepo.On("Create", ctx, mock.MatchedBy(func(o *domain.WorkOrder) bool {
+					return o.SpaceshipID == 1 && o.Severity == domain.DefaultDamageSeverity
+				})).Return(nil)
+				spaceshipRepo.On("Update", ctx, spaceship).Return(nil)
+			},
+		},
+		{
+			name:      "failed update crew below roster",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Crew: 2, Status: domain.SpaceshipStatusOperational},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Crew: 10, Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(3, nil)
+			},
+			err: domain.ErrCrewBelowRoster,
+		},
+		{
+			name:      "failed repair with open work orders",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusOperational},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				orderRepo.On("CountOpen", ctx, uint(1)).Return(1, nil)
+			},
+			err: domain.ErrWorkOrdersOpen,
+		},
+		{
+			name:      "failed update spaceship",
+			spaceship: &domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusOperational},
+			current:   &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+			expectations: func(ctx context.Context, spaceship *domain.Spaceship, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository) {
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				spaceshipRepo.On("Update", ctx, spaceship).Return(domain.ErrSpaceshipExists)
+			},
+			err: domain.ErrSpaceshipExists,
+		},
 	}
 
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		crewRepo := mocks.NewCrewRepository(t)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, orderRepo, nil, nil, nil, ImageConfig{})
+
+		spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+		spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(test.current, nil)
+		test.expectations(ctx, test.spaceship, spaceshipRepo, crewRepo, orderRepo)
+
+		err := spaceshipService.UpdateSpaceship(ctx, test.spaceship)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		spaceshipRepo.AssertExpectations(t)
+		crewRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+
+	}
+}
+
+func TestSpaceshipService_PatchSpaceship(t *testing.T) {
+
+	crew := uint(0)
+	name := ""
+
 	testCases := []struct {
 		name         string
-		expectations func(context.Context, *mocks.SpaceshipRepository)
+		patch        *domain.SpaceshipUpdate
+		expectations func(context.Context, *mocks.SpaceshipRepository, *mocks.CrewRepository)
 		err          error
 	}{
 		{
-			name: "success update spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("Update", ctx, spaceship).Return(nil)
+			name:  "success patch crew to zero",
+			patch: &domain.SpaceshipUpdate{Crew: &crew},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator", Crew: 35000, Status: domain.SpaceshipStatusOperational}, nil)
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				spaceshipRepo.On("Update", ctx, &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational}).Return(nil)
 			},
-			err: nil,
 		},
 		{
-			name: "failed update spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("Update", ctx, spaceship).Return(domain.ErrNotFound)
+			name:  "failed patch crew below roster",
+			patch: &domain.SpaceshipUpdate{Crew: &crew},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator", Crew: 35000, Status: domain.SpaceshipStatusOperational}, nil)
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(1, nil)
+			},
+			err: domain.ErrCrewBelowRoster,
+		},
+		{
+			name:  "failed patch name to empty",
+			patch: &domain.SpaceshipUpdate{Name: &name},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator"}, nil)
+			},
+			err: domain.ErrNameRequired,
+		},
+		{
+			name:  "failed patch unknown spaceship",
+			patch: &domain.SpaceshipUpdate{Crew: &crew},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(nil, domain.ErrNotFound)
 			},
 			err: domain.ErrNotFound,
 		},
@@ -210,22 +360,136 @@ func TestSpaceshipService_UpdateSpaceship(t *testing.T) {
 		ctx := context.Background()
 
 		spaceshipRepo := mocks.NewSpaceshipRepository(t)
-		spaceshipService := NewSpaceshipService(spaceshipRepo)
+		crewRepo := mocks.NewCrewRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, nil, nil, nil, nil, ImageConfig{})
 
-		test.expectations(ctx, spaceshipRepo)
+		spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+		test.expectations(ctx, spaceshipRepo, crewRepo)
 
-		err := spaceshipService.UpdateSpaceship(ctx, spaceship)
+		_, err := spaceshipService.PatchSpaceship(ctx, 1, test.patch)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
 
-		if err != nil {
-			if test.err != nil {
-				assert.Error(t, test.err, err)
+func TestSpaceshipService_Batch(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		operations   []domain.SpaceshipOperation
+		atomic       bool
+		expectations func(context.Context, *mocks.SpaceshipRepository, *mocks.CrewRepository, *mocks.WorkOrderRepository, *mocks.MissionRepository)
+		errs         []error
+		err          error
+	}{
+		{
+			name: "success independent operations with failed one",
+			operations: []domain.SpaceshipOperation{
+				{Type: domain.SpaceshipOperationStatus, ID: 1, Status: domain.SpaceshipStatusDamaged},
+				{Type: domain.SpaceshipOperationCreate, Spaceship: &domain.Spaceship{}},
+				{Type: domain.SpaceshipOperationDelete, ID: 2},
+			},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+				spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator", Status: domain.SpaceshipStatusOperational}, nil)
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational}, nil)
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				orderRepo.On("Create", ctx, mock.AnythingOfType("*domain.WorkOrder")).Return(nil)
+				spaceshipRepo.On("Update", ctx, &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusDamaged}).Return(nil)
+				deleting(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo, &domain.Spaceship{ID: 2})
+			},
+			errs: []error{nil, domain.ErrNameRequired, nil},
+		},
+		{
+			name: "failed atomic operations are rolled back",
+			operations: []domain.SpaceshipOperation{
+				{Type: domain.SpaceshipOperationDelete, ID: 2},
+				{Type: domain.SpaceshipOperationStatus, ID: 1},
+				{Type: domain.SpaceshipOperationDelete, ID: 3},
+			},
+			atomic: true,
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+				deleting(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo, &domain.Spaceship{ID: 2})
+			},
+			errs: []error{domain.ErrBatchAborted, domain.ErrBatchOperation, domain.ErrBatchAborted},
+		},
+		{
+			name: "failed delete of spaceship on mission",
+			operations: []domain.SpaceshipOperation{
+				{Type: domain.SpaceshipOperationDelete, ID: 2},
+			},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+				spaceshipRepo.On("GetForUpdate", ctx, uint(2)).Return(&domain.Spaceship{ID: 2}, nil)
+				missionRepo.On("CountActive", ctx, uint(2)).Return(1, nil)
+			},
+			errs: []error{domain.ErrSpaceshipOnMission},
+		},
+		{
+			name: "success atomic operations",
+			operations: []domain.SpaceshipOperation{
+				{Type: domain.SpaceshipOperationCreate, Spaceship: &domain.Spaceship{Name: "Executor"}},
+				{Type: domain.SpaceshipOperationUpdate, ID: 1, Spaceship: &domain.Spaceship{Name: "Devastator"}},
+			},
+			atomic: true,
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+				spaceshipRepo.On("Create", ctx, &domain.Spaceship{Name: "Executor", Currency: domain.DefaultCurrency}).Return(nil)
+				spaceshipRepo.On("AddValuation", ctx, mock.AnythingOfType("*domain.Valuation")).Return(nil)
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency}, nil)
+				crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+				spaceshipRepo.On("Update", ctx, &domain.Spaceship{ID: 1, Name: "Devastator", Currency: domain.DefaultCurrency}).Return(nil)
+			},
+			errs: []error{nil, nil},
+		},
+		{
+			name: "failed empty batch",
+			expectations: func(context.Context, *mocks.SpaceshipRepository, *mocks.CrewRepository, *mocks.WorkOrderRepository, *mocks.MissionRepository) {
+			},
+			err: domain.ErrBatchSize,
+		},
+		{
+			name:       "failed batch over limit",
+			operations: make([]domain.SpaceshipOperation, domain.SpaceshipBatchLimit+1),
+			expectations: func(context.Context, *mocks.SpaceshipRepository, *mocks.CrewRepository, *mocks.WorkOrderRepository, *mocks.MissionRepository) {
+			},
+			err: domain.ErrBatchSize,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		crewRepo := mocks.NewCrewRepository(t)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		missionRepo := mocks.NewMissionRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, orderRepo, missionRepo, nil, nil, ImageConfig{})
+
+		test.expectations(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo)
+
+		results, err := spaceshipService.Batch(ctx, test.operations, test.atomic)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Len(t, results, len(test.errs))
+		for i, r := range results {
+			if test.errs[i] != nil {
+				assert.ErrorIs(t, r.Err, test.errs[i])
+				assert.Nil(t, r.Spaceship)
 			} else {
-				assert.NoError(t, err)
+				assert.NoError(t, r.Err)
+				assert.NotNil(t, r.Spaceship)
 			}
 		}
-
-		spaceshipRepo.AssertExpectations(t)
-
 	}
 }
 
@@ -239,20 +503,28 @@ func TestSpaceshipService_DeleteSpaceship(t *testing.T) {
 
 	testCases := []struct {
 		name         string
-		expectations func(context.Context, *mocks.SpaceshipRepository)
+		expectations func(context.Context, *mocks.SpaceshipRepository, *mocks.CrewRepository, *mocks.WorkOrderRepository, *mocks.MissionRepository)
 		err          error
 	}{
 		{
 			name: "success delete spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("Delete", ctx, spaceship).Return(nil)
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				deleting(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo, spaceship)
 			},
 			err: nil,
 		},
+		{
+			name: "failed delete spaceship on mission",
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(spaceship, nil)
+				missionRepo.On("CountActive", ctx, uint(1)).Return(1, nil)
+			},
+			err: domain.ErrSpaceshipOnMission,
+		},
 		{
 			name: "failed delete spaceship",
-			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository) {
-				spaceshipRepo.On("Delete", ctx, spaceship).Return(domain.ErrNotFound)
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository, orderRepo *mocks.WorkOrderRepository, missionRepo *mocks.MissionRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(nil, domain.ErrNotFound)
 			},
 			err: domain.ErrNotFound,
 		},
@@ -264,21 +536,285 @@ func TestSpaceshipService_DeleteSpaceship(t *testing.T) {
 		ctx := context.Background()
 
 		spaceshipRepo := mocks.NewSpaceshipRepository(t)
-		spaceshipService := NewSpaceshipService(spaceshipRepo)
+		crewRepo := mocks.NewCrewRepository(t)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		missionRepo := mocks.NewMissionRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, orderRepo, missionRepo, nil, nil, ImageConfig{})
 
-		test.expectations(ctx, spaceshipRepo)
+		spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+		test.expectations(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo)
 
 		err := spaceshipService.DeleteSpaceship(ctx, spaceship)
-
-		if err != nil {
-			if test.err != nil {
-				assert.Error(t, test.err, err)
-			} else {
-				assert.NoError(t, err)
-			}
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
 		}
 
 		spaceshipRepo.AssertExpectations(t)
+		crewRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+		missionRepo.AssertExpectations(t)
 
 	}
 }
+
+func TestSpaceshipService_DeleteSpaceshipImage(t *testing.T) {
+
+	ctx := context.Background()
+	spaceship := &domain.Spaceship{ID: 1, TenantID: 2}
+
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	crewRepo := mocks.NewCrewRepository(t)
+	orderRepo := mocks.NewWorkOrderRepository(t)
+	missionRepo := mocks.NewMissionRepository(t)
+	blobs := mocks.NewBlobStore(t)
+	spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, orderRepo, missionRepo, blobs, nil, ImageConfig{})
+
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+	deleting(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo, spaceship)
+	blobs.On("DeleteAll", ctx, "spaceships/2/1").Return(nil)
+
+	err := spaceshipService.DeleteSpaceship(ctx, spaceship)
+	assert.NoError(t, err)
+}
+
+func TestSpaceshipService_UploadImage(t *testing.T) {
+
+	spaceship := &domain.Spaceship{ID: 1, TenantID: 2}
+
+	// png of 400x200 px
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 200)))
+	assert.NoError(t, err)
+	pngData := buf.Bytes()
+
+	testCases := []struct {
+		name         string
+		data         []byte
+		config       ImageConfig
+		expectations func(context.Context, *mocks.SpaceshipRepository, *mocks.BlobStore)
+		err          error
+	}{
+		{
+			name:   "success upload image",
+			data:   pngData,
+			config: ImageConfig{MaxBytes: 1 << 20, MaxSide: 1000},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, blobs *mocks.BlobStore) {
+				spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(spaceship, nil)
+				for _, size := range []string{"thumb", "small", "medium"} {
+					blobs.On("Put", ctx, "spaceships/2/1/"+size, mock.AnythingOfType("[]uint8")).Return(nil)
+				}
+				blobs.On("Put", ctx, "spaceships/2/1/original", pngData).Return(nil)
+				spaceshipRepo.On("UpdateImage", ctx, spaceship.ID, "/image").Return(nil)
+			},
+		},
+		{
+			name:   "failed upload too many bytes",
+			data:   pngData,
+			config: ImageConfig{MaxBytes: 10},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, blobs *mocks.BlobStore) {
+				spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(spaceship, nil)
+			},
+			err: domain.ErrImageTooLarge,
+		},
+		{
+			name:   "failed upload too many pixels",
+			data:   pngData,
+			config: ImageConfig{MaxSide: 300},
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, blobs *mocks.BlobStore) {
+				spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(spaceship, nil)
+			},
+			err: domain.ErrImageTooLarge,
+		},
+		{
+			name: "failed upload not image",
+			data: []byte("GIF89a not supported"),
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, blobs *mocks.BlobStore) {
+				spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(spaceship, nil)
+			},
+			err: domain.ErrImageType,
+		},
+		{
+			name: "failed upload spaceship not found",
+			data: pngData,
+			expectations: func(ctx context.Context, spaceshipRepo *mocks.SpaceshipRepository, blobs *mocks.BlobStore) {
+				spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		blobs := mocks.NewBlobStore(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, nil, nil, nil, nil, blobs, nil, test.config)
+
+		test.expectations(ctx, spaceshipRepo, blobs)
+
+		err := spaceshipService.UploadImage(ctx, spaceship.ID, test.data, "/image")
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestSpaceshipService_GetImage(t *testing.T) {
+
+	ctx := context.Background()
+	spaceship := &domain.Spaceship{ID: 1, TenantID: 2}
+	thumb := &domain.Blob{Key: "spaceships/2/1/thumb"}
+
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	blobs := mocks.NewBlobStore(t)
+	spaceshipService := NewSpaceshipService(spaceshipRepo, nil, nil, nil, nil, blobs, nil, ImageConfig{})
+
+	spaceshipRepo.On("GetById", ctx, spaceship.ID).Return(spaceship, nil)
+	blobs.On("Get", ctx, thumb.Key).Return(thumb, nil)
+
+	blob, err := spaceshipService.GetImage(ctx, spaceship.ID, "thumb")
+	assert.NoError(t, err)
+	assert.Equal(t, thumb, blob)
+
+	_, err = spaceshipService.GetImage(ctx, spaceship.ID, "huge")
+	assert.ErrorIs(t, err, domain.ErrImageSize)
+}
+
+func TestSpaceshipService_Index(t *testing.T) {
+
+	ctx := context.Background()
+	spaceship := &domain.Spaceship{ID: 1, TenantID: 2, Name: "Devastator"}
+
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	crewRepo := mocks.NewCrewRepository(t)
+	orderRepo := mocks.NewWorkOrderRepository(t)
+	missionRepo := mocks.NewMissionRepository(t)
+	index := mocks.NewSpaceshipIndex(t)
+	spaceshipService := NewSpaceshipService(spaceshipRepo, nil, crewRepo, orderRepo, missionRepo, nil, index, ImageConfig{})
+
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+	spaceshipRepo.On("Create", ctx, spaceship).Return(nil)
+	spaceshipRepo.On("AddValuation", ctx, mock.AnythingOfType("*domain.Valuation")).Return(nil)
+	spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, TenantID: 2, Name: "Devastator", Currency: domain.DefaultCurrency}, nil).Once()
+	crewRepo.On("CountRoster", ctx, uint(1)).Return(0, nil)
+	spaceshipRepo.On("Update", ctx, spaceship).Return(nil)
+	deleting(ctx, spaceshipRepo, crewRepo, orderRepo, missionRepo, spaceship)
+	index.On("Put", spaceship).Return().Twice()
+	index.On("Delete", uint(2), uint(1)).Return().Once()
+
+	assert.NoError(t, spaceshipService.CreateSpaceship(ctx, spaceship))
+	assert.NoError(t, spaceshipService.UpdateSpaceship(ctx, spaceship))
+	assert.NoError(t, spaceshipService.DeleteSpaceship(ctx, spaceship))
+
+	// index is not changed by failed update
+	spaceshipRepo.On("GetForUpdate", ctx, uint(3)).Return(nil, domain.ErrNotFound)
+	assert.Error(t, spaceshipService.UpdateSpaceship(ctx, &domain.Spaceship{ID: 3, Name: "Avenger"}))
+}
+
+func TestSpaceshipService_Class(t *testing.T) {
+
+	starDestroyer := &domain.ShipClass{
+		ID:        1,
+		Name:      "Star Destroyer",
+		Aliases:   []string{"ISD"},
+		Armament:  []domain.ShipClassArmament{{Title: "Turbo Laser", Qty: 60, Max: 60}, {Title: "Tractor Beam", Qty: 10, Max: 10}},
+		MinCrew:   9000,
+		MaxCrew:   50000,
+		BaseValue: 1500,
+	}
+
+	testCases := []struct {
+		name      string
+		spaceship *domain.Spaceship
+		expected  *domain.Spaceship
+		err       error
+	}{
+		{
+			name:      "success create with defaults of class",
+			spaceship: &domain.Spaceship{Name: "Devastator", ClassID: 1, Status: domain.SpaceshipStatusDamaged},
+			expected: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", ClassID: 1, Crew: 9000, Value: domain.NewDecimal(1500), Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusDamaged,
+				Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}, {Title: "Tractor Beam", Qty: 10}}},
+		},
+		{
+			name: "success create with alias of class",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: " isd ", Crew: 37085, Status: domain.SpaceshipStatusDamaged,
+				Armament: []domain.SpaceshipArmament{{Title: "turbo laser", Qty: 40}}},
+			expected: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", ClassID: 1, Crew: 37085, Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusDamaged,
+				Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 40}}},
+		},
+		{
+			name:      "success create of class out of catalog without limits",
+			spaceship: &domain.Spaceship{Name: "Tydirium", Class: "Shuttle", Crew: 1000000, Status: domain.SpaceshipStatusOperational},
+			expected:  &domain.Spaceship{Name: "Tydirium", Class: "Shuttle", Crew: 1000000, Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusOperational},
+		},
+		{
+			name:      "success create damaged under-crewed",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 100, Status: domain.SpaceshipStatusDamaged},
+			expected:  &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", ClassID: 1, Crew: 100, Currency: domain.DefaultCurrency, Status: domain.SpaceshipStatusDamaged},
+		},
+		{
+			name:      "failed create operational without assigned crew",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 37085, Status: domain.SpaceshipStatusOperational},
+			err:       domain.ErrUnderCrewed,
+		},
+		{
+			name:      "failed create over-crewed",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 60000, Status: domain.SpaceshipStatusDamaged},
+			err:       domain.ErrCrewRange,
+		},
+		{
+			name: "failed create with armament out of class",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 100, Status: domain.SpaceshipStatusDamaged,
+				Armament: []domain.SpaceshipArmament{{Title: "Death Star Laser", Qty: 1}}},
+			err: domain.ErrArmamentLimit,
+		},
+		{
+			name: "failed create with armament over limit",
+			spaceship: &domain.Spaceship{Name: "Devastator", Class: "Star Destroyer", Crew: 100, Status: domain.SpaceshipStatusDamaged,
+				Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 61}}},
+			err: domain.ErrArmamentLimit,
+		},
+		{
+			name:      "failed create with unknown class id",
+			spaceship: &domain.Spaceship{Name: "Devastator", ClassID: 2, Status: domain.SpaceshipStatusDamaged},
+			err:       domain.ErrShipClassUnknown,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		classRepo := mocks.NewShipClassRepository(t)
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		spaceshipService := NewSpaceshipService(spaceshipRepo, classRepo, nil, orderRepo, nil, nil, nil, ImageConfig{})
+
+		classRepo.On("GetById", ctx, uint(1)).Return(starDestroyer, nil).Maybe()
+		classRepo.On("GetById", ctx, uint(2)).Return(nil, domain.ErrNotFound).Maybe()
+		classRepo.On("GetByName", ctx, mock.MatchedBy(func(name string) bool {
+			return domain.ShipClassKey(name) == "star destroyer" || domain.ShipClassKey(name) == "isd"
+		})).Return(starDestroyer, nil).Maybe()
+		classRepo.On("GetByName", ctx, mock.Anything).Return(nil, domain.ErrNotFound).Maybe()
+		if test.err == nil {
+			spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+			spaceshipRepo.On("Create", ctx, test.expected).Return(nil)
+			spaceshipRepo.On("AddValuation", ctx, mock.AnythingOfType("*domain.Valuation")).Return(nil)
+			orderRepo.On("Create", ctx, mock.AnythingOfType("*domain.WorkOrder")).Return(nil).Maybe()
+		}
+
+		err := spaceshipService.CreateSpaceship(ctx, test.spaceship)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
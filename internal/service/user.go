@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
@@ -14,78 +15,265 @@ var (
 	userErrorPrefix = "[service.user]"
 )
 
+// bcrypt hash with the same cost as user passwords, compared when user
+// is not found to make auth timing equal for existing and unknown users
+const dummyPasswordHash = "$2a$14$yar6jY8fAfR85i0.KmVH1OFkkfuJLGr2o5uMPu3p7Iae2xspPIpAu"
+
 //go:generate mockery --dir . --name UserRepository --output ./mocks
 type UserRepository interface {
+	GetAll(context.Context) ([]*domain.User, error)
+	GetById(context.Context, uint) (*domain.User, error)
 	GetByEmail(context.Context, string) (*domain.User, error)
 	Create(context.Context, *domain.User) (*domain.User, error)
+	Update(context.Context, *domain.User) (*domain.User, error)
+	UpdateTOTP(context.Context, *domain.User) error
+}
+
+// progressive lockout of accounts after failed logins
+//
+//go:generate mockery --dir . --name LoginLockout --output ./mocks
+type LoginLockout interface {
+	Check(string) time.Duration
+	Fail(string)
+	Success(string)
 }
 
 // user service
 type UserService struct {
 	repository UserRepository
+	lockout    LoginLockout
 }
 
-// user service builder
-func NewUserService(repository UserRepository) *UserService {
-	return &UserService{repository}
+// user service builder, lockout is optional
+func NewUserService(repository UserRepository, lockout LoginLockout) *UserService {
+	return &UserService{repository, lockout}
 }
 
 // user registration
-func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterReq) error {
+func (s *UserService) Register(ctx context.Context, req *domain.UserRegisterReq) (*domain.User, error) {
 
 	// required fields
 	if req.Email == "" || req.Password == "" {
-		return domain.ErrRegRequiredFields
+		return nil, domain.ErrRegRequiredFields
 	}
 
 	// if repassword and password are not match
 	if req.Password != req.RePassword {
-		return domain.ErrRePasswordWrong
+		return nil, domain.ErrRePasswordWrong
 	}
 
 	_, err := s.repository.GetByEmail(ctx, req.Email)
 
 	// if user exists
 	if !errors.Is(err, domain.ErrNotFound) {
-		return domain.ErrUserExists
+		return nil, domain.ErrUserExists
 	}
 
 	// encode password
-	passwordBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
+	passwordHash, err := hashPassword(req.Password)
 	if err != nil {
-		return errors.Wrapf(err, "%s: encode password error", userErrorPrefix)
+		return nil, err
 	}
 
 	// save user
 	newUser := &domain.User{
 		Email:     req.Email,
-		Password:  string(passwordBytes),
+		Password:  passwordHash,
+		Role:      domain.UserRoleUser,
 		CreatedAt: time.Now().Unix(),
 		UpdatedAt: time.Now().Unix(),
 	}
-	_, err = s.repository.Create(ctx, newUser)
+	user, err := s.repository.Create(ctx, newUser)
 	if err != nil {
-		return errors.Wrapf(err, "%s: repo save error", userErrorPrefix)
+		return nil, errors.Wrapf(err, "%s: repo save error", userErrorPrefix)
 	}
 
-	return nil
+	return user, nil
 }
 
-func (s *UserService) Auth(ctx context.Context, req *domain.UserAuthReq) error {
+// user authorization, unknown user and wrong password give the same error
+func (s *UserService) Auth(ctx context.Context, req *domain.UserAuthReq) (*domain.User, error) {
+
+	// lockout is keyed by email whether account exists or not
+	lockoutKey := strings.ToLower(req.Email)
+	if s.lockout != nil {
+		if after := s.lockout.Check(lockoutKey); after > 0 {
+			return nil, &domain.RetryAfterError{Err: domain.ErrLoginLocked, After: after}
+		}
+	}
 
 	// find user and compare password
 	user, err := s.repository.GetByEmail(ctx, req.Email)
 
-	// if user not exists or other errors
+	// other errors than not found are returned as is
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	// password is compared with dummy hash for unknown user
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = user.Password
+	}
+	errCompare := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password))
+
+	if err != nil || errCompare != nil {
+		if s.lockout != nil {
+			s.lockout.Fail(lockoutKey)
+		}
+		return nil, domain.ErrAuthFailed
+	}
+
+	if s.lockout != nil {
+		s.lockout.Success(lockoutKey)
+	}
+
+	return user, nil
+}
+
+// reload user of refresh token, deleted user can't refresh tokens anymore
+func (s *UserService) Refresh(ctx context.Context, userID uint) (*domain.User, error) {
+
+	user, err := s.repository.GetById(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrAuthFailed
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: refresh", userErrorPrefix)
+	}
+
+	return user, nil
+}
+
+// get list of all users
+func (s *UserService) GetAll(ctx context.Context) ([]*domain.User, error) {
+
+	users, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all users error", userErrorPrefix)
+	}
+
+	return users, nil
+}
+
+// user creation by operator, bypasses repassword check and allows to set role
+func (s *UserService) Create(ctx context.Context, req *domain.UserCreateReq) (*domain.User, error) {
+
+	// required fields
+	if req.Email == "" || req.Password == "" {
+		return nil, domain.ErrRegRequiredFields
+	}
+
+	if req.Role == domain.UserRoleUndefined {
+		return nil, domain.ErrRoleWrong
+	}
+
+	_, err := s.repository.GetByEmail(ctx, req.Email)
+
+	// if user exists
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUserExists
+	}
+
+	// encode password
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// save user, email of user created by operator is trusted
+	newUser := &domain.User{
+		Email:           req.Email,
+		Password:        passwordHash,
+		Role:            req.Role,
+		EmailVerifiedAt: time.Now().Unix(),
+		CreatedAt:       time.Now().Unix(),
+		UpdatedAt:       time.Now().Unix(),
+	}
+	user, err := s.repository.Create(ctx, newUser)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: repo save error", userErrorPrefix)
+	}
+
+	return user, nil
+}
+
+// set new password for existing user
+func (s *UserService) SetPassword(ctx context.Context, email string, password string) error {
+
+	if password == "" {
+		return domain.ErrRegRequiredFields
+	}
+
+	user, err := s.repository.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	// encode password
+	user.Password, err = hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.UpdatedAt = time.Now().Unix()
+
+	_, err = s.repository.Update(ctx, user)
+	if err != nil {
+		return errors.Wrapf(err, "%s: repo update error", userErrorPrefix)
+	}
+
+	return nil
+}
+
+// set role for existing user
+func (s *UserService) SetRole(ctx context.Context, email string, role domain.UserRole) error {
+
+	if role == domain.UserRoleUndefined {
+		return domain.ErrRoleWrong
+	}
+
+	user, err := s.repository.GetByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
 
-	// compare passwords
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	user.Role = role
+	user.UpdatedAt = time.Now().Unix()
+
+	_, err = s.repository.Update(ctx, user)
 	if err != nil {
-		return domain.ErrPasswordWrong
+		return errors.Wrapf(err, "%s: repo update error", userErrorPrefix)
 	}
 
 	return nil
 }
+
+// turn off mfa of user who lost authenticator, allowed to operator only
+func (s *UserService) ResetMFA(ctx context.Context, email string) error {
+
+	user, err := s.repository.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = 0
+	user.TOTPCounter = 0
+	user.UpdatedAt = time.Now().Unix()
+
+	err = s.repository.UpdateTOTP(ctx, user)
+	if err != nil {
+		return errors.Wrapf(err, "%s: repo update totp error", userErrorPrefix)
+	}
+
+	return nil
+}
+
+// encode password with bcrypt
+func hashPassword(password string) (string, error) {
+	passwordBytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: encode password error", userErrorPrefix)
+	}
+	return string(passwordBytes), nil
+}
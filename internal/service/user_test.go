@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
 	"github.com/Je33/imperial_fleet/internal/service/mocks"
@@ -90,11 +91,11 @@ func TestUserService_Register(t *testing.T) {
 		ctx := context.Background()
 
 		userRepo := mocks.NewUserRepository(t)
-		userService := NewUserService(userRepo)
+		userService := NewUserService(userRepo, nil)
 
 		test.expectations(ctx, userRepo)
 
-		err := userService.Register(ctx, test.input)
+		_, err := userService.Register(ctx, test.input)
 
 		if err != nil {
 			if test.err != nil {
@@ -118,23 +119,25 @@ func TestUserService_Auth(t *testing.T) {
 
 	testCases := []struct {
 		name         string
-		expectations func(context.Context, *mocks.UserRepository)
+		expectations func(context.Context, *mocks.UserRepository, *mocks.LoginLockout)
 		input        *domain.UserAuthReq
 		err          error
 	}{
 		{
 			name: "success auth",
 			input: &domain.UserAuthReq{
-				Email:    "test@test.com",
+				Email:    "Test@test.com",
 				Password: "123123",
 			},
-			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
-				userRepo.On("GetByEmail", ctx, userAuthReq.Email).Return(&domain.User{
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", userAuthReq.Email).Return(time.Duration(0))
+				userRepo.On("GetByEmail", ctx, "Test@test.com").Return(&domain.User{
 					Email:     "test@test.com",
 					Password:  "$2a$14$yar6jY8fAfR85i0.KmVH1OFkkfuJLGr2o5uMPu3p7Iae2xspPIpAu",
 					CreatedAt: 1,
 					UpdatedAt: 1,
 				}, nil)
+				lockout.On("Success", userAuthReq.Email).Return()
 			},
 			err: nil,
 		},
@@ -144,15 +147,17 @@ func TestUserService_Auth(t *testing.T) {
 				Email:    "test@test.com",
 				Password: "123123",
 			},
-			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", userAuthReq.Email).Return(time.Duration(0))
 				userRepo.On("GetByEmail", ctx, userAuthReq.Email).Return(&domain.User{
 					Email:     "test@test.com",
 					Password:  "$2a$$yar6jY8fAfR85i0.KmVH1OFkkfuJLGr2o5uMPu3p7Iae2xspPIpAu",
 					CreatedAt: 1,
 					UpdatedAt: 1,
 				}, nil)
+				lockout.On("Fail", userAuthReq.Email).Return()
 			},
-			err: domain.ErrPasswordWrong,
+			err: domain.ErrAuthFailed,
 		},
 		{
 			name: "failed auth user not found",
@@ -160,8 +165,158 @@ func TestUserService_Auth(t *testing.T) {
 				Email:    "test@test.com",
 				Password: "123123",
 			},
-			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", userAuthReq.Email).Return(time.Duration(0))
 				userRepo.On("GetByEmail", ctx, userAuthReq.Email).Return(nil, domain.ErrNotFound)
+				lockout.On("Fail", userAuthReq.Email).Return()
+			},
+			err: domain.ErrAuthFailed,
+		},
+		{
+			name: "failed auth locked",
+			input: &domain.UserAuthReq{
+				Email:    "test@test.com",
+				Password: "123123",
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", userAuthReq.Email).Return(time.Minute)
+			},
+			err: &domain.RetryAfterError{Err: domain.ErrLoginLocked, After: time.Minute},
+		},
+		{
+			name: "failed auth repo error",
+			input: &domain.UserAuthReq{
+				Email:    "test@test.com",
+				Password: "123123",
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, lockout *mocks.LoginLockout) {
+				lockout.On("Check", userAuthReq.Email).Return(time.Duration(0))
+				userRepo.On("GetByEmail", ctx, userAuthReq.Email).Return(nil, domain.ErrConfig)
+			},
+			err: domain.ErrConfig,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		lockout := mocks.NewLoginLockout(t)
+		userService := NewUserService(userRepo, lockout)
+
+		test.expectations(ctx, userRepo, lockout)
+
+		_, err := userService.Auth(ctx, test.input)
+
+		assert.Equal(t, test.err, err)
+
+		userRepo.AssertExpectations(t)
+		lockout.AssertExpectations(t)
+
+	}
+}
+
+func TestUserService_Create(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository)
+		input        *domain.UserCreateReq
+		err          error
+	}{
+		{
+			name: "success create",
+			input: &domain.UserCreateReq{
+				Email:    "test@test.com",
+				Password: "123123",
+				Role:     domain.UserRoleAdmin,
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(nil, domain.ErrNotFound)
+				userRepo.On("Create", ctx, mock.MatchedBy(func(u *domain.User) bool {
+					return u.Role == domain.UserRoleAdmin
+				})).Return(&domain.User{}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed create role undefined",
+			input: &domain.UserCreateReq{
+				Email:    "test@test.com",
+				Password: "123123",
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				//
+			},
+			err: domain.ErrRoleWrong,
+		},
+		{
+			name: "failed create user exists",
+			input: &domain.UserCreateReq{
+				Email:    "test@test.com",
+				Password: "123123",
+				Role:     domain.UserRoleUser,
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(&domain.User{}, nil)
+			},
+			err: domain.ErrUserExists,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, nil)
+
+		test.expectations(ctx, userRepo)
+
+		_, err := userService.Create(ctx, test.input)
+
+		assert.Equal(t, test.err, err)
+
+		userRepo.AssertExpectations(t)
+
+	}
+}
+
+func TestUserService_SetRole(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository)
+		role         domain.UserRole
+		err          error
+	}{
+		{
+			name: "success set role",
+			role: domain.UserRoleOfficer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(&domain.User{ID: 1}, nil)
+				userRepo.On("Update", ctx, mock.MatchedBy(func(u *domain.User) bool {
+					return u.ID == 1 && u.Role == domain.UserRoleOfficer
+				})).Return(&domain.User{}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed set role undefined",
+			role: domain.UserRoleUndefined,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				//
+			},
+			err: domain.ErrRoleWrong,
+		},
+		{
+			name: "failed set role user not found",
+			role: domain.UserRoleOfficer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByEmail", ctx, "test@test.com").Return(nil, domain.ErrNotFound)
 			},
 			err: domain.ErrNotFound,
 		},
@@ -173,21 +328,56 @@ func TestUserService_Auth(t *testing.T) {
 		ctx := context.Background()
 
 		userRepo := mocks.NewUserRepository(t)
-		userService := NewUserService(userRepo)
+		userService := NewUserService(userRepo, nil)
 
 		test.expectations(ctx, userRepo)
 
-		err := userService.Auth(ctx, test.input)
+		err := userService.SetRole(ctx, "test@test.com", test.role)
 
-		if err != nil {
-			if test.err != nil {
-				assert.Equal(t, test.err, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		}
+		assert.Equal(t, test.err, err)
 
 		userRepo.AssertExpectations(t)
 
 	}
 }
+
+func TestUserService_Refresh(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name: "success refresh",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(&domain.User{ID: 1}, nil)
+			},
+			err: nil,
+		},
+		{
+			name: "failed refresh deleted user",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, uint(1)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrAuthFailed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, nil)
+
+		test.expectations(ctx, userRepo)
+
+		_, err := userService.Refresh(ctx, 1)
+
+		assert.Equal(t, test.err, err)
+
+		userRepo.AssertExpectations(t)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	valuationErrorPrefix = "[service.valuation]"
+)
+
+// book values of spaceships of tenant from context, depreciated by schedules of classes
+type ValuationService struct {
+	spaceships SpaceshipRepository
+	classes    ShipClassRepository
+	index      SpaceshipIndex
+}
+
+// index is optional, values of indexed spaceships are refreshed on appraisal
+func NewValuationService(spaceships SpaceshipRepository, classes ShipClassRepository, index SpaceshipIndex) *ValuationService {
+	return &ValuationService{spaceships, classes, index}
+}
+
+func (s *ValuationService) Spaceship(ctx context.Context, id uint) (*domain.SpaceshipValuation, error) {
+
+	spaceship, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.valuation(ctx, spaceship)
+}
+
+// valuation of spaceship now with history of appraisals
+func (s *ValuationService) valuation(ctx context.Context, spaceship *domain.Spaceship) (*domain.SpaceshipValuation, error) {
+
+	var err error
+	var class *domain.ShipClass
+	if spaceship.ClassID != 0 {
+		class, err = s.classes.GetById(ctx, spaceship.ClassID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get class", valuationErrorPrefix)
+		}
+	}
+
+	valuation := domain.NewSpaceshipValuation(spaceship, class, time.Now().Unix())
+
+	valuation.History, err = s.spaceships.GetValuations(ctx, spaceship.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get valuations", valuationErrorPrefix)
+	}
+
+	return valuation, nil
+}
+
+func (s *ValuationService) Fleet(ctx context.Context) (*domain.FleetValuation, error) {
+
+	spaceships, err := s.spaceships.GetAll(ctx, domain.SpaceshipFilter{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get spaceships", valuationErrorPrefix)
+	}
+
+	classes, err := s.classes.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get classes", valuationErrorPrefix)
+	}
+	classByID := make(map[uint]*domain.ShipClass, len(classes))
+	for _, c := range classes {
+		classByID[c.ID] = c
+	}
+
+	now := time.Now().Unix()
+	valuations := make([]*domain.SpaceshipValuation, 0, len(spaceships))
+	for _, ss := range spaceships {
+		valuations = append(valuations, domain.NewSpaceshipValuation(ss, classByID[ss.ClassID], now))
+	}
+
+	return domain.NewFleetValuation(valuations, now), nil
+}
+
+// record appraisal of spaceship, date defaults to now and can't be in the future
+func (s *ValuationService) Appraise(ctx context.Context, id uint, valuation *domain.Valuation) (*domain.SpaceshipValuation, error) {
+
+	now := time.Now().Unix()
+
+	valuation.SpaceshipID = id
+	valuation.Source = domain.ValuationSourceAppraisal
+	valuation.Note = strings.TrimSpace(valuation.Note)
+	valuation.Currency = strings.ToUpper(strings.TrimSpace(valuation.Currency))
+	if valuation.Currency == "" {
+		valuation.Currency = domain.DefaultCurrency
+	}
+	if valuation.AppraisedAt == 0 {
+		valuation.AppraisedAt = now
+	}
+
+	if valuation.Amount < 0 {
+		return nil, errors.Wrapf(domain.ErrValueWrong, "%s: value can't be negative", valuationErrorPrefix)
+	}
+	if !domain.ValidCurrency(valuation.Currency) {
+		return nil, errors.Wrapf(domain.ErrCurrencyWrong, "%s: currency %q", valuationErrorPrefix, valuation.Currency)
+	}
+	if valuation.AppraisedAt > now {
+		return nil, errors.Wrapf(domain.ErrAppraisalDate, "%s: appraisal can't be in the future", valuationErrorPrefix)
+	}
+
+	err := s.spaceships.Appraise(ctx, valuation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: appraise", valuationErrorPrefix)
+	}
+
+	spaceship, err := s.spaceships.GetById(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get spaceship", valuationErrorPrefix)
+	}
+
+	if s.index != nil {
+		s.index.Put(spaceship)
+	}
+
+	return s.valuation(ctx, spaceship)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuationService_Appraise(t *testing.T) {
+
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name      string
+		valuation *domain.Valuation
+		currency  string
+		err       error
+	}{
+		{
+			name:      "success appraisal in default currency",
+			valuation: &domain.Valuation{Amount: domain.NewDecimal(1000), Note: " survey "},
+			currency:  domain.DefaultCurrency,
+		},
+		{
+			name:      "success past appraisal in lower case currency",
+			valuation: &domain.Valuation{Amount: domain.NewDecimal(1000), Currency: "imp", AppraisedAt: now - 3600},
+			currency:  "IMP",
+		},
+		{
+			name:      "failed appraisal with negative amount",
+			valuation: &domain.Valuation{Amount: domain.NewDecimal(-1)},
+			err:       domain.ErrValueWrong,
+		},
+		{
+			name:      "failed appraisal with wrong currency",
+			valuation: &domain.Valuation{Amount: domain.NewDecimal(1000), Currency: "credits"},
+			err:       domain.ErrCurrencyWrong,
+		},
+		{
+			name:      "failed appraisal in the future",
+			valuation: &domain.Valuation{Amount: domain.NewDecimal(1000), AppraisedAt: now + 3600},
+			err:       domain.ErrAppraisalDate,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		classRepo := mocks.NewShipClassRepository(t)
+		valuationService := NewValuationService(spaceshipRepo, classRepo, nil)
+
+		if test.err == nil {
+			spaceshipRepo.On("Appraise", ctx, mock.Anything).Return(nil)
+			spaceshipRepo.On("GetById", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Value: test.valuation.Amount, Currency: test.currency}, nil)
+			spaceshipRepo.On("GetValuations", ctx, uint(1)).Return([]*domain.Valuation{test.valuation}, nil)
+		}
+
+		v, err := valuationService.Appraise(ctx, 1, test.valuation)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), test.valuation.SpaceshipID)
+		assert.Equal(t, domain.ValuationSourceAppraisal, test.valuation.Source)
+		assert.Equal(t, test.currency, test.valuation.Currency)
+		assert.NotZero(t, test.valuation.AppraisedAt)
+		assert.NotContains(t, test.valuation.Note, " ")
+		assert.Equal(t, test.valuation.Amount, v.BookValue)
+	}
+}
+
+func TestValuationService_Fleet(t *testing.T) {
+
+	ctx := context.Background()
+	day := int64(24 * 60 * 60)
+	now := time.Now().Unix()
+
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	classRepo := mocks.NewShipClassRepository(t)
+	valuationService := NewValuationService(spaceshipRepo, classRepo, nil)
+
+	classRepo.On("GetAll", ctx).Return([]*domain.ShipClass{
+		{ID: 1, Depreciation: domain.Depreciation{Method: domain.DepreciationStraightLine, Rate: 500, Salvage: 2000, Damage: 3000}},
+		{ID: 2, Depreciation: domain.Depreciation{Method: domain.DepreciationDecliningBalance, Rate: 1200, Salvage: 1000, Damage: 4000}},
+	}, nil)
+	spaceshipRepo.On("GetAll", ctx, domain.SpaceshipFilter{}).Return([]*domain.Spaceship{
+		// 5% of 1000 for two years
+		{ID: 1, ClassID: 1, Value: domain.NewDecimal(1000), Currency: "GCR", ValuedAt: now - 730*day},
+		// never below salvage share of 20%
+		{ID: 2, ClassID: 1, Value: domain.NewDecimal(1000), Currency: "GCR", ValuedAt: now - 100*365*day},
+		// 12% of 100 for a year, then 40% of 88 while damaged
+		{ID: 3, ClassID: 2, Value: domain.NewDecimal(100), Currency: "GCR", ValuedAt: now - 365*day, Status: domain.SpaceshipStatusDamaged},
+		// values out of catalog are not depreciated, sums of other currency are kept apart
+		{ID: 4, Value: domain.Decimal(1000), Currency: "IMP", ValuedAt: now - 365*day},
+		{ID: 5, Value: domain.Decimal(1000), Currency: "IMP", ValuedAt: now - 365*day},
+		{ID: 6, Value: domain.Decimal(1000), Currency: "IMP", ValuedAt: now - 365*day},
+	}, nil)
+
+	fleet, err := valuationService.Fleet(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.ValuationTotal{
+		{
+			Currency:         "GCR",
+			Spaceships:       3,
+			Appraised:        domain.NewDecimal(2100),
+			Depreciation:     domain.NewDecimal(100 + 800 + 12),
+			DamageAdjustment: domain.Decimal(352000),
+			BookValue:        domain.NewDecimal(900+200) + domain.Decimal(528000),
+		},
+		{
+			Currency:   "IMP",
+			Spaceships: 3,
+			Appraised:  domain.Decimal(3000),
+			BookValue:  domain.Decimal(3000),
+		},
+	}, fleet.Totals)
+	assert.Equal(t, "0.30", fleet.Totals[1].BookValue.String())
+}
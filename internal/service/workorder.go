@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+var (
+	// prefix for wrap errors
+	workOrderErrorPrefix = "[service.workorder]"
+)
+
+//go:generate mockery --dir . --name WorkOrderRepository --output ./mocks
+type WorkOrderRepository interface {
+	GetAll(context.Context, domain.WorkOrderFilter) ([]*domain.WorkOrder, error)
+	GetById(context.Context, uint) (*domain.WorkOrder, error)
+	// work order locked until transaction of Atomic ends, parts are not loaded
+	GetForUpdate(context.Context, uint) (*domain.WorkOrder, error)
+	Create(context.Context, *domain.WorkOrder) error
+	Update(context.Context, *domain.WorkOrder) error
+	SetStatus(context.Context, uint, domain.WorkOrderStatus, int64) error
+	// number of work orders of spaceship which are not done
+	CountOpen(context.Context, uint) (int, error)
+	DeleteBySpaceship(context.Context, uint) error
+}
+
+// repair work orders of damaged spaceships of tenant from context,
+// status of spaceship is changed with its work orders in one transaction
+type WorkOrderService struct {
+	repository WorkOrderRepository
+	spaceships SpaceshipRepository
+	classes    ShipClassRepository
+	crew       CrewRepository
+	cache      SpaceshipCache
+	index      SpaceshipIndex
+}
+
+// work order service builder, class catalog is optional and checks crew of repaired spaceship if set,
+// cache and index are optional, they are refreshed when status of spaceship is changed
+func NewWorkOrderService(repository WorkOrderRepository, spaceships SpaceshipRepository, classes ShipClassRepository, crew CrewRepository,
+	cache SpaceshipCache, index SpaceshipIndex) *WorkOrderService {
+	return &WorkOrderService{repository, spaceships, classes, crew, cache, index}
+}
+
+func (s *WorkOrderService) GetAll(ctx context.Context, filter domain.WorkOrderFilter) ([]*domain.WorkOrder, error) {
+
+	orders, err := s.repository.GetAll(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", workOrderErrorPrefix)
+	}
+
+	return orders, nil
+}
+
+func (s *WorkOrderService) GetById(ctx context.Context, id uint) (*domain.WorkOrder, error) {
+	return s.repository.GetById(ctx, id)
+}
+
+// report damage of spaceship, it is marked damaged with work order in triage
+func (s *WorkOrderService) Report(ctx context.Context, order *domain.WorkOrder) error {
+
+	err := validateWorkOrder(order)
+	if err != nil {
+		return err
+	}
+
+	order.Status = domain.WorkOrderStatusTriage
+	order.OpenedAt = time.Now().Unix()
+	order.StartedAt = 0
+	order.ClosedAt = 0
+
+	err = s.spaceships.Atomic(ctx, func(ctx context.Context) error {
+
+		spaceship, err := s.spaceships.GetForUpdate(ctx, order.SpaceshipID)
+		if err != nil {
+			return err
+		}
+
+		if spaceship.Status != domain.SpaceshipStatusDamaged {
+			err = s.spaceships.SetStatus(ctx, spaceship.ID, domain.SpaceshipStatusDamaged)
+			if err != nil {
+				return errors.Wrapf(err, "%s: mark damaged", workOrderErrorPrefix)
+			}
+		}
+
+		return s.repository.Create(ctx, order)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.refresh(ctx, order.SpaceshipID)
+}
+
+// update details of work order, status is changed by move only
+func (s *WorkOrderService) Update(ctx context.Context, order *domain.WorkOrder) (*domain.WorkOrder, error) {
+
+	err := validateWorkOrder(order)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.Update(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetById(ctx, order.ID)
+}
+
+// move work order to next status, spaceship becomes operational when its last order is done
+func (s *WorkOrderService) Move(ctx context.Context, id uint, status domain.WorkOrderStatus) (*domain.WorkOrder, error) {
+
+	if status == domain.WorkOrderStatusUndefined {
+		return nil, domain.ErrWorkOrderStatus
+	}
+
+	err := s.spaceships.Atomic(ctx, func(ctx context.Context) error {
+
+		order, err := s.repository.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// spaceship is locked before order is moved, so concurrent closing of its last orders is serialized
+		spaceship, err := s.spaceships.GetForUpdate(ctx, order.SpaceshipID)
+		if err != nil {
+			return err
+		}
+
+		err = s.repository.SetStatus(ctx, id, status, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+
+		if status != domain.WorkOrderStatusDone || spaceship.Status != domain.SpaceshipStatusDamaged {
+			return nil
+		}
+
+		open, err := s.repository.CountOpen(ctx, spaceship.ID)
+		if err != nil || open > 0 {
+			return err
+		}
+
+		// under-crewed spaceship of catalog class can't be operational
+		err = s.checkManned(ctx, spaceship)
+		if err != nil {
+			return err
+		}
+
+		err = s.spaceships.SetStatus(ctx, spaceship.ID, domain.SpaceshipStatusOperational)
+		if err != nil {
+			return errors.Wrapf(err, "%s: mark operational", workOrderErrorPrefix)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refresh(ctx, order.SpaceshipID)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// crew members assigned to spaceship must man its class, spaceship out of catalog is always manned
+func (s *WorkOrderService) checkManned(ctx context.Context, spaceship *domain.Spaceship) error {
+
+	if s.classes == nil || spaceship.ClassID == 0 {
+		return nil
+	}
+
+	class, err := s.classes.GetById(ctx, spaceship.ClassID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: get class", workOrderErrorPrefix)
+	}
+
+	roster, err := s.crew.CountRoster(ctx, spaceship.ID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: count roster", workOrderErrorPrefix)
+	}
+
+	return checkManned(class, roster)
+}
+
+// spaceship is marked damaged or operational by work orders, so its cached and indexed status is refreshed
+func (s *WorkOrderService) refresh(ctx context.Context, spaceshipID uint) error {
+
+	if s.cache != nil {
+		err := s.cache.Invalidate(ctx, spaceshipID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.index == nil {
+		return nil
+	}
+	spaceship, err := s.spaceships.GetById(ctx, spaceshipID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: get spaceship", workOrderErrorPrefix)
+	}
+	s.index.Put(spaceship)
+
+	return nil
+}
+
+// work orders which are not closed in time, the most overdue first
+func (s *WorkOrderService) SLABreaches(ctx context.Context) ([]*domain.WorkOrderBreach, error) {
+
+	orders, err := s.repository.GetAll(ctx, domain.WorkOrderFilter{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: sla breaches", workOrderErrorPrefix)
+	}
+
+	now := time.Now().Unix()
+	breaches := []*domain.WorkOrderBreach{}
+	for _, o := range orders {
+		if overdue := o.Overdue(now); overdue > 0 {
+			breaches = append(breaches, &domain.WorkOrderBreach{WorkOrder: o, Overdue: overdue})
+		}
+	}
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].Overdue > breaches[j].Overdue
+	})
+
+	return breaches, nil
+}
+
+func validateWorkOrder(order *domain.WorkOrder) error {
+
+	if order.Severity == domain.WorkOrderSeverityUndefined {
+		return domain.ErrSeverityWrong
+	}
+
+	order.Description = strings.TrimSpace(order.Description)
+	if order.Description == "" {
+		return domain.ErrDescriptionEmpty
+	}
+
+	order.Yard = strings.TrimSpace(order.Yard)
+	if order.EstimatedCost < 0 {
+		return domain.ErrCostWrong
+	}
+
+	return nil
+}
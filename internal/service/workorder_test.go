@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkOrderService_Report(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		order        *domain.WorkOrder
+		expectations func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository)
+		err          error
+	}{
+		{
+			name:  "success report damage",
+			order: &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityHigh, Description: " Hull breach ", Status: domain.WorkOrderStatusDone},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Status: domain.SpaceshipStatusOperational}, nil)
+				spaceshipRepo.On("SetStatus", ctx, uint(1), domain.SpaceshipStatusDamaged).Return(nil)
+				orderRepo.On("Create", ctx, mock.MatchedBy(func(o *domain.WorkOrder) bool {
+					return o.Description == "Hull breach" && o.Status == domain.WorkOrderStatusTriage && o.OpenedAt > 0
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "success report damage of damaged spaceship",
+			order: &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityLow, Description: "Hull breach"},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Status: domain.SpaceshipStatusDamaged}, nil)
+				orderRepo.On("Create", ctx, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:         "failed report with unknown severity",
+			order:        &domain.WorkOrder{SpaceshipID: 1, Description: "Hull breach"},
+			expectations: func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository) {},
+			err:          domain.ErrSeverityWrong,
+		},
+		{
+			name:         "failed report without description",
+			order:        &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityLow, Description: " "},
+			expectations: func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository) {},
+			err:          domain.ErrDescriptionEmpty,
+		},
+		{
+			name:         "failed report with negative cost",
+			order:        &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityLow, Description: "Hull breach", EstimatedCost: -1},
+			expectations: func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository) {},
+			err:          domain.ErrCostWrong,
+		},
+		{
+			name:  "failed report for unknown spaceship",
+			order: &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityLow, Description: "Hull breach"},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name:  "failed report with unknown part",
+			order: &domain.WorkOrder{SpaceshipID: 1, Severity: domain.WorkOrderSeverityLow, Description: "Hull breach", Parts: []domain.WorkOrderPart{{Title: "Hyperdrive", Qty: 1}}},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository) {
+				spaceshipRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.Spaceship{ID: 1, Status: domain.SpaceshipStatusDamaged}, nil)
+				orderRepo.On("Create", ctx, mock.Anything).Return(domain.ErrPartUnknown)
+			},
+			err: domain.ErrPartUnknown,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		orderService := NewWorkOrderService(orderRepo, spaceshipRepo, nil, nil, nil, nil)
+
+		spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically).Maybe()
+		test.expectations(ctx, orderRepo, spaceshipRepo)
+
+		err := orderService.Report(ctx, test.order)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestWorkOrderService_Move(t *testing.T) {
+
+	starDestroyer := &domain.ShipClass{ID: 1, Name: "Star Destroyer", MinCrew: 9000}
+
+	testCases := []struct {
+		name         string
+		status       domain.WorkOrderStatus
+		spaceship    *domain.Spaceship
+		expectations func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository, *mocks.CrewRepository)
+		err          error
+	}{
+		{
+			name:      "success move to done",
+			status:    domain.WorkOrderStatusDone,
+			spaceship: &domain.Spaceship{ID: 5, Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				orderRepo.On("SetStatus", ctx, uint(1), domain.WorkOrderStatusDone, mock.AnythingOfType("int64")).Return(nil)
+				orderRepo.On("CountOpen", ctx, uint(5)).Return(1, nil)
+				orderRepo.On("GetById", ctx, uint(1)).Return(&domain.WorkOrder{ID: 1, SpaceshipID: 5, Status: domain.WorkOrderStatusDone}, nil)
+			},
+		},
+		{
+			name:      "success move last order to done makes spaceship operational",
+			status:    domain.WorkOrderStatusDone,
+			spaceship: &domain.Spaceship{ID: 5, ClassID: 1, Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				orderRepo.On("SetStatus", ctx, uint(1), domain.WorkOrderStatusDone, mock.AnythingOfType("int64")).Return(nil)
+				orderRepo.On("CountOpen", ctx, uint(5)).Return(0, nil)
+				crewRepo.On("CountRoster", ctx, uint(5)).Return(9000, nil)
+				spaceshipRepo.On("SetStatus", ctx, uint(5), domain.SpaceshipStatusOperational).Return(nil)
+				orderRepo.On("GetById", ctx, uint(1)).Return(&domain.WorkOrder{ID: 1, SpaceshipID: 5, Status: domain.WorkOrderStatusDone}, nil)
+			},
+		},
+		{
+			name:      "failed move last order to done of under-crewed spaceship",
+			status:    domain.WorkOrderStatusDone,
+			spaceship: &domain.Spaceship{ID: 5, ClassID: 1, Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				orderRepo.On("SetStatus", ctx, uint(1), domain.WorkOrderStatusDone, mock.AnythingOfType("int64")).Return(nil)
+				orderRepo.On("CountOpen", ctx, uint(5)).Return(0, nil)
+				crewRepo.On("CountRoster", ctx, uint(5)).Return(100, nil)
+			},
+			err: domain.ErrUnderCrewed,
+		},
+		{
+			name:         "failed move to unknown status",
+			status:       domain.WorkOrderStatusUndefined,
+			expectations: func(context.Context, *mocks.WorkOrderRepository, *mocks.SpaceshipRepository, *mocks.CrewRepository) {},
+			err:          domain.ErrWorkOrderStatus,
+		},
+		{
+			name:      "failed move back",
+			status:    domain.WorkOrderStatusTriage,
+			spaceship: &domain.Spaceship{ID: 5, Status: domain.SpaceshipStatusDamaged},
+			expectations: func(ctx context.Context, orderRepo *mocks.WorkOrderRepository, spaceshipRepo *mocks.SpaceshipRepository, crewRepo *mocks.CrewRepository) {
+				orderRepo.On("SetStatus", ctx, uint(1), domain.WorkOrderStatusTriage, mock.AnythingOfType("int64")).Return(domain.ErrWorkOrderMove)
+			},
+			err: domain.ErrWorkOrderMove,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		orderRepo := mocks.NewWorkOrderRepository(t)
+		spaceshipRepo := mocks.NewSpaceshipRepository(t)
+		classRepo := mocks.NewShipClassRepository(t)
+		crewRepo := mocks.NewCrewRepository(t)
+		orderService := NewWorkOrderService(orderRepo, spaceshipRepo, classRepo, crewRepo, nil, nil)
+
+		if test.spaceship != nil {
+			spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+			orderRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.WorkOrder{ID: 1, SpaceshipID: test.spaceship.ID}, nil)
+			spaceshipRepo.On("GetForUpdate", ctx, test.spaceship.ID).Return(test.spaceship, nil)
+			classRepo.On("GetById", ctx, uint(1)).Return(starDestroyer, nil).Maybe()
+		}
+		test.expectations(ctx, orderRepo, spaceshipRepo, crewRepo)
+
+		_, err := orderService.Move(ctx, 1, test.status)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestWorkOrderService_SLABreaches(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+	day := int64(24 * time.Hour / time.Second)
+
+	orders := []*domain.WorkOrder{
+		// open critical order overdue by a day
+		{ID: 1, Severity: domain.WorkOrderSeverityCritical, Status: domain.WorkOrderStatusInProgress, OpenedAt: now - 2*day},
+		// open low order in time
+		{ID: 2, Severity: domain.WorkOrderSeverityLow, Status: domain.WorkOrderStatusTriage, OpenedAt: now - 2*day},
+		// high order closed late by two days
+		{ID: 3, Severity: domain.WorkOrderSeverityHigh, Status: domain.WorkOrderStatusDone, OpenedAt: now - 10*day, ClosedAt: now - 5*day},
+		// medium order closed in time
+		{ID: 4, Severity: domain.WorkOrderSeverityMedium, Status: domain.WorkOrderStatusDone, OpenedAt: now - 10*day, ClosedAt: now - 4*day},
+	}
+
+	orderRepo := mocks.NewWorkOrderRepository(t)
+	orderRepo.On("GetAll", ctx, domain.WorkOrderFilter{}).Return(orders, nil)
+
+	breaches, err := NewWorkOrderService(orderRepo, nil, nil, nil, nil, nil).SLABreaches(ctx)
+	require.NoError(t, err)
+	require.Len(t, breaches, 2)
+	assert.Equal(t, uint(3), breaches[0].WorkOrder.ID)
+	assert.Equal(t, 48*time.Hour, breaches[0].Overdue)
+	assert.Equal(t, uint(1), breaches[1].WorkOrder.ID)
+	assert.Equal(t, 24*time.Hour, breaches[1].Overdue)
+}
+
+func TestWorkOrderService_RefreshSpaceship(t *testing.T) {
+
+	ctx := context.Background()
+
+	orderRepo := mocks.NewWorkOrderRepository(t)
+	spaceshipRepo := mocks.NewSpaceshipRepository(t)
+	cache := mocks.NewSpaceshipCache(t)
+	index := mocks.NewSpaceshipIndex(t)
+	orderService := NewWorkOrderService(orderRepo, spaceshipRepo, nil, nil, cache, index)
+
+	spaceshipRepo.On("Atomic", ctx, mock.Anything).Return(atomically)
+	cache.On("Invalidate", ctx, uint(5)).Return(nil).Twice()
+
+	// spaceship is marked damaged by report and operational by move
+	damaged := &domain.Spaceship{ID: 5, Status: domain.SpaceshipStatusDamaged}
+	spaceshipRepo.On("GetForUpdate", ctx, uint(5)).Return(&domain.Spaceship{ID: 5, Status: domain.SpaceshipStatusOperational}, nil).Once()
+	spaceshipRepo.On("SetStatus", ctx, uint(5), domain.SpaceshipStatusDamaged).Return(nil)
+	orderRepo.On("Create", ctx, mock.Anything).Return(nil)
+	spaceshipRepo.On("GetById", ctx, uint(5)).Return(damaged, nil).Once()
+	index.On("Put", damaged).Once()
+	err := orderService.Report(ctx, &domain.WorkOrder{SpaceshipID: 5, Severity: domain.WorkOrderSeverityLow, Description: "Hull breach"})
+	require.NoError(t, err)
+
+	orderRepo.On("GetForUpdate", ctx, uint(1)).Return(&domain.WorkOrder{ID: 1, SpaceshipID: 5}, nil)
+	spaceshipRepo.On("GetForUpdate", ctx, uint(5)).Return(damaged, nil).Once()
+	orderRepo.On("SetStatus", ctx, uint(1), domain.WorkOrderStatusDone, mock.AnythingOfType("int64")).Return(nil)
+	orderRepo.On("CountOpen", ctx, uint(5)).Return(0, nil)
+	spaceshipRepo.On("SetStatus", ctx, uint(5), domain.SpaceshipStatusOperational).Return(nil)
+	orderRepo.On("GetById", ctx, uint(1)).Return(&domain.WorkOrder{ID: 1, SpaceshipID: 5}, nil)
+	operational := &domain.Spaceship{ID: 5, Status: domain.SpaceshipStatusOperational}
+	spaceshipRepo.On("GetById", ctx, uint(5)).Return(operational, nil).Once()
+	index.On("Put", operational).Once()
+	_, err = orderService.Move(ctx, 1, domain.WorkOrderStatusDone)
+	require.NoError(t, err)
+}
@@ -0,0 +1,26 @@
+// Package tenant carries organization of request through context,
+// repositories read it to scope every query by tenant
+package tenant
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type contextKey struct{}
+
+// context with organization id
+func WithID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// organization id from context, missing tenant is an error
+// so that records can't be reached without tenant by mistake
+func ID(ctx context.Context) (uint, error) {
+	id, ok := ctx.Value(contextKey{}).(uint)
+	if !ok || id == 0 {
+		return 0, domain.ErrTenantRequired
+	}
+	return id, nil
+}
@@ -0,0 +1,111 @@
+// Package totp implements RFC 6238 time-based one-time passwords
+// with parameters supported by common authenticator apps
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// length of generated code
+	Digits = 6
+	// time step of code
+	Period = 30 * time.Second
+	// secret length in bytes, recommended by RFC 4226
+	secretSize = 20
+)
+
+var (
+	// errors prefix
+	totpErrorPrefix = "[totp]"
+
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// generate random base32 secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: generate secret", totpErrorPrefix)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// time step counter of moment
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// code for time step counter
+func CodeAt(secret string, counter int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: decode secret", totpErrorPrefix)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+// code for moment
+func Code(secret string, t time.Time) (string, error) {
+	return CodeAt(secret, Counter(t))
+}
+
+// check code against moment allowing skew steps of clock drift,
+// returns matched counter which should be stored to prevent replay
+func Validate(secret string, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	counter := Counter(t)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		expected, err := CodeAt(secret, counter+i)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + i, true
+		}
+	}
+	return 0, false
+}
+
+// provisioning uri understood by authenticator apps
+func URI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
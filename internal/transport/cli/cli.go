@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/crew"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/migrate"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mission"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/shipclass"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/workorder"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	cliErrorPrefix = "[transport.cli]"
+
+	// returned when command line can't be parsed, usage is already printed
+	ErrUsage = errors.New("usage error")
+)
+
+const usage = `Usage: server <command> [arguments]
+
+Commands:
+  serve                                        run rest api server (default)
+  migrate                                      automigrate database schema
+  user create -email -password [-role]         create user with role (user, officer, admin)
+  user set-password -email -password           set new password for user
+  user set-role -email -role                   set role for user
+  user mfa-reset -email                        turn off mfa of user who lost authenticator
+  user list                                    list all users
+  org create -name                             create organization
+  org add-member -org -email [-role]           add user to organization with role (viewer, member, admin)
+  org list                                     list all organizations
+  spaceship import -f file [-org]              create or update spaceships of organization by name from json file
+  spaceship export [-o file] [-org]            export all spaceships of organization to json file
+  keys list                                    list token signing keys
+  keys rotate                                  generate new token signing key
+  seed [-reset] [-org]                         load demo spaceships into organization, -reset deletes its spaceships before
+  config print [--redacted]                    print current configuration
+
+Spaceships imported or seeded by cli are found by search of running server
+after restart or fleetctl search reindex. Spaceships updated by cli may be
+served from cache of running server until SPACESHIP_CACHE_TTL passes or an
+admin purges it with DELETE /v1/cache.
+
+Classes of existing spaceships are linked to the class catalog on first
+migrate, later an admin relinks them with POST /v1/classes/normalize.
+`
+
+// database connector, e.g. mysql.Connect
+type Connector func(context.Context) (*mysql.DB, error)
+
+// command line interface for server and operational tasks
+type CLI struct {
+	out     io.Writer
+	connect Connector
+	serve   func() error
+}
+
+// cli builder
+func NewCLI(out io.Writer, connect Connector) *CLI {
+	return &CLI{
+		out:     out,
+		connect: connect,
+		serve:   rest.RunRest,
+	}
+}
+
+// run command from arguments without program name
+func (c *CLI) Run(ctx context.Context, args []string) error {
+
+	// server is started if no command provided
+	if len(args) == 0 {
+		return c.serve()
+	}
+
+	switch args[0] {
+	case "serve":
+		return c.serve()
+	case "migrate":
+		return c.migrate(ctx)
+	case "user":
+		return c.user(ctx, args[1:])
+	case "org":
+		return c.org(ctx, args[1:])
+	case "spaceship":
+		return c.spaceship(ctx, args[1:])
+	case "keys":
+		return c.keys(ctx, args[1:])
+	case "seed":
+		return c.seed(ctx, args[1:])
+	case "config":
+		return c.config(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(c.out, usage)
+		return nil
+	default:
+		return c.usageError("unknown command %q", args[0])
+	}
+}
+
+// print error with usage and return usage error
+func (c *CLI) usageError(format string, args ...interface{}) error {
+	fmt.Fprintf(c.out, format+"\n\n", args...)
+	fmt.Fprint(c.out, usage)
+	return ErrUsage
+}
+
+// create flag set which prints errors to cli output
+func (c *CLI) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	return fs
+}
+
+// parse flags, flag errors are reported as usage errors
+func (c *CLI) parse(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return ErrUsage
+	}
+	return nil
+}
+
+func (c *CLI) migrate(ctx context.Context) error {
+
+	db, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = migrate.Run(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "migration done")
+	return nil
+}
+
+// connect db and init services the same way rest server does
+func (c *CLI) services(ctx context.Context) (*service.UserService, *service.SpaceshipService, error) {
+
+	db, err := c.connect(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%s: connect", cliErrorPrefix)
+	}
+
+	userService := service.NewUserService(user.NewUserRepo(db), nil)
+	spaceshipService := service.NewSpaceshipService(spaceship.NewSpaceshipRepo(db), shipclass.NewShipClassRepo(db), crew.NewCrewRepo(db),
+		workorder.NewWorkOrderRepo(db), mission.NewMissionRepo(db), nil, nil, service.ImageConfig{})
+
+	return userService, spaceshipService, nil
+}
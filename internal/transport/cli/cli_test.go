@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// create cli backed by sqlite database with migrated schema
+// config is read once, so secrets checked by config print are set before any test
+func TestMain(m *testing.M) {
+	os.Setenv("SMTP_PASSWORD", "deathstar")
+	os.Setenv("MYSQL_DSN", "fleet:plans@tcp(localhost:3306)/fleet")
+	os.Exit(m.Run())
+}
+
+func newTestCLI(t *testing.T) (*CLI, *bytes.Buffer) {
+	t.Helper()
+
+	db := mysqltest.Open(t)
+
+	out := new(bytes.Buffer)
+	c := NewCLI(out, func(context.Context) (*mysql.DB, error) {
+		return db, nil
+	})
+	c.serve = func() error {
+		out.WriteString("serving\n")
+		return nil
+	}
+
+	require.NoError(t, c.Run(context.Background(), []string{"migrate"}))
+	out.Reset()
+
+	return c, out
+}
+
+func TestCLI_Run(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	// server is default command
+	assert.NoError(t, c.Run(ctx, nil))
+	assert.Equal(t, "serving\n", out.String())
+
+	out.Reset()
+	assert.ErrorIs(t, c.Run(ctx, []string{"unknown"}), ErrUsage)
+	assert.Contains(t, out.String(), "Usage:")
+
+	out.Reset()
+	assert.ErrorIs(t, c.Run(ctx, []string{"user", "create", "-unknown"}), ErrUsage)
+}
+
+func TestCLI_User(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	err := c.Run(ctx, []string{"user", "create", "-email", "vader@empire.gov", "-password", "123123", "-role", "admin"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "role Admin")
+
+	err = c.Run(ctx, []string{"user", "create", "-email", "vader@empire.gov", "-password", "123123"})
+	assert.ErrorIs(t, err, domain.ErrUserExists)
+
+	err = c.Run(ctx, []string{"user", "create", "-email", "tarkin@empire.gov", "-password", "123123", "-role", "emperor"})
+	assert.ErrorIs(t, err, domain.ErrRoleWrong)
+
+	err = c.Run(ctx, []string{"user", "set-password", "-email", "vader@empire.gov", "-password", "456456"})
+	require.NoError(t, err)
+
+	userService, _, err := c.services(ctx)
+	require.NoError(t, err)
+	_, err = userService.Auth(ctx, &domain.UserAuthReq{Email: "vader@empire.gov", Password: "456456"})
+	assert.NoError(t, err)
+	_, err = userService.Auth(ctx, &domain.UserAuthReq{Email: "vader@empire.gov", Password: "123123"})
+	assert.ErrorIs(t, err, domain.ErrAuthFailed)
+
+	err = c.Run(ctx, []string{"user", "set-role", "-email", "vader@empire.gov", "-role", "officer"})
+	require.NoError(t, err)
+
+	err = c.Run(ctx, []string{"user", "set-role", "-email", "unknown@empire.gov", "-role", "officer"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	out.Reset()
+	err = c.Run(ctx, []string{"user", "list"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "vader@empire.gov")
+	assert.Contains(t, out.String(), "Officer")
+	assert.NotContains(t, out.String(), "tarkin@empire.gov")
+}
+
+func TestCLI_SpaceshipImportExport(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	importFile := filepath.Join(t.TempDir(), "spaceships.json")
+	require.NoError(t, os.WriteFile(importFile, seedSpaceships, 0o600))
+
+	// spaceships are imported into existing organization
+	err := c.Run(ctx, []string{"spaceship", "import", "-f", importFile})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	require.NoError(t, c.Run(ctx, []string{"org", "create", "-name", domain.DefaultOrganizationName}))
+
+	out.Reset()
+	err = c.Run(ctx, []string{"spaceship", "import", "-f", importFile})
+	require.NoError(t, err)
+	assert.Equal(t, "spaceships imported: 3 created, 0 updated\n", out.String())
+
+	// second import updates spaceships by name
+	out.Reset()
+	err = c.Run(ctx, []string{"spaceship", "import", "-f", importFile})
+	require.NoError(t, err)
+	assert.Equal(t, "spaceships imported: 0 created, 3 updated\n", out.String())
+
+	out.Reset()
+	err = c.Run(ctx, []string{"spaceship", "export"})
+	require.NoError(t, err)
+
+	exported := []model.SpaceshipFull{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &exported))
+	expected := []model.SpaceshipFull{}
+	require.NoError(t, json.Unmarshal(seedSpaceships, &expected))
+
+	require.Len(t, exported, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].Name, exported[i].Name)
+		assert.Equal(t, expected[i].Class, exported[i].Class)
+		assert.Equal(t, expected[i].Crew, exported[i].Crew)
+		assert.Equal(t, expected[i].Value, exported[i].Value)
+		assert.Equal(t, domain.SpaceshipStatusFromString(expected[i].Status).String(), exported[i].Status)
+		assert.ElementsMatch(t, expected[i].Armament, exported[i].Armament)
+	}
+}
+
+func TestCLI_Seed(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	require.NoError(t, c.Run(ctx, []string{"seed"}))
+	assert.Equal(t, "spaceships seeded: 3 created, 0 updated\n", out.String())
+
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"seed"}))
+	assert.Equal(t, "spaceships seeded: 0 created, 3 updated\n", out.String())
+
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"seed", "-reset"}))
+	assert.Equal(t, "spaceships deleted: 3\nspaceships seeded: 3 created, 0 updated\n", out.String())
+
+	// the same spaceships are seeded into other organization
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"seed", "-org", "Rebel Alliance"}))
+	assert.Equal(t, "spaceships seeded: 3 created, 0 updated\n", out.String())
+}
+
+func TestCLI_Org(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	require.NoError(t, c.Run(ctx, []string{"org", "create", "-name", "Imperial Navy"}))
+	assert.Contains(t, out.String(), "organization Imperial Navy created")
+
+	err := c.Run(ctx, []string{"org", "create", "-name", "Imperial Navy"})
+	assert.ErrorIs(t, err, domain.ErrOrgExists)
+
+	require.NoError(t, c.Run(ctx, []string{"user", "create", "-email", "piett@empire.gov", "-password", "123123"}))
+
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"org", "add-member", "-org", "Imperial Navy", "-email", "piett@empire.gov", "-role", "viewer"}))
+	assert.Equal(t, "user piett@empire.gov is Viewer of organization Imperial Navy\n", out.String())
+
+	err = c.Run(ctx, []string{"org", "add-member", "-org", "Imperial Navy", "-email", "piett@empire.gov", "-role", "emperor"})
+	assert.ErrorIs(t, err, domain.ErrRoleWrong)
+
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"org", "list"}))
+	assert.Contains(t, out.String(), "Imperial Navy")
+}
+
+func TestCLI_Keys(t *testing.T) {
+
+	ctx := context.Background()
+	c, out := newTestCLI(t)
+
+	// the first key is generated on load
+	require.NoError(t, c.Run(ctx, []string{"keys", "list"}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "EdDSA  true")
+
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"keys", "rotate"}))
+	assert.Contains(t, out.String(), "signs tokens in 10m0s")
+
+	// new key is not signing until propagated
+	out.Reset()
+	require.NoError(t, c.Run(ctx, []string{"keys", "list"}))
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "EdDSA  false")
+	assert.Contains(t, lines[2], "EdDSA  true")
+}
+
+func TestCLI_ConfigPrint(t *testing.T) {
+
+	c := NewCLI(new(bytes.Buffer), nil)
+	out := c.out.(*bytes.Buffer)
+
+	require.NoError(t, c.Run(context.Background(), []string{"config", "print", "--redacted"}))
+	assert.NotContains(t, out.String(), "deathstar")
+	assert.NotContains(t, out.String(), "plans")
+	assert.Contains(t, out.String(), "fleet:***@tcp(localhost:3306)/fleet")
+}
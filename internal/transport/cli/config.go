@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/pkg/errors"
+)
+
+func (c *CLI) config(args []string) error {
+
+	if len(args) == 0 || args[0] != "print" {
+		return c.usageError("config command is required")
+	}
+
+	fs := c.flagSet("config print")
+	redacted := fs.Bool("redacted", false, "hide secrets")
+	if err := c.parse(fs, args[1:]); err != nil {
+		return err
+	}
+
+	cfg := *config.Get()
+	if *redacted {
+		cfg = cfg.Redacted()
+	}
+
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(cfg)
+	if err != nil {
+		return errors.Wrapf(err, "%s: encode config", cliErrorPrefix)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/transport/rest"
+	"github.com/pkg/errors"
+)
+
+func (c *CLI) keys(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return c.usageError("keys command is required")
+	}
+
+	switch args[0] {
+	case "list":
+		return c.keysList(ctx)
+	case "rotate":
+		return c.keysRotate(ctx)
+	default:
+		return c.usageError("unknown keys command %q", args[0])
+	}
+}
+
+func (c *CLI) keysList(ctx context.Context) error {
+
+	keys, err := c.keySet(ctx)
+	if err != nil {
+		return err
+	}
+
+	signing := keys.Signing()
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tSIGNING\tCREATED")
+	for _, key := range keys.Keys() {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", key.ID, key.Algorithm, key == signing, key.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// generate new key now, e.g. when signing key is compromised
+func (c *CLI) keysRotate(ctx context.Context) error {
+
+	keys, err := c.keySet(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := keys.Rotate(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "key %s created, it signs tokens in %s\n", key.ID, config.Get().JWTKeyPropagation)
+	return nil
+}
+
+// connect db and load key set the same way rest server does
+func (c *CLI) keySet(ctx context.Context) (*keyset.KeySet, error) {
+
+	db, err := c.connect(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: connect", cliErrorPrefix)
+	}
+
+	return rest.NewKeySet(ctx, config.Get(), db)
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/organization"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/pkg/errors"
+)
+
+func (c *CLI) org(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return c.usageError("org command is required")
+	}
+
+	switch args[0] {
+	case "create":
+		return c.orgCreate(ctx, args[1:])
+	case "add-member":
+		return c.orgAddMember(ctx, args[1:])
+	case "list":
+		return c.orgList(ctx)
+	default:
+		return c.usageError("unknown org command %q", args[0])
+	}
+}
+
+func (c *CLI) orgCreate(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("org create")
+	name := fs.String("name", "", "organization name")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	orgService, err := c.organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := orgService.CreateOrganization(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "organization %s created with id %d\n", org.Name, org.ID)
+	return nil
+}
+
+func (c *CLI) orgAddMember(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("org add-member")
+	name := fs.String("org", "", "organization name")
+	email := fs.String("email", "", "user email")
+	role := fs.String("role", "member", "role in organization: viewer, member or admin")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	orgService, err := c.organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := orgService.GetByName(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	membership, err := orgService.SetMember(ctx, org.ID, *email, domain.OrgRoleFromString(*role))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "user %s is %s of organization %s\n", *email, membership.Role, org.Name)
+	return nil
+}
+
+func (c *CLI) orgList(ctx context.Context) error {
+
+	orgService, err := c.organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	orgs, err := orgService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCREATED")
+	for _, o := range orgs {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", o.ID, o.Name, time.Unix(o.CreatedAt, 0).UTC().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// connect db and init organization service
+func (c *CLI) organizations(ctx context.Context) (*service.OrganizationService, error) {
+
+	db, err := c.connect(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: connect", cliErrorPrefix)
+	}
+
+	return service.NewOrganizationService(organization.NewOrganizationRepo(db), user.NewUserRepo(db)), nil
+}
+
+// context scoped by organization, missing organization is created if asked
+func (c *CLI) tenantContext(ctx context.Context, name string, create bool) (context.Context, error) {
+
+	orgService, err := c.organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := orgService.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) && create {
+		org, err = orgService.CreateOrganization(ctx, name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: organization %q", cliErrorPrefix, name)
+	}
+
+	return tenant.WithID(ctx, org.ID), nil
+}
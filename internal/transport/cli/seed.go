@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+)
+
+// demo spaceships in the same format as spaceship export, they have no crew
+// members assigned, so spaceships of catalog classes are seeded damaged
+//
+//go:embed seed/spaceships.json
+var seedSpaceships []byte
+
+func (c *CLI) seed(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("seed")
+	reset := fs.Bool("reset", false, "delete all spaceships of organization before seeding")
+	org := fs.String("org", domain.DefaultOrganizationName, "organization name, created if missing")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	spaceships := []model.SpaceshipFull{}
+	err := json.Unmarshal(seedSpaceships, &spaceships)
+	if err != nil {
+		return errors.Wrapf(err, "%s: decode seed data", cliErrorPrefix)
+	}
+
+	ctx, err = c.tenantContext(ctx, *org, true)
+	if err != nil {
+		return err
+	}
+
+	_, spaceshipService, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *reset {
+		existing, err := spaceshipService.GetAll(ctx, domain.SpaceshipFilter{})
+		if err != nil {
+			return err
+		}
+		for _, s := range existing {
+			err = spaceshipService.DeleteSpaceship(ctx, s)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(c.out, "spaceships deleted: %d\n", len(existing))
+	}
+
+	created, updated, err := importSpaceships(ctx, spaceshipService, spaceships)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "spaceships seeded: %d created, %d updated\n", created, updated)
+	return nil
+}
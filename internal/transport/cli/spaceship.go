@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+)
+
+func (c *CLI) spaceship(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return c.usageError("spaceship command is required")
+	}
+
+	switch args[0] {
+	case "import":
+		return c.spaceshipImport(ctx, args[1:])
+	case "export":
+		return c.spaceshipExport(ctx, args[1:])
+	default:
+		return c.usageError("unknown spaceship command %q", args[0])
+	}
+}
+
+func (c *CLI) spaceshipImport(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("spaceship import")
+	file := fs.String("f", "", "json file with spaceships array, - for stdin")
+	org := fs.String("org", domain.DefaultOrganizationName, "organization name")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return c.usageError("spaceship import file is required")
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return errors.Wrapf(err, "%s: open import file", cliErrorPrefix)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	spaceships := []model.SpaceshipFull{}
+	err := json.NewDecoder(r).Decode(&spaceships)
+	if err != nil {
+		return errors.Wrapf(err, "%s: decode import file", cliErrorPrefix)
+	}
+
+	ctx, err = c.tenantContext(ctx, *org, false)
+	if err != nil {
+		return err
+	}
+
+	_, spaceshipService, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	created, updated, err := importSpaceships(ctx, spaceshipService, spaceships)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "spaceships imported: %d created, %d updated\n", created, updated)
+	return nil
+}
+
+func (c *CLI) spaceshipExport(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("spaceship export")
+	file := fs.String("o", "-", "output json file, - for stdout")
+	org := fs.String("org", domain.DefaultOrganizationName, "organization name")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	ctx, err := c.tenantContext(ctx, *org, false)
+	if err != nil {
+		return err
+	}
+
+	_, spaceshipService, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	// list contains only short info, so every spaceship is requested in detail
+	spaceships, err := spaceshipService.GetAll(ctx, domain.SpaceshipFilter{})
+	if err != nil {
+		return err
+	}
+	restSpaceships := make([]model.SpaceshipFull, 0, len(spaceships))
+	for _, s := range spaceships {
+		spaceship, err := spaceshipService.GetById(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+		restSpaceships = append(restSpaceships, model.SpaceshipFullFromDomain(spaceship))
+	}
+
+	w := c.out
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return errors.Wrapf(err, "%s: create export file", cliErrorPrefix)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(restSpaceships)
+	if err != nil {
+		return errors.Wrapf(err, "%s: encode export file", cliErrorPrefix)
+	}
+
+	return nil
+}
+
+// create spaceships through service, existing spaceships with same name are updated
+func importSpaceships(ctx context.Context, spaceshipService *service.SpaceshipService, spaceships []model.SpaceshipFull) (created int, updated int, err error) {
+
+	existing, err := spaceshipService.GetAll(ctx, domain.SpaceshipFilter{})
+	if err != nil {
+		return 0, 0, err
+	}
+	ids := make(map[string]uint, len(existing))
+	for _, s := range existing {
+		ids[s.Name] = s.ID
+	}
+
+	for _, s := range spaceships {
+		domainSpaceship := s.ToDomain()
+		domainSpaceship.ID = ids[s.Name]
+
+		if domainSpaceship.ID == 0 {
+			err = spaceshipService.CreateSpaceship(ctx, domainSpaceship)
+			if err != nil {
+				return created, updated, errors.Wrapf(err, "%s: import spaceship %q", cliErrorPrefix, s.Name)
+			}
+			ids[s.Name] = domainSpaceship.ID
+			created++
+			continue
+		}
+
+		err = spaceshipService.UpdateSpaceship(ctx, domainSpaceship)
+		if err != nil {
+			return created, updated, errors.Wrapf(err, "%s: import spaceship %q", cliErrorPrefix, s.Name)
+		}
+		updated++
+	}
+
+	return created, updated, nil
+}
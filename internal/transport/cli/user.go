@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+func (c *CLI) user(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return c.usageError("user command is required")
+	}
+
+	switch args[0] {
+	case "create":
+		return c.userCreate(ctx, args[1:])
+	case "set-password":
+		return c.userSetPassword(ctx, args[1:])
+	case "set-role":
+		return c.userSetRole(ctx, args[1:])
+	case "mfa-reset":
+		return c.userMFAReset(ctx, args[1:])
+	case "list":
+		return c.userList(ctx)
+	default:
+		return c.usageError("unknown user command %q", args[0])
+	}
+}
+
+func (c *CLI) userCreate(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("user create")
+	email := fs.String("email", "", "user email")
+	password := fs.String("password", "", "user password")
+	role := fs.String("role", "user", "user role: user, officer or admin")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	userService, _, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := userService.Create(ctx, &domain.UserCreateReq{
+		Email:    *email,
+		Password: *password,
+		Role:     domain.UserRoleFromString(*role),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "user %s created with id %d and role %s\n", user.Email, user.ID, user.Role)
+	return nil
+}
+
+func (c *CLI) userSetPassword(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("user set-password")
+	email := fs.String("email", "", "user email")
+	password := fs.String("password", "", "new user password")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	userService, _, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = userService.SetPassword(ctx, *email, *password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "password of user %s changed\n", *email)
+	return nil
+}
+
+func (c *CLI) userSetRole(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("user set-role")
+	email := fs.String("email", "", "user email")
+	role := fs.String("role", "", "user role: user, officer or admin")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	userService, _, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	domainRole := domain.UserRoleFromString(*role)
+	err = userService.SetRole(ctx, *email, domainRole)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "role of user %s changed to %s\n", *email, domainRole)
+	return nil
+}
+
+func (c *CLI) userMFAReset(ctx context.Context, args []string) error {
+
+	fs := c.flagSet("user mfa-reset")
+	email := fs.String("email", "", "user email")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	userService, _, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = userService.ResetMFA(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "mfa of user %s is reset\n", *email)
+	return nil
+}
+
+func (c *CLI) userList(ctx context.Context) error {
+
+	userService, _, err := c.services(ctx)
+	if err != nil {
+		return err
+	}
+
+	users, err := userService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tROLE\tMFA\tCREATED")
+	for _, u := range users {
+		mfa := "off"
+		if u.TOTPEnabledAt != 0 {
+			mfa = "on"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.Role, mfa, time.Unix(u.CreatedAt, 0).UTC().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
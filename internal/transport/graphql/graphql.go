@@ -0,0 +1,146 @@
+// Package graphql serves spaceships of organization, their armament and current user
+// by graphql schema on top of services, queries are limited by depth and complexity
+package graphql
+
+import (
+	"context"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	graphqlErrorPrefix = "[transport.graphql]"
+
+	// test interface
+	_ SpaceshipService    = (*service.SpaceshipService)(nil)
+	_ OrganizationService = (*service.OrganizationService)(nil)
+)
+
+//go:generate mockery --dir . --name SpaceshipService --output ./mocks
+type SpaceshipService interface {
+	GetAll(context.Context, domain.SpaceshipFilter) ([]*domain.Spaceship, error)
+	Count(context.Context, domain.SpaceshipFilter) (int, error)
+	GetById(context.Context, uint) (*domain.Spaceship, error)
+	GetArmament(context.Context, []uint) (map[uint][]domain.SpaceshipArmament, error)
+	CreateSpaceship(context.Context, *domain.Spaceship) error
+	PatchSpaceship(context.Context, uint, domain.SpaceshipPatch) (*domain.Spaceship, error)
+	DeleteSpaceship(context.Context, *domain.Spaceship) error
+}
+
+//go:generate mockery --dir . --name OrganizationService --output ./mocks
+type OrganizationService interface {
+	List(context.Context, uint) ([]*domain.Membership, error)
+}
+
+// limits of queries, zero disables limit
+type Config struct {
+	// max nesting of fields
+	MaxDepth int
+	// max count of fields, fields of pages are counted for every item of page
+	MaxComplexity int
+}
+
+// graphql request of transport
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// result with data and errors of request
+type Result struct {
+	Data   interface{}                `json:"data"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// caller of request, authenticated by transport
+type Viewer struct {
+	// membership which selects organization of request
+	Membership *domain.Membership
+	// api key which authenticated request, nil for jwt
+	APIKey *domain.APIKey
+}
+
+// spaceships are changed by members, api keys must have write scope too
+func (v *Viewer) canWrite() error {
+	if v.APIKey != nil && !v.APIKey.HasScope(domain.ScopeSpaceshipsWrite) {
+		return domain.ErrScopeMissing
+	}
+	if !v.Membership.Role.Allows(domain.OrgRoleMember) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// executable graphql schema
+type Schema struct {
+	schema   gql.Schema
+	resolver *resolver
+	config   Config
+}
+
+func New(spaceships SpaceshipService, orgs OrganizationService, config Config) (*Schema, error) {
+	r := &resolver{spaceships: spaceships, orgs: orgs}
+	schema, err := gql.NewSchema(r.schemaConfig())
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: new schema", graphqlErrorPrefix)
+	}
+	return &Schema{schema: schema, resolver: r, config: config}, nil
+}
+
+// execute request of viewer, errors of resolvers are returned in result,
+// their domain errors are available by OriginalError
+func (s *Schema) Execute(ctx context.Context, viewer *Viewer, req *Request) *Result {
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	// limits are checked before anything is resolved
+	err = checkLimits(doc, req.OperationName, req.Variables, s.config)
+	if err != nil {
+		return &Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	ctx = context.WithValue(ctx, viewerContextKey{}, viewer)
+	ctx = context.WithValue(ctx, loaderContextKey{}, newArmamentLoader(ctx, s.resolver.spaceships))
+
+	res := gql.Do(gql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+	return &Result{Data: res.Data, Errors: res.Errors}
+}
+
+// error returned by resolver which caused error of result, nil for errors of query
+func OriginalError(err gqlerrors.FormattedError) error {
+	var cause error = err
+	for {
+		switch e := cause.(type) {
+		case gqlerrors.FormattedError:
+			cause = e.OriginalError()
+		case *gqlerrors.Error:
+			cause = e.OriginalError
+		default:
+			return cause
+		}
+		if cause == nil {
+			return nil
+		}
+	}
+}
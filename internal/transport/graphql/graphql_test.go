@@ -0,0 +1,214 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/transport/graphql"
+	"github.com/Je33/imperial_fleet/internal/transport/graphql/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var member = &graphql.Viewer{Membership: &domain.Membership{
+	OrgID: 1, UserID: 7, Role: domain.OrgRoleMember, OrgName: "Empire", UserEmail: "tarkin@empire.gov",
+}}
+
+func newSchema(t *testing.T, config graphql.Config) (*graphql.Schema, *mocks.SpaceshipService, *mocks.OrganizationService) {
+	spaceships := mocks.NewSpaceshipService(t)
+	orgs := mocks.NewOrganizationService(t)
+	schema, err := graphql.New(spaceships, orgs, config)
+	require.NoError(t, err)
+	return schema, spaceships, orgs
+}
+
+func data(t *testing.T, res *graphql.Result) string {
+	require.Empty(t, res.Errors)
+	b, err := json.Marshal(res.Data)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSchema_Spaceships(t *testing.T) {
+
+	ctx := context.Background()
+	schema, spaceships, _ := newSchema(t, graphql.Config{})
+
+	// page is read with one more spaceship, which tells there is next page
+	operational := domain.SpaceshipFilter{Status: domain.SpaceshipStatusOperational}
+	spaceships.On("GetAll", mock.Anything, domain.SpaceshipFilter{Status: domain.SpaceshipStatusOperational, Limit: 3}).Return([]*domain.Spaceship{
+		{ID: 1, Name: "Devastator", Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational},
+		{ID: 2, Name: "Executor", Class: "Star Dreadnought", Status: domain.SpaceshipStatusOperational},
+		{ID: 3, Name: "Avenger", Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational},
+	}, nil).Once()
+	spaceships.On("GetAll", mock.Anything, domain.SpaceshipFilter{Status: domain.SpaceshipStatusOperational, AfterID: 2, Limit: 3}).Return([]*domain.Spaceship{
+		{ID: 3, Name: "Avenger", Class: "Star Destroyer", Status: domain.SpaceshipStatusOperational},
+	}, nil).Twice()
+	spaceships.On("Count", mock.Anything, operational).Return(3, nil).Twice()
+	// armament of the whole page is loaded by one query
+	spaceships.On("GetArmament", mock.Anything, []uint{1, 2}).Return(map[uint][]domain.SpaceshipArmament{
+		1: {{ID: 1, Title: "Turbo Laser", Qty: 60}},
+	}, nil).Once()
+	spaceships.On("GetArmament", mock.Anything, []uint{3}).Return(map[uint][]domain.SpaceshipArmament{}, nil).Once()
+
+	query := `query Page($after: String) {
+		spaceships(first: 2, after: $after, filter: {status: OPERATIONAL}) {
+			totalCount
+			pageInfo { hasNextPage endCursor }
+			edges { node { id name status armament { title qty } fleet { name } } }
+		}
+	}`
+	res := schema.Execute(ctx, member, &graphql.Request{Query: query})
+	assert.JSONEq(t, `{"spaceships": {
+		"totalCount": 3,
+		"pageInfo": {"hasNextPage": true, "endCursor": "c3BhY2VzaGlwOjI"},
+		"edges": [
+			{"node": {"id": "1", "name": "Devastator", "status": "OPERATIONAL", "armament": [{"title": "Turbo Laser", "qty": 60}], "fleet": {"name": "Empire"}}},
+			{"node": {"id": "2", "name": "Executor", "status": "OPERATIONAL", "armament": [], "fleet": {"name": "Empire"}}}
+		]
+	}}`, data(t, res))
+
+	// the next page starts after cursor
+	res = schema.Execute(ctx, member, &graphql.Request{Query: query, Variables: map[string]interface{}{"after": "c3BhY2VzaGlwOjI"}})
+	assert.JSONEq(t, `{"spaceships": {
+		"totalCount": 3,
+		"pageInfo": {"hasNextPage": false, "endCursor": "c3BhY2VzaGlwOjM"},
+		"edges": [
+			{"node": {"id": "3", "name": "Avenger", "status": "OPERATIONAL", "armament": [], "fleet": {"name": "Empire"}}}
+		]
+	}}`, data(t, res))
+
+	// spaceships are not counted unless total count is selected
+	res = schema.Execute(ctx, member, &graphql.Request{Query: `query Page($after: String) {
+		spaceships(first: 2, after: $after, filter: {status: OPERATIONAL}) { edges { node { id } } }
+	}`, Variables: map[string]interface{}{"after": "c3BhY2VzaGlwOjI"}})
+	assert.JSONEq(t, `{"spaceships": {"edges": [{"node": {"id": "3"}}]}}`, data(t, res))
+
+	// broken cursor is an error of field
+	res = schema.Execute(ctx, member, &graphql.Request{Query: `{ spaceships(after: "x") { totalCount } }`})
+	require.Len(t, res.Errors, 1)
+	assert.ErrorIs(t, graphql.OriginalError(res.Errors[0]), domain.ErrConversion)
+}
+
+func TestSchema_Me(t *testing.T) {
+
+	ctx := context.Background()
+	schema, _, orgs := newSchema(t, graphql.Config{})
+
+	orgs.On("List", mock.Anything, uint(7)).Return([]*domain.Membership{
+		{OrgID: 1, OrgName: "Empire", Role: domain.OrgRoleMember},
+		{OrgID: 3, OrgName: "Death Star", Role: domain.OrgRoleAdmin},
+	}, nil)
+
+	res := schema.Execute(ctx, member, &graphql.Request{Query: `{ me { id email organizations { id name role } } fleet { id role } }`})
+	assert.JSONEq(t, `{
+		"me": {"id": "7", "email": "tarkin@empire.gov", "organizations": [
+			{"id": "1", "name": "Empire", "role": "Member"},
+			{"id": "3", "name": "Death Star", "role": "Admin"}
+		]},
+		"fleet": {"id": "1", "role": "Member"}
+	}`, data(t, res))
+}
+
+func TestSchema_Mutations(t *testing.T) {
+
+	ctx := context.Background()
+	schema, spaceships, _ := newSchema(t, graphql.Config{})
+
+	create := `mutation { createSpaceship(input: {name: "Devastator", class: "Star Destroyer", crew: 35000,
+		status: OPERATIONAL, armament: [{title: "Turbo Laser", qty: 60}]}) { id armament { title } } }`
+
+	// viewers and api keys without write scope can't change spaceships
+	viewer := &graphql.Viewer{Membership: &domain.Membership{OrgID: 1, UserID: 8, Role: domain.OrgRoleViewer}}
+	res := schema.Execute(ctx, viewer, &graphql.Request{Query: create})
+	require.Len(t, res.Errors, 1)
+	assert.ErrorIs(t, graphql.OriginalError(res.Errors[0]), domain.ErrForbidden)
+
+	readOnly := &graphql.Viewer{Membership: member.Membership, APIKey: &domain.APIKey{Scopes: []string{domain.ScopeSpaceshipsRead}}}
+	res = schema.Execute(ctx, readOnly, &graphql.Request{Query: create})
+	require.Len(t, res.Errors, 1)
+	assert.ErrorIs(t, graphql.OriginalError(res.Errors[0]), domain.ErrScopeMissing)
+
+	spaceships.On("CreateSpaceship", mock.Anything, &domain.Spaceship{
+		Name:     "Devastator",
+		Class:    "Star Destroyer",
+		Crew:     35000,
+		Status:   domain.SpaceshipStatusOperational,
+		Armament: []domain.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}},
+	}).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Spaceship).ID = 5
+	}).Return(nil).Once()
+	spaceships.On("GetById", mock.Anything, uint(5)).Return(&domain.Spaceship{
+		ID: 5, Name: "Devastator", Armament: []domain.SpaceshipArmament{{ID: 1, Title: "Turbo Laser", Qty: 60}},
+	}, nil).Once()
+	res = schema.Execute(ctx, member, &graphql.Request{Query: create})
+	assert.JSONEq(t, `{"createSpaceship": {"id": "5", "armament": [{"title": "Turbo Laser"}]}}`, data(t, res))
+
+	// absent fields of update are kept
+	crew := uint(40000)
+	spaceships.On("PatchSpaceship", mock.Anything, uint(5), &domain.SpaceshipUpdate{Crew: &crew}).
+		Return(&domain.Spaceship{ID: 5, Name: "Devastator", Crew: 40000}, nil).Once()
+	res = schema.Execute(ctx, member, &graphql.Request{Query: `mutation { updateSpaceship(id: "5", input: {crew: 40000}) { name crew } }`})
+	assert.JSONEq(t, `{"updateSpaceship": {"name": "Devastator", "crew": 40000}}`, data(t, res))
+
+	spaceships.On("DeleteSpaceship", mock.Anything, &domain.Spaceship{ID: 5}).Return(nil).Once()
+	res = schema.Execute(ctx, member, &graphql.Request{Query: `mutation { deleteSpaceship(id: "5") }`})
+	assert.JSONEq(t, `{"deleteSpaceship": true}`, data(t, res))
+}
+
+func TestSchema_Limits(t *testing.T) {
+
+	ctx := context.Background()
+	schema, _, _ := newSchema(t, graphql.Config{MaxDepth: 5, MaxComplexity: 100})
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		err       error
+	}{
+		{
+			name:  "depth",
+			query: `{ spaceships(first: 1) { edges { node { fleet { spaceships(first: 1) { totalCount } } } } } }`,
+			err:   domain.ErrQueryLimit,
+		},
+		{
+			name:  "depth in fragments",
+			query: `{ spaceships(first: 1) { ...edges } } fragment edges on SpaceshipConnection { edges { node { ...fleet } } } fragment fleet on Spaceship { fleet { spaceships { totalCount } } }`,
+			err:   domain.ErrQueryLimit,
+		},
+		{
+			name:  "complexity of default page",
+			query: `{ spaceships { edges { node { id name class crew value armament { title } } } } }`,
+			err:   domain.ErrQueryLimit,
+		},
+		{
+			name:      "complexity of page size variable",
+			query:     `query Page($first: Int) { spaceships(first: $first) { edges { node { id name } } } }`,
+			variables: map[string]interface{}{"first": float64(50)},
+			err:       domain.ErrQueryLimit,
+		},
+		{
+			name:  "introspection",
+			query: `{ __schema { types { name fields { name type { name ofType { name ofType { name ofType { name } } } } } } } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Logf("testing %s", tt.name)
+
+		res := schema.Execute(ctx, member, &graphql.Request{Query: tt.query, Variables: tt.variables})
+		if tt.err == nil {
+			assert.Empty(t, res.Errors)
+			continue
+		}
+		// nothing is resolved when limit is exceeded
+		assert.Nil(t, res.Data)
+		require.Len(t, res.Errors, 1)
+		assert.ErrorIs(t, graphql.OriginalError(res.Errors[0]), tt.err)
+	}
+}
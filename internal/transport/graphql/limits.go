@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+// fields which return pages, their selections are counted for every item of page
+var connectionFields = map[string]bool{
+	"spaceships": true,
+}
+
+// cost of selection set
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// analysis of query document, fragments are inlined where they are spread
+type analysis struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// costs of fragments which are computed once
+	costs map[string]cost
+	// fragments being inlined, cycles are reported by validation
+	visiting map[string]bool
+}
+
+// check depth and complexity of operations of document, all operations are checked when name is empty,
+// introspection fields are not limited
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, config Config) error {
+
+	a := &analysis{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		costs:     map[string]cost{},
+		visiting:  map[string]bool{},
+	}
+	operations := []*ast.OperationDefinition{}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || def.Name != nil && def.Name.Value == operationName {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		c := a.selectionSet(op.SelectionSet)
+		if config.MaxDepth > 0 && c.depth > config.MaxDepth {
+			return errors.Wrapf(domain.ErrQueryLimit, "%s: depth %d exceeds %d", graphqlErrorPrefix, c.depth, config.MaxDepth)
+		}
+		if config.MaxComplexity > 0 && c.complexity > config.MaxComplexity {
+			return errors.Wrapf(domain.ErrQueryLimit, "%s: complexity %d exceeds %d", graphqlErrorPrefix, c.complexity, config.MaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+func (a *analysis) selectionSet(set *ast.SelectionSet) cost {
+
+	total := cost{}
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		c := cost{}
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			c = a.selectionSet(selection.SelectionSet)
+			c.depth++
+			c.complexity = 1 + c.complexity*a.pageSize(selection)
+		case *ast.InlineFragment:
+			c = a.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			c = a.fragment(selection.Name.Value)
+		}
+		if c.depth > total.depth {
+			total.depth = c.depth
+		}
+		total.complexity += c.complexity
+	}
+
+	return total
+}
+
+func (a *analysis) fragment(name string) cost {
+	if c, ok := a.costs[name]; ok {
+		return c
+	}
+	fragment, ok := a.fragments[name]
+	if !ok || a.visiting[name] {
+		return cost{}
+	}
+	a.visiting[name] = true
+	c := a.selectionSet(fragment.SelectionSet)
+	delete(a.visiting, name)
+	a.costs[name] = c
+	return c
+}
+
+// count of items which selection of field is resolved for
+func (a *analysis) pageSize(field *ast.Field) int {
+	if !connectionFields[field.Name.Value] {
+		return 1
+	}
+	n := defaultPageSize
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			n, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := a.variables[value.Name.Value].(type) {
+			case int:
+				n = v
+			case float64:
+				n = int(v)
+			}
+		}
+	}
+	// negative size is rejected by resolver and must not lower complexity of other fields
+	if n < 0 {
+		n = 0
+	}
+	return n
+}
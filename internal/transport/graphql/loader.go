@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// loader of armaments which batches spaceships of one query level,
+// resolvers return thunks which are called after the whole level is resolved,
+// so the first thunk loads armaments of all spaceships requested by level by one query,
+// resolvers run sequentially, so loader is not locked
+type armamentLoader struct {
+	ctx        context.Context
+	spaceships SpaceshipService
+
+	pending map[uint]struct{}
+	loaded  map[uint][]domain.SpaceshipArmament
+	failed  map[uint]error
+}
+
+func newArmamentLoader(ctx context.Context, spaceships SpaceshipService) *armamentLoader {
+	return &armamentLoader{
+		ctx:        ctx,
+		spaceships: spaceships,
+		pending:    map[uint]struct{}{},
+		loaded:     map[uint][]domain.SpaceshipArmament{},
+		failed:     map[uint]error{},
+	}
+}
+
+// queue spaceships which are loaded with the next batch, e.g. page of list
+func (l *armamentLoader) prime(ids ...uint) {
+	for _, id := range ids {
+		_, loaded := l.loaded[id]
+		_, failed := l.failed[id]
+		if !loaded && !failed {
+			l.pending[id] = struct{}{}
+		}
+	}
+}
+
+// thunk of armament of spaceship
+func (l *armamentLoader) load(id uint) func() (interface{}, error) {
+	l.prime(id)
+	return func() (interface{}, error) {
+		if _, ok := l.pending[id]; ok {
+			l.flush()
+		}
+		if err, ok := l.failed[id]; ok {
+			return nil, err
+		}
+		return l.loaded[id], nil
+	}
+}
+
+// load armaments of all pending spaceships
+func (l *armamentLoader) flush() {
+	ids := make([]uint, 0, len(l.pending))
+	for id := range l.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	l.pending = map[uint]struct{}{}
+
+	armaments, err := l.spaceships.GetArmament(l.ctx, ids)
+	for _, id := range ids {
+		if err != nil {
+			l.failed[id] = err
+			continue
+		}
+		// spaceships without armament are absent
+		l.loaded[id] = armaments[id]
+		if l.loaded[id] == nil {
+			l.loaded[id] = []domain.SpaceshipArmament{}
+		}
+	}
+}
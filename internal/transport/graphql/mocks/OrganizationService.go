@@ -0,0 +1,56 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrganizationService is an autogenerated mock type for the OrganizationService type
+type OrganizationService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationService) List(_a0 context.Context, _a1 uint) ([]*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrganizationService creates a new instance of OrganizationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrganizationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrganizationService {
+	mock := &OrganizationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
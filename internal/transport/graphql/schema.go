@@ -0,0 +1,513 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/pkg/errors"
+)
+
+const (
+	// page size of connection without first argument
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	cursorPrefix = "spaceship:"
+)
+
+type (
+	viewerContextKey struct{}
+	loaderContextKey struct{}
+)
+
+// resolvers of schema fields
+type resolver struct {
+	spaceships SpaceshipService
+	orgs       OrganizationService
+}
+
+// organization of current user with role of user, fleet is organization selected by request
+type organization struct {
+	ID   uint
+	Name string
+	Role string
+}
+
+// current user
+type user struct {
+	ID    uint
+	Email string
+	// set for service account, which is a member of its own organization only
+	membership *domain.Membership
+}
+
+// page of spaceships, total count is counted by filter only if it is selected
+type connection struct {
+	Edges    []edge
+	PageInfo pageInfo
+	filter   domain.SpaceshipFilter
+}
+
+type edge struct {
+	Cursor string
+	Node   *domain.Spaceship
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+func (r *resolver) schemaConfig() gql.SchemaConfig {
+
+	status := gql.NewEnum(gql.EnumConfig{
+		Name: "SpaceshipStatus",
+		Values: gql.EnumValueConfigMap{
+			"OPERATIONAL": {Value: domain.SpaceshipStatusOperational},
+			"DAMAGED":     {Value: domain.SpaceshipStatusDamaged},
+		},
+	})
+
+	armament := gql.NewObject(gql.ObjectConfig{
+		Name: "Armament",
+		Fields: gql.Fields{
+			"id":    {Type: gql.NewNonNull(gql.ID)},
+			"title": {Type: gql.NewNonNull(gql.String)},
+			"qty":   {Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+
+	filter := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "SpaceshipFilter",
+		Fields: gql.InputObjectConfigFieldMap{
+			"name":   {Type: gql.String},
+			"class":  {Type: gql.String},
+			"status": {Type: status},
+		},
+	})
+
+	// fleet and spaceship refer to each other, so fields of fleet are resolved lazily
+	var spaceshipConnection *gql.Object
+	fleetType := gql.NewObject(gql.ObjectConfig{
+		Name: "Fleet",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":   {Type: gql.NewNonNull(gql.ID)},
+				"name": {Type: gql.NewNonNull(gql.String)},
+				// role of current user in organization
+				"role":       {Type: gql.NewNonNull(gql.String)},
+				"spaceships": r.spaceshipsField(spaceshipConnection, filter),
+			}
+		}),
+	})
+
+	spaceship := gql.NewObject(gql.ObjectConfig{
+		Name: "Spaceship",
+		Fields: gql.Fields{
+			"id":       {Type: gql.NewNonNull(gql.ID)},
+			"name":     {Type: gql.NewNonNull(gql.String)},
+			"class":    {Type: gql.NewNonNull(gql.String)},
+			"classId":  {Type: gql.ID, Resolve: r.spaceshipClassID},
+			"crew":     {Type: gql.NewNonNull(gql.Int)},
+			"image":    {Type: gql.NewNonNull(gql.String)},
+			"value":    {Type: gql.NewNonNull(gql.Float), Resolve: r.spaceshipValue},
+			"currency": {Type: gql.NewNonNull(gql.String)},
+			"status":   {Type: status},
+			"armament": {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(armament))), Resolve: r.spaceshipArmament},
+			"fleet":    {Type: gql.NewNonNull(fleetType), Resolve: r.fleet},
+		},
+	})
+
+	spaceshipConnection = gql.NewObject(gql.ObjectConfig{
+		Name: "SpaceshipConnection",
+		Fields: gql.Fields{
+			"edges": {Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.NewObject(gql.ObjectConfig{
+				Name: "SpaceshipEdge",
+				Fields: gql.Fields{
+					"cursor": {Type: gql.NewNonNull(gql.String)},
+					"node":   {Type: gql.NewNonNull(spaceship)},
+				},
+			}))))},
+			"pageInfo": {Type: gql.NewNonNull(gql.NewObject(gql.ObjectConfig{
+				Name: "PageInfo",
+				Fields: gql.Fields{
+					"hasNextPage": {Type: gql.NewNonNull(gql.Boolean)},
+					"endCursor":   {Type: gql.String},
+				},
+			}))},
+			"totalCount": {Type: gql.NewNonNull(gql.Int), Resolve: r.totalCount},
+		},
+	})
+
+	userType := gql.NewObject(gql.ObjectConfig{
+		Name: "User",
+		Fields: gql.Fields{
+			"id":    {Type: gql.NewNonNull(gql.ID)},
+			"email": {Type: gql.NewNonNull(gql.String)},
+			"organizations": {
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.NewObject(gql.ObjectConfig{
+					Name: "Organization",
+					Fields: gql.Fields{
+						"id":   {Type: gql.NewNonNull(gql.ID)},
+						"name": {Type: gql.NewNonNull(gql.String)},
+						"role": {Type: gql.NewNonNull(gql.String)},
+					},
+				})))),
+				Resolve: r.organizations,
+			},
+		},
+	})
+
+	armamentInput := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "ArmamentInput",
+		Fields: gql.InputObjectConfigFieldMap{
+			"title": {Type: gql.NewNonNull(gql.String)},
+			"qty":   {Type: gql.NewNonNull(gql.Int)},
+		},
+	})
+	// fields of spaceship input, name is required on create only
+	inputFields := func(name gql.Input) gql.InputObjectConfigFieldMap {
+		return gql.InputObjectConfigFieldMap{
+			"name":     {Type: name},
+			"class":    {Type: gql.String},
+			"classId":  {Type: gql.ID},
+			"crew":     {Type: gql.Int},
+			"image":    {Type: gql.String},
+			"value":    {Type: gql.Float},
+			"currency": {Type: gql.String},
+			"status":   {Type: status},
+			"armament": {Type: gql.NewList(gql.NewNonNull(armamentInput))},
+		}
+	}
+	id := gql.FieldConfigArgument{"id": {Type: gql.NewNonNull(gql.ID)}}
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"me":         {Type: gql.NewNonNull(userType), Resolve: r.me},
+			"fleet":      {Type: gql.NewNonNull(fleetType), Resolve: r.fleet},
+			"spaceship":  {Type: spaceship, Args: id, Resolve: r.spaceship},
+			"spaceships": r.spaceshipsField(spaceshipConnection, filter),
+		},
+	})
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createSpaceship": {
+				Type: gql.NewNonNull(spaceship),
+				Args: gql.FieldConfigArgument{"input": {Type: gql.NewNonNull(gql.NewInputObject(gql.InputObjectConfig{
+					Name:   "CreateSpaceshipInput",
+					Fields: inputFields(gql.NewNonNull(gql.String)),
+				}))}},
+				Resolve: r.createSpaceship,
+			},
+			// fields absent in input are kept, armament replaces the whole armament
+			"updateSpaceship": {
+				Type: gql.NewNonNull(spaceship),
+				Args: gql.FieldConfigArgument{
+					"id": {Type: gql.NewNonNull(gql.ID)},
+					"input": {Type: gql.NewNonNull(gql.NewInputObject(gql.InputObjectConfig{
+						Name:   "UpdateSpaceshipInput",
+						Fields: inputFields(gql.String),
+					}))},
+				},
+				Resolve: r.updateSpaceship,
+			},
+			"deleteSpaceship": {Type: gql.NewNonNull(gql.Boolean), Args: id, Resolve: r.deleteSpaceship},
+		},
+	})
+
+	return gql.SchemaConfig{Query: query, Mutation: mutation}
+}
+
+// connection field of spaceships matching filter
+func (r *resolver) spaceshipsField(connection *gql.Object, filter *gql.InputObject) *gql.Field {
+	return &gql.Field{
+		Type: gql.NewNonNull(connection),
+		Args: gql.FieldConfigArgument{
+			"first":  {Type: gql.Int, Description: "page size, 20 by default, 100 at most"},
+			"after":  {Type: gql.String, Description: "end cursor of previous page"},
+			"filter": {Type: filter},
+		},
+		Resolve: r.spaceshipConnection,
+	}
+}
+
+func (r *resolver) me(p gql.ResolveParams) (interface{}, error) {
+	v := viewer(p.Context)
+	if v.APIKey != nil && v.APIKey.ServiceAccount != nil {
+		return &user{ID: v.APIKey.ServiceAccount.ID, membership: v.Membership}, nil
+	}
+	return &user{ID: v.Membership.UserID, Email: v.Membership.UserEmail}, nil
+}
+
+func (r *resolver) organizations(p gql.ResolveParams) (interface{}, error) {
+	u := p.Source.(*user)
+	memberships := []*domain.Membership{u.membership}
+	if u.membership == nil {
+		var err error
+		memberships, err = r.orgs.List(p.Context, u.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	orgs := make([]*organization, 0, len(memberships))
+	for _, m := range memberships {
+		orgs = append(orgs, &organization{ID: m.OrgID, Name: m.OrgName, Role: m.Role.String()})
+	}
+	return orgs, nil
+}
+
+func (r *resolver) fleet(p gql.ResolveParams) (interface{}, error) {
+	membership := viewer(p.Context).Membership
+	return &organization{ID: membership.OrgID, Name: membership.OrgName, Role: membership.Role.String()}, nil
+}
+
+func (r *resolver) spaceship(p gql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	spaceship, err := r.spaceships.GetById(p.Context, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return spaceship, err
+}
+
+// page of spaceships after cursor, armament of page is loaded at once
+func (r *resolver) spaceshipConnection(p gql.ResolveParams) (interface{}, error) {
+
+	first := defaultPageSize
+	if v, ok := p.Args["first"].(int); ok {
+		first = v
+	}
+	if first < 0 || first > maxPageSize {
+		return nil, errors.Wrapf(domain.ErrQueryLimit, "%s: first must be between 0 and %d", graphqlErrorPrefix, maxPageSize)
+	}
+	var after uint
+	if cursor, ok := p.Args["after"].(string); ok {
+		id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = id
+	}
+
+	filter := domain.SpaceshipFilter{}
+	if f, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter.Name, _ = f["name"].(string)
+		filter.Class, _ = f["class"].(string)
+		filter.Status, _ = f["status"].(domain.SpaceshipStatus)
+	}
+
+	// spaceships are ordered by id, so id is stable cursor, one more spaceship tells there is next page
+	page := &connection{filter: filter}
+	filter.AfterID = after
+	filter.Limit = first + 1
+	spaceships, err := r.spaceships.GetAll(p.Context, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(spaceships) > first {
+		spaceships = spaceships[:first]
+		page.PageInfo.HasNextPage = true
+	}
+
+	page.Edges = make([]edge, 0, len(spaceships))
+	ids := make([]uint, 0, len(spaceships))
+	for _, spaceship := range spaceships {
+		page.Edges = append(page.Edges, edge{Cursor: encodeCursor(spaceship.ID), Node: spaceship})
+		ids = append(ids, spaceship.ID)
+	}
+	if len(page.Edges) > 0 {
+		page.PageInfo.EndCursor = page.Edges[len(page.Edges)-1].Cursor
+	}
+	loader(p.Context).prime(ids...)
+
+	return page, nil
+}
+
+// count of spaceships matching filter of connection
+func (r *resolver) totalCount(p gql.ResolveParams) (interface{}, error) {
+	return r.spaceships.Count(p.Context, p.Source.(*connection).filter)
+}
+
+func (r *resolver) spaceshipClassID(p gql.ResolveParams) (interface{}, error) {
+	spaceship := p.Source.(*domain.Spaceship)
+	if spaceship.ClassID == 0 {
+		return nil, nil
+	}
+	return spaceship.ClassID, nil
+}
+
+// fixed-point value is given as float
+func (r *resolver) spaceshipValue(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(*domain.Spaceship).Value.Float64(), nil
+}
+
+// armament of lists is loaded by loader, spaceship read by id has it already
+func (r *resolver) spaceshipArmament(p gql.ResolveParams) (interface{}, error) {
+	spaceship := p.Source.(*domain.Spaceship)
+	if spaceship.Armament != nil {
+		return spaceship.Armament, nil
+	}
+	return loader(p.Context).load(spaceship.ID), nil
+}
+
+func (r *resolver) createSpaceship(p gql.ResolveParams) (interface{}, error) {
+
+	err := viewer(p.Context).canWrite()
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := spaceshipUpdate(p.Args["input"].(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	spaceship := &domain.Spaceship{}
+	update.Apply(spaceship)
+
+	err = r.spaceships.CreateSpaceship(p.Context, spaceship)
+	if err != nil {
+		return nil, err
+	}
+
+	// defaults of class are filled on create
+	return r.spaceships.GetById(p.Context, spaceship.ID)
+}
+
+func (r *resolver) updateSpaceship(p gql.ResolveParams) (interface{}, error) {
+
+	err := viewer(p.Context).canWrite()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	update, err := spaceshipUpdate(p.Args["input"].(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.spaceships.PatchSpaceship(p.Context, id, update)
+}
+
+func (r *resolver) deleteSpaceship(p gql.ResolveParams) (interface{}, error) {
+
+	err := viewer(p.Context).canWrite()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.spaceships.DeleteSpaceship(p.Context, &domain.Spaceship{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+// update of fields present in input, null fields are absent
+func spaceshipUpdate(input map[string]interface{}) (*domain.SpaceshipUpdate, error) {
+
+	update := &domain.SpaceshipUpdate{}
+	if v, ok := input["name"].(string); ok {
+		update.Name = &v
+	}
+	if v, ok := input["class"].(string); ok {
+		update.Class = &v
+	}
+	if v, ok := input["classId"]; ok && v != nil {
+		id, err := parseID(v)
+		if err != nil {
+			return nil, err
+		}
+		update.ClassID = &id
+	}
+	if v, ok := input["crew"].(int); ok {
+		if v < 0 {
+			return nil, errors.Wrapf(domain.ErrConversion, "%s: crew can't be negative", graphqlErrorPrefix)
+		}
+		crew := uint(v)
+		update.Crew = &crew
+	}
+	if v, ok := input["image"].(string); ok {
+		update.Image = &v
+	}
+	if v, ok := input["value"].(float64); ok {
+		value, err := domain.DecimalFromFloat(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: value", graphqlErrorPrefix)
+		}
+		update.Value = &value
+	}
+	if v, ok := input["currency"].(string); ok {
+		update.Currency = &v
+	}
+	if v, ok := input["status"].(domain.SpaceshipStatus); ok {
+		update.Status = &v
+	}
+	if v, ok := input["armament"].([]interface{}); ok {
+		armament := make([]domain.SpaceshipArmament, 0, len(v))
+		for _, a := range v {
+			a := a.(map[string]interface{})
+			qty := a["qty"].(int)
+			if qty < 0 {
+				return nil, errors.Wrapf(domain.ErrConversion, "%s: qty can't be negative", graphqlErrorPrefix)
+			}
+			armament = append(armament, domain.SpaceshipArmament{Title: a["title"].(string), Qty: uint(qty)})
+		}
+		update.Armament = &armament
+	}
+
+	return update, nil
+}
+
+func parseID(v interface{}) (uint, error) {
+	s, _ := v.(string)
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(domain.ErrConversion, "%s: id %q", graphqlErrorPrefix, s)
+	}
+	return uint(id), nil
+}
+
+// opaque cursor of spaceship
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(cursor string) (uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(b), cursorPrefix) {
+		id, err := strconv.ParseUint(strings.TrimPrefix(string(b), cursorPrefix), 10, 32)
+		if err == nil {
+			return uint(id), nil
+		}
+	}
+	return 0, errors.Wrapf(domain.ErrConversion, "%s: cursor %q", graphqlErrorPrefix, cursor)
+}
+
+func viewer(ctx context.Context) *Viewer {
+	return ctx.Value(viewerContextKey{}).(*Viewer)
+}
+
+func loader(ctx context.Context) *armamentLoader {
+	return ctx.Value(loaderContextKey{}).(*armamentLoader)
+}
@@ -0,0 +1,1496 @@
+// Package client is typed http client of rest api v1
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/jsonpatch"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errors prefix
+	clientErrorPrefix = "[transport.rest.client]"
+)
+
+// error responded by api
+type APIError struct {
+	StatusCode int
+	Message    string
+	// raw responce, e.g. with details of error
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// check if error is api error with status
+func IsStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// client option
+type Option func(*Client)
+
+// use custom http client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// use previously issued tokens
+func WithTokens(authToken string, refreshToken string) Option {
+	return func(c *Client) {
+		c.tokens = model.UserAuthRes{AuthToken: authToken, RefreshToken: refreshToken}
+	}
+}
+
+// retry idempotent requests on network errors and temporary statuses
+func WithRetry(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+// callback called every time tokens are issued by login or refresh
+func WithTokenHandler(handler func(model.UserAuthRes)) Option {
+	return func(c *Client) {
+		c.onTokens = handler
+	}
+}
+
+// authenticate with api key instead of tokens
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// make spaceship requests for organization instead of default one of user
+func WithOrg(orgID uint) Option {
+	return func(c *Client) {
+		c.orgID = orgID
+	}
+}
+
+// api client, safe for concurrent use
+type Client struct {
+	baseURL    string
+	apiKey     string
+	orgID      uint
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+	onTokens   func(model.UserAuthRes)
+
+	mu     sync.RWMutex
+	tokens model.UserAuthRes
+}
+
+// client builder, base url is server address without api version
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		retryWait:  200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// current tokens
+func (c *Client) Tokens() model.UserAuthRes {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokens
+}
+
+func (c *Client) setTokens(tokens model.UserAuthRes) {
+	c.mu.Lock()
+	c.tokens = tokens
+	c.mu.Unlock()
+	if c.onTokens != nil {
+		c.onTokens(tokens)
+	}
+}
+
+// authenticate with email and password, issued tokens are used for next requests,
+// when second factor is required responce has mfa token for VerifyMFA instead
+func (c *Client) Login(ctx context.Context, email string, password string) (*model.UserAuthRes, error) {
+	res := new(model.UserAuthRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/auth",
+		body:   model.UserAuthReq{Email: email, Password: password},
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	if res.MFAToken == "" {
+		c.setTokens(*res)
+	}
+	return res, nil
+}
+
+// exchange mfa token from Login and totp or recovery code for tokens
+func (c *Client) VerifyMFA(ctx context.Context, mfaToken string, code string) (*model.UserAuthRes, error) {
+	res := new(model.UserAuthRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/mfa/verify",
+		body:   model.MFAVerifyReq{MFAToken: mfaToken, Code: code},
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	c.setTokens(*res)
+	return res, nil
+}
+
+// register new user, issued tokens are used for next requests
+func (c *Client) Register(ctx context.Context, req model.UserRegisterReq) (*model.UserAuthRes, error) {
+	res := new(model.UserAuthRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/register",
+		body:   req,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	c.setTokens(*res)
+	return res, nil
+}
+
+// exchange refresh token for new tokens, fails when second factor is required since login
+func (c *Client) Refresh(ctx context.Context) error {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return errors.Wrapf(&APIError{StatusCode: http.StatusUnauthorized, Message: "no refresh token"}, "%s: refresh", clientErrorPrefix)
+	}
+	res := new(model.UserAuthRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/refresh",
+		body:   model.UserRefreshReq{RefreshToken: refreshToken},
+	}, res)
+	if err != nil {
+		return err
+	}
+	// second factor was enabled since login, user must login again
+	if res.MFAToken != "" {
+		return errors.Wrapf(&APIError{StatusCode: http.StatusUnauthorized, Message: "mfa required"}, "%s: refresh", clientErrorPrefix)
+	}
+	c.setTokens(*res)
+	return nil
+}
+
+// filter of spaceships list, empty fields are not applied
+type SpaceshipFilter struct {
+	Name   string
+	Class  string
+	Status string
+	Sector string
+	System string
+}
+
+func (f SpaceshipFilter) query() url.Values {
+	q := url.Values{}
+	if f.Name != "" {
+		q.Set("name", f.Name)
+	}
+	if f.Class != "" {
+		q.Set("class", f.Class)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if f.Sector != "" {
+		q.Set("sector", f.Sector)
+	}
+	if f.System != "" {
+		q.Set("system", f.System)
+	}
+	return q
+}
+
+func (c *Client) ListSpaceships(ctx context.Context, filter SpaceshipFilter) ([]model.SpaceshipShort, error) {
+	res := new(model.SpaceshipsResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships",
+		query:      filter.query(),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) GetSpaceship(ctx context.Context, id uint) (*model.SpaceshipFull, error) {
+	res := new(model.SpaceshipFull)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readiness score of spaceship with breakdown by factors
+func (c *Client) SpaceshipReadiness(ctx context.Context, id uint) (*model.Readiness, error) {
+	res := new(model.Readiness)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/readiness",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readiness of fleet of organization
+func (c *Client) FleetReadiness(ctx context.Context) (*model.FleetReadiness, error) {
+	res := new(model.FleetReadiness)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/readiness",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// book value of spaceship with history of appraisals
+func (c *Client) SpaceshipValuation(ctx context.Context, id uint) (*model.SpaceshipValuation, error) {
+	res := new(model.SpaceshipValuation)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/valuation",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// book value of fleet of organization by currency
+func (c *Client) FleetValuation(ctx context.Context) (*model.FleetValuation, error) {
+	res := new(model.FleetValuation)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/valuation",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// appraisal is retried with idempotency key, so it is recorded once
+func (c *Client) AppraiseSpaceship(ctx context.Context, id uint, req model.AppraisalReq) (*model.SpaceshipValuation, error) {
+	res := new(model.SpaceshipValuation)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/valuations",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// report is retried with idempotency key, so position is logged once
+func (c *Client) ReportPosition(ctx context.Context, id uint, req model.PositionReportReq) (*model.Position, error) {
+	res := new(model.Position)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/position",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// logged positions of spaceship from the newest, default count if limit is zero
+func (c *Client) SpaceshipPositions(ctx context.Context, id uint, limit int) ([]model.Position, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	res := new(model.PositionsResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/positions",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// spaceships within radius of point from the nearest
+func (c *Client) NearSpaceships(ctx context.Context, point model.Coordinates, radius float64, limit int) (*model.ProximityResponce, error) {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	query := url.Values{
+		"x":      {format(point.X)},
+		"y":      {format(point.Y)},
+		"z":      {format(point.Z)},
+		"radius": {format(radius)},
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	res := new(model.ProximityResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/near",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// route of spaceship from its star system to destination, fastest if mode is empty
+func (c *Client) PlanRoute(ctx context.Context, id uint, req model.RouteReq) (*model.Route, error) {
+	res := new(model.Route)
+	err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/routes",
+		body:       req,
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// star systems and hyperlanes of route planner with active closures
+func (c *Client) Galaxy(ctx context.Context) (*model.Galaxy, error) {
+	res := new(model.Galaxy)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/hyperspace",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replace galaxy by yaml or json one, admins only
+func (c *Client) UploadGalaxy(ctx context.Context, data []byte, contentType string) (*model.Galaxy, error) {
+	res := new(model.Galaxy)
+	err := c.do(ctx, request{
+		method:      http.MethodPut,
+		path:        "/v1/hyperspace",
+		body:        data,
+		contentType: contentType,
+		auth:        true,
+		idempotent:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// close hyperlane, admins only
+func (c *Client) CloseHyperlane(ctx context.Context, req model.HyperlaneClosureReq) (*model.HyperlaneClosure, error) {
+	res := new(model.HyperlaneClosure)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/hyperspace/closures",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// open hyperlane by removing its closure, admins only
+func (c *Client) OpenHyperlane(ctx context.Context, id uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/hyperspace/closures/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// create is retried with idempotency key, so spaceship is created once
+func (c *Client) CreateSpaceship(ctx context.Context, spaceship *model.SpaceshipFull) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/spaceships",
+		body:   spaceship,
+		auth:   true,
+		keyed:  true,
+	}, new(model.PostResponce))
+}
+
+// update replaces all spaceship fields, so it is safe to retry
+func (c *Client) UpdateSpaceship(ctx context.Context, id uint, spaceship *model.SpaceshipFull) error {
+	return c.do(ctx, request{
+		method:     http.MethodPut,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10),
+		body:       spaceship,
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// run batch of spaceship operations, results of failed atomic batch are returned with error
+func (c *Client) BatchSpaceships(ctx context.Context, batch *model.SpaceshipBatch) ([]model.SpaceshipOperationResult, error) {
+	res := new(model.SpaceshipBatchResponce)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/spaceships/batch",
+		body:   batch,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && len(apiErr.Body) > 0 {
+		_ = json.Unmarshal(apiErr.Body, res)
+	}
+	return res.Data, err
+}
+
+// run graphql query or mutation, data is decoded into data even if some fields failed,
+// errors of query and fields are returned as model.GraphQLErrors
+func (c *Client) GraphQL(ctx context.Context, req *model.GraphQLRequest, data interface{}) error {
+	res := new(model.GraphQLResponce)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/graphql",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return err
+	}
+	if data != nil && len(res.Data) > 0 {
+		err = json.Unmarshal(res.Data, data)
+		if err != nil {
+			return errors.Wrapf(err, "%s: decode graphql data", clientErrorPrefix)
+		}
+	}
+	if len(res.Errors) > 0 {
+		return res.Errors
+	}
+	return nil
+}
+
+// merge patch changes only given fields, null resets field
+func (c *Client) MergePatchSpaceship(ctx context.Context, id uint, patch map[string]interface{}) (*model.SpaceshipFull, error) {
+	return c.patchSpaceship(ctx, id, patch, "application/merge-patch+json")
+}
+
+// json patch applies operations to spaceship, it is retried with idempotency key
+// as operations may be not idempotent
+func (c *Client) JSONPatchSpaceship(ctx context.Context, id uint, patch jsonpatch.Patch) (*model.SpaceshipFull, error) {
+	return c.patchSpaceship(ctx, id, patch, "application/json-patch+json")
+}
+
+func (c *Client) patchSpaceship(ctx context.Context, id uint, patch interface{}, contentType string) (*model.SpaceshipFull, error) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: encode patch", clientErrorPrefix)
+	}
+	res := new(model.SpaceshipFull)
+	err = c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10),
+		body:        body,
+		contentType: contentType,
+		auth:        true,
+		keyed:       true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteSpaceship(ctx context.Context, id uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// upload jpeg, png or webp image of spaceship, replacing previous one
+func (c *Client) UploadSpaceshipImage(ctx context.Context, id uint, filename string, image []byte) error {
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("image", filename)
+	if err != nil {
+		return errors.Wrapf(err, "%s: build form", clientErrorPrefix)
+	}
+	_, err = part.Write(image)
+	if err != nil {
+		return errors.Wrapf(err, "%s: build form", clientErrorPrefix)
+	}
+	err = form.Close()
+	if err != nil {
+		return errors.Wrapf(err, "%s: build form", clientErrorPrefix)
+	}
+
+	return c.do(ctx, request{
+		method:      http.MethodPut,
+		path:        "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/image",
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+		auth:        true,
+		idempotent:  true,
+	}, new(model.PostResponce))
+}
+
+// get image of spaceship in size, empty size is original
+func (c *Client) GetSpaceshipImage(ctx context.Context, id uint, size string) ([]byte, error) {
+
+	query := url.Values{}
+	if size != "" {
+		query.Set("size", size)
+	}
+
+	var image []byte
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/spaceships/" + strconv.FormatUint(uint64(id), 10) + "/image",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+	}, &image)
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// classes of catalog shared by organizations
+func (c *Client) ListShipClasses(ctx context.Context) ([]model.ShipClass, error) {
+	res := new(model.ShipClassesResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/classes",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) GetShipClass(ctx context.Context, id uint) (*model.ShipClass, error) {
+	res := new(model.ShipClass)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/classes/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// create class of catalog, admins only
+func (c *Client) CreateShipClass(ctx context.Context, class model.ShipClass) (*model.ShipClass, error) {
+	res := new(model.ShipClass)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/classes",
+		body:   class,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// update class of catalog, admins only
+func (c *Client) UpdateShipClass(ctx context.Context, id uint, class model.ShipClass) (*model.ShipClass, error) {
+	res := new(model.ShipClass)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/classes/" + strconv.FormatUint(uint64(id), 10),
+		body:   class,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteShipClass(ctx context.Context, id uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/classes/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// link spaceships of all organizations to classes by name or alias, admins only
+func (c *Client) NormalizeShipClasses(ctx context.Context) (*model.ShipClassNormalizeRes, error) {
+	res := new(model.ShipClassNormalizeRes)
+	err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       "/v1/classes/normalize",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// filter of crew list, empty fields are not applied
+type CrewFilter struct {
+	SpaceshipID uint
+	Discharged  bool
+}
+
+func (f CrewFilter) query() url.Values {
+	q := url.Values{}
+	if f.SpaceshipID != 0 {
+		q.Set("spaceship", strconv.FormatUint(uint64(f.SpaceshipID), 10))
+	}
+	if f.Discharged {
+		q.Set("discharged", "true")
+	}
+	return q
+}
+
+func (c *Client) ListCrew(ctx context.Context, filter CrewFilter) ([]model.CrewMember, error) {
+	res := new(model.CrewResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/crew",
+		query:      filter.query(),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// crew member with assignments
+func (c *Client) GetCrewMember(ctx context.Context, id uint) (*model.CrewMember, error) {
+	res := new(model.CrewMember)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/crew/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) EnlistCrewMember(ctx context.Context, req model.CrewEnlistReq) (*model.CrewMember, error) {
+	res := new(model.CrewMember)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/crew",
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) TransferCrewMember(ctx context.Context, id uint, spaceshipID uint) (*model.CrewMember, error) {
+	res := new(model.CrewMember)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/crew/" + strconv.FormatUint(uint64(id), 10) + "/transfer",
+		body:   model.CrewTransferReq{SpaceshipID: spaceshipID},
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DischargeCrewMember(ctx context.Context, id uint) (*model.CrewMember, error) {
+	res := new(model.CrewMember)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/crew/" + strconv.FormatUint(uint64(id), 10) + "/discharge",
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// filter of work orders, open orders are listed when status is empty
+type WorkOrderFilter struct {
+	SpaceshipID uint
+	// "open", "all" or one of statuses
+	Status string
+}
+
+func (f WorkOrderFilter) query() url.Values {
+	q := url.Values{}
+	if f.SpaceshipID != 0 {
+		q.Set("spaceship", strconv.FormatUint(uint64(f.SpaceshipID), 10))
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	return q
+}
+
+func (c *Client) ListWorkOrders(ctx context.Context, filter WorkOrderFilter) ([]model.WorkOrder, error) {
+	res := new(model.WorkOrdersResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/workorders",
+		query:      filter.query(),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) GetWorkOrder(ctx context.Context, id uint) (*model.WorkOrder, error) {
+	res := new(model.WorkOrder)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/workorders/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// report damage, spaceship is marked damaged
+func (c *Client) ReportDamage(ctx context.Context, req model.WorkOrderReportReq) (*model.WorkOrder, error) {
+	res := new(model.WorkOrder)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/workorders",
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateWorkOrder(ctx context.Context, id uint, req model.WorkOrderUpdateReq) (*model.WorkOrder, error) {
+	res := new(model.WorkOrder)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/workorders/" + strconv.FormatUint(uint64(id), 10),
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// move work order to status, closing of the last order makes spaceship operational
+func (c *Client) MoveWorkOrder(ctx context.Context, id uint, status string) (*model.WorkOrder, error) {
+	res := new(model.WorkOrder)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/workorders/" + strconv.FormatUint(uint64(id), 10) + "/status",
+		body:   model.WorkOrderStatusReq{Status: status},
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// work orders of organization which breach sla
+func (c *Client) WorkOrderSLA(ctx context.Context) (*model.WorkOrderSLAResponce, error) {
+	res := new(model.WorkOrderSLAResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/workorders/sla",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// filter of missions, open missions are listed when status is empty
+type MissionFilter struct {
+	SpaceshipID uint
+	// "open", "all" or one of statuses
+	Status string
+	// missions which windows overlap window from until to
+	From time.Time
+	To   time.Time
+}
+
+func (f MissionFilter) query() url.Values {
+	q := url.Values{}
+	if f.SpaceshipID != 0 {
+		q.Set("spaceship", strconv.FormatUint(uint64(f.SpaceshipID), 10))
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.UTC().Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+func (c *Client) ListMissions(ctx context.Context, filter MissionFilter) ([]model.Mission, error) {
+	res := new(model.MissionsResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/missions",
+		query:      filter.query(),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) GetMission(ctx context.Context, id uint) (*model.Mission, error) {
+	res := new(model.Mission)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/missions/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// plan mission, assigned spaceships are booked for its window
+func (c *Client) PlanMission(ctx context.Context, req model.MissionReq) (*model.Mission, error) {
+	res := new(model.Mission)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/missions",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateMission(ctx context.Context, id uint, req model.MissionReq) (*model.Mission, error) {
+	res := new(model.Mission)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/missions/" + strconv.FormatUint(uint64(id), 10),
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// move mission to status, spaceships of active mission can't be deleted
+func (c *Client) MoveMission(ctx context.Context, id uint, status string) (*model.Mission, error) {
+	res := new(model.Mission)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/missions/" + strconv.FormatUint(uint64(id), 10) + "/status",
+		body:   model.MissionStatusReq{Status: status},
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// report params, zero bounds are not applied
+type ReportFilter struct {
+	Group []string
+	From  time.Time
+	To    time.Time
+}
+
+func (f ReportFilter) query() url.Values {
+	q := url.Values{}
+	if len(f.Group) > 0 {
+		q.Set("group", strings.Join(f.Group, ","))
+	}
+	if !f.From.IsZero() {
+		q.Set("from", f.From.Format(time.RFC3339))
+	}
+	if !f.To.IsZero() {
+		q.Set("to", f.To.Format(time.RFC3339))
+	}
+	return q
+}
+
+func (c *Client) FleetReport(ctx context.Context, filter ReportFilter) ([]model.FleetReportRow, error) {
+	res := new(model.FleetReportResponce)
+	err := c.report(ctx, "fleet", filter, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) ArmamentReport(ctx context.Context, filter ReportFilter) ([]model.ArmamentReportRow, error) {
+	res := new(model.ArmamentReportResponce)
+	err := c.report(ctx, "armament", filter, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) ReadinessReport(ctx context.Context, filter ReportFilter) (*model.ReadinessReportResponce, error) {
+	res := new(model.ReadinessReportResponce)
+	err := c.report(ctx, "readiness", filter, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) TrendsReport(ctx context.Context, filter ReportFilter) ([]model.FleetSnapshot, error) {
+	res := new(model.TrendsReportResponce)
+	err := c.report(ctx, "trends", filter, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// report of name as csv with header row
+func (c *Client) ReportCSV(ctx context.Context, name string, filter ReportFilter) ([]byte, error) {
+	query := filter.query()
+	query.Set("format", "csv")
+	res := []byte{}
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/reports/" + name,
+		query:      query,
+		auth:       true,
+		idempotent: true,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) report(ctx context.Context, name string, filter ReportFilter, res interface{}) error {
+	return c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/reports/" + name,
+		query:      filter.query(),
+		auth:       true,
+		idempotent: true,
+	}, res)
+}
+
+// metrics of spaceship cache, admin only
+func (c *Client) CacheStats(ctx context.Context) (*model.CacheStats, error) {
+	res := new(model.CacheStats)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/cache",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// purge spaceship cache, admin only
+func (c *Client) PurgeCache(ctx context.Context) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/cache",
+		auth:       true,
+		idempotent: true,
+	}, nil)
+}
+
+// spaceships of organization matched by query, zero limit is server default
+func (c *Client) Search(ctx context.Context, q string, limit int) (*model.SearchResponce, error) {
+
+	query := url.Values{"q": {q}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	res := new(model.SearchResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/search",
+		query:      query,
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// rebuild search index from database, admins only
+func (c *Client) Reindex(ctx context.Context) (*model.SearchReindexRes, error) {
+	res := new(model.SearchReindexRes)
+	err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       "/v1/search/reindex",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	res := new(model.APIKeysResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/apikeys",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// create api key, raw key is returned only once
+func (c *Client) CreateAPIKey(ctx context.Context, req model.APIKeyCreateReq) (*model.APIKeyCreateRes, error) {
+	res := new(model.APIKeyCreateRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/apikeys",
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteAPIKey(ctx context.Context, id uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/apikeys/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// organizations of user with role of user
+func (c *Client) ListOrganizations(ctx context.Context) ([]model.Organization, error) {
+	res := new(model.OrganizationsResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/orgs",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// create organization, user becomes its admin
+func (c *Client) CreateOrganization(ctx context.Context, name string) (*model.Organization, error) {
+	res := new(model.Organization)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/orgs",
+		body:   model.OrganizationCreateReq{Name: name},
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) ListMembers(ctx context.Context, orgID uint) ([]model.Member, error) {
+	res := new(model.MembersResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/members",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// add member or change role of member
+func (c *Client) PutMember(ctx context.Context, orgID uint, req model.MemberReq) (*model.Member, error) {
+	res := new(model.Member)
+	err := c.do(ctx, request{
+		method:     http.MethodPut,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/members",
+		body:       req,
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteMember(ctx context.Context, orgID uint, userID uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/members/" + strconv.FormatUint(uint64(userID), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// service accounts of organization, managed by its admins
+func (c *Client) ListServiceAccounts(ctx context.Context, orgID uint) ([]model.ServiceAccount, error) {
+	res := new(model.ServiceAccountsResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (c *Client) CreateServiceAccount(ctx context.Context, orgID uint, req model.ServiceAccountCreateReq) (*model.ServiceAccount, error) {
+	res := new(model.ServiceAccount)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts",
+		body:   req,
+		auth:   true,
+		keyed:  true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// revoke service account, its keys stop working
+func (c *Client) DeleteServiceAccount(ctx context.Context, orgID uint, id uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts/" + strconv.FormatUint(uint64(id), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+func (c *Client) ListServiceAccountKeys(ctx context.Context, orgID uint, id uint) ([]model.APIKey, error) {
+	res := new(model.APIKeysResponce)
+	err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts/" + strconv.FormatUint(uint64(id), 10) + "/apikeys",
+		auth:       true,
+		idempotent: true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// create api key of service account, raw key is returned only once
+func (c *Client) CreateServiceAccountKey(ctx context.Context, orgID uint, id uint, req model.APIKeyCreateReq) (*model.APIKeyCreateRes, error) {
+	res := new(model.APIKeyCreateRes)
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts/" + strconv.FormatUint(uint64(id), 10) + "/apikeys",
+		body:   req,
+		auth:   true,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) DeleteServiceAccountKey(ctx context.Context, orgID uint, id uint, keyID uint) error {
+	return c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       "/v1/orgs/" + strconv.FormatUint(uint64(orgID), 10) + "/serviceaccounts/" + strconv.FormatUint(uint64(id), 10) + "/apikeys/" + strconv.FormatUint(uint64(keyID), 10),
+		auth:       true,
+		idempotent: true,
+	}, new(model.PostResponce))
+}
+
+// api request description
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// content type of raw []byte body, other bodies are sent as json
+	contentType string
+	auth        bool
+	idempotent  bool
+	// request is sent with random Idempotency-Key, so server runs it once on retries
+	keyed bool
+	key   string
+}
+
+// send request with retries and token refresh, decode json responce into res
+func (c *Client) do(ctx context.Context, req request, res interface{}) error {
+
+	var body []byte
+	if raw, ok := req.body.([]byte); ok {
+		body = raw
+	} else if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return errors.Wrapf(err, "%s: encode request", clientErrorPrefix)
+		}
+	}
+
+	if req.keyed {
+		req.key = newIdempotencyKey()
+		req.idempotent = true
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		status, retryAfter, err := c.send(ctx, req, body, res)
+
+		// expired auth token is refreshed once
+		if req.auth && status == http.StatusUnauthorized && !refreshed && c.Tokens().RefreshToken != "" {
+			refreshed = true
+			if refreshErr := c.Refresh(ctx); refreshErr != nil {
+				return err
+			}
+			attempt--
+			continue
+		}
+
+		if err == nil || !req.idempotent || attempt >= c.maxRetries || !temporary(status) {
+			return err
+		}
+
+		// exponential backoff unless server asks to wait
+		wait := c.retryWait << attempt
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "%s: %s %s", clientErrorPrefix, req.method, req.path)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send request once, zero status means network error
+func (c *Client) send(ctx context.Context, req request, body []byte, res interface{}) (int, time.Duration, error) {
+
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "%s: build request", clientErrorPrefix)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	} else if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.key != "" {
+		httpReq.Header.Set("Idempotency-Key", req.key)
+	}
+	if req.auth && c.orgID != 0 {
+		httpReq.Header.Set("X-Org-ID", strconv.FormatUint(uint64(c.orgID), 10))
+	}
+	if req.auth && c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+	} else if req.auth {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Tokens().AuthToken)
+	}
+
+	httpRes, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "%s: %s %s", clientErrorPrefix, req.method, req.path)
+	}
+	defer httpRes.Body.Close()
+
+	resBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "%s: read responce", clientErrorPrefix)
+	}
+
+	if httpRes.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: httpRes.StatusCode, Message: http.StatusText(httpRes.StatusCode), Body: resBody}
+		errRes := model.ErrorResponce{}
+		if json.Unmarshal(resBody, &errRes) == nil && errRes.Message != "" {
+			apiErr.Message = errRes.Message
+		}
+		return httpRes.StatusCode, retryAfter(httpRes.Header), apiErr
+	}
+
+	// raw responce is requested with *[]byte
+	if raw, ok := res.(*[]byte); ok {
+		*raw = resBody
+	} else if res != nil {
+		err = json.Unmarshal(resBody, res)
+		if err != nil {
+			return httpRes.StatusCode, 0, errors.Wrapf(err, "%s: decode responce", clientErrorPrefix)
+		}
+	}
+
+	return httpRes.StatusCode, 0, nil
+}
+
+// network errors and overload statuses are worth to retry
+func temporary(status int) bool {
+	switch status {
+	case 0, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parse Retry-After header in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// random key of request which is the same on all retries
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,1676 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/jsonpatch"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/resttest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start api server backed by sqlite database
+func newTestServer(t *testing.T) *resttest.Server {
+	t.Helper()
+	return resttest.NewServer(t, config.Get())
+}
+
+// decimal of float which is in range
+func decimal(t *testing.T, f float64) domain.Decimal {
+	t.Helper()
+	d, err := domain.DecimalFromFloat(f)
+	require.NoError(t, err)
+	return d
+}
+
+// create operational spaceship of catalog class, it is created damaged
+// and manned by crew members of organization of user, returns its id
+func createManned(t *testing.T, server *resttest.Server, c *Client, orgEmail string, spaceship *model.SpaceshipFull) uint {
+	t.Helper()
+
+	ctx := context.Background()
+	spaceship.Status = "damaged"
+	require.NoError(t, c.CreateSpaceship(ctx, spaceship))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: spaceship.Name})
+	require.NoError(t, err)
+	for _, s := range spaceships {
+		if s.Name == spaceship.Name {
+			server.ManSpaceship(t, orgEmail, s.ID)
+			spaceship.Status = "operational"
+			return s.ID
+		}
+	}
+	t.Fatalf("spaceship %s is not created", spaceship.Name)
+	return 0
+}
+
+func TestClient_Spaceships(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	c := New(server.URL)
+
+	_, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	_, err = c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	devastator := &model.SpaceshipFull{
+		Name:     "Devastator",
+		Class:    "Star Destroyer",
+		Crew:     35000,
+		Status:   "operational",
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}},
+	}
+	// declared crew doesn't man operational spaceship, crew members assigned to it are counted
+	err = c.CreateSpaceship(ctx, devastator)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	createManned(t, server, c, "tarkin@empire.gov", devastator)
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Status: "damaged"}))
+
+	err = c.CreateSpaceship(ctx, &model.SpaceshipFull{Class: "Star Destroyer"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 2)
+
+	spaceships, err = c.ListSpaceships(ctx, SpaceshipFilter{Class: "star destroyer", Status: "damaged"})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	assert.Equal(t, "Avenger", spaceships[0].Name)
+
+	spaceships, err = c.ListSpaceships(ctx, SpaceshipFilter{Name: "devas"})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	id := spaceships[0].ID
+
+	spaceship, err := c.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Devastator", spaceship.Name)
+	assert.Equal(t, devastator.Armament, spaceship.Armament)
+
+	spaceship.Crew = 37000
+	require.NoError(t, c.UpdateSpaceship(ctx, id, spaceship))
+	spaceship, err = c.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, uint(37000), spaceship.Crew)
+
+	require.NoError(t, c.DeleteSpaceship(ctx, id))
+	_, err = c.GetSpaceship(ctx, id)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+}
+
+func TestClient_PatchSpaceship(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	// class out of catalog has no minimum crew, so crew is patched freely
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{
+		Name:     "Devastator",
+		Class:    "Victory Star Destroyer",
+		Crew:     35000,
+		Image:    "devastator.png",
+		Value:    decimal(t, 1999.99),
+		Status:   "operational",
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}},
+	}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	id := spaceships[0].ID
+
+	// zero values are set, omitted fields are kept
+	spaceship, err := c.MergePatchSpaceship(ctx, id, map[string]interface{}{"crew": 0, "image": nil, "status": "damaged"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), spaceship.Crew)
+	assert.Equal(t, "", spaceship.Image)
+	assert.Equal(t, "Devastator", spaceship.Name)
+	assert.Equal(t, decimal(t, 1999.99), spaceship.Value)
+	assert.Len(t, spaceship.Armament, 1)
+
+	_, err = c.MergePatchSpaceship(ctx, id, map[string]interface{}{"name": nil})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.MergePatchSpaceship(ctx, id, map[string]interface{}{"status": "lost"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.MergePatchSpaceship(ctx, id, map[string]interface{}{"speed": 1})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.MergePatchSpaceship(ctx, id, map[string]interface{}{"value": 1e300})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	// armament array is changed by operations
+	spaceship, err = c.JSONPatchSpaceship(ctx, id, jsonpatch.Patch{
+		{Op: "test", Path: "/armament/0/title", Value: "Turbo Laser"},
+		{Op: "replace", Path: "/armament/0/qty", Value: "50"},
+		{Op: "add", Path: "/armament/-", Value: map[string]interface{}{"title": "Ion Cannons", "qty": "20"}},
+		{Op: "replace", Path: "/crew", Value: 30000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 50}, {Title: "Ion Cannons", Qty: 20}}, spaceship.Armament)
+	assert.Equal(t, uint(30000), spaceship.Crew)
+
+	spaceship, err = c.JSONPatchSpaceship(ctx, id, jsonpatch.Patch{{Op: "remove", Path: "/armament/0"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpaceshipArmament{{Title: "Ion Cannons", Qty: 20}}, spaceship.Armament)
+
+	_, err = c.JSONPatchSpaceship(ctx, id, jsonpatch.Patch{{Op: "test", Path: "/crew", Value: 100}})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	_, err = c.JSONPatchSpaceship(ctx, id, jsonpatch.Patch{{Op: "remove", Path: "/armament/5"}})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	err = c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        fmt.Sprintf("/v1/spaceships/%d", id),
+		body:        []byte("crew=1"),
+		contentType: "application/x-www-form-urlencoded",
+		auth:        true,
+	}, new(model.SpaceshipFull))
+	assert.True(t, IsStatus(err, http.StatusUnsupportedMediaType))
+
+	// put replaces whole spaceship
+	require.NoError(t, c.UpdateSpaceship(ctx, id, &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Status: "damaged"}))
+	spaceship, err = c.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Avenger", spaceship.Name)
+	assert.Equal(t, domain.Decimal(0), spaceship.Value)
+	assert.Empty(t, spaceship.Armament)
+}
+
+func TestClient_Idempotency(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	create := func(key string, name string) error {
+		return c.do(ctx, request{
+			method: http.MethodPost,
+			path:   "/v1/spaceships",
+			body:   &model.SpaceshipFull{Name: name, Class: "Star Destroyer", Status: "damaged"},
+			auth:   true,
+			key:    key,
+		}, new(model.PostResponce))
+	}
+
+	// concurrent duplicates wait for the first request and get its response
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- create("create-devastator", "Devastator")
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		assert.NoError(t, <-errs)
+	}
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+
+	// replayed response is marked, retry must send the same body
+	body, err := json.Marshal(&model.SpaceshipFull{Name: "Devastator", Class: "Star Destroyer", Status: "damaged"})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/spaceships", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.Tokens().AuthToken)
+	req.Header.Set("Idempotency-Key", "create-devastator")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "true", res.Header.Get("Idempotent-Replayed"))
+
+	// key can't be used for another payload
+	err = create("create-devastator", "Avenger")
+	assert.True(t, IsStatus(err, http.StatusUnprocessableEntity))
+
+	// error responses are replayed too
+	err = create("create-duplicate", "Devastator")
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	err = create("create-duplicate", "Devastator")
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	// keys of another user are independent
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleOfficer)
+	vader := New(server.URL)
+	_, err = vader.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+	require.NoError(t, vader.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/v1/spaceships",
+		body:   &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Status: "damaged"},
+		auth:   true,
+		key:    "create-devastator",
+	}, new(model.PostResponce)))
+	spaceships, err = vader.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+
+	// created api key is not stored for replay, so its raw key is shown once
+	createKey := func() *model.APIKeyCreateRes {
+		res := new(model.APIKeyCreateRes)
+		require.NoError(t, c.do(ctx, request{
+			method: http.MethodPost,
+			path:   "/v1/apikeys",
+			body:   model.APIKeyCreateReq{Name: "ci", Scopes: []string{"spaceships:read"}},
+			auth:   true,
+			key:    "create-key",
+		}, res))
+		return res
+	}
+	first, second := createKey(), createKey()
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.NotEqual(t, first.Key, second.Key)
+}
+
+func TestClient_BatchSpaceships(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	ids := []uint{}
+	for _, name := range []string{"Devastator", "Avenger", "Executor"} {
+		require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: name, Class: "Victory Star Destroyer", Crew: 37000, Status: "operational"}))
+		spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: name})
+		require.NoError(t, err)
+		ids = append(ids, spaceships[0].ID)
+	}
+
+	// independent operations get own results
+	results, err := c.BatchSpaceships(ctx, &model.SpaceshipBatch{Operations: []model.SpaceshipOperation{
+		{Op: "status", ID: ids[0], Status: "damaged"},
+		{Op: "status", ID: ids[1], Status: "damaged"},
+		{Op: "status", ID: 1000, Status: "damaged"},
+		{Op: "status", ID: ids[2], Status: "lost"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.Equal(t, "Damaged", results[0].Data.Status)
+	assert.Equal(t, http.StatusOK, results[1].Status)
+	assert.Equal(t, http.StatusNotFound, results[2].Status)
+	assert.Equal(t, http.StatusBadRequest, results[3].Status)
+	assert.NotEmpty(t, results[3].Error.Message)
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Status: "damaged"})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 2)
+
+	// failed atomic batch is rolled back and responds with status of failed operation
+	results, err = c.BatchSpaceships(ctx, &model.SpaceshipBatch{Atomic: true, Operations: []model.SpaceshipOperation{
+		{Op: "create", Spaceship: &model.SpaceshipFull{Name: "Chimaera", Class: "Star Destroyer", Status: "damaged"}},
+		{Op: "delete", ID: ids[2]},
+		{Op: "create", Spaceship: &model.SpaceshipFull{Name: "Devastator", Class: "Star Destroyer", Status: "damaged"}},
+	}})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	require.Len(t, results, 3)
+	assert.Equal(t, http.StatusConflict, results[2].Status)
+	assert.Equal(t, domain.ErrSpaceshipExists.Error(), results[2].Error.Message)
+	assert.Equal(t, domain.ErrBatchAborted.Error(), results[0].Error.Message)
+	spaceships, err = c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 3)
+
+	results, err = c.BatchSpaceships(ctx, &model.SpaceshipBatch{Atomic: true, Operations: []model.SpaceshipOperation{
+		{Op: "create", Spaceship: &model.SpaceshipFull{Name: "Chimaera", Class: "Star Destroyer", Status: "damaged"}},
+		{Op: "delete", ID: ids[2]},
+		{Op: "update", ID: ids[1], Spaceship: &model.SpaceshipFull{Name: "Avenger II", Class: "Star Destroyer", Status: "damaged"}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NotZero(t, results[0].Data.ID)
+	assert.Nil(t, results[1].Data)
+	spaceships, err = c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 3)
+	spaceship, err := c.GetSpaceship(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, "Avenger II", spaceship.Name)
+	_, err = c.GetSpaceship(ctx, ids[2])
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// batch size is limited
+	_, err = c.BatchSpaceships(ctx, &model.SpaceshipBatch{Operations: make([]model.SpaceshipOperation, domain.SpaceshipBatchLimit+1)})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.BatchSpaceships(ctx, &model.SpaceshipBatch{})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+}
+
+func TestClient_Refresh(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	tokens, err := New(server.URL).Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	// refresh token can't be used for api access
+	c := New(server.URL, WithTokens(tokens.RefreshToken, ""))
+	_, err = c.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	// invalid auth token is refreshed and request is repeated
+	var refreshed model.UserAuthRes
+	c = New(server.URL, WithTokens("invalid", tokens.RefreshToken), WithTokenHandler(func(tokens model.UserAuthRes) {
+		refreshed = tokens
+	}))
+	_, err = c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AuthToken)
+	assert.Equal(t, refreshed, c.Tokens())
+}
+
+func TestClient_Retry(t *testing.T) {
+
+	ctx := context.Background()
+
+	var calls int32
+	keys := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			keys <- key
+		}
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":1,"name":"Devastator","status":"Operational"}]}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithRetry(3, time.Millisecond))
+
+	// idempotent request is retried
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// create is retried with the same idempotency key
+	atomic.StoreInt32(&calls, 0)
+	err = c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	require.Len(t, keys, 3)
+	key := <-keys
+	assert.NotEmpty(t, key)
+	assert.Equal(t, key, <-keys)
+	assert.Equal(t, key, <-keys)
+
+	// request without idempotency key is not retried
+	atomic.StoreInt32(&calls, 0)
+	err = c.do(ctx, request{method: http.MethodPost, path: "/v1/spaceships", body: &model.SpaceshipFull{Name: "Devastator"}, auth: true}, new(model.PostResponce))
+	assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// retries are limited
+	atomic.StoreInt32(&calls, -10)
+	_, err = c.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+	assert.Equal(t, int32(-6), atomic.LoadInt32(&calls))
+}
+
+func TestClient_APIKeys(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator", Class: "Star Destroyer", Crew: 35000, Status: "damaged"}))
+
+	created, err := c.CreateAPIKey(ctx, model.APIKeyCreateReq{Name: "probe", Scopes: []string{domain.ScopeSpaceshipsRead}})
+	require.NoError(t, err)
+	assert.Equal(t, created.Prefix, created.Key[:len(created.Prefix)])
+
+	keys, err := c.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "probe", keys[0].Name)
+
+	probe := New(server.URL, WithAPIKey(created.Key))
+	spaceships, err := probe.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+
+	// read only key can not write
+	err = probe.DeleteSpaceship(ctx, spaceships[0].ID)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+
+	// keys are managed with user tokens only
+	_, err = probe.ListAPIKeys(ctx)
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	require.NoError(t, c.DeleteAPIKey(ctx, created.ID))
+	_, err = probe.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	_, err = New(server.URL, WithAPIKey("ifk_unknown")).ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+}
+
+func TestClient_ServiceAccounts(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	login := func(email string, opts ...Option) *Client {
+		c := New(server.URL, opts...)
+		_, err := c.Login(ctx, email, "123123")
+		require.NoError(t, err)
+		return c
+	}
+
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "piett@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "mothma@rebellion.org", "123123", domain.UserRoleOfficer)
+	tarkin := login("tarkin@empire.gov")
+	rebels := login("mothma@rebellion.org")
+
+	orgs, err := tarkin.ListOrganizations(ctx)
+	require.NoError(t, err)
+	empireOrg := orgs[0]
+	orgs, err = rebels.ListOrganizations(ctx)
+	require.NoError(t, err)
+	rebelOrg := orgs[0]
+
+	// piett is the second admin, who creates account and key
+	_, err = tarkin.PutMember(ctx, empireOrg.ID, model.MemberReq{Email: "piett@empire.gov", Role: "admin"})
+	require.NoError(t, err)
+	piett := login("piett@empire.gov", WithOrg(empireOrg.ID))
+
+	// service account can't be admin
+	_, err = piett.CreateServiceAccount(ctx, empireOrg.ID, model.ServiceAccountCreateReq{Name: "ci", Role: "admin"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	ci, err := piett.CreateServiceAccount(ctx, empireOrg.ID, model.ServiceAccountCreateReq{Name: "ci", Role: "member"})
+	require.NoError(t, err)
+	assert.Equal(t, "member", ci.Role)
+	created, err := piett.CreateServiceAccountKey(ctx, empireOrg.ID, ci.ID, model.APIKeyCreateReq{
+		Name:   "deploy",
+		Scopes: []string{domain.ScopeSpaceshipsRead, domain.ScopeSpaceshipsWrite},
+	})
+	require.NoError(t, err)
+	keys, err := piett.ListServiceAccountKeys(ctx, empireOrg.ID, ci.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	// keys of service account are not listed as keys of admin
+	own, err := piett.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, own, 0)
+
+	// key acts as member of organization of account
+	probe := New(server.URL, WithAPIKey(created.Key))
+	require.NoError(t, probe.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator", Status: "damaged"}))
+	spaceships, err := tarkin.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+	me := struct {
+		Me struct {
+			Organizations []struct {
+				Name string
+				Role string
+			}
+		}
+	}{}
+	require.NoError(t, probe.GraphQL(ctx, &model.GraphQLRequest{Query: `{ me { organizations { name role } } }`}, &me))
+	require.Len(t, me.Me.Organizations, 1)
+	assert.Equal(t, empireOrg.Name, me.Me.Organizations[0].Name)
+	assert.Equal(t, "Member", me.Me.Organizations[0].Role)
+
+	// key does not depend on admin who created it
+	members, err := tarkin.ListMembers(ctx, empireOrg.ID)
+	require.NoError(t, err)
+	for _, m := range members {
+		if m.Email == "piett@empire.gov" {
+			require.NoError(t, tarkin.DeleteMember(ctx, empireOrg.ID, m.UserID))
+		}
+	}
+	spaceships, err = probe.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 1)
+	_, err = piett.ListServiceAccounts(ctx, empireOrg.ID)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// key can't select other organization
+	_, err = New(server.URL, WithAPIKey(created.Key), WithOrg(rebelOrg.ID)).ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+
+	// accounts are managed by admins of organization only
+	_, err = rebels.ListServiceAccounts(ctx, empireOrg.ID)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	_, err = rebels.CreateServiceAccountKey(ctx, empireOrg.ID, ci.ID, model.APIKeyCreateReq{Name: "steal", Scopes: []string{domain.ScopeSpaceshipsRead}})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	_, err = probe.ListServiceAccounts(ctx, empireOrg.ID)
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	// viewer account can't change spaceships
+	auditor, err := tarkin.CreateServiceAccount(ctx, empireOrg.ID, model.ServiceAccountCreateReq{Name: "audit", Role: "viewer"})
+	require.NoError(t, err)
+	auditKey, err := tarkin.CreateServiceAccountKey(ctx, empireOrg.ID, auditor.ID, model.APIKeyCreateReq{
+		Name:   "audit",
+		Scopes: []string{domain.ScopeSpaceshipsRead, domain.ScopeSpaceshipsWrite},
+	})
+	require.NoError(t, err)
+	audit := New(server.URL, WithAPIKey(auditKey.Key))
+	_, err = audit.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	err = audit.DeleteSpaceship(ctx, spaceships[0].ID)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+
+	accounts, err := tarkin.ListServiceAccounts(ctx, empireOrg.ID)
+	require.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	// key of revoked account is not accepted
+	require.NoError(t, tarkin.DeleteServiceAccount(ctx, empireOrg.ID, ci.ID))
+	_, err = probe.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+	_, err = tarkin.CreateServiceAccountKey(ctx, empireOrg.ID, ci.ID, model.APIKeyCreateReq{Name: "again", Scopes: []string{domain.ScopeSpaceshipsRead}})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	require.NoError(t, tarkin.DeleteServiceAccountKey(ctx, empireOrg.ID, auditor.ID, auditKey.ID))
+	_, err = audit.ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+}
+
+func TestClient_Tenants(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	login := func(email string, opts ...Option) *Client {
+		c := New(server.URL, opts...)
+		_, err := c.Login(ctx, email, "123123")
+		require.NoError(t, err)
+		return c
+	}
+
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "mothma@rebellion.org", "123123", domain.UserRoleOfficer)
+	empire := login("tarkin@empire.gov")
+	rebels := login("mothma@rebellion.org")
+
+	orgs, err := empire.ListOrganizations(ctx)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	empireOrg := orgs[0]
+	assert.Equal(t, "admin", empireOrg.Role)
+
+	// the same name is allowed in other organization
+	require.NoError(t, empire.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator", Status: "operational"}))
+	require.NoError(t, rebels.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator", Status: "damaged"}))
+	err = empire.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	spaceships, err := empire.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	empireShip := spaceships[0]
+
+	// spaceships of other organization are not found
+	_, err = rebels.GetSpaceship(ctx, empireShip.ID)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	err = rebels.UpdateSpaceship(ctx, empireShip.ID, &model.SpaceshipFull{Name: "Liberty"})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	err = rebels.DeleteSpaceship(ctx, empireShip.ID)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// organization can't be selected by non member
+	_, err = login("mothma@rebellion.org", WithOrg(empireOrg.ID)).ListSpaceships(ctx, SpaceshipFilter{})
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	_, err = rebels.ListMembers(ctx, empireOrg.ID)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	_, err = rebels.PutMember(ctx, empireOrg.ID, model.MemberReq{Email: "mothma@rebellion.org", Role: "admin"})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// viewer can read spaceships of organization but can't change them
+	_, err = empire.PutMember(ctx, empireOrg.ID, model.MemberReq{Email: "mothma@rebellion.org", Role: "viewer"})
+	require.NoError(t, err)
+	viewer := login("mothma@rebellion.org", WithOrg(empireOrg.ID))
+	spaceship, err := viewer.GetSpaceship(ctx, empireShip.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Operational", spaceship.Status)
+	err = viewer.DeleteSpaceship(ctx, empireShip.ID)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	_, err = viewer.PutMember(ctx, empireOrg.ID, model.MemberReq{Email: "mothma@rebellion.org", Role: "admin"})
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+
+	members, err := empire.ListMembers(ctx, empireOrg.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	// the last admin can't leave
+	err = empire.DeleteMember(ctx, empireOrg.ID, members[0].UserID)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	require.NoError(t, empire.DeleteMember(ctx, empireOrg.ID, members[1].UserID))
+	_, err = viewer.GetSpaceship(ctx, empireShip.ID)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+}
+
+func TestClient_SpaceshipImage(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Devastator", Status: "operational"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	id := spaceships[0].ID
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 320))))
+
+	err = c.UploadSpaceshipImage(ctx, id, "devastator.txt", []byte("not an image"))
+	assert.True(t, IsStatus(err, http.StatusUnsupportedMediaType))
+	require.NoError(t, c.UploadSpaceshipImage(ctx, id, "devastator.png", buf.Bytes()))
+
+	spaceship, err := c.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("/v1/spaceships/%d/image", id), spaceship.Image)
+
+	original, err := c.GetSpaceshipImage(ctx, id, "")
+	require.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), original)
+
+	data, err := c.GetSpaceshipImage(ctx, id, "thumb")
+	require.NoError(t, err)
+	thumb, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 160, thumb.Width)
+	assert.Equal(t, 80, thumb.Height)
+
+	_, err = c.GetSpaceshipImage(ctx, id, "huge")
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	// image is removed with spaceship
+	require.NoError(t, c.DeleteSpaceship(ctx, id))
+	_, err = c.GetSpaceshipImage(ctx, id, "")
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+}
+
+func TestClient_Search(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+	server.AddMember(t, "tarkin@empire.gov", "vader@empire.gov", domain.OrgRoleMember)
+
+	officer := New(server.URL)
+	_, err := officer.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	createManned(t, server, officer, "tarkin@empire.gov", &model.SpaceshipFull{
+		Name:     "Devastator",
+		Class:    "Star Destroyer",
+		Crew:     35000,
+		Armament: []model.SpaceshipArmament{{Title: "Ion Cannons", Qty: 60}},
+	})
+	require.NoError(t, officer.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Crew: 37000, Status: "damaged"}))
+
+	res, err := officer.Search(ctx, `class:"star destroyer" armament:"ion cannons" crew>5000`, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, res.Total)
+	assert.Equal(t, "Devastator", res.Data[0].Name)
+	assert.Equal(t, []string{"<em>Ion</em> <em>Cannons</em>"}, res.Data[0].Highlights["armament"])
+
+	_, err = officer.Search(ctx, "pilot:vader", 0)
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	// spaceship saved bypassing service, e.g. by cli, is found after reindex
+	orgs, err := officer.ListOrganizations(ctx)
+	require.NoError(t, err)
+	tenantCtx := tenant.WithID(ctx, orgs[0].ID)
+	require.NoError(t, spaceship.NewSpaceshipRepo(server.DB).Create(tenantCtx, &domain.Spaceship{Name: "Executor", Class: "Super Star Destroyer"}))
+	res, err = officer.Search(ctx, "destroyer", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Total)
+	assert.Len(t, res.Data, 1)
+
+	_, err = officer.Reindex(ctx)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+
+	admin := New(server.URL)
+	_, err = admin.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+	reindex, err := admin.Reindex(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reindex.Spaceships)
+
+	res, err = officer.Search(ctx, "destroyer", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, "Executor", res.Data[2].Name)
+
+	// spaceships of other organization are not found
+	adminOrg := server.AddMember(t, "vader@empire.gov", "vader@empire.gov", domain.OrgRoleAdmin)
+	own := New(server.URL, WithOrg(adminOrg.ID))
+	_, err = own.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+	res, err = own.Search(ctx, "destroyer", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Total)
+}
+
+func TestClient_Crew(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	devastator := &model.SpaceshipFull{Name: "Devastator", Class: "Star Destroyer", Crew: 2, Status: "damaged"}
+	require.NoError(t, c.CreateSpaceship(ctx, devastator))
+	// declared crew doesn't man spaceship, crew members assigned to it are counted
+	err = c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Status: "operational"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Status: "damaged"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	devastatorID, shuttleID := spaceships[0].ID, spaceships[1].ID
+
+	// under-crewed spaceship can't be operational
+	devastator.Status = "operational"
+	err = c.UpdateSpaceship(ctx, devastatorID, devastator)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	devastator.Status = "damaged"
+
+	piett, err := c.EnlistCrewMember(ctx, model.CrewEnlistReq{Name: "Firmus Piett", Rank: "Captain", Specialty: "Command", ServiceNumber: "IN-0001", SpaceshipID: devastatorID})
+	require.NoError(t, err)
+	assert.Equal(t, devastatorID, piett.SpaceshipID)
+	_, err = c.EnlistCrewMember(ctx, model.CrewEnlistReq{Name: "Kendal Ozzel", Rank: "Admiral", ServiceNumber: "IN-0001"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	_, err = c.EnlistCrewMember(ctx, model.CrewEnlistReq{Name: "Kendal Ozzel", Rank: "Grand Moff", ServiceNumber: "IN-0002"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	ozzel, err := c.EnlistCrewMember(ctx, model.CrewEnlistReq{Name: "Kendal Ozzel", Rank: "Admiral", ServiceNumber: "IN-0002", SpaceshipID: devastatorID})
+	require.NoError(t, err)
+
+	// roster can't exceed crew of spaceship
+	_, err = c.EnlistCrewMember(ctx, model.CrewEnlistReq{Name: "Lorth Needa", Rank: "Captain", ServiceNumber: "IN-0003", SpaceshipID: devastatorID})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	devastator.Crew = 1
+	err = c.UpdateSpaceship(ctx, devastatorID, devastator)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	_, err = c.TransferCrewMember(ctx, piett.ID, shuttleID)
+	require.NoError(t, err)
+	_, err = c.TransferCrewMember(ctx, piett.ID, shuttleID)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	crew, err := c.ListCrew(ctx, CrewFilter{SpaceshipID: devastatorID})
+	require.NoError(t, err)
+	require.Len(t, crew, 1)
+	assert.Equal(t, "Kendal Ozzel", crew[0].Name)
+
+	discharged, err := c.DischargeCrewMember(ctx, piett.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, discharged.DischargedAt)
+	assert.Zero(t, discharged.SpaceshipID)
+	_, err = c.TransferCrewMember(ctx, piett.ID, devastatorID)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	crew, err = c.ListCrew(ctx, CrewFilter{})
+	require.NoError(t, err)
+	assert.Len(t, crew, 1)
+	crew, err = c.ListCrew(ctx, CrewFilter{Discharged: true})
+	require.NoError(t, err)
+	assert.Len(t, crew, 2)
+
+	member, err := c.GetCrewMember(ctx, piett.ID)
+	require.NoError(t, err)
+	require.Len(t, member.Assignments, 2)
+	assert.Equal(t, devastatorID, member.Assignments[0].SpaceshipID)
+	assert.NotNil(t, member.Assignments[0].EndedAt)
+	assert.Equal(t, shuttleID, member.Assignments[1].SpaceshipID)
+	assert.NotNil(t, member.Assignments[1].EndedAt)
+
+	// crew of deleted spaceship stays in service
+	require.NoError(t, c.DeleteSpaceship(ctx, devastatorID))
+	member, err = c.GetCrewMember(ctx, ozzel.ID)
+	require.NoError(t, err)
+	assert.Zero(t, member.SpaceshipID)
+	assert.Nil(t, member.DischargedAt)
+	assert.NotNil(t, member.Assignments[0].EndedAt)
+}
+
+func TestClient_WorkOrders(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	// work order of spaceship created damaged is closed once it is manned
+	executor := &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9500,
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}}}
+	executorID := createManned(t, server, c, "tarkin@empire.gov", executor)
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Status: "damaged"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: "Tydirium"})
+	require.NoError(t, err)
+	shuttleID := spaceships[0].ID
+
+	// spaceship created damaged has default work order
+	orders, err := c.ListWorkOrders(ctx, WorkOrderFilter{})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, shuttleID, orders[0].SpaceshipID)
+	assert.Equal(t, "Medium", orders[0].Severity)
+	assert.Equal(t, "Triage", orders[0].Status)
+
+	// closing of the last work order doesn't make under-crewed spaceship operational
+	_, err = c.MoveWorkOrder(ctx, orders[0].ID, "done")
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	_, err = c.ReportDamage(ctx, model.WorkOrderReportReq{SpaceshipID: executorID, Severity: "fatal", Description: "Bridge"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.ReportDamage(ctx, model.WorkOrderReportReq{SpaceshipID: executorID, Severity: "high", Description: "Bridge",
+		Parts: []model.WorkOrderPart{{Title: "Ion Cannon", Qty: 1}}})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	bridge, err := c.ReportDamage(ctx, model.WorkOrderReportReq{SpaceshipID: executorID, Severity: "critical", Description: "Bridge is hit"})
+	require.NoError(t, err)
+	hull, err := c.ReportDamage(ctx, model.WorkOrderReportReq{SpaceshipID: executorID, Severity: "low", Description: "Hull breach"})
+	require.NoError(t, err)
+	ship, err := c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Damaged", ship.Status)
+	found, err := c.Search(ctx, "status:damaged executor", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, found.Total)
+
+	// damaged spaceship is repaired by work orders only
+	executor.Status = "operational"
+	err = c.UpdateSpaceship(ctx, executorID, executor)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	bridge, err = c.UpdateWorkOrder(ctx, bridge.ID, model.WorkOrderUpdateReq{Severity: "critical", Description: "Bridge is hit",
+		Yard: "Kuat Drive Yards", EstimatedCost: 1500000, Parts: []model.WorkOrderPart{{Title: "Turbo Laser", Qty: 4}}})
+	require.NoError(t, err)
+	assert.Equal(t, "Kuat Drive Yards", bridge.Yard)
+	assert.Equal(t, []model.WorkOrderPart{{Title: "Turbo Laser", Qty: 4}}, bridge.Parts)
+
+	_, err = c.MoveWorkOrder(ctx, bridge.ID, "launched")
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	bridge, err = c.MoveWorkOrder(ctx, bridge.ID, "in_progress")
+	require.NoError(t, err)
+	assert.Equal(t, "In Progress", bridge.Status)
+	assert.NotNil(t, bridge.StartedAt)
+	_, err = c.MoveWorkOrder(ctx, bridge.ID, "triage")
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	bridge, err = c.MoveWorkOrder(ctx, bridge.ID, "done")
+	require.NoError(t, err)
+	assert.NotNil(t, bridge.ClosedAt)
+	_, err = c.UpdateWorkOrder(ctx, bridge.ID, model.WorkOrderUpdateReq{Severity: "low", Description: "Bridge"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	// spaceship stays damaged while any work order is open
+	ship, err = c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Damaged", ship.Status)
+
+	orders, err = c.ListWorkOrders(ctx, WorkOrderFilter{SpaceshipID: executorID})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, hull.ID, orders[0].ID)
+	orders, err = c.ListWorkOrders(ctx, WorkOrderFilter{Status: "all"})
+	require.NoError(t, err)
+	assert.Len(t, orders, 4)
+	orders, err = c.ListWorkOrders(ctx, WorkOrderFilter{Status: "done"})
+	require.NoError(t, err)
+	assert.Len(t, orders, 2)
+
+	_, err = c.MoveWorkOrder(ctx, hull.ID, "done")
+	require.NoError(t, err)
+	ship, err = c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Operational", ship.Status)
+	found, err = c.Search(ctx, "status:operational executor", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, found.Total)
+
+	sla, err := c.WorkOrderSLA(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sla.Data)
+
+	// work orders are deleted with spaceship
+	require.NoError(t, c.DeleteSpaceship(ctx, shuttleID))
+	orders, err = c.ListWorkOrders(ctx, WorkOrderFilter{Status: "all"})
+	require.NoError(t, err)
+	assert.Len(t, orders, 3)
+}
+
+func TestClient_ShipClasses(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+
+	officer := New(server.URL)
+	_, err := officer.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+	admin := New(server.URL)
+	_, err = admin.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+
+	classes, err := officer.ListShipClasses(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, classes)
+	var starDestroyer model.ShipClass
+	for _, c := range classes {
+		if c.Name == "Star Destroyer" {
+			starDestroyer = c
+		}
+	}
+	require.NotZero(t, starDestroyer.ID)
+
+	// defaults of class are instantiated on create by class id
+	id := createManned(t, server, officer, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Devastator", ClassID: starDestroyer.ID})
+	devastator, err := officer.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Star Destroyer", devastator.Class)
+	assert.Equal(t, starDestroyer.MinCrew, devastator.Crew)
+	assert.Equal(t, decimal(t, starDestroyer.BaseValue), devastator.Value)
+	assert.Len(t, devastator.Armament, len(starDestroyer.Armament))
+
+	// class is normalized by alias and limits are checked
+	createManned(t, server, officer, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Avenger", Class: "isd", Crew: 37000})
+	err = officer.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Chimaera", Class: "ISD", Crew: 90000, Status: "damaged"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	err = officer.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Chimaera", Class: "ISD", Crew: 37000, Status: "damaged",
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 500}}})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	err = officer.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Chimaera", ClassID: 1000, Status: "damaged"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	spaceships, err := officer.ListSpaceships(ctx, SpaceshipFilter{Class: "Imperial Star Destroyer"})
+	require.NoError(t, err)
+	assert.Len(t, spaceships, 2)
+
+	// catalog is changed by admins only
+	freighter := model.ShipClass{Name: "YT-1300", Aliases: []string{"Light Freighter"}, MaxCrew: 8,
+		Armament: []model.ShipClassArmament{{Title: "Laser Cannon", Qty: 2, Max: 2}}}
+	_, err = officer.CreateShipClass(ctx, freighter)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	_, err = admin.CreateShipClass(ctx, model.ShipClass{Name: "ISD"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	// spaceship of class out of catalog is linked after alias is added
+	require.NoError(t, officer.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Falcon", Class: "Light Freighter", Crew: 4, Status: "operational"}))
+	created, err := admin.CreateShipClass(ctx, freighter)
+	require.NoError(t, err)
+	normalized, err := admin.NormalizeShipClasses(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), normalized.Spaceships)
+	spaceships, err = officer.ListSpaceships(ctx, SpaceshipFilter{Class: "YT-1300"})
+	require.NoError(t, err)
+	require.Len(t, spaceships, 1)
+	assert.Equal(t, "Falcon", spaceships[0].Name)
+
+	freighter.Name = "YT-1300 Light Freighter"
+	_, err = admin.UpdateShipClass(ctx, created.ID, freighter)
+	require.NoError(t, err)
+	class, err := officer.GetShipClass(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "YT-1300 Light Freighter", class.Name)
+	assert.Equal(t, []string{"Light Freighter"}, class.Aliases)
+	falcon, err := officer.GetSpaceship(ctx, spaceships[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "YT-1300 Light Freighter", falcon.Class)
+
+	err = admin.DeleteShipClass(ctx, created.ID)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+}
+
+func TestClient_Reports(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9500, Value: domain.NewDecimal(1500),
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}}})
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Crew: 9000, Value: domain.NewDecimal(1200),
+		Status: "damaged", Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 40}}}))
+	createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Value: domain.NewDecimal(10)})
+
+	fleet, err := c.FleetReport(ctx, ReportFilter{Group: []string{"class", "status"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.FleetReportRow{
+		{Class: "Lambda Shuttle", Status: "Operational", Currency: "GCR", Count: 1, Value: domain.NewDecimal(10), Crew: 6},
+		{Class: "Star Destroyer", Status: "Operational", Currency: "GCR", Count: 1, Value: domain.NewDecimal(1500), Crew: 9500},
+		{Class: "Star Destroyer", Status: "Damaged", Currency: "GCR", Count: 1, Value: domain.NewDecimal(1200), Crew: 9000},
+	}, fleet)
+
+	fleet, err = c.FleetReport(ctx, ReportFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.FleetReportRow{{Currency: "GCR", Count: 3, Value: domain.NewDecimal(2710), Crew: 18506}}, fleet)
+
+	_, err = c.FleetReport(ctx, ReportFilter{Group: []string{"armament"}})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	armament, err := c.ArmamentReport(ctx, ReportFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.ArmamentReportRow{{Title: "Turbo Laser", Qty: 100, Spaceships: 2}}, armament)
+
+	readiness, err := c.ReadinessReport(ctx, ReportFilter{Group: []string{"class"}})
+	require.NoError(t, err)
+	require.Len(t, readiness.Data, 2)
+	assert.Equal(t, float64(50), readiness.Data[1].Percent)
+	assert.InDelta(t, 66.67, readiness.Percent, 0.01)
+
+	// spaceships changed within window only, there is no total of no currency
+	fleet, err = c.FleetReport(ctx, ReportFilter{From: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []model.FleetReportRow{}, fleet)
+	_, err = c.FleetReport(ctx, ReportFilter{From: time.Now(), To: time.Now().Add(-time.Hour)})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	csv, err := c.ReportCSV(ctx, "fleet", ReportFilter{Group: []string{"status"}})
+	require.NoError(t, err)
+	assert.Equal(t, "status,currency,count,value,crew\nOperational,GCR,2,1510.00,9506\nDamaged,GCR,1,1200.00,9000\n", string(csv))
+
+	// snapshots of the day are refreshed
+	_, err = server.Reports.Snapshot(ctx, time.Now())
+	require.NoError(t, err)
+	_, err = server.Reports.Snapshot(ctx, time.Now())
+	require.NoError(t, err)
+	trends, err := c.TrendsReport(ctx, ReportFilter{})
+	require.NoError(t, err)
+	require.Len(t, trends, 1)
+	assert.Equal(t, int64(3), trends[0].Spaceships)
+	assert.Equal(t, int64(1), trends[0].Damaged)
+	assert.InDelta(t, 66.67, trends[0].Readiness, 0.01)
+}
+
+func TestClient_Readiness(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	// full loadout and crew of catalog class
+	executorID := createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9000,
+		Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}, {Title: "Ion Cannons", Qty: 60}, {Title: "Tractor Beam", Qty: 10}}})
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 3, Status: "damaged"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: "Tydirium"})
+	require.NoError(t, err)
+	shuttleID := spaceships[0].ID
+
+	executor, err := c.SpaceshipReadiness(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), executor.Score)
+	assert.Equal(t, "ready", executor.Level)
+	require.Len(t, executor.Factors, 4)
+	assert.Equal(t, "firepower", executor.Factors[2].Name)
+	assert.Equal(t, "firepower 970 of 970 of class loadout", executor.Factors[2].Explanation)
+
+	// damaged without armament and with default work order
+	shuttle, err := c.SpaceshipReadiness(ctx, shuttleID)
+	require.NoError(t, err)
+	assert.Equal(t, "not_ready", shuttle.Level)
+	assert.Equal(t, "1 open work orders, 0 overdue", shuttle.Factors[3].Explanation)
+
+	_, err = c.SpaceshipReadiness(ctx, 1000)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	fleet, err := c.FleetReadiness(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fleet.Spaceships)
+	assert.Equal(t, map[string]int{"ready": 1, "not_ready": 1}, fleet.Levels)
+	require.Len(t, fleet.Classes, 2)
+	assert.Equal(t, "Lambda Shuttle", fleet.Classes[0].Class)
+	require.NotNil(t, fleet.Weakest)
+	assert.Equal(t, shuttleID, fleet.Weakest.SpaceshipID)
+}
+
+func TestClient_Valuation(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	executorID := createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9000,
+		Value: domain.NewDecimal(1000)})
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 3,
+		Value: decimal(t, 100.1), Status: "damaged"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: "Tydirium"})
+	require.NoError(t, err)
+	shuttleID := spaceships[0].ID
+
+	// value of created spaceship is its initial valuation
+	executor, err := c.SpaceshipValuation(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, "GCR", executor.Currency)
+	assert.Equal(t, domain.NewDecimal(1000), executor.BookValue)
+	require.NotNil(t, executor.Depreciation)
+	assert.Equal(t, "straight_line", executor.Depreciation.Method)
+	require.Len(t, executor.History, 1)
+	assert.Equal(t, "Initial", executor.History[0].Source)
+
+	// past appraisal is recorded, but value is kept
+	twoYearsAgo := time.Now().AddDate(-2, 0, 0).UTC()
+	executor, err = c.AppraiseSpaceship(ctx, executorID, model.AppraisalReq{Amount: domain.NewDecimal(2000), AppraisedAt: &twoYearsAgo})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewDecimal(1000), executor.Appraised)
+	require.Len(t, executor.History, 2)
+	assert.Equal(t, "Appraisal", executor.History[0].Source)
+	assert.Equal(t, domain.NewDecimal(2000), executor.History[0].Amount)
+
+	executor, err = c.AppraiseSpaceship(ctx, executorID, model.AppraisalReq{Amount: decimal(t, 1200.5), Currency: "imp", Note: "survey"})
+	require.NoError(t, err)
+	assert.Equal(t, "IMP", executor.Currency)
+	assert.Equal(t, decimal(t, 1200.5), executor.BookValue)
+	require.Len(t, executor.History, 3)
+	assert.Equal(t, "survey", executor.History[2].Note)
+
+	full, err := c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, decimal(t, 1200.5), full.Value)
+	assert.Equal(t, "IMP", full.Currency)
+
+	_, err = c.AppraiseSpaceship(ctx, executorID, model.AppraisalReq{Amount: domain.NewDecimal(-1)})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	_, err = c.AppraiseSpaceship(ctx, executorID, model.AppraisalReq{Amount: domain.NewDecimal(1), AppraisedAt: &tomorrow})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.AppraiseSpaceship(ctx, 1000, model.AppraisalReq{Amount: domain.NewDecimal(1)})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// 35% of damaged shuttle value is lost
+	shuttle, err := c.SpaceshipValuation(ctx, shuttleID)
+	require.NoError(t, err)
+	assert.Equal(t, decimal(t, 35.035), shuttle.DamageAdjustment)
+	assert.Equal(t, decimal(t, 65.065), shuttle.BookValue)
+
+	fleet, err := c.FleetValuation(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.ValuationTotal{
+		{Currency: "GCR", Spaceships: 1, Appraised: decimal(t, 100.1), DamageAdjustment: decimal(t, 35.035), BookValue: decimal(t, 65.065)},
+		{Currency: "IMP", Spaceships: 1, Appraised: decimal(t, 1200.5), BookValue: decimal(t, 1200.5)},
+	}, fleet.Totals)
+}
+
+func TestClient_Positions(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	for _, name := range []string{"Executor", "Avenger", "Tydirium"} {
+		require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: name, Class: "Victory Star Destroyer", Crew: 9000, Status: "operational"}))
+	}
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{})
+	require.NoError(t, err)
+	executorID, avengerID, tydiriumID := spaceships[0].ID, spaceships[1].ID, spaceships[2].ID
+
+	// spaceship without position has none in detail view
+	executor, err := c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Nil(t, executor.Position)
+
+	position, err := c.ReportPosition(ctx, executorID, model.PositionReportReq{Sector: "Outer Rim", System: "Hoth", Coordinates: &model.Coordinates{}})
+	require.NoError(t, err)
+	assert.Equal(t, "Hoth", position.System)
+	_, err = c.ReportPosition(ctx, avengerID, model.PositionReportReq{Sector: "Outer Rim", System: "Bespin", Coordinates: &model.Coordinates{X: 30, Y: 40}})
+	require.NoError(t, err)
+	_, err = c.ReportPosition(ctx, tydiriumID, model.PositionReportReq{Sector: "Core Worlds", System: "Coruscant"})
+	require.NoError(t, err)
+
+	outerRim, err := c.ListSpaceships(ctx, SpaceshipFilter{Sector: "outer rim"})
+	require.NoError(t, err)
+	assert.Len(t, outerRim, 2)
+	coruscant, err := c.ListSpaceships(ctx, SpaceshipFilter{System: "Coruscant"})
+	require.NoError(t, err)
+	require.Len(t, coruscant, 1)
+	assert.Equal(t, tydiriumID, coruscant[0].ID)
+
+	executor, err = c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	require.NotNil(t, executor.Position)
+	assert.Equal(t, "Outer Rim", executor.Position.Sector)
+	assert.Equal(t, &model.Coordinates{}, executor.Position.Coordinates)
+
+	// spaceships without coordinates are not found by proximity
+	near, err := c.NearSpaceships(ctx, model.Coordinates{}, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, near.Total)
+	require.Len(t, near.Data, 2)
+	assert.Equal(t, executorID, near.Data[0].ID)
+	assert.Equal(t, float64(50), near.Data[1].Distance)
+	assert.Equal(t, "Bespin", near.Data[1].Position.System)
+
+	// past position is logged, but the latest one is kept
+	hourAgo := time.Now().Add(-time.Hour).UTC()
+	_, err = c.ReportPosition(ctx, executorID, model.PositionReportReq{Sector: "Outer Rim", System: "Endor",
+		Coordinates: &model.Coordinates{X: 5000}, ReportedAt: &hourAgo})
+	require.NoError(t, err)
+	positions, err := c.SpaceshipPositions(ctx, executorID, 0)
+	require.NoError(t, err)
+	require.Len(t, positions, 2)
+	assert.Equal(t, "Hoth", positions[0].System)
+	assert.Equal(t, "Endor", positions[1].System)
+	positions, err = c.SpaceshipPositions(ctx, executorID, 1)
+	require.NoError(t, err)
+	assert.Len(t, positions, 1)
+
+	// moved spaceship is found at new coordinates, update keeps position
+	_, err = c.ReportPosition(ctx, executorID, model.PositionReportReq{Sector: "Unknown Regions", Coordinates: &model.Coordinates{X: 5000}})
+	require.NoError(t, err)
+	executor.Crew = 9500
+	require.NoError(t, c.UpdateSpaceship(ctx, executorID, executor))
+	near, err = c.NearSpaceships(ctx, model.Coordinates{X: 5000}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, near.Data, 1)
+	assert.Equal(t, executorID, near.Data[0].ID)
+	executor, err = c.GetSpaceship(ctx, executorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Unknown Regions", executor.Position.Sector)
+
+	_, err = c.ReportPosition(ctx, executorID, model.PositionReportReq{System: "Hoth"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.ReportPosition(ctx, 1000, model.PositionReportReq{Sector: "Outer Rim"})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	_, err = c.NearSpaceships(ctx, model.Coordinates{}, 0, 0)
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	// deleted spaceship is not found by proximity
+	require.NoError(t, c.DeleteSpaceship(ctx, avengerID))
+	near, err = c.NearSpaceships(ctx, model.Coordinates{}, 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, near.Total)
+}
+
+func TestClient_Routes(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+
+	officer := New(server.URL)
+	_, err := officer.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+	admin := New(server.URL)
+	_, err = admin.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+
+	ids := []uint{}
+	for _, s := range []model.SpaceshipFull{
+		{Name: "Executor", Class: "Star Destroyer", Crew: 9000},
+		{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6},
+		{Name: "Avenger", Class: "Star Destroyer", Crew: 9000},
+	} {
+		ids = append(ids, createManned(t, server, officer, "tarkin@empire.gov", &s))
+	}
+	executorID, tydiriumID, avengerID := ids[0], ids[1], ids[2]
+	for _, id := range []uint{executorID, tydiriumID} {
+		_, err = officer.ReportPosition(ctx, id, model.PositionReportReq{Sector: "Core Worlds", System: "coruscant"})
+		require.NoError(t, err)
+	}
+
+	systems := func(route *model.Route) []string {
+		res := []string{route.From}
+		for _, j := range route.Jumps {
+			res = append(res, j.To)
+		}
+		return res
+	}
+
+	// shortest and fastest routes differ by speed of lanes
+	route, err := officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "hoth", Mode: "shortest"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Coruscant", "Corellia", "Kessel", "Bespin", "Hoth"}, systems(route))
+	assert.Equal(t, 6929.35, route.Distance)
+	assert.Equal(t, "shortest", route.Mode)
+	route, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Hoth"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Coruscant", "Corellia", "Naboo", "Dagobah", "Hoth"}, systems(route))
+	assert.Equal(t, 23.02, route.Hours)
+	assert.Equal(t, "fastest", route.Mode)
+	assert.Equal(t, float64(2), route.Hyperdrive)
+
+	// shuttle has faster hyperdrive than star destroyer
+	route, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Tatooine"})
+	require.NoError(t, err)
+	assert.Equal(t, 14.64, route.Hours)
+	route, err = officer.PlanRoute(ctx, tydiriumID, model.RouteReq{Destination: "Tatooine"})
+	require.NoError(t, err)
+	assert.Equal(t, 8.82, route.Hours)
+	assert.Equal(t, float64(1), route.Hyperdrive)
+
+	_, err = officer.PlanRoute(ctx, avengerID, model.RouteReq{Destination: "Tatooine"})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	_, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Alderaan", Mode: "scenic"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Jakku"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = officer.PlanRoute(ctx, 1000, model.RouteReq{Destination: "Tatooine"})
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+
+	// closed lane is bypassed until it is opened
+	_, err = officer.CloseHyperlane(ctx, model.HyperlaneClosureReq{From: "Corellia", To: "Naboo"})
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	_, err = admin.CloseHyperlane(ctx, model.HyperlaneClosureReq{From: "Coruscant", To: "Tatooine"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	closure, err := admin.CloseHyperlane(ctx, model.HyperlaneClosureReq{From: "naboo", To: "corellia", Reason: "blockade"})
+	require.NoError(t, err)
+	// closure names lane as it is in galaxy
+	assert.Equal(t, "Corellia", closure.From)
+	assert.Nil(t, closure.Until)
+
+	route, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Tatooine"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Coruscant", "Corellia", "Kessel", "Bespin", "Hoth", "Dagobah", "Tatooine"}, systems(route))
+	galaxy, err := officer.Galaxy(ctx)
+	require.NoError(t, err)
+	require.Len(t, galaxy.Closures, 1)
+	assert.Equal(t, "blockade", galaxy.Closures[0].Reason)
+	closed := []string{}
+	for _, l := range galaxy.Lanes {
+		if l.Closed {
+			closed = append(closed, l.From+" - "+l.To)
+		}
+	}
+	assert.Equal(t, []string{"Corellia - Naboo"}, closed)
+
+	require.NoError(t, admin.OpenHyperlane(ctx, closure.ID))
+	route, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Tatooine"})
+	require.NoError(t, err)
+	assert.Len(t, route.Jumps, 3)
+	assert.True(t, IsStatus(admin.OpenHyperlane(ctx, closure.ID), http.StatusNotFound))
+
+	// uploaded galaxy replaces galaxy of data file
+	data := []byte(`
+jump_hours: 0
+speed: 100
+systems:
+  - {name: Coruscant, sector: Core Worlds}
+  - {name: Jakku, sector: Western Reaches, x: 300, y: 400}
+lanes:
+  - {from: Coruscant, to: Jakku}
+`)
+	_, err = officer.UploadGalaxy(ctx, data, "application/yaml")
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	_, err = admin.UploadGalaxy(ctx, []byte(`{"systems": [{"name": "Jakku"}], "lanes": [{"from": "Jakku", "to": "Hoth"}]}`), "application/json")
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	galaxy, err = admin.UploadGalaxy(ctx, data, "application/yaml")
+	require.NoError(t, err)
+	assert.Len(t, galaxy.Systems, 2)
+	assert.NotNil(t, galaxy.UploadedAt)
+
+	route, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Jakku"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(500), route.Distance)
+	assert.Equal(t, float64(10), route.Hours)
+	_, err = officer.PlanRoute(ctx, executorID, model.RouteReq{Destination: "Tatooine"})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+}
+
+func TestClient_Cache(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+
+	officer := New(server.URL)
+	_, err := officer.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+	admin := New(server.URL)
+	_, err = admin.Login(ctx, "vader@empire.gov", "123123")
+	require.NoError(t, err)
+
+	executor := &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9500}
+	id := createManned(t, server, officer, "tarkin@empire.gov", executor)
+
+	for i := 0; i < 2; i++ {
+		_, err = officer.GetSpaceship(ctx, id)
+		require.NoError(t, err)
+	}
+
+	// updated spaceship is never served from cache
+	executor.Name = "Devastator"
+	require.NoError(t, officer.UpdateSpaceship(ctx, id, executor))
+	spaceship, err := officer.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Devastator", spaceship.Name)
+
+	_, err = officer.CacheStats(ctx)
+	assert.True(t, IsStatus(err, http.StatusForbidden))
+	stats, err := admin.CacheStats(ctx)
+	require.NoError(t, err)
+	assert.True(t, stats.Enabled)
+	assert.Positive(t, stats.Hits)
+	assert.Positive(t, stats.Misses)
+
+	require.NoError(t, admin.PurgeCache(ctx))
+	spaceship, err = officer.GetSpaceship(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Devastator", spaceship.Name)
+}
+
+func TestClient_GraphQL(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+
+	err := c.GraphQL(ctx, &model.GraphQLRequest{Query: `{ me { email } }`}, nil)
+	assert.True(t, IsStatus(err, http.StatusUnauthorized))
+
+	_, err = c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	for _, name := range []string{"Devastator", "Avenger"} {
+		require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{
+			Name: name, Class: "Victory Star Destroyer", Crew: 37000, Status: "operational",
+			Armament: []model.SpaceshipArmament{{Title: "Turbo Laser", Qty: 60}},
+		}))
+	}
+
+	// spaceships of organization with armament and current user
+	page := struct {
+		Me struct {
+			Email string
+		}
+		Spaceships struct {
+			TotalCount int
+			Edges      []struct {
+				Node struct {
+					Name     string
+					Armament []struct {
+						Title string
+						Qty   int
+					}
+				}
+			}
+		}
+	}{}
+	err = c.GraphQL(ctx, &model.GraphQLRequest{
+		Query:     `query Page($first: Int) { me { email } spaceships(first: $first) { totalCount edges { node { name armament { title qty } } } } }`,
+		Variables: map[string]interface{}{"first": 1},
+	}, &page)
+	require.NoError(t, err)
+	assert.Equal(t, "tarkin@empire.gov", page.Me.Email)
+	assert.Equal(t, 2, page.Spaceships.TotalCount)
+	require.Len(t, page.Spaceships.Edges, 1)
+	assert.Equal(t, "Devastator", page.Spaceships.Edges[0].Node.Name)
+	assert.Equal(t, 60, page.Spaceships.Edges[0].Node.Armament[0].Qty)
+
+	// mutations delegate to spaceship service, its errors have http status of rest api
+	created := struct {
+		CreateSpaceship struct {
+			ID   string
+			Crew int
+		}
+	}{}
+	err = c.GraphQL(ctx, &model.GraphQLRequest{
+		Query: `mutation { createSpaceship(input: {name: "Executor", class: "Star Dreadnought", crew: 280000, status: DAMAGED}) { id crew } }`,
+	}, &created)
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.CreateSpaceship.ID)
+	assert.Equal(t, 280000, created.CreateSpaceship.Crew)
+
+	err = c.GraphQL(ctx, &model.GraphQLRequest{
+		Query: `mutation { createSpaceship(input: {name: "Executor"}) { id } }`,
+	}, nil)
+	var gqlErrs model.GraphQLErrors
+	require.ErrorAs(t, err, &gqlErrs)
+	assert.Equal(t, domain.ErrSpaceshipExists.Error(), gqlErrs[0].Message)
+	assert.Equal(t, float64(http.StatusConflict), gqlErrs[0].Extensions["status"])
+
+	// too deep queries are rejected before they run
+	err = c.GraphQL(ctx, &model.GraphQLRequest{
+		Query: `{ fleet { spaceships { edges { node { fleet { spaceships { edges { node { fleet { spaceships { totalCount } } } } } } } } } } }`,
+	}, nil)
+	require.ErrorAs(t, err, &gqlErrs)
+	assert.Equal(t, float64(http.StatusBadRequest), gqlErrs[0].Extensions["status"])
+
+	// api keys without write scope can read only
+	key, err := c.CreateAPIKey(ctx, model.APIKeyCreateReq{Name: "reader", Scopes: []string{domain.ScopeSpaceshipsRead}})
+	require.NoError(t, err)
+	reader := New(server.URL, WithAPIKey(key.Key))
+	err = reader.GraphQL(ctx, &model.GraphQLRequest{Query: `{ spaceships { totalCount } }`}, &page)
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Spaceships.TotalCount)
+	err = reader.GraphQL(ctx, &model.GraphQLRequest{Query: `mutation { deleteSpaceship(id: "1") }`}, nil)
+	require.ErrorAs(t, err, &gqlErrs)
+	assert.Equal(t, float64(http.StatusForbidden), gqlErrs[0].Extensions["status"])
+}
+
+func TestClient_Missions(t *testing.T) {
+
+	ctx := context.Background()
+	server := newTestServer(t)
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+	c := New(server.URL)
+	_, err := c.Login(ctx, "tarkin@empire.gov", "123123")
+	require.NoError(t, err)
+
+	executorID := createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Executor", Class: "Star Destroyer", Crew: 9500})
+	avengerID := createManned(t, server, c, "tarkin@empire.gov", &model.SpaceshipFull{Name: "Avenger", Class: "Star Destroyer", Crew: 9000})
+	require.NoError(t, c.CreateSpaceship(ctx, &model.SpaceshipFull{Name: "Tydirium", Class: "Lambda Shuttle", Crew: 6, Status: "damaged"}))
+	spaceships, err := c.ListSpaceships(ctx, SpaceshipFilter{Name: "Tydirium"})
+	require.NoError(t, err)
+	shuttleID := spaceships[0].ID
+
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	hoth := model.MissionReq{Objective: "Hoth", StartsAt: start, EndsAt: start.Add(2 * time.Hour),
+		Spaceships: []uint{executorID, avengerID}, Requirements: model.MissionRequirements{MinCrew: 1000}}
+	mission, err := c.PlanMission(ctx, hoth)
+	require.NoError(t, err)
+	assert.Equal(t, "Planned", mission.Status)
+	assert.Equal(t, start, mission.StartsAt)
+	assert.Equal(t, []uint{executorID, avengerID}, mission.Spaceships)
+
+	// spaceship can't be double booked by overlapping window, back to back window is free
+	_, err = c.PlanMission(ctx, model.MissionReq{Objective: "Endor", StartsAt: start.Add(time.Hour), EndsAt: start.Add(3 * time.Hour),
+		Spaceships: []uint{avengerID}})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	endor, err := c.PlanMission(ctx, model.MissionReq{Objective: "Endor", StartsAt: start.Add(2 * time.Hour), EndsAt: start.Add(3 * time.Hour),
+		Spaceships: []uint{avengerID}})
+	require.NoError(t, err)
+
+	// damaged spaceship and spaceship without required crew can't be assigned
+	_, err = c.PlanMission(ctx, model.MissionReq{Objective: "Yavin", StartsAt: start, EndsAt: start.Add(time.Hour), Spaceships: []uint{shuttleID}})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	_, err = c.PlanMission(ctx, model.MissionReq{Objective: "Yavin", StartsAt: start, EndsAt: start.Add(time.Hour), Spaceships: []uint{avengerID},
+		Requirements: model.MissionRequirements{MinCrew: 10000}})
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	_, err = c.PlanMission(ctx, model.MissionReq{Objective: "Yavin", StartsAt: start, Spaceships: []uint{avengerID}})
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+
+	hoth.Spaceships = []uint{executorID}
+	mission, err = c.UpdateMission(ctx, mission.ID, hoth)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{executorID}, mission.Spaceships)
+
+	missions, err := c.ListMissions(ctx, MissionFilter{SpaceshipID: avengerID})
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, endor.ID, missions[0].ID)
+	missions, err = c.ListMissions(ctx, MissionFilter{From: start, To: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, missions, 1)
+	assert.Equal(t, mission.ID, missions[0].ID)
+
+	// spaceship of active mission can't be deleted
+	_, err = c.MoveMission(ctx, mission.ID, "launched")
+	assert.True(t, IsStatus(err, http.StatusBadRequest))
+	_, err = c.MoveMission(ctx, mission.ID, "completed")
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	mission, err = c.MoveMission(ctx, mission.ID, "active")
+	require.NoError(t, err)
+	assert.Equal(t, "Active", mission.Status)
+	assert.NotNil(t, mission.StartedAt)
+	_, err = c.UpdateMission(ctx, mission.ID, hoth)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+	err = c.DeleteSpaceship(ctx, executorID)
+	assert.True(t, IsStatus(err, http.StatusConflict))
+
+	mission, err = c.MoveMission(ctx, mission.ID, "aborted")
+	require.NoError(t, err)
+	assert.NotNil(t, mission.ClosedAt)
+	require.NoError(t, c.DeleteSpaceship(ctx, executorID))
+
+	mission, err = c.GetMission(ctx, mission.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Aborted", mission.Status)
+	missions, err = c.ListMissions(ctx, MissionFilter{Status: "all"})
+	require.NoError(t, err)
+	assert.Len(t, missions, 2)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ AccountService = (*service.AccountService)(nil)
+)
+
+//go:generate mockery --dir . --name AccountService --output ./mocks
+type AccountService interface {
+	ForgotPassword(context.Context, string) error
+	ResetPassword(context.Context, *domain.PasswordResetReq) error
+	SendVerification(context.Context, string) error
+	VerifyEmail(context.Context, string) error
+	CheckVerified(context.Context, uint) error
+	CheckRole(context.Context, uint, domain.UserRole) error
+}
+
+type AccountHandler struct {
+	service AccountService
+}
+
+func NewAccountHandler(service AccountService) *AccountHandler {
+	return &AccountHandler{service}
+}
+
+// middleware which allows users with verified email only
+// must be used after jwt or auth middleware
+func RequireVerified(account AccountService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			// api keys are created by verified users only
+			if _, ok := contextAPIKey(ctx); ok {
+				return next(ctx)
+			}
+
+			userID, ok := contextSubject(ctx)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			err := account.CheckVerified(ctx.Request().Context(), userID)
+			if errors.Is(err, domain.ErrNotFound) {
+				return echo.ErrUnauthorized
+			}
+			if errors.Is(err, domain.ErrEmailNotVerified) {
+				return echo.NewHTTPError(http.StatusForbidden, domain.ErrEmailNotVerified.Error())
+			}
+			if err != nil {
+				return err
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// middleware which allows users with global role or higher only, api keys act
+// within organizations and are not allowed, must be used after jwt or auth middleware
+func RequireRole(account AccountService, role domain.UserRole) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if _, ok := contextAPIKey(ctx); ok {
+				return domain.ErrForbidden
+			}
+
+			userID, ok := contextSubject(ctx)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			err := account.CheckRole(ctx.Request().Context(), userID, role)
+			if errors.Is(err, domain.ErrNotFound) {
+				return echo.ErrUnauthorized
+			}
+			if err != nil {
+				return err
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// request password reset email, responce does not depend on user existence
+func (h *AccountHandler) ForgotPassword(ctx echo.Context) error {
+
+	req := new(model.PasswordForgotReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.ForgotPassword(ctx.Request().Context(), req.Email)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+func (h *AccountHandler) ResetPassword(ctx echo.Context) error {
+
+	req := new(model.PasswordResetReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.ResetPassword(ctx.Request().Context(), &domain.PasswordResetReq{
+		Token:      req.Token,
+		Password:   req.Password,
+		RePassword: req.RePassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+func (h *AccountHandler) VerifyEmail(ctx echo.Context) error {
+
+	req := new(model.EmailVerifyReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.VerifyEmail(ctx.Request().Context(), req.Token)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+// resend verification email, responce does not depend on user existence
+func (h *AccountHandler) ResendVerification(ctx echo.Context) error {
+
+	req := new(model.EmailVerifyResendReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.SendVerification(ctx.Request().Context(), req.Email)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ APIKeyService = (*service.APIKeyService)(nil)
+)
+
+const (
+	// header with api key
+	APIKeyHeader = "X-API-Key"
+	// echo context key of authenticated api key
+	apiKeyContextKey = "apikey"
+)
+
+//go:generate mockery --dir . --name APIKeyService --output ./mocks
+type APIKeyService interface {
+	Create(context.Context, uint, *domain.APIKeyCreateReq) (*domain.APIKey, string, error)
+	List(context.Context, uint) ([]*domain.APIKey, error)
+	Revoke(context.Context, uint, uint) error
+	Authenticate(context.Context, string) (*domain.APIKey, error)
+}
+
+type APIKeyHandler struct {
+	service APIKeyService
+}
+
+func NewAPIKeyHandler(service APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service}
+}
+
+// auth middleware which accepts api key header or jwt auth token
+func AuthMiddleware(tokens *Tokens, apiKeys APIKeyService) echo.MiddlewareFunc {
+	jwtMiddleware := JWTMiddleware(tokens)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := jwtMiddleware(next)
+		return func(ctx echo.Context) error {
+			rawKey := ctx.Request().Header.Get(APIKeyHeader)
+			if rawKey == "" {
+				return withJWT(ctx)
+			}
+
+			key, err := apiKeys.Authenticate(ctx.Request().Context(), rawKey)
+			if err != nil {
+				return err
+			}
+			ctx.Set(apiKeyContextKey, key)
+
+			return next(ctx)
+		}
+	}
+}
+
+// middleware which allows api keys with scope, users authenticated by jwt have all scopes
+// must be used after auth middleware
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if key, ok := contextAPIKey(ctx); ok && !key.HasScope(scope) {
+				return domain.ErrScopeMissing
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// api key verified by auth middleware
+func contextAPIKey(ctx echo.Context) (*domain.APIKey, bool) {
+	key, ok := ctx.Get(apiKeyContextKey).(*domain.APIKey)
+	return key, ok
+}
+
+func (h *APIKeyHandler) List(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	keys, err := h.service.List(ctx.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	restKeys := make([]model.APIKey, 0, len(keys))
+	for _, key := range keys {
+		restKeys = append(restKeys, model.APIKeyFromDomain(key))
+	}
+
+	return ctx.JSON(http.StatusOK, model.APIKeysResponce{Data: restKeys})
+}
+
+func (h *APIKeyHandler) Create(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	req := new(model.APIKeyCreateReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	key, rawKey, err := h.service.Create(ctx.Request().Context(), userID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.APIKeyCreateRes{
+		APIKey: model.APIKeyFromDomain(key),
+		Key:    rawKey,
+	})
+}
+
+func (h *APIKeyHandler) Delete(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	if idInt < 0 {
+		return domain.ErrNotFound
+	}
+
+	err = h.service.Revoke(ctx.Request().Context(), userID, uint(idInt))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ CacheService = (*service.CacheService)(nil)
+)
+
+//go:generate mockery --dir . --name CacheService --output ./mocks
+type CacheService interface {
+	Stats(context.Context) (domain.CacheStats, error)
+	Purge(context.Context) error
+}
+
+type CacheHandler struct {
+	service CacheService
+}
+
+func NewCacheHandler(service CacheService) *CacheHandler {
+	return &CacheHandler{service}
+}
+
+// hit and miss metrics of spaceship cache
+func (h *CacheHandler) Stats(ctx echo.Context) error {
+
+	stats, err := h.service.Stats(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.CacheStatsFromDomain(stats))
+}
+
+func (h *CacheHandler) Purge(ctx echo.Context) error {
+
+	err := h.service.Purge(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ CrewService = (*service.CrewService)(nil)
+)
+
+//go:generate mockery --dir . --name CrewService --output ./mocks
+type CrewService interface {
+	GetAll(context.Context, domain.CrewFilter) ([]*domain.CrewMember, error)
+	GetById(context.Context, uint) (*domain.CrewMember, []*domain.CrewAssignment, error)
+	Enlist(context.Context, *domain.CrewMember) error
+	Transfer(context.Context, uint, uint) (*domain.CrewMember, error)
+	Discharge(context.Context, uint) (*domain.CrewMember, error)
+}
+
+type CrewHandler struct {
+	service CrewService
+}
+
+func NewCrewHandler(service CrewService) *CrewHandler {
+	return &CrewHandler{service}
+}
+
+// crew members in service, filtered by spaceship
+func (h *CrewHandler) GetAll(ctx echo.Context) error {
+
+	filter := domain.CrewFilter{}
+	if spaceship := ctx.QueryParam("spaceship"); spaceship != "" {
+		id, err := strconv.ParseUint(spaceship, 10, 32)
+		if err != nil {
+			return err
+		}
+		filter.SpaceshipID = uint(id)
+	}
+	if discharged := ctx.QueryParam("discharged"); discharged != "" {
+		var err error
+		filter.Discharged, err = strconv.ParseBool(discharged)
+		if err != nil {
+			return err
+		}
+	}
+
+	members, err := h.service.GetAll(ctx.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	res := model.CrewResponce{Data: make([]model.CrewMember, 0, len(members))}
+	for _, m := range members {
+		res.Data = append(res.Data, model.CrewMemberFromDomain(m))
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// crew member with assignments
+func (h *CrewHandler) GetById(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	member, assignments, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	res := model.CrewMemberFromDomain(member)
+	for _, a := range assignments {
+		res.Assignments = append(res.Assignments, model.CrewAssignmentFromDomain(a))
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func (h *CrewHandler) Enlist(ctx echo.Context) error {
+
+	req := new(model.CrewEnlistReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	member := req.ToDomain()
+	err = h.service.Enlist(ctx.Request().Context(), member)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.CrewMemberFromDomain(member))
+}
+
+func (h *CrewHandler) Transfer(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.CrewTransferReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	member, err := h.service.Transfer(ctx.Request().Context(), id, req.SpaceshipID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.CrewMemberFromDomain(member))
+}
+
+func (h *CrewHandler) Discharge(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	member, err := h.service.Discharge(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.CrewMemberFromDomain(member))
+}
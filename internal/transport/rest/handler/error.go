@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// http status of error returned by handlers
+func ErrorStatus(err error) int {
+	var httpErr *echo.HTTPError
+	var numErr *strconv.NumError
+
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.Code
+	case errors.As(err, &numErr):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUserExists),
+		errors.Is(err, domain.ErrMFAEnabled),
+		errors.Is(err, domain.ErrOrgExists),
+		errors.Is(err, domain.ErrOrgAdminRequired),
+		errors.Is(err, domain.ErrSpaceshipExists),
+		errors.Is(err, domain.ErrServiceNumberUsed),
+		errors.Is(err, domain.ErrCrewDischarged),
+		errors.Is(err, domain.ErrCrewAssigned),
+		errors.Is(err, domain.ErrCrewComplete),
+		errors.Is(err, domain.ErrCrewBelowRoster),
+		errors.Is(err, domain.ErrUnderCrewed),
+		errors.Is(err, domain.ErrWorkOrderMove),
+		errors.Is(err, domain.ErrWorkOrderClosed),
+		errors.Is(err, domain.ErrWorkOrdersOpen),
+		errors.Is(err, domain.ErrShipClassExists),
+		errors.Is(err, domain.ErrShipClassInUse),
+		errors.Is(err, domain.ErrPatchTest),
+		errors.Is(err, domain.ErrIdempotencyBusy),
+		errors.Is(err, domain.ErrBatchAborted),
+		errors.Is(err, domain.ErrRouteOrigin),
+		errors.Is(err, domain.ErrMissionMove),
+		errors.Is(err, domain.ErrMissionClosed),
+		errors.Is(err, domain.ErrMissionConflict),
+		errors.Is(err, domain.ErrSpaceshipDamaged),
+		errors.Is(err, domain.ErrMissionCapability),
+		errors.Is(err, domain.ErrSpaceshipOnMission):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrIdempotencyReused),
+		errors.Is(err, domain.ErrNoRoute):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrPasswordWrong),
+		errors.Is(err, domain.ErrAuthFailed),
+		errors.Is(err, domain.ErrMFACodeInvalid),
+		errors.Is(err, domain.ErrAPIKeyInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrImageType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, domain.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrLoginLocked):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrEmailNotVerified),
+		errors.Is(err, domain.ErrMFARequired),
+		errors.Is(err, domain.ErrForbidden),
+		errors.Is(err, domain.ErrScopeMissing),
+		errors.Is(err, domain.ErrNotMember):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrRegRequiredFields),
+		errors.Is(err, domain.ErrNameRequired),
+		errors.Is(err, domain.ErrRePasswordWrong),
+		errors.Is(err, domain.ErrRoleWrong),
+		errors.Is(err, domain.ErrTokenInvalid),
+		errors.Is(err, domain.ErrMFANotEnabled),
+		errors.Is(err, domain.ErrScopeWrong),
+		errors.Is(err, domain.ErrExpiryWrong),
+		errors.Is(err, domain.ErrImageRequired),
+		errors.Is(err, domain.ErrImageSize),
+		errors.Is(err, domain.ErrSearchQuery),
+		errors.Is(err, domain.ErrRankWrong),
+		errors.Is(err, domain.ErrServiceNumber),
+		errors.Is(err, domain.ErrSeverityWrong),
+		errors.Is(err, domain.ErrDescriptionEmpty),
+		errors.Is(err, domain.ErrPartUnknown),
+		errors.Is(err, domain.ErrWorkOrderStatus),
+		errors.Is(err, domain.ErrCostWrong),
+		errors.Is(err, domain.ErrShipClassUnknown),
+		errors.Is(err, domain.ErrCrewRange),
+		errors.Is(err, domain.ErrValueWrong),
+		errors.Is(err, domain.ErrArmamentLimit),
+		errors.Is(err, domain.ErrReportGroup),
+		errors.Is(err, domain.ErrReportWindow),
+		errors.Is(err, domain.ErrPatchInvalid),
+		errors.Is(err, domain.ErrIdempotencyKey),
+		errors.Is(err, domain.ErrBatchSize),
+		errors.Is(err, domain.ErrBatchOperation),
+		errors.Is(err, domain.ErrGraphQLQuery),
+		errors.Is(err, domain.ErrQueryLimit),
+		errors.Is(err, domain.ErrCurrencyWrong),
+		errors.Is(err, domain.ErrDepreciation),
+		errors.Is(err, domain.ErrAppraisalDate),
+		errors.Is(err, domain.ErrPositionWrong),
+		errors.Is(err, domain.ErrProximityQuery),
+		errors.Is(err, domain.ErrGalaxyInvalid),
+		errors.Is(err, domain.ErrSystemUnknown),
+		errors.Is(err, domain.ErrLaneUnknown),
+		errors.Is(err, domain.ErrRouteMode),
+		errors.Is(err, domain.ErrHyperdriveWrong),
+		errors.Is(err, domain.ErrClosureWrong),
+		errors.Is(err, domain.ErrObjectiveWrong),
+		errors.Is(err, domain.ErrMissionWindow),
+		errors.Is(err, domain.ErrMissionSpaceships),
+		errors.Is(err, domain.ErrFirepowerWrong),
+		errors.Is(err, domain.ErrMissionStatus),
+		errors.Is(err, domain.ErrConversion):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// error envelope of handler error, internal errors are not exposed
+func ErrorResponce(err error) model.ErrorResponce {
+	var httpErr *echo.HTTPError
+
+	status := ErrorStatus(err)
+	switch {
+	case errors.As(err, &httpErr):
+		if msg, ok := httpErr.Message.(string); ok {
+			return model.ErrorResponce{Message: msg}
+		}
+		return model.ErrorResponce{Message: http.StatusText(status)}
+	case status == http.StatusInternalServerError:
+		return model.ErrorResponce{Message: http.StatusText(status)}
+	default:
+		var retryErr *domain.RetryAfterError
+		if errors.As(err, &retryErr) {
+			return model.ErrorResponce{Message: retryErr.Err.Error()}
+		}
+		return model.ErrorResponce{Message: errors.Cause(err).Error()}
+	}
+}
+
+// echo error handler which maps domain errors to http statuses
+func ErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	status := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		ctx.Logger().Error(err)
+	}
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		ctx.Response().Header().Set("Retry-After", headerSeconds(retryErr.After))
+	}
+
+	if ctx.Request().Method == http.MethodHead {
+		err = ctx.NoContent(status)
+	} else {
+		err = renderError(ctx, status, ErrorResponce(err))
+	}
+	if err != nil {
+		ctx.Logger().Error(err)
+	}
+}
+
+// error is rendered in negotiated format, json if it is not acceptable
+func renderError(ctx echo.Context, status int, res model.ErrorResponce) error {
+	f, err := responceFormat(ctx.Request())
+	if err != nil {
+		return ctx.JSON(status, res)
+	}
+	return f.render(ctx, status, res)
+}
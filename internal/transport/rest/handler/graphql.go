@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/transport/graphql"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ GraphQLService = (*graphql.Schema)(nil)
+)
+
+//go:generate mockery --dir . --name GraphQLService --output ./mocks
+type GraphQLService interface {
+	Execute(context.Context, *graphql.Viewer, *graphql.Request) *graphql.Result
+}
+
+type GraphQLHandler struct {
+	service GraphQLService
+}
+
+func NewGraphQLHandler(service GraphQLService) *GraphQLHandler {
+	return &GraphQLHandler{service}
+}
+
+// execute graphql request for organization selected by tenant middleware,
+// errors of resolvers are mapped like errors of rest handlers
+func (h *GraphQLHandler) Query(ctx echo.Context) error {
+
+	req := graphql.Request{}
+	err := ctx.Bind(&req)
+	if err != nil {
+		return err
+	}
+	if req.Query == "" {
+		return domain.ErrGraphQLQuery
+	}
+
+	membership, ok := ctx.Get(membershipContextKey).(*domain.Membership)
+	if !ok {
+		return domain.ErrTenantRequired
+	}
+	viewer := &graphql.Viewer{Membership: membership}
+	if key, ok := contextAPIKey(ctx); ok {
+		viewer.APIKey = key
+	}
+
+	res := h.service.Execute(ctx.Request().Context(), viewer, &req)
+
+	// internal errors are logged and not exposed, status is given in extensions
+	for i := range res.Errors {
+		err := graphql.OriginalError(res.Errors[i])
+		if err == nil {
+			continue
+		}
+		status := ErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			ctx.Logger().Error(err)
+		}
+		res.Errors[i].Message = ErrorResponce(err).Message
+		res.Errors[i].Extensions = map[string]interface{}{"status": status}
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
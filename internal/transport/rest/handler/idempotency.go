@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/idempotency"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	// test interface
+	_ IdempotencyService = (*idempotency.Keeper)(nil)
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// set on replayed responses
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+//go:generate mockery --dir . --name IdempotencyService --output ./mocks
+type IdempotencyService interface {
+	Begin(context.Context, *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	Complete(context.Context, *domain.IdempotencyRecord) error
+	Release(context.Context, *domain.IdempotencyRecord) error
+}
+
+// middleware which replays the first response of POST, PUT, PATCH and DELETE requests
+// with the same Idempotency-Key header, responses with server errors are not stored,
+// must be used after auth and tenant middleware
+func IdempotencyMiddleware(service IdempotencyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			key := req.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !idempotencyMethod(req.Method) {
+				return next(ctx)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return errors.Wrapf(domain.ErrIdempotencyKey, "longer than %d", maxIdempotencyKeyLength)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			record := &domain.IdempotencyRecord{
+				Owner:       requestOwner(ctx),
+				Key:         key,
+				Fingerprint: requestFingerprint(req, body),
+			}
+			replay, err := service.Begin(req.Context(), record)
+			if err != nil {
+				return err
+			}
+			if replay != nil {
+				ctx.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return ctx.Blob(replay.StatusCode, replay.ContentType, replay.Body)
+			}
+
+			// key is freed if handler panics
+			completed := false
+			defer func() {
+				if !completed {
+					_ = service.Release(context.Background(), record)
+				}
+			}()
+
+			res := ctx.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+			err = next(ctx)
+			if err != nil {
+				ctx.Error(err)
+			}
+			res.Writer = recorder.ResponseWriter
+
+			completed = true
+			if res.Status >= http.StatusInternalServerError {
+				err = service.Release(req.Context(), record)
+			} else {
+				record.StatusCode = res.Status
+				record.ContentType = res.Header().Get(echo.HeaderContentType)
+				record.Body = recorder.body.Bytes()
+				err = service.Complete(req.Context(), record)
+			}
+			if err != nil {
+				ctx.Logger().Error(err)
+			}
+
+			return nil
+		}
+	}
+}
+
+func idempotencyMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// hash of request which must be the same on retry, negotiated formats are compared
+// so replayed responce is in requested format
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	parts := []string{req.Method, req.URL.RequestURI(), req.Header.Get(OrgHeader), requestFormats(req)}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writer which keeps copy of response body
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ MFAService = (*service.MFAService)(nil)
+)
+
+//go:generate mockery --dir . --name MFAService --output ./mocks
+type MFAService interface {
+	Challenge(context.Context, string) (domain.MFAChallenge, error)
+	Enroll(context.Context, uint) (*domain.MFAEnrollment, error)
+	Confirm(context.Context, uint, string) ([]string, error)
+	Verify(context.Context, uint, string) error
+	Disable(context.Context, uint, string) error
+	RegenerateRecoveryCodes(context.Context, uint, string) ([]string, error)
+}
+
+type MFAHandler struct {
+	service MFAService
+	tokens  *Tokens
+}
+
+func NewMFAHandler(service MFAService, tokens *Tokens) *MFAHandler {
+	return &MFAHandler{service, tokens}
+}
+
+// exchange mfa token and totp or recovery code for auth tokens
+func (h *MFAHandler) Verify(ctx echo.Context) error {
+	req := new(model.MFAVerifyReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	_, claims, err := h.tokens.parse(req.MFAToken)
+	if err != nil || claims.MFA != domain.MFAChallengeVerify.String() {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid mfa token")
+	}
+
+	userID, ok := claims.userID()
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid mfa token")
+	}
+
+	err = h.service.Verify(ctx.Request().Context(), userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.tokens.issue(claims.Subject, claims.Email)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// start totp enrolment, must be used after jwt enroll middleware
+func (h *MFAHandler) Enroll(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	enrollment, err := h.service.Enroll(ctx.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.MFAEnrollRes{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+		QRCode: enrollment.QRCode,
+	})
+}
+
+// finish enrolment with first code, must be used after jwt enroll middleware
+func (h *MFAHandler) Confirm(ctx echo.Context) error {
+
+	claims, ok := contextClaims(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+	userID, ok := claims.userID()
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	req := new(model.MFACodeReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.service.Confirm(ctx.Request().Context(), userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	res := model.MFARecoveryCodesRes{RecoveryCodes: codes}
+
+	// login forced enrolment, second factor is passed now
+	if claims.MFA == domain.MFAChallengeEnroll.String() {
+		res.UserAuthRes, err = h.tokens.issue(claims.Subject, claims.Email)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func (h *MFAHandler) Disable(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	req := new(model.MFACodeReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.Disable(ctx.Request().Context(), userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+func (h *MFAHandler) RecoveryCodes(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	req := new(model.MFACodeReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(ctx.Request().Context(), userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.MFARecoveryCodesRes{RecoveryCodes: codes})
+}
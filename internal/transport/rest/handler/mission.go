@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ MissionService = (*service.MissionService)(nil)
+)
+
+//go:generate mockery --dir . --name MissionService --output ./mocks
+type MissionService interface {
+	GetAll(context.Context, domain.MissionFilter) ([]*domain.Mission, error)
+	GetById(context.Context, uint) (*domain.Mission, error)
+	Plan(context.Context, *domain.Mission) error
+	Update(context.Context, *domain.Mission) (*domain.Mission, error)
+	Move(context.Context, uint, domain.MissionStatus) (*domain.Mission, error)
+}
+
+type MissionHandler struct {
+	service MissionService
+}
+
+func NewMissionHandler(service MissionService) *MissionHandler {
+	return &MissionHandler{service}
+}
+
+// missions of fleet, open ones by default, status may be "all" or one of statuses,
+// from and to select missions which windows overlap them
+func (h *MissionHandler) GetAll(ctx echo.Context) error {
+
+	filter := domain.MissionFilter{Open: true}
+	if spaceship := ctx.QueryParam("spaceship"); spaceship != "" {
+		id, err := strconv.ParseUint(spaceship, 10, 32)
+		if err != nil {
+			return err
+		}
+		filter.SpaceshipID = uint(id)
+	}
+	switch status := ctx.QueryParam("status"); status {
+	case "", "open":
+	case "all":
+		filter.Open = false
+	default:
+		filter.Open = false
+		filter.Status = domain.MissionStatusFromString(status)
+		if filter.Status == domain.MissionStatusUndefined {
+			return domain.ErrMissionStatus
+		}
+	}
+
+	var err error
+	filter.From, err = reportTime(ctx.QueryParam("from"))
+	if err != nil {
+		return domain.ErrMissionWindow
+	}
+	filter.To, err = reportTime(ctx.QueryParam("to"))
+	if err != nil {
+		return domain.ErrMissionWindow
+	}
+
+	missions, err := h.service.GetAll(ctx.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	res := model.MissionsResponce{Data: make([]model.Mission, 0, len(missions))}
+	for _, m := range missions {
+		res.Data = append(res.Data, model.MissionFromDomain(m))
+	}
+
+	return render(ctx, http.StatusOK, res)
+}
+
+func (h *MissionHandler) GetById(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	mission, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.MissionFromDomain(mission))
+}
+
+func (h *MissionHandler) Plan(ctx echo.Context) error {
+
+	req := new(model.MissionReq)
+	err := bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	mission := req.ToDomain(0)
+	err = h.service.Plan(ctx.Request().Context(), mission)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusCreated, model.MissionFromDomain(mission))
+}
+
+// reschedule or reassign planned mission
+func (h *MissionHandler) Update(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.MissionReq)
+	err = bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	mission, err := h.service.Update(ctx.Request().Context(), req.ToDomain(id))
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.MissionFromDomain(mission))
+}
+
+func (h *MissionHandler) Move(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.MissionStatusReq)
+	err = bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	mission, err := h.service.Move(ctx.Request().Context(), id, domain.MissionStatusFromString(req.Status))
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.MissionFromDomain(mission))
+}
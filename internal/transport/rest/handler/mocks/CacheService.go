@@ -0,0 +1,68 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CacheService is an autogenerated mock type for the CacheService type
+type CacheService struct {
+	mock.Mock
+}
+
+// Purge provides a mock function with given fields: _a0
+func (_m *CacheService) Purge(_a0 context.Context) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stats provides a mock function with given fields: _a0
+func (_m *CacheService) Stats(_a0 context.Context) (domain.CacheStats, error) {
+	ret := _m.Called(_a0)
+
+	var r0 domain.CacheStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.CacheStats, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.CacheStats); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(domain.CacheStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCacheService creates a new instance of CacheService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCacheService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CacheService {
+	mock := &CacheService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,157 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CrewService is an autogenerated mock type for the CrewService type
+type CrewService struct {
+	mock.Mock
+}
+
+// Discharge provides a mock function with given fields: _a0, _a1
+func (_m *CrewService) Discharge(_a0 context.Context, _a1 uint) (*domain.CrewMember, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.CrewMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.CrewMember, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.CrewMember); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Enlist provides a mock function with given fields: _a0, _a1
+func (_m *CrewService) Enlist(_a0 context.Context, _a1 *domain.CrewMember) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CrewMember) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *CrewService) GetAll(_a0 context.Context, _a1 domain.CrewFilter) ([]*domain.CrewMember, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.CrewMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CrewFilter) ([]*domain.CrewMember, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CrewFilter) []*domain.CrewMember); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CrewFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *CrewService) GetById(_a0 context.Context, _a1 uint) (*domain.CrewMember, []*domain.CrewAssignment, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.CrewMember
+	var r1 []*domain.CrewAssignment
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.CrewMember, []*domain.CrewAssignment, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.CrewMember); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) []*domain.CrewAssignment); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*domain.CrewAssignment)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Transfer provides a mock function with given fields: _a0, _a1, _a2
+func (_m *CrewService) Transfer(_a0 context.Context, _a1 uint, _a2 uint) (*domain.CrewMember, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.CrewMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (*domain.CrewMember, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *domain.CrewMember); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.CrewMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCrewService creates a new instance of CrewService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCrewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CrewService {
+	mock := &CrewService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	graphql "github.com/Je33/imperial_fleet/internal/transport/graphql"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// GraphQLService is an autogenerated mock type for the GraphQLService type
+type GraphQLService struct {
+	mock.Mock
+}
+
+// Execute provides a mock function with given fields: _a0, _a1, _a2
+func (_m *GraphQLService) Execute(_a0 context.Context, _a1 *graphql.Viewer, _a2 *graphql.Request) *graphql.Result {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *graphql.Result
+	if rf, ok := ret.Get(0).(func(context.Context, *graphql.Viewer, *graphql.Request) *graphql.Result); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*graphql.Result)
+		}
+	}
+
+	return r0
+}
+
+// NewGraphQLService creates a new instance of GraphQLService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGraphQLService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GraphQLService {
+	mock := &GraphQLService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,148 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MissionService is an autogenerated mock type for the MissionService type
+type MissionService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *MissionService) GetAll(_a0 context.Context, _a1 domain.MissionFilter) ([]*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MissionFilter) ([]*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MissionFilter) []*domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MissionFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *MissionService) GetById(_a0 context.Context, _a1 uint) (*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MissionService) Move(_a0 context.Context, _a1 uint, _a2 domain.MissionStatus) (*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.MissionStatus) (*domain.Mission, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.MissionStatus) *domain.Mission); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, domain.MissionStatus) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Plan provides a mock function with given fields: _a0, _a1
+func (_m *MissionService) Plan(_a0 context.Context, _a1 *domain.Mission) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Mission) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *MissionService) Update(_a0 context.Context, _a1 *domain.Mission) (*domain.Mission, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Mission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Mission) (*domain.Mission, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Mission) *domain.Mission); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Mission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Mission) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMissionService creates a new instance of MissionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMissionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MissionService {
+	mock := &MissionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,174 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrganizationService is an autogenerated mock type for the OrganizationService type
+type OrganizationService struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *OrganizationService) AddMember(_a0 context.Context, _a1 uint, _a2 uint, _a3 string, _a4 domain.OrgRole) (*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	var r0 *domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string, domain.OrgRole) (*domain.Membership, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string, domain.OrgRole) *domain.Membership); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, string, domain.OrgRole) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: _a0, _a1, _a2
+func (_m *OrganizationService) Create(_a0 context.Context, _a1 uint, _a2 string) (*domain.Organization, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) (*domain.Organization, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) *domain.Organization); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: _a0, _a1
+func (_m *OrganizationService) List(_a0 context.Context, _a1 uint) ([]*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*domain.Membership, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*domain.Membership); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Members provides a mock function with given fields: _a0, _a1, _a2
+func (_m *OrganizationService) Members(_a0 context.Context, _a1 uint, _a2 uint) ([]*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) ([]*domain.Membership, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) []*domain.Membership); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *OrganizationService) RemoveMember(_a0 context.Context, _a1 uint, _a2 uint, _a3 uint) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resolve provides a mock function with given fields: _a0, _a1, _a2
+func (_m *OrganizationService) Resolve(_a0 context.Context, _a1 uint, _a2 uint) (*domain.Membership, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Membership
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (*domain.Membership, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *domain.Membership); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Membership)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrganizationService creates a new instance of OrganizationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrganizationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrganizationService {
+	mock := &OrganizationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,103 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PositionService is an autogenerated mock type for the PositionService type
+type PositionService struct {
+	mock.Mock
+}
+
+// Near provides a mock function with given fields: _a0, _a1
+func (_m *PositionService) Near(_a0 context.Context, _a1 domain.ProximityQuery) ([]*domain.ProximityHit, int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ProximityHit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ProximityQuery) ([]*domain.ProximityHit, int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ProximityQuery) []*domain.ProximityHit); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ProximityHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ProximityQuery) int); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.ProximityQuery) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Positions provides a mock function with given fields: _a0, _a1, _a2
+func (_m *PositionService) Positions(_a0 context.Context, _a1 uint, _a2 int) ([]*domain.Position, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) ([]*domain.Position, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) []*domain.Position); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Position)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Report provides a mock function with given fields: _a0, _a1, _a2
+func (_m *PositionService) Report(_a0 context.Context, _a1 uint, _a2 *domain.Position) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, *domain.Position) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPositionService creates a new instance of PositionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPositionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PositionService {
+	mock := &PositionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
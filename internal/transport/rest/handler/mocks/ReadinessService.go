@@ -0,0 +1,82 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReadinessService is an autogenerated mock type for the ReadinessService type
+type ReadinessService struct {
+	mock.Mock
+}
+
+// Fleet provides a mock function with given fields: _a0
+func (_m *ReadinessService) Fleet(_a0 context.Context) (*domain.FleetReadiness, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.FleetReadiness
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.FleetReadiness, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.FleetReadiness); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.FleetReadiness)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Spaceship provides a mock function with given fields: _a0, _a1
+func (_m *ReadinessService) Spaceship(_a0 context.Context, _a1 uint) (*domain.Readiness, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Readiness
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Readiness, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Readiness); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Readiness)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReadinessService creates a new instance of ReadinessService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReadinessService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReadinessService {
+	mock := &ReadinessService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,134 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReportService is an autogenerated mock type for the ReportService type
+type ReportService struct {
+	mock.Mock
+}
+
+// Armament provides a mock function with given fields: _a0, _a1
+func (_m *ReportService) Armament(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.ArmamentReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ArmamentReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.ArmamentReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.ArmamentReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ArmamentReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fleet provides a mock function with given fields: _a0, _a1
+func (_m *ReportService) Fleet(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.FleetReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.FleetReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.FleetReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.FleetReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.FleetReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Readiness provides a mock function with given fields: _a0, _a1
+func (_m *ReportService) Readiness(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.ReadinessReportRow, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.ReadinessReportRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.ReadinessReportRow, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.ReadinessReportRow); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ReadinessReportRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Trends provides a mock function with given fields: _a0, _a1
+func (_m *ReportService) Trends(_a0 context.Context, _a1 domain.ReportQuery) ([]*domain.FleetSnapshot, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.FleetSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) ([]*domain.FleetSnapshot, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ReportQuery) []*domain.FleetSnapshot); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.FleetSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ReportQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReportService creates a new instance of ReportService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReportService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReportService {
+	mock := &ReportService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RouteService is an autogenerated mock type for the RouteService type
+type RouteService struct {
+	mock.Mock
+}
+
+// CloseLane provides a mock function with given fields: _a0, _a1
+func (_m *RouteService) CloseLane(_a0 context.Context, _a1 *domain.HyperlaneClosure) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.HyperlaneClosure) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Galaxy provides a mock function with given fields: _a0
+func (_m *RouteService) Galaxy(_a0 context.Context) (*domain.Galaxy, []*domain.HyperlaneClosure, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.Galaxy
+	var r1 []*domain.HyperlaneClosure
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.Galaxy, []*domain.HyperlaneClosure, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.Galaxy); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Galaxy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) []*domain.HyperlaneClosure); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*domain.HyperlaneClosure)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
+		r2 = rf(_a0)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// OpenLane provides a mock function with given fields: _a0, _a1
+func (_m *RouteService) OpenLane(_a0 context.Context, _a1 uint) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Plan provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *RouteService) Plan(_a0 context.Context, _a1 uint, _a2 string, _a3 domain.RouteMode) (*domain.Route, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *domain.Route
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, domain.RouteMode) (*domain.Route, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, domain.RouteMode) *domain.Route); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Route)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, domain.RouteMode) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upload provides a mock function with given fields: _a0, _a1
+func (_m *RouteService) Upload(_a0 context.Context, _a1 []byte) (*domain.Galaxy, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Galaxy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.Galaxy, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.Galaxy); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Galaxy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRouteService creates a new instance of RouteService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRouteService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RouteService {
+	mock := &RouteService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,169 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceAccountService is an autogenerated mock type for the ServiceAccountService type
+type ServiceAccountService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *ServiceAccountService) Create(_a0 context.Context, _a1 uint, _a2 uint, _a3 *domain.ServiceAccountCreateReq) (*domain.ServiceAccount, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *domain.ServiceAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, *domain.ServiceAccountCreateReq) (*domain.ServiceAccount, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, *domain.ServiceAccountCreateReq) *domain.ServiceAccount); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ServiceAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, *domain.ServiceAccountCreateReq) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateKey provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *ServiceAccountService) CreateKey(_a0 context.Context, _a1 uint, _a2 uint, _a3 uint, _a4 *domain.APIKeyCreateReq) (*domain.APIKey, string, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	var r0 *domain.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint, *domain.APIKeyCreateReq) (*domain.APIKey, string, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint, *domain.APIKeyCreateReq) *domain.APIKey); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, uint, *domain.APIKeyCreateReq) string); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, uint, uint, *domain.APIKeyCreateReq) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Keys provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *ServiceAccountService) Keys(_a0 context.Context, _a1 uint, _a2 uint, _a3 uint) ([]*domain.APIKey, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 []*domain.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) ([]*domain.APIKey, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) []*domain.APIKey); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ServiceAccountService) List(_a0 context.Context, _a1 uint, _a2 uint) ([]*domain.ServiceAccount, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.ServiceAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) ([]*domain.ServiceAccount, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) []*domain.ServiceAccount); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.ServiceAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *ServiceAccountService) Revoke(_a0 context.Context, _a1 uint, _a2 uint, _a3 uint) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeKey provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *ServiceAccountService) RevokeKey(_a0 context.Context, _a1 uint, _a2 uint, _a3 uint, _a4 uint) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewServiceAccountService creates a new instance of ServiceAccountService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceAccountService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceAccountService {
+	mock := &ServiceAccountService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -15,6 +15,32 @@ type SpaceshipService struct {
 	mock.Mock
 }
 
+// Batch provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipService) Batch(_a0 context.Context, _a1 []domain.SpaceshipOperation, _a2 bool) ([]domain.SpaceshipOperationResult, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []domain.SpaceshipOperationResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.SpaceshipOperation, bool) ([]domain.SpaceshipOperationResult, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.SpaceshipOperation, bool) []domain.SpaceshipOperationResult); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SpaceshipOperationResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.SpaceshipOperation, bool) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateSpaceship provides a mock function with given fields: _a0, _a1
 func (_m *SpaceshipService) CreateSpaceship(_a0 context.Context, _a1 *domain.Spaceship) error {
 	ret := _m.Called(_a0, _a1)
@@ -43,25 +69,25 @@ func (_m *SpaceshipService) DeleteSpaceship(_a0 context.Context, _a1 *domain.Spa
 	return r0
 }
 
-// GetAll provides a mock function with given fields: _a0
-func (_m *SpaceshipService) GetAll(_a0 context.Context) ([]*domain.Spaceship, error) {
-	ret := _m.Called(_a0)
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *SpaceshipService) GetAll(_a0 context.Context, _a1 domain.SpaceshipFilter) ([]*domain.Spaceship, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []*domain.Spaceship
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*domain.Spaceship, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) ([]*domain.Spaceship, error)); ok {
+		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*domain.Spaceship); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SpaceshipFilter) []*domain.Spaceship); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*domain.Spaceship)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SpaceshipFilter) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -95,6 +121,58 @@ func (_m *SpaceshipService) GetById(_a0 context.Context, _a1 uint) (*domain.Spac
 	return r0, r1
 }
 
+// GetImage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipService) GetImage(_a0 context.Context, _a1 uint, _a2 string) (*domain.Blob, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Blob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) (*domain.Blob, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) *domain.Blob); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Blob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchSpaceship provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SpaceshipService) PatchSpaceship(_a0 context.Context, _a1 uint, _a2 domain.SpaceshipPatch) (*domain.Spaceship, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.Spaceship
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.SpaceshipPatch) (*domain.Spaceship, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.SpaceshipPatch) *domain.Spaceship); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Spaceship)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, domain.SpaceshipPatch) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateSpaceship provides a mock function with given fields: _a0, _a1
 func (_m *SpaceshipService) UpdateSpaceship(_a0 context.Context, _a1 *domain.Spaceship) error {
 	ret := _m.Called(_a0, _a1)
@@ -109,6 +187,20 @@ func (_m *SpaceshipService) UpdateSpaceship(_a0 context.Context, _a1 *domain.Spa
 	return r0
 }
 
+// UploadImage provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *SpaceshipService) UploadImage(_a0 context.Context, _a1 uint, _a2 []byte, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []byte, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewSpaceshipService creates a new instance of SpaceshipService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewSpaceshipService(t interface {
@@ -0,0 +1,108 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ValuationService is an autogenerated mock type for the ValuationService type
+type ValuationService struct {
+	mock.Mock
+}
+
+// Appraise provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ValuationService) Appraise(_a0 context.Context, _a1 uint, _a2 *domain.Valuation) (*domain.SpaceshipValuation, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.SpaceshipValuation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, *domain.Valuation) (*domain.SpaceshipValuation, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, *domain.Valuation) *domain.SpaceshipValuation); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SpaceshipValuation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, *domain.Valuation) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fleet provides a mock function with given fields: _a0
+func (_m *ValuationService) Fleet(_a0 context.Context) (*domain.FleetValuation, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *domain.FleetValuation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.FleetValuation, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.FleetValuation); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.FleetValuation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Spaceship provides a mock function with given fields: _a0, _a1
+func (_m *ValuationService) Spaceship(_a0 context.Context, _a1 uint) (*domain.SpaceshipValuation, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.SpaceshipValuation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.SpaceshipValuation, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.SpaceshipValuation); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SpaceshipValuation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewValuationService creates a new instance of ValuationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewValuationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ValuationService {
+	mock := &ValuationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
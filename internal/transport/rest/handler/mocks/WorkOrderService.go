@@ -0,0 +1,174 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Je33/imperial_fleet/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkOrderService is an autogenerated mock type for the WorkOrderService type
+type WorkOrderService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderService) GetAll(_a0 context.Context, _a1 domain.WorkOrderFilter) ([]*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WorkOrderFilter) ([]*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.WorkOrderFilter) []*domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.WorkOrderFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderService) GetById(_a0 context.Context, _a1 uint) (*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WorkOrderService) Move(_a0 context.Context, _a1 uint, _a2 domain.WorkOrderStatus) (*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.WorkOrderStatus) (*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, domain.WorkOrderStatus) *domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, domain.WorkOrderStatus) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Report provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderService) Report(_a0 context.Context, _a1 *domain.WorkOrder) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SLABreaches provides a mock function with given fields: _a0
+func (_m *WorkOrderService) SLABreaches(_a0 context.Context) ([]*domain.WorkOrderBreach, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*domain.WorkOrderBreach
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*domain.WorkOrderBreach, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*domain.WorkOrderBreach); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.WorkOrderBreach)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *WorkOrderService) Update(_a0 context.Context, _a1 *domain.WorkOrder) (*domain.WorkOrder, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) (*domain.WorkOrder, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) *domain.WorkOrder); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.WorkOrder) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWorkOrderService creates a new instance of WorkOrderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWorkOrderService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WorkOrderService {
+	mock := &WorkOrderService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ OrganizationService = (*service.OrganizationService)(nil)
+)
+
+const (
+	// header with id of organization which request is made for
+	OrgHeader = "X-Org-ID"
+	// echo context key of membership which selects tenant
+	membershipContextKey = "membership"
+)
+
+//go:generate mockery --dir . --name OrganizationService --output ./mocks
+type OrganizationService interface {
+	Create(context.Context, uint, string) (*domain.Organization, error)
+	List(context.Context, uint) ([]*domain.Membership, error)
+	Resolve(context.Context, uint, uint) (*domain.Membership, error)
+	Members(context.Context, uint, uint) ([]*domain.Membership, error)
+	AddMember(context.Context, uint, uint, string, domain.OrgRole) (*domain.Membership, error)
+	RemoveMember(context.Context, uint, uint, uint) error
+}
+
+type OrganizationHandler struct {
+	service OrganizationService
+}
+
+func NewOrganizationHandler(service OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service}
+}
+
+// middleware which puts organization of request into request context,
+// organization is selected by header or the oldest membership of user is used,
+// key of service account is limited to organization of account
+// must be used after jwt or auth middleware
+func TenantMiddleware(orgs OrganizationService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			var orgID uint
+			if header := ctx.Request().Header.Get(OrgHeader); header != "" {
+				id, err := strconv.ParseUint(header, 10, 32)
+				if err != nil {
+					return domain.ErrConversion
+				}
+				orgID = uint(id)
+			}
+
+			var membership *domain.Membership
+			if key, ok := contextAPIKey(ctx); ok && key.ServiceAccount != nil {
+				// service account is a member of its own organization only
+				membership = key.ServiceAccount.Membership()
+				if orgID != 0 && orgID != membership.OrgID {
+					return domain.ErrNotMember
+				}
+			} else {
+				userID, ok := contextUserID(ctx)
+				if !ok {
+					return echo.ErrUnauthorized
+				}
+
+				var err error
+				membership, err = orgs.Resolve(ctx.Request().Context(), userID, orgID)
+				if err != nil {
+					return err
+				}
+			}
+			ctx.Set(membershipContextKey, membership)
+			ctx.SetRequest(ctx.Request().WithContext(tenant.WithID(ctx.Request().Context(), membership.OrgID)))
+			ctx.Response().Header().Set(OrgHeader, strconv.FormatUint(uint64(membership.OrgID), 10))
+
+			return next(ctx)
+		}
+	}
+}
+
+// middleware which allows members with role in organization of request
+// must be used after tenant middleware
+func RequireOrgRole(role domain.OrgRole) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			membership, ok := ctx.Get(membershipContextKey).(*domain.Membership)
+			if !ok {
+				return domain.ErrTenantRequired
+			}
+			if !membership.Role.Allows(role) {
+				return domain.ErrForbidden
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// id of user authenticated by api key or jwt
+func contextUserID(ctx echo.Context) (uint, bool) {
+	if key, ok := contextAPIKey(ctx); ok {
+		return key.UserID, true
+	}
+	return contextSubject(ctx)
+}
+
+// organizations of user
+func (h *OrganizationHandler) List(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	memberships, err := h.service.List(ctx.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	orgs := make([]model.Organization, 0, len(memberships))
+	for _, m := range memberships {
+		orgs = append(orgs, model.OrganizationFromDomain(m))
+	}
+
+	return ctx.JSON(http.StatusOK, model.OrganizationsResponce{Data: orgs})
+}
+
+// create organization, user becomes its admin
+func (h *OrganizationHandler) Create(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	req := new(model.OrganizationCreateReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	org, err := h.service.Create(ctx.Request().Context(), userID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.Organization{
+		ID:   org.ID,
+		Name: org.Name,
+		Role: "admin",
+	})
+}
+
+func (h *OrganizationHandler) Members(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	members, err := h.service.Members(ctx.Request().Context(), userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	restMembers := make([]model.Member, 0, len(members))
+	for _, m := range members {
+		restMembers = append(restMembers, model.MemberFromDomain(m))
+	}
+
+	return ctx.JSON(http.StatusOK, model.MembersResponce{Data: restMembers})
+}
+
+// add member or change role of member
+func (h *OrganizationHandler) PutMember(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.MemberReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	member, err := h.service.AddMember(ctx.Request().Context(), userID, orgID, req.Email, domain.OrgRoleFromString(req.Role))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.MemberFromDomain(member))
+}
+
+func (h *OrganizationHandler) DeleteMember(ctx echo.Context) error {
+
+	callerID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	userID, err := paramID(ctx, "userId")
+	if err != nil {
+		return err
+	}
+
+	err = h.service.RemoveMember(ctx.Request().Context(), callerID, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+// positive id from path param
+func paramID(ctx echo.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ PositionService = (*service.PositionService)(nil)
+)
+
+//go:generate mockery --dir . --name PositionService --output ./mocks
+type PositionService interface {
+	Report(context.Context, uint, *domain.Position) error
+	Positions(context.Context, uint, int) ([]*domain.Position, error)
+	Near(context.Context, domain.ProximityQuery) ([]*domain.ProximityHit, int, error)
+}
+
+type PositionHandler struct {
+	service PositionService
+}
+
+func NewPositionHandler(service PositionService) *PositionHandler {
+	return &PositionHandler{service}
+}
+
+// report position of spaceship, responds with recorded position
+func (h *PositionHandler) Report(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.PositionReportReq)
+	err = bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	position := req.ToDomain()
+	err = h.service.Report(ctx.Request().Context(), id, position)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusCreated, model.PositionFromDomain(position))
+}
+
+// log of spaceship positions from the newest, at most limit are returned
+func (h *PositionHandler) Positions(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	limit := 0
+	if param := ctx.QueryParam("limit"); param != "" {
+		limit, err = strconv.Atoi(param)
+		if err != nil {
+			return domain.ErrConversion
+		}
+	}
+
+	positions, err := h.service.Positions(ctx.Request().Context(), id, limit)
+	if err != nil {
+		return err
+	}
+
+	res := model.PositionsResponce{Data: make([]model.Position, 0, len(positions))}
+	for _, p := range positions {
+		res.Data = append(res.Data, model.PositionFromDomain(p))
+	}
+
+	return render(ctx, http.StatusOK, res)
+}
+
+// spaceships within radius of point x, y, z, absent coordinates are zero
+func (h *PositionHandler) Near(ctx echo.Context) error {
+
+	query := domain.ProximityQuery{}
+	params := []struct {
+		name  string
+		value *float64
+	}{
+		{"x", &query.Point.X},
+		{"y", &query.Point.Y},
+		{"z", &query.Point.Z},
+		{"radius", &query.Radius},
+	}
+	for _, p := range params {
+		param := ctx.QueryParam(p.name)
+		if param == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return domain.ErrConversion
+		}
+		*p.value = v
+	}
+	if param := ctx.QueryParam("limit"); param != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(param)
+		if err != nil {
+			return domain.ErrConversion
+		}
+	}
+
+	hits, total, err := h.service.Near(ctx.Request().Context(), query)
+	if err != nil {
+		return err
+	}
+
+	res := model.ProximityResponce{
+		Data:  make([]model.ProximityHit, 0, len(hits)),
+		Total: total,
+	}
+	for _, hit := range hits {
+		res.Data = append(res.Data, model.ProximityHitFromDomain(hit))
+	}
+
+	return render(ctx, http.StatusOK, res)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/ratelimit"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// key of rate limit bucket for request
+type RateLimitKeyFunc func(echo.Context) string
+
+// rate limit by client ip
+func RateLimitByIP(ctx echo.Context) string {
+	return "ip:" + ctx.RealIP()
+}
+
+// rate limit by authenticated user or api key, falls back to client ip
+// must be used after jwt or auth middleware
+func RateLimitByUser(ctx echo.Context) string {
+	return requestOwner(ctx)
+}
+
+// authenticated user or api key which sent request, falls back to client ip
+func requestOwner(ctx echo.Context) string {
+	if key, ok := contextAPIKey(ctx); ok {
+		return "apikey:" + strconv.FormatUint(uint64(key.ID), 10)
+	}
+	if token, ok := ctx.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(*jwtCustomClaims); ok && claims.Subject != "" {
+			return "user:" + claims.Subject
+		}
+	}
+	return RateLimitByIP(ctx)
+}
+
+// rate limit middleware with RateLimit-* headers, responds 429 with Retry-After when limit exceeded
+func RateLimitMiddleware(limiter *ratelimit.Limiter, key RateLimitKeyFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			res := limiter.Allow(key(ctx))
+
+			// unlimited
+			if res.Limit == 0 {
+				return next(ctx)
+			}
+
+			header := ctx.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", headerSeconds(res.Reset))
+
+			if !res.Allowed {
+				header.Set("Retry-After", headerSeconds(res.RetryAfter))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// duration in whole seconds rounded up
+func headerSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ ReadinessService = (*service.ReadinessService)(nil)
+)
+
+//go:generate mockery --dir . --name ReadinessService --output ./mocks
+type ReadinessService interface {
+	Spaceship(context.Context, uint) (*domain.Readiness, error)
+	Fleet(context.Context) (*domain.FleetReadiness, error)
+}
+
+type ReadinessHandler struct {
+	service ReadinessService
+}
+
+func NewReadinessHandler(service ReadinessService) *ReadinessHandler {
+	return &ReadinessHandler{service}
+}
+
+// readiness score of spaceship with breakdown by factors
+func (h *ReadinessHandler) Spaceship(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	readiness, err := h.service.Spaceship(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.ReadinessFromDomain(readiness))
+}
+
+// readiness of fleet of organization
+func (h *ReadinessHandler) Fleet(ctx echo.Context) error {
+
+	fleet, err := h.service.Fleet(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.FleetReadinessFromDomain(fleet))
+}
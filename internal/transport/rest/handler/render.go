@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// media types of rendered responces and decoded requests, json is the default
+const (
+	MIMEApplicationYAML    = "application/yaml"
+	MIMEApplicationMsgpack = "application/msgpack"
+	MIMETextCSV            = "text/csv"
+
+	// query param which overrides accept header
+	formatParam = "format"
+
+	// key of format negotiated by middleware in echo context
+	formatContextKey = "format"
+)
+
+// format of responces and requests, yaml and msgpack have the same fields as json,
+// csv is available for models which are tables
+type format struct {
+	name      string
+	mediaType string
+	// other media types of format
+	aliases []string
+	encode  func(interface{}) ([]byte, error)
+	decode  func([]byte, interface{}) error
+}
+
+var formats = []*format{
+	{
+		name:      "json",
+		mediaType: echo.MIMEApplicationJSON,
+		encode:    json.Marshal,
+		decode:    json.Unmarshal,
+	},
+	{
+		name:      "csv",
+		mediaType: MIMETextCSV,
+		encode:    encodeCSV,
+		decode:    decodeCSV,
+	},
+	{
+		name:      "yaml",
+		mediaType: MIMEApplicationYAML,
+		aliases:   []string{"application/x-yaml", "text/yaml", "text/x-yaml"},
+		encode:    transcode(yaml.Marshal),
+		decode:    viaJSON(yaml.Unmarshal),
+	},
+	{
+		name:      "msgpack",
+		mediaType: MIMEApplicationMsgpack,
+		aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		encode:    transcode(msgpack.Marshal),
+		decode:    viaJSON(msgpack.Unmarshal),
+	},
+}
+
+// middleware which negotiates responce format before handler, so request which accepts
+// none of formats is refused by 406 before it changes anything, must be used before idempotency middleware
+func NegotiateMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			f, err := responceFormat(ctx.Request())
+			if err != nil {
+				return err
+			}
+			ctx.Set(formatContextKey, f)
+			return next(ctx)
+		}
+	}
+}
+
+// write responce in format chosen by format param or accept header,
+// responds 406 if none of accepted formats is available
+func render(ctx echo.Context, status int, v interface{}) error {
+
+	if f, ok := ctx.Get(formatContextKey).(*format); ok {
+		return f.render(ctx, status, v)
+	}
+
+	f, err := responceFormat(ctx.Request())
+	if err != nil {
+		return err
+	}
+
+	return f.render(ctx, status, v)
+}
+
+func (f *format) render(ctx echo.Context, status int, v interface{}) error {
+	body, err := f.encode(v)
+	if err != nil {
+		return err
+	}
+	ctx.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	contentType := f.mediaType
+	if f.name == "json" || f.name == "csv" {
+		contentType += "; charset=utf-8"
+	}
+	return ctx.Blob(status, contentType, body)
+}
+
+// decode request body by content type, json requests are bound by echo as before
+func bind(ctx echo.Context, v interface{}) error {
+
+	req := ctx.Request()
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	f := formatByMediaType(mediaType)
+	if f == nil || f.name == "json" {
+		return ctx.Bind(v)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	err = f.decode(body, v)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// format of format param, or the most preferred format of accept header, json if any is accepted
+func responceFormat(req *http.Request) (*format, error) {
+
+	if name := req.URL.Query().Get(formatParam); name != "" {
+		for _, f := range formats {
+			if f.name == name {
+				return f, nil
+			}
+		}
+		return nil, errors.Wrapf(domain.ErrNotAcceptable, "format %q", name)
+	}
+
+	accept := req.Header.Get(echo.HeaderAccept)
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], nil
+	}
+
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+	ranges := []accepted{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, accepted{mediaType, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		switch r.mediaType {
+		case "*/*", "application/*":
+			return formats[0], nil
+		}
+		if f := formatByMediaType(r.mediaType); f != nil {
+			return f, nil
+		}
+		if r.mediaType == "text/*" {
+			return formatByMediaType(MIMETextCSV), nil
+		}
+	}
+
+	return nil, errors.Wrapf(domain.ErrNotAcceptable, "accept %q", accept)
+}
+
+// names of body and responce formats of request
+func requestFormats(req *http.Request) string {
+	names := [2]string{"json", ""}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if f := formatByMediaType(mediaType); f != nil {
+		names[0] = f.name
+	}
+	if f, err := responceFormat(req); err == nil {
+		names[1] = f.name
+	}
+	return names[0] + "/" + names[1]
+}
+
+func formatByMediaType(mediaType string) *format {
+	for _, f := range formats {
+		if f.mediaType == mediaType {
+			return f
+		}
+		for _, alias := range f.aliases {
+			if alias == mediaType {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func encodeCSV(v interface{}) ([]byte, error) {
+	table, ok := v.(model.CSVTable)
+	if !ok {
+		return nil, errors.Wrap(domain.ErrNotAcceptable, "responce is not a table")
+	}
+	buf := bytes.Buffer{}
+	w := csv.NewWriter(&buf)
+	err := w.WriteAll(table.CSVRows())
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csv request is header and one record
+func decodeCSV(body []byte, v interface{}) error {
+	record, ok := v.(model.CSVRecord)
+	if !ok {
+		return echo.ErrUnsupportedMediaType
+	}
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "csv body can't be decoded")
+	}
+	if len(rows) != 2 {
+		return echo.NewHTTPError(http.StatusBadRequest, "csv body must be header and one record")
+	}
+	return record.FromCSV(rows[0], rows[1])
+}
+
+// encoder which writes the same fields as json, so json tags of models are used
+func transcode(marshal func(interface{}) ([]byte, error)) func(interface{}) ([]byte, error) {
+	return func(v interface{}) ([]byte, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		var generic interface{}
+		err = d.Decode(&generic)
+		if err != nil {
+			return nil, err
+		}
+		return marshal(numbers(generic))
+	}
+}
+
+// decoder which reads the same fields as json
+func viaJSON(unmarshal func([]byte, interface{}) error) func([]byte, interface{}) error {
+	return func(body []byte, v interface{}) error {
+		var generic interface{}
+		err := unmarshal(body, &generic)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "body can't be decoded")
+		}
+		b, err := json.Marshal(generic)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "body can't be decoded")
+		}
+		err = json.Unmarshal(b, v)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "body can't be decoded")
+		}
+		return nil
+	}
+}
+
+// json numbers as integers where possible, so they keep type in other formats
+func numbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = numbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = numbers(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ ReportService = (*service.ReportService)(nil)
+)
+
+//go:generate mockery --dir . --name ReportService --output ./mocks
+type ReportService interface {
+	Fleet(context.Context, domain.ReportQuery) ([]*domain.FleetReportRow, error)
+	Armament(context.Context, domain.ReportQuery) ([]*domain.ArmamentReportRow, error)
+	Readiness(context.Context, domain.ReportQuery) ([]*domain.ReadinessReportRow, error)
+	Trends(context.Context, domain.ReportQuery) ([]*domain.FleetSnapshot, error)
+}
+
+type ReportHandler struct {
+	service ReportService
+}
+
+func NewReportHandler(service ReportService) *ReportHandler {
+	return &ReportHandler{service}
+}
+
+// row of report which can be written as csv
+type reportRow interface {
+	CSV(domain.ReportQuery) ([]string, []string)
+}
+
+// spaceships counted by status and class, e.g. ?group=status,class&from=2024-01-01
+func (h *ReportHandler) Fleet(ctx echo.Context) error {
+
+	q, err := reportQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.Fleet(ctx.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	res := model.FleetReportResponce{Data: make([]model.FleetReportRow, 0, len(rows))}
+	for _, r := range rows {
+		res.Data = append(res.Data, model.FleetReportRowFromDomain(r))
+	}
+
+	return writeReport(ctx, q, res.Data, res)
+}
+
+// armament inventory totals, grouped by armament and optionally by class
+func (h *ReportHandler) Armament(ctx echo.Context) error {
+
+	q, err := reportQuery(ctx)
+	if err != nil {
+		return err
+	}
+	if !q.GroupedBy(domain.ReportGroupArmament) {
+		q.Group = append([]string{domain.ReportGroupArmament}, q.Group...)
+	}
+
+	rows, err := h.service.Armament(ctx.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	res := model.ArmamentReportResponce{Data: make([]model.ArmamentReportRow, 0, len(rows))}
+	for _, r := range rows {
+		res.Data = append(res.Data, model.ArmamentReportRowFromDomain(r))
+	}
+
+	return writeReport(ctx, q, res.Data, res)
+}
+
+// percent of operational spaceships, optionally by class
+func (h *ReportHandler) Readiness(ctx echo.Context) error {
+
+	q, err := reportQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.service.Readiness(ctx.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	total := domain.ReadinessReportRow{}
+	res := model.ReadinessReportResponce{Data: make([]model.ReadinessReportRow, 0, len(rows))}
+	for _, r := range rows {
+		res.Data = append(res.Data, model.ReadinessReportRowFromDomain(r))
+		total.Total += r.Total
+		total.Operational += r.Operational
+	}
+	res.Percent = total.Percent()
+
+	return writeReport(ctx, q, res.Data, res)
+}
+
+// daily snapshots of fleet metrics
+func (h *ReportHandler) Trends(ctx echo.Context) error {
+
+	q, err := reportQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := h.service.Trends(ctx.Request().Context(), q)
+	if err != nil {
+		return err
+	}
+
+	res := model.TrendsReportResponce{Data: make([]model.FleetSnapshot, 0, len(snapshots))}
+	for _, s := range snapshots {
+		res.Data = append(res.Data, model.FleetSnapshotFromDomain(s))
+	}
+
+	return writeReport(ctx, q, res.Data, res)
+}
+
+// query of report from params group, from and to, bounds are RFC 3339 times or dates
+func reportQuery(ctx echo.Context) (domain.ReportQuery, error) {
+
+	q := domain.ReportQuery{}
+	for _, g := range strings.Split(ctx.QueryParam("group"), ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			q.Group = append(q.Group, g)
+		}
+	}
+
+	var err error
+	q.From, err = reportTime(ctx.QueryParam("from"))
+	if err != nil {
+		return q, err
+	}
+	q.To, err = reportTime(ctx.QueryParam("to"))
+	if err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+func reportTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, domain.ErrReportWindow
+}
+
+// write report as csv if it is requested by format param or accept header, otherwise as json
+func writeReport[R reportRow](ctx echo.Context, q domain.ReportQuery, rows []R, res interface{}) error {
+
+	format := ctx.QueryParam("format")
+	if format == "" && strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), "text/csv") {
+		format = "csv"
+	}
+	if format != "csv" {
+		return ctx.JSON(http.StatusOK, res)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Response())
+	var zero R
+	header, _ := zero.CSV(q)
+	err := w.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		_, record := r.CSV(q)
+		err = w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ RouteService = (*service.RouteService)(nil)
+)
+
+//go:generate mockery --dir . --name RouteService --output ./mocks
+type RouteService interface {
+	Plan(context.Context, uint, string, domain.RouteMode) (*domain.Route, error)
+	Galaxy(context.Context) (*domain.Galaxy, []*domain.HyperlaneClosure, error)
+	Upload(context.Context, []byte) (*domain.Galaxy, error)
+	CloseLane(context.Context, *domain.HyperlaneClosure) error
+	OpenLane(context.Context, uint) error
+}
+
+type RouteHandler struct {
+	service RouteService
+}
+
+func NewRouteHandler(service RouteService) *RouteHandler {
+	return &RouteHandler{service}
+}
+
+// route of spaceship from its star system to destination
+func (h *RouteHandler) Plan(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.RouteReq)
+	err = bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	route, err := h.service.Plan(ctx.Request().Context(), id, req.Destination, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.RouteFromDomain(route))
+}
+
+// star systems and hyperlanes with active closures
+func (h *RouteHandler) Galaxy(ctx echo.Context) error {
+
+	galaxy, closures, err := h.service.Galaxy(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.GalaxyFromDomain(galaxy, closures))
+}
+
+// replace galaxy by yaml or json of request body, admins only
+func (h *RouteHandler) Upload(ctx echo.Context) error {
+
+	data, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	galaxy, err := h.service.Upload(ctx.Request().Context(), data)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.GalaxyFromDomain(galaxy, nil))
+}
+
+// close hyperlane, admins only
+func (h *RouteHandler) CloseLane(ctx echo.Context) error {
+
+	req := new(model.HyperlaneClosureReq)
+	err := bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	closure := req.ToDomain()
+	err = h.service.CloseLane(ctx.Request().Context(), closure)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusCreated, model.HyperlaneClosureFromDomain(closure))
+}
+
+// open hyperlane by removing its closure, admins only
+func (h *RouteHandler) OpenLane(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	err = h.service.OpenLane(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.PostResponce{Success: true})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ SearchService = (*service.SearchService)(nil)
+)
+
+//go:generate mockery --dir . --name SearchService --output ./mocks
+type SearchService interface {
+	Search(context.Context, string, int) ([]*domain.SearchHit, int, error)
+	Rebuild(context.Context) (int, error)
+}
+
+type SearchHandler struct {
+	service SearchService
+}
+
+func NewSearchHandler(service SearchService) *SearchHandler {
+	return &SearchHandler{service}
+}
+
+// spaceships of organization matched by query q, at most limit are returned
+func (h *SearchHandler) Search(ctx echo.Context) error {
+
+	limit := 0
+	if param := ctx.QueryParam("limit"); param != "" {
+		var err error
+		limit, err = strconv.Atoi(param)
+		if err != nil {
+			return domain.ErrConversion
+		}
+	}
+
+	hits, total, err := h.service.Search(ctx.Request().Context(), ctx.QueryParam("q"), limit)
+	if err != nil {
+		return err
+	}
+
+	res := model.SearchResponce{
+		Data:  make([]model.SearchHit, 0, len(hits)),
+		Total: total,
+	}
+	for _, hit := range hits {
+		res.Data = append(res.Data, model.SearchHitFromDomain(hit))
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// rebuild search index from database, admins only
+func (h *SearchHandler) Reindex(ctx echo.Context) error {
+
+	count, err := h.service.Rebuild(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.SearchReindexRes{Spaceships: count})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ ServiceAccountService = (*service.ServiceAccountService)(nil)
+)
+
+//go:generate mockery --dir . --name ServiceAccountService --output ./mocks
+type ServiceAccountService interface {
+	List(context.Context, uint, uint) ([]*domain.ServiceAccount, error)
+	Create(context.Context, uint, uint, *domain.ServiceAccountCreateReq) (*domain.ServiceAccount, error)
+	Revoke(context.Context, uint, uint, uint) error
+	CreateKey(context.Context, uint, uint, uint, *domain.APIKeyCreateReq) (*domain.APIKey, string, error)
+	Keys(context.Context, uint, uint, uint) ([]*domain.APIKey, error)
+	RevokeKey(context.Context, uint, uint, uint, uint) error
+}
+
+type ServiceAccountHandler struct {
+	service ServiceAccountService
+}
+
+func NewServiceAccountHandler(service ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{service}
+}
+
+// service accounts of organization
+func (h *ServiceAccountHandler) List(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	accounts, err := h.service.List(ctx.Request().Context(), userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	restAccounts := make([]model.ServiceAccount, 0, len(accounts))
+	for _, a := range accounts {
+		restAccounts = append(restAccounts, model.ServiceAccountFromDomain(a))
+	}
+
+	return ctx.JSON(http.StatusOK, model.ServiceAccountsResponce{Data: restAccounts})
+}
+
+func (h *ServiceAccountHandler) Create(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.ServiceAccountCreateReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	account, err := h.service.Create(ctx.Request().Context(), userID, orgID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.ServiceAccountFromDomain(account))
+}
+
+func (h *ServiceAccountHandler) Delete(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	accountID, err := paramID(ctx, "accountId")
+	if err != nil {
+		return err
+	}
+
+	err = h.service.Revoke(ctx.Request().Context(), userID, orgID, accountID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+// keys of service account
+func (h *ServiceAccountHandler) Keys(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	accountID, err := paramID(ctx, "accountId")
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.service.Keys(ctx.Request().Context(), userID, orgID, accountID)
+	if err != nil {
+		return err
+	}
+
+	restKeys := make([]model.APIKey, 0, len(keys))
+	for _, key := range keys {
+		restKeys = append(restKeys, model.APIKeyFromDomain(key))
+	}
+
+	return ctx.JSON(http.StatusOK, model.APIKeysResponce{Data: restKeys})
+}
+
+// create key of service account, raw key is shown once
+func (h *ServiceAccountHandler) CreateKey(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	accountID, err := paramID(ctx, "accountId")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.APIKeyCreateReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	key, rawKey, err := h.service.CreateKey(ctx.Request().Context(), userID, orgID, accountID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.APIKeyCreateRes{
+		APIKey: model.APIKeyFromDomain(key),
+		Key:    rawKey,
+	})
+}
+
+func (h *ServiceAccountHandler) DeleteKey(ctx echo.Context) error {
+
+	userID, ok := contextSubject(ctx)
+	if !ok {
+		return echo.ErrUnauthorized
+	}
+
+	orgID, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	accountID, err := paramID(ctx, "accountId")
+	if err != nil {
+		return err
+	}
+
+	keyID, err := paramID(ctx, "keyId")
+	if err != nil {
+		return err
+	}
+
+	err = h.service.RevokeKey(ctx.Request().Context(), userID, orgID, accountID, keyID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ ShipClassService = (*service.ShipClassService)(nil)
+)
+
+//go:generate mockery --dir . --name ShipClassService --output ./mocks
+type ShipClassService interface {
+	GetAll(context.Context) ([]*domain.ShipClass, error)
+	GetById(context.Context, uint) (*domain.ShipClass, error)
+	Create(context.Context, *domain.ShipClass) error
+	Update(context.Context, *domain.ShipClass) error
+	Delete(context.Context, uint) error
+	Normalize(context.Context) (int64, error)
+}
+
+type ShipClassHandler struct {
+	service ShipClassService
+}
+
+func NewShipClassHandler(service ShipClassService) *ShipClassHandler {
+	return &ShipClassHandler{service}
+}
+
+func (h *ShipClassHandler) GetAll(ctx echo.Context) error {
+
+	classes, err := h.service.GetAll(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res := model.ShipClassesResponce{Data: make([]model.ShipClass, 0, len(classes))}
+	for _, c := range classes {
+		res.Data = append(res.Data, model.ShipClassFromDomain(c))
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func (h *ShipClassHandler) GetById(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	class, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.ShipClassFromDomain(class))
+}
+
+// create class of catalog, admins only
+func (h *ShipClassHandler) Create(ctx echo.Context) error {
+
+	req := new(model.ShipClass)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	class := req.ToDomain()
+	err = h.service.Create(ctx.Request().Context(), class)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.ShipClassFromDomain(class))
+}
+
+// update class of catalog, admins only
+func (h *ShipClassHandler) Update(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.ShipClass)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	class := req.ToDomain()
+	class.ID = id
+	err = h.service.Update(ctx.Request().Context(), class)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.ShipClassFromDomain(class))
+}
+
+// delete class of catalog which is not used, admins only
+func (h *ShipClassHandler) Delete(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	err = h.service.Delete(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+}
+
+// link spaceships of all organizations to classes by name or alias, admins only
+func (h *ShipClassHandler) Normalize(ctx echo.Context) error {
+
+	count, err := h.service.Normalize(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.ShipClassNormalizeRes{Spaceships: count})
+}
@@ -1,16 +1,24 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/jsonpatch"
 	"github.com/Je33/imperial_fleet/internal/service"
 	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
 
 	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -23,11 +31,15 @@ var (
 
 //go:generate mockery --dir . --name SpaceshipService --output ./mocks
 type SpaceshipService interface {
-	GetAll(context.Context) ([]*domain.Spaceship, error)
+	GetAll(context.Context, domain.SpaceshipFilter) ([]*domain.Spaceship, error)
 	GetById(context.Context, uint) (*domain.Spaceship, error)
 	CreateSpaceship(context.Context, *domain.Spaceship) error
 	UpdateSpaceship(context.Context, *domain.Spaceship) error
+	PatchSpaceship(context.Context, uint, domain.SpaceshipPatch) (*domain.Spaceship, error)
 	DeleteSpaceship(context.Context, *domain.Spaceship) error
+	Batch(context.Context, []domain.SpaceshipOperation, bool) ([]domain.SpaceshipOperationResult, error)
+	UploadImage(context.Context, uint, []byte, string) error
+	GetImage(context.Context, uint, string) (*domain.Blob, error)
 }
 
 type SpaceshipHandler struct {
@@ -40,7 +52,22 @@ func NewSpaceshipHandler(service SpaceshipService) *SpaceshipHandler {
 
 func (h *SpaceshipHandler) GetAll(ctx echo.Context) error {
 
-	spaceships, err := h.service.GetAll(ctx.Request().Context())
+	filter := domain.SpaceshipFilter{
+		Name:   ctx.QueryParam("name"),
+		Class:  ctx.QueryParam("class"),
+		Sector: ctx.QueryParam("sector"),
+		System: ctx.QueryParam("system"),
+	}
+
+	status := ctx.QueryParam("status")
+	if status != "" {
+		filter.Status = domain.SpaceshipStatusFromString(status)
+		if filter.Status == domain.SpaceshipStatusUndefined {
+			return domain.ErrConversion
+		}
+	}
+
+	spaceships, err := h.service.GetAll(ctx.Request().Context(), filter)
 
 	if err != nil {
 		return err
@@ -58,7 +85,7 @@ func (h *SpaceshipHandler) GetAll(ctx echo.Context) error {
 		Data: restSpaceships,
 	}
 
-	return ctx.JSON(http.StatusOK, res)
+	return render(ctx, http.StatusOK, res)
 }
 
 func (h *SpaceshipHandler) GetById(ctx echo.Context) error {
@@ -79,65 +106,33 @@ func (h *SpaceshipHandler) GetById(ctx echo.Context) error {
 		return err
 	}
 
-	modelSpaceshipArmament := make([]model.SpaceshipArmament, 0, len(spaceship.Armament))
-	for _, a := range spaceship.Armament {
-		modelSpaceshipArmament = append(modelSpaceshipArmament, model.SpaceshipArmament{
-			Title: a.Title,
-			Qty:   a.Qty,
-		})
-	}
-
-	restSpaceship := model.SpaceshipFull{
-		ID:       spaceship.ID,
-		Name:     spaceship.Name,
-		Class:    spaceship.Class,
-		Crew:     spaceship.Crew,
-		Image:    spaceship.Image,
-		Value:    spaceship.Value,
-		Status:   spaceship.Status.String(),
-		Armament: modelSpaceshipArmament,
-	}
-	return ctx.JSON(http.StatusOK, restSpaceship)
+	restSpaceship := model.SpaceshipFullFromDomain(spaceship)
+	return render(ctx, http.StatusOK, restSpaceship)
 }
 
 func (h *SpaceshipHandler) CreateSpaceship(ctx echo.Context) error {
 
 	spaceship := new(model.SpaceshipFull)
-	err := ctx.Bind(spaceship)
+	err := bind(ctx, spaceship)
 	if err != nil {
 		return err
 	}
 
-	domainSpaceshipArmament := make([]domain.SpaceshipArmament, 0, len(spaceship.Armament))
-	for _, a := range spaceship.Armament {
-		domainSpaceshipArmament = append(domainSpaceshipArmament, domain.SpaceshipArmament{
-			Title: a.Title,
-			Qty:   a.Qty,
-		})
-	}
-
-	domainSpaceship := &domain.Spaceship{
-		Name:     spaceship.Name,
-		Class:    spaceship.Class,
-		Crew:     spaceship.Crew,
-		Status:   domain.SpaceshipStatusFromString(spaceship.Status),
-		Image:    spaceship.Image,
-		Value:    spaceship.Value,
-		Armament: domainSpaceshipArmament,
-	}
+	spaceship.ID = 0
+	domainSpaceship := spaceship.ToDomain()
 
 	err = h.service.CreateSpaceship(ctx.Request().Context(), domainSpaceship)
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+	return render(ctx, http.StatusOK, model.PostResponce{Success: true})
 }
 
 func (h *SpaceshipHandler) UpdateSpaceship(ctx echo.Context) error {
 
 	spaceship := new(model.SpaceshipFull)
-	err := ctx.Bind(spaceship)
+	err := bind(ctx, spaceship)
 	if err != nil {
 		return err
 	}
@@ -155,31 +150,115 @@ func (h *SpaceshipHandler) UpdateSpaceship(ctx echo.Context) error {
 
 	spaceship.ID = uint(idInt)
 
-	domainSpaceshipArmament := make([]domain.SpaceshipArmament, 0, len(spaceship.Armament))
-	for _, a := range spaceship.Armament {
-		domainSpaceshipArmament = append(domainSpaceshipArmament, domain.SpaceshipArmament{
-			Title: a.Title,
-			Qty:   a.Qty,
-		})
+	domainSpaceship := spaceship.ToDomain()
+
+	err = h.service.UpdateSpaceship(ctx.Request().Context(), domainSpaceship)
+	if err != nil {
+		return err
 	}
 
-	domainSpaceship := &domain.Spaceship{
-		ID:       spaceship.ID,
-		Name:     spaceship.Name,
-		Class:    spaceship.Class,
-		Crew:     spaceship.Crew,
-		Status:   domain.SpaceshipStatusFromString(spaceship.Status),
-		Image:    spaceship.Image,
-		Value:    spaceship.Value,
-		Armament: domainSpaceshipArmament,
+	return render(ctx, http.StatusOK, model.PostResponce{Success: true})
+}
+
+// media types of spaceship patch
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+func (h *SpaceshipHandler) PatchSpaceship(ctx echo.Context) error {
+
+	idString := ctx.Param("id")
+	idInt, err := strconv.Atoi(idString)
+
+	if err != nil {
+		return err
 	}
 
-	err = h.service.UpdateSpaceship(ctx.Request().Context(), domainSpaceship)
+	if idInt < 0 {
+		return domain.ErrNotFound
+	}
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	var patch domain.SpaceshipPatch
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case mergePatchMediaType, echo.MIMEApplicationJSON:
+		patch, err = model.SpaceshipMergePatch(body)
+	case jsonPatchMediaType:
+		var ops jsonpatch.Patch
+		ops, err = jsonpatch.Decode(body)
+		if err != nil {
+			err = errors.Wrapf(domain.ErrPatchInvalid, "%s", err)
+		}
+		patch = model.SpaceshipJSONPatch{Patch: ops}
+	default:
+		return echo.ErrUnsupportedMediaType
+	}
+	if err != nil {
+		return err
+	}
+
+	spaceship, err := h.service.PatchSpaceship(ctx.Request().Context(), uint(idInt), patch)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.SpaceshipFullFromDomain(spaceship))
+}
+
+// run batch of operations, every operation gets result with status of the same single operation,
+// failed atomic batch responds with status of failed operation
+func (h *SpaceshipHandler) Batch(ctx echo.Context) error {
+
+	batch := new(model.SpaceshipBatch)
+	err := bind(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	operations := make([]domain.SpaceshipOperation, 0, len(batch.Operations))
+	for _, o := range batch.Operations {
+		operations = append(operations, o.ToDomain())
+	}
+
+	results, err := h.service.Batch(ctx.Request().Context(), operations, batch.Atomic)
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+	status := http.StatusOK
+	res := model.SpaceshipBatchResponce{
+		Data: make([]model.SpaceshipOperationResult, 0, len(results)),
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			opStatus := ErrorStatus(r.Err)
+			if opStatus == http.StatusInternalServerError {
+				ctx.Logger().Error(r.Err)
+			}
+			errRes := ErrorResponce(r.Err)
+			res.Data = append(res.Data, model.SpaceshipOperationResult{Status: opStatus, Error: &errRes})
+			if batch.Atomic && status == http.StatusOK && !errors.Is(r.Err, domain.ErrBatchAborted) {
+				status = opStatus
+				res.Message = errRes.Message
+			}
+			continue
+		}
+
+		result := model.SpaceshipOperationResult{Status: http.StatusOK}
+		if operations[i].Type != domain.SpaceshipOperationDelete {
+			spaceship := model.SpaceshipFullFromDomain(r.Spaceship)
+			result.Data = &spaceship
+		}
+		res.Data = append(res.Data, result)
+	}
+
+	return render(ctx, status, res)
 }
 
 func (h *SpaceshipHandler) DeleteSpaceship(ctx echo.Context) error {
@@ -206,5 +285,70 @@ func (h *SpaceshipHandler) DeleteSpaceship(ctx echo.Context) error {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, model.PostResponce{Success: true})
+	return render(ctx, http.StatusOK, model.PostResponce{Success: true})
+}
+
+// multipart form field of uploaded image
+const imageFormField = "image"
+
+// body size is limited by route middleware, image limits are checked by service
+func (h *SpaceshipHandler) UploadImage(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	file, err := ctx.FormFile(imageFormField)
+	if err != nil {
+		return errors.Wrapf(domain.ErrImageRequired, "%s: %s", spaceshipErrorPrefix, err)
+	}
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("/v1/spaceships/%d/image", id)
+	err = h.service.UploadImage(ctx.Request().Context(), id, data, url)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.PostResponce{Success: true})
+}
+
+// image is private to tenant, so it is cached by browser only and revalidated by etag
+func (h *SpaceshipHandler) GetImage(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	size := ctx.QueryParam("size")
+	if size == "" {
+		size = service.ImageSizeOriginal
+	}
+
+	image, err := h.service.GetImage(ctx.Request().Context(), id, size)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(image.Data)
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, image.ContentType)
+	res.Header().Set("Cache-Control", "private, max-age=300")
+	res.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+
+	// conditional and range requests are handled by net/http
+	http.ServeContent(res, ctx.Request(), "", time.Unix(image.ModTime, 0), bytes.NewReader(image.Data))
+
+	return nil
 }
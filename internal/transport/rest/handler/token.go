@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errors prefix
+	tokenErrorPrefix = "[transport.rest.handler.token]"
+)
+
+const (
+	authTokenTTL    = time.Hour * 168
+	refreshTokenTTL = time.Hour * 720
+)
+
+// jwt token struct, sub is user id
+type jwtCustomClaims struct {
+	Email string `json:"email"`
+	// refresh token can be exchanged for new tokens only
+	Refresh bool `json:"refresh,omitempty"`
+	// mfa challenge token can be used for mfa step only
+	MFA string `json:"mfa,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// issues and verifies tokens signed by key set
+type Tokens struct {
+	keys         *keyset.KeySet
+	issuer       string
+	audience     string
+	challengeTTL time.Duration
+}
+
+func NewTokens(keys *keyset.KeySet, issuer string, audience string, challengeTTL time.Duration) *Tokens {
+	return &Tokens{keys, issuer, audience, challengeTTL}
+}
+
+// jwt middleware which accepts auth tokens only
+func JWTMiddleware(tokens *Tokens) echo.MiddlewareFunc {
+	return tokens.middleware(false)
+}
+
+// jwt middleware which also accepts mfa enroll challenge, used when enrolment is forced on login
+func JWTEnrollMiddleware(tokens *Tokens) echo.MiddlewareFunc {
+	return tokens.middleware(true)
+}
+
+func (t *Tokens) middleware(allowEnroll bool) echo.MiddlewareFunc {
+	return echojwt.WithConfig(echojwt.Config{
+		ParseTokenFunc: func(ctx echo.Context, auth string) (interface{}, error) {
+			token, claims, err := t.parse(auth)
+			if err != nil {
+				return nil, err
+			}
+			if claims.Refresh {
+				return nil, errors.New("refresh token is not allowed")
+			}
+			if claims.MFA != "" && !(allowEnroll && claims.MFA == domain.MFAChallengeEnroll.String()) {
+				return nil, errors.New("mfa token is not allowed")
+			}
+			return token, nil
+		},
+	})
+}
+
+// public keys of token signing keys
+func (t *Tokens) JWKS(ctx echo.Context) error {
+	// consumers may cache keys for less than key propagation time
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, t.keys.JWKS())
+}
+
+// claims of token verified by jwt middleware
+func contextClaims(ctx echo.Context) (*jwtCustomClaims, bool) {
+	token, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := token.Claims.(*jwtCustomClaims)
+	return claims, ok
+}
+
+// user id of token verified by jwt middleware
+func contextSubject(ctx echo.Context) (uint, bool) {
+	claims, ok := contextClaims(ctx)
+	if !ok {
+		return 0, false
+	}
+	return claims.userID()
+}
+
+// user id encoded in token subject
+func (c *jwtCustomClaims) userID() (uint, bool) {
+	id, err := strconv.ParseUint(c.Subject, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// subject of tokens issued for user
+func userSubject(user *domain.User) string {
+	return strconv.FormatUint(uint64(user.ID), 10)
+}
+
+// sign auth and refresh tokens for subject
+func (t *Tokens) issue(subject string, email string) (*model.UserAuthRes, error) {
+
+	authToken, err := t.sign(&jwtCustomClaims{Email: email}, subject, authTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := t.sign(&jwtCustomClaims{Email: email, Refresh: true}, subject, refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserAuthRes{
+		AuthToken:    authToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// sign short-lived mfa challenge token for subject
+func (t *Tokens) issueChallenge(subject string, email string, challenge domain.MFAChallenge) (*model.UserAuthRes, error) {
+
+	mfaToken, err := t.sign(&jwtCustomClaims{Email: email, MFA: challenge.String()}, subject, t.challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserAuthRes{
+		MFA:      challenge.String(),
+		MFAToken: mfaToken,
+	}, nil
+}
+
+// fill registered claims and sign token with current signing key
+func (t *Tokens) sign(claims *jwtCustomClaims, subject string, ttl time.Duration) (string, error) {
+
+	key := t.keys.Signing()
+	if key == nil {
+		return "", errors.Errorf("%s: no signing key", tokenErrorPrefix)
+	}
+
+	jti := make([]byte, 16)
+	_, err := rand.Read(jti)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: generate token id", tokenErrorPrefix)
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    t.issuer,
+		Audience:  jwt.ClaimStrings{t.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		ID:        hex.EncodeToString(jti),
+	}
+
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+
+	tokenSign, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s: sign token", tokenErrorPrefix)
+	}
+
+	return tokenSign, nil
+}
+
+// verify token signature by kid, issuer, audience and expiration
+func (t *Tokens) parse(auth string) (*jwt.Token, *jwtCustomClaims, error) {
+	claims := new(jwtCustomClaims)
+	token, err := jwt.ParseWithClaims(auth, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := t.keys.Key(kid)
+		if !ok {
+			return nil, errors.Errorf("%s: unknown key %q", tokenErrorPrefix, kid)
+		}
+		// algorithm of token must match algorithm of key
+		if token.Method != key.SigningMethod() {
+			return nil, errors.Errorf("%s: unexpected algorithm %s", tokenErrorPrefix, token.Method.Alg())
+		}
+		return key.Public(), nil
+	},
+		jwt.WithValidMethods([]string{keyset.AlgorithmRS256, keyset.AlgorithmEdDSA}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithAudience(t.audience),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, nil, errors.Errorf("%s: token has no subject or expiration", tokenErrorPrefix)
+	}
+	return token, claims, nil
+}
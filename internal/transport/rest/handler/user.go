@@ -3,50 +3,41 @@ package handler
 import (
 	"context"
 	"net/http"
-	"time"
 
-	"github.com/Je33/imperial_fleet/internal/config"
 	"github.com/Je33/imperial_fleet/internal/domain"
 	"github.com/Je33/imperial_fleet/internal/service"
 	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
 var (
-	// errors prefix
-	userErrorPrefix = "[transport.rest.handler.user]"
-
 	// test interface
 	_ UserService = (*service.UserService)(nil)
 )
 
-// jwt token struct
-type jwtCustomClaims struct {
-	Email string `json:"email"`
-	jwt.RegisteredClaims
-}
-
 //go:generate mockery --dir . --name UserService --output ./mocks
 type UserService interface {
-	Auth(context.Context, *domain.UserAuthReq) error
-	Register(context.Context, *domain.UserRegisterReq) error
+	Auth(context.Context, *domain.UserAuthReq) (*domain.User, error)
+	Register(context.Context, *domain.UserRegisterReq) (*domain.User, error)
+	Refresh(context.Context, uint) (*domain.User, error)
 }
 
 type UserHandler struct {
 	service UserService
+	account AccountService
+	mfa     MFAService
+	tokens  *Tokens
 }
 
-func NewUserHandler(service UserService) *UserHandler {
-	return &UserHandler{service}
+func NewUserHandler(service UserService, account AccountService, mfa MFAService, tokens *Tokens) *UserHandler {
+	return &UserHandler{service, account, mfa, tokens}
 }
 
 func (h *UserHandler) Auth(ctx echo.Context) error {
-	cfg := config.Get()
 
 	restUserAuthReq := new(model.UserAuthReq)
-	err := ctx.Bind(restUserAuthReq)
+	err := bind(ctx, restUserAuthReq)
 	if err != nil {
 		return err
 	}
@@ -56,42 +47,23 @@ func (h *UserHandler) Auth(ctx echo.Context) error {
 		Password: restUserAuthReq.Password,
 	}
 
-	err = h.service.Auth(ctx.Request().Context(), domainUserAuthReq)
+	user, err := h.service.Auth(ctx.Request().Context(), domainUserAuthReq)
 	if err != nil {
 		return err
 	}
 
-	claims := &jwtCustomClaims{
-		restUserAuthReq.Email,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 168)),
-		},
-	}
-
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Generate encoded token
-	tokenSign, err := token.SignedString([]byte(cfg.JWTSecret))
+	restUserAuthRes, err := h.authResponce(ctx.Request().Context(), user)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Implement refresh strategy
-
-	restUserAuthRes := &model.UserAuthRes{
-		AuthToken:    tokenSign,
-		RefreshToken: "",
-	}
-
-	return ctx.JSON(http.StatusOK, restUserAuthRes)
+	return render(ctx, http.StatusOK, restUserAuthRes)
 }
 
 func (h *UserHandler) Register(ctx echo.Context) error {
-	cfg := config.Get()
 
 	restUserAuthReq := new(model.UserRegisterReq)
-	err := ctx.Bind(restUserAuthReq)
+	err := bind(ctx, restUserAuthReq)
 	if err != nil {
 		return err
 	}
@@ -106,33 +78,69 @@ func (h *UserHandler) Register(ctx echo.Context) error {
 		RePassword: restUserAuthReq.RePassword,
 	}
 
-	err = h.service.Register(ctx.Request().Context(), domainUserRegisterReq)
+	user, err := h.service.Register(ctx.Request().Context(), domainUserRegisterReq)
 	if err != nil {
 		return err
 	}
 
-	claims := &jwtCustomClaims{
-		restUserAuthReq.Email,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 168)),
-		},
+	// user is registered anyway, verification email can be resent
+	err = h.account.SendVerification(ctx.Request().Context(), domainUserRegisterReq.Email)
+	if err != nil {
+		ctx.Logger().Error(err)
+	}
+
+	restUserAuthRes, err := h.authResponce(ctx.Request().Context(), user)
+	if err != nil {
+		return err
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return render(ctx, http.StatusOK, restUserAuthRes)
+}
+
+// exchange refresh token for new pair of tokens, user is reloaded so current
+// email and mfa state apply the same way as on login
+func (h *UserHandler) Refresh(ctx echo.Context) error {
 
-	// Generate encoded token
-	tokenSign, err := token.SignedString([]byte(cfg.JWTSecret))
+	restUserRefreshReq := new(model.UserRefreshReq)
+	err := bind(ctx, restUserRefreshReq)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Implement refresh strategy
+	_, claims, err := h.tokens.parse(restUserRefreshReq.RefreshToken)
+	if err != nil || !claims.Refresh {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	userID, ok := claims.userID()
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	user, err := h.service.Refresh(ctx.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	restUserAuthRes, err := h.authResponce(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, restUserAuthRes)
+}
+
+// auth tokens, or mfa challenge when user must pass second factor
+func (h *UserHandler) authResponce(ctx context.Context, user *domain.User) (*model.UserAuthRes, error) {
+
+	challenge, err := h.mfa.Challenge(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
 
-	restUserAuthRes := &model.UserAuthRes{
-		AuthToken:    tokenSign,
-		RefreshToken: "",
+	if challenge == domain.MFAChallengeNone {
+		return h.tokens.issue(userSubject(user), user.Email)
 	}
 
-	return ctx.JSON(http.StatusOK, restUserAuthRes)
+	return h.tokens.issueChallenge(userSubject(user), user.Email, challenge)
 }
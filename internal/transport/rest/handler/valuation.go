@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ ValuationService = (*service.ValuationService)(nil)
+)
+
+//go:generate mockery --dir . --name ValuationService --output ./mocks
+type ValuationService interface {
+	Spaceship(context.Context, uint) (*domain.SpaceshipValuation, error)
+	Fleet(context.Context) (*domain.FleetValuation, error)
+	Appraise(context.Context, uint, *domain.Valuation) (*domain.SpaceshipValuation, error)
+}
+
+type ValuationHandler struct {
+	service ValuationService
+}
+
+func NewValuationHandler(service ValuationService) *ValuationHandler {
+	return &ValuationHandler{service}
+}
+
+// book value of spaceship with history of appraisals
+func (h *ValuationHandler) Spaceship(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	valuation, err := h.service.Spaceship(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.SpaceshipValuationFromDomain(valuation))
+}
+
+// book value of fleet of organization by currency
+func (h *ValuationHandler) Fleet(ctx echo.Context) error {
+
+	fleet, err := h.service.Fleet(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusOK, model.FleetValuationFromDomain(fleet))
+}
+
+// record appraisal of spaceship, responds with its new valuation
+func (h *ValuationHandler) Appraise(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.AppraisalReq)
+	err = bind(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	valuation, err := h.service.Appraise(ctx.Request().Context(), id, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return render(ctx, http.StatusCreated, model.SpaceshipValuationFromDomain(valuation))
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// test interface
+	_ WorkOrderService = (*service.WorkOrderService)(nil)
+)
+
+//go:generate mockery --dir . --name WorkOrderService --output ./mocks
+type WorkOrderService interface {
+	GetAll(context.Context, domain.WorkOrderFilter) ([]*domain.WorkOrder, error)
+	GetById(context.Context, uint) (*domain.WorkOrder, error)
+	Report(context.Context, *domain.WorkOrder) error
+	Update(context.Context, *domain.WorkOrder) (*domain.WorkOrder, error)
+	Move(context.Context, uint, domain.WorkOrderStatus) (*domain.WorkOrder, error)
+	SLABreaches(context.Context) ([]*domain.WorkOrderBreach, error)
+}
+
+type WorkOrderHandler struct {
+	service WorkOrderService
+}
+
+func NewWorkOrderHandler(service WorkOrderService) *WorkOrderHandler {
+	return &WorkOrderHandler{service}
+}
+
+// work orders of fleet, open ones by default, status may be "all" or one of statuses
+func (h *WorkOrderHandler) GetAll(ctx echo.Context) error {
+
+	filter := domain.WorkOrderFilter{Open: true}
+	if spaceship := ctx.QueryParam("spaceship"); spaceship != "" {
+		id, err := strconv.ParseUint(spaceship, 10, 32)
+		if err != nil {
+			return err
+		}
+		filter.SpaceshipID = uint(id)
+	}
+	switch status := ctx.QueryParam("status"); status {
+	case "", "open":
+	case "all":
+		filter.Open = false
+	default:
+		filter.Open = false
+		filter.Status = domain.WorkOrderStatusFromString(status)
+		if filter.Status == domain.WorkOrderStatusUndefined {
+			return domain.ErrWorkOrderStatus
+		}
+	}
+
+	orders, err := h.service.GetAll(ctx.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	res := model.WorkOrdersResponce{Data: make([]model.WorkOrder, 0, len(orders))}
+	for _, o := range orders {
+		res.Data = append(res.Data, model.WorkOrderFromDomain(o))
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func (h *WorkOrderHandler) GetById(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	order, err := h.service.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.WorkOrderFromDomain(order))
+}
+
+func (h *WorkOrderHandler) Report(ctx echo.Context) error {
+
+	req := new(model.WorkOrderReportReq)
+	err := ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	order := req.ToDomain()
+	err = h.service.Report(ctx.Request().Context(), order)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, model.WorkOrderFromDomain(order))
+}
+
+func (h *WorkOrderHandler) Update(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.WorkOrderUpdateReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.service.Update(ctx.Request().Context(), req.ToDomain(id))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.WorkOrderFromDomain(order))
+}
+
+func (h *WorkOrderHandler) Move(ctx echo.Context) error {
+
+	id, err := paramID(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(model.WorkOrderStatusReq)
+	err = ctx.Bind(req)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.service.Move(ctx.Request().Context(), id, domain.WorkOrderStatusFromString(req.Status))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, model.WorkOrderFromDomain(order))
+}
+
+// work orders which breach sla, with counts by severity
+func (h *WorkOrderHandler) SLABreaches(ctx echo.Context) error {
+
+	breaches, err := h.service.SLABreaches(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res := model.WorkOrderSLAResponce{
+		Data:     make([]model.WorkOrderBreach, 0, len(breaches)),
+		Severity: map[string]int{},
+	}
+	for _, b := range breaches {
+		res.Data = append(res.Data, model.WorkOrderBreachFromDomain(b))
+		res.Severity[b.WorkOrder.Severity.String()]++
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
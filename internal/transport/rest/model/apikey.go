@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type APIKey struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyCreateReq struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// created key with secret which is shown once
+type APIKeyCreateRes struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// convert domain api key to rest model
+func APIKeyFromDomain(key *domain.APIKey) APIKey {
+	return APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  unixTime(key.ExpiresAt),
+		LastUsedAt: unixTime(key.LastUsedAt),
+		CreatedAt:  time.Unix(key.CreatedAt, 0).UTC(),
+	}
+}
+
+// convert rest create request to domain
+func (r *APIKeyCreateReq) ToDomain() *domain.APIKeyCreateReq {
+	req := &domain.APIKeyCreateReq{
+		Name:   r.Name,
+		Scopes: r.Scopes,
+	}
+	if r.ExpiresAt != nil {
+		req.ExpiresAt = r.ExpiresAt.Unix()
+	}
+	return req
+}
+
+// nil for zero unix time
+func unixTime(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
@@ -7,3 +7,75 @@ type PostResponce struct {
 type SpaceshipsResponce struct {
 	Data []SpaceshipShort `json:"data"`
 }
+
+type ErrorResponce struct {
+	Message string `json:"message"`
+}
+
+type APIKeysResponce struct {
+	Data []APIKey `json:"data"`
+}
+
+type OrganizationsResponce struct {
+	Data []Organization `json:"data"`
+}
+
+type MembersResponce struct {
+	Data []Member `json:"data"`
+}
+
+type ServiceAccountsResponce struct {
+	Data []ServiceAccount `json:"data"`
+}
+
+type SearchResponce struct {
+	Data []SearchHit `json:"data"`
+	// count of all matches, data is limited
+	Total int `json:"total"`
+}
+
+type CrewResponce struct {
+	Data []CrewMember `json:"data"`
+}
+
+type WorkOrdersResponce struct {
+	Data []WorkOrder `json:"data"`
+}
+
+type WorkOrderSLAResponce struct {
+	Data []WorkOrderBreach `json:"data"`
+	// count of breaches by severity
+	Severity map[string]int `json:"severity"`
+}
+
+type ShipClassesResponce struct {
+	Data []ShipClass `json:"data"`
+}
+
+type FleetReportResponce struct {
+	Data []FleetReportRow `json:"data"`
+}
+
+type ArmamentReportResponce struct {
+	Data []ArmamentReportRow `json:"data"`
+}
+
+type ReadinessReportResponce struct {
+	Data []ReadinessReportRow `json:"data"`
+	// readiness of all counted spaceships
+	Percent float64 `json:"percent"`
+}
+
+type TrendsReportResponce struct {
+	Data []FleetSnapshot `json:"data"`
+}
+
+type SpaceshipBatchResponce struct {
+	Data []SpaceshipOperationResult `json:"data"`
+	// error of failed operation of atomic batch
+	Message string `json:"message,omitempty"`
+}
+
+type MissionsResponce struct {
+	Data []Mission `json:"data"`
+}
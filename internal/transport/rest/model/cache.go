@@ -0,0 +1,22 @@
+package model
+
+import "github.com/Je33/imperial_fleet/internal/domain"
+
+type CacheStats struct {
+	Enabled bool  `json:"enabled"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	// misses collapsed into load of another request
+	Shared  int64   `json:"shared"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+func CacheStatsFromDomain(stats domain.CacheStats) CacheStats {
+	return CacheStats{
+		Enabled: stats.Enabled,
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+		Shared:  stats.Shared,
+		HitRate: stats.HitRate(),
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type CrewMember struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Rank          string `json:"rank"`
+	Specialty     string `json:"specialty"`
+	ServiceNumber string `json:"service_number"`
+	// zero if crew member is not assigned
+	SpaceshipID  uint       `json:"spaceship_id"`
+	EnlistedAt   time.Time  `json:"enlisted_at"`
+	DischargedAt *time.Time `json:"discharged_at,omitempty"`
+	// filled for single crew member
+	Assignments []CrewAssignment `json:"assignments,omitempty"`
+}
+
+type CrewAssignment struct {
+	SpaceshipID uint       `json:"spaceship_id"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+}
+
+type CrewEnlistReq struct {
+	Name          string `json:"name"`
+	Rank          string `json:"rank"`
+	Specialty     string `json:"specialty"`
+	ServiceNumber string `json:"service_number"`
+	// optional spaceship of first assignment
+	SpaceshipID uint `json:"spaceship_id"`
+}
+
+type CrewTransferReq struct {
+	SpaceshipID uint `json:"spaceship_id"`
+}
+
+func CrewMemberFromDomain(member *domain.CrewMember) CrewMember {
+	return CrewMember{
+		ID:            member.ID,
+		Name:          member.Name,
+		Rank:          member.Rank.String(),
+		Specialty:     member.Specialty,
+		ServiceNumber: member.ServiceNumber,
+		SpaceshipID:   member.SpaceshipID,
+		EnlistedAt:    time.Unix(member.EnlistedAt, 0).UTC(),
+		DischargedAt:  unixTime(member.DischargedAt),
+	}
+}
+
+func CrewAssignmentFromDomain(assignment *domain.CrewAssignment) CrewAssignment {
+	return CrewAssignment{
+		SpaceshipID: assignment.SpaceshipID,
+		StartedAt:   time.Unix(assignment.StartedAt, 0).UTC(),
+		EndedAt:     unixTime(assignment.EndedAt),
+	}
+}
+
+func (r *CrewEnlistReq) ToDomain() *domain.CrewMember {
+	return &domain.CrewMember{
+		Name:          r.Name,
+		Rank:          domain.CrewRankFromString(r.Rank),
+		Specialty:     r.Specialty,
+		ServiceNumber: r.ServiceNumber,
+		SpaceshipID:   r.SpaceshipID,
+	}
+}
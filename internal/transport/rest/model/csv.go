@@ -0,0 +1,140 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// responce which can be written as csv, the first row is header
+type CSVTable interface {
+	CSVRows() [][]string
+}
+
+// request which can be read from csv header and record
+type CSVRecord interface {
+	FromCSV(header []string, record []string) error
+}
+
+var spaceshipCSVHeader = []string{"id", "name", "class", "class_id", "crew", "image", "value", "currency", "status", "armament"}
+
+func (r SpaceshipsResponce) CSVRows() [][]string {
+	rows := [][]string{{"id", "name", "status"}}
+	for _, s := range r.Data {
+		rows = append(rows, []string{formatInt(int64(s.ID)), s.Name, s.Status})
+	}
+	return rows
+}
+
+func (s SpaceshipFull) CSVRows() [][]string {
+	return [][]string{spaceshipCSVHeader, s.csvRecord()}
+}
+
+func (s SpaceshipFull) csvRecord() []string {
+	return []string{formatInt(int64(s.ID)), s.Name, s.Class, formatInt(int64(s.ClassID)), formatInt(int64(s.Crew)),
+		s.Image, s.Value.String(), s.Currency, s.Status, formatArmament(s.Armament)}
+}
+
+// columns of spaceship csv, absent columns are left empty
+func (s *SpaceshipFull) FromCSV(header []string, record []string) error {
+	for i, column := range header {
+		value := record[i]
+		var err error
+		switch column {
+		case "id":
+			s.ID, err = parseUint(value)
+		case "name":
+			s.Name = value
+		case "class":
+			s.Class = value
+		case "class_id":
+			s.ClassID, err = parseUint(value)
+		case "crew":
+			s.Crew, err = parseUint(value)
+		case "image":
+			s.Image = value
+		case "value":
+			if value != "" {
+				s.Value, err = domain.ParseDecimal(value)
+			}
+		case "currency":
+			s.Currency = value
+		case "status":
+			s.Status = value
+		case "armament":
+			s.Armament, err = parseArmament(value)
+		default:
+			return errors.Wrapf(domain.ErrConversion, "unknown csv column %q", column)
+		}
+		if err != nil {
+			return errors.Wrapf(domain.ErrConversion, "csv column %q: %s", column, err)
+		}
+	}
+	return nil
+}
+
+func (r SpaceshipBatchResponce) CSVRows() [][]string {
+	rows := [][]string{{"status", "id", "name", "error"}}
+	for _, result := range r.Data {
+		row := []string{formatInt(int64(result.Status)), "", "", ""}
+		if result.Data != nil {
+			row[1], row[2] = formatInt(int64(result.Data.ID)), result.Data.Name
+		}
+		if result.Error != nil {
+			row[3] = result.Error.Message
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r PostResponce) CSVRows() [][]string {
+	return [][]string{{"success"}, {strconv.FormatBool(r.Success)}}
+}
+
+func (r ErrorResponce) CSVRows() [][]string {
+	return [][]string{{"message"}, {r.Message}}
+}
+
+func (r UserAuthRes) CSVRows() [][]string {
+	return [][]string{{"auth_token", "refresh_token", "mfa", "mfa_token"}, {r.AuthToken, r.RefreshToken, r.MFA, r.MFAToken}}
+}
+
+// armament in one column as title:qty pairs separated by semicolons
+func formatArmament(armament []SpaceshipArmament) string {
+	parts := make([]string, 0, len(armament))
+	for _, a := range armament {
+		parts = append(parts, a.Title+":"+formatInt(int64(a.Qty)))
+	}
+	return strings.Join(parts, ";")
+}
+
+func parseArmament(value string) ([]SpaceshipArmament, error) {
+	armament := []SpaceshipArmament{}
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		// title may contain colons, qty is after the last one
+		i := strings.LastIndex(part, ":")
+		if i < 0 {
+			return nil, errors.Errorf("armament %q has no qty", part)
+		}
+		qty, err := parseUint(part[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		armament = append(armament, SpaceshipArmament{Title: strings.TrimSpace(part[:i]), Qty: qty})
+	}
+	return armament, nil
+}
+
+func parseUint(value string) (uint, error) {
+	if value == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+	return uint(v), err
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type MissionRequirements struct {
+	MinFirepower float64 `json:"min_firepower"`
+	MinCrew      uint    `json:"min_crew"`
+}
+
+type Mission struct {
+	ID           uint                `json:"id"`
+	Objective    string              `json:"objective"`
+	Status       string              `json:"status"`
+	StartsAt     time.Time           `json:"starts_at"`
+	EndsAt       time.Time           `json:"ends_at"`
+	Spaceships   []uint              `json:"spaceships"`
+	Requirements MissionRequirements `json:"requirements"`
+	CreatedAt    time.Time           `json:"created_at"`
+	StartedAt    *time.Time          `json:"started_at,omitempty"`
+	ClosedAt     *time.Time          `json:"closed_at,omitempty"`
+}
+
+type MissionReq struct {
+	Objective string    `json:"objective"`
+	StartsAt  time.Time `json:"starts_at"`
+	// end of window is exclusive
+	EndsAt       time.Time           `json:"ends_at"`
+	Spaceships   []uint              `json:"spaceships"`
+	Requirements MissionRequirements `json:"requirements"`
+}
+
+type MissionStatusReq struct {
+	Status string `json:"status"`
+}
+
+func MissionFromDomain(mission *domain.Mission) Mission {
+	spaceships := mission.SpaceshipIDs
+	if spaceships == nil {
+		spaceships = []uint{}
+	}
+	return Mission{
+		ID:         mission.ID,
+		Objective:  mission.Objective,
+		Status:     mission.Status.String(),
+		StartsAt:   time.Unix(mission.StartsAt, 0).UTC(),
+		EndsAt:     time.Unix(mission.EndsAt, 0).UTC(),
+		Spaceships: spaceships,
+		Requirements: MissionRequirements{
+			MinFirepower: mission.Requirements.MinFirepower,
+			MinCrew:      mission.Requirements.MinCrew,
+		},
+		CreatedAt: time.Unix(mission.CreatedAt, 0).UTC(),
+		StartedAt: unixTime(mission.StartedAt),
+		ClosedAt:  unixTime(mission.ClosedAt),
+	}
+}
+
+func (r *MissionReq) ToDomain(id uint) *domain.Mission {
+	return &domain.Mission{
+		ID:           id,
+		Objective:    r.Objective,
+		StartsAt:     timeUnix(r.StartsAt),
+		EndsAt:       timeUnix(r.EndsAt),
+		SpaceshipIDs: r.Spaceships,
+		Requirements: domain.MissionRequirements{
+			MinFirepower: r.Requirements.MinFirepower,
+			MinCrew:      r.Requirements.MinCrew,
+		},
+	}
+}
+
+// unix seconds of time, zero time is absent
+func timeUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
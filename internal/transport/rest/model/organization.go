@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// organization with role of current user
+type Organization struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type OrganizationCreateReq struct {
+	Name string `json:"name"`
+}
+
+type Member struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// add member or change role of member
+type MemberReq struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// convert domain membership to organization of member
+func OrganizationFromDomain(membership *domain.Membership) Organization {
+	return Organization{
+		ID:   membership.OrgID,
+		Name: membership.OrgName,
+		Role: strings.ToLower(membership.Role.String()),
+	}
+}
+
+// convert domain membership to member of organization
+func MemberFromDomain(membership *domain.Membership) Member {
+	return Member{
+		UserID:    membership.UserID,
+		Email:     membership.UserEmail,
+		Role:      strings.ToLower(membership.Role.String()),
+		CreatedAt: time.Unix(membership.CreatedAt, 0).UTC(),
+	}
+}
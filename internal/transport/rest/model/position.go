@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// point of galactic map in parsecs from galactic core
+type Coordinates struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type Position struct {
+	Sector      string       `json:"sector"`
+	System      string       `json:"system,omitempty"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	ReportedAt  time.Time    `json:"reported_at"`
+}
+
+type PositionReportReq struct {
+	Sector      string       `json:"sector"`
+	System      string       `json:"system"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	// now if empty
+	ReportedAt *time.Time `json:"reported_at,omitempty"`
+}
+
+type PositionsResponce struct {
+	// from the newest
+	Data []Position `json:"data"`
+}
+
+type ProximityHit struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	Class    string   `json:"class"`
+	Status   string   `json:"status"`
+	Distance float64  `json:"distance"`
+	Position Position `json:"position"`
+}
+
+type ProximityResponce struct {
+	// from the nearest
+	Data []ProximityHit `json:"data"`
+	// count of all spaceships within radius, data is limited
+	Total int `json:"total"`
+}
+
+func PositionFromDomain(p *domain.Position) Position {
+	res := Position{
+		Sector:     p.Sector,
+		System:     p.System,
+		ReportedAt: time.Unix(p.ReportedAt, 0).UTC(),
+	}
+	if c := p.Coordinates; c != nil {
+		res.Coordinates = &Coordinates{X: c.X, Y: c.Y, Z: c.Z}
+	}
+	return res
+}
+
+func ProximityHitFromDomain(hit *domain.ProximityHit) ProximityHit {
+	res := ProximityHit{
+		ID:       hit.Spaceship.ID,
+		Name:     hit.Spaceship.Name,
+		Class:    hit.Spaceship.Class,
+		Status:   hit.Spaceship.Status.String(),
+		Distance: hit.Distance,
+	}
+	if hit.Spaceship.Position != nil {
+		res.Position = PositionFromDomain(hit.Spaceship.Position)
+	}
+	return res
+}
+
+func (r *PositionReportReq) ToDomain() *domain.Position {
+	p := &domain.Position{
+		Sector: r.Sector,
+		System: r.System,
+	}
+	if c := r.Coordinates; c != nil {
+		p.Coordinates = &domain.Coordinates{X: c.X, Y: c.Y, Z: c.Z}
+	}
+	if r.ReportedAt != nil {
+		p.ReportedAt = r.ReportedAt.Unix()
+	}
+	return p
+}
@@ -0,0 +1,69 @@
+package model
+
+import "github.com/Je33/imperial_fleet/internal/domain"
+
+type ReadinessFactor struct {
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Score       float64 `json:"score"`
+	Points      float64 `json:"points"`
+	Explanation string  `json:"explanation"`
+}
+
+type Readiness struct {
+	SpaceshipID uint              `json:"spaceship_id"`
+	Score       float64           `json:"score"`
+	Level       string            `json:"level"`
+	Factors     []ReadinessFactor `json:"factors"`
+}
+
+type ClassReadiness struct {
+	Class      string  `json:"class"`
+	Spaceships int     `json:"spaceships"`
+	Score      float64 `json:"score"`
+}
+
+type FleetReadiness struct {
+	Spaceships int              `json:"spaceships"`
+	Score      float64          `json:"score"`
+	Levels     map[string]int   `json:"levels"`
+	Classes    []ClassReadiness `json:"classes"`
+	Weakest    *Readiness       `json:"weakest,omitempty"`
+}
+
+func ReadinessFromDomain(r *domain.Readiness) Readiness {
+	factors := make([]ReadinessFactor, 0, len(r.Factors))
+	for _, f := range r.Factors {
+		factors = append(factors, ReadinessFactor{
+			Name:        f.Name,
+			Weight:      f.Weight,
+			Score:       f.Score,
+			Points:      f.Points,
+			Explanation: f.Explanation,
+		})
+	}
+	return Readiness{
+		SpaceshipID: r.SpaceshipID,
+		Score:       r.Score,
+		Level:       r.Level,
+		Factors:     factors,
+	}
+}
+
+func FleetReadinessFromDomain(fleet *domain.FleetReadiness) FleetReadiness {
+	classes := make([]ClassReadiness, 0, len(fleet.Classes))
+	for _, c := range fleet.Classes {
+		classes = append(classes, ClassReadiness{Class: c.Class, Spaceships: c.Spaceships, Score: c.Score})
+	}
+	res := FleetReadiness{
+		Spaceships: fleet.Spaceships,
+		Score:      fleet.Score,
+		Levels:     fleet.Levels,
+		Classes:    classes,
+	}
+	if fleet.Weakest != nil {
+		weakest := ReadinessFromDomain(fleet.Weakest)
+		res.Weakest = &weakest
+	}
+	return res
+}
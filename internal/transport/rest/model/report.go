@@ -0,0 +1,139 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type FleetReportRow struct {
+	Status   string         `json:"status,omitempty"`
+	Class    string         `json:"class,omitempty"`
+	Currency string         `json:"currency"`
+	Count    int64          `json:"count"`
+	Value    domain.Decimal `json:"value"`
+	Crew     int64          `json:"crew"`
+}
+
+type ArmamentReportRow struct {
+	Title      string `json:"title"`
+	Class      string `json:"class,omitempty"`
+	Qty        int64  `json:"qty"`
+	Spaceships int64  `json:"spaceships"`
+}
+
+type ReadinessReportRow struct {
+	Class       string  `json:"class,omitempty"`
+	Total       int64   `json:"total"`
+	Operational int64   `json:"operational"`
+	Percent     float64 `json:"percent"`
+}
+
+type FleetSnapshot struct {
+	Day         time.Time      `json:"day"`
+	Spaceships  int64          `json:"spaceships"`
+	Operational int64          `json:"operational"`
+	Damaged     int64          `json:"damaged"`
+	Value       domain.Decimal `json:"value"`
+	Crew        int64          `json:"crew"`
+	Readiness   float64        `json:"readiness"`
+	TakenAt     time.Time      `json:"taken_at"`
+}
+
+func FleetReportRowFromDomain(row *domain.FleetReportRow) FleetReportRow {
+	res := FleetReportRow{
+		Class:    row.Class,
+		Currency: row.Currency,
+		Count:    row.Count,
+		Value:    row.Value,
+		Crew:     row.Crew,
+	}
+	if row.Status != domain.SpaceshipStatusUndefined {
+		res.Status = row.Status.String()
+	}
+	return res
+}
+
+func ArmamentReportRowFromDomain(row *domain.ArmamentReportRow) ArmamentReportRow {
+	return ArmamentReportRow{
+		Title:      row.Title,
+		Class:      row.Class,
+		Qty:        row.Qty,
+		Spaceships: row.Spaceships,
+	}
+}
+
+func ReadinessReportRowFromDomain(row *domain.ReadinessReportRow) ReadinessReportRow {
+	return ReadinessReportRow{
+		Class:       row.Class,
+		Total:       row.Total,
+		Operational: row.Operational,
+		Percent:     row.Percent(),
+	}
+}
+
+func FleetSnapshotFromDomain(snapshot *domain.FleetSnapshot) FleetSnapshot {
+	return FleetSnapshot{
+		Day:         time.Unix(snapshot.Day, 0).UTC(),
+		Spaceships:  snapshot.Spaceships,
+		Operational: snapshot.Operational,
+		Damaged:     snapshot.Damaged,
+		Value:       snapshot.Value,
+		Crew:        snapshot.Crew,
+		Readiness:   snapshot.Readiness(),
+		TakenAt:     time.Unix(snapshot.TakenAt, 0).UTC(),
+	}
+}
+
+// csv columns of report rows, grouping columns are present only if report is grouped by them
+
+func (r FleetReportRow) CSV(q domain.ReportQuery) ([]string, []string) {
+	header, record := groupColumns(q, map[string]string{
+		domain.ReportGroupStatus: r.Status,
+		domain.ReportGroupClass:  r.Class,
+	})
+	return append(header, "currency", "count", "value", "crew"),
+		append(record, r.Currency, formatInt(r.Count), r.Value.String(), formatInt(r.Crew))
+}
+
+func (r ArmamentReportRow) CSV(q domain.ReportQuery) ([]string, []string) {
+	header, record := groupColumns(q, map[string]string{
+		domain.ReportGroupArmament: r.Title,
+		domain.ReportGroupClass:    r.Class,
+	})
+	return append(header, "qty", "spaceships"),
+		append(record, formatInt(r.Qty), formatInt(r.Spaceships))
+}
+
+func (r ReadinessReportRow) CSV(q domain.ReportQuery) ([]string, []string) {
+	header, record := groupColumns(q, map[string]string{
+		domain.ReportGroupClass: r.Class,
+	})
+	return append(header, "total", "operational", "percent"),
+		append(record, formatInt(r.Total), formatInt(r.Operational), formatFloat(r.Percent))
+}
+
+func (s FleetSnapshot) CSV(domain.ReportQuery) ([]string, []string) {
+	return []string{"day", "spaceships", "operational", "damaged", "value", "crew", "readiness"},
+		[]string{s.Day.Format("2006-01-02"), formatInt(s.Spaceships), formatInt(s.Operational),
+			formatInt(s.Damaged), s.Value.String(), formatInt(s.Crew), formatFloat(s.Readiness)}
+}
+
+func groupColumns(q domain.ReportQuery, values map[string]string) ([]string, []string) {
+	header := []string{}
+	record := []string{}
+	for _, g := range q.Group {
+		header = append(header, g)
+		record = append(record, values[g])
+	}
+	return header, record
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
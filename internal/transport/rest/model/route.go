@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type RouteReq struct {
+	// name of star system
+	Destination string `json:"destination"`
+	// shortest or fastest, fastest if empty
+	Mode string `json:"mode"`
+}
+
+type RouteJump struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Distance float64 `json:"distance"`
+	Hours    float64 `json:"hours"`
+}
+
+type Route struct {
+	SpaceshipID uint   `json:"spaceship_id"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Mode        string `json:"mode"`
+	// hyperdrive rating of spaceship class
+	Hyperdrive float64     `json:"hyperdrive"`
+	Distance   float64     `json:"distance"`
+	Hours      float64     `json:"hours"`
+	Jumps      []RouteJump `json:"jumps"`
+}
+
+type StarSystem struct {
+	Name        string      `json:"name"`
+	Sector      string      `json:"sector"`
+	Coordinates Coordinates `json:"coordinates"`
+}
+
+type Hyperlane struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Length float64 `json:"length"`
+	Speed  float64 `json:"speed"`
+	Closed bool    `json:"closed"`
+}
+
+type HyperlaneClosure struct {
+	ID     uint   `json:"id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+	// absent until lane is opened
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type HyperlaneClosureReq struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+	// closed until lane is opened if empty
+	Until *time.Time `json:"until,omitempty"`
+}
+
+type Galaxy struct {
+	JumpHours float64 `json:"jump_hours"`
+	// absent for galaxy of data file
+	UploadedAt *time.Time         `json:"uploaded_at,omitempty"`
+	Systems    []StarSystem       `json:"systems"`
+	Lanes      []Hyperlane        `json:"lanes"`
+	Closures   []HyperlaneClosure `json:"closures"`
+}
+
+func (r *RouteReq) ToDomain() domain.RouteMode {
+	if r.Mode == "" {
+		return domain.RouteModeFastest
+	}
+	return domain.RouteModeFromString(r.Mode)
+}
+
+func RouteFromDomain(route *domain.Route) Route {
+	jumps := make([]RouteJump, 0, len(route.Jumps))
+	for _, j := range route.Jumps {
+		jumps = append(jumps, RouteJump{From: j.From, To: j.To, Distance: j.Distance, Hours: j.Hours})
+	}
+	return Route{
+		SpaceshipID: route.SpaceshipID,
+		From:        route.From,
+		To:          route.To,
+		Mode:        route.Mode.String(),
+		Hyperdrive:  route.Rating,
+		Distance:    route.Distance,
+		Hours:       route.Hours,
+		Jumps:       jumps,
+	}
+}
+
+func HyperlaneClosureFromDomain(c *domain.HyperlaneClosure) HyperlaneClosure {
+	res := HyperlaneClosure{
+		ID:        c.ID,
+		From:      c.From,
+		To:        c.To,
+		Reason:    c.Reason,
+		CreatedAt: time.Unix(c.CreatedAt, 0).UTC(),
+	}
+	if c.Until != 0 {
+		until := time.Unix(c.Until, 0).UTC()
+		res.Until = &until
+	}
+	return res
+}
+
+func GalaxyFromDomain(galaxy *domain.Galaxy, closures []*domain.HyperlaneClosure) Galaxy {
+	res := Galaxy{
+		JumpHours: galaxy.JumpHours,
+		Systems:   make([]StarSystem, 0, len(galaxy.Systems)),
+		Lanes:     make([]Hyperlane, 0, len(galaxy.Lanes)),
+		Closures:  make([]HyperlaneClosure, 0, len(closures)),
+	}
+	if galaxy.UploadedAt != 0 {
+		uploadedAt := time.Unix(galaxy.UploadedAt, 0).UTC()
+		res.UploadedAt = &uploadedAt
+	}
+	for _, s := range galaxy.Systems {
+		res.Systems = append(res.Systems, StarSystem{
+			Name:        s.Name,
+			Sector:      s.Sector,
+			Coordinates: Coordinates{X: s.Coordinates.X, Y: s.Coordinates.Y, Z: s.Coordinates.Z},
+		})
+	}
+	for _, l := range galaxy.Lanes {
+		res.Lanes = append(res.Lanes, Hyperlane{From: l.From, To: l.To, Length: l.Length, Speed: l.Speed, Closed: l.Closed})
+	}
+	for _, c := range closures {
+		res.Closures = append(res.Closures, HyperlaneClosureFromDomain(c))
+	}
+	return res
+}
+
+func (r *HyperlaneClosureReq) ToDomain() *domain.HyperlaneClosure {
+	c := &domain.HyperlaneClosure{From: r.From, To: r.To, Reason: r.Reason}
+	if r.Until != nil {
+		c.Until = r.Until.Unix()
+	}
+	return c
+}
@@ -0,0 +1,28 @@
+package model
+
+import "github.com/Je33/imperial_fleet/internal/domain"
+
+type SearchHit struct {
+	ID     uint    `json:"id"`
+	Name   string  `json:"name"`
+	Class  string  `json:"class"`
+	Status string  `json:"status"`
+	Score  float64 `json:"score"`
+	// matched values by field, matched words are wrapped in <em>
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+type SearchReindexRes struct {
+	Spaceships int `json:"spaceships"`
+}
+
+func SearchHitFromDomain(hit *domain.SearchHit) SearchHit {
+	return SearchHit{
+		ID:         hit.Spaceship.ID,
+		Name:       hit.Spaceship.Name,
+		Class:      hit.Spaceship.Class,
+		Status:     hit.Spaceship.Status.String(),
+		Score:      hit.Score,
+		Highlights: hit.Highlights,
+	}
+}
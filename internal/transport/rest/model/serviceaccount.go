@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+// service account with role in its organization
+type ServiceAccount struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	CreatedBy uint       `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ServiceAccountCreateReq struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// convert domain service account to rest model
+func ServiceAccountFromDomain(account *domain.ServiceAccount) ServiceAccount {
+	return ServiceAccount{
+		ID:        account.ID,
+		Name:      account.Name,
+		Role:      strings.ToLower(account.Role.String()),
+		CreatedBy: account.CreatedBy,
+		CreatedAt: time.Unix(account.CreatedAt, 0).UTC(),
+		RevokedAt: unixTime(account.RevokedAt),
+	}
+}
+
+// convert rest create request to domain
+func (r *ServiceAccountCreateReq) ToDomain() *domain.ServiceAccountCreateReq {
+	return &domain.ServiceAccountCreateReq{
+		Name: r.Name,
+		Role: domain.OrgRoleFromString(r.Role),
+	}
+}
@@ -0,0 +1,96 @@
+package model
+
+import "github.com/Je33/imperial_fleet/internal/domain"
+
+type ShipClassArmament struct {
+	Title string `json:"title"`
+	// qty of default loadout
+	Qty uint `json:"qty"`
+	Max uint `json:"max"`
+	// damage rating of one unit
+	Damage float64 `json:"damage"`
+}
+
+// depreciation schedule of class, shares are from 0 to 1
+type Depreciation struct {
+	// none, straight_line or declining_balance
+	Method string `json:"method"`
+	// share of value lost per year
+	Rate domain.Decimal `json:"rate"`
+	// share of appraised value which is never depreciated
+	Salvage domain.Decimal `json:"salvage"`
+	// share of book value lost while spaceship is damaged
+	Damage domain.Decimal `json:"damage"`
+}
+
+type ShipClass struct {
+	ID        uint                `json:"id"`
+	Name      string              `json:"name"`
+	Aliases   []string            `json:"aliases"`
+	Armament  []ShipClassArmament `json:"armament"`
+	MinCrew   uint                `json:"min_crew"`
+	MaxCrew   uint                `json:"max_crew"`
+	BaseValue float64             `json:"base_value"`
+	// no depreciation if absent
+	Depreciation *Depreciation `json:"depreciation,omitempty"`
+	// rating of hyperdrive, lower is faster, default rating if zero
+	Hyperdrive float64 `json:"hyperdrive"`
+}
+
+type ShipClassNormalizeRes struct {
+	// count of spaceships which class is changed
+	Spaceships int64 `json:"spaceships"`
+}
+
+func ShipClassFromDomain(class *domain.ShipClass) ShipClass {
+	armament := make([]ShipClassArmament, 0, len(class.Armament))
+	for _, a := range class.Armament {
+		armament = append(armament, ShipClassArmament{Title: a.Title, Qty: a.Qty, Max: a.Max, Damage: a.Damage})
+	}
+	aliases := class.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return ShipClass{
+		ID:        class.ID,
+		Name:      class.Name,
+		Aliases:   aliases,
+		Armament:  armament,
+		MinCrew:   class.MinCrew,
+		MaxCrew:   class.MaxCrew,
+		BaseValue: class.BaseValue,
+		Depreciation: &Depreciation{
+			Method:  class.Depreciation.Method.String(),
+			Rate:    class.Depreciation.Rate,
+			Salvage: class.Depreciation.Salvage,
+			Damage:  class.Depreciation.Damage,
+		},
+		Hyperdrive: class.Hyperdrive,
+	}
+}
+
+func (c *ShipClass) ToDomain() *domain.ShipClass {
+	armament := make([]domain.ShipClassArmament, 0, len(c.Armament))
+	for _, a := range c.Armament {
+		armament = append(armament, domain.ShipClassArmament{Title: a.Title, Qty: a.Qty, Max: a.Max, Damage: a.Damage})
+	}
+	class := &domain.ShipClass{
+		ID:         c.ID,
+		Name:       c.Name,
+		Aliases:    c.Aliases,
+		Armament:   armament,
+		MinCrew:    c.MinCrew,
+		MaxCrew:    c.MaxCrew,
+		BaseValue:  c.BaseValue,
+		Hyperdrive: c.Hyperdrive,
+	}
+	if c.Depreciation != nil {
+		class.Depreciation = domain.Depreciation{
+			Method:  domain.DepreciationMethodFromString(c.Depreciation.Method),
+			Rate:    c.Depreciation.Rate,
+			Salvage: c.Depreciation.Salvage,
+			Damage:  c.Depreciation.Damage,
+		}
+	}
+	return class
+}
@@ -1,5 +1,7 @@
 package model
 
+import "github.com/Je33/imperial_fleet/internal/domain"
+
 type SpaceshipArmament struct {
 	Title string `json:"title"`
 	Qty   uint   `json:"qty,string"`
@@ -12,12 +14,71 @@ type SpaceshipShort struct {
 }
 
 type SpaceshipFull struct {
-	ID       uint                `json:"id"`
-	Name     string              `json:"name"`
-	Class    string              `json:"class"`
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Class string `json:"class"`
+	// class of catalog, defaults of class are used on create for empty fields
+	ClassID  uint                `json:"class_id,omitempty"`
 	Armament []SpaceshipArmament `json:"armament"`
 	Crew     uint                `json:"crew"`
 	Image    string              `json:"image"`
-	Value    float64             `json:"value"`
-	Status   string              `json:"status"`
+	Value    domain.Decimal      `json:"value"`
+	// currency of value, default currency if empty
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+	// the latest reported position, read only
+	Position *Position `json:"position,omitempty"`
+}
+
+// convert domain spaceship to full rest model
+func SpaceshipFullFromDomain(spaceship *domain.Spaceship) SpaceshipFull {
+	modelSpaceshipArmament := make([]SpaceshipArmament, 0, len(spaceship.Armament))
+	for _, a := range spaceship.Armament {
+		modelSpaceshipArmament = append(modelSpaceshipArmament, SpaceshipArmament{
+			Title: a.Title,
+			Qty:   a.Qty,
+		})
+	}
+
+	res := SpaceshipFull{
+		ID:       spaceship.ID,
+		Name:     spaceship.Name,
+		Class:    spaceship.Class,
+		ClassID:  spaceship.ClassID,
+		Crew:     spaceship.Crew,
+		Image:    spaceship.Image,
+		Value:    spaceship.Value,
+		Currency: spaceship.Currency,
+		Status:   spaceship.Status.String(),
+		Armament: modelSpaceshipArmament,
+	}
+	if spaceship.Position != nil {
+		position := PositionFromDomain(spaceship.Position)
+		res.Position = &position
+	}
+	return res
+}
+
+// convert full rest model to domain spaceship
+func (s *SpaceshipFull) ToDomain() *domain.Spaceship {
+	domainSpaceshipArmament := make([]domain.SpaceshipArmament, 0, len(s.Armament))
+	for _, a := range s.Armament {
+		domainSpaceshipArmament = append(domainSpaceshipArmament, domain.SpaceshipArmament{
+			Title: a.Title,
+			Qty:   a.Qty,
+		})
+	}
+
+	return &domain.Spaceship{
+		ID:       s.ID,
+		Name:     s.Name,
+		Class:    s.Class,
+		ClassID:  s.ClassID,
+		Crew:     s.Crew,
+		Status:   domain.SpaceshipStatusFromString(s.Status),
+		Image:    s.Image,
+		Value:    s.Value,
+		Currency: s.Currency,
+		Armament: domainSpaceshipArmament,
+	}
 }
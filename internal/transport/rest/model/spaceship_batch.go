@@ -0,0 +1,39 @@
+package model
+
+import "github.com/Je33/imperial_fleet/internal/domain"
+
+// batch of spaceship operations, in atomic mode all operations are committed or none of them
+type SpaceshipBatch struct {
+	Atomic     bool                 `json:"atomic"`
+	Operations []SpaceshipOperation `json:"operations"`
+}
+
+// operation of batch, op is create, update, delete or status,
+// spaceship is set for create and update, status is set for status change
+type SpaceshipOperation struct {
+	Op        string         `json:"op"`
+	ID        uint           `json:"id,omitempty"`
+	Spaceship *SpaceshipFull `json:"spaceship,omitempty"`
+	Status    string         `json:"status,omitempty"`
+}
+
+// result of operation with http status of the same single operation,
+// error is in the same envelope as error of single operation
+type SpaceshipOperationResult struct {
+	Status int            `json:"status"`
+	Data   *SpaceshipFull `json:"data,omitempty"`
+	Error  *ErrorResponce `json:"error,omitempty"`
+}
+
+// convert operation to domain, unknown op and status are left undefined for service to reject
+func (o *SpaceshipOperation) ToDomain() domain.SpaceshipOperation {
+	op := domain.SpaceshipOperation{
+		Type:   domain.SpaceshipOperationTypeFromString(o.Op),
+		ID:     o.ID,
+		Status: domain.SpaceshipStatusFromString(o.Status),
+	}
+	if o.Spaceship != nil {
+		op.Spaceship = o.Spaceship.ToDomain()
+	}
+	return op
+}
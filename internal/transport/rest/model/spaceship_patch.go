@@ -0,0 +1,146 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/jsonpatch"
+	"github.com/pkg/errors"
+)
+
+var (
+	// test interface
+	_ domain.SpaceshipPatch = SpaceshipJSONPatch{}
+)
+
+// RFC 7396 merge patch of spaceship, null resets field to zero value,
+// armament array is replaced as a whole, id is read-only and ignored
+func SpaceshipMergePatch(data []byte) (*domain.SpaceshipUpdate, error) {
+	return spaceshipUpdate(data, false)
+}
+
+// RFC 6902 json patch of spaceship, operations are applied to full rest model,
+// e.g. replace of /armament/0/qty or add of /armament/-
+type SpaceshipJSONPatch struct {
+	Patch jsonpatch.Patch
+}
+
+func (p SpaceshipJSONPatch) Update(spaceship *domain.Spaceship) (*domain.SpaceshipUpdate, error) {
+
+	data, err := json.Marshal(SpaceshipFullFromDomain(spaceship))
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = p.Patch.Apply(doc)
+	if errors.Is(err, jsonpatch.ErrTest) {
+		return nil, errors.Wrapf(domain.ErrPatchTest, "%s", err)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(domain.ErrPatchInvalid, "%s", err)
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// patched document is full spaceship, removed fields are reset
+	return spaceshipUpdate(data, true)
+}
+
+// update of fields present in json object, absent fields are zero values of full update
+func spaceshipUpdate(data []byte, full bool) (*domain.SpaceshipUpdate, error) {
+
+	fields := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, errors.Wrapf(domain.ErrPatchInvalid, "%s", err)
+	}
+
+	u := &domain.SpaceshipUpdate{}
+	if full {
+		u = &domain.SpaceshipUpdate{
+			Name:     new(string),
+			Class:    new(string),
+			ClassID:  new(uint),
+			Armament: &[]domain.SpaceshipArmament{},
+			Crew:     new(uint),
+			Image:    new(string),
+			Value:    new(domain.Decimal),
+			Currency: new(string),
+		}
+	}
+
+	for key, raw := range fields {
+		// null is zero value of field
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			raw = nil
+		}
+		switch key {
+		// read only, position is reported separately
+		case "id", "position":
+		case "name":
+			u.Name = new(string)
+			err = decodeField(raw, u.Name)
+		case "class":
+			u.Class = new(string)
+			err = decodeField(raw, u.Class)
+		case "class_id":
+			u.ClassID = new(uint)
+			err = decodeField(raw, u.ClassID)
+		case "crew":
+			u.Crew = new(uint)
+			err = decodeField(raw, u.Crew)
+		case "image":
+			u.Image = new(string)
+			err = decodeField(raw, u.Image)
+		case "value":
+			u.Value = new(domain.Decimal)
+			err = decodeField(raw, u.Value)
+		case "currency":
+			u.Currency = new(string)
+			err = decodeField(raw, u.Currency)
+		case "armament":
+			armament := []SpaceshipArmament{}
+			err = decodeField(raw, &armament)
+			domainArmament := make([]domain.SpaceshipArmament, 0, len(armament))
+			for _, a := range armament {
+				domainArmament = append(domainArmament, domain.SpaceshipArmament{Title: a.Title, Qty: a.Qty})
+			}
+			u.Armament = &domainArmament
+		case "status":
+			var status string
+			err = decodeField(raw, &status)
+			s := domain.SpaceshipStatusFromString(status)
+			if err == nil && s == domain.SpaceshipStatusUndefined {
+				err = errors.Errorf("status %q is unknown", status)
+			}
+			u.Status = &s
+		default:
+			err = errors.Errorf("field %q is unknown", key)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(domain.ErrPatchInvalid, "%s: %s", key, err)
+		}
+	}
+
+	if full && u.Status == nil {
+		return nil, errors.Wrapf(domain.ErrPatchInvalid, "status is required")
+	}
+
+	return u, nil
+}
+
+func decodeField(raw json.RawMessage, v interface{}) error {
+	if raw == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
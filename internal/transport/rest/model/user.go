@@ -20,7 +20,52 @@ type UserRefreshReq struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// auth tokens, or mfa token with required step when second factor is needed
 type UserAuthRes struct {
-	AuthToken    string `json:"auth_token"`
-	RefreshToken string `json:"refresh_token"`
+	AuthToken    string `json:"auth_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// mfa step is verify or enroll
+	MFA      string `json:"mfa,omitempty"`
+	MFAToken string `json:"mfa_token,omitempty"`
+}
+
+type PasswordForgotReq struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetReq struct {
+	Token      string `json:"token"`
+	Password   string `json:"password"`
+	RePassword string `json:"repassword"`
+}
+
+type EmailVerifyReq struct {
+	Token string `json:"token"`
+}
+
+type EmailVerifyResendReq struct {
+	Email string `json:"email"`
+}
+
+type MFAVerifyReq struct {
+	MFAToken string `json:"mfa_token"`
+	// totp or recovery code
+	Code string `json:"code"`
+}
+
+type MFACodeReq struct {
+	Code string `json:"code"`
+}
+
+type MFAEnrollRes struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	// base64 encoded png image
+	QRCode []byte `json:"qr_code"`
+}
+
+// recovery codes are shown once, auth tokens are issued when enrolment was forced on login
+type MFARecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	*UserAuthRes
 }
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type Valuation struct {
+	ID          uint           `json:"id"`
+	Amount      domain.Decimal `json:"amount"`
+	Currency    string         `json:"currency"`
+	Source      string         `json:"source"`
+	Note        string         `json:"note,omitempty"`
+	AppraisedAt time.Time      `json:"appraised_at"`
+}
+
+type SpaceshipValuation struct {
+	SpaceshipID uint           `json:"spaceship_id"`
+	Currency    string         `json:"currency"`
+	Appraised   domain.Decimal `json:"appraised"`
+	AppraisedAt *time.Time     `json:"appraised_at,omitempty"`
+	// schedule of class, absent for spaceship out of catalog
+	Depreciation     *Depreciation  `json:"depreciation,omitempty"`
+	Depreciated      domain.Decimal `json:"depreciated"`
+	DamageAdjustment domain.Decimal `json:"damage_adjustment"`
+	BookValue        domain.Decimal `json:"book_value"`
+	History          []Valuation    `json:"history"`
+	At               time.Time      `json:"at"`
+}
+
+type ValuationTotal struct {
+	Currency         string         `json:"currency"`
+	Spaceships       int            `json:"spaceships"`
+	Appraised        domain.Decimal `json:"appraised"`
+	Depreciated      domain.Decimal `json:"depreciated"`
+	DamageAdjustment domain.Decimal `json:"damage_adjustment"`
+	BookValue        domain.Decimal `json:"book_value"`
+}
+
+type FleetValuation struct {
+	// totals by currency, values of different currencies are never summed
+	Totals []ValuationTotal `json:"totals"`
+	At     time.Time        `json:"at"`
+}
+
+type AppraisalReq struct {
+	Amount domain.Decimal `json:"amount"`
+	// default currency if empty
+	Currency string `json:"currency"`
+	Note     string `json:"note"`
+	// now if empty
+	AppraisedAt *time.Time `json:"appraised_at,omitempty"`
+}
+
+func ValuationFromDomain(v *domain.Valuation) Valuation {
+	return Valuation{
+		ID:          v.ID,
+		Amount:      v.Amount,
+		Currency:    v.Currency,
+		Source:      v.Source.String(),
+		Note:        v.Note,
+		AppraisedAt: time.Unix(v.AppraisedAt, 0).UTC(),
+	}
+}
+
+func SpaceshipValuationFromDomain(v *domain.SpaceshipValuation) SpaceshipValuation {
+	history := make([]Valuation, 0, len(v.History))
+	for _, h := range v.History {
+		history = append(history, ValuationFromDomain(h))
+	}
+	res := SpaceshipValuation{
+		SpaceshipID:      v.SpaceshipID,
+		Currency:         v.Currency,
+		Appraised:        v.Appraised,
+		Depreciated:      v.Depreciation,
+		DamageAdjustment: v.DamageAdjustment,
+		BookValue:        v.BookValue,
+		History:          history,
+		At:               time.Unix(v.At, 0).UTC(),
+	}
+	if v.AppraisedAt != 0 {
+		appraisedAt := time.Unix(v.AppraisedAt, 0).UTC()
+		res.AppraisedAt = &appraisedAt
+	}
+	if v.Schedule != nil {
+		res.Depreciation = &Depreciation{
+			Method:  v.Schedule.Method.String(),
+			Rate:    v.Schedule.Rate,
+			Salvage: v.Schedule.Salvage,
+			Damage:  v.Schedule.Damage,
+		}
+	}
+	return res
+}
+
+func FleetValuationFromDomain(fleet *domain.FleetValuation) FleetValuation {
+	totals := make([]ValuationTotal, 0, len(fleet.Totals))
+	for _, t := range fleet.Totals {
+		totals = append(totals, ValuationTotal{
+			Currency:         t.Currency,
+			Spaceships:       t.Spaceships,
+			Appraised:        t.Appraised,
+			Depreciated:      t.Depreciation,
+			DamageAdjustment: t.DamageAdjustment,
+			BookValue:        t.BookValue,
+		})
+	}
+	return FleetValuation{Totals: totals, At: time.Unix(fleet.At, 0).UTC()}
+}
+
+func (r *AppraisalReq) ToDomain() *domain.Valuation {
+	v := &domain.Valuation{
+		Amount:   r.Amount,
+		Currency: r.Currency,
+		Note:     r.Note,
+	}
+	if r.AppraisedAt != nil {
+		v.AppraisedAt = r.AppraisedAt.Unix()
+	}
+	return v
+}
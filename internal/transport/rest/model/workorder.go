@@ -0,0 +1,112 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/domain"
+)
+
+type WorkOrder struct {
+	ID            uint            `json:"id"`
+	SpaceshipID   uint            `json:"spaceship_id"`
+	Severity      string          `json:"severity"`
+	Status        string          `json:"status"`
+	Description   string          `json:"description"`
+	Yard          string          `json:"yard"`
+	EstimatedCost float64         `json:"estimated_cost"`
+	Parts         []WorkOrderPart `json:"parts"`
+	OpenedAt      time.Time       `json:"opened_at"`
+	DueAt         time.Time       `json:"due_at"`
+	StartedAt     *time.Time      `json:"started_at,omitempty"`
+	ClosedAt      *time.Time      `json:"closed_at,omitempty"`
+}
+
+type WorkOrderPart struct {
+	Title string `json:"title"`
+	Qty   uint   `json:"qty"`
+}
+
+type WorkOrderBreach struct {
+	WorkOrder
+	// overdue in seconds
+	Overdue int64 `json:"overdue"`
+}
+
+type WorkOrderReportReq struct {
+	SpaceshipID   uint            `json:"spaceship_id"`
+	Severity      string          `json:"severity"`
+	Description   string          `json:"description"`
+	Yard          string          `json:"yard"`
+	EstimatedCost float64         `json:"estimated_cost"`
+	Parts         []WorkOrderPart `json:"parts"`
+}
+
+type WorkOrderUpdateReq struct {
+	Severity      string          `json:"severity"`
+	Description   string          `json:"description"`
+	Yard          string          `json:"yard"`
+	EstimatedCost float64         `json:"estimated_cost"`
+	Parts         []WorkOrderPart `json:"parts"`
+}
+
+type WorkOrderStatusReq struct {
+	Status string `json:"status"`
+}
+
+func WorkOrderFromDomain(order *domain.WorkOrder) WorkOrder {
+	parts := make([]WorkOrderPart, 0, len(order.Parts))
+	for _, p := range order.Parts {
+		parts = append(parts, WorkOrderPart{Title: p.Title, Qty: p.Qty})
+	}
+	return WorkOrder{
+		ID:            order.ID,
+		SpaceshipID:   order.SpaceshipID,
+		Severity:      order.Severity.String(),
+		Status:        order.Status.String(),
+		Description:   order.Description,
+		Yard:          order.Yard,
+		EstimatedCost: order.EstimatedCost,
+		Parts:         parts,
+		OpenedAt:      time.Unix(order.OpenedAt, 0).UTC(),
+		DueAt:         time.Unix(order.DueAt(), 0).UTC(),
+		StartedAt:     unixTime(order.StartedAt),
+		ClosedAt:      unixTime(order.ClosedAt),
+	}
+}
+
+func WorkOrderBreachFromDomain(breach *domain.WorkOrderBreach) WorkOrderBreach {
+	return WorkOrderBreach{
+		WorkOrder: WorkOrderFromDomain(breach.WorkOrder),
+		Overdue:   int64(breach.Overdue / time.Second),
+	}
+}
+
+func (r *WorkOrderReportReq) ToDomain() *domain.WorkOrder {
+	return &domain.WorkOrder{
+		SpaceshipID:   r.SpaceshipID,
+		Severity:      domain.WorkOrderSeverityFromString(r.Severity),
+		Description:   r.Description,
+		Yard:          r.Yard,
+		EstimatedCost: r.EstimatedCost,
+		Parts:         partsToDomain(r.Parts),
+	}
+}
+
+func (r *WorkOrderUpdateReq) ToDomain(id uint) *domain.WorkOrder {
+	return &domain.WorkOrder{
+		ID:            id,
+		Severity:      domain.WorkOrderSeverityFromString(r.Severity),
+		Description:   r.Description,
+		Yard:          r.Yard,
+		EstimatedCost: r.EstimatedCost,
+		Parts:         partsToDomain(r.Parts),
+	}
+}
+
+func partsToDomain(parts []WorkOrderPart) []domain.WorkOrderPart {
+	res := make([]domain.WorkOrderPart, 0, len(parts))
+	for _, p := range parts {
+		res = append(res, domain.WorkOrderPart{Title: p.Title, Qty: p.Qty})
+	}
+	return res
+}
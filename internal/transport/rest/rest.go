@@ -2,22 +2,54 @@ package rest
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/Je33/imperial_fleet/internal/blob"
 	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/hyperspace"
+	"github.com/Je33/imperial_fleet/internal/idempotency"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/mailer"
+	"github.com/Je33/imperial_fleet/internal/ratelimit"
+	"github.com/Je33/imperial_fleet/internal/readiness"
+	"github.com/Je33/imperial_fleet/internal/repository/cache"
 	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/apikey"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/crew"
+	hyperspacerepo "github.com/Je33/imperial_fleet/internal/repository/db/mysql/hyperspace"
+	idempotencyrepo "github.com/Je33/imperial_fleet/internal/repository/db/mysql/idempotency"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/migrate"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mission"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/organization"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/report"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/serviceaccount"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/shipclass"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/signingkey"
 	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
 	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/workorder"
+	"github.com/Je33/imperial_fleet/internal/search"
 	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/transport/graphql"
 	"github.com/Je33/imperial_fleet/internal/transport/rest/handler"
+	"github.com/pkg/errors"
 
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoLog "github.com/labstack/gommon/log"
 )
 
+var (
+	// errors prefix
+	restErrorPrefix = "[transport.rest]"
+)
+
 // TODO: create swagger doc
 
 func RunRest() error {
@@ -33,22 +65,327 @@ func RunRest() error {
 	}
 
 	// Automigrate database
-	db.AutoMigrate(&user.User{}, &spaceship.Spaceship{}, &spaceship.SpaceshipArmament{}, &spaceship.SpaceshipArmamentQty{})
+	err = migrate.Run(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	// init mailer
+	mail, err := NewMailer(cfg)
+	if err != nil {
+		return err
+	}
+
+	// init token signing keys, rotated in background
+	keys, err := NewKeySet(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+	go keys.Run(ctx, keyCheckInterval(cfg))
 
 	// init repositories
 	userRepo := user.NewUserRepo(db)
-	spaceshipRepo := spaceship.NewSpaceshipRepo(db)
+	userTokenRepo := user.NewUserTokenRepo(db)
+	spaceshipRepo, spaceshipCache := NewSpaceshipCache(cfg, spaceship.NewSpaceshipRepo(db))
+	crewRepo := crew.NewCrewRepo(db)
+	organizationRepo := organization.NewOrganizationRepo(db)
 
 	// init services
-	userService := service.NewUserService(userRepo)
-	spaceshipService := service.NewSpaceshipService(spaceshipRepo)
+	lockout := ratelimit.NewLockout(cfg.LoginLockoutThreshold, cfg.LoginLockoutBase, cfg.LoginLockoutMax)
+	userService := service.NewUserService(userRepo, lockout)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, mail, service.AccountConfig{
+		AppURL:           cfg.AppURL,
+		PasswordResetTTL: cfg.PasswordResetTTL,
+		EmailVerifyTTL:   cfg.EmailVerifyTTL,
+	})
+	mfaConfig, err := NewMFAConfig(cfg)
+	if err != nil {
+		return err
+	}
+	mfaService := service.NewMFAService(userRepo, userTokenRepo, lockout, mfaConfig)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo)
+
+	// search index lives in memory, so it is built on every start
+	index := search.NewIndex()
+	searchService := service.NewSearchService(index, spaceshipRepo, organizationRepo)
+	_, err = searchService.Rebuild(ctx)
+	if err != nil {
+		return err
+	}
+	shipClassRepo := shipclass.NewShipClassRepo(db)
+	workOrderRepo := workorder.NewWorkOrderRepo(db)
+	missionRepo := mission.NewMissionRepo(db)
+	spaceshipService := service.NewSpaceshipService(spaceshipRepo, shipClassRepo, crewRepo, workOrderRepo, missionRepo, blob.NewFileStore(cfg.BlobDir), index, service.ImageConfig{
+		MaxBytes: cfg.ImageMaxBytes,
+		MaxSide:  cfg.ImageMaxSide,
+	})
+
+	// readiness rules are reloaded when file changes
+	readinessEngine, err := NewReadinessEngine(cfg)
+	if err != nil {
+		return err
+	}
+
+	// galaxy of data file is replaced by the one uploaded by admin
+	planner, err := NewHyperspacePlanner(cfg)
+	if err != nil {
+		return err
+	}
+	routeService := service.NewRouteService(spaceshipRepo, shipClassRepo, hyperspacerepo.NewHyperspaceRepo(db), planner)
+	err = routeService.Restore(ctx)
+	if err != nil {
+		return err
+	}
+
+	// fleet metrics are snapshotted daily for trends
+	reportService := service.NewReportService(report.NewReportRepo(db), organizationRepo)
+	go reportService.Run(ctx, cfg.ReportSnapshotInterval)
+
+	// responses of retried requests are replayed, expired ones are purged in background
+	keeper, err := NewIdempotencyKeeper(cfg, db)
+	if err != nil {
+		return err
+	}
+	go keeper.Run(ctx, time.Hour)
+
+	schema, err := NewGraphQLSchema(cfg, spaceshipService, organizationService)
+	if err != nil {
+		return err
+	}
+
+	// keys of users and of service accounts share one table
+	apiKeyRepo := apikey.NewAPIKeyRepo(db)
+	serviceAccountRepo := serviceaccount.NewServiceAccountRepo(db)
+
+	// init echo
+	e := NewServer(cfg, Services{
+		User:           userService,
+		Account:        accountService,
+		MFA:            mfaService,
+		APIKey:         service.NewAPIKeyService(apiKeyRepo, userRepo, serviceAccountRepo),
+		ServiceAccount: service.NewServiceAccountService(serviceAccountRepo, apiKeyRepo, organizationRepo, userRepo),
+		Organization:   organizationService,
+		Spaceship:      spaceshipService,
+		Search:         searchService,
+		ShipClass:      service.NewShipClassService(shipClassRepo, searchService, spaceshipCache),
+		Crew:           service.NewCrewService(crewRepo),
+		WorkOrder:      service.NewWorkOrderService(workOrderRepo, spaceshipRepo, shipClassRepo, crewRepo, spaceshipCache, index),
+		Report:         reportService,
+		Readiness:      service.NewReadinessService(spaceshipRepo, shipClassRepo, workOrderRepo, crewRepo, readinessEngine),
+		Valuation:      service.NewValuationService(spaceshipRepo, shipClassRepo, index),
+		Position:       service.NewPositionService(spaceshipRepo, index),
+		Route:          routeService,
+		Mission:        service.NewMissionService(missionRepo, spaceshipRepo, shipClassRepo, crewRepo),
+		Cache:          service.NewCacheService(spaceshipCache),
+		Idempotency:    keeper,
+		GraphQL:        schema,
+		Keys:           keys,
+	})
+
+	// Start server
+	s := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	e.Logger.Fatal(e.StartServer(s))
+
+	return nil
+}
+
+// NewSpaceshipCache wraps spaceship repository with cache if SPACESHIP_CACHE is on,
+// cache is nil if it is off
+func NewSpaceshipCache(cfg *config.Config, repository service.SpaceshipRepository) (service.SpaceshipRepository, service.SpaceshipCache) {
+	if !cfg.SpaceshipCache {
+		return repository, nil
+	}
+	cached := cache.NewSpaceshipRepo(repository, cache.NewLRU(cfg.SpaceshipCacheSize), cfg.SpaceshipCacheTTL)
+	return cached, cached
+}
+
+// NewIdempotencyKeeper builds keeper of idempotency keys with store configured by IDEMPOTENCY_STORE
+func NewIdempotencyKeeper(cfg *config.Config, db *mysql.DB) (*idempotency.Keeper, error) {
+	var store idempotency.Store
+	switch cfg.IdempotencyStore {
+	case "db", "":
+		store = idempotencyrepo.NewIdempotencyRepo(db)
+	case "memory":
+		store = idempotency.NewMemoryStore()
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: unknown idempotency store %q", restErrorPrefix, cfg.IdempotencyStore)
+	}
+
+	return idempotency.New(store, idempotency.Config{
+		TTL:  cfg.IdempotencyTTL,
+		Wait: cfg.IdempotencyWait,
+	}), nil
+}
+
+// NewGraphQLSchema builds graphql schema with limits of GRAPHQL_* config
+func NewGraphQLSchema(cfg *config.Config, spaceships graphql.SpaceshipService, orgs graphql.OrganizationService) (*graphql.Schema, error) {
+	return graphql.New(spaceships, orgs, graphql.Config{
+		MaxDepth:      cfg.GraphQLMaxDepth,
+		MaxComplexity: cfg.GraphQLMaxComplexity,
+	})
+}
+
+// NewReadinessEngine builds readiness engine with rules of READINESS_RULES file or default rules
+func NewReadinessEngine(cfg *config.Config) (*readiness.Engine, error) {
+	if cfg.ReadinessRules == "" {
+		return readiness.New(readiness.DefaultRules()), nil
+	}
+	rules, err := readiness.NewFile(cfg.ReadinessRules)
+	if err != nil {
+		return nil, err
+	}
+	return readiness.New(rules), nil
+}
+
+// NewHyperspacePlanner builds route planner on galaxy of HYPERSPACE_GALAXY file or default galaxy
+func NewHyperspacePlanner(cfg *config.Config) (*hyperspace.Planner, error) {
+	if cfg.HyperspaceGalaxy == "" {
+		return hyperspace.NewPlanner(hyperspace.DefaultGalaxy())
+	}
+	galaxy, err := hyperspace.ReadFile(cfg.HyperspaceGalaxy)
+	if err != nil {
+		return nil, err
+	}
+	return hyperspace.NewPlanner(galaxy)
+}
+
+// NewMailer builds mailer configured by MAILER
+func NewMailer(cfg *config.Config) (service.Mailer, error) {
+	switch cfg.Mailer {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "log", "":
+		var w io.Writer = os.Stderr
+		if cfg.MailerLogFile != "" {
+			f, err := os.OpenFile(cfg.MailerLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: open mailer log file", restErrorPrefix)
+			}
+			w = f
+		}
+		return mailer.NewLogMailer(w, cfg.MailFrom), nil
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: unknown mailer %q", restErrorPrefix, cfg.Mailer)
+	}
+}
+
+// NewKeySet loads token signing keys from store configured by JWT_KEY_STORE
+func NewKeySet(ctx context.Context, cfg *config.Config, db *mysql.DB) (*keyset.KeySet, error) {
+	var store keyset.Store
+	switch cfg.JWTKeyStore {
+	case "db", "":
+		store = signingkey.NewSigningKeyRepo(db)
+	case "file":
+		store = keyset.NewFileStore(cfg.JWTKeyDir)
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: unknown key store %q", restErrorPrefix, cfg.JWTKeyStore)
+	}
+
+	return keyset.New(ctx, store, keyset.Config{
+		Algorithm:        cfg.JWTKeyAlgorithm,
+		RotationInterval: cfg.JWTKeyRotation,
+		Propagation:      cfg.JWTKeyPropagation,
+		Retention:        cfg.JWTKeyRetention,
+	})
+}
+
+// keys are checked often enough for every instance to load new key during propagation
+func keyCheckInterval(cfg *config.Config) time.Duration {
+	interval := cfg.JWTKeyPropagation / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// NewMFAConfig builds mfa policy from MFA_* config
+func NewMFAConfig(cfg *config.Config) (service.MFAConfig, error) {
+	roles := make([]domain.UserRole, 0, len(cfg.MFARequiredRoles))
+	for _, name := range cfg.MFARequiredRoles {
+		role := domain.UserRoleFromString(strings.TrimSpace(name))
+		if role == domain.UserRoleUndefined {
+			return service.MFAConfig{}, errors.Wrapf(domain.ErrConfig, "%s: unknown mfa required role %q", restErrorPrefix, name)
+		}
+		roles = append(roles, role)
+	}
+	return service.MFAConfig{
+		Issuer:        cfg.MFAIssuer,
+		RequiredRoles: roles,
+	}, nil
+}
+
+// NewIPExtractor trusts X-Forwarded-For of TRUSTED_PROXIES only, otherwise
+// client ip is address of connection, so headers can't spoof it for rate limits
+func NewIPExtractor(cfg *config.Config) echo.IPExtractor {
+	if len(cfg.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, ipNet := range cfg.TrustedProxies {
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// services used by api handlers
+type Services struct {
+	User           handler.UserService
+	Account        handler.AccountService
+	MFA            handler.MFAService
+	APIKey         handler.APIKeyService
+	ServiceAccount handler.ServiceAccountService
+	Organization   handler.OrganizationService
+	Spaceship      handler.SpaceshipService
+	Search         handler.SearchService
+	ShipClass      handler.ShipClassService
+	Crew           handler.CrewService
+	WorkOrder      handler.WorkOrderService
+	Report         handler.ReportService
+	Readiness      handler.ReadinessService
+	Valuation      handler.ValuationService
+	Position       handler.PositionService
+	Route          handler.RouteService
+	Mission        handler.MissionService
+	Cache          handler.CacheService
+	Idempotency    handler.IdempotencyService
+	GraphQL        handler.GraphQLService
+	// token signing keys
+	Keys *keyset.KeySet
+}
+
+// NewServer builds echo with all api routes on top of services
+func NewServer(cfg *config.Config, services Services) *echo.Echo {
 
 	// init handlers
-	userHandler := handler.NewUserHandler(userService)
-	spaceshipHandler := handler.NewSpaceshipHandler(spaceshipService)
+	tokens := handler.NewTokens(services.Keys, cfg.JWTIssuer, cfg.JWTAudience, cfg.MFAChallengeTTL)
+	userHandler := handler.NewUserHandler(services.User, services.Account, services.MFA, tokens)
+	accountHandler := handler.NewAccountHandler(services.Account)
+	mfaHandler := handler.NewMFAHandler(services.MFA, tokens)
+	apiKeyHandler := handler.NewAPIKeyHandler(services.APIKey)
+	organizationHandler := handler.NewOrganizationHandler(services.Organization)
+	serviceAccountHandler := handler.NewServiceAccountHandler(services.ServiceAccount)
+	spaceshipHandler := handler.NewSpaceshipHandler(services.Spaceship)
+	searchHandler := handler.NewSearchHandler(services.Search)
+	shipClassHandler := handler.NewShipClassHandler(services.ShipClass)
+	crewHandler := handler.NewCrewHandler(services.Crew)
+	workOrderHandler := handler.NewWorkOrderHandler(services.WorkOrder)
+	reportHandler := handler.NewReportHandler(services.Report)
+	readinessHandler := handler.NewReadinessHandler(services.Readiness)
+	valuationHandler := handler.NewValuationHandler(services.Valuation)
+	positionHandler := handler.NewPositionHandler(services.Position)
+	routeHandler := handler.NewRouteHandler(services.Route)
+	missionHandler := handler.NewMissionHandler(services.Mission)
+	cacheHandler := handler.NewCacheHandler(services.Cache)
+	graphqlHandler := handler.NewGraphQLHandler(services.GraphQL)
 
 	// init echo
 	e := echo.New()
+	e.HTTPErrorHandler = handler.ErrorHandler
+	e.IPExtractor = NewIPExtractor(cfg)
 	// Disable Echo JSON logger in debug mode
 	if cfg.LogLevel == "debug" {
 		if l, ok := e.Logger.(*echoLog.Logger); ok {
@@ -60,29 +397,202 @@ func RunRest() error {
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
+	// Public keys of token signing keys
+	e.GET("/.well-known/jwks.json", tokens.JWKS)
+
 	// API V1
 	v1 := e.Group("/v1")
 
-	// Auth jwt request
-	v1.POST("/auth", userHandler.Auth)
-	v1.POST("/register", userHandler.Register)
+	// responce format is negotiated before handlers which change anything
+	negotiate := handler.NegotiateMiddleware()
+
+	// Auth jwt request, limited by ip
+	authLimit := handler.RateLimitMiddleware(ratelimit.NewLimiter(cfg.RateLimitAuth), handler.RateLimitByIP)
+	v1.POST("/auth", userHandler.Auth, authLimit, negotiate)
+	v1.POST("/register", userHandler.Register, authLimit, negotiate)
+	v1.POST("/refresh", userHandler.Refresh, authLimit, negotiate)
+
+	// Password reset and email verification
+	v1.POST("/password/forgot", accountHandler.ForgotPassword, authLimit)
+	v1.POST("/password/reset", accountHandler.ResetPassword, authLimit)
+	v1.POST("/email/verify", accountHandler.VerifyEmail, authLimit)
+	v1.POST("/email/verify/resend", accountHandler.ResendVerification, authLimit)
 
-	// Spaceship
+	// Second factor, enrolment is allowed with challenge token when it is forced on login
+	v1.POST("/mfa/verify", mfaHandler.Verify, authLimit)
+	enrollJWT := handler.JWTEnrollMiddleware(tokens)
+	v1.POST("/mfa/enroll", mfaHandler.Enroll, enrollJWT, authLimit)
+	v1.POST("/mfa/confirm", mfaHandler.Confirm, enrollJWT, authLimit)
+	authJWT := handler.JWTMiddleware(tokens)
+	v1.POST("/mfa/disable", mfaHandler.Disable, authJWT, authLimit)
+	v1.POST("/mfa/recovery-codes", mfaHandler.RecoveryCodes, authJWT, authLimit)
+
+	// limited by user or api key
+	apiLimit := handler.RateLimitMiddleware(ratelimit.NewLimiter(cfg.RateLimitAPI), handler.RateLimitByUser)
+
+	// retried mutations with Idempotency-Key get the first response
+	idempotent := handler.IdempotencyMiddleware(services.Idempotency)
+
+	// API keys are managed by users authenticated with jwt only,
+	// creation is not replayed, since stored responce would keep raw key
+	kg := v1.Group("/apikeys")
+	kg.Use(handler.JWTMiddleware(tokens))
+	kg.Use(handler.RequireVerified(services.Account))
+	kg.Use(apiLimit)
+	kg.GET("", apiKeyHandler.List)
+	kg.POST("", apiKeyHandler.Create)
+	kg.DELETE("/:id", apiKeyHandler.Delete, idempotent)
+
+	// Organizations and their members
+	og := v1.Group("/orgs")
+	og.Use(handler.JWTMiddleware(tokens))
+	og.Use(handler.RequireVerified(services.Account))
+	og.Use(apiLimit)
+	og.Use(idempotent)
+	og.GET("", organizationHandler.List)
+	og.POST("", organizationHandler.Create)
+	og.GET("/:id/members", organizationHandler.Members)
+	og.PUT("/:id/members", organizationHandler.PutMember)
+	og.DELETE("/:id/members/:userId", organizationHandler.DeleteMember)
+
+	// Service accounts of organization and their keys are managed by its admins,
+	// key creation is not replayed, since stored responce would keep raw key
+	sag := v1.Group("/orgs/:id/serviceaccounts")
+	sag.Use(handler.JWTMiddleware(tokens))
+	sag.Use(handler.RequireVerified(services.Account))
+	sag.Use(apiLimit)
+	sag.GET("", serviceAccountHandler.List)
+	sag.POST("", serviceAccountHandler.Create, idempotent)
+	sag.DELETE("/:accountId", serviceAccountHandler.Delete, idempotent)
+	sag.GET("/:accountId/apikeys", serviceAccountHandler.Keys)
+	sag.POST("/:accountId/apikeys", serviceAccountHandler.CreateKey)
+	sag.DELETE("/:accountId/apikeys/:keyId", serviceAccountHandler.DeleteKey, idempotent)
+
+	// Spaceship of organization selected by X-Org-ID, api keys are allowed with scopes
 	sg := v1.Group("/spaceships")
-	sg.Use(echojwt.JWT([]byte(cfg.JWTSecret)))
-	sg.GET("", spaceshipHandler.GetAll)
-	sg.GET("/:id", spaceshipHandler.GetById)
-	sg.POST("", spaceshipHandler.CreateSpaceship)
-	sg.POST("/:id", spaceshipHandler.UpdateSpaceship)
-	sg.DELETE("/:id", spaceshipHandler.DeleteSpaceship)
+	sg.Use(handler.AuthMiddleware(tokens, services.APIKey))
+	sg.Use(handler.RequireVerified(services.Account))
+	sg.Use(apiLimit)
+	sg.Use(handler.TenantMiddleware(services.Organization))
+	read := handler.RequireScope(domain.ScopeSpaceshipsRead)
+	write := handler.RequireScope(domain.ScopeSpaceshipsWrite)
+	member := handler.RequireOrgRole(domain.OrgRoleMember)
+	sg.GET("", spaceshipHandler.GetAll, read)
+	sg.GET("/:id", spaceshipHandler.GetById, read)
+	sg.POST("", spaceshipHandler.CreateSpaceship, write, member, negotiate, idempotent)
+	sg.POST("/batch", spaceshipHandler.Batch, write, member, negotiate, idempotent)
+	sg.PUT("/:id", spaceshipHandler.UpdateSpaceship, write, member, negotiate, idempotent)
+	sg.PATCH("/:id", spaceshipHandler.PatchSpaceship, write, member, negotiate, idempotent)
+	// legacy alias of full replacement
+	sg.POST("/:id", spaceshipHandler.UpdateSpaceship, write, member, negotiate, idempotent)
+	sg.DELETE("/:id", spaceshipHandler.DeleteSpaceship, write, member, negotiate, idempotent)
+	// multipart envelope is allowed on top of image size, which is checked before body is buffered
+	imageLimit := middleware.BodyLimit(fmt.Sprintf("%dK", cfg.ImageMaxBytes/1024+64))
+	sg.PUT("/:id/image", spaceshipHandler.UploadImage, write, member, imageLimit, negotiate, idempotent)
+	sg.GET("/:id/image", spaceshipHandler.GetImage, read)
+	sg.GET("/readiness", readinessHandler.Fleet, read)
+	sg.GET("/:id/readiness", readinessHandler.Spaceship, read)
+	sg.GET("/valuation", valuationHandler.Fleet, read)
+	sg.GET("/:id/valuation", valuationHandler.Spaceship, read)
+	sg.POST("/:id/valuations", valuationHandler.Appraise, write, member, negotiate, idempotent)
+	sg.GET("/near", positionHandler.Near, read)
+	sg.POST("/:id/position", positionHandler.Report, write, member, negotiate, idempotent)
+	sg.GET("/:id/positions", positionHandler.Positions, read)
+	sg.POST("/:id/routes", routeHandler.Plan, read)
 
-	// Start server
-	s := &http.Server{
-		Addr:         cfg.HTTPAddr,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-	}
-	e.Logger.Fatal(e.StartServer(s))
+	// GraphQL over spaceships of organization, mutations check write scope and role in resolvers
+	v1.POST("/graphql", graphqlHandler.Query, handler.AuthMiddleware(tokens, services.APIKey),
+		handler.RequireVerified(services.Account), apiLimit, handler.TenantMiddleware(services.Organization), read, idempotent)
 
-	return nil
+	// Catalog of spaceship classes shared by organizations, changed by admins
+	clg := v1.Group("/classes")
+	clg.GET("", shipClassHandler.GetAll, handler.AuthMiddleware(tokens, services.APIKey),
+		handler.RequireVerified(services.Account), apiLimit, read)
+	clg.GET("/:id", shipClassHandler.GetById, handler.AuthMiddleware(tokens, services.APIKey),
+		handler.RequireVerified(services.Account), apiLimit, read)
+	adminRole := handler.RequireRole(services.Account, domain.UserRoleAdmin)
+	admin := []echo.MiddlewareFunc{handler.JWTMiddleware(tokens), handler.RequireVerified(services.Account), adminRole, apiLimit, negotiate, idempotent}
+	clg.POST("", shipClassHandler.Create, admin...)
+	clg.POST("/normalize", shipClassHandler.Normalize, admin...)
+	clg.POST("/:id", shipClassHandler.Update, admin...)
+	clg.DELETE("/:id", shipClassHandler.Delete, admin...)
+
+	// Galaxy of route planner shared by organizations, changed by admins
+	hg := v1.Group("/hyperspace")
+	hg.GET("", routeHandler.Galaxy, handler.AuthMiddleware(tokens, services.APIKey),
+		handler.RequireVerified(services.Account), apiLimit, read)
+	hg.PUT("", routeHandler.Upload, handler.JWTMiddleware(tokens), handler.RequireVerified(services.Account), adminRole, apiLimit,
+		middleware.BodyLimit("1M"), negotiate, idempotent)
+	hg.POST("/closures", routeHandler.CloseLane, admin...)
+	hg.DELETE("/closures/:id", routeHandler.OpenLane, admin...)
+
+	// Crew roster of organization
+	cg := v1.Group("/crew")
+	cg.Use(handler.AuthMiddleware(tokens, services.APIKey))
+	cg.Use(handler.RequireVerified(services.Account))
+	cg.Use(apiLimit)
+	cg.Use(handler.TenantMiddleware(services.Organization))
+	cg.Use(idempotent)
+	crewRead := handler.RequireScope(domain.ScopeCrewRead)
+	crewWrite := handler.RequireScope(domain.ScopeCrewWrite)
+	cg.GET("", crewHandler.GetAll, crewRead)
+	cg.GET("/:id", crewHandler.GetById, crewRead)
+	cg.POST("", crewHandler.Enlist, crewWrite, member)
+	cg.POST("/:id/transfer", crewHandler.Transfer, crewWrite, member)
+	cg.POST("/:id/discharge", crewHandler.Discharge, crewWrite, member)
+
+	// Repair work orders of damaged spaceships
+	wg := v1.Group("/workorders")
+	wg.Use(handler.AuthMiddleware(tokens, services.APIKey))
+	wg.Use(handler.RequireVerified(services.Account))
+	wg.Use(apiLimit)
+	wg.Use(handler.TenantMiddleware(services.Organization))
+	wg.Use(idempotent)
+	workOrdersRead := handler.RequireScope(domain.ScopeWorkOrdersRead)
+	workOrdersWrite := handler.RequireScope(domain.ScopeWorkOrdersWrite)
+	wg.GET("", workOrderHandler.GetAll, workOrdersRead)
+	wg.GET("/sla", workOrderHandler.SLABreaches, workOrdersRead)
+	wg.GET("/:id", workOrderHandler.GetById, workOrdersRead)
+	wg.POST("", workOrderHandler.Report, workOrdersWrite, member)
+	wg.POST("/:id", workOrderHandler.Update, workOrdersWrite, member)
+	wg.POST("/:id/status", workOrderHandler.Move, workOrdersWrite, member)
+
+	// Missions of spaceships, spaceship is booked by one mission at a time
+	mg := v1.Group("/missions")
+	mg.Use(handler.AuthMiddleware(tokens, services.APIKey))
+	mg.Use(handler.RequireVerified(services.Account))
+	mg.Use(apiLimit)
+	mg.Use(handler.TenantMiddleware(services.Organization))
+	mg.Use(negotiate)
+	mg.Use(idempotent)
+	missionsRead := handler.RequireScope(domain.ScopeMissionsRead)
+	missionsWrite := handler.RequireScope(domain.ScopeMissionsWrite)
+	mg.GET("", missionHandler.GetAll, missionsRead)
+	mg.GET("/:id", missionHandler.GetById, missionsRead)
+	mg.POST("", missionHandler.Plan, missionsWrite, member)
+	mg.POST("/:id", missionHandler.Update, missionsWrite, member)
+	mg.POST("/:id/status", missionHandler.Move, missionsWrite, member)
+
+	// Reports over fleet of organization, csv by format=csv or accept header
+	rg := v1.Group("/reports")
+	rg.Use(handler.AuthMiddleware(tokens, services.APIKey))
+	rg.Use(handler.RequireVerified(services.Account))
+	rg.Use(apiLimit)
+	rg.Use(handler.TenantMiddleware(services.Organization))
+	rg.Use(handler.RequireScope(domain.ScopeReportsRead))
+	rg.GET("/fleet", reportHandler.Fleet)
+	rg.GET("/armament", reportHandler.Armament)
+	rg.GET("/readiness", reportHandler.Readiness)
+	rg.GET("/trends", reportHandler.Trends)
+
+	// Search in spaceships of organization, index is rebuilt by admin
+	v1.GET("/search", searchHandler.Search, handler.AuthMiddleware(tokens, services.APIKey),
+		handler.RequireVerified(services.Account), apiLimit, handler.TenantMiddleware(services.Organization), read)
+	v1.POST("/search/reindex", searchHandler.Reindex, admin...)
+
+	// Metrics and purge of spaceship cache for admins
+	v1.GET("/cache", cacheHandler.Stats, admin...)
+	v1.DELETE("/cache", cacheHandler.Purge, admin...)
+
+	return e
 }
@@ -0,0 +1,457 @@
+package rest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/ratelimit"
+	"github.com/Je33/imperial_fleet/internal/totp"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/model"
+	"github.com/Je33/imperial_fleet/internal/transport/rest/resttest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// post json and decode json responce
+func postJSON(t *testing.T, url string, body interface{}, res interface{}) *http.Response {
+	t.Helper()
+	return postAuth(t, url, "", body, res)
+}
+
+// post json with bearer token and decode json responce
+func postAuth(t *testing.T, url string, token string, body interface{}, res interface{}) *http.Response {
+	t.Helper()
+
+	reqBody, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(reqBody)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpRes, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer httpRes.Body.Close()
+
+	if res != nil {
+		require.NoError(t, json.NewDecoder(httpRes.Body).Decode(res))
+	}
+	return httpRes
+}
+
+func TestNewServer_AuthLimits(t *testing.T) {
+
+	cfg := *config.Get()
+	cfg.RateLimitAuth = ratelimit.Rate{Limit: 4, Period: time.Minute}
+	server := resttest.NewServer(t, &cfg, resttest.WithLockout(ratelimit.NewLockout(2, time.Minute, time.Hour)))
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+
+	// unknown user and wrong password give the same responce
+	unknownRes := model.ErrorResponce{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "tarkin@empire.gov", Password: "123123"}, &unknownRes)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+	assert.Equal(t, "4", httpRes.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "3", httpRes.Header.Get("RateLimit-Remaining"))
+
+	wrongRes := model.ErrorResponce{}
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "456456"}, &wrongRes)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+	assert.Equal(t, unknownRes, wrongRes)
+
+	// account is locked after threshold, even with right password
+	postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "456456"}, nil)
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "123123"}, nil)
+	assert.Equal(t, http.StatusTooManyRequests, httpRes.StatusCode)
+	assert.Equal(t, "60", httpRes.Header.Get("Retry-After"))
+
+	// ip is limited
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "123123"}, nil)
+	assert.Equal(t, http.StatusTooManyRequests, httpRes.StatusCode)
+	assert.Equal(t, "0", httpRes.Header.Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, httpRes.Header.Get("Retry-After"))
+}
+
+func TestNewServer_AuthLimitsForwarded(t *testing.T) {
+
+	cfg := *config.Get()
+	cfg.RateLimitAuth = ratelimit.Rate{Limit: 2, Period: time.Minute}
+
+	// post login from client which claims ip in X-Forwarded-For
+	login := func(url string, ip string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, url+"/v1/auth", strings.NewReader(`{"email":"vader@empire.gov","password":"123123"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", ip)
+		req.Header.Set("X-Real-IP", ip)
+		httpRes, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		httpRes.Body.Close()
+		return httpRes
+	}
+
+	// spoofed header doesn't give new bucket
+	server := resttest.NewServer(t, &cfg)
+	assert.Equal(t, "1", login(server.URL, "10.0.0.1").Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "0", login(server.URL, "10.0.0.2").Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, login(server.URL, "10.0.0.3").StatusCode)
+
+	// header of trusted proxy gives ip of client
+	require.NoError(t, cfg.TrustedProxies.Decode("127.0.0.1/32, ::1/128"))
+	server = resttest.NewServer(t, &cfg)
+	assert.Equal(t, "1", login(server.URL, "10.0.0.1").Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", login(server.URL, "10.0.0.2").Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "0", login(server.URL, "10.0.0.1").Header.Get("RateLimit-Remaining"))
+}
+
+// get with bearer token
+func getAuth(t *testing.T, url string, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	httpRes, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	httpRes.Body.Close()
+	return httpRes
+}
+
+func TestNewServer_Account(t *testing.T) {
+
+	server := resttest.NewServer(t, config.Get())
+
+	// registered user must verify email before using api
+	tokens := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/register", model.UserRegisterReq{Email: "krennic@empire.gov", Password: "123123", RePassword: "123123"}, &tokens)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, http.StatusForbidden, getAuth(t, server.URL+"/v1/spaceships", tokens.AuthToken).StatusCode)
+
+	verifyToken := server.Outbox.LastToken("krennic@empire.gov")
+	require.NotEmpty(t, verifyToken)
+
+	httpRes = postJSON(t, server.URL+"/v1/email/verify", model.EmailVerifyReq{Token: "wrong"}, nil)
+	assert.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+
+	httpRes = postJSON(t, server.URL+"/v1/email/verify", model.EmailVerifyReq{Token: verifyToken}, nil)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+	// verified user needs organization
+	assert.Equal(t, http.StatusForbidden, getAuth(t, server.URL+"/v1/spaceships", tokens.AuthToken).StatusCode)
+	httpRes = postAuth(t, server.URL+"/v1/orgs", tokens.AuthToken, model.OrganizationCreateReq{Name: "Advanced Weapons Research"}, nil)
+	assert.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	assert.Equal(t, http.StatusOK, getAuth(t, server.URL+"/v1/spaceships", tokens.AuthToken).StatusCode)
+
+	// token is single use
+	httpRes = postJSON(t, server.URL+"/v1/email/verify", model.EmailVerifyReq{Token: verifyToken}, nil)
+	assert.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+
+	// unknown email gives the same responce and sends nothing
+	sent := len(server.Outbox.Messages())
+	httpRes = postJSON(t, server.URL+"/v1/password/forgot", model.PasswordForgotReq{Email: "tarkin@empire.gov"}, nil)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Len(t, server.Outbox.Messages(), sent)
+
+	httpRes = postJSON(t, server.URL+"/v1/password/forgot", model.PasswordForgotReq{Email: "krennic@empire.gov"}, nil)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+	resetToken := server.Outbox.LastToken("krennic@empire.gov")
+	require.NotEmpty(t, resetToken)
+
+	httpRes = postJSON(t, server.URL+"/v1/password/reset", model.PasswordResetReq{Token: resetToken, Password: "456456", RePassword: "456456"}, nil)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "krennic@empire.gov", Password: "123123"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "krennic@empire.gov", Password: "456456"}, nil)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+}
+
+func TestNewServer_MFA(t *testing.T) {
+
+	cfg := *config.Get()
+	cfg.RateLimitAuth = ratelimit.Rate{Limit: 100, Period: time.Minute}
+	server := resttest.NewServer(t, &cfg, resttest.WithMFARequired(domain.UserRoleAdmin))
+	server.CreateUser(t, "vader@empire.gov", "123123", domain.UserRoleAdmin)
+
+	// policy forces enrolment on login, challenge token is not auth token
+	login := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "123123"}, &login)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "enroll", login.MFA)
+	assert.Empty(t, login.AuthToken)
+	assert.Equal(t, http.StatusUnauthorized, getAuth(t, server.URL+"/v1/spaceships", login.MFAToken).StatusCode)
+
+	enrollment := model.MFAEnrollRes{}
+	httpRes = postAuth(t, server.URL+"/v1/mfa/enroll", login.MFAToken, nil, &enrollment)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	assert.True(t, bytes.HasPrefix(enrollment.QRCode, []byte("\x89PNG")))
+
+	httpRes = postAuth(t, server.URL+"/v1/mfa/confirm", login.MFAToken, model.MFACodeReq{Code: "000000"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+
+	now := time.Now()
+	code, err := totp.Code(enrollment.Secret, now)
+	require.NoError(t, err)
+	confirm := model.MFARecoveryCodesRes{}
+	httpRes = postAuth(t, server.URL+"/v1/mfa/confirm", login.MFAToken, model.MFACodeReq{Code: code}, &confirm)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.Len(t, confirm.RecoveryCodes, 10)
+	require.NotNil(t, confirm.UserAuthRes)
+	assert.Equal(t, http.StatusOK, getAuth(t, server.URL+"/v1/spaceships", confirm.AuthToken).StatusCode)
+
+	// next login requires code, used code is not accepted twice
+	httpRes = postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "vader@empire.gov", Password: "123123"}, &login)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "verify", login.MFA)
+
+	httpRes = postJSON(t, server.URL+"/v1/mfa/verify", model.MFAVerifyReq{MFAToken: login.MFAToken, Code: code}, nil)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+
+	nextCode, err := totp.Code(enrollment.Secret, now.Add(totp.Period))
+	require.NoError(t, err)
+	tokens := model.UserAuthRes{}
+	httpRes = postJSON(t, server.URL+"/v1/mfa/verify", model.MFAVerifyReq{MFAToken: login.MFAToken, Code: nextCode}, &tokens)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, http.StatusOK, getAuth(t, server.URL+"/v1/spaceships", tokens.AuthToken).StatusCode)
+
+	// recovery code is single use
+	httpRes = postJSON(t, server.URL+"/v1/mfa/verify", model.MFAVerifyReq{MFAToken: login.MFAToken, Code: strings.ToUpper(confirm.RecoveryCodes[0])}, &tokens)
+	assert.Equal(t, http.StatusOK, httpRes.StatusCode)
+	httpRes = postJSON(t, server.URL+"/v1/mfa/verify", model.MFAVerifyReq{MFAToken: login.MFAToken, Code: confirm.RecoveryCodes[0]}, nil)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+
+	// auth token is not mfa token
+	httpRes = postJSON(t, server.URL+"/v1/mfa/verify", model.MFAVerifyReq{MFAToken: tokens.AuthToken, Code: confirm.RecoveryCodes[1]}, nil)
+	assert.Equal(t, http.StatusUnauthorized, httpRes.StatusCode)
+
+	// policy does not allow to disable mfa
+	httpRes = postAuth(t, server.URL+"/v1/mfa/disable", tokens.AuthToken, model.MFACodeReq{Code: confirm.RecoveryCodes[1]}, nil)
+	assert.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+}
+
+func TestNewServer_RefreshMFA(t *testing.T) {
+
+	server := resttest.NewServer(t, config.Get())
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+
+	login := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "tarkin@empire.gov", Password: "123123"}, &login)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.NotEmpty(t, login.RefreshToken)
+
+	enrollment := model.MFAEnrollRes{}
+	httpRes = postAuth(t, server.URL+"/v1/mfa/enroll", login.AuthToken, nil, &enrollment)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	httpRes = postAuth(t, server.URL+"/v1/mfa/confirm", login.AuthToken, model.MFACodeReq{Code: code}, nil)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+	// refresh token issued before enrolment doesn't skip second factor
+	refreshed := model.UserAuthRes{}
+	httpRes = postJSON(t, server.URL+"/v1/refresh", model.UserRefreshReq{RefreshToken: login.RefreshToken}, &refreshed)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "verify", refreshed.MFA)
+	assert.Empty(t, refreshed.AuthToken)
+	assert.Empty(t, refreshed.RefreshToken)
+}
+
+func TestNewServer_JWKS(t *testing.T) {
+
+	server := resttest.NewServer(t, config.Get())
+	u := server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleOfficer)
+
+	tokens := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "tarkin@empire.gov", Password: "123123"}, &tokens)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+	// token consumer verifies tokens with published keys only
+	httpRes, err := http.Get(server.URL + "/.well-known/jwks.json")
+	require.NoError(t, err)
+	defer httpRes.Body.Close()
+	assert.Equal(t, "public, max-age=300", httpRes.Header.Get("Cache-Control"))
+	jwks := keyset.JWKS{}
+	require.NoError(t, json.NewDecoder(httpRes.Body).Decode(&jwks))
+	require.Len(t, jwks.Keys, 1)
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokens.AuthToken, claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwks.Keys[0].KeyID, token.Header["kid"])
+		x, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+		return ed25519.PublicKey(x), err
+	}, jwt.WithValidMethods([]string{"EdDSA"}), jwt.WithIssuer(config.Get().JWTIssuer), jwt.WithAudience(config.Get().JWTAudience))
+	require.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, fmt.Sprint(u.ID), claims["sub"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotEmpty(t, claims["iat"])
+
+	// tokens signed with rotated out key are accepted while key is kept
+	_, err = server.Keys.Rotate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, getAuth(t, server.URL+"/v1/spaceships", tokens.AuthToken).StatusCode)
+
+	// tokens signed with shared secret are not accepted anymore
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, getAuth(t, server.URL+"/v1/spaceships", forged).StatusCode)
+}
+
+// send body of content type and accept header with bearer token, responce body is returned
+func sendAuth(t *testing.T, method string, url string, token string, contentType string, body string, accept string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	httpRes, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer httpRes.Body.Close()
+
+	resBody, err := io.ReadAll(httpRes.Body)
+	require.NoError(t, err)
+	return httpRes, resBody
+}
+
+func TestNewServer_ContentNegotiation(t *testing.T) {
+
+	server := resttest.NewServer(t, config.Get())
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleAdmin)
+	tokens := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "tarkin@empire.gov", Password: "123123"}, &tokens)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	url := server.URL + "/v1/spaceships"
+
+	// spaceships are created from every format, responce is in accepted format,
+	// operational ones are of classes out of catalog, which have no minimum crew
+	httpRes, body := sendAuth(t, http.MethodPost, url, tokens.AuthToken, "application/yaml",
+		"name: Devastator\nclass: Victory Star Destroyer\ncrew: 35000\nstatus: operational\narmament:\n  - title: Turbo Laser\n    qty: \"60\"\n", "application/yaml")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode, string(body))
+	assert.Equal(t, "application/yaml", httpRes.Header.Get("Content-Type"))
+	assert.Equal(t, "success: true\n", string(body))
+
+	httpRes, body = sendAuth(t, http.MethodPost, url, tokens.AuthToken, "text/csv",
+		"name,class,crew,status,armament\nExecutor,Victory Star Destroyer,37000,operational,Turbo Laser:50;Ion Cannons:10\n", "text/csv")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode, string(body))
+	assert.Equal(t, "success\ntrue\n", string(body))
+
+	avenger, err := msgpack.Marshal(map[string]interface{}{"name": "Avenger", "class": "Star Destroyer", "status": "damaged"})
+	require.NoError(t, err)
+	httpRes, body = sendAuth(t, http.MethodPost, url, tokens.AuthToken, "application/msgpack", string(avenger), "")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode, string(body))
+	assert.JSONEq(t, `{"success": true}`, string(body))
+
+	// the most preferred available format is chosen
+	httpRes, body = sendAuth(t, http.MethodGet, url, tokens.AuthToken, "", "", "image/png, text/csv;q=0.5, application/json;q=0.1")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", httpRes.Header.Get("Content-Type"))
+	assert.Equal(t, "Accept", httpRes.Header.Get("Vary"))
+	assert.Equal(t, "id,name,status\n1,Devastator,Operational\n2,Executor,Operational\n3,Avenger,Damaged\n", string(body))
+
+	httpRes, body = sendAuth(t, http.MethodGet, url+"/2", tokens.AuthToken, "", "", "application/x-yaml")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	// fields are named as in json
+	executor := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(body, &executor))
+	assert.Equal(t, "Executor", executor["name"])
+	assert.Equal(t, 37000, executor["crew"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"title": "Turbo Laser", "qty": "50"},
+		map[string]interface{}{"title": "Ion Cannons", "qty": "10"},
+	}, executor["armament"])
+
+	// format param overrides accept header
+	httpRes, body = sendAuth(t, http.MethodGet, url+"/3?format=msgpack", tokens.AuthToken, "", "", "application/json")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "application/msgpack", httpRes.Header.Get("Content-Type"))
+	decoded := map[string]interface{}{}
+	require.NoError(t, msgpack.Unmarshal(body, &decoded))
+	assert.Equal(t, "Avenger", decoded["name"])
+	assert.Equal(t, "Damaged", decoded["status"])
+
+	// unavailable formats are not acceptable, error is written as json
+	httpRes, body = sendAuth(t, http.MethodGet, url, tokens.AuthToken, "", "", "image/png")
+	assert.Equal(t, http.StatusNotAcceptable, httpRes.StatusCode)
+	assert.JSONEq(t, `{"message": "none of accepted media types is available"}`, string(body))
+	httpRes, _ = sendAuth(t, http.MethodGet, url+"?format=xml", tokens.AuthToken, "", "", "")
+	assert.Equal(t, http.StatusNotAcceptable, httpRes.StatusCode)
+
+	// errors are written in accepted format, csv body is available only for spaceships
+	httpRes, body = sendAuth(t, http.MethodGet, url+"/9", tokens.AuthToken, "", "", "text/csv")
+	assert.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	assert.Equal(t, "message\nnot found\n", string(body))
+	httpRes, _ = sendAuth(t, http.MethodPost, server.URL+"/v1/auth", "", "text/csv", "email,password\ntarkin@empire.gov,123123\n", "")
+	assert.Equal(t, http.StatusUnsupportedMediaType, httpRes.StatusCode)
+}
+
+func TestNewServer_NotAcceptableWrite(t *testing.T) {
+
+	server := resttest.NewServer(t, config.Get())
+	server.CreateUser(t, "tarkin@empire.gov", "123123", domain.UserRoleAdmin)
+	tokens := model.UserAuthRes{}
+	httpRes := postJSON(t, server.URL+"/v1/auth", model.UserAuthReq{Email: "tarkin@empire.gov", Password: "123123"}, &tokens)
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	url := server.URL + "/v1/spaceships"
+
+	create := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"name": "Devastator", "class": "Star Destroyer", "status": "damaged"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "image/png")
+		req.Header.Set("Authorization", "Bearer "+tokens.AuthToken)
+		req.Header.Set("Idempotency-Key", "devastator")
+		httpRes, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		httpRes.Body.Close()
+		return httpRes
+	}
+
+	// spaceship is not created and 406 is not kept for idempotency key, so it is not replayed
+	for i := 0; i < 2; i++ {
+		httpRes = create()
+		assert.Equal(t, http.StatusNotAcceptable, httpRes.StatusCode)
+		assert.Empty(t, httpRes.Header.Get("Idempotent-Replayed"))
+	}
+	httpRes, body := sendAuth(t, http.MethodGet, url, tokens.AuthToken, "", "", "text/csv")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "id,name,status\n", string(body))
+
+	// spaceship is neither changed nor deleted
+	httpRes, body = sendAuth(t, http.MethodPost, url, tokens.AuthToken, "application/json",
+		`{"name": "Devastator", "class": "Star Destroyer", "status": "damaged"}`, "")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode, string(body))
+	httpRes, _ = sendAuth(t, http.MethodPatch, url+"/1?format=xml", tokens.AuthToken, "application/json", `{"name": "Executor"}`, "")
+	assert.Equal(t, http.StatusNotAcceptable, httpRes.StatusCode)
+	httpRes, _ = sendAuth(t, http.MethodDelete, url+"/1", tokens.AuthToken, "", "", "image/png")
+	assert.Equal(t, http.StatusNotAcceptable, httpRes.StatusCode)
+	httpRes, body = sendAuth(t, http.MethodGet, url, tokens.AuthToken, "", "", "text/csv")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	assert.Equal(t, "id,name,status\n1,Devastator,Damaged\n", string(body))
+}
@@ -0,0 +1,338 @@
+// Package resttest runs api server backed by sqlite database for tests
+package resttest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Je33/imperial_fleet/internal/blob"
+	"github.com/Je33/imperial_fleet/internal/config"
+	"github.com/Je33/imperial_fleet/internal/domain"
+	"github.com/Je33/imperial_fleet/internal/idempotency"
+	"github.com/Je33/imperial_fleet/internal/keyset"
+	"github.com/Je33/imperial_fleet/internal/mailer"
+	"github.com/Je33/imperial_fleet/internal/readiness"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/apikey"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/crew"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/hyperspace"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mission"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/mysqltest"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/organization"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/report"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/serviceaccount"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/shipclass"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/spaceship"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/user"
+	"github.com/Je33/imperial_fleet/internal/repository/db/mysql/workorder"
+	"github.com/Je33/imperial_fleet/internal/search"
+	"github.com/Je33/imperial_fleet/internal/service"
+	"github.com/Je33/imperial_fleet/internal/tenant"
+	"github.com/Je33/imperial_fleet/internal/transport/graphql"
+	"github.com/Je33/imperial_fleet/internal/transport/rest"
+)
+
+// test api server with its services
+type Server struct {
+	*httptest.Server
+
+	DB      *mysql.DB
+	Outbox  *Outbox
+	User    *service.UserService
+	Account *service.AccountService
+	MFA     *service.MFAService
+	APIKey  *service.APIKeyService
+	SAs     *service.ServiceAccountService
+	Org     *service.OrganizationService
+	Ship    *service.SpaceshipService
+	Search  *service.SearchService
+	Classes *service.ShipClassService
+	Crew    *service.CrewService
+	Orders  *service.WorkOrderService
+	Reports *service.ReportService
+	Ready   *service.ReadinessService
+	Values  *service.ValuationService
+	Places  *service.PositionService
+	Routes  *service.RouteService
+	Mission *service.MissionService
+	Cache   *service.CacheService
+	Keeper  *idempotency.Keeper
+	GraphQL *graphql.Schema
+	Keys    *keyset.KeySet
+}
+
+// test server option
+type Option func(*options)
+
+type options struct {
+	lockout     service.LoginLockout
+	mfaRequired []domain.UserRole
+}
+
+// lock out users after failed logins
+func WithLockout(lockout service.LoginLockout) Option {
+	return func(o *options) {
+		o.lockout = lockout
+	}
+}
+
+// require second factor for roles
+func WithMFARequired(roles ...domain.UserRole) Option {
+	return func(o *options) {
+		o.mfaRequired = roles
+	}
+}
+
+// start api server with config
+func NewServer(t testing.TB, cfg *config.Config, opts ...Option) *Server {
+	t.Helper()
+
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	db := mysqltest.Open(t)
+	userRepo := user.NewUserRepo(db)
+	userTokenRepo := user.NewUserTokenRepo(db)
+	spaceshipRepo, spaceshipCache := rest.NewSpaceshipCache(cfg, spaceship.NewSpaceshipRepo(db))
+	organizationRepo := organization.NewOrganizationRepo(db)
+	shipClassRepo := shipclass.NewShipClassRepo(db)
+	crewRepo := crew.NewCrewRepo(db)
+	workOrderRepo := workorder.NewWorkOrderRepo(db)
+	missionRepo := mission.NewMissionRepo(db)
+	index := search.NewIndex()
+
+	s := &Server{
+		DB:     db,
+		Outbox: new(Outbox),
+		User:   service.NewUserService(userRepo, o.lockout),
+		Ship: service.NewSpaceshipService(spaceshipRepo, shipClassRepo, crewRepo, workOrderRepo, missionRepo, blob.NewFileStore(t.TempDir()), index, service.ImageConfig{
+			MaxBytes: cfg.ImageMaxBytes,
+			MaxSide:  cfg.ImageMaxSide,
+		}),
+		Search: service.NewSearchService(index, spaceshipRepo, organizationRepo),
+		Crew:   service.NewCrewService(crewRepo),
+		Orders: service.NewWorkOrderService(workOrderRepo, spaceshipRepo, shipClassRepo, crewRepo, spaceshipCache, index),
+	}
+	s.Reports = service.NewReportService(report.NewReportRepo(db), organizationRepo)
+	s.Ready = service.NewReadinessService(spaceshipRepo, shipClassRepo, workOrderRepo, crewRepo, readiness.New(readiness.DefaultRules()))
+	s.Values = service.NewValuationService(spaceshipRepo, shipClassRepo, index)
+	s.Places = service.NewPositionService(spaceshipRepo, index)
+	planner, err := rest.NewHyperspacePlanner(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Routes = service.NewRouteService(spaceshipRepo, shipClassRepo, hyperspace.NewHyperspaceRepo(db), planner)
+	s.Mission = service.NewMissionService(missionRepo, spaceshipRepo, shipClassRepo, crewRepo)
+	s.MFA = service.NewMFAService(userRepo, userTokenRepo, o.lockout, service.MFAConfig{
+		Issuer:        "Imperial Fleet",
+		RequiredRoles: o.mfaRequired,
+	})
+	s.Classes = service.NewShipClassService(shipClassRepo, s.Search, spaceshipCache)
+	s.Cache = service.NewCacheService(spaceshipCache)
+	apiKeyRepo := apikey.NewAPIKeyRepo(db)
+	serviceAccountRepo := serviceaccount.NewServiceAccountRepo(db)
+	s.APIKey = service.NewAPIKeyService(apiKeyRepo, userRepo, serviceAccountRepo)
+	s.SAs = service.NewServiceAccountService(serviceAccountRepo, apiKeyRepo, organizationRepo, userRepo)
+	s.Org = service.NewOrganizationService(organizationRepo, userRepo)
+	s.Account = service.NewAccountService(userRepo, userTokenRepo, s.Outbox, service.AccountConfig{
+		AppURL:           "http://fleet.test",
+		PasswordResetTTL: time.Hour,
+		EmailVerifyTTL:   time.Hour,
+	})
+
+	keys, err := keyset.New(context.Background(), keyset.NewMemoryStore(), keyset.Config{
+		Algorithm:        keyset.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		Retention:        time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Keys = keys
+
+	s.Keeper, err = rest.NewIdempotencyKeeper(cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.GraphQL, err = rest.NewGraphQLSchema(cfg, s.Ship, s.Org)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := rest.NewServer(cfg, rest.Services{
+		User:           s.User,
+		Account:        s.Account,
+		MFA:            s.MFA,
+		APIKey:         s.APIKey,
+		ServiceAccount: s.SAs,
+		Organization:   s.Org,
+		Spaceship:      s.Ship,
+		Search:         s.Search,
+		ShipClass:      s.Classes,
+		Crew:           s.Crew,
+		WorkOrder:      s.Orders,
+		Report:         s.Reports,
+		Readiness:      s.Ready,
+		Valuation:      s.Values,
+		Position:       s.Places,
+		Route:          s.Routes,
+		Mission:        s.Mission,
+		Cache:          s.Cache,
+		Idempotency:    s.Keeper,
+		GraphQL:        s.GraphQL,
+		Keys:           s.Keys,
+	})
+	e.Logger.SetOutput(io.Discard)
+
+	s.Server = httptest.NewServer(e)
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+// create user with verified email, user is admin of own organization named by email
+func (s *Server) CreateUser(t testing.TB, email string, password string, role domain.UserRole) *domain.User {
+	t.Helper()
+
+	u, err := s.User.Create(context.Background(), &domain.UserCreateReq{Email: email, Password: password, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Org.Create(context.Background(), u.ID, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// add user to organization of other user
+func (s *Server) AddMember(t testing.TB, orgEmail string, email string, role domain.OrgRole) *domain.Organization {
+	t.Helper()
+
+	org, err := s.Org.GetByName(context.Background(), orgEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Org.SetMember(context.Background(), org.ID, email, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return org
+}
+
+// enlist crew members into spaceship of organization of user up to minimum crew of its class
+// and complete its open work orders or repair it, so damaged spaceship becomes operational
+func (s *Server) ManSpaceship(t testing.TB, orgEmail string, spaceshipID uint) {
+	t.Helper()
+
+	org, err := s.Org.GetByName(context.Background(), orgEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := tenant.WithID(context.Background(), org.ID)
+
+	spaceship, err := s.Ship.GetById(ctx, spaceshipID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minCrew := uint(0)
+	if spaceship.ClassID != 0 {
+		class, err := s.Classes.GetById(ctx, spaceship.ClassID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		minCrew = class.MinCrew
+	}
+
+	// thousands of crew members are inserted at once, assignment history is not needed by tests
+	roster, err := crew.NewCrewRepo(s.DB).CountRoster(ctx, spaceshipID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := []crew.CrewMember{}
+	for i := uint(roster); i < minCrew; i++ {
+		members = append(members, crew.CrewMember{
+			TenantID:      org.ID,
+			ServiceNumber: fmt.Sprintf("TK-%d-%d", spaceshipID, i),
+			Name:          "Stormtrooper",
+			Rank:          uint(domain.CrewRankCrewman),
+			SpaceshipID:   spaceshipID,
+			EnlistedAt:    time.Now().Unix(),
+		})
+	}
+	if len(members) > 0 {
+		err = s.DB.Conn(ctx).CreateInBatches(members, 500).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orders, err := s.Orders.GetAll(ctx, domain.WorkOrderFilter{SpaceshipID: spaceshipID, Open: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range orders {
+		_, err = s.Orders.Move(ctx, o.ID, domain.WorkOrderStatusDone)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// spaceship damaged without work orders is repaired directly
+	spaceship, err = s.Ship.GetById(ctx, spaceshipID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spaceship.Status != domain.SpaceshipStatusOperational {
+		status := domain.SpaceshipStatusOperational
+		_, err = s.Ship.PatchSpaceship(ctx, spaceshipID, &domain.SpaceshipUpdate{Status: &status})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// mailer which keeps sent messages
+type Outbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (o *Outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// all sent messages
+func (o *Outbox) Messages() []mailer.Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]mailer.Message(nil), o.messages...)
+}
+
+var tokenRegexp = regexp.MustCompile(`token: (\S+)`)
+
+// token from last message sent to address, empty if none
+func (o *Outbox) LastToken(to string) string {
+	messages := o.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To != to {
+			continue
+		}
+		if m := tokenRegexp.FindStringSubmatch(messages[i].Body); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}